@@ -24,6 +24,12 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 */
 
 #include "interactive.hpp"
+#include "repl/repl.hpp"
+#include "server/http_server.hpp"
+#include "testing/golden.hpp"
+#ifdef ZELPH_GRPC
+    #include "server/grpc_server.hpp"
+#endif
 #include "versions.hpp"
 
 #ifdef _WIN32
@@ -31,53 +37,79 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
     #include <fcntl.h>   // for _O_U16TEXT
     #include <io.h>      // for _setmode
     #include <stdio.h>   // for _fileno
-#else
-    #include <sys/types.h> // for fork
-    #include <sys/wait.h>  // for waitpid
-    #include <unistd.h>    // for execvp, getenv
 #endif
 
-#include <chrono>
-#include <cstdio>
+#include <algorithm>
+#include <cctype>
+#include <cstdlib>
 #include <iostream>
+#include <optional>
 #include <string>
 #include <vector>
 
+using namespace zelph::console;
+Interactive interactive;
+
 namespace
 {
-    // Print a timing line after a REPL input only when it took noticeable
-    // time. A script import (.import file) arrives as a single REPL line,
-    // so it yields exactly one timing - never one per script line.
-    constexpr std::chrono::milliseconds kReplTimingThreshold{10};
-
-    std::string format_duration(const std::chrono::steady_clock::duration d)
+    // A TCP port given on the command line, or nothing if it is not a
+    // number from 0 to 65535.
+    std::optional<uint16_t> parse_port(const std::string& text)
     {
-        using namespace std::chrono;
-        const long long ms = duration_cast<milliseconds>(d).count();
-        if (ms < 1000) return std::to_string(ms) + " ms";
-
-        const long long s = ms / 1000;
-        char            buf[64];
-        if (s < 60)
-            std::snprintf(buf, sizeof(buf), "%lld.%03lld s", s, ms % 1000);
-        else
-            std::snprintf(buf, sizeof(buf), "%lldm%lld.%03llds", s / 60, s % 60, ms % 1000);
-        return buf;
+        if (text.empty() || text.size() > 5 || !std::all_of(text.begin(), text.end(), [](unsigned char c)
+                                                            { return std::isdigit(c); }))
+            return std::nullopt;
+        const unsigned long port = std::stoul(text);
+        if (port > 65535) return std::nullopt;
+        return static_cast<uint16_t>(port);
     }
 }
 
-using namespace zelph::console;
-Interactive interactive;
-
 int main(int argc, char** argv)
 {
 #ifdef _WIN32
     SetConsoleOutputCP(CP_UTF8);
 #endif
+    // zelph test [--update] <script.zph|directory>...: golden-file tests
+    // (see zelph::testing::run_golden_tests), exit code 1 if any fails.
+    if (argc > 1 && std::string(argv[1]) == "test")
+    {
+        bool                     update = false;
+        std::vector<std::string> paths;
+        for (int i = 2; i < argc; ++i)
+        {
+            const std::string arg = argv[i];
+            if (arg == "--update")
+                update = true;
+            else
+                paths.push_back(arg);
+        }
+
+        if (paths.empty())
+        {
+            interactive.err("Usage: zelph test [--update] <script.zph|directory>...");
+            return 2;
+        }
+
+        try
+        {
+            return zelph::testing::run_golden_tests(paths, update, std::cout) == 0 ? 0 : 1;
+        }
+        catch (std::exception& ex)
+        {
+            interactive.err(ex.what());
+            return 1;
+        }
+    }
+
     try
     {
         std::vector<std::string> script_files;
         bool                     show_version = false;
+        std::optional<uint16_t>  serve_port;
+        std::string              serve_address = "127.0.0.1";
+        std::string              serve_token;
+        std::string              grpc_address;
 
         std::vector<std::string> script_args;
 
@@ -88,6 +120,32 @@ int main(int argc, char** argv)
             {
                 show_version = true;
             }
+            else if (arg == "--serve" && script_files.empty() && i + 1 < argc)
+            {
+                // [address:]port
+                std::string value = argv[++i];
+                if (const auto colon = value.rfind(':'); colon != std::string::npos)
+                {
+                    serve_address = value.substr(0, colon);
+                    value         = value.substr(colon + 1);
+                }
+                serve_port = parse_port(value);
+                if (!serve_port)
+                {
+                    interactive.err("Usage: zelph --serve [address:]port [--serve-token token] [script.zph]; the port is a number from 0 to 65535");
+                    return 2;
+                }
+            }
+            else if (arg == "--serve-token" && script_files.empty() && i + 1 < argc)
+            {
+                serve_token = argv[++i];
+            }
+#ifdef ZELPH_GRPC
+            else if (arg == "--grpc" && script_files.empty() && i + 1 < argc)
+            {
+                grpc_address = argv[++i];
+            }
+#endif
             else if (script_files.empty())
             {
                 script_files.push_back(arg);
@@ -98,44 +156,6 @@ int main(int argc, char** argv)
             }
         }
 
-#if !defined(_WIN32) && defined(NDEBUG)
-        // rlwrap exists solely for the interactive REPL (line editing,
-        // history). Skip it for script runs (zelph <script>), for --version,
-        // and when stdin is not a terminal - e.g. when another program (a
-        // chess GUI speaking UCI, a test driver) controls zelph via pipes.
-        if (script_files.empty() && !show_version && isatty(STDIN_FILENO)
-            && getenv("ZELPH_NO_RLWRAP") == nullptr)
-        {
-            FILE* pipe = popen("command -v rlwrap", "r");
-            if (pipe)
-            {
-                char        buffer[128];
-                std::string rlwrap_path;
-                while (fgets(buffer, sizeof(buffer), pipe) != nullptr)
-                {
-                    rlwrap_path += buffer;
-                }
-                int status = pclose(pipe);
-                if (status == 0 && !rlwrap_path.empty())
-                {
-                    setenv("ZELPH_NO_RLWRAP", "1", 1);
-                    std::vector<char*> exec_args;
-                    exec_args.push_back(const_cast<char*>("rlwrap"));
-                    exec_args.push_back(const_cast<char*>("-m"));
-                    exec_args.push_back(argv[0]);
-                    for (int i = 1; i < argc; ++i)
-                    {
-                        exec_args.push_back(argv[i]);
-                    }
-                    exec_args.push_back(nullptr);
-                    execvp("rlwrap", exec_args.data());
-                    perror("execvp failed");
-                    return 1;
-                }
-            }
-        }
-#endif
-
         if (show_version)
         {
             std::cout << zelph::get_version_description() << std::endl;
@@ -147,63 +167,52 @@ int main(int argc, char** argv)
             interactive.process_file(file, script_args);
         }
 
-        if (script_files.empty())
+        // The REPL keeps running next to the server, so .quit (or the end
+        // of stdin) also stops serving.
+        zelph::server::HttpServer server(interactive);
+        if (serve_port)
         {
-            std::string exit_command = ".quit";
-            interactive.out("zelph " + zelph::console::Interactive::get_version());
-            interactive.out("-- REPL mode - type .help for commands, " + exit_command + " to exit --");
-            interactive.out("");
-
-            auto make_prompt = [&]() -> std::string
-            {
-                if (interactive.is_accumulating())
-                    return "";
-                else
-                    return interactive.get_lang()
-                         + (interactive.is_auto_run_active() ? "> " : "-> ");
-            };
-
-            interactive.prompt(make_prompt(), false);
-
-            std::string line;
-            while (std::getline(std::cin, line))
+            // Requests then need "Authorization: Bearer <token>". Without a
+            // token, only the local machine may connect.
+            if (serve_token.empty())
+                if (const char* token = std::getenv("ZELPH_SERVE_TOKEN")) serve_token = token;
+            if (serve_token.empty() && serve_address != "127.0.0.1")
             {
-                if (line == exit_command)
-                    break;
-
-                if (line.empty() && !interactive.is_accumulating())
-                {
-                    interactive.out("type .help for help --");
-                    interactive.prompt(make_prompt(), false);
-                    continue;
-                }
-
-                const auto start_time = std::chrono::steady_clock::now();
-
-                try
-                {
-                    interactive.process(line);
-                }
-                catch (const std::exception& e)
-                {
-                    interactive.err(e.what());
-                }
-
-                const auto elapsed = std::chrono::steady_clock::now() - start_time;
-                if (elapsed >= kReplTimingThreshold)
-                {
-                    interactive.log("-- " + format_duration(elapsed) + " --");
-                }
-
-                interactive.prompt(make_prompt(), false);
+                interactive.err("Serving on " + serve_address + " needs --serve-token or ZELPH_SERVE_TOKEN");
+                return 2;
             }
+            if (!serve_token.empty())
+                server.set_authenticator([expected = "Bearer " + serve_token](const zelph::server::Request& request)
+                                         {
+                    const auto it = request.headers.find("authorization");
+                    return it != request.headers.end() && it->second == expected; });
+            interactive.out("-- serving HTTP on " + serve_address + ":" + std::to_string(server.start(*serve_port, serve_address)) + " --");
+        }
+#ifdef ZELPH_GRPC
+        std::optional<zelph::server::GrpcServer> grpc_server;
+        if (!grpc_address.empty())
+        {
+            grpc_server.emplace(interactive);
+            interactive.out("-- serving gRPC on port " + std::to_string(grpc_server->start(grpc_address)) + " --");
+        }
+        const bool serving = serve_port || grpc_server;
+#else
+        const bool serving = serve_port.has_value();
+#endif
 
-            interactive.out("");
+        if (script_files.empty() || serving)
+        {
+            zelph::repl::ReplOptions options;
+            options.history_file = zelph::repl::Repl::default_history_file();
+            zelph::repl::Repl(interactive, options).run();
         }
     }
     catch (std::exception& ex)
     {
+        // E.g. a failing line or .assert of a script passed on the command
+        // line; the exit code lets CI jobs detect it.
         interactive.err(ex.what());
+        return 1;
     }
 
     return 0;
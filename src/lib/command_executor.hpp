@@ -25,9 +25,11 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #pragma once
 
+#include "process_error.hpp"
 #include "repl_state.hpp"
 
 #include <functional>
+#include <iosfwd>
 #include <memory>
 #include <string>
 #include <vector>
@@ -57,7 +59,8 @@ namespace zelph::console
     class CommandExecutor
     {
     public:
-        using LineProcessor = std::function<void(const std::string&)>;
+        using LineProcessor    = std::function<void(const std::string&)>;
+        using LineErrorHandler = std::function<void(size_t line_number, const process_error& error)>;
 
         /**
          * @brief Constructs the executor with references to the system components.
@@ -92,6 +95,38 @@ namespace zelph::console
          */
         void import_file(const std::string& file, const std::vector<std::string>& args = {}) const;
 
+        /**
+         * @brief Processes a zelph script from a stream, like import_file.
+         *
+         * Without an error handler, the first failing line ends the import
+         * with its process_error. With one, each failing line is passed to it
+         * together with its 1-based number and processing continues with the
+         * next line; only a cancellation still ends the import, after it
+         * has been passed to the handler as well.
+         *
+         * @param in The script text.
+         * @param on_error Optional handler for failing lines.
+         */
+        void import_stream(std::istream& in, const LineErrorHandler& on_error = nullptr) const;
+
+        /**
+         * @brief Processes a Datalog program from a stream, like import_stream.
+         *
+         * Each clause is translated into a zelph statement (see
+         * io::translate_datalog) and processed with the number of the line it
+         * starts on; a clause that cannot be translated fails as a syntax
+         * error. Files ending in .dl are read this way by import_file.
+         *
+         * @param in The Datalog text.
+         * @param on_error Optional handler for failing clauses.
+         */
+        void import_datalog(std::istream& in, const LineErrorHandler& on_error = nullptr) const;
+
+        /**
+         * @brief The names of all commands (e.g. ".help"), sorted.
+         */
+        std::vector<std::string> command_names() const;
+
         // Non-copyable due to internal state references
         CommandExecutor(const CommandExecutor&)            = delete;
         CommandExecutor& operator=(const CommandExecutor&) = delete;
@@ -0,0 +1,415 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "network/reasoning.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <optional>
+#include <sstream>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_confidence(const std::vector<std::string>& cmd) const
+{
+    const bool    set  = cmd.size() == 3 || cmd.size() == 5;
+    network::Node fact = resolve_fact(set ? std::vector<std::string>(cmd.begin(), cmd.end() - 1) : cmd);
+
+    if (set)
+    {
+        double value;
+        try
+        {
+            value = std::stod(cmd.back());
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .confidence: invalid value '" + cmd.back() + "'");
+        }
+        _n->set_confidence(fact, value);
+    }
+
+    std::string text;
+    string::node_to_string(_n, text, _n->lang(), fact, 3);
+    std::ostringstream value;
+    value << _n->confidence(fact);
+    _n->out(string::unmark_identifiers(text) + "  (confidence " + value.str() + ")", true);
+}
+
+void console::CommandExecutor::Impl::cmd_confidence_combination(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 3)
+        throw std::runtime_error("Usage: .confidence-combination [rule-id] [none|min|product]");
+
+    using Combination = network::Reasoning::ConfidenceCombination;
+    network::Node rule = 0;
+    if (cmd.size() == 3)
+        rule = resolve_single_node(cmd[1], true);
+
+    if (cmd.size() >= 2)
+    {
+        const std::string& mode = cmd.back();
+        if (mode == "none")
+            _n->set_confidence_combination(rule, Combination::None);
+        else if (mode == "min")
+            _n->set_confidence_combination(rule, Combination::Min);
+        else if (mode == "product")
+            _n->set_confidence_combination(rule, Combination::Product);
+        else
+            throw std::runtime_error("Usage: .confidence-combination [rule-id] [none|min|product]");
+    }
+
+    std::string name;
+    switch (_n->confidence_combination(rule))
+    {
+    case Combination::None: name = "none"; break;
+    case Combination::Min: name = "min"; break;
+    case Combination::Product: name = "product"; break;
+    case Combination::Custom: name = "custom"; break;
+    }
+    _n->out("Confidence combination" + (rule ? " of rule " + std::to_string(rule) : std::string()) + ": " + name, true);
+}
+
+void console::CommandExecutor::Impl::cmd_min_confidence(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .min-confidence [threshold]");
+
+    if (cmd.size() == 2)
+    {
+        double threshold;
+        try
+        {
+            threshold = std::stod(cmd[1]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .min-confidence: invalid threshold '" + cmd[1] + "'");
+        }
+        _n->set_min_confidence(threshold);
+    }
+
+    std::ostringstream threshold;
+    threshold << _n->min_confidence();
+    _n->out("Minimum answer confidence: " + threshold.str(), true);
+}
+
+std::optional<int64_t> console::CommandExecutor::Impl::parse_time_point(const std::string& text, const std::string& command)
+{
+    if (text.empty()) return std::nullopt;
+
+    size_t  used = 0;
+    int64_t time = 0;
+    try
+    {
+        time = std::stoll(text, &used);
+    }
+    catch (...)
+    {
+    }
+    if (used != text.size())
+        throw std::runtime_error("Command " + command + ": invalid time point '" + text + "'");
+    return time;
+}
+
+std::string console::CommandExecutor::Impl::format_validity(const network::Reasoning::Validity& validity)
+{
+    if (validity.always()) return "always valid";
+    return "valid " + (validity.from ? std::to_string(*validity.from) : std::string()) + ".."
+         + (validity.to ? std::to_string(*validity.to) : std::string());
+}
+
+void console::CommandExecutor::Impl::cmd_validity(const std::vector<std::string>& cmd) const
+{
+    const bool    set  = cmd.size() == 3 || cmd.size() == 5;
+    network::Node fact = resolve_fact(set ? std::vector<std::string>(cmd.begin(), cmd.end() - 1) : cmd);
+
+    if (set)
+    {
+        network::Reasoning::Validity validity;
+        const std::string&           interval = cmd.back();
+        if (interval != "always")
+        {
+            const size_t dots = interval.find("..");
+            if (dots == std::string::npos)
+                throw std::runtime_error("Command .validity: invalid interval '" + interval + "' (expected from..to or always)");
+            validity.from = parse_time_point(interval.substr(0, dots), ".validity");
+            validity.to   = parse_time_point(interval.substr(dots + 2), ".validity");
+        }
+        _n->set_validity(fact, validity);
+    }
+
+    std::string text;
+    string::node_to_string(_n, text, _n->lang(), fact, 3);
+    _n->out(string::unmark_identifiers(text) + "  (" + format_validity(_n->validity(fact)) + ")", true);
+}
+
+void console::CommandExecutor::Impl::cmd_as_of(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .as-of [time|off]");
+
+    if (cmd.size() == 2)
+        _n->set_as_of(cmd[1] == "off" ? std::nullopt : parse_time_point(cmd[1], ".as-of"));
+
+    const auto time = _n->as_of();
+    _n->out("Queries as of: " + (time ? std::to_string(*time) : std::string("off")), true);
+}
+
+std::string console::CommandExecutor::Impl::format_contexts(const network::Reasoning::ContextSet& contexts)
+{
+    if (contexts.empty()) return "all";
+    std::string result;
+    for (const auto& context : contexts)
+        result += (result.empty() ? "" : " ") + context;
+    return result;
+}
+
+void console::CommandExecutor::Impl::cmd_context(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .context [name|off]");
+
+    if (cmd.size() == 2)
+        _n->set_active_context(cmd[1] == "off" ? std::string() : cmd[1]);
+
+    const std::string& context = _n->active_context();
+    _n->out("Active context: " + (context.empty() ? std::string("off") : context), true);
+}
+
+void console::CommandExecutor::Impl::cmd_contexts(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() == 1)
+    {
+        const auto contexts = _n->list_contexts();
+        if (contexts.empty())
+            _n->out("No contexts.", true);
+        for (const auto& [name, count] : contexts)
+            _n->out(name + " (" + std::to_string(count) + " facts)", true);
+        return;
+    }
+
+    network::Node fact = resolve_fact(cmd);
+    std::string   text;
+    string::node_to_string(_n, text, _n->lang(), fact, 3);
+    _n->out(string::unmark_identifiers(text) + "  (contexts: " + format_contexts(_n->contexts(fact)) + ")", true);
+}
+
+void console::CommandExecutor::Impl::cmd_qualify(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() != 4 && cmd.size() != 6)
+        throw std::runtime_error("Usage: .qualify <fact-id|s p o> <predicate> <value>");
+
+    // Predicate and value are names, created if new (1990 is not an ID).
+    auto named = [this](const std::string& name)
+    {
+        network::Node nd = _n->get_node(name);
+        return nd != 0 ? nd : _n->node(name, _n->lang());
+    };
+
+    network::Node fact      = resolve_fact(std::vector<std::string>(cmd.begin(), cmd.end() - 2));
+    network::Node qualifier = _n->qualify(fact, named(cmd[cmd.size() - 2]), named(cmd.back()));
+
+    std::string text;
+    string::node_to_string(_n, text, _n->lang(), qualifier, 3);
+    _n->out(string::unmark_identifiers(text), true);
+}
+
+void console::CommandExecutor::Impl::cmd_qualifiers(const std::vector<std::string>& cmd) const
+{
+    network::Node fact       = resolve_fact(cmd);
+    const auto    qualifiers = _n->qualifiers(fact);
+    if (qualifiers.empty())
+        _n->out("No qualifiers.", true);
+    for (network::Node qualifier : qualifiers)
+    {
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), qualifier, 3);
+        _n->out(string::unmark_identifiers(text) + "  (" + std::to_string(qualifier) + ")", true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_context_scope(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() == 2 && cmd[1] == "all")
+        _n->set_context_scope({});
+    else if (cmd.size() >= 2)
+        _n->set_context_scope({cmd.begin() + 1, cmd.end()});
+
+    _n->out("Context scope: " + format_contexts(_n->context_scope()), true);
+}
+
+void console::CommandExecutor::Impl::cmd_provenance(const std::vector<std::string>& cmd) const
+{
+    network::Node                        fact       = resolve_fact(cmd);
+    const network::Reasoning::Provenance provenance = _n->provenance(fact);
+
+    std::string origin;
+    if (provenance.source.empty())
+        origin = "unknown";
+    else if (provenance.rule != 0)
+        origin = "rule " + std::to_string(provenance.rule);
+    else
+    {
+        origin = provenance.source;
+        if (!provenance.file.empty()) origin += " " + provenance.file;
+        if (provenance.line != 0) origin += ":" + std::to_string(provenance.line);
+    }
+
+    std::ostringstream trust;
+    trust << _n->trust(fact);
+
+    std::string text;
+    string::node_to_string(_n, text, _n->lang(), fact, 3);
+    _n->out(string::unmark_identifiers(text) + "  (from " + origin + ", trust " + trust.str() + ")", true);
+}
+
+void console::CommandExecutor::Impl::cmd_source_scope(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() == 2 && cmd[1] == "all")
+        _n->set_source_scope({});
+    else if (cmd.size() >= 2)
+        _n->set_source_scope({cmd.begin() + 1, cmd.end()});
+
+    _n->out("Source scope: " + format_contexts(_n->source_scope()), true);
+}
+
+double console::CommandExecutor::Impl::parse_trust(const std::string& command, const std::string& text)
+{
+    try
+    {
+        size_t       pos;
+        const double trust = std::stod(text, &pos);
+        if (pos == text.size()) return trust;
+    }
+    catch (...)
+    {
+    }
+    throw std::runtime_error("Command " + command + ": invalid trust '" + text + "'");
+}
+
+void console::CommandExecutor::Impl::cmd_trust(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 3)
+        throw std::runtime_error("Usage: .trust [source [weight]]");
+
+    if (cmd.size() == 1)
+    {
+        const auto sources = _n->list_source_trust();
+        if (sources.empty())
+            _n->out("All sources have trust 1.", true);
+        for (const auto& [source, weight] : sources)
+        {
+            std::ostringstream trust;
+            trust << weight;
+            _n->out(source + ": " + trust.str(), true);
+        }
+        return;
+    }
+
+    if (cmd.size() == 3) _n->set_source_trust(cmd[1], parse_trust(".trust", cmd[2]));
+
+    std::ostringstream trust;
+    trust << _n->source_trust(cmd[1]);
+    _n->out("Trust of " + cmd[1] + ": " + trust.str(), true);
+}
+
+void console::CommandExecutor::Impl::cmd_min_trust(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .min-trust [threshold]");
+
+    if (cmd.size() == 2) _n->set_min_trust(parse_trust(".min-trust", cmd[1]));
+
+    std::ostringstream threshold;
+    threshold << _n->min_trust();
+    _n->out("Minimum trust: " + threshold.str(), true);
+}
+
+void console::CommandExecutor::Impl::cmd_rule_trust(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Usage: .rule-trust <rule-id> [threshold]");
+
+    network::Node rule = resolve_single_node(cmd[1], true);
+    if (cmd.size() == 3) _n->set_rule_min_trust(rule, parse_trust(".rule-trust", cmd[2]));
+
+    std::ostringstream threshold;
+    threshold << _n->rule_min_trust(rule);
+    _n->out("Minimum trust of rule " + std::to_string(rule) + ": " + threshold.str(), true);
+}
+
+void console::CommandExecutor::Impl::cmd_rule_context(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 2)
+        throw std::runtime_error("Usage: .rule-context <rule-id> [name...|all]");
+
+    network::Node rule = resolve_single_node(cmd[1], true);
+    if (cmd.size() == 3 && cmd[2] == "all")
+        _n->set_rule_contexts(rule, {});
+    else if (cmd.size() >= 3)
+        _n->set_rule_contexts(rule, {cmd.begin() + 2, cmd.end()});
+
+    _n->out("Contexts of rule " + std::to_string(rule) + ": " + format_contexts(_n->rule_contexts(rule)), true);
+}
+
+void console::CommandExecutor::Impl::cmd_probabilistic(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2 || (cmd.size() == 2 && cmd[1] != "on" && cmd[1] != "off"))
+        throw std::runtime_error("Usage: .probabilistic [on|off]");
+
+    if (cmd.size() == 2)
+        _n->set_probabilistic(cmd[1] == "on");
+
+    _n->out(std::string("Probabilistic inference: ") + (_n->probabilistic() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_rule_weight(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Usage: .rule-weight <rule-id> [weight]");
+
+    network::Node rule = resolve_single_node(cmd[1], true);
+    if (cmd.size() == 3)
+    {
+        double weight;
+        try
+        {
+            weight = std::stod(cmd[2]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .rule-weight: invalid weight '" + cmd[2] + "'");
+        }
+        _n->set_rule_weight(rule, weight);
+    }
+
+    std::ostringstream weight;
+    weight << _n->rule_weight(rule);
+    _n->out("Weight of rule " + std::to_string(rule) + ": " + weight.str(), true);
+}
@@ -0,0 +1,898 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "io/data_manager.hpp"
+#include "io/facts.hpp"
+#include "io/graph_export.hpp"
+#include "io/graph_stats.hpp"
+#include "io/mermaid.hpp"
+#include "network/network.hpp"
+#include "network/reasoning.hpp"
+#include "platform/platform_utils.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#ifndef __EMSCRIPTEN__
+    #include "wikidata/wikidata.hpp"
+#endif
+
+#include <algorithm>
+#include <filesystem>
+#include <fstream>
+#include <iomanip>
+#include <map>
+#include <optional>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::display_node_details(network::Node nd, bool resolved_from_name, int depth, int max_neighbors) const
+{
+    if (resolved_from_name)
+    {
+        _n->out_stream() << "Resolved to node ID: " << nd << std::endl;
+    }
+
+    _n->out_stream() << "Node ID: " << nd << std::endl;
+
+    {
+        std::string core_name = _n->get_core_name(nd);
+        if (!core_name.empty())
+        {
+            _n->out_stream() << "  Core node: " << core_name << std::endl;
+        }
+    }
+
+    _n->out_stream() << "  Variable: " << (network::Network::is_var(nd) ? "yes" : "no") << std::endl;
+
+    bool        has_wikidata = false;
+    std::string wikidata_name;
+    bool        has_any_name = false;
+
+    for (const std::string& lang : _n->get_languages())
+    {
+        std::string name = _n->get_name(nd, lang, false);
+        if (!name.empty())
+        {
+            has_any_name = true;
+            _n->out_stream() << "  Name in language '" << lang << "': '" << name << "'" << std::endl;
+            if (lang == "wikidata")
+            {
+                has_wikidata  = true;
+                wikidata_name = name;
+            }
+        }
+    }
+
+    if (!has_any_name)
+    {
+        _n->out_stream() << "  (No names in any language)" << std::endl;
+    }
+
+    if (has_wikidata)
+    {
+        std::string       prefix    = (wikidata_name[0] == 'P') ? "Property:" : "";
+        std::string       url       = "https://www.wikidata.org/wiki/" + prefix + wikidata_name;
+        const std::string OSC_START = "\033]8;;";
+        const char        OSC_SEP   = '\a';
+        const std::string OSC_END   = "\033]8;;\a";
+        _n->out_stream() << "  Wikidata URL: " << OSC_START << url << OSC_SEP << url << OSC_END << std::endl;
+    }
+
+    if (depth > 0)
+    {
+        generate_and_print_mermaid_link(nd,
+                                        depth,
+                                        max_neighbors,
+                                        DEFAULT_EXCLUDE_NODES);
+    }
+
+    auto format_node = [this, max_neighbors](network::Node node) -> std::string
+    {
+        std::string node_str  = std::to_string(node);
+        std::string node_name = _n->get_name(node, _n->lang(), true); // fallback active
+        if (node_str == node_name || node_name.empty())
+        {
+            std::string fact_repr;
+            string::node_to_string(_n, fact_repr, _n->lang(), node, max_neighbors);
+            if (!fact_repr.empty() && fact_repr != "??")
+            {
+                return fact_repr + " (ID " + string::unmark_identifiers(std::to_string(node)) + ")";
+            }
+            else
+            {
+                return "ID " + string::unmark_identifiers(std::to_string(node));
+            }
+        }
+        else
+        {
+            return node_name + " (ID " + string::unmark_identifiers(std::to_string(node)) + ")";
+        }
+    };
+
+    auto display_connections = [&](const network::adjacency_set& conns, const std::string& header)
+    {
+        if (conns.empty())
+        {
+            return;
+        }
+
+        _n->out_stream() << "  " << header << ":" << std::endl;
+        if (conns.size() <= max_neighbors)
+        {
+            for (network::Node node : conns)
+            {
+                _n->out_stream() << "    - " << string::unmark_identifiers(format_node(node)) << std::endl;
+            }
+        }
+        else
+        {
+            _n->out_stream() << "    (" << conns.size() << " connections)" << std::endl;
+        }
+    };
+
+    display_connections(_n->get_left(nd), "Incoming connections from");
+    display_connections(_n->get_right(nd), "Outgoing connections to");
+
+    std::string fact_repr;
+    string::node_to_string(_n, fact_repr, _n->lang(), nd, max_neighbors);
+    if (!fact_repr.empty() && fact_repr != "??")
+    {
+        _n->out_stream() << "  Representation: " << string::unmark_identifiers(fact_repr) << std::endl;
+    }
+
+    _n->out_stream() << "------------------------" << std::endl;
+}
+
+void console::CommandExecutor::Impl::generate_and_print_mermaid_link(network::Node                            nd,
+                                                                     int                                      depth,
+                                                                     int                                      max_neighbors,
+                                                                     const std::unordered_set<network::Node>& exclude_nodes,
+                                                                     bool                                     dark_theme,
+                                                                     bool                                     horizontal_layout,
+                                                                     bool                                     use_subgraphs) const
+{
+    std::filesystem::path temp_dir  = std::filesystem::temp_directory_path();
+    std::string           hex_name  = _n->get_name_hex(nd, false, max_neighbors);
+    std::string           safe_name = string::sanitize_filename(hex_name);
+    std::filesystem::path html_path = temp_dir / (safe_name + ".html");
+
+    io::gen_mermaid_html(_n,
+                         nd,
+                         html_path.string(),
+                         depth,
+                         max_neighbors,
+                         exclude_nodes,
+                         dark_theme,
+                         horizontal_layout,
+                         use_subgraphs);
+
+    _repl_state->last_graph_html_path = html_path.string();
+
+#ifndef __EMSCRIPTEN__
+    // In a real terminal the OSC 8 link is clickable. In the wasm
+    // playground the graph panel fetches the file from MEMFS via
+    // take_last_graph_html() instead, and a dead file:// link in the
+    // browser terminal would only mislead - so print nothing there.
+    std::string file_url = "file://" + html_path.string();
+
+    const std::string OSC_START = "\033]8;;";
+    const char        OSC_SEP   = '\a';
+    const std::string OSC_END   = "\033]8;;\a";
+
+    _n->out_stream() << "  Mermaid HTML: " << OSC_START << file_url << OSC_SEP << file_url << OSC_END << std::endl;
+#endif
+}
+
+void console::CommandExecutor::Impl::list_predicate_usage(size_t limit)
+{
+    // Map to store predicate node and its usage count
+    std::map<network::Node, size_t> predicate_usage_counts;
+
+    // Get all predicates directly: nodes that IsA RelationTypeCategory
+    auto predicates = _n->get_sources(_n->core.IsA, _n->core.RelationTypeCategory, true);
+
+    for (const auto& pred : predicates)
+    {
+        // Get all facts where this node is used as a relation type
+        const auto& facts_using_predicate = _n->get_left(pred);
+        predicate_usage_counts[pred]      = facts_using_predicate.size();
+    }
+
+    // Convert map to vector for sorting
+    std::vector<std::pair<network::Node, size_t>> sorted_predicates(predicate_usage_counts.begin(), predicate_usage_counts.end());
+
+    // Sort the predicates by usage count in ascending order
+    std::sort(sorted_predicates.begin(), sorted_predicates.end(), [](const auto& a, const auto& b)
+              {
+                  return a.second < b.second; // Sort by count, ascending
+              });
+
+    // Determine if wikidata language is available for three-column output
+    bool has_wikidata_lang = _n->has_language("wikidata");
+
+    _n->out("Predicate Usage:", true);
+    _n->out("------------------------", true);
+
+    size_t total           = sorted_predicates.size();
+    size_t entries_to_show = limit ? std::min(limit, total) : total;
+    size_t start_idx       = (limit && limit < total) ? total - entries_to_show : 0;
+
+    for (size_t i = start_idx; i < total; ++i)
+    {
+        const auto& entry          = sorted_predicates[i];
+        std::string predicate_name = _n->get_name(entry.first, "", true); // Current language, with fallback
+        std::string line_output;
+
+        if (has_wikidata_lang && _n->get_lang() != "wikidata")
+        {
+            // Three columns: current lang name \t wikidata name \t count
+            // For the first column, `lang` is an empty string to use the current language.
+            // For the second column (wikidata name), `lang` is "wikidata" and `fallback` is `false`.
+            std::string wikidata_name = _n->get_name(entry.first, "wikidata", false);
+            line_output               = predicate_name + "\t" + wikidata_name + "\t" + std::to_string(entry.second);
+        }
+        else
+        {
+            // Two columns: current lang name \t count
+            // `lang` is an empty string to use the current language, `fallback` is `true`.
+            line_output = predicate_name + "\t" + std::to_string(entry.second);
+        }
+        _n->out(line_output, true);
+    }
+    _n->out("------------------------", true);
+    if (limit && limit < total)
+        _n->out("Showing top " + std::to_string(limit) + " of " + std::to_string(total) + " predicates.", true);
+}
+
+void console::CommandExecutor::Impl::list_predicate_value_usage(const std::string& pred_arg, size_t limit /*= 0*/)
+{
+    // Resolve the predicate node (accept name in current language or raw numeric ID)
+    network::Node pred = _n->get_node(pred_arg);
+    if (pred == 0)
+    {
+        try
+        {
+            size_t pos = 0;
+            pred       = std::stoull(pred_arg, &pos);
+            if (pos != pred_arg.length())
+                throw std::runtime_error("");
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Unknown predicate '" + pred_arg + "' in current language '" + _n->lang() + "'");
+        }
+    }
+
+    std::string pred_display = _n->get_name(pred, _n->lang(), true);
+    if (pred_display.empty())
+        pred_display = pred_arg;
+
+    _n->out("Value Usage for predicate " + pred_display + ":", true);
+    _n->out("------------------------", true);
+
+    ankerl::unordered_dense::map<network::Node, size_t> value_counts;
+
+    // All fact nodes that use this predicate: fact --> pred
+    network::adjacency_set facts = _n->get_left(pred);
+
+    for (network::Node fact : facts)
+    {
+        network::adjacency_set incoming = _n->get_left(fact); // subject(s) + object(s) --> fact
+
+        // Objects are incoming nodes where fact does NOT point back to them
+        for (network::Node cand : incoming)
+        {
+            if (!_n->has_right_edge(fact, cand))
+            {
+                value_counts[cand]++;
+            }
+        }
+    }
+
+    // Sort by count ascending
+    std::vector<std::pair<size_t, network::Node>> sorted;
+    sorted.reserve(value_counts.size());
+    for (const auto& p : value_counts)
+    {
+        sorted.emplace_back(p.second, p.first);
+    }
+    std::sort(sorted.begin(), sorted.end());
+
+    bool        has_wikidata_lang = _n->has_language("wikidata");
+    std::string curr_lang         = _n->get_lang();
+
+    size_t total           = sorted.size();
+    size_t entries_to_show = limit ? std::min(limit, total) : total;
+    size_t start_idx       = (limit && limit < total) ? total - entries_to_show : 0;
+
+    for (size_t i = start_idx; i < total; ++i)
+    {
+        const auto& entry      = sorted[i];
+        std::string value_name = _n->get_name(entry.second, "", true); // current language with fallback
+
+        std::string line;
+        if (has_wikidata_lang && curr_lang != "wikidata")
+        {
+            std::string wikidata_name = _n->get_name(entry.second, "wikidata", false);
+            if (wikidata_name.empty())
+                wikidata_name = "(no ID)";
+            line = value_name + "\t" + wikidata_name + "\t" + std::to_string(entry.first);
+        }
+        else
+        {
+            line = value_name + "\t" + std::to_string(entry.first);
+        }
+        _n->out(line, true);
+    }
+
+    _n->out("------------------------", true);
+    _n->out("Total unique values: " + std::to_string(total), true);
+    if (limit && limit < total)
+        _n->out("Showing top " + std::to_string(limit) + " of " + std::to_string(total) + " values.", true);
+    if (total == 0)
+    {
+        _n->out("(No values found for this predicate)", true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_node(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2) throw std::runtime_error("Command .node: At most one argument required");
+
+    std::string                arg;
+    std::vector<network::Node> nodes;
+
+    if (cmd.size() == 1)
+    {
+        network::Node last = string::last_node_to_string_node();
+        if (last == network::Node{}) throw std::runtime_error("Command .node: No argument given and no previous output node available");
+        nodes.push_back(last);
+    }
+    else
+    {
+        arg = cmd[1];
+
+        try
+        {
+            // Try single resolve (non-destructive: ID last)
+            network::Node single = resolve_single_node(arg, false);
+            nodes.push_back(single);
+        }
+        catch (...)
+        {
+            // Not a single node/ID → try name search (multiple possible)
+            nodes = _n->resolve_nodes_by_name(arg);
+            if (nodes.empty())
+            {
+                throw std::runtime_error("No node found with name '" + arg + "' in current language '" + _n->lang() + "'");
+            }
+        }
+    }
+
+    if (nodes.size() == 1)
+    {
+        bool resolved_from_name = !arg.empty() && (!_n->get_name(nodes[0], _n->lang(), false).empty() || std::all_of(arg.begin(), arg.end(), ::iswdigit));
+        display_node_details(nodes[0], resolved_from_name && nodes.size() == 1);
+    }
+    else
+    {
+        // nodes.size() > 1 only reachable via name search (arg non-empty)
+        _n->out_stream() << "Found " << nodes.size() << " nodes with name '" << arg
+                         << "' in current language '" << _n->lang() << "':" << std::endl;
+        _n->out_stream() << "------------------------" << std::endl;
+
+        // Sort by ID for consistent output
+        std::sort(nodes.begin(), nodes.end());
+
+        for (network::Node nd : nodes)
+        {
+            display_node_details(nd, true);
+        }
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_list(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 2) throw std::runtime_error("Command .list: Missing count parameter");
+
+    size_t count = string::parse_count(cmd[1]);
+
+    auto view = _n->get_all_nodes_view();
+
+    _n->out_stream() << "Listing " << count << " nodes:" << std::endl;
+    _n->out_stream() << "------------------------" << std::endl;
+
+    size_t displayed = 0;
+    for (auto it = view.begin(); it != view.end() && displayed < count; ++it, ++displayed)
+    {
+        display_node_details(it->first, false);
+    }
+
+    _n->out_stream() << "Displayed " << displayed << " nodes." << std::endl;
+}
+
+void console::CommandExecutor::Impl::cmd_clist(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 2) throw std::runtime_error("Command .clist: Missing count parameter");
+
+    size_t count = string::parse_count(cmd[1]);
+
+    auto view = _n->get_lang_nodes_view(_n->lang());
+
+    _n->out_stream() << "Listing first " << count << " nodes named in current language '" << _n->lang() << "'" << std::endl;
+    _n->out_stream() << "------------------------" << std::endl;
+
+    size_t displayed = 0;
+    for (auto it = view.begin(); it != view.end() && displayed < count; ++it, ++displayed)
+    {
+        display_node_details(it->second, false);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_connections(const std::vector<std::string>& cmd, bool outgoing)
+{
+    if (cmd.size() < 2) throw std::runtime_error(std::string("Command ") + cmd[0] + ": Missing node argument");
+
+    const std::string& arg     = cmd[1];
+    network::Node      base_nd = resolve_node(arg, _n->lang()); // same resolve logic as .node/.remove
+
+    if (base_nd == 0)
+    {
+        throw std::runtime_error("Unknown node");
+    }
+
+    size_t max_count = 20; // default
+    if (cmd.size() >= 3)
+    {
+        max_count = string::parse_count(cmd[2]);
+    }
+
+    network::adjacency_set neighbors = outgoing ? _n->get_right(base_nd) : _n->get_left(base_nd);
+
+    std::vector<network::Node> vec(neighbors.begin(), neighbors.end());
+    std::sort(vec.begin(), vec.end());
+
+    size_t to_display = std::min(max_count, vec.size());
+
+    _n->out_stream() << (outgoing ? "Outgoing" : "Incoming")
+                     << " connected nodes of " << base_nd
+                     << " (first " << to_display << " of " << vec.size() << ", sorted by ID):" << std::endl;
+    _n->out_stream() << "------------------------" << std::endl;
+
+    for (size_t i = 0; i < to_display; ++i)
+    {
+        display_node_details(vec[i], false);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_remove(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".remove");
+
+    if (cmd.size() != 2) throw std::runtime_error("Command .remove requires exactly one argument: name or ID");
+
+    const std::string& arg = cmd[1];
+    network::Node      nd  = resolve_single_node(arg, true); // prioritize ID
+
+    if (nd == 0)
+    {
+        try
+        {
+            size_t pos = 0;
+            nd         = std::stoull(arg, &pos);
+            if (pos != arg.length())
+            {
+                throw std::exception();
+            }
+        }
+        catch (const std::exception&)
+        {
+            throw std::runtime_error("Command .remove: Unknown node '" + arg + "' in current language '" + _n->lang() + "'");
+        }
+
+        if (!_n->exists(nd))
+        {
+            throw std::runtime_error("Command .remove: Node '" + std::to_string(nd) + "' does not exist");
+        }
+    }
+
+    _n->remove_node(nd);
+    _n->out("Removed node " + std::to_string(nd) + " (all edges disconnected, name mappings cleaned).", true);
+    _n->diagnostic("Consider running .cleanup afterwards if needed.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_mermaid(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2) throw std::runtime_error("Command .mermaid: Missing node name to visualise");
+    const std::string& arg = cmd[1];
+    network::Node      nd  = resolve_single_node(arg, true);
+    if (nd == 0) throw std::runtime_error("Command .mermaid: Unknown node '" + arg + "'");
+    int max_depth     = 1;
+    int max_neighbors = string::default_display_max_neighbors;
+    if (cmd.size() >= 3)
+    {
+        max_depth = std::stoi(cmd[2]);
+        if (max_depth < 1) throw std::runtime_error("Command .mermaid: Maximum depth must be greater than 0. Note: when using 1, a dynamic depth based on the node count will be used.");
+    }
+    if (cmd.size() >= 4)
+    {
+        max_neighbors = std::stoi(cmd[3]);
+        if (max_neighbors < 1) throw std::runtime_error("Command .mermaid: Maximum neighbors must be at least 1");
+    }
+    generate_and_print_mermaid_link(nd,
+                                    max_depth,
+                                    max_neighbors,
+                                    DEFAULT_EXCLUDE_NODES);
+}
+
+void console::CommandExecutor::Impl::cmd_export_graph(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 3 || cmd.size() > 4)
+        throw std::runtime_error("Command .export-graph requires a node, the output file (.dot or .mmd) and an optional depth");
+
+    network::Node nd = resolve_single_node(cmd[1], true);
+    if (nd == 0) throw std::runtime_error("Command .export-graph: Unknown node '" + cmd[1] + "'");
+
+    const std::string& file = cmd[2];
+    const bool         dot  = file.ends_with(".dot");
+    if (!dot && !file.ends_with(".mmd"))
+        throw std::runtime_error("Command .export-graph: filename must end with '.dot' or '.mmd'");
+
+    int depth = 2;
+    if (cmd.size() == 4)
+    {
+        depth = std::stoi(cmd[3]);
+        if (depth < 1) throw std::runtime_error("Command .export-graph: Depth must be at least 1");
+    }
+
+    std::ofstream out(file, std::ios::binary);
+    if (!out) throw std::runtime_error("Command .export-graph: cannot open '" + file + "' for writing");
+
+    auto is_deduced = [this](network::Node fact)
+    { return _n->is_deduced(fact); };
+    const size_t edges = dot ? io::export_dot(_n, out, nd, depth, is_deduced)
+                             : io::export_mermaid(_n, out, nd, depth, is_deduced);
+    _n->diagnostic("Exported " + std::to_string(edges) + " edges to " + file, true);
+}
+
+void console::CommandExecutor::Impl::cmd_facts(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1 && cmd.size() != 4)
+        throw std::runtime_error("Usage: .facts [<subject> <predicate> <object>]");
+
+    // A part of the pattern is *, the node of a name, or 0 for a name
+    // that is unknown and so matches nothing.
+    std::vector<std::optional<network::Node>> pattern;
+    for (size_t i = 1; i < cmd.size(); ++i)
+    {
+        if (cmd[i] == "*")
+            pattern.emplace_back();
+        else
+        {
+            network::Node nd = _n->get_node(cmd[i], _n->lang());
+            if (nd == 0) nd = _n->get_core_node(cmd[i]);
+            pattern.emplace_back(nd);
+        }
+    }
+
+    auto statements = io::exportable_facts(_n);
+    std::sort(statements.begin(), statements.end(), [](const io::ExportedFact& a, const io::ExportedFact& b)
+              { return a.relation < b.relation; });
+
+    size_t listed = 0;
+    for (const auto& statement : statements)
+    {
+        if (!pattern.empty()
+            && ((pattern[0] && *pattern[0] != statement.subject)
+                || (pattern[1] && *pattern[1] != statement.predicate)
+                || (pattern[2] && statement.objects.count(*pattern[2]) == 0)))
+            continue;
+
+        std::string output;
+        string::node_to_string(_n, output, _n->lang(), statement.relation, 3);
+        _n->out("[" + std::to_string(statement.relation) + "] " + string::unmark_identifiers(output) + (_n->is_deduced(statement.relation) ? " (deduced)" : ""), true);
+        ++listed;
+    }
+
+    if (listed == 0) _n->out(pattern.empty() ? "No facts found." : "No facts match the pattern.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_list_predicate_usage(const std::vector<std::string>& cmd)
+{
+    size_t limit = 0;
+    if (cmd.size() > 2) throw std::runtime_error("Command .list-predicate-usage accepts at most one optional argument (max entries)");
+    if (cmd.size() == 2)
+    {
+        try
+        {
+            size_t pos = 0;
+            limit      = std::stoull(cmd[1], &pos);
+            if (pos != cmd[1].length() || limit == 0)
+                throw std::runtime_error("Could not parse max entries argument");
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Invalid max entries argument");
+        }
+    }
+    if (_data_manager)
+    {
+        _data_manager->set_logging(false);
+    }
+    list_predicate_usage(limit);
+    if (_data_manager)
+    {
+        _data_manager->set_logging(true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_list_predicate_value_usage(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Command .list-predicate-value-usage requires one required argument (<predicate>) and one optional (max entries)");
+
+    size_t             limit    = 0;
+    const std::string& pred_arg = cmd[1];
+    if (cmd.size() == 3)
+    {
+        try
+        {
+            size_t pos = 0;
+            limit      = std::stoull(cmd[2], &pos);
+            if (pos != cmd[2].length() || limit == 0)
+                throw std::runtime_error("Could not parse max entries argument");
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Invalid max entries argument");
+        }
+    }
+    if (_data_manager)
+    {
+        _data_manager->set_logging(false);
+    }
+    list_predicate_value_usage(pred_arg, limit);
+    if (_data_manager)
+    {
+        _data_manager->set_logging(true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_stat(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .stat takes no arguments");
+
+    _n->out_stream() << "Network Statistics:" << std::endl;
+    _n->out_stream() << "------------------------" << std::endl;
+
+    _n->out_stream() << "Nodes: " << _n->count() << std::endl;
+
+    size_t ram_usage = zelph::platform::get_process_memory_usage();
+    if (ram_usage > 0)
+    {
+        _n->out_stream() << "RAM Usage: " << std::fixed << std::setprecision(1)
+                         << (static_cast<double>(ram_usage) / (1024 * 1024 * 1024)) << " GiB" << std::endl;
+    }
+
+    if (_n->language_count() > 0)
+    {
+        _n->out_stream() << "Name-of-Node Entries by language:" << std::endl;
+        for (const std::string& lang : _n->get_languages())
+        {
+            _n->out_stream() << "  " << lang << ": " << _n->get_name_of_node_size(lang) << std::endl;
+        }
+
+        _n->out_stream() << "Node-of-Name Entries by language:" << std::endl;
+        for (const std::string& lang : _n->get_languages())
+        {
+            _n->out_stream() << "  " << lang << ": " << _n->get_node_of_name_size(lang) << std::endl;
+        }
+    }
+
+    _n->out_stream() << "Languages: " << _n->language_count() << std::endl;
+    _n->out_stream() << "Rules: " << _n->rule_count() << std::endl;
+
+    _n->out_stream() << "------------------------" << std::endl;
+}
+
+void console::CommandExecutor::Impl::cmd_paths(const std::vector<std::string>& cmd)
+{
+    const std::string usage = "Usage: .paths <from> <to> [k] [max-depth] [directed] [via <relation>...]";
+    if (cmd.size() < 3) throw std::runtime_error(usage);
+
+    auto resolve = [this](const std::string& arg)
+    {
+        if (const network::Node nd = _n->get_node(arg)) return nd;
+        if (const network::Node core = _n->get_core_node(arg)) return core;
+        return resolve_single_node(arg, false);
+    };
+
+    const network::Node         from = resolve(cmd[1]);
+    const network::Node         to   = resolve(cmd[2]);
+    network::Zelph::PathOptions options;
+
+    size_t numbers = 0;
+    for (size_t i = 3; i < cmd.size(); ++i)
+    {
+        if (cmd[i] == "via")
+        {
+            if (i + 1 == cmd.size()) throw std::runtime_error(usage);
+            for (++i; i < cmd.size(); ++i)
+                options.relations.insert(resolve(cmd[i]));
+        }
+        else if (cmd[i] == "directed")
+        {
+            options.directed = true;
+        }
+        else if (numbers < 2 && !cmd[i].empty() && std::all_of(cmd[i].begin(), cmd[i].end(), ::isdigit))
+        {
+            (numbers++ == 0 ? options.max_paths : options.max_depth) = std::stoul(cmd[i]);
+        }
+        else
+        {
+            throw std::runtime_error(usage);
+        }
+    }
+
+    const auto paths = _n->find_paths(from, to, options);
+    if (paths.empty())
+    {
+        _n->out("No path found.", true);
+        return;
+    }
+
+    auto name = [this](const network::Node nd)
+    {
+        std::string result;
+        string::node_to_string(_n, result, _n->lang(), nd, 3);
+        return string::unmark_identifiers(result);
+    };
+
+    for (const auto& path : paths)
+    {
+        std::string line = name(from);
+        for (const auto& step : path)
+        {
+            const std::string predicate = name(step.predicate);
+            line += step.forward ? " --" + predicate + "--> " : " <--" + predicate + "-- ";
+            line += name(step.to);
+        }
+        _n->out(line, true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_kind_of(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Usage: .kind-of <node> [category]");
+
+    const network::Node nd = resolve_single_node(cmd[1], false);
+    if (cmd.size() == 2)
+    {
+        print_node_names(_n->kinds_of(nd), "No categories.");
+        return;
+    }
+
+    const network::Node category = resolve_single_node(cmd[2], false);
+    std::string         node_name, category_name;
+    string::node_to_string(_n, node_name, _n->lang(), nd, 3);
+    string::node_to_string(_n, category_name, _n->lang(), category, 3);
+    _n->out(string::unmark_identifiers(node_name)
+                + (_n->is_kind_of(nd, category) ? " is a kind of " : " is not a kind of ")
+                + string::unmark_identifiers(category_name) + ".",
+            true);
+}
+
+void console::CommandExecutor::Impl::cmd_instances(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 2)
+        throw std::runtime_error("Usage: .instances <category>");
+
+    print_node_names(_n->instances_of(resolve_single_node(cmd[1], false)), "No instances.");
+}
+
+void console::CommandExecutor::Impl::print_node_names(const network::adjacency_set& nodes, const std::string& none) const
+{
+    if (nodes.empty())
+    {
+        _n->out(none, true);
+        return;
+    }
+
+    std::vector<std::string> names;
+    for (const network::Node nd : nodes)
+    {
+        std::string name;
+        string::node_to_string(_n, name, _n->lang(), nd, 3);
+        names.push_back(string::unmark_identifiers(name));
+    }
+    std::sort(names.begin(), names.end());
+    for (const auto& name : names)
+        _n->out(name, true);
+}
+
+void console::CommandExecutor::Impl::cmd_graph_stats(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .graph-stats [top]");
+
+    size_t top = 10;
+    if (cmd.size() == 2)
+    {
+        try
+        {
+            top = std::stoul(cmd[1]);
+        }
+        catch (const std::exception&)
+        {
+            throw std::runtime_error("Usage: .graph-stats [top]");
+        }
+    }
+
+    const io::GraphStats stats = io::graph_stats(_n);
+
+    _n->out_stream() << "Graph Statistics:" << std::endl;
+    _n->out_stream() << "------------------------" << std::endl;
+    _n->out_stream() << "Nodes: " << stats.nodes << std::endl;
+    _n->out_stream() << "Statements: " << stats.statements << std::endl;
+    _n->out_stream() << "Edges: " << stats.edges << std::endl;
+    _n->out_stream() << "Connected components: " << stats.components << std::endl;
+
+    _n->out_stream() << "Relations: " << stats.relations.size() << std::endl;
+    const size_t shown = top == 0 ? stats.relations.size() : std::min(top, stats.relations.size());
+    for (size_t i = 0; i < shown; ++i)
+    {
+        const auto& [predicate, count] = stats.relations[i];
+        _n->out_stream() << "  " << _n->get_name(predicate, _n->lang(), true) << ": " << count << std::endl;
+    }
+    if (shown < stats.relations.size())
+        _n->out_stream() << "  ... " << stats.relations.size() - shown << " more" << std::endl;
+
+    auto distribution = [this](const std::string& title, const std::map<uint64_t, uint64_t>& degrees)
+    {
+        _n->out_stream() << title << ":" << std::endl;
+        std::map<uint64_t, uint64_t> buckets; // lower bound -> nodes
+        for (const auto& [degree, nodes] : degrees)
+        {
+            uint64_t lower = 1;
+            while (degree != 0 && lower * 2 <= degree) lower *= 2;
+            buckets[degree == 0 ? 0 : lower] += nodes;
+        }
+        for (const auto& [lower, nodes] : buckets)
+        {
+            const uint64_t upper = lower <= 1 ? lower : lower * 2 - 1;
+            std::string    range = std::to_string(lower);
+            if (upper != lower) range += "-" + std::to_string(upper);
+            _n->out_stream() << "  " << range << ": " << nodes << " nodes" << std::endl;
+        }
+    };
+    distribution("In-degree", stats.in_degrees);
+    distribution("Out-degree", stats.out_degrees);
+
+    _n->out_stream() << "------------------------" << std::endl;
+}
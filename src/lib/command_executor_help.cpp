@@ -0,0 +1,1267 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "network/reasoning.hpp"
+
+#ifndef __EMSCRIPTEN__
+    #include "wikidata/wikidata.hpp"
+#endif
+
+#include <map>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_help(const std::vector<std::string>& cmd)
+{
+    static const std::vector<std::string> general_help_lines = {
+        "zelph Interactive Help",
+        "",
+        "Available Commands",
+        "──────────────────",
+        ".help [command]             – Show this help or detailed help for a specific command",
+        ".quit                       – Exit REPL (quits zelph)",
+        ".lang [code]                – Show or set current language",
+        ".normalize [nfc|nfkc|none]  – Show or set the Unicode normalization of names (default: nfc)",
+        ".case-fold [on|off]         – Show or set whether names are matched case-insensitively (default: off)",
+        ".quotes [<open> <close>...|default] – Show or set additional quote characters of the parser",
+        ".name <node|id> <new_name>         – Set name in current language",
+        ".name <node|id> <lang> <new_name>  – Set name in specific language",
+        ".delname <node|id> [lang]          – Delete name in current language (or specified language)",
+        ".search <text> [n] [fuzzy]         – Find concepts whose name equals, starts with or contains the text (default 10)",
+        ".suggest [on|off]                  – Show or set whether queries without answers name near misses of unknown concepts (default: off)",
+        ".no-matches [on|off]               – Show or set whether a query without answers says so, showing how it was parsed (default: off)",
+        ".embedding <node|id> [v...|none]   – Show, set or remove the embedding vector of a node",
+        ".similar <node|id> [k]             – List the k nodes (default 10) with the most similar embeddings",
+        ".node [<name|id>]                  – Show detailed node information (names, connections, representation, Wikidata URL); defaults to last output node",
+        ".list <count>                      – List first N existing nodes (internal map order, with details)",
+        ".clist <count>                     – List first N nodes named in current language (sorted by ID if reasonable size, otherwise map order)",
+        ".out <name|id> [count]             – List details of outgoing connected nodes (default 20)",
+        ".in <name|id> [count]              – List details of incoming connected nodes (default 20)",
+        ".mermaid <node_name> [max_depth]   – Generate Mermaid HTML file for a node",
+        ".export-graph <node> <file.dot|file.mmd> [depth] – Write the facts around a node as GraphViz DOT or Mermaid",
+        ".export-datalog <file.dl|file.pl> [deduced] – Write facts and rules as Datalog for Soufflé or SWI-Prolog",
+        ".run                        – Run full inference",
+        ".run-once                   – Run a single inference pass",
+        ".run-dry                    – Show the facts a run would deduce, without keeping them",
+        ".debug [step|continue|...]  – Step through inference, with breakpoints on rules and facts",
+#ifndef __EMSCRIPTEN__
+        ".run-md <subdir>            – Run inference and export results as Markdown",
+        ".run-file <file>            – Run inference, write deduced facts (reversed order) to <file> (encoded if lang=wikidata)",
+        ".decode <file>              – Decode an encoded/plain file and print readable facts",
+#endif
+        ".facts [<s> <p> <o>]        – List the stated and deduced facts, or those matching a pattern (* matches anything)",
+        ".list-rules                 – List all defined inference rules",
+        ".rules                      – Same as .list-rules",
+        ".owl-rules                  – Translate the OWL axioms in the network (subClassOf, domain, range, inverse, symmetric, transitive) into rules",
+        ".list-predicate-usage [max] – Show predicate usage statistics (top N most frequent predicates)",
+        ".list-predicate-value-usage <pred> [max] – Show object/value usage statistics for a specific predicate (top N most frequent values)",
+        ".remove-rules               – Remove all inference rules",
+        ".remove-rule <id>           – Remove a single inference rule (IDs are shown by .list-rules)",
+        ".disable-rule <id>          – Keep a rule, but skip it when reasoning",
+        ".enable-rule <id>           – Re-enable a rule disabled with .disable-rule",
+        ".remove <name|id>           – Remove a node (destructive: disconnects all edges and cleans names)",
+        ".import <script> [args...]  – Load and execute a zelph (.zph, optional), Janet (.janet) or Datalog (.dl) script; falls back to the standard library",
+        ".include <script> [as <ns>] – Import a script once as a module whose new names get the prefix <ns>: (default: the file name)",
+        ".include-path [dir]         – Add a directory to search scripts in, or list them",
+        ".template [<name> <param>... : <rule>] – Define a rule template, or list them",
+        ".apply <template> <arg>...  – Add the rule of a template for each relation (or group of relations)",
+        ".relation [<rel> <property>] – Declare a relation transitive, symmetric, reflexive or inverse, or list them",
+        ".same-as <concept> <concept> – Declare two concepts identical by merging the first into the second",
+        ".bulk-load <file> [lang]    – Stream a large file of plain facts (subject predicate object per line) into the network",
+        ".import-csv <file> <s> <p> <o> [options] – Import one fact per CSV row from the given columns or =constants",
+        ".import-conceptnet <file> [options] – Import ConceptNet assertions, filtered by language, relation and weight",
+        ".import-wordnet <dir|file> [options] – Import WordNet synsets with their words and hypernyms (or other relations)",
+        ".store [<file> [cache]|off] – Show, open or close a disk-backed fact store for networks larger than RAM",
+        ".store-create <file> [facts] – Write the network's facts, or those of a plain fact file, to a fact store",
+        ".store-query <s> <p> <o> [n] – List up to n facts of the open store matching a pattern (* matches anything)",
+        ".store-import <s> <p> <o>   – State the facts of the open store matching a pattern in the network",
+        ".journal [<file>|off]       – Show the journal, or replay a journal file and append all further input to it",
+#ifndef __EMSCRIPTEN__
+        ".load <file> [lang=..] [properties=..] [owl] – Load a saved network (.bin), import a Wikidata JSON dump (creates .bin cache) or an RDF file (.ttl, .nt, .jsonld)",
+        ".load-partial <file.bin|manifest.json> [left=...] [right=...] [nameOfNode=...] [nodeOfName=...] [route-node=...] [route-name=...] [route-lang=<lang>] [manifest=<path>] [source-bin=<path>] [shard-root=<path>] [meta-only] – Load selected chunks by manifest, or selected chunks from an explicit .bin when selectors are provided; omit selectors to load all.",
+        ".save <file.bin>            – Save the current network to a binary file",
+        ".export-rdf <file.nt|file.nq> [base-iri] – Export all facts as N-Triples, or as N-Quads with deduced facts in a named graph",
+        ".export-jsonld <file.jsonld> [context.jsonld] – Export all facts as JSON-LD, compacted with the given context",
+        ".export-cypher <file.cypher> – Export all facts as Cypher MERGE statements for Neo4j",
+        ".import-neo4j <file> [name-property] – Import a Neo4j graph exported as JSON Lines (apoc.export.json.all)",
+#endif
+        ".prune-facts <pattern>      – Remove all facts matching the query pattern (only statements)",
+        ".prune-nodes <pattern>      – Remove matching facts AND all involved subject/object nodes",
+        ".retract <fact-id|s p o>    – Remove a stated fact and withdraw the deductions that depended on it",
+        ".revise <fact> [-> <fact>]  – Replace or remove a stated fact, recomputing only the deductions that depended on it",
+        ".explain <fact-id|s p o>    – Show the rules and premises a deduced fact was derived from",
+        ".conflicts                  – List the contradictions found by reasoning that still hold",
+        ".plan <query|rule-id>       – Show the join order, lookups and candidate counts of a query or rule",
+        ".view [name [query]]        – Register a query as a view kept up to date, show its answers or list the views",
+        ".remove-view <name>         – Remove a view",
+        ".query-cache [on|off|clear] – Show, switch or clear the cache of query answers",
+        ".assert <statement>         – Run the rules and fail unless the statement holds",
+        ".assert-not <statement>     – Run the rules and fail if the statement holds",
+        ".schema-violations          – List the conflicts that break a domain or range constraint",
+        ".validate                   – List the subjects with more objects of a relation than its cardinality allows",
+        ".validate-shapes            – Validate the network against the SHACL shapes it contains",
+        ".confidence <fact-id|s p o> [value] – Show or set the confidence of a fact (0 to 1)",
+        ".confidence-combination [rule-id] [none|min|product] – Show or set how rules combine premise confidences",
+        ".min-confidence [threshold] – Show or set the confidence below which query answers are left out",
+        ".validity <fact-id|s p o> [from..to|always] – Show or set the time interval during which a fact holds",
+        ".as-of [time|off]           – Show or set the time at which query answers must hold",
+        ".context [name|off]         – Show or set the context that new statements are added to",
+        ".contexts [fact-id|s p o]   – List all contexts, or the contexts of a fact",
+        ".qualify <fact-id|s p o> <predicate> <value> – Qualify a fact, e.g. with since 1990",
+        ".qualifiers <fact-id|s p o> – List the qualifiers of a fact",
+        ".context-scope [name...|all] – Show or set the contexts that queries and reasoning are limited to",
+        ".rule-context <rule-id> [name...|all] – Show or set the contexts a rule applies in",
+        ".provenance <fact-id|s p o> – Show where a fact came from (script line, import or rule)",
+        ".source-scope [name...|all] – Show or set the sources whose facts queries and reasoning use",
+        ".trust [source [weight]]    – List the trust of sources, or show or set that of one (default: 1)",
+        ".min-trust [threshold]      – Show or set the trust that query answers and deductions need",
+        ".rule-trust <rule-id> [threshold] – Show or set the trust the premises of a rule need",
+        ".probabilistic [on|off]     – Show or set whether reasoning computes marginal probabilities (default: off)",
+        ".arithmetic [on|off]        – Show or set whether <, <=, >, >= and (A + B) = C compute on numeric names (default: off)",
+        ".strings [on|off]           – Show or set whether starts_with, ends_with, equals_ignoring_case and (A ++ B) = C work on names (default: off)",
+        ".lists [on|off]             – Show or set whether element_of, head, tail and length work on lists like <a b c> (default: off)",
+        ".rule-weight <rule-id> [weight] – Show or set the probability that a rule's derivations hold",
+        ".stratum [n]                – Show or set the stratum that new rules are put into (default: 0)",
+        ".rule-stratum <rule-id> [n] – Show or set the stratum of a rule",
+        ".default-rule <rule-id> [off|priority] – Mark a rule as a default that contradictions can override",
+        ".defeats                    – List the assumptions of default rules that contradictions defeated",
+        ".cleanup                    – Remove isolated nodes and clean name mappings",
+        ".new                        – Clear the complete network and re-initialize the core nodes",
+        ".stat                       – Show network statistics (nodes, RAM usage, name entries, languages, rules)",
+        ".stats                      – Same as .stat",
+        ".paths <from> <to> [k] [max-depth] [directed] [via <relation>...] – Show the k shortest connections of two nodes",
+        ".kind-of <node> [category]  – Tell whether a node is a kind of a category, or list its categories",
+        ".instances <category>       – List all nodes that are a kind of a category",
+        ".graph-stats [top]          – Show statements, edges, relation frequencies, degree distributions and components",
+        ".metrics [on|off]           – Show monitoring metrics in Prometheus format, or switch their collection",
+        ".rule-profile [on|off]      – Show the firings and time of each rule in the last run, or switch profiling",
+#ifndef __EMSCRIPTEN__
+        ".stat-file <file.bin>       – Show serialized-file chunk statistics without loading the network",
+        ".index-file <file.bin> <json> – Emit a JSON byte-offset index for a serialized .bin file",
+#endif
+        ".licenses                   – Show third-party libraries and licenses",
+        ".trace [off|deductions|on]  – Show or set whether runs print each deduction with its premises (default), and how lines are parsed (on)",
+        ".log <max-depth>            – Enable detailed reasoning logging up to given recursion depth (0 = off, -1 = only statistics)",
+        ".log-janet                  – Toggle logging of Janet function calls (inputs/outputs)",
+        ".auto-run                   – Toggle automatic execution of .run after each input",
+        ".parallel                   – Toggle parallel processing (default: on)",
+        ".threads [n]                – Show or set the number of reasoning worker threads (0 = one per core)",
+        ".deterministic [on|off]     – Show or set whether deductions and answers come in a fixed order (default: off)",
+        ".indexes [spo] [pos] [osp]  – Show or set the fact lookup orientations the matcher may use (default: all)",
+        ".max-facts [n]              – Show or set the number of facts a run may deduce (0 = no limit)",
+        ".max-memory [bytes]         – Show or set the process memory a run may use, e.g. 4G (0 = no limit)",
+        ".max-iterations [n]         – Show or set the iterations after which a run pauses (0 = no bound)",
+        ".max-deductions [n]         – Show or set the deductions after which a run pauses (0 = no bound)",
+        ".max-term-depth [n]         – Show or set how deeply rule firings may nest the terms they build (0 = no limit)",
+        ".query-limits [ms [matches]] – Show or set the time and matches a query may use (0 = no limit)",
+        ".semi-naive [on|off|check]  – Show or set the fixpoint evaluation strategy (default: on)",
+        ".stratification [check|strict|lenient] – Check whether the rules' negations are stratifiable, or reject runs that are not",
+        ".unbounded-rules            – List rules that may build new terms without bound",
+#ifndef __EMSCRIPTEN__
+        ".wikidata-constraints <json> <dir> – Export constraints to a directory",
+        ".wikidata-qualifiers <json> [P1 P2 ...] – Import statement qualifiers from a Wikidata dump (all, or only listed qualifier properties)",
+        ".export-wikidata <json> <id1> [id2 ...] – Extracts exact JSON lines for Q-IDs (no import)",
+#endif
+        ".cluster [name]             – Show clusters, or activate one ('default' = no cluster)",
+        ".cluster-drop <name>        – Remove a cluster INCLUDING all nodes created in it",
+        ".cluster-merge <from> <to>  – Move a cluster's membership into another ('default' = keep nodes, forget cluster)",
+        ".begin                      – Open a transaction",
+        ".commit                     – Keep everything created since .begin",
+        ".rollback                   – Remove everything created since .begin",
+        "",
+        "Type \".help <command>\" for detailed information about a specific command.",
+        "",
+        "Basic Syntax",
+        "────────────",
+        "Facts:    <subject> <predicate> <object>",
+        "          Predicates with spaces must be quoted.",
+        "          Example: peter \"is father of\" paul",
+        "",
+        "Queries:  Statements containing variables (A-Z or starting with _).",
+        "          Example:",
+        "          _who \"is father of\" paul",
+        "          Answer:  peter   is father of   paul",
+        "",
+        "Rules:    (condition1, condition2, ...) => (deduction)",
+        "          Example:",
+        "          Berlin \"is capital of\" Germany",
+        "          Germany \"is located in\" Europe",
+        "            (X \"is capital of\" Y,",
+        "             Y \"is located in\" Z)",
+        "          => (X \"is located in\" Z)",
+        "          Answer: Berlin   is located in   Europe",
+        "                  ⇐ {( Germany   is located in   Europe )",
+        "                      ( Berlin   is capital of   Germany )}",
+        "",
+        "Janet Scripting",
+        "───────────────",
+        "Janet:    %<code> (inline, one line) or bare % (toggle block mode until next %).",
+        "          Janet generates facts/rules programmatically – then zelph inference runs as usual.",
+        "          Example (using the Berlin/Germany facts from above):",
+        "          %(zelph/fact \"Berlin\" \"is capital of\" \"Germany\")",
+        "          Germany \"is located in\" Europe",
+        "          %",
+        "          (let [cond (zelph/set",
+        "                      (zelph/fact 'X \"is capital of\" 'Y)",
+        "                      (zelph/fact 'Y \"is located in\" 'Z))]",
+        "            (zelph/fact cond \"~\" \"conjunction\")",
+        "            (zelph/fact cond \"=>\" (zelph/fact 'X \"is located in\" 'Z)))",
+        "          %",
+        "          Answer: Berlin   is located in   Europe",
+        "                  ⇐ {( Germany   is located in   Europe )",
+        "                      ( Berlin   is capital of   Germany )}",
+        "",
+        "Unquote:  ,janet-var inside zelph lines (after defining in Janet).",
+        "          Example:",
+        "          %(def berlin (zelph/resolve \"Berlin\"))",
+        "          ,berlin \"is capital of\" Germany"};
+
+    static const std::map<std::string, std::string> detailed_help = {
+        {".help", ".help [command]\n"
+                  "Without argument: shows this general help text with syntax and command overview.\n"
+                  "With argument: shows detailed help for the specified command."},
+
+        {".quit", ".quit\n"
+                  "Exits the interactive REPL session and quits zelph."},
+
+        {".lang", ".lang [language_code]\n"
+                  "Without argument: displays the current language used for node names.\n"
+                  "With argument: sets the language (e.g., 'zelph', 'en', 'de', 'wikidata')."},
+
+        {".normalize", ".normalize [nfc|nfkc|none]\n"
+                       "Shows or sets the Unicode normalization applied to node names when they\n"
+                       "are stored and looked up. With nfc (the default), a precomposed character\n"
+                       "and its decomposed spelling (e.g. é as one code point or as e plus a\n"
+                       "combining accent) denote the same node. nfkc additionally folds\n"
+                       "compatibility characters such as ligatures and full-width letters.\n"
+                       "none compares names byte by byte. Existing names are not rewritten,\n"
+                       "so set this before loading data."},
+
+        {".case-fold", ".case-fold [on|off]\n"
+                       "Shows or sets Unicode case folding of node names. When on, Berlin, berlin\n"
+                       "and BERLIN denote the same node; names are stored in their folded form.\n"
+                       "Variable names are not affected. Off by default."},
+
+        {".quotes", ".quotes [<open> <close> ...|default]\n"
+                    "Without argument, lists the quote characters the parser accepts. Otherwise\n"
+                    "replaces the additional quote pairs, given as opening and closing\n"
+                    "character alternately, e.g. .quotes „ “ « ». Text between them is read\n"
+                    "like \"...\", which always remains available. .quotes default removes all\n"
+                    "additional pairs."},
+
+        {".name", ".name <node|id> <new_name>\n"
+                  "Sets the name of the node in the current language.\n"
+                  ".name <node|id> <lang> <new_name>\n"
+                  "Sets the name in the specified language.\n"
+                  "The <node|id> can be a name (in current language) or numeric node ID.\n"
+                  "Empty <new_name> is not allowed – use .delname to remove a name."},
+
+        {".delname", ".delname <node|id> [lang]\n"
+                     "Removes the name of the node in the current language (or the specified language if provided).\n"
+                     "The <node|id> can be a name (in current language) or numeric node ID.\n"
+                     "If the node had no name in the target language, nothing happens."},
+
+        {".search", ".search <text> [n] [fuzzy]\n"
+                    "Lists up to n concepts (default 10) whose name in the current language equals\n"
+                    "<text>, starts with it or contains it, in this order, compared case-insensitively.\n"
+                    "With fuzzy, names a typo or two away follow (\"did you mean\"). Quote a text with\n"
+                    "blanks. Substring and fuzzy matching need at least three characters. The name\n"
+                    "index is built by the first search and again after names changed."},
+
+        {".suggest", ".suggest [on|off]\n"
+                     "Shows or sets concept suggestions. When on, a query without answers that\n"
+                     "mentions a concept known only from queries, typically a misspelt name, reports\n"
+                     "it with up to three similar names, e.g. Unknown concept 'piuss' – did you mean\n"
+                     "pius? Off by default."},
+
+        {".no-matches", ".no-matches [on|off]\n"
+                        "Shows or sets no-match reports. When on, a query without answers is answered with\n"
+                        "the pattern as it was parsed instead of nothing, e.g.\n"
+                        "  No matches for pattern X is_parent_of anna (subject: variable X, relation: is_parent_of, object: anna)\n"
+                        "so that a query that was understood can be told from one that was not. Off by default."},
+
+        {".embedding", ".embedding <node|id> [v...|none]\n"
+                       "Without values, shows the embedding vector of the node, e.g. from a language\n"
+                       "model. Otherwise sets it to the given numbers, or removes it with none. All\n"
+                       "embeddings have the dimension of the first one. Embeddings are session state\n"
+                       "and not saved with the network; see .similar."},
+
+        {".similar", ".similar <node|id> [k]\n"
+                     "Lists the k nodes (default 10) whose embeddings are most similar to that of\n"
+                     "the node by cosine similarity, best first (see .embedding)."},
+
+        {".list", ".list <count>\n"
+                  "Lists the first N existing nodes in the network (in internal map iteration order).\n"
+                  "For each node: ID, non-empty names in all languages, connection counts, representation, and Wikidata URL if available."},
+
+        {".clist", ".clist <count>\n"
+                   "Lists the first N nodes that have a name in the current language.\n"
+                   "If the language has a reasonable number of entries (≤ ~50k), nodes are sorted by ID.\n"
+                   "For very large languages (e.g. 'wikidata'), order follows the internal map (fast, no full sort)."},
+
+        {".out", ".out <name|id> [count]\n"
+                 "Lists detailed information for up to <count> nodes reachable via outgoing connections\n"
+                 "from the given node (default 20, sorted by node ID)."},
+
+        {".in", ".in <name|id> [count]\n"
+                "Lists detailed information for up to <count> nodes that have outgoing connections\n"
+                "to the given node (default 20, sorted by node ID)."},
+
+        {".node", ".node [<name_or_id>]\n"
+                  "Displays details for a single node: its ID, non-empty names in all languages,\n"
+                  "incoming/outgoing connection counts, and a clickable Wikidata URL if it has a Wikidata ID.\n"
+                  "The argument can be a name (in current language) or a numeric node ID.\n"
+                  "If no argument is given, the node from the last output is used."},
+
+        {".nodes", ".nodes <count>\n"
+                   "Lists the first N named nodes (nodes that have at least one name in any language),\n"
+                   "sorted by node ID. For each node: ID, non-empty names in all languages,\n"
+                   "incoming/outgoing connection counts, and Wikidata URL if available."},
+
+        {".clist", ".clist <count>\n"
+                   "Lists the first N nodes that have a name in the current language, sorted by node ID.\n"
+                   "Output format is identical to .list (names in all languages, connection counts, Wikidata URL)."},
+
+        {".mermaid", ".mermaid <node_name> [max_depth]\n"
+                     "Generates a Mermaid HTML file visualizing the specified node and its connections\n"
+                     "up to the given depth (default 3). The file is named <node_name>.html in the system temp dir.\n"
+                     "Outputs a clickable file:// link to the generated HTML."},
+
+        {".export-graph", ".export-graph <node> <file.dot|file.mmd> [depth]\n"
+                          "Writes the facts within <depth> steps (default 2) of <node> as a GraphViz digraph (.dot)\n"
+                          "or a Mermaid flowchart (.mmd). Each fact becomes an edge from its subject to each object,\n"
+                          "labelled by the predicate. Deduced facts are drawn dashed, asserted ones solid.\n"
+                          "Rules and facts containing variables are left out."},
+
+        {".export-datalog", ".export-datalog <file.dl|file.pl> [deduced]\n"
+                            "Writes the facts and enabled rules as Datalog clauses over one relation\n"
+                            "fact(Subject, Predicate, Object): for Soufflé (.dl) or SWI-Prolog (.pl, tabled).\n"
+                            "A rule becomes a clause per consequence, negated conditions use ! or tnot, X != Y\n"
+                            "an inequality and => ! the relation contradiction. Rules without an equivalent\n"
+                            "(nested statements, builtins, negated conjunctions, unbound consequence variables)\n"
+                            "are written as comments. Deduced facts are left out unless deduced is given.\n"
+                            "Example:\n"
+                            "  .export-datalog rules.dl\n"
+                            "  souffle -D- rules.dl"},
+
+        {".run", ".run\n"
+                 "Performs full inference: repeatedly applies all rules until no new facts are derived.\n"
+                 "Deductions are printed as they are found."},
+
+        {".run-once", ".run-once\n"
+                      "Performs a single inference pass."},
+
+        {".run-dry", ".run-dry\n"
+                     "Runs full inference like .run and prints the facts it deduces, but removes them\n"
+                     "again afterwards, so the network is left as it was. Shows the effect of a new rule\n"
+                     "before it is applied for real. Not available inside a transaction (see .begin)."},
+
+        {".debug", ".debug [break <rule-id> | break <subject> <predicate> <object> | delete <n> | clear]\n"
+                   ".debug step | continue | agenda [<limit>]\n"
+                   "Steps through inference one rule firing at a time. Without arguments, lists the\n"
+                   "breakpoints. break sets one on a rule (see .list-rules) or on the facts matching a\n"
+                   "pattern, where * matches anything; while any is set, every run (.run, the runs after\n"
+                   "input lines and .debug continue) pauses after a firing that hits one, and the next\n"
+                   "run continues from there. delete and clear remove breakpoints. step lets the rules\n"
+                   "fire once, continue until a breakpoint or the fixpoint. agenda lists the matches\n"
+                   "waiting to fire, each rule with its variable bindings, at most <limit> of them."},
+
+        {".run-md", ".run-md <subdir>\n"
+                    "Runs full inference and exports all deductions and contradictions as Markdown files\n"
+                    "in the directory mkdocs/docs/<subdir> for use with MkDocs."},
+#ifndef __EMSCRIPTEN__
+        {".run-file", ".run-file <file>\n"
+                      "Performs full inference. Deduced facts (positive conclusions and contradictions) are written to <file>\n"
+                      "in reversed order (reasons first, then ⇒ conclusion), without any brackets or markup.\n"
+                      "Console output remains unchanged (original order with ⇐ explanations).\n"
+                      "If the current language is 'wikidata' (set via .lang wikidata), Wikidata identifiers are heavily\n"
+                      "compressed for minimal file size. Otherwise the file contains plain readable text."},
+
+        {".decode", ".decode <file>\n"
+                    "Reads a file created by .run-file (encoded or plain) and prints the decoded facts\n"
+                    "in readable form to standard output."},
+#endif
+        {".facts", ".facts [<subject> <predicate> <object>]\n"
+                   "Lists the facts of the network, stated and deduced, each prefixed with its node ID;\n"
+                   "deduced facts are marked (deduced). With a pattern, only facts with these parts are\n"
+                   "listed; * matches anything, e.g. .facts * is_parent_of peter. Rules and facts\n"
+                   "containing variables are not listed."},
+
+        {".rules", ".rules\n"
+                   "Same as .list-rules."},
+
+        {".list-rules", ".list-rules\n"
+                        "Lists all currently defined inference rules in readable format, each prefixed with its node ID.\n"
+                        "Rules disabled with .disable-rule are marked (disabled), rules in a stratum other than 0\n"
+                        "show it (see .stratum), default rules are marked (default) with their priority."},
+
+        {".owl-rules", ".owl-rules\n"
+                       "Translates the axioms of a pragmatic OWL subset in the network, e.g. of an ontology\n"
+                       "imported with .load, into rules:\n"
+                       "  C rdfs:subClassOf D          (X ~ C) => (X ~ D)\n"
+                       "  P rdfs:domain C              (X P Y) => (X ~ C)\n"
+                       "  P rdfs:range C               (X P Y) => (Y ~ C)\n"
+                       "  P owl:inverseOf Q            (X P Y) => (Y Q X) and (X Q Y) => (Y P X)\n"
+                       "  P ~ owl:SymmetricProperty    (X P Y) => (Y P X)\n"
+                       "  P ~ owl:TransitiveProperty   (X P Y, Y P Z) => (X P Z)\n"
+                       "Terms are recognized by their full IRI or the prefixes rdfs: and owl:. Axioms about\n"
+                       "unnamed classes (restrictions, unions), owl:Thing as superclass and datatype ranges\n"
+                       "are skipped. Rules that already exist are not added again. Use .run to apply them."},
+
+        {".list-predicate-usage", ".list-predicate-usage [max_entries]\n"
+                                  "Shows how often each predicate (relation type) is used, sorted by frequency.\n"
+                                  "If <max_entries> is specified, only the top N most frequent predicates are shown.\n"
+                                  "If Wikidata language is active, Wikidata IDs are shown alongside names."},
+
+        {".list-predicate-value-usage", ".list-predicate-value-usage <predicate> [max_entries]\n"
+                                        "Shows how often each object (value) is used with the specified predicate, sorted by frequency.\n"
+                                        "The <predicate> can be a name (in the current language) or a numeric node ID.\n"
+                                        "If <max_entries> is specified, only the top N most frequent values are shown.\n"
+                                        "If the Wikidata language is available and active, Wikidata IDs are shown alongside names."},
+
+        {".remove-rules", ".remove-rules\n"
+                          "Deletes all inference rules from the network."},
+
+        {".remove-rule", ".remove-rule <id>\n"
+                         "Deletes a single inference rule, given by the node ID shown by .list-rules.\n"
+                         "Facts the rule has already deduced are kept."},
+
+        {".disable-rule", ".disable-rule <id>\n"
+                          "Excludes a rule (node ID as shown by .list-rules) from reasoning without deleting it.\n"
+                          "The rule stays in the network and is still saved by .save; being disabled is session state.\n"
+                          "Facts the rule has already deduced are kept."},
+
+        {".enable-rule", ".enable-rule <id>\n"
+                         "Includes a rule disabled with .disable-rule in reasoning again."},
+
+        {".remove", ".remove <name_or_id>\n"
+                    "Removes the specified node from the network, disconnecting all its edges\n"
+                    "and cleaning all name mappings. The argument can be a node name (looked up in the current language)\n"
+                    "or a numeric node ID.\n"
+                    "WARNING: This operation is destructive and irreversible!"},
+
+        {".import", ".import <script> [args...]\n"
+                    "Loads and immediately executes a script. Three script types are supported:\n"
+                    "  .zph   – zelph scripts, processed line by line. The extension is optional.\n"
+                    "  .janet – Janet programs, run like the janet CLI would: fresh environment\n"
+                    "           with the zelph/... API available, relative imports such as\n"
+                    "           (use ./foo) resolve against the script's directory, ev/... (threads,\n"
+                    "           channels) works, and a main function - if defined - is called.\n"
+                    "           The '.janet' extension must be spelled out.\n"
+                    "  .dl    – Datalog programs: parent(paul, peter). states paul parent peter,\n"
+                    "           ancestor(X, Y) :- parent(X, Y). adds a rule, ?- goal. asks a query.\n"
+                    "\n"
+                    "Anything after the script path is passed to the script as arguments:\n"
+                    "  Janet scripts receive them as parameters of main (preceded by the script\n"
+                    "  path, CLI convention) and via (dyn :args).\n"
+                    "  zelph scripts can read them from Janet code via (dyn :args).\n"
+                    "\n"
+                    "Resolution order:\n"
+                    "  1. The path as given (absolute, or relative to the current working directory).\n"
+                    "  2. The directory of the importing script, then those added by .include-path.\n"
+                    "  3. The zelph standard library. Search locations, in order:\n"
+                    "       - $ZELPH_STDLIB (if set)\n"
+                    "       - 'stdlib' next to the zelph executable (release archives, build tree)\n"
+                    "       - '../share/zelph' relative to the executable (e.g. /usr/share/zelph)\n"
+                    "       - /usr/local/share/zelph and /usr/share/zelph (non-Windows)\n"
+                    "\n"
+                    "Standard library scripts are addressed without path or extension:\n"
+                    "  .import sparql\n"
+                    "  .import arithmetic\n"
+                    "Subdirectories must be given explicitly:\n"
+                    "  .import examples/english\n"
+                    "  .import examples/neural/nn-wikidata-demo\n"
+                    "\n"
+                    "A script that imports itself, directly or through others, is an error."},
+        {".include", ".include <script> [as <namespace>]\n"
+                     "Imports a script like .import, but as a module: a second .include of the same file does\n"
+                     "nothing, and the names the module introduces are prefixed with its namespace, the file\n"
+                     "name without extension unless given with 'as'. In family.zph, 'paul' creates family:paul;\n"
+                     "other scripts refer to it as family:paul, so modules cannot collide. A name that already\n"
+                     "exists outside the module (e.g. a relation of the standard library) is shared, and names\n"
+                     "containing ':' are taken as they are. Scripts imported by a module belong to its namespace,\n"
+                     "included ones have their own. Scripts are searched like with .import, first next to the\n"
+                     "including script. Example:\n"
+                     "  .include-path kb\n"
+                     "  .include family\n"
+                     "  family:paul ~ human"},
+        {".template", ".template [<name> <parameter>... : <rule>]\n"
+                      "Defines a rule template: a rule whose parameters stand for relations, so that the same\n"
+                      "rule is declared once and added for many relations with .apply. Parameters are tokens\n"
+                      "of the rule, usually written like variables:\n"
+                      "  .template implies R S : (X R Y) => (X S Y)\n"
+                      "  .template exclusive R S : (X R Y, X S Y) => !\n"
+                      "A template of the same name is replaced. Transitive, symmetric and inverse relations are\n"
+                      "declared with .relation instead, which closes them natively. Without arguments, lists the\n"
+                      "templates."},
+        {".apply", ".apply <template> <argument>...\n"
+                   "Adds the rule of a template (see .template) with its parameters replaced by the arguments,\n"
+                   "once for each group of as many arguments as the template has parameters:\n"
+                   "  .apply implies \"is mother of\" \"is parent of\" \"is father of\" \"is parent of\"\n"
+                   "  .apply exclusive \"is parent of\" \"is child of\"\n"
+                   "A rule that exists already is not added again."},
+        {".relation", ".relation [<relation> transitive|symmetric|reflexive|inverse <relation>]\n"
+                      "Declares a property of a relation and adds the rules that define it:\n"
+                      "  .relation \"is part of\" transitive\n"
+                      "  .relation \"is sibling of\" symmetric\n"
+                      "  .relation parent inverse child\n"
+                      "Reasoning does not fire these rules but closes the relation directly, which is much faster\n"
+                      "for large relations: by breadth-first search for a transitive relation, by union-find for one\n"
+                      "that is transitive and symmetric. .explain shows the rules as usual. The rules fire normally\n"
+                      "while validity, contexts, sources, trust or confidences could restrict deductions. Without\n"
+                      "arguments, lists the declarations of this session."},
+        {".same-as", ".same-as <concept> <concept>\n"
+                     "Declares two concepts identical (owl:sameAs) and merges the first into the second: every\n"
+                     "fact about the first is restated about the second, so that both take part in the same\n"
+                     "inferences. The name of the first remains an alias of the second in later statements and\n"
+                     "queries:\n"
+                     "  .same-as NYC \"New York City\""},
+        {".include-path", ".include-path [directory]\n"
+                          "Adds a directory in which .import and .include search scripts that are not found at the\n"
+                          "given path or next to the importing script, before the standard library. Without an\n"
+                          "argument, lists the directories added."},
+        {".bulk-load", ".bulk-load <file> [lang]\n"
+                       "Streams plain facts into the network, bypassing the script parser - much faster than\n"
+                       "importing the same facts as a .zph script. Each line holds one fact:\n"
+                       "  subject<TAB>predicate<TAB>object      (tab-separated), or\n"
+                       "  subject predicate \"object with spaces\" (space-separated, double quotes group)\n"
+                       "Empty lines and lines starting with # are skipped. Names are taken literally (no variables,\n"
+                       "no nested statements) in the current language or <lang>. Rules are not run afterwards.\n"
+                       "Progress and throughput are reported every 100000 lines."},
+        {".import-csv", ".import-csv <file> <subject> <relation> <object> [delimiter=<c>|delimiter=tab] [noheader] [lang=<code>]\n"
+                        "Imports one fact per row of a CSV file. Subject, relation and object each name a column\n"
+                        "(by its header, or by its number from 1 with noheader) or, starting with =, a constant\n"
+                        "used for every row. A suffix :int, :number or :bool converts the cells to a canonical\n"
+                        "name (e.g. 007 -> 7, 2.50 -> 2.5, yes -> true); :string keeps them as they are.\n"
+                        "Rows with an empty mapped cell are skipped; rows that are too short or do not convert\n"
+                        "are reported with their line, and the import goes on. Rules are not run afterwards.\n"
+                        "Examples:\n"
+                        "  .import-csv people.csv name \"=lives in\" city\n"
+                        "  .import-csv prices.csv product =price price:number delimiter=;"},
+        {".import-conceptnet", ".import-conceptnet <file> [languages=<l>,...] [relations=<r>,...] [min-weight=<w>] [qualify]\n"
+                               "Imports the assertions of a ConceptNet 5 CSV dump (unpacked) as facts \"term relation term\",\n"
+                               "e.g. dog IsA animal. Terms have underscores replaced by blanks; the part of speech and sense\n"
+                               "of a concept are dropped. languages keeps assertions between concepts of the given languages,\n"
+                               "relations keeps those relations (named without /r/), min-weight drops assertions with a\n"
+                               "smaller weight. qualify names concepts with their language, e.g. en:dog, to keep words of\n"
+                               "several languages apart. Rules are not run afterwards.\n"
+                               "Example:\n"
+                               "  .import-conceptnet conceptnet-assertions-5.7.0.csv languages=en relations=IsA,PartOf min-weight=1"},
+        {".import-wordnet", ".import-wordnet <dir|file> [relations=<r>,...|relations=all] [glosses] [nolemmas]\n"
+                            "Imports WordNet 3 synsets from the data files (data.noun, data.verb, data.adj, data.adv) of\n"
+                            "a dict directory, or from one of them. Each synset is a node named wn:<offset>-<pos>, e.g.\n"
+                            "wn:02084071-n, with a fact \"<synset> lemma <word>\" per word (unless nolemmas) and, with\n"
+                            "glosses, \"<synset> gloss <definition>\". relations selects the pointers stated between\n"
+                            "synsets, by default hypernym and instance_hypernym; others are e.g. hyponym, part_holonym,\n"
+                            "part_meronym, member_holonym, antonym, entailment, cause, similar_to and derivation.\n"
+                            "Rules are not run afterwards.\n"
+                            "Example:\n"
+                            "  .import-wordnet /usr/share/wordnet relations=hypernym,part_holonym glosses"},
+        {".store", ".store [<file> [cache]|off]\n"
+                   "Opens a fact store (see .store-create) for querying without loading it: the file is\n"
+                   "read in pages of 64 KiB, of which the most recently used stay in memory up to the cache\n"
+                   "size (bytes or a number with K, M or G; default 64M). Only one store is open at a time.\n"
+                   "Without argument: shows the open store with its facts and cache statistics.\n"
+                   "'.store off' closes it. See .store-query and .store-import."},
+        {".store-create", ".store-create <file> [facts]\n"
+                          "Writes a fact store: a read-only file holding each fact three times, sorted by\n"
+                          "subject, predicate and object, so that any pattern is answered by binary search.\n"
+                          "Without [facts], the facts of the network whose parts all have names are written\n"
+                          "(facts with several objects or unnamed nodes are skipped). With [facts], a plain fact\n"
+                          "file as read by .bulk-load is converted without loading it into the network; only\n"
+                          "24 bytes per fact and the distinct names are held in memory while writing."},
+        {".store-query", ".store-query <subject> <predicate> <object> [n]\n"
+                         "Lists the facts of the open store (see .store) matching the pattern, at most n if\n"
+                         "given. * matches anything; other names are taken literally. Example:\n"
+                         "  .store-query * \"is capital of\" *"},
+        {".store-import", ".store-import <subject> <predicate> <object>\n"
+                          "States the facts of the open store matching the pattern (as with .store-query) in the\n"
+                          "network, naming nodes in the current language, so that rules can work on a region\n"
+                          "of a store too large to load. Rules are not run afterwards."},
+        {".journal", ".journal [<file>|off]\n"
+                     "Keeps an append-only journal of the input, for crash safety and as an audit trail.\n"
+                     "If <file> exists, it is replayed first (like .import, failing lines are reported and\n"
+                     "skipped), which rebuilds the network of an earlier session. After that, every accepted\n"
+                     "input line is appended to <file> and flushed at once, followed by the facts the rules\n"
+                     "deduced from it as '# deduced: <fact>  <=  <rule>' comments, which replay skips.\n"
+                     "Lines of an .import are covered by the .import line; failing lines and .journal\n"
+                     "itself are not written. '.journal off' closes the journal, '.journal' shows its file."},
+#ifndef __EMSCRIPTEN__
+        {".load", ".load <file> [lang=<codes>] [properties=<P-ids>] [owl]\n"
+                  "Loads a previously saved network state.\n"
+                  "- If <file> ends with '.bin': loads the serialized network directly (fast).\n"
+                  "- If <file> ends with '.json' or '.json.bz2' (Wikidata dump): imports the data and automatically creates a '.bin' cache file\n"
+                  "  in the same directory for faster future loads.\n"
+                  "  Options for Wikidata dumps:\n"
+                  "    lang=<code>[,<code>...]        label languages to import besides the Wikidata IDs (default: en; 'lang=' for none)\n"
+                  "    properties=<P-id>[,<P-id>...]  import only claims of these properties (default: all)\n"
+                  "  A filtered import is cached in its own .bin file, named after the filter.\n"
+                  "- If <file> ends with '.jsonld' (JSON-LD): imports every triple as a fact, named via the document's @context.\n"
+                  "- If <file> ends with '.ttl' (Turtle) or '.nt' (N-Triples): imports every triple as a fact in the current language.\n"
+                  "  IRIs are named by their prefixed form (ex:alice) where a prefix is declared, rdf:type/a maps to ~,\n"
+                  "  literals are named by their lexical form and blank nodes become unnamed nodes.\n"
+                  "- With 'owl', the OWL axioms of an imported RDF file are translated into rules afterwards (see .owl-rules)."},
+
+        {".load-partial", ".load-partial <file.bin|manifest.json> [selectors...] [options...]\n"
+                          "\n"
+                          "Load selected chunks from a serialized .bin file or from a JSON manifest\n"
+                          "that references chunk locations (local paths or remote URIs).\n"
+                          "The result is a read-only, incomplete graph view.\n"
+                          "Inference (.run), pruning, cleanup, and destructive edits are blocked.\n"
+                          "\n"
+                          "Chunk selectors (comma-separated indices, default: load all):\n"
+                          "  left=0,1,2          – load only left-adjacency chunks 0, 1, 2\n"
+                          "  right=5,6           – load only right-adjacency chunks 5, 6\n"
+                          "  nameOfNode=0,1      – load only name-of-node chunks 0, 1  (alias: name=)\n"
+                          "  nodeOfName=0,1      – load only node-of-name chunks 0, 1  (alias: node-name=)\n"
+                          "  <section>=none      – skip that section entirely (also accepts '-')\n"
+                          "  (omit a selector to load all chunks of that section)\n"
+                          "\n"
+                          "Use .index-file to discover chunk indices and byte offsets,\n"
+                          "and .stat-file for a quick chunk count overview.\n"
+                          "\n"
+                          "Route selectors (manifest mode only, require a node route index):\n"
+                          "  route-node=<id,...>  – resolve node IDs to the chunks that contain them\n"
+                          "                        (sets left, right, and nameOfNode selectors)\n"
+                          "  route-name=<name>    – resolve a name to the nodeOfName chunk that contains it\n"
+                          "  route-lang=<lang>    – language for route-name lookup (required with route-name)\n"
+                          "  Route selectors determine which chunks to load automatically based on\n"
+                          "  the manifest's node route index sidecar. They can be combined with\n"
+                          "  explicit chunk selectors.\n"
+                          "\n"
+                          "Options:\n"
+                          "  meta-only           – load only the header; skip all chunk payloads\n"
+                          "\n"
+                          "Manifest mode (for sharded/remote storage):\n"
+                          "  Pass a .json manifest as the first argument, or use manifest=<path>.\n"
+                          "  source-bin=<path>   – override the .bin path used for the header\n"
+                          "  shard-root=<path>   – local directory containing pre-downloaded shard files\n"
+                          "  Remote chunks (hf:// or https://) are fetched and cached automatically.\n"
+                          "\n"
+                          "Examples:\n"
+                          "  .load-partial data.bin                          – load everything (partial mode)\n"
+                          "  .load-partial data.bin left=0,1 right=none      – two left chunks, no right\n"
+                          "  .load-partial data.bin meta-only                 – header only, no graph data\n"
+                          "  .load-partial manifest.json shard-root=/data/shards\n"
+                          "  .load-partial manifest.json route-node=1        – load only chunks containing node 1\n"
+                          "  .load-partial manifest.json route-name=A route-lang=wikidata"},
+
+        {".save", ".save <file.bin>\n"
+                  "Saves the current network state to a binary file.\n"
+                  "The filename must end with '.bin'."},
+        {".export-rdf", ".export-rdf <file.nt|file.nq> [base-iri]\n"
+                        "Exports all facts (rules and facts containing variables excluded) for use with RDF tools.\n"
+                        "- '.nt' writes N-Triples, '.nq' writes N-Quads: facts deduced by rules in this session are placed\n"
+                        "  in the named graph <base-iri>graph:deduced, all others in the default graph.\n"
+                        "- Names that are IRIs (containing '://' or starting with 'urn:') are kept, all other names are\n"
+                        "  appended to <base-iri> (default: urn:zelph:). ~ is written as rdf:type, unnamed nodes as blank nodes."},
+        {".export-jsonld", ".export-jsonld <file.jsonld> [context.jsonld]\n"
+                           "Exports all facts (rules and facts containing variables excluded) as a JSON-LD document.\n"
+                           "- The context file (a JSON object, optionally wrapped in {\"@context\": ...}) maps names to IRIs\n"
+                           "  and is embedded in the output. Without @vocab, urn:zelph: is used for all names it does not cover.\n"
+                           "- .load <file.jsonld> applies the inverse mapping, so exported files can be loaded again."},
+        {".export-cypher", ".export-cypher <file.cypher>\n"
+                           "Exports all facts (rules and facts containing variables excluded) as a Cypher script for Neo4j\n"
+                           "(e.g. cypher-shell -f <file.cypher>). Nodes become :Zelph nodes keyed by zelph_id, named by the\n"
+                           "property name; each fact becomes a relationship typed by its predicate, with the property\n"
+                           "deduced telling whether a rule deduced it in this session. MERGE makes re-running the script safe."},
+        {".import-neo4j", ".import-neo4j <file> [name-property]\n"
+                          "Imports a Neo4j graph exported with apoc.export.json.all (one JSON object per line).\n"
+                          "Nodes are named by <name-property> (default: name) in the current language, unnamed if missing.\n"
+                          "Each label except Zelph yields a fact 'node ~ Label', each relationship a fact 'start TYPE end'.\n"
+                          "Relationship properties are not imported. Rules are not run afterwards."},
+#endif
+        {".prune-facts", ".prune-facts <pattern>\n"
+                         "Removes only the matching facts (statement nodes).\n"
+                         "The pattern may contain variables in any position.\n"
+                         "Reports how many facts were removed."},
+
+        {".prune-nodes", ".prune-nodes <pattern>\n"
+                         "Removes all matching facts AND all nodes that appear as subject or object in these facts.\n"
+                         "Requirements:\n"
+                         "- The relation (predicate) must be fixed (no variable allowed in predicate position)\n"
+                         "- Variables are allowed in subject and/or object positions\n"
+                         "WARNING: This is highly destructive! It removes ALL connections of the affected nodes.\n"
+                         "The relation node itself becomes isolated and can be removed with .cleanup.\n"
+                         "Reports removed facts and nodes."},
+
+        {".retract", ".retract <fact-id>\n"
+                     ".retract <subject> <predicate> <object>\n"
+                     "Removes a stated fact (or a rule, given by ID) and withdraws every deduced fact that is\n"
+                     "no longer supported: the deductions whose derivation depended on it are removed, and the\n"
+                     "rules that could produce them are applied again silently (with negated conditions in\n"
+                     "rules or in probabilistic mode, all deductions are recomputed).\n"
+                     "Nodes are given by name (current language) or ID. Deduced facts cannot be retracted\n"
+                     "directly; retract one of their premises. Reports how many deductions were withdrawn."},
+
+        {".revise", ".revise <fact-id|s p o>\n"
+                    ".revise <fact-id|s p o> -> <fact-id|s p o>\n"
+                    "Belief revision: removes a stated fact, optionally in favour of a replacement that has\n"
+                    "been stated before (e.g. pluto ~ dwarf-planet for pluto ~ planet). Like .retract, it\n"
+                    "recomputes only the affected deductions: those whose derivation depended on the fact\n"
+                    "are removed, and the rules that could produce them are applied again. The deductions\n"
+                    "that stayed withdrawn are listed. With negated conditions in rules or in probabilistic\n"
+                    "mode, all deductions are recomputed."},
+
+        {".explain", ".explain <fact-id>\n"
+                     ".explain <subject> <predicate> <object>\n"
+                     "Prints the proof tree of a fact deduced in this session: the fact with the rule that\n"
+                     "derived it, followed by the facts the rule's conditions matched, indented. Deduced\n"
+                     "premises are explained in turn; stated ones are marked (stated). Negated conditions\n"
+                     "contribute no premise."},
+
+        {".plan", ".plan <query>\n"
+                  ".plan <rule-id>\n"
+                  "Shows how the matcher evaluates a query (a statement with variables) or the\n"
+                  "conditions of a rule, without running it: one line per condition in the order\n"
+                  "they are joined, with the lookup its candidate facts are found by (spo: from the\n"
+                  "subject, pos: all facts of the relation, osp: from the object, see .indexes) and\n"
+                  "their number. Counts marked 'per binding' are averages for each binding of the\n"
+                  "earlier conditions, estimated from a sample of the relation. Conditions with\n"
+                  "large counts are the ones that make a rule slow."},
+
+        {".view", ".view <name> <query>\n"
+                  ".view <name>\n"
+                  ".view\n"
+                  "Registers a query (a statement with variables) as a materialized view, e.g.\n"
+                  "  .view grandparents (X parent Y, Y parent Z)\n"
+                  "The answers are computed once and then kept up to date as facts are stated or\n"
+                  "deduced: each new fact is matched against the conditions of the view instead of\n"
+                  "evaluating the query again, so reading a view is immediate. Views with negated\n"
+                  "conditions, and all views after facts are removed or loaded, are evaluated\n"
+                  "again. With a name only, shows the answers of the view; without arguments,\n"
+                  "lists the views with their number of answers. Views are not saved."},
+
+        {".remove-view", ".remove-view <name>\n"
+                         "Removes a view registered with .view."},
+
+        {".query-cache", ".query-cache [on|off|clear]\n"
+                         "Switches the cache of query answers on or off (default: off), clears it, or\n"
+                         "shows whether it is on with its number of entries and hits. When on, the\n"
+                         "answers to a query asked through the API (e.g. zelph_query_h) are kept and\n"
+                         "returned for the same query until a fact of one of its relations is stated,\n"
+                         "deduced or annotated (confidence, validity, contexts). Removing or loading\n"
+                         "facts and changing the query filters invalidate all entries. Queries that\n"
+                         "use Janet builtins or neural nets are not cached."},
+
+        {".assert", ".assert <subject> <predicate> <object>\n"
+                    ".assert <query>\n"
+                    "Runs the rules, then fails with an error of kind 'assertion' unless the fact exists\n"
+                    "(stated or deduced) or the query (a statement with variables) has an answer. A script\n"
+                    "can check the knowledge it builds this way, e.g. .assert paul \"is ancestor of\" pius;\n"
+                    "passed on the command line, a failing assertion makes zelph exit with code 1.\n"
+                    "Names with blanks are quoted."},
+
+        {".assert-not", ".assert-not <subject> <predicate> <object>\n"
+                        ".assert-not <query>\n"
+                        "Like .assert, but fails if the fact exists or the query has an answer."},
+
+        {".conflicts", ".conflicts\n"
+                       "Lists each distinct contradiction found by reasoning in this session whose rule and facts\n"
+                       "still exist: the rule that detected it, followed by the facts its conditions matched.\n"
+                       "stdlib/exclusive.zph declares mutually exclusive relations (R excludes S)."},
+
+        {".schema-violations", ".schema-violations\n"
+                               "Lists the conflicts (see .conflicts) that break a schema constraint declared with\n"
+                               "stdlib/schema.zph (R domain C, R range C): the statement, the node that is not an\n"
+                               "instance of the class expected, and whether it is the subject or the object."},
+
+        {".validate", ".validate\n"
+                      "Checks the statements against the cardinality constraints of their relations:\n"
+                      "  R ~ functional          – every subject has at most one object of R\n"
+                      "  R max_cardinality N     – every subject has at most N objects of R\n"
+                      "Lists each subject that exceeds a limit, followed by the statements that give its\n"
+                      "objects. If a relation has several declarations, the smallest limit applies."},
+
+        {".validate-shapes", ".validate-shapes\n"
+                             "Validates the focus nodes of the SHACL shapes in the network (e.g. loaded with\n"
+                             ".load shapes.ttl, or stated with sh: names) and lists one result per violated\n"
+                             "constraint: focus node, path, constraint component and message. Supported are\n"
+                             "sh:targetClass, sh:targetNode, sh:targetSubjectsOf, sh:targetObjectsOf and property\n"
+                             "shapes with a predicate path and sh:minCount, sh:maxCount or sh:class. Property\n"
+                             "shapes with other paths are skipped and counted."},
+
+        {".confidence", ".confidence <fact-id> [value]\n"
+                        ".confidence <subject> <predicate> <object> [value]\n"
+                        "Shows the confidence of a fact, or sets it to a value between 0 and 1. Facts have\n"
+                        "confidence 1 unless set. Above 0.5 a fact counts as true, below 0.5 as false: a rule\n"
+                        "deducing a fact of confidence below 0.5 reports a contradiction."},
+
+        {".confidence-combination", ".confidence-combination [rule-id] [none|min|product]\n"
+                                    "Shows or sets how a rule derives the confidence of its deductions from the facts its\n"
+                                    "conditions matched. Without rule ID: the setting of all rules without one of their own.\n"
+                                    "  none    – (default) premise confidences are ignored\n"
+                                    "  min     – the weakest premise\n"
+                                    "  product – the product of the premises\n"
+                                    "The confidence of ≈ conditions multiplies the result. Deductions whose confidence is not\n"
+                                    "above 0.5 are not made. Facts that already exist keep their confidence."},
+
+        {".min-confidence", ".min-confidence [threshold]\n"
+                            "Shows or sets the confidence threshold of query answers (default 0: all answers).\n"
+                            "An answer's confidence is that of the weakest fact it matched, times the confidence\n"
+                            "of its ≈ conditions."},
+
+        {".validity", ".validity <fact-id> [from..to|always]\n"
+                      ".validity <subject> <predicate> <object> [from..to|always]\n"
+                      "Shows the validity interval of a fact, or sets it. Time points are integers in a unit of\n"
+                      "your choice (e.g. years); both ends are inclusive and may be left out (1848.., ..1990).\n"
+                      "always removes the interval. A statement can also carry one: paris capital france @[1848..]\n"
+                      "A deduction holds where the intervals of the facts its conditions matched overlap; if they\n"
+                      "do not overlap, it is not made. Intervals are not saved with the network."},
+
+        {".as-of", ".as-of [time|off]\n"
+                   "Shows or sets a point in time for queries: answers are left out unless all the facts they\n"
+                   "matched are valid at that time (see .validity). off (the default) keeps all answers."},
+
+        {".context", ".context [name|off]\n"
+                     "Shows or sets the active context. While it is set, every fact stated at the top level is\n"
+                     "added to it (a fact that held in every context then holds only in the contexts it was\n"
+                     "added to). Facts without a context hold in every context. A deduction holds in the\n"
+                     "contexts the facts its conditions matched have in common; if they have none, it is not\n"
+                     "made. Contexts are not saved with the network."},
+
+        {".contexts", ".contexts [fact-id]\n"
+                      ".contexts <subject> <predicate> <object>\n"
+                      "Without arguments, lists all contexts with the number of facts in them. With a fact,\n"
+                      "lists the contexts it holds in."},
+
+        {".qualify", ".qualify <fact-id> <predicate> <value>\n"
+                     ".qualify <subject> <predicate> <object> <predicate> <value>\n"
+                     "States the qualifier (fact predicate value), e.g. .qualify berlin is_capital_of germany\n"
+                     "since 1990, the same as the statement (berlin is_capital_of germany) since 1990.\n"
+                     "Qualifiers are ordinary facts about the fact, so queries and rules match them, as in\n"
+                     "(X is_capital_of germany) since T."},
+
+        {".qualifiers", ".qualifiers <fact-id>\n"
+                        ".qualifiers <subject> <predicate> <object>\n"
+                        "Lists the qualifiers of a fact, i.e. the facts whose subject it is."},
+
+        {".context-scope", ".context-scope [name...|all]\n"
+                           "Shows or sets the contexts that queries and reasoning are limited to: only facts holding\n"
+                           "in at least one of them (or in every context) are used. all (the default) removes the\n"
+                           "limit. Negated conditions still see all facts."},
+
+        {".rule-context", ".rule-context <rule-id> [name...|all]\n"
+                          "Shows or sets the contexts a rule applies in. Its deductions then hold in these contexts\n"
+                          "only, and it is not applied to facts outside of them. all (the default) removes the limit."},
+
+        {".provenance", ".provenance <fact-id>\n"
+                        ".provenance <subject> <predicate> <object>\n"
+                        "Shows where a fact came from: the script file and line that stated it (source script),\n"
+                        "the file it was loaded from (sources wikidata, rdf, jsonld, zelph, neo4j), a line\n"
+                        "entered interactively (input), the API (api) or the rule that deduced it (rule).\n"
+                        "Facts from .bulk-load have no recorded source. The fact's trust is shown as well (see .trust)."},
+
+        {".source-scope", ".source-scope [name...|all]\n"
+                          "Shows or sets the sources that queries and reasoning are limited to (see .provenance):\n"
+                          "only facts from one of them are used, and deduced facts whose premises are. For\n"
+                          "example, .source-scope rdf lets only the facts of a trusted RDF import take part in\n"
+                          "inference. all (the default) removes the limit. Negated conditions still see all facts."},
+
+        {".trust", ".trust [source [weight]]\n"
+                   "Without arguments, lists the sources whose trust has been set. With a source (see\n"
+                   ".provenance), shows its trust or sets it to a weight between 0 and 1 (default 1). A stated\n"
+                   "fact has the trust of its source, a deduced fact that of its least trusted premise, e.g.\n"
+                   ".trust rdf 0.4 for scraped data next to curated facts of trust 1."},
+
+        {".min-trust", ".min-trust [threshold]\n"
+                       "Shows or sets the trust that the facts matched by query answers and deductions need\n"
+                       "(default 0: all facts). See .trust."},
+
+        {".rule-trust", ".rule-trust <rule-id> [threshold]\n"
+                        "Shows or sets the trust that the facts matched by a rule need for it to deduce from them\n"
+                        "(default 0), in addition to .min-trust. See .trust."},
+
+        {".probabilistic", ".probabilistic [on|off]\n"
+                           "Shows or sets probabilistic inference. When on, .run records every derivation of a fact\n"
+                           "and then sets the confidence of each deduced fact to its marginal probability: the\n"
+                           "noisy-OR of its derivations, each holding with its rule's weight times the probabilities\n"
+                           "of the facts its conditions matched. Stated facts keep their confidence. Deduced facts\n"
+                           "below 0.5 are kept rather than reported as contradictions, and query answers show their\n"
+                           "probability. Confidence combinations are not applied in this mode."},
+
+        {".arithmetic", ".arithmetic [on|off]\n"
+                        "Shows or sets native arithmetic on numeric literals, i.e. nodes named like 1000000, -3\n"
+                        "or 2.5. When on, the conditions X < Y, X <= Y, X > Y and X >= Y compare two numbers, and\n"
+                        "(A op B) = C with op one of + - * / binds C to the result, or checks a bound C. These\n"
+                        "relations are then no longer matched against facts, so the rule-based arithmetic of\n"
+                        "the standard library (stdlib/arithmetic.zph, &-literals) needs it to be off. Off by\n"
+                        "default."},
+
+        {".strings", ".strings [on|off]\n"
+                     "Shows or sets the string builtins, which work on the names of nodes in the current\n"
+                     "language. When on, A starts_with B, A ends_with B and A equals_ignoring_case B test two\n"
+                     "names, and (A ++ B) = C binds C to the node named by the concatenation, or checks a\n"
+                     "bound C. Terms nest, e.g. ((F ++ \"_\") ++ L) = N. Off by default."},
+
+        {".lists", ".lists [on|off]\n"
+                   "Shows or sets the list builtins on cons lists like <a b c>. When on, E element_of L\n"
+                   "binds E to each element of L in turn (or tests a bound E), L head H and L tail T give\n"
+                   "the first element and the rest of a non-empty list, and L length N the number of\n"
+                   "elements, as a node named by the number. Off by default."},
+
+        {".rule-weight", ".rule-weight <rule-id> [weight]\n"
+                         "Shows or sets the weight of a rule (0 to 1, default 1): the probability that one of its\n"
+                         "derivations holds given its premises. Only used by .probabilistic inference."},
+
+        {".stratum", ".stratum [n]\n"
+                     "Shows or sets the stratum that rules stated from now on are put into (an integer, default 0).\n"
+                     ".run first runs the rules of the lowest stratum to a fixpoint, then adds the next stratum\n"
+                     "and runs again, and so on; e.g. classification rules in stratum 0 are saturated before\n"
+                     "rules in stratum 1 first fire. Within a pass, rules are applied in order of stratum, then\n"
+                     "node ID. Strata are session state and not saved."},
+
+        {".rule-stratum", ".rule-stratum <rule-id> [n]\n"
+                          "Shows or sets the stratum of a rule (see .stratum)."},
+
+        {".default-rule", ".default-rule <rule-id> [on|off|priority]\n"
+                          "Shows whether a rule is a default rule, or marks it as one (on: priority 0) or not (off).\n"
+                          "The deductions of default rules are assumptions: a contradiction that rests on them\n"
+                          "is not reported, instead the assumptions of the lowest priority among them are\n"
+                          "withdrawn with their consequences and not deduced again. Exceptions are contradiction\n"
+                          "rules, which always win over defaults:\n"
+                          "  (X ~ bird) => (X can fly)                 .default-rule <its id>\n"
+                          "  (X ~ penguin, X can fly) => !\n"
+                          "Session state, not saved."},
+
+        {".defeats", ".defeats\n"
+                     "Lists the assumptions of default rules that contradictions defeated, each with the\n"
+                     "default rule that deduced it and the rule that found the contradiction."},
+
+        {".cleanup", ".cleanup\n"
+                     "Removes all nodes that have no connections (isolated nodes).\n"
+                     "Also cleans up associated entries in name mappings."},
+
+        {".new", ".new\n"
+                 "Clears the complete network, including node names. Re-initializes core nodes."},
+
+        {".stat", ".stat\n"
+                  "Shows current network statistics:\n"
+                  "- Number of nodes\n"
+                  "- RAM usage (in GiB, if available)\n"
+                  "- Total entries in name-of-node mappings\n"
+                  "- Total entries in node-of-name mappings\n"
+                  "- Number of languages\n"
+                  "- Number of rules"},
+
+        {".stats", ".stats\n"
+                   "Same as .stat."},
+
+        {".paths", ".paths <from> <to> [k] [max-depth] [directed] [via <relation>...]\n"
+                   "Shows how two nodes are connected: the k shortest paths (default 1) of at\n"
+                   "most max-depth statements (default 6, 0 for no bound), e.g.\n"
+                   "  paul --is_parent_of--> peter <--is_parent_of-- anna\n"
+                   "Statements are followed in both directions unless 'directed' is given.\n"
+                   "'via' limits the relations that may be followed. Rules, predicate\n"
+                   "declarations and statements with variables are not followed."},
+
+        {".kind-of", ".kind-of <node> [category]\n"
+                     "Tells whether the node is a kind of the category, i.e. whether a chain of\n"
+                     "one or more is-a statements (~) leads from the node to the category, e.g.\n"
+                     "  .kind-of tweety animal\n"
+                     "Without a category, lists all categories of the node. No rules are\n"
+                     "applied: the hierarchy is kept as an index that is updated whenever an\n"
+                     "is-a statement is added, so the answer is immediate even for deep\n"
+                     "hierarchies. Statements with variables and negated ones do not count."},
+
+        {".instances", ".instances <category>\n"
+                       "Lists all nodes that are a kind of the category (see .kind-of): its\n"
+                       "instances and subclasses and theirs, sorted by name."},
+
+        {".graph-stats", ".graph-stats [top]\n"
+                         "Shows the shape of the network: nodes, statements, edges (subject-object\n"
+                         "pairs of statements), the number of weakly connected components, the\n"
+                         "top relations by number of statements (default 10, 0 for all) and the\n"
+                         "in- and out-degree distributions of the nodes that occur in statements,\n"
+                         "grouped by powers of two. Rules and their conditions are not counted."},
+
+        {".metrics", ".metrics [on|off]\n"
+                     "Without argument, prints the metrics of the network in the Prometheus text\n"
+                     "exposition format: nodes, facts, deduced facts and rules as gauges, and,\n"
+                     "while collection is on, the runs and their duration, the deductions per\n"
+                     "rule, the contradictions and the duration of queries since it was switched\n"
+                     "on. Collection is off by default; .metrics on starts it from zero."},
+
+        {".rule-profile", ".rule-profile [on|off]\n"
+                          "Without argument, prints the rule profile of the last run made while profiling\n"
+                          "was on, in the style of pprof's top listing: for each rule that fired its\n"
+                          "evaluation time, the share of the total, the running sum of the shares, its\n"
+                          "firings (matches of its condition) and the new facts they produced, the most\n"
+                          "expensive rule first, then the rules that never fired. Off by default."},
+#ifndef __EMSCRIPTEN__
+        {".stat-file", ".stat-file <file.bin>\n"
+                       "Reads only the serialized zelph header from the given .bin file and prints\n"
+                       "file size and chunk counts for left/right adjacency and name maps.\n"
+                       "Does not load the network into memory."},
+
+        {".index-file", ".index-file <file.bin> <output.json>\n"
+                        "Scans a serialized zelph .bin file sequentially and emits a JSON sidecar\n"
+                        "containing byte offsets and lengths for the header and each chunk section.\n"
+                        "Does not load the graph into the live network."},
+#endif
+        {".licenses", ".licenses\n"
+                      "Lists all third-party software embedded in zelph, including their versions and licenses."},
+
+        {".trace", ".trace [off|deductions|on]\n"
+                   "Shows or sets the trace. At deductions, the default, .run and the runs after each\n"
+                   "input print every fact they deduce together with the condition it was derived from,\n"
+                   "e.g. peter is_child_of paul ⇐ paul is_parent_of peter. When off, runs only print\n"
+                   "contradictions. When on, every statement is also reported the way it was parsed:\n"
+                   "as fact, rule or query, with the subject, relation and objects of each part and\n"
+                   "the names that became new concepts, e.g.\n"
+                   "  Parsed as fact paul is_parent_of peter (subject: paul, relation: is_parent_of, object: peter); new concepts: paul, is_parent_of, peter"},
+
+        {".log", ".log <max-depth>\n"
+                 "Enables detailed reasoning logging up to the given recursion depth.\n"
+                 "0 disables it.\n"
+                 "Every line is correctly indented according to depth."},
+
+        {".log-janet", ".log-janet\n"
+                       "Toggles detailed logging of inputs and outputs for all zelph/* Janet functions.\n"
+                       "Logs inputs at function entry and both inputs and output at exit."},
+
+        {".auto-run", ".auto-run\n"
+                      "Toggles the automatic execution of the inference engine (.run) after every input.\n"
+                      "Default is ON. Automatically switches to OFF when .load is used."},
+
+        {".parallel", ".parallel\n"
+                      "Toggles parallel processing on/off.\n"
+                      "Default is on for performance."},
+
+        {".threads", ".threads [n]\n"
+                     "Without argument: shows the number of worker threads that scan for the\n"
+                     "matches of rule conditions in parallel (see .parallel).\n"
+                     "With argument: sets it; 0 selects one thread per hardware thread (default).\n"
+                     "The matches are processed in the order a single thread would find them,\n"
+                     "so deductions are inserted in the same order for every thread count."},
+
+        {".deterministic", ".deterministic [on|off]\n"
+                           "Without argument: shows the mode. With argument: switches it (default: off).\n"
+                           "In deterministic mode, identical input gives the same deductions in the same\n"
+                           "order and the same answers in the same order, e.g. for golden tests, also\n"
+                           "after .save and .load: the matcher visits candidate facts in ascending order\n"
+                           "of their IDs instead of the order of the sets holding them, semi-naive\n"
+                           "iterations seed their facts in that order, and the answers of a query are\n"
+                           "sorted before they are printed. Costs a sort of every set of candidate facts\n"
+                           "the matcher visits."},
+
+        {".indexes", ".indexes [spo] [pos] [osp]\n"
+                     "Without argument: shows the orientations the matcher may use to find the\n"
+                     "candidate facts of a condition. With arguments: enables exactly the given ones.\n"
+                     "  spo  start from a bound subject\n"
+                     "  pos  scan the facts of the relation (always enabled, it is the fallback)\n"
+                     "  osp  start from a bound object\n"
+                     "For each condition the enabled orientation with the fewest candidate facts\n"
+                     "is chosen, so a bound object with few facts is not scanned through a hub\n"
+                     "subject. All three read the adjacency the network keeps anyway; disabling\n"
+                     "one costs query speed and saves no memory."},
+
+        {".max-facts", ".max-facts [n]\n"
+                       "Without argument: shows the limit. With argument: sets the number of facts\n"
+                       "a single run may deduce; 0 removes the limit (default). A run exceeding it\n"
+                       "stops with a resource-limit error and keeps the facts deduced so far.\n"
+                       "Guards against rule sets that never reach a fixpoint."},
+
+        {".max-memory", ".max-memory [bytes]\n"
+                        "Without argument: shows the limit. With argument: sets the memory the\n"
+                        "process may use during a run (resident plus swapped), in bytes or with a\n"
+                        "K, M or G suffix; 0 removes the limit (default). Checked every 1024\n"
+                        "deductions; a run exceeding it stops with a resource-limit error and keeps\n"
+                        "the facts deduced so far. Only measured on Linux."},
+
+        {".max-iterations", ".max-iterations [n]\n"
+                            "Without argument: shows the bound. With argument: sets the number of\n"
+                            "fixpoint iterations after which a run pauses; 0 removes the bound\n"
+                            "(default). A paused run is not an error: it keeps its deductions, and the\n"
+                            "next run continues where it stopped (with semi-naive evaluation from the\n"
+                            "facts it had not yet processed). Allows reasoning step by step on large\n"
+                            "networks."},
+
+        {".max-deductions", ".max-deductions [n]\n"
+                            "Without argument: shows the bound. With argument: sets the number of facts\n"
+                            "after which a run pauses; 0 removes the bound (default). Worker threads may\n"
+                            "finish a few more deductions before the run stops. Like .max-iterations,\n"
+                            "and unlike .max-facts, the next run continues where this one stopped."},
+
+        {".max-term-depth", ".max-term-depth [n]\n"
+                            "Without argument: shows the limit. With argument: sets how many nested\n"
+                            "firings of rules that build terms (fresh variables or nested statements in\n"
+                            "their consequences) a run may chain; 0 removes the limit (default). A firing\n"
+                            "is one deeper than the deepest term among its bindings, stated nodes have\n"
+                            "depth 0. A run exceeding the limit stops with a resource-limit error naming\n"
+                            "the rule and keeps the facts deduced so far. See .unbounded-rules."},
+
+        {".query-limits", ".query-limits [ms [matches]]\n"
+                          "Without argument: shows the limits of queries. With arguments: sets the time\n"
+                          "in milliseconds a query may take and the number of matches (candidate facts\n"
+                          "bound by its conditions) it may process; 0 removes a limit (default). A query\n"
+                          "exceeding one stops, prints the answers found so far and a notice that they\n"
+                          "are incomplete, so a pathological pattern cannot stall the session. Runs,\n"
+                          "views and .prune-facts/.prune-nodes are not limited."},
+
+        {".semi-naive", ".semi-naive [on|off|check]\n"
+                        "Controls the fixpoint evaluation strategy of the reasoning engine.\n"
+                        "Without argument: shows the current mode.\n"
+                        "  on    – (default) delta-driven semi-naive evaluation: after a classic\n"
+                        "          first pass, each further iteration evaluates rules only against\n"
+                        "          the facts created in the previous iteration. A run after a\n"
+                        "          complete one skips the classic pass and starts from the facts\n"
+                        "          created since, unless rules were added, enabled or disabled or\n"
+                        "          facts were removed, bulk-imported or loaded. Results are\n"
+                        "          identical to 'off'; typically much faster on rule-heavy\n"
+                        "          workloads such as the arithmetic modules.\n"
+                        "  off   – classic naive evaluation: every iteration re-evaluates all\n"
+                        "          rules against the whole graph.\n"
+                        "  check – like 'on', but after the delta drains, classic verification\n"
+                        "          passes run until quiescence. If any of them derives a fact the\n"
+                        "          delta path missed, the run completes the fixpoint classically\n"
+                        "          and then fails with a completeness-violation error. Intended\n"
+                        "          for tests and debugging; the test suite always enables it.\n"
+                        "Single-pass runs (.run-once) and queries are unaffected by this setting."},
+
+        {".stratification", ".stratification [check|strict|lenient]\n"
+                            "Rules with negated conditions are evaluated in a single deferred stratum,\n"
+                            "after the positive rules have reached quiescence. This is sound unless a\n"
+                            "pattern negated by one such rule may be grown by the consequences of\n"
+                            "another one (directly or through other rules), or directly by its own.\n"
+                            "Without argument: shows the current mode.\n"
+                            "  check   – lists every such case among the enabled rules, comparing\n"
+                            "            patterns structurally (a variable matches anything).\n"
+                            "  strict  – .run and auto-run reject a rule set with such cases with an error.\n"
+                            "  lenient – (default) such rule sets are evaluated anyway. The check is\n"
+                            "            conservative: modules whose terms order their derivations\n"
+                            "            (e.g. diff with symbolic-core) are reported although sound."},
+
+        {".unbounded-rules", ".unbounded-rules\n"
+                             "Lists the enabled rules that build a new term on each firing (a variable\n"
+                             "only their consequences contain, or a nested statement with variables)\n"
+                             "and whose consequences may match their own conditions, directly or through\n"
+                             "other rules. Each line shows the rule, the term and the cycle of rules.\n"
+                             "Such rule sets may never reach a fixpoint. The check compares patterns\n"
+                             "structurally and is conservative: modules whose terms shrink along the\n"
+                             "recursion (e.g. the arithmetic modules) are reported although they\n"
+                             "terminate. See .max-term-depth for a guard at run time."},
+#ifndef __EMSCRIPTEN__
+        {".wikidata-constraints", ".wikidata-constraints <json_file> <output_dir>\n"
+                                  "Processes the Wikidata dump and exports constraint scripts\n"
+                                  "to the specified output directory."},
+
+        {".wikidata-qualifiers", ".wikidata-qualifiers <wikidata-dump.json[.bz2]> [P-id1 P-id2 ...]\n"
+                                 "Imports statement qualifiers from a Wikidata JSON dump into the current network.\n"
+                                 "Without P-ids: imports all qualifiers. With P-ids: imports only qualifiers whose\n"
+                                 "property is listed (e.g. P11260); a statement is only materialized if it\n"
+                                 "contributes at least one matching qualifier.\n"
+                                 "\n"
+                                 "For each such statement, reified structures are created as ordinary nodes and\n"
+                                 "facts in the 'wikidata' language (matching the RDF statement layer):\n"
+                                 "  <subject>   p:<P>          <statement>   – links entity to its statement node\n"
+                                 "  <statement> ps:<P>         <main value>  – the statement's main value\n"
+                                 "  <statement> pq:<Pq>        <value>       – one fact per imported qualifier\n"
+                                 "  <statement> wikibase:rank  wikibase:{Normal|Preferred|Deprecated}Rank\n"
+                                 "Statement nodes are named by their Wikidata statement ID (contains '$').\n"
+                                 "\n"
+                                 "IMPORTANT: Load the base network first (.load <all.bin>), so that subjects and\n"
+                                 "entity values attach to the existing Q/P nodes via their 'wikidata' names.\n"
+                                 "The import is idempotent and incremental: re-running it (e.g. with additional\n"
+                                 "qualifier properties) extends the loaded network. Persist the combined result\n"
+                                 "with .save <file.bin>.\n"
+                                 "\n"
+                                 "Value handling: entity values use their Q/P ID; time, quantity, string and\n"
+                                 "monolingual text values become nodes named by their raw value (e.g.\n"
+                                 "'+2020-01-01T00:00:00Z', '+42'). novalue/somevalue and coordinates are skipped.\n"
+                                 "\n"
+                                 "Example (disjointness analysis, 'list item' qualifiers on P2738):\n"
+                                 "  .load wikidata-20260309-all.bin\n"
+                                 "  .wikidata-qualifiers wikidata-20260309-all.json.bz2 P11260\n"
+                                 "  .save wikidata-20260309-all-P11260.bin"},
+
+        {".export-wikidata", ".export-wikidata <wikidata-dump.json> <Qid1> [Qid2 ...]\n"
+                             "Extracts the exact JSON line for each given Wikidata ID (Q…)\n"
+                             "from the dump and writes it to <id>.json in the current directory.\n"
+                             "The dump can be .json or .json.bz2.\n"
+                             "No import, no .bin cache, no network – pure extraction."},
+
+        {".cluster", ".cluster [name]\n"
+                     "Without argument: lists all clusters with node counts and shows the active one.\n"
+                     "With argument: activates the named cluster (created if needed). All nodes and\n"
+                     "facts created from now on are recorded in it — including relation nodes,\n"
+                     "rule definitions, query patterns, and facts deduced by .run. Facts that\n"
+                     "already existed before are never recorded.\n"
+                     "'.cluster default' deactivates cluster tracking.\n"
+                     "Note: clusters are session state and are not persisted by .save."},
+#endif
+        {".cluster-drop", ".cluster-drop <name>\n"
+                          "Removes every node recorded in the cluster, including all edges and names\n"
+                          "(rollback semantics). Pre-existing knowledge is untouched, but facts created\n"
+                          "OUTSIDE the cluster that reference cluster nodes lose those connections.\n"
+                          "WARNING: destructive and irreversible."},
+
+        {".cluster-merge", ".cluster-merge <from> <to>\n"
+                           "Moves the membership bookkeeping of <from> into <to> (commit semantics).\n"
+                           "No nodes or edges are touched. If <to> is 'default', the nodes simply\n"
+                           "become ordinary nodes."},
+
+        {".begin", ".begin\n"
+                   "Opens a transaction: every node created from now on — facts, rules and deductions —\n"
+                   "is recorded, like in a cluster of its own. .commit keeps them, .rollback removes them,\n"
+                   "e.g. after a batch of statements turned out to contain an error or a contradiction.\n"
+                   "Changes to nodes that existed before are not undone. Transactions do not nest, and\n"
+                   ".cluster cannot be used inside one."},
+
+        {".commit", ".commit\n"
+                    "Closes the transaction opened by .begin and keeps everything created in it."},
+
+        {".rollback", ".rollback\n"
+                      "Closes the transaction opened by .begin and removes every node created in it."},
+    };
+
+    if (cmd[0] == ".help")
+    {
+        if (cmd.size() == 1)
+        {
+            for (const auto& l : general_help_lines)
+                _n->out(l, true);
+        }
+        else if (cmd.size() == 2)
+        {
+            auto it = detailed_help.find(cmd[1]);
+            if (it != detailed_help.end())
+            {
+                _n->out(it->second, true);
+            }
+            else
+            {
+                _n->error("Unknown command: " + cmd[1] + ". Use \".help\" for a list of all commands.", true);
+            }
+        }
+        else
+        {
+            throw std::runtime_error("Usage: .help [command]");
+        }
+    }
+}
@@ -0,0 +1,485 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "command_executor.hpp"
+
+#include "io/data_manager.hpp"
+#include "io/fact_store.hpp"
+#include "io/owl.hpp"
+#include "io/rule_template.hpp"
+#include "network/reasoning.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+
+#ifndef __EMSCRIPTEN__
+    #include <capnp/message.h>
+    #include <kj/io.h>
+#endif
+
+#include <map>
+#include <optional>
+
+class zelph::console::CommandExecutor::Impl
+{
+public:
+    Impl(network::Reasoning*            n,
+         ScriptEngine*                  se,
+         std::shared_ptr<ReplState>     rs,
+         CommandExecutor::LineProcessor lp)
+        : _n(n)
+        , _script_engine(se)
+        , _repl_state(std::move(rs))
+        , _process_line_callback(std::move(lp))
+    {
+        register_commands();
+    }
+
+    void execute(const std::vector<std::string>& cmd);
+
+    std::vector<std::string> command_names() const;
+
+private:
+    // --- Context References ---
+    network::Reasoning*              _n;
+    ScriptEngine*                    _script_engine;
+    std::shared_ptr<io::DataManager> _data_manager;
+    std::shared_ptr<ReplState>       _repl_state;
+    CommandExecutor::LineProcessor   _process_line_callback;
+
+    // --- Dispatch Map ---
+    using Handler = std::function<void(const std::vector<std::string>&)>;
+    std::map<std::string, Handler> _command_map;
+
+    // --- Rule templates of .template and .apply, by name ---
+    std::map<std::string, io::RuleTemplate> _rule_templates;
+
+    // --- Breakpoints of .debug ---
+    // A part of a pattern is unset for *, or the node of a name, 0 for a
+    // name that is unknown and so matches nothing (as in .facts).
+    struct Breakpoint
+    {
+        uint64_t                     id;
+        network::Node                rule{0}; // 0 for a pattern
+        std::optional<network::Node> subject;
+        std::optional<network::Node> predicate;
+        std::optional<network::Node> object;
+        std::string                  description;
+    };
+    std::vector<Breakpoint> _breakpoints;
+    uint64_t                _next_breakpoint{1};
+    uint64_t                _breakpoint_hit{0};
+
+    // --- Registration ---
+    void register_commands();
+
+// --- Helpers ---
+#ifndef __EMSCRIPTEN__
+    class CountingInputStream : public kj::InputStream
+    {
+    public:
+        explicit CountingInputStream(kj::InputStream& inner)
+            : _inner(inner)
+        {
+        }
+
+        size_t tryRead(void* buffer, size_t minBytes, size_t maxBytes) override
+        {
+            auto n = _inner.tryRead(buffer, minBytes, maxBytes);
+            _count += n;
+            return n;
+        }
+
+        void skip(size_t bytes) override
+        {
+            _inner.skip(bytes);
+            _count += bytes;
+        }
+
+        uint64_t bytes_read() const
+        {
+            return _count;
+        }
+
+    private:
+        kj::InputStream& _inner;
+        uint64_t         _count{0};
+    };
+
+    class CountingBufferedInputStream : public kj::BufferedInputStream
+    {
+    public:
+        explicit CountingBufferedInputStream(kj::InputStream& inner)
+            : _buffered(inner)
+        {
+        }
+
+        kj::ArrayPtr<const kj::byte> tryGetReadBuffer() override
+        {
+            return _buffered.tryGetReadBuffer();
+        }
+
+        size_t tryRead(void* buffer, size_t minBytes, size_t maxBytes) override
+        {
+            auto n = _buffered.tryRead(buffer, minBytes, maxBytes);
+            _count += n;
+            return n;
+        }
+
+        void skip(size_t bytes) override
+        {
+            _buffered.skip(bytes);
+            _count += bytes;
+        }
+
+        uint64_t bytes_read() const
+        {
+            return _count;
+        }
+
+    private:
+        kj::BufferedInputStreamWrapper _buffered;
+        uint64_t                       _count{0};
+    };
+
+    struct BinHeaderStats
+    {
+        uint32_t left_chunk_count   = 0;
+        uint32_t right_chunk_count  = 0;
+        uint32_t name_of_node_count = 0;
+        uint32_t node_of_name_count = 0;
+        uint32_t format_version     = 0;
+        uint64_t file_size_bytes    = 0;
+    };
+
+    struct BinChunkRef
+    {
+        uint32_t    chunk_index = 0;
+        uint64_t    offset      = 0;
+        uint64_t    length      = 0;
+        std::string which;
+        std::string lang;
+    };
+
+    struct BinIndexData
+    {
+        std::string              filename;
+        BinHeaderStats           stats;
+        uint64_t                 header_length_bytes = 0;
+        std::vector<BinChunkRef> left_chunks;
+        std::vector<BinChunkRef> right_chunks;
+        std::vector<BinChunkRef> name_of_node_chunks;
+        std::vector<BinChunkRef> node_of_name_chunks;
+    };
+
+    static ::capnp::ReaderOptions make_bin_reader_options();
+
+    static BinHeaderStats read_bin_header_stats(const std::string& filename);
+
+    static BinIndexData read_bin_index_data(const std::string& filename);
+
+    static void write_bin_index_json(const BinIndexData& data, const std::string& output_filename);
+
+    static std::vector<uint32_t> parse_chunk_index_list(const std::string& value, const std::string& label);
+
+    static std::vector<uint64_t> parse_node_id_list(const std::string& value, const std::string& label);
+#endif
+
+    void require_full_graph_mode(const char* command_name) const;
+
+#define DEFAULT_EXCLUDE_NODES {_n->core.RelationTypeCategory, _n->core.IsA}
+
+    void display_node_details(network::Node nd, bool resolved_from_name, int depth = 1, int max_neighbors = string::default_display_max_neighbors) const;
+
+    void generate_and_print_mermaid_link(network::Node                            nd,
+                                         int                                      depth,
+                                         int                                      max_neighbors,
+                                         const std::unordered_set<network::Node>& exclude_nodes,
+                                         bool                                     dark_theme        = true,
+                                         bool                                     horizontal_layout = true,
+                                         bool                                     use_subgraphs     = true) const;
+
+    network::Node resolve_node(const std::string& arg, const std::string& lang) const;
+
+    network::Node resolve_single_node(const std::string& arg, bool prioritize_id) const;
+
+public:
+    void import_file(const std::string& file, const std::vector<std::string>& args = {}) const;
+
+    // Imports a script as a module (see .include): once, with its names in
+    // the namespace name_space, or in one named after the file if empty.
+    void include_file(const std::string& file, std::string name_space) const;
+
+    void import_stream(std::istream& in, const LineErrorHandler& on_error) const;
+
+    void import_datalog(std::istream& in, const LineErrorHandler& on_error) const;
+
+private:
+    // Where scripts are searched after the path as given: the directory of
+    // the script being read, if any, then the .include-path directories.
+    std::vector<std::string> search_paths() const;
+
+    // The path that identifies a script for cycle detection and .include.
+    static std::string canonical_script(const std::string& path);
+
+    // Like read_script, with each clause processed as the zelph statement
+    // it translates to, its origin the line it starts on.
+    void read_datalog(std::istream& in, const LineErrorHandler& on_error, const std::string& file) const;
+
+    // Facts stated by a line get its file and number as their origin (see
+    // Reasoning::provenance).
+    void read_script(std::istream& in, const LineErrorHandler& on_error, const std::string& file) const;
+
+    void list_predicate_usage(size_t limit);
+
+    void list_predicate_value_usage(const std::string& pred_arg, size_t limit /*= 0*/);
+
+    // --- Command Handlers ---
+
+    void cmd_help(const std::vector<std::string>& cmd);
+    void cmd_lang(const std::vector<std::string>& cmd);
+
+    void cmd_normalize(const std::vector<std::string>& cmd);
+
+    void cmd_case_fold(const std::vector<std::string>& cmd);
+
+    void cmd_quotes(const std::vector<std::string>& cmd);
+
+    void cmd_name(const std::vector<std::string>& cmd);
+    void cmd_delname(const std::vector<std::string>& cmd);
+    void cmd_suggest(const std::vector<std::string>& cmd);
+
+    void cmd_trace(const std::vector<std::string>& cmd);
+
+    void cmd_no_matches(const std::vector<std::string>& cmd);
+
+    void cmd_search(const std::vector<std::string>& cmd);
+
+    void cmd_embedding(const std::vector<std::string>& cmd);
+
+    void cmd_similar(const std::vector<std::string>& cmd);
+
+    void cmd_node(const std::vector<std::string>& cmd);
+    void cmd_list(const std::vector<std::string>& cmd);
+    void cmd_clist(const std::vector<std::string>& cmd);
+    void cmd_connections(const std::vector<std::string>& cmd, bool outgoing);
+    void cmd_remove(const std::vector<std::string>& cmd);
+    void cmd_mermaid(const std::vector<std::string>& cmd);
+    void cmd_export_graph(const std::vector<std::string>& cmd) const;
+    void cmd_export_datalog(const std::vector<std::string>& cmd) const;
+    void cmd_run(const std::vector<std::string>&);
+    void cmd_run_dry(const std::vector<std::string>& cmd);
+    void cmd_debug(const std::vector<std::string>& cmd);
+    // Lets every run (.run, .debug continue and the runs after input lines)
+    // pause after a firing that hits a breakpoint, or none if there are none.
+    void install_breakpoints();
+    void list_breakpoints() const;
+    void list_agenda(const size_t limit) const;
+    void cmd_run_once(const std::vector<std::string>&);
+#ifndef __EMSCRIPTEN__
+    void cmd_run_md(const std::vector<std::string>& cmd);
+    void cmd_run_file(const std::vector<std::string>& cmd);
+
+    void cmd_decode(const std::vector<std::string>& cmd);
+    // The source recorded for facts loaded by .load (see .provenance).
+    static std::string data_source(const io::DataType type);
+
+    void cmd_load(const std::vector<std::string>& cmd);
+    void cmd_load_partial(const std::vector<std::string>& cmd);
+    void cmd_wikidata_constraints(const std::vector<std::string>& cmd);
+    void cmd_wikidata_qualifiers(const std::vector<std::string>& cmd);
+
+    void cmd_export_wikidata(const std::vector<std::string>& cmd);
+#endif
+    void cmd_list_rules(const std::vector<std::string>&);
+    void cmd_facts(const std::vector<std::string>& cmd);
+    void cmd_owl_rules(const std::vector<std::string>& cmd);
+    void report_owl_rules(const io::OwlTranslation& translation);
+    void cmd_list_predicate_usage(const std::vector<std::string>& cmd);
+    void cmd_list_predicate_value_usage(const std::vector<std::string>& cmd);
+
+    void cmd_remove_rules(const std::vector<std::string>&);
+    void cmd_remove_rule(const std::vector<std::string>& cmd);
+    void cmd_set_rule_enabled(const std::vector<std::string>& cmd, bool enabled);
+    void cmd_prune(const std::vector<std::string>& cmd, bool facts_mode);
+    // The fact given by "<fact-id>" or "<subject> <predicate> <object>"
+    // after the command name (.retract, .explain).
+    network::Node resolve_fact(const std::vector<std::string>& cmd) const;
+    void cmd_explain(const std::vector<std::string>& cmd) const;
+    void cmd_conflicts(const std::vector<std::string>& cmd) const;
+    void cmd_schema_violations(const std::vector<std::string>& cmd) const;
+    void cmd_validate(const std::vector<std::string>& cmd) const;
+    void cmd_validate_shapes(const std::vector<std::string>& cmd) const;
+    void cmd_confidence(const std::vector<std::string>& cmd) const;
+    void cmd_confidence_combination(const std::vector<std::string>& cmd) const;
+    void cmd_min_confidence(const std::vector<std::string>& cmd) const;
+    static std::optional<int64_t> parse_time_point(const std::string& text, const std::string& command);
+    static std::string format_validity(const network::Reasoning::Validity& validity);
+    void cmd_validity(const std::vector<std::string>& cmd) const;
+    void cmd_as_of(const std::vector<std::string>& cmd) const;
+    static std::string format_contexts(const network::Reasoning::ContextSet& contexts);
+    void cmd_context(const std::vector<std::string>& cmd) const;
+    void cmd_contexts(const std::vector<std::string>& cmd) const;
+    void cmd_qualify(const std::vector<std::string>& cmd) const;
+    void cmd_qualifiers(const std::vector<std::string>& cmd) const;
+    void cmd_context_scope(const std::vector<std::string>& cmd) const;
+    void cmd_provenance(const std::vector<std::string>& cmd) const;
+    void cmd_source_scope(const std::vector<std::string>& cmd) const;
+    static double parse_trust(const std::string& command, const std::string& text);
+    void cmd_trust(const std::vector<std::string>& cmd) const;
+    void cmd_min_trust(const std::vector<std::string>& cmd) const;
+    void cmd_rule_trust(const std::vector<std::string>& cmd) const;
+    void cmd_rule_context(const std::vector<std::string>& cmd) const;
+    void cmd_probabilistic(const std::vector<std::string>& cmd) const;
+    void cmd_arithmetic(const std::vector<std::string>& cmd) const;
+    void cmd_strings(const std::vector<std::string>& cmd) const;
+    void cmd_lists(const std::vector<std::string>& cmd) const;
+    void cmd_rule_weight(const std::vector<std::string>& cmd) const;
+    static int parse_stratum(const std::string& command, const std::string& text);
+    void cmd_stratum(const std::vector<std::string>& cmd) const;
+    void cmd_rule_stratum(const std::vector<std::string>& cmd) const;
+    void cmd_default_rule(const std::vector<std::string>& cmd) const;
+    void cmd_defeats(const std::vector<std::string>& cmd) const;
+    void cmd_retract(const std::vector<std::string>& cmd);
+    void cmd_revise(const std::vector<std::string>& cmd);
+    void cmd_cleanup(const std::vector<std::string>& cmd);
+    void cmd_journal(const std::vector<std::string>& cmd);
+    void cmd_new(const std::vector<std::string>& cmd);
+    void cmd_stat(const std::vector<std::string>& cmd);
+#ifndef __EMSCRIPTEN__
+    void cmd_stat_file(const std::vector<std::string>& cmd);
+    void cmd_index_file(const std::vector<std::string>& cmd);
+#endif
+    void cmd_licenses(const std::vector<std::string>& cmd);
+    void cmd_log(const std::vector<std::string>& cmd);
+    void cmd_log_janet(const std::vector<std::string>& cmd);
+#ifndef __EMSCRIPTEN__
+    void cmd_save(const std::vector<std::string>& cmd);
+    void cmd_export_rdf(const std::vector<std::string>& cmd) const;
+    void cmd_export_jsonld(const std::vector<std::string>& cmd) const;
+    void cmd_export_cypher(const std::vector<std::string>& cmd) const;
+    void cmd_import_neo4j(const std::vector<std::string>& cmd) const;
+#endif
+    void cmd_import(const std::vector<std::string>& cmd) const;
+    void cmd_template(const std::vector<std::string>& cmd);
+    // Adds the rule of a template for each group of arguments, one group
+    // per parameter count; a rule that exists already is not added again.
+    void cmd_apply(const std::vector<std::string>& cmd);
+    // Declares a property of a relation (see
+    // network::Reasoning::declare_relation_property), or lists them.
+    void cmd_relation(const std::vector<std::string>& cmd);
+    void cmd_same_as(const std::vector<std::string>& cmd);
+    std::string rule_text(const network::Node rule) const;
+    void cmd_include(const std::vector<std::string>& cmd) const;
+    void cmd_include_path(const std::vector<std::string>& cmd) const;
+    void cmd_bulk_load(const std::vector<std::string>& cmd) const;
+    void cmd_import_csv(const std::vector<std::string>& cmd) const;
+    void cmd_import_conceptnet(const std::vector<std::string>& cmd) const;
+    void cmd_import_wordnet(const std::vector<std::string>& cmd) const;
+    void cmd_store(const std::vector<std::string>& cmd) const;
+
+    void cmd_store_create(const std::vector<std::string>& cmd) const;
+
+    const io::FactStore& open_store(const char* command_name) const;
+
+    static std::string store_pattern_part(const std::string& part);
+
+    void cmd_store_query(const std::vector<std::string>& cmd) const;
+
+    void cmd_store_import(const std::vector<std::string>& cmd) const;
+
+    void cmd_auto_run(const std::vector<std::string>&);
+    void cmd_parallel(const std::vector<std::string>& cmd);
+
+    void cmd_indexes(const std::vector<std::string>& cmd);
+
+    void cmd_threads(const std::vector<std::string>& cmd);
+
+    void cmd_deterministic(const std::vector<std::string>& cmd);
+
+    void cmd_max_facts(const std::vector<std::string>& cmd);
+
+    void cmd_max_term_depth(const std::vector<std::string>& cmd);
+
+    // A number of bytes, optionally with a K, M or G suffix. Anything else
+    // is thrown as std::invalid_argument.
+    static uint64_t parse_bytes(const std::string& text);
+
+    void cmd_max_memory(const std::vector<std::string>& cmd);
+
+    void cmd_max_iterations(const std::vector<std::string>& cmd);
+
+    void cmd_query_limits(const std::vector<std::string>& cmd);
+
+    void cmd_max_deductions(const std::vector<std::string>& cmd);
+
+    void cmd_paths(const std::vector<std::string>& cmd);
+
+    void cmd_kind_of(const std::vector<std::string>& cmd);
+
+    void cmd_instances(const std::vector<std::string>& cmd);
+
+    // One line per node, sorted by name.
+    void print_node_names(const network::adjacency_set& nodes, const std::string& none) const;
+
+    void cmd_graph_stats(const std::vector<std::string>& cmd);
+
+    void cmd_metrics(const std::vector<std::string>& cmd);
+
+    void cmd_rule_profile(const std::vector<std::string>& cmd);
+
+    void cmd_semi_naive(const std::vector<std::string>& cmd);
+
+    void cmd_stratification(const std::vector<std::string>& cmd);
+
+    void cmd_plan(const std::vector<std::string>& cmd);
+
+    // The statement given as the arguments of a command; the tokenizer
+    // stripped the quotes of names with blanks.
+    void cmd_view(const std::vector<std::string>& cmd);
+
+    void cmd_remove_view(const std::vector<std::string>& cmd);
+
+    void cmd_query_cache(const std::vector<std::string>& cmd);
+
+    static std::string join_statement(const std::vector<std::string>& cmd);
+
+    void cmd_assert(const std::vector<std::string>& cmd, const bool expected);
+
+    void cmd_unbounded_rules(const std::vector<std::string>& cmd);
+
+    void cmd_cluster(const std::vector<std::string>& cmd);
+
+    void cmd_cluster_drop(const std::vector<std::string>& cmd);
+
+    void cmd_begin(const std::vector<std::string>& cmd);
+
+    void cmd_commit(const std::vector<std::string>& cmd);
+
+    void cmd_rollback(const std::vector<std::string>& cmd);
+
+    void cmd_cluster_merge(const std::vector<std::string>& cmd);
+};
@@ -0,0 +1,303 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "io/bulk_loader.hpp"
+#include "io/conceptnet.hpp"
+#include "io/csv.hpp"
+#include "io/datalog.hpp"
+#include "io/fact_store.hpp"
+#include "io/wordnet.hpp"
+#include "network/reasoning.hpp"
+
+#include <algorithm>
+#include <filesystem>
+#include <fstream>
+#include <iomanip>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_export_datalog(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 2 || cmd.size() > 3 || (cmd.size() == 3 && cmd[2] != "deduced"))
+        throw std::runtime_error("Usage: .export-datalog <file.dl|file.pl> [deduced]");
+
+    const std::string& file = cmd[1];
+    io::DatalogOptions options;
+    if (file.ends_with(".pl"))
+        options.dialect = io::DatalogDialect::Prolog;
+    else if (!file.ends_with(".dl"))
+        throw std::runtime_error("Command .export-datalog: filename must end with '.dl' or '.pl'");
+    options.deduced = cmd.size() == 3;
+
+    std::ofstream out(file, std::ios::binary);
+    if (!out) throw std::runtime_error("Command .export-datalog: cannot open '" + file + "' for writing");
+
+    const io::DatalogCounts counts = io::export_datalog(_n, out, options);
+    _n->diagnostic("Exported " + std::to_string(counts.facts) + " facts and " + std::to_string(counts.rules) + " rules to " + file
+                       + (counts.skipped ? ", skipped " + std::to_string(counts.skipped) + " rules without a Datalog equivalent" : ""),
+                   true);
+}
+
+void console::CommandExecutor::Impl::cmd_bulk_load(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".bulk-load");
+    if (cmd.size() < 2 || cmd.size() > 3) throw std::runtime_error("Command .bulk-load requires a file name and an optional language");
+
+    std::ifstream in(cmd[1], std::ios::binary);
+    if (!in) throw std::runtime_error("Command .bulk-load: cannot open '" + cmd[1] + "'");
+
+    io::BulkOptions options;
+    if (cmd.size() == 3) options.lang = cmd[2];
+    options.progress = [this](const io::BulkProgress& p)
+    {
+        _n->diagnostic_stream() << p.lines << " lines, " << p.facts << " facts ("
+                                << std::fixed << std::setprecision(0) << p.facts_per_second() << " facts/s)" << std::endl;
+    };
+
+    io::BulkLoader loader(_n, options);
+    try
+    {
+        loader.feed(in);
+    }
+    catch (const std::exception& ex)
+    {
+        throw std::runtime_error("Command .bulk-load: " + cmd[1] + ", " + ex.what());
+    }
+    const io::BulkProgress& result = loader.finish();
+    _n->diagnostic("Loaded " + std::to_string(result.facts) + " facts from " + cmd[1], true);
+}
+
+void console::CommandExecutor::Impl::cmd_import_csv(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".import-csv");
+    if (cmd.size() < 5) throw std::runtime_error("Usage: .import-csv <file> <subject> <relation> <object> [options]");
+
+    io::CsvMapping mapping;
+    try
+    {
+        mapping = io::parse_csv_mapping(std::vector<std::string>(cmd.begin() + 2, cmd.end()));
+    }
+    catch (const std::exception& ex)
+    {
+        throw std::runtime_error(std::string("Command .import-csv: ") + ex.what());
+    }
+
+    std::ifstream in(cmd[1], std::ios::binary);
+    if (!in) throw std::runtime_error("Command .import-csv: cannot open '" + cmd[1] + "'");
+
+    network::Reasoning::OriginScope origin(*_n, {"csv", cmd[1]});
+    const std::string               file   = std::filesystem::path(cmd[1]).filename().string();
+    const io::CsvResult             result = io::import_csv(_n, in, mapping, file);
+
+    // Only the first errors, a wrong type mapping fails every row.
+    constexpr size_t shown = 20;
+    for (size_t i = 0; i < std::min(result.errors.size(), shown); ++i)
+    {
+        const io::CsvError& e = result.errors[i];
+        _n->diagnostic(file + ":" + std::to_string(e.line) + ": " + e.message + (e.column.empty() ? "" : " (column " + e.column + ")"), true);
+    }
+    if (result.errors.size() > shown)
+        _n->diagnostic("... and " + std::to_string(result.errors.size() - shown) + " more rows with errors", true);
+
+    _n->diagnostic("Imported " + std::to_string(result.facts) + " facts from " + std::to_string(result.rows) + " rows of " + cmd[1]
+                       + (result.skipped ? ", skipped " + std::to_string(result.skipped) + " rows with empty cells" : "")
+                       + (result.errors.empty() ? "" : ", " + std::to_string(result.errors.size()) + " rows with errors"),
+                   true);
+}
+
+void console::CommandExecutor::Impl::cmd_import_conceptnet(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".import-conceptnet");
+    if (cmd.size() < 2) throw std::runtime_error("Usage: .import-conceptnet <file> [options]");
+
+    io::ConceptNetOptions options;
+    try
+    {
+        options = io::parse_conceptnet_options(std::vector<std::string>(cmd.begin() + 2, cmd.end()));
+    }
+    catch (const std::exception& ex)
+    {
+        throw std::runtime_error(std::string("Command .import-conceptnet: ") + ex.what());
+    }
+    options.progress = [this](const io::BulkProgress& p)
+    {
+        _n->diagnostic_stream() << p.lines << " lines, " << p.facts << " facts ("
+                                << std::fixed << std::setprecision(0) << p.facts_per_second() << " facts/s)" << std::endl;
+    };
+
+    std::ifstream in(cmd[1], std::ios::binary);
+    if (!in) throw std::runtime_error("Command .import-conceptnet: cannot open '" + cmd[1] + "'");
+
+    const io::BulkProgress result = io::import_conceptnet(_n, in, options, std::filesystem::path(cmd[1]).filename().string());
+    _n->diagnostic("Imported " + std::to_string(result.facts) + " facts from " + std::to_string(result.lines) + " assertions of " + cmd[1], true);
+}
+
+void console::CommandExecutor::Impl::cmd_import_wordnet(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".import-wordnet");
+    if (cmd.size() < 2) throw std::runtime_error("Usage: .import-wordnet <dir|file> [options]");
+
+    io::WordNetOptions options;
+    try
+    {
+        options = io::parse_wordnet_options(std::vector<std::string>(cmd.begin() + 2, cmd.end()));
+    }
+    catch (const std::exception& ex)
+    {
+        throw std::runtime_error(std::string("Command .import-wordnet: ") + ex.what());
+    }
+
+    const std::vector<std::string> files = io::wordnet_data_files(cmd[1]);
+    if (files.empty()) throw std::runtime_error("Command .import-wordnet: no WordNet data files in '" + cmd[1] + "'");
+
+    uint64_t facts = 0;
+    for (const std::string& file : files)
+    {
+        std::ifstream in(file, std::ios::binary);
+        if (!in) throw std::runtime_error("Command .import-wordnet: cannot open '" + file + "'");
+        facts += io::import_wordnet(_n, in, options, std::filesystem::path(file).filename().string()).facts;
+    }
+    _n->diagnostic("Imported " + std::to_string(facts) + " facts from " + cmd[1], true);
+}
+
+void console::CommandExecutor::Impl::cmd_store(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 3) throw std::runtime_error("Usage: .store [<file> [cache]|off]");
+
+    if (cmd.size() >= 2)
+    {
+        if (cmd[1] == "off")
+        {
+            if (cmd.size() == 3) throw std::runtime_error("Usage: .store [<file> [cache]|off]");
+            _repl_state->fact_store.reset();
+        }
+        else
+        {
+            uint64_t cache = 64 << 20;
+            if (cmd.size() == 3)
+            {
+                try
+                {
+                    cache = parse_bytes(cmd[2]);
+                }
+                catch (...)
+                {
+                    throw std::runtime_error("Command .store: invalid cache size, expected bytes or a number with K, M or G.");
+                }
+            }
+            _repl_state->fact_store = std::make_shared<io::FactStore>(cmd[1], static_cast<size_t>(cache));
+        }
+    }
+
+    const auto& store = _repl_state->fact_store;
+    if (!store)
+    {
+        _n->out("No fact store.", true);
+        return;
+    }
+    const io::FactStoreStats stats = store->stats();
+    _n->out("Fact store " + store->file() + ": " + std::to_string(stats.facts) + " facts, " + std::to_string(stats.names) + " names; "
+                + std::to_string(stats.cached_pages) + " of " + std::to_string(stats.capacity_pages) + " pages cached, "
+                + std::to_string(stats.hits) + " hits, " + std::to_string(stats.misses) + " misses.",
+            true);
+}
+
+void console::CommandExecutor::Impl::cmd_store_create(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 2 || cmd.size() > 3) throw std::runtime_error("Usage: .store-create <file> [facts]");
+
+    if (cmd.size() == 3)
+    {
+        std::ifstream in(cmd[2], std::ios::binary);
+        if (!in) throw std::runtime_error("Command .store-create: cannot open '" + cmd[2] + "'");
+        uint64_t facts;
+        try
+        {
+            facts = io::write_fact_store(in, cmd[1]);
+        }
+        catch (const std::exception& ex)
+        {
+            throw std::runtime_error("Command .store-create: " + cmd[2] + ", " + ex.what());
+        }
+        _n->out("Wrote " + std::to_string(facts) + " facts to " + cmd[1] + ".", true);
+        return;
+    }
+
+    uint64_t       skipped = 0;
+    const uint64_t facts   = io::write_fact_store(_n, cmd[1], skipped);
+    _n->out("Wrote " + std::to_string(facts) + " facts to " + cmd[1]
+                + (skipped ? " (skipped " + std::to_string(skipped) + " with unnamed parts or several objects)." : "."),
+            true);
+}
+
+const io::FactStore& console::CommandExecutor::Impl::open_store(const char* command_name) const
+{
+    if (!_repl_state->fact_store)
+        throw std::runtime_error(std::string("Command ") + command_name + ": no fact store is open (see .store).");
+    return *_repl_state->fact_store;
+}
+
+std::string console::CommandExecutor::Impl::store_pattern_part(const std::string& part)
+{
+    return part == "*" ? "" : part;
+}
+
+void console::CommandExecutor::Impl::cmd_store_query(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 4 || cmd.size() > 5) throw std::runtime_error("Usage: .store-query <subject> <predicate> <object> [n]");
+
+    size_t limit = 0;
+    if (cmd.size() == 5)
+    {
+        try
+        {
+            size_t pos;
+            limit = std::stoull(cmd[4], &pos);
+            if (pos != cmd[4].size() || cmd[4][0] == '-' || limit == 0) throw std::invalid_argument(cmd[4]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .store-query: invalid number of facts.");
+        }
+    }
+
+    size_t count = 0;
+    open_store(".store-query").match(store_pattern_part(cmd[1]), store_pattern_part(cmd[2]), store_pattern_part(cmd[3]), [&](const io::StoredFact& fact)
+                                     {
+        _n->out(fact.subject + " " + fact.predicate + " " + fact.object, true);
+        return limit == 0 || ++count < limit; });
+}
+
+void console::CommandExecutor::Impl::cmd_store_import(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".store-import");
+    if (cmd.size() != 4) throw std::runtime_error("Usage: .store-import <subject> <predicate> <object>");
+
+    const uint64_t facts = io::import_from_store(_n, open_store(".store-import"), store_pattern_part(cmd[1]), store_pattern_part(cmd[2]), store_pattern_part(cmd[3]));
+    _n->out("Imported " + std::to_string(facts) + " facts.", true);
+}
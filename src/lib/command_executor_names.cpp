@@ -0,0 +1,372 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "network/reasoning.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <sstream>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_lang(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2)
+    {
+        _n->out_stream() << "The current language is '" << _n->get_lang() << "'" << std::endl;
+    }
+    else
+    {
+        _n->set_lang(cmd[1]);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_normalize(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .normalize [nfc|nfkc|none]");
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] == "nfc")
+            _n->set_name_normalization(string::unicode::Normalization::NFC);
+        else if (cmd[1] == "nfkc")
+            _n->set_name_normalization(string::unicode::Normalization::NFKC);
+        else if (cmd[1] == "none")
+            _n->set_name_normalization(string::unicode::Normalization::None);
+        else
+            throw std::runtime_error("Usage: .normalize [nfc|nfkc|none]");
+    }
+
+    switch (_n->name_normalization())
+    {
+    case string::unicode::Normalization::NFC:
+        _n->out("Name normalization: nfc", true);
+        break;
+    case string::unicode::Normalization::NFKC:
+        _n->out("Name normalization: nfkc", true);
+        break;
+    case string::unicode::Normalization::None:
+        _n->out("Name normalization: none", true);
+        break;
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_case_fold(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .case-fold [on|off]");
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] == "on")
+            _n->set_case_folding(true);
+        else if (cmd[1] == "off")
+            _n->set_case_folding(false);
+        else
+            throw std::runtime_error("Usage: .case-fold [on|off]");
+    }
+
+    _n->out(std::string("Case folding: ") + (_n->case_folding() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_quotes(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() == 2 && cmd[1] == "default")
+    {
+        _script_engine->set_quote_pairs({});
+    }
+    else if (cmd.size() > 1)
+    {
+        if (cmd.size() % 2 == 0)
+            throw std::runtime_error("Usage: .quotes [<open> <close> ...|default]");
+
+        std::vector<std::pair<std::string, std::string>> pairs;
+        for (size_t i = 1; i < cmd.size(); i += 2)
+            pairs.emplace_back(cmd[i], cmd[i + 1]);
+
+        try
+        {
+            _script_engine->set_quote_pairs(pairs);
+        }
+        catch (const std::invalid_argument& e)
+        {
+            throw std::runtime_error(std::string("Command .quotes: ") + e.what());
+        }
+    }
+
+    std::string text = "Quotes: \"...\"";
+    for (const auto& [open, close] : _script_engine->quote_pairs())
+        text += " " + open + "..." + close;
+    _n->out(text, true);
+}
+
+void console::CommandExecutor::Impl::cmd_name(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".name");
+    if (cmd.size() < 3 || cmd.size() > 4)
+        throw std::runtime_error("Command .name: Invalid arguments. Usage: .name <node> <new_name>  or  .name <node> <lang> <new_name>");
+
+    const std::string& name_in_current_lang = cmd[1];
+    const std::string& name_in_target_lang  = cmd.size() == 3 ? cmd[2] : cmd[3];
+    std::string        current_lang         = _n->get_lang();
+    std::string        target_lang          = cmd.size() == 3 ? _n->lang() : cmd[2];
+
+    network::Node node_in_current_lang = resolve_node(name_in_current_lang, current_lang);
+    network::Node node_in_target_lang  = resolve_node(name_in_target_lang, target_lang);
+
+    if (current_lang == target_lang)
+    {
+        // In this case, name_in_current_lang is strictly interpreted as the old name that we use to reference
+        // the existing node. It does not make sense to support creating a new node in this mode.
+        if (node_in_current_lang == 0)
+        {
+            throw std::runtime_error("Node '" + name_in_current_lang + "' does not exist");
+        }
+        else if (node_in_target_lang != 0)
+        {
+            throw std::runtime_error("Name '" + name_in_target_lang + "' is already in use by node " + std::to_string(node_in_target_lang));
+        }
+        else
+        {
+            _n->set_name(node_in_current_lang, name_in_target_lang, target_lang, true);
+        }
+    }
+    else if (node_in_current_lang == 0)
+    {
+        if (node_in_target_lang == 0)
+        {
+            node_in_current_lang = _n->node(name_in_current_lang);
+            _n->set_name(node_in_current_lang, name_in_target_lang, target_lang, true);
+            _n->out("Node '" + name_in_current_lang + "' ('" + current_lang + "') / '" + name_in_target_lang + "' ('" + target_lang + "') does not exist yet in either language => created it.", true);
+        }
+        else
+        {
+            _n->set_name(node_in_target_lang, name_in_current_lang, current_lang, true);
+            _n->out("Node '" + name_in_target_lang + "' ('" + target_lang + "') exists, assigned name '" + name_in_current_lang + "' in '" + current_lang + "'.", true);
+        }
+    }
+    else if (node_in_target_lang == 0)
+    {
+        _n->set_name(node_in_current_lang, name_in_target_lang, target_lang, true);
+        _n->out("Node '" + name_in_current_lang + "' ('" + current_lang + "') exists, assigned name '" + name_in_target_lang + "' in '" + target_lang + "'.", true);
+    }
+    else if (name_in_current_lang == _n->get_name(node_in_current_lang, current_lang, false) && name_in_target_lang == _n->get_name(node_in_target_lang, target_lang, false))
+    {
+        _n->out("Node '" + name_in_current_lang + "' ('" + current_lang + "') / '" + name_in_target_lang + "' ('" + target_lang + "') have the requested names, but are different nodes => Merging them.", true);
+        _n->set_name(node_in_current_lang, name_in_target_lang, target_lang, true);
+    }
+    else
+    {
+        throw std::runtime_error("Node '" + name_in_current_lang + "' ('" + current_lang + "') / '" + name_in_target_lang + "' ('" + target_lang + "') exists in both languages as different nodes => did not do anything)");
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_delname(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".delname");
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Command .delname: Invalid arguments. Usage: .delname <node|id> [lang]");
+
+    network::Node nd = resolve_single_node(cmd[1], true); // prioritize ID
+
+    std::string target_lang = _n->lang();
+    if (cmd.size() == 3)
+    {
+        target_lang = cmd[2];
+    }
+
+    _n->remove_name(nd, target_lang);
+
+    _n->out("Removed name of node " + std::to_string(nd) + " in language '" + target_lang + "' (if it existed).", true);
+}
+
+void console::CommandExecutor::Impl::cmd_suggest(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .suggest [on|off]");
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] == "on")
+            _n->set_suggest_concepts(true);
+        else if (cmd[1] == "off")
+            _n->set_suggest_concepts(false);
+        else
+            throw std::runtime_error("Usage: .suggest [on|off]");
+    }
+
+    _n->out(std::string("Concept suggestions: ") + (_n->suggest_concepts() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_no_matches(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .no-matches [on|off]");
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] == "on")
+            _n->set_report_no_matches(true);
+        else if (cmd[1] == "off")
+            _n->set_report_no_matches(false);
+        else
+            throw std::runtime_error("Usage: .no-matches [on|off]");
+    }
+
+    _n->out(std::string("No-match reports: ") + (_n->report_no_matches() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_search(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2 || cmd.size() > 4)
+        throw std::runtime_error("Usage: .search <text> [n] [fuzzy]");
+
+    size_t limit = 10;
+    bool   fuzzy = false;
+    for (size_t i = 2; i < cmd.size(); ++i)
+    {
+        if (cmd[i] == "fuzzy")
+        {
+            fuzzy = true;
+            continue;
+        }
+        try
+        {
+            limit = std::stoul(cmd[i]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Usage: .search <text> [n] [fuzzy]");
+        }
+    }
+
+    const auto matches = _n->search_names(cmd[1], limit, fuzzy);
+    if (matches.empty())
+    {
+        _n->out("No concept name matches '" + cmd[1] + "'.", true);
+        return;
+    }
+
+    for (const auto& match : matches)
+    {
+        std::string kind;
+        switch (match.kind)
+        {
+        case network::NameIndex::MatchKind::Exact:
+            kind = "exact";
+            break;
+        case network::NameIndex::MatchKind::Prefix:
+            kind = "prefix";
+            break;
+        case network::NameIndex::MatchKind::Substring:
+            kind = "substring";
+            break;
+        case network::NameIndex::MatchKind::Fuzzy:
+            kind = "fuzzy, " + std::to_string(match.distance) + (match.distance == 1 ? " edit" : " edits");
+            break;
+        }
+        _n->out(match.name + " [" + std::to_string(match.node) + "] (" + kind + ")", true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_embedding(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2)
+        throw std::runtime_error("Usage: .embedding <node|id> [v...|none]");
+
+    const network::Node nd = resolve_node(cmd[1], _n->lang());
+    if (nd == 0) throw std::runtime_error("Unknown node '" + cmd[1] + "'");
+
+    if (cmd.size() == 3 && cmd[2] == "none")
+    {
+        _n->out(_n->remove_embedding(nd) ? "Removed the embedding of node " + std::to_string(nd) + "."
+                                         : "Node " + std::to_string(nd) + " has no embedding.",
+                true);
+        return;
+    }
+
+    if (cmd.size() > 2)
+    {
+        std::vector<float> values;
+        for (size_t i = 2; i < cmd.size(); ++i)
+        {
+            try
+            {
+                size_t pos = 0;
+                values.push_back(std::stof(cmd[i], &pos));
+                if (pos != cmd[i].size()) throw std::invalid_argument(cmd[i]);
+            }
+            catch (...)
+            {
+                throw std::runtime_error("Not a number: '" + cmd[i] + "'");
+            }
+        }
+        _n->set_embedding(nd, std::move(values));
+    }
+
+    const std::vector<float> values = _n->embedding(nd);
+    if (values.empty())
+    {
+        _n->out("Node " + std::to_string(nd) + " has no embedding.", true);
+        return;
+    }
+
+    std::ostringstream text;
+    text << "Embedding of node " << nd << " (dimension " << values.size() << "):";
+    for (float v : values)
+        text << ' ' << v;
+    _n->out(text.str(), true);
+}
+
+void console::CommandExecutor::Impl::cmd_similar(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Usage: .similar <node|id> [k]");
+
+    const network::Node nd = resolve_node(cmd[1], _n->lang());
+    if (nd == 0) throw std::runtime_error("Unknown node '" + cmd[1] + "'");
+
+    const size_t k       = cmd.size() == 3 ? string::parse_count(cmd[2]) : 10;
+    const auto   similar = _n->similar_nodes(nd, k);
+    if (similar.empty())
+    {
+        _n->out("No other node has an embedding.", true);
+        return;
+    }
+
+    for (const auto& [node, similarity] : similar)
+    {
+        std::string name;
+        string::node_to_string(_n, name, _n->lang(), node, 3);
+        std::ostringstream line;
+        line << string::unmark_identifiers(name) << " [" << node << "] (similarity " << similarity << ")";
+        _n->out(line.str(), true);
+    }
+}
@@ -0,0 +1,862 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "io/data_manager.hpp"
+#include "io/owl.hpp"
+#include "network/reasoning.hpp"
+#include "string/string_utils.hpp"
+
+#ifndef __EMSCRIPTEN__
+    #include "io/cypher.hpp"
+    #include "io/jsonld.hpp"
+    #include "io/rdf.hpp"
+    #include "wikidata/wikidata.hpp"
+    #include "wikidata/wikidata_text_compressor.hpp"
+
+    #include "zelph.capnp.h"
+
+    #include <capnp/message.h>
+    #include <capnp/serialize-packed.h>
+    #include <kj/io.h>
+#endif
+
+#include <cstdio>
+#include <filesystem>
+#include <fstream>
+#include <iomanip>
+#include <limits>
+#include <sstream>
+
+using namespace zelph;
+
+#ifndef __EMSCRIPTEN__
+::capnp::ReaderOptions console::CommandExecutor::Impl::make_bin_reader_options()
+{
+    ::capnp::ReaderOptions options;
+    options.traversalLimitInWords = 1ULL << 32;
+    options.nestingLimit          = 128;
+    return options;
+}
+
+console::CommandExecutor::Impl::BinHeaderStats console::CommandExecutor::Impl::read_bin_header_stats(const std::string& filename)
+{
+    FILE* file = fopen(filename.c_str(), "rb");
+    if (!file)
+    {
+        throw std::runtime_error("Command .stat-file: Failed to open file '" + filename + "'");
+    }
+
+    try
+    {
+        BinHeaderStats stats;
+        stats.file_size_bytes = std::filesystem::file_size(filename);
+
+        kj::FdInputStream              raw_input(fileno(file));
+        kj::BufferedInputStreamWrapper buffered_input(raw_input);
+
+        ::capnp::ReaderOptions options;
+        options.traversalLimitInWords = 1ULL << 32;
+        options.nestingLimit          = 128;
+
+        ::capnp::PackedMessageReader main_message(buffered_input, options);
+        auto                         impl = main_message.getRoot<zelph::network::ZelphImpl>();
+
+        stats.left_chunk_count   = impl.getLeftChunkCount();
+        stats.right_chunk_count  = impl.getRightChunkCount();
+        stats.name_of_node_count = impl.getNameOfNodeChunkCount();
+        stats.node_of_name_count = impl.getNodeOfNameChunkCount();
+        stats.format_version     = impl.getFormatVersion();
+
+        fclose(file);
+        return stats;
+    }
+    catch (...)
+    {
+        fclose(file);
+        throw;
+    }
+}
+
+console::CommandExecutor::Impl::BinIndexData console::CommandExecutor::Impl::read_bin_index_data(const std::string& filename)
+{
+    FILE* file = fopen(filename.c_str(), "rb");
+    if (!file)
+    {
+        throw std::runtime_error("Command .index-file: Failed to open file '" + filename + "'");
+    }
+
+    try
+    {
+        BinIndexData data;
+        data.filename              = filename;
+        data.stats.file_size_bytes = std::filesystem::file_size(filename);
+
+        kj::FdInputStream           raw_input(fileno(file));
+        CountingBufferedInputStream counting_input(raw_input);
+        auto                        options = make_bin_reader_options();
+
+        // A PackedMessageReader reads its segments lazily. Here we only touch the
+        // root struct (chunkIndex / which / lang), which lives in segment 0, so
+        // any further segments stay unread until the reader is destroyed. The
+        // destructor then skips the remaining segments to position the stream at
+        // the start of the next message. We must therefore measure the consumed
+        // byte count *after* the reader has been destroyed; measuring it while
+        // the reader is still alive yields a length that only covers segment 0,
+        // which produces a gap before the next chunk for large multi-segment
+        // chunks (those exceeding the 512 MiB first segment of saveToFile's
+        // MallocMessageBuilder(1u << 26)).
+        uint64_t header_offset = counting_input.bytes_read();
+        {
+            ::capnp::PackedMessageReader main_message(counting_input, options);
+            auto                         impl = main_message.getRoot<zelph::network::ZelphImpl>();
+            data.stats.left_chunk_count       = impl.getLeftChunkCount();
+            data.stats.right_chunk_count      = impl.getRightChunkCount();
+            data.stats.name_of_node_count     = impl.getNameOfNodeChunkCount();
+            data.stats.node_of_name_count     = impl.getNodeOfNameChunkCount();
+        }
+        data.header_length_bytes = counting_input.bytes_read() - header_offset;
+
+        auto read_adj_chunks = [&](uint32_t                  count,
+                                   std::vector<BinChunkRef>& target)
+        {
+            target.reserve(count);
+            for (uint32_t i = 0; i < count; ++i)
+            {
+                uint64_t    before = counting_input.bytes_read();
+                BinChunkRef ref;
+                ref.offset = before;
+                {
+                    ::capnp::PackedMessageReader chunk_message(counting_input, options);
+                    auto                         chunk = chunk_message.getRoot<zelph::network::AdjChunk>();
+                    ref.chunk_index                    = chunk.getChunkIndex();
+                    ref.which                          = chunk.getWhich().cStr();
+                }
+                // Measured after chunk_message is destroyed: the destructor has now
+                // skipped all remaining segments of this message.
+                ref.length = counting_input.bytes_read() - before;
+                target.push_back(std::move(ref));
+            }
+        };
+
+        read_adj_chunks(data.stats.left_chunk_count, data.left_chunks);
+        read_adj_chunks(data.stats.right_chunk_count, data.right_chunks);
+
+        data.name_of_node_chunks.reserve(data.stats.name_of_node_count);
+        for (uint32_t i = 0; i < data.stats.name_of_node_count; ++i)
+        {
+            uint64_t    before = counting_input.bytes_read();
+            BinChunkRef ref;
+            ref.offset = before;
+            {
+                ::capnp::PackedMessageReader chunk_message(counting_input, options);
+                auto                         chunk = chunk_message.getRoot<zelph::network::NameChunk>();
+                ref.chunk_index                    = chunk.getChunkIndex();
+                ref.lang                           = chunk.getLang().cStr();
+            }
+            ref.length = counting_input.bytes_read() - before;
+            data.name_of_node_chunks.push_back(std::move(ref));
+        }
+
+        data.node_of_name_chunks.reserve(data.stats.node_of_name_count);
+        for (uint32_t i = 0; i < data.stats.node_of_name_count; ++i)
+        {
+            uint64_t    before = counting_input.bytes_read();
+            BinChunkRef ref;
+            ref.offset = before;
+            {
+                ::capnp::PackedMessageReader chunk_message(counting_input, options);
+                auto                         chunk = chunk_message.getRoot<zelph::network::NodeNameChunk>();
+                ref.chunk_index                    = chunk.getChunkIndex();
+                ref.lang                           = chunk.getLang().cStr();
+            }
+            ref.length = counting_input.bytes_read() - before;
+            data.node_of_name_chunks.push_back(std::move(ref));
+        }
+
+        fclose(file);
+        return data;
+    }
+    catch (...)
+    {
+        fclose(file);
+        throw;
+    }
+}
+
+void console::CommandExecutor::Impl::write_bin_index_json(const BinIndexData& data, const std::string& output_filename)
+{
+    std::ofstream out(output_filename);
+    if (!out.is_open())
+    {
+        throw std::runtime_error("Command .index-file: Failed to open output file '" + output_filename + "'");
+    }
+
+    auto escape_json_string = [](const std::string& value)
+    {
+        std::ostringstream escaped;
+        for (unsigned char ch : value)
+        {
+            switch (ch)
+            {
+            case '\"':
+                escaped << "\\\"";
+                break;
+            case '\\':
+                escaped << "\\\\";
+                break;
+            case '\b':
+                escaped << "\\b";
+                break;
+            case '\f':
+                escaped << "\\f";
+                break;
+            case '\n':
+                escaped << "\\n";
+                break;
+            case '\r':
+                escaped << "\\r";
+                break;
+            case '\t':
+                escaped << "\\t";
+                break;
+            default:
+                if (ch < 0x20)
+                {
+                    escaped << "\\u"
+                            << std::hex
+                            << std::setw(4)
+                            << std::setfill('0')
+                            << static_cast<int>(ch)
+                            << std::dec
+                            << std::setfill(' ');
+                }
+                else
+                {
+                    escaped << static_cast<char>(ch);
+                }
+                break;
+            }
+        }
+        return escaped.str();
+    };
+
+    auto write_chunk_array = [&](const char* key, const std::vector<BinChunkRef>& refs)
+    {
+        out << "  \"" << key << "\": [\n";
+        for (size_t i = 0; i < refs.size(); ++i)
+        {
+            const auto& ref = refs[i];
+            out << "    {\"chunkIndex\":" << ref.chunk_index
+                << ",\"offset\":" << ref.offset
+                << ",\"length\":" << ref.length;
+            if (!ref.which.empty())
+            {
+                out << ",\"which\":\"" << escape_json_string(ref.which) << "\"";
+            }
+            if (!ref.lang.empty())
+            {
+                out << ",\"lang\":\"" << escape_json_string(ref.lang) << "\"";
+            }
+            out << "}";
+            if (i + 1 < refs.size())
+            {
+                out << ",";
+            }
+            out << "\n";
+        }
+        out << "  ]";
+    };
+
+    out << "{\n";
+    out << "  \"file\":\"" << escape_json_string(data.filename) << "\",\n";
+    out << "  \"header\":{\"offset\":0,\"length\":" << data.header_length_bytes << "},\n";
+    write_chunk_array("left", data.left_chunks);
+    out << ",\n";
+    write_chunk_array("right", data.right_chunks);
+    out << ",\n";
+    write_chunk_array("nameOfNode", data.name_of_node_chunks);
+    out << ",\n";
+    write_chunk_array("nodeOfName", data.node_of_name_chunks);
+    out << "\n}\n";
+}
+
+std::vector<uint32_t> console::CommandExecutor::Impl::parse_chunk_index_list(const std::string& value, const std::string& label)
+{
+    std::vector<uint32_t> indices;
+    if (value.empty() || value == "-" || value == "none")
+    {
+        return indices;
+    }
+
+    std::stringstream stream(value);
+    std::string       token;
+    while (std::getline(stream, token, ','))
+    {
+        if (token.empty())
+        {
+            continue;
+        }
+        const auto first_non_space = token.find_first_not_of(" \t");
+        if (first_non_space != std::string::npos)
+        {
+            const auto last_non_space = token.find_last_not_of(" \t");
+            token                     = token.substr(first_non_space, last_non_space - first_non_space + 1);
+        }
+        if (token.empty())
+        {
+            continue;
+        }
+        if (token == "-" || token == "none")
+        {
+            throw std::runtime_error("Invalid chunk selector '" + token + "' in " + label);
+        }
+
+        try
+        {
+            size_t pos = 0;
+            if (token[0] == '-')
+            {
+                throw std::runtime_error("");
+            }
+            uint64_t parsed = std::stoull(token, &pos, 10);
+            if (pos != token.size())
+            {
+                throw std::runtime_error("");
+            }
+            if (parsed > std::numeric_limits<uint32_t>::max())
+            {
+                throw std::runtime_error("");
+            }
+            uint32_t index = static_cast<uint32_t>(parsed);
+            indices.push_back(index);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Invalid chunk index '" + token + "' in " + label);
+        }
+    }
+
+    return indices;
+}
+
+std::vector<uint64_t> console::CommandExecutor::Impl::parse_node_id_list(const std::string& value, const std::string& label)
+{
+    std::vector<uint64_t> ids;
+    if (value.empty() || value == "-" || value == "none")
+    {
+        return ids;
+    }
+
+    std::stringstream stream(value);
+    std::string       token;
+    while (std::getline(stream, token, ','))
+    {
+        if (token.empty())
+        {
+            continue;
+        }
+        const auto first_non_space = token.find_first_not_of(" \t");
+        if (first_non_space != std::string::npos)
+        {
+            const auto last_non_space = token.find_last_not_of(" \t");
+            token                     = token.substr(first_non_space, last_non_space - first_non_space + 1);
+        }
+        if (token.empty())
+        {
+            continue;
+        }
+        if (token == "-" || token == "none")
+        {
+            throw std::runtime_error("Invalid node-route selector '" + token + "' in " + label);
+        }
+
+        try
+        {
+            size_t pos = 0;
+            if (token[0] == '-')
+            {
+                throw std::runtime_error("");
+            }
+            uint64_t id = std::stoull(token, &pos, 10);
+            if (pos != token.size())
+            {
+                throw std::runtime_error("");
+            }
+            ids.push_back(id);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Invalid node ID '" + token + "' in " + label);
+        }
+    }
+
+    return ids;
+}
+
+void console::CommandExecutor::Impl::cmd_run_file(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".run-file");
+    if (cmd.size() != 2)
+        throw std::runtime_error("Command .run-file requires exactly one argument: the output file path");
+    const std::string& outfile = cmd[1];
+    std::ofstream      out(outfile);
+    if (!out.is_open())
+        throw std::runtime_error("Command .run-file: Cannot open output file '" + outfile + "'");
+    out << std::unitbuf;
+
+    zelph::wikidata::WikidataTextCompressor compressor({U' ', U'\t', U'\n', U','});
+    bool                                    is_wikidata = (_n->get_lang() == "wikidata");
+
+    auto original_handler = _n->get_output_handler();
+
+    std::mutex file_mtx;
+
+    _n->set_output_handler(
+        [&](const zelph::io::OutputEvent& event)
+        {
+            // Always forward to original handler (console output)
+            original_handler(event);
+
+            // Additionally intercept Out channel for file writing
+            if (event.channel != zelph::io::OutputChannel::Out)
+                return;
+
+            const std::string& str = event.text;
+            size_t             pos = str.find(" ⇐ ");
+            if (pos == std::string::npos)
+                return;
+
+            std::string deduction = str.substr(0, pos);
+            string::trim_in_place(deduction);
+
+            std::string reasons = str.substr(pos + std::string(" ⇐ ").size());
+            string::trim_in_place(reasons);
+            if (!reasons.empty() && reasons.front() == '(')
+                reasons.erase(0, 1);
+            if (!reasons.empty() && reasons.back() == ')')
+                reasons.erase(reasons.size() - 1);
+            string::trim_in_place(reasons);
+
+            string::replace_all(reasons, "(", "");
+            string::replace_all(reasons, ")", "");
+            string::replace_all(deduction, "«", "");
+            string::replace_all(deduction, "»", "");
+            string::replace_all(reasons, "«", "");
+            string::replace_all(reasons, "»", "");
+
+            std::string line_for_file;
+            if (!reasons.empty())
+                line_for_file = reasons + " ⇒ " + deduction;
+            else
+                line_for_file = deduction;
+            string::trim_in_place(line_for_file);
+
+            std::lock_guard lock(file_mtx);
+            if (is_wikidata)
+                out << compressor.encode(line_for_file) << '\n';
+            else
+                out << line_for_file << '\n';
+        });
+
+    _n->diagnostic(
+        "Starting full inference in encode mode – deduced facts (reversed order, no brackets/markup) will be written to "
+            + outfile + (is_wikidata ? " (with Wikidata token encoding)." : " (plain text)."),
+        true);
+
+    _n->run(true, false, false);
+
+    _n->set_output_handler(std::move(original_handler));
+    _n->diagnostic("Ready.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_decode(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 2)
+        throw std::runtime_error("Command .decode requires exactly one argument: the input file path");
+
+    const std::string& infile = cmd[1];
+    std::ifstream      in(infile);
+    if (!in.is_open())
+        throw std::runtime_error("Command .decode: Cannot open input file '" + infile + "'");
+
+    zelph::wikidata::WikidataTextCompressor compressor({U' ', U'\t', U'\n', U','});
+
+    std::string line;
+    while (std::getline(in, line))
+    {
+        if (!line.empty())
+        {
+            std::string decoded = compressor.decode(line);
+            _n->out_stream() << decoded << std::endl;
+        }
+    }
+}
+
+std::string console::CommandExecutor::Impl::data_source(const io::DataType type)
+{
+    switch (type)
+    {
+    case io::DataType::Wikidata:
+        return "wikidata";
+    case io::DataType::Rdf:
+        return "rdf";
+    case io::DataType::JsonLd:
+        return "jsonld";
+    default:
+        return "zelph";
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_load(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2) throw std::runtime_error("Command .load: Missing bin, json or RDF file name");
+
+    // Wikidata import filters: lang=<code>[,<code>...] properties=<P-id>[,<P-id>...]
+    wikidata::Wikidata::ImportFilter filter;
+    bool                             filtered = false;
+    bool                             owl      = false;
+    for (size_t i = 2; i < cmd.size(); ++i)
+    {
+        const std::string& arg = cmd[i];
+        if (arg == "owl")
+        {
+            owl = true;
+            continue;
+        }
+        const size_t       eq  = arg.find('=');
+        const std::string  key = eq == std::string::npos ? arg : arg.substr(0, eq);
+        if (key != "lang" && key != "properties") throw std::runtime_error("Command .load: Unknown argument after file name: " + arg);
+
+        std::vector<std::string> values;
+        std::stringstream        list(eq == std::string::npos ? "" : arg.substr(eq + 1));
+        for (std::string value; std::getline(list, value, ',');)
+            if (!value.empty()) values.push_back(value);
+
+        if (key == "lang")
+        {
+            filter.label_languages = values;
+        }
+        else
+        {
+            if (values.empty()) throw std::runtime_error("Command .load: properties= needs at least one property ID");
+            filter.properties.insert(values.begin(), values.end());
+        }
+        filtered = true;
+    }
+
+    if (_repl_state->auto_run)
+    {
+        _repl_state->auto_run = false;
+        _n->out("Auto-run has been disabled due to loading a large dataset.", true);
+    }
+
+    chrono::StopWatch watch;
+    watch.start();
+
+    // This detects if it's Wikidata (json/bz2 OR bin with source) or Generic (bin only)
+    _data_manager = io::DataManager::create(_n, cmd[1]);
+    if (filtered)
+    {
+        auto wikidata = std::dynamic_pointer_cast<wikidata::Wikidata>(_data_manager);
+        if (!wikidata) throw std::runtime_error("Command .load: lang= and properties= only apply to Wikidata dumps");
+        wikidata->set_import_filter(std::move(filter));
+    }
+    if (owl && _data_manager->get_type() != io::DataType::Rdf && _data_manager->get_type() != io::DataType::JsonLd)
+        throw std::runtime_error("Command .load: owl only applies to RDF files");
+    {
+        network::Reasoning::OriginScope origin(*_n, {data_source(_data_manager->get_type()), cmd[1]});
+        _data_manager->load();
+    }
+    if (owl) report_owl_rules(io::owl_rules(_n));
+    _repl_state->partial_load_mode   = false;
+    _repl_state->partial_load_source = "";
+
+    watch.stop();
+    _n->diagnostic(" Time needed for loading/importing: " + watch.format(), true);
+}
+
+void console::CommandExecutor::Impl::cmd_load_partial(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2)
+        throw std::runtime_error("Command .load-partial: Missing .bin file name or manifest");
+
+    const std::string& first_arg          = cmd[1];
+    bool               use_manifest       = !first_arg.ends_with(".bin");
+    std::string        source_or_manifest = first_arg;
+    std::string        source_bin_override;
+    std::string        shard_root;
+
+    if (!use_manifest && !std::filesystem::exists(first_arg))
+    {
+        throw std::runtime_error("Command .load-partial: Cannot open input file '" + first_arg + "'");
+    }
+
+    network::Zelph::BinChunkSelection selection;
+    bool                              meta_only = false;
+
+    for (size_t i = 2; i < cmd.size(); ++i)
+    {
+        const std::string& arg = cmd[i];
+        if (arg == "meta-only")
+        {
+            meta_only = true;
+            continue;
+        }
+
+        auto eq = arg.find('=');
+        if (eq == std::string::npos)
+        {
+            throw std::runtime_error("Command .load-partial: Unknown argument '" + arg + "'");
+        }
+
+        std::string key   = arg.substr(0, eq);
+        std::string value = arg.substr(eq + 1);
+
+        if (key == "left")
+        {
+            selection.left          = parse_chunk_index_list(value, "left");
+            selection.left_explicit = true;
+        }
+        else if (key == "right")
+        {
+            selection.right          = parse_chunk_index_list(value, "right");
+            selection.right_explicit = true;
+        }
+        else if (key == "nameOfNode" || key == "name")
+        {
+            selection.nameOfNode            = parse_chunk_index_list(value, "nameOfNode");
+            selection.name_of_node_explicit = true;
+        }
+        else if (key == "nodeOfName" || key == "node-name")
+        {
+            selection.nodeOfName            = parse_chunk_index_list(value, "nodeOfName");
+            selection.node_of_name_explicit = true;
+        }
+        else if (key == "route-node" || key == "route_node")
+        {
+            selection.route_nodes          = parse_node_id_list(value, "route-node");
+            selection.route_nodes_explicit = true;
+        }
+        else if (key == "route-name" || key == "route_name")
+        {
+            selection.route_name          = value;
+            selection.route_name_explicit = true;
+        }
+        else if (key == "route-lang" || key == "route_lang")
+        {
+            selection.route_lang = value;
+        }
+        else if (key == "manifest")
+        {
+            use_manifest       = true;
+            source_or_manifest = value;
+        }
+        else if (key == "source-bin" || key == "source_bin")
+        {
+            source_bin_override = value;
+        }
+        else if (key == "shard-root" || key == "shard_root")
+        {
+            shard_root = value;
+        }
+        else
+        {
+            throw std::runtime_error("Command .load-partial: Unknown selector '" + key + "'");
+        }
+    }
+
+    if (use_manifest && source_bin_override.empty() && first_arg.ends_with(".bin"))
+    {
+        source_bin_override = first_arg;
+    }
+
+    if ((selection.route_nodes_explicit || selection.route_name_explicit) && !use_manifest)
+    {
+        throw std::runtime_error("Command .load-partial: route selectors require manifest mode");
+    }
+
+    if (selection.route_name_explicit && selection.route_lang.empty())
+    {
+        throw std::runtime_error("Command .load-partial: route-name requires route-lang=<lang>");
+    }
+
+    if (meta_only)
+    {
+        selection = {};
+    }
+
+    if (_repl_state->auto_run)
+    {
+        _repl_state->auto_run = false;
+        _n->out("Auto-run has been disabled due to partial loading.", true);
+    }
+
+    chrono::StopWatch watch;
+    watch.start();
+    if (use_manifest)
+    {
+        _n->load_from_manifest(source_or_manifest, selection, shard_root, source_bin_override, meta_only);
+    }
+    else
+    {
+        _n->load_from_file(source_or_manifest, selection, meta_only);
+    }
+    watch.stop();
+
+    _data_manager                    = nullptr;
+    _repl_state->partial_load_mode   = true;
+    _repl_state->partial_load_source = source_or_manifest;
+    _n->out("WARNING: partial/incomplete graph loaded; reasoning, pruning, cleanup, and destructive edits are blocked.", true);
+    _n->diagnostic(" Time needed for partial loading: " + watch.format(), true);
+}
+
+void console::CommandExecutor::Impl::cmd_stat_file(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 2) throw std::runtime_error("Command .stat-file requires exactly one argument: the input .bin file");
+
+    const std::string& filename     = cmd[1];
+    BinHeaderStats     stats        = read_bin_header_stats(filename);
+    uint64_t           total_chunks = static_cast<uint64_t>(stats.left_chunk_count)
+                                    + static_cast<uint64_t>(stats.right_chunk_count)
+                                    + static_cast<uint64_t>(stats.name_of_node_count)
+                                    + static_cast<uint64_t>(stats.node_of_name_count);
+
+    _n->out_stream() << "Serialized File Statistics:" << std::endl;
+    _n->out_stream() << "------------------------" << std::endl;
+    _n->out_stream() << "File: " << filename << std::endl;
+    _n->out_stream() << "File Size: " << stats.file_size_bytes << " bytes" << std::endl;
+    _n->out_stream() << "Format Version: " << (stats.format_version == 0 ? "unversioned" : std::to_string(stats.format_version)) << std::endl;
+    _n->out_stream() << "Left Chunks: " << stats.left_chunk_count << std::endl;
+    _n->out_stream() << "Right Chunks: " << stats.right_chunk_count << std::endl;
+    _n->out_stream() << "Name-of-Node Chunks: " << stats.name_of_node_count << std::endl;
+    _n->out_stream() << "Node-of-Name Chunks: " << stats.node_of_name_count << std::endl;
+    _n->out_stream() << "Total Chunks: " << total_chunks << std::endl;
+    _n->out_stream() << "------------------------" << std::endl;
+}
+
+void console::CommandExecutor::Impl::cmd_index_file(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 3) throw std::runtime_error("Command .index-file requires exactly two arguments: the input .bin file and output .json file");
+
+    BinIndexData data = read_bin_index_data(cmd[1]);
+    write_bin_index_json(data, cmd[2]);
+    _n->out("Wrote byte-offset index to " + cmd[2], true);
+}
+
+void console::CommandExecutor::Impl::cmd_save(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".save");
+    if (cmd.size() != 2)
+        throw std::runtime_error("Command .save requires exactly one argument: the output file (must end with .bin)");
+
+    const std::string& file = cmd[1];
+    if (!file.ends_with(".bin"))
+        throw std::runtime_error("Command .save: filename must end with '.bin'");
+
+    _n->save_to_file(file);
+    _n->diagnostic("Saved network to " + file, true);
+}
+
+void console::CommandExecutor::Impl::cmd_export_rdf(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".export-rdf");
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Command .export-rdf requires the output file (.nt or .nq) and an optional base IRI");
+
+    const std::string& file  = cmd[1];
+    const bool         quads = file.ends_with(".nq");
+    if (!quads && !file.ends_with(".nt"))
+        throw std::runtime_error("Command .export-rdf: filename must end with '.nt' or '.nq'");
+
+    std::ofstream out(file, std::ios::binary);
+    if (!out) throw std::runtime_error("Command .export-rdf: cannot open '" + file + "' for writing");
+
+    std::function<bool(network::Node)> is_deduced;
+    if (quads) is_deduced = [this](network::Node fact)
+    { return _n->is_deduced(fact); };
+
+    const size_t lines = io::export_rdf(_n, out, cmd.size() == 3 ? cmd[2] : "urn:zelph:", is_deduced);
+    _n->diagnostic("Exported " + std::to_string(lines) + " triples to " + file, true);
+}
+
+void console::CommandExecutor::Impl::cmd_export_jsonld(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".export-jsonld");
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Command .export-jsonld requires the output file (.jsonld) and an optional context file");
+
+    const std::string& file = cmd[1];
+    if (!file.ends_with(".jsonld"))
+        throw std::runtime_error("Command .export-jsonld: filename must end with '.jsonld'");
+
+    std::string context;
+    if (cmd.size() == 3)
+    {
+        std::ifstream in(cmd[2], std::ios::binary);
+        if (!in) throw std::runtime_error("Command .export-jsonld: cannot open context file '" + cmd[2] + "'");
+        context.assign(std::istreambuf_iterator<char>(in), std::istreambuf_iterator<char>());
+    }
+
+    std::ofstream out(file, std::ios::binary);
+    if (!out) throw std::runtime_error("Command .export-jsonld: cannot open '" + file + "' for writing");
+
+    const size_t triples = io::export_jsonld(_n, out, context);
+    _n->diagnostic("Exported " + std::to_string(triples) + " triples to " + file, true);
+}
+
+void console::CommandExecutor::Impl::cmd_export_cypher(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".export-cypher");
+    if (cmd.size() != 2) throw std::runtime_error("Command .export-cypher requires the output file (.cypher)");
+
+    const std::string& file = cmd[1];
+    if (!file.ends_with(".cypher"))
+        throw std::runtime_error("Command .export-cypher: filename must end with '.cypher'");
+
+    std::ofstream out(file, std::ios::binary);
+    if (!out) throw std::runtime_error("Command .export-cypher: cannot open '" + file + "' for writing");
+
+    const size_t relationships = io::export_cypher(_n, out, [this](network::Node fact)
+                                                   { return _n->is_deduced(fact); });
+    _n->diagnostic("Exported " + std::to_string(relationships) + " relationships to " + file, true);
+}
+
+void console::CommandExecutor::Impl::cmd_import_neo4j(const std::vector<std::string>& cmd) const
+{
+    require_full_graph_mode(".import-neo4j");
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Command .import-neo4j requires the input file and an optional name property");
+
+    std::ifstream in(cmd[1], std::ios::binary);
+    if (!in) throw std::runtime_error("Command .import-neo4j: cannot open '" + cmd[1] + "'");
+
+    network::Reasoning::OriginScope origin(*_n, {"neo4j", cmd[1]});
+    const size_t                    facts = io::import_neo4j(_n, in, std::filesystem::path(cmd[1]).filename().string(), cmd.size() == 3 ? cmd[2] : "name");
+    _n->diagnostic("Imported " + std::to_string(facts) + " facts from " + cmd[1], true);
+}
+#endif
@@ -0,0 +1,518 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "io/owl.hpp"
+#include "io/rule_template.hpp"
+#include "network/network.hpp"
+#include "network/reasoning.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <map>
+#include <set>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_list_rules(const std::vector<std::string>&)
+{
+    // Get all nodes that are subjects of a core.Causes() relation
+    network::adjacency_set rule_nodes = _n->get_rules();
+    if (rule_nodes.empty())
+    {
+        _n->out("No rules found.", true);
+        return;
+    }
+
+    _n->out("Listing all rules:", true);
+    _n->out("------------------------", true);
+
+    for (const auto& rule : rule_nodes)
+    {
+        std::string output;
+        // Format the rule for printing
+        string::node_to_string(_n, output, _n->lang(), rule, 3);
+        const int stratum = _n->rule_stratum(rule);
+        _n->out("[" + std::to_string(rule) + "] " + output + (_n->is_rule_enabled(rule) ? "" : " (disabled)")
+                    + (stratum == 0 ? "" : " (stratum " + std::to_string(stratum) + ")")
+                    + (_n->is_default_rule(rule) ? " (default " + std::to_string(_n->default_priority(rule)) + ")" : ""),
+                true);
+    }
+    _n->out("------------------------", true);
+}
+
+void console::CommandExecutor::Impl::cmd_owl_rules(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .owl-rules takes no arguments");
+    require_full_graph_mode(".owl-rules");
+    report_owl_rules(io::owl_rules(_n));
+}
+
+void console::CommandExecutor::Impl::report_owl_rules(const io::OwlTranslation& translation)
+{
+    _n->out("Translated " + std::to_string(translation.axioms) + " OWL axioms into " + std::to_string(translation.rules) + " new rules"
+                + (translation.skipped ? " (" + std::to_string(translation.skipped) + " skipped)." : "."),
+            true);
+}
+
+void console::CommandExecutor::Impl::cmd_remove_rules(const std::vector<std::string>&)
+{
+    require_full_graph_mode(".remove-rules");
+    _n->remove_rules();
+    _n->out("All rules removed.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_remove_rule(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".remove-rule");
+    if (cmd.size() != 2)
+        throw std::runtime_error("Command .remove-rule requires a rule ID");
+    _n->remove_rule(resolve_single_node(cmd[1], true));
+    _n->out("Rule removed.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_set_rule_enabled(const std::vector<std::string>& cmd, bool enabled)
+{
+    const std::string name = enabled ? ".enable-rule" : ".disable-rule";
+    if (cmd.size() != 2)
+        throw std::runtime_error("Command " + name + " requires a rule ID");
+    _n->set_rule_enabled(resolve_single_node(cmd[1], true), enabled);
+    _n->out(enabled ? "Rule enabled." : "Rule disabled.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_prune(const std::vector<std::string>& cmd, bool facts_mode)
+{
+    require_full_graph_mode(facts_mode ? ".prune-facts" : ".prune-nodes");
+    if (cmd.size() < 2)
+        throw std::runtime_error("Command requires a pattern");
+
+    // Reconstruct the pattern string from arguments to feed into the parser
+    std::string pattern_str;
+    for (size_t i = 1; i < cmd.size(); ++i)
+    {
+        const std::string& token = cmd[i];
+        // If it's a variable, keep as is (A). If not, quote it ("is") so PEG treats it as value.
+        // Note: Since cmd is already tokenized by escaped_list_separator, quotes were stripped.
+        // We re-add them for non-vars to be safe for parse_zelph_to_janet.
+        if (string::is_var(token))
+            pattern_str += token + " ";
+        else
+            pattern_str += "\"" + token + "\" ";
+    }
+
+    std::string utf8_pattern = pattern_str;
+
+    // Delegate parsing and evaluation to ScriptEngine
+    std::string janet_code = _script_engine->parse_zelph_to_janet(utf8_pattern);
+
+    if (janet_code.empty())
+        throw std::runtime_error("Could not parse pattern");
+
+    network::Node pattern_fact = _script_engine->evaluate_expression(janet_code);
+
+    if (pattern_fact == 0)
+        throw std::runtime_error("Invalid pattern");
+
+    if (facts_mode)
+    {
+        size_t removed = 0;
+        _n->prune_facts(pattern_fact, removed);
+        _n->out("Pruned " + std::to_string(removed) + " matching facts.", true);
+        if (removed > 0) _n->diagnostic("Consider running .cleanup.", true);
+    }
+    else
+    {
+        network::Node relation = _n->parse_relation(pattern_fact);
+        if (network::Network::is_var(relation))
+        {
+            throw std::runtime_error("Command .prune-nodes: relation (predicate) must be fixed");
+        }
+        size_t removed_facts = 0;
+        size_t removed_nodes = 0;
+        _n->prune_nodes(pattern_fact, removed_facts, removed_nodes);
+        _n->out("Pruned " + std::to_string(removed_facts) + " matching facts and " + std::to_string(removed_nodes) + " nodes.", true);
+        if (removed_facts > 0 || removed_nodes > 0)
+        {
+            _n->diagnostic("Consider running .cleanup.", true);
+        }
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_explain(const std::vector<std::string>& cmd) const
+{
+    network::Node fact = resolve_fact(cmd);
+    if (!_n->is_deduced(fact))
+        throw std::runtime_error("Command .explain: fact " + std::to_string(fact) + " was not deduced");
+
+    std::function<void(const network::Reasoning::Proof&, const std::string&)> print = [&](const network::Reasoning::Proof& proof, const std::string& indent)
+    {
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), proof.fact, 3);
+        if (proof.rule)
+        {
+            std::string rule;
+            string::node_to_string(_n, rule, _n->lang(), proof.rule, 3);
+            _n->out(indent + string::unmark_identifiers(text + "  ⇐ " + rule), true);
+        }
+        else
+        {
+            _n->out(indent + string::unmark_identifiers(text) + "  (stated)", true);
+        }
+        for (const auto& premise : proof.premises)
+            print(premise, indent + "  ");
+    };
+    print(_n->explain(fact), "");
+}
+
+void console::CommandExecutor::Impl::cmd_conflicts(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .conflicts takes no arguments");
+
+    auto conflicts = _n->conflicts();
+    if (conflicts.empty())
+    {
+        _n->out("No conflicts found.", true);
+        return;
+    }
+
+    for (const auto& conflict : conflicts)
+    {
+        std::string rule;
+        string::node_to_string(_n, rule, _n->lang(), conflict.rule, 3);
+        _n->out(string::unmark_identifiers(rule), true);
+        for (network::Node fact : conflict.facts)
+        {
+            std::string text;
+            string::node_to_string(_n, text, _n->lang(), fact, 3);
+            _n->out("  " + string::unmark_identifiers(text), true);
+        }
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_arithmetic(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2 || (cmd.size() == 2 && cmd[1] != "on" && cmd[1] != "off"))
+        throw std::runtime_error("Usage: .arithmetic [on|off]");
+
+    if (cmd.size() == 2)
+        _n->set_native_arithmetic(cmd[1] == "on");
+
+    _n->out(std::string("Native arithmetic: ") + (_n->native_arithmetic() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_strings(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2 || (cmd.size() == 2 && cmd[1] != "on" && cmd[1] != "off"))
+        throw std::runtime_error("Usage: .strings [on|off]");
+
+    if (cmd.size() == 2)
+        _n->set_string_builtins(cmd[1] == "on");
+
+    _n->out(std::string("String builtins: ") + (_n->string_builtins() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_lists(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2 || (cmd.size() == 2 && cmd[1] != "on" && cmd[1] != "off"))
+        throw std::runtime_error("Usage: .lists [on|off]");
+
+    if (cmd.size() == 2)
+        _n->set_list_builtins(cmd[1] == "on");
+
+    _n->out(std::string("List builtins: ") + (_n->list_builtins() ? "on" : "off"), true);
+}
+
+int console::CommandExecutor::Impl::parse_stratum(const std::string& command, const std::string& text)
+{
+    try
+    {
+        size_t    pos;
+        const int stratum = std::stoi(text, &pos);
+        if (pos == text.size()) return stratum;
+    }
+    catch (...)
+    {
+    }
+    throw std::runtime_error("Command " + command + ": invalid stratum '" + text + "'");
+}
+
+void console::CommandExecutor::Impl::cmd_stratum(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .stratum [n]");
+
+    if (cmd.size() == 2) _n->set_active_stratum(parse_stratum(".stratum", cmd[1]));
+    _n->out("Active stratum: " + std::to_string(_n->active_stratum()), true);
+}
+
+void console::CommandExecutor::Impl::cmd_rule_stratum(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Usage: .rule-stratum <rule-id> [n]");
+
+    network::Node rule = resolve_single_node(cmd[1], true);
+    if (cmd.size() == 3) _n->set_rule_stratum(rule, parse_stratum(".rule-stratum", cmd[2]));
+    _n->out("Stratum of rule " + std::to_string(rule) + ": " + std::to_string(_n->rule_stratum(rule)), true);
+}
+
+void console::CommandExecutor::Impl::cmd_default_rule(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() < 2 || cmd.size() > 3)
+        throw std::runtime_error("Usage: .default-rule <rule-id> [on|off|priority]");
+
+    network::Node rule = resolve_single_node(cmd[1], true);
+    if (cmd.size() == 3)
+    {
+        if (cmd[2] == "off")
+        {
+            _n->set_rule_default(rule, false);
+        }
+        else
+        {
+            int priority = 0;
+            if (cmd[2] != "on")
+            {
+                size_t pos = 0;
+                try
+                {
+                    priority = std::stoi(cmd[2], &pos);
+                }
+                catch (...)
+                {
+                }
+                if (pos == 0 || pos != cmd[2].size())
+                    throw std::runtime_error("Command .default-rule: invalid priority '" + cmd[2] + "'");
+            }
+            _n->set_rule_default(rule, true, priority);
+        }
+    }
+    if (_n->is_default_rule(rule))
+        _n->out("Rule " + std::to_string(rule) + " is a default rule with priority " + std::to_string(_n->default_priority(rule)) + ".", true);
+    else
+        _n->out("Rule " + std::to_string(rule) + " is not a default rule.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_defeats(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .defeats takes no arguments");
+
+    const auto defeats = _n->defeats();
+    if (defeats.empty())
+    {
+        _n->out("No defeated assumptions.", true);
+        return;
+    }
+    auto render = [this](network::Node node)
+    {
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), node, 3);
+        return text;
+    };
+    for (const auto& defeat : defeats)
+    {
+        std::string text = render(defeat.assumption.subject) + " " + render(defeat.assumption.predicate);
+        for (network::Node object : defeat.assumption.objects)
+            text += " " + render(object);
+        _n->out(string::unmark_identifiers(text + "  (rule " + std::to_string(defeat.rule) + ", defeated by rule "
+                                           + std::to_string(defeat.by) + ")"),
+                true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_template(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() == 1)
+    {
+        for (const auto& [name, t] : _rule_templates)
+        {
+            std::string parameters;
+            for (const auto& p : t.parameters())
+                parameters += " " + p;
+            _n->out(name + parameters + ": " + t.rule(), true);
+        }
+        return;
+    }
+
+    const auto colon = std::find(cmd.begin() + 2, cmd.end(), ":");
+    if (cmd.size() < 5 || colon == cmd.end() || colon + 1 == cmd.end())
+        throw std::runtime_error("Usage: .template <name> <parameter>... : <rule>");
+
+    std::vector<std::string> rule(colon, cmd.end()); // join_statement skips the first element
+    try
+    {
+        _rule_templates.insert_or_assign(cmd[1], io::RuleTemplate({cmd.begin() + 2, colon}, join_statement(rule)));
+    }
+    catch (const std::runtime_error& ex)
+    {
+        throw std::runtime_error("Command .template: " + std::string(ex.what()));
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_apply(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".apply");
+    if (cmd.size() < 3) throw std::runtime_error("Usage: .apply <template> <argument>...");
+
+    auto it = _rule_templates.find(cmd[1]);
+    if (it == _rule_templates.end()) throw std::runtime_error("Command .apply: unknown template '" + cmd[1] + "' (see .template)");
+    const io::RuleTemplate& t     = it->second;
+    const size_t            arity = t.parameters().size();
+    if ((cmd.size() - 2) % arity != 0)
+        throw std::runtime_error("Command .apply: template " + cmd[1] + " takes " + std::to_string(arity) + " argument(s) per rule");
+
+    std::set<std::string> known_rules;
+    for (const network::Node rule : _n->get_rules())
+        known_rules.insert(rule_text(rule));
+
+    AutoRunSuspender suspend(_repl_state);
+    size_t           added = 0;
+    for (size_t i = 2; i < cmd.size(); i += arity)
+    {
+        std::string statement;
+        try
+        {
+            statement = t.instantiate({cmd.begin() + i, cmd.begin() + i + arity});
+        }
+        catch (const std::runtime_error& ex)
+        {
+            throw std::runtime_error("Command .apply: " + std::string(ex.what()));
+        }
+
+        const network::adjacency_set before = _n->get_rules();
+        _process_line_callback(statement);
+        for (const network::Node rule : _n->get_rules())
+        {
+            if (before.count(rule) == 1) continue;
+            if (known_rules.insert(rule_text(rule)).second)
+                ++added;
+            else
+                _n->remove_rule(rule);
+        }
+    }
+    _n->out("Added " + std::to_string(added) + " rule(s) from template " + cmd[1] + ".", true);
+
+    if (suspend.was_active())
+    {
+        _n->run(true, false, false, true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_relation(const std::vector<std::string>& cmd)
+{
+    using Property = network::Reasoning::RelationProperty;
+    static const std::map<std::string, Property> properties{{"transitive", Property::Transitive},
+                                                            {"symmetric", Property::Symmetric},
+                                                            {"reflexive", Property::Reflexive},
+                                                            {"inverse", Property::Inverse}};
+
+    if (cmd.size() == 1)
+    {
+        for (const auto& d : _n->relation_properties())
+        {
+            const auto it = std::find_if(properties.begin(), properties.end(), [&](const auto& p)
+                                         { return p.second == d.property; });
+            std::string line = _n->get_name(d.relation, _n->lang(), true) + ": " + it->first;
+            if (d.inverse) line += " of " + _n->get_name(d.inverse, _n->lang(), true);
+            _n->out(line, true);
+        }
+        return;
+    }
+
+    require_full_graph_mode(".relation");
+    const auto it = cmd.size() < 3 ? properties.end() : properties.find(cmd[2]);
+    if (it == properties.end() || cmd.size() != (it->second == Property::Inverse ? 4u : 3u))
+        throw std::runtime_error("Usage: .relation [<relation> transitive|symmetric|reflexive|inverse <relation>]");
+
+    const network::Node relation = _n->node(cmd[1], _n->lang());
+    const network::Node inverse  = cmd.size() == 4 ? _n->node(cmd[3], _n->lang()) : 0;
+
+    AutoRunSuspender suspend(_repl_state);
+    std::string      ids;
+    for (const network::Node rule : _n->declare_relation_property(relation, it->second, inverse))
+        ids += " [" + std::to_string(rule) + "]";
+    _n->out("Declared " + cmd[1] + " " + cmd[2] + (inverse ? " " + cmd[3] : "") + ", rule(s)" + ids + ".", true);
+
+    if (suspend.was_active())
+    {
+        _n->run(true, false, false, true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_same_as(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".same-as");
+    if (cmd.size() != 3) throw std::runtime_error("Usage: .same-as <concept> <concept>");
+
+    network::Node concepts[2];
+    for (size_t i = 0; i < 2; ++i)
+    {
+        concepts[i] = resolve_node(cmd[i + 1], _n->lang());
+        if (!concepts[i]) throw std::runtime_error("Command .same-as: unknown concept '" + cmd[i + 1] + "'");
+    }
+
+    AutoRunSuspender suspend(_repl_state);
+    const size_t     restated = _n->merge_concepts(concepts[0], concepts[1]).size();
+    _n->out("Merged " + cmd[1] + " into " + cmd[2] + ", restated " + std::to_string(restated) + " fact(s).", true);
+
+    if (suspend.was_active())
+    {
+        _n->run(true, false, false, true);
+    }
+}
+
+std::string console::CommandExecutor::Impl::rule_text(const network::Node rule) const
+{
+    std::string text;
+    string::node_to_string(_n, text, _n->lang(), rule, 3);
+    return text;
+}
+
+void console::CommandExecutor::Impl::cmd_unbounded_rules(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1)
+        throw std::runtime_error("Usage: .unbounded-rules");
+
+    auto unbounded = _n->unbounded_rules();
+    if (unbounded.empty())
+    {
+        _n->out("No rule builds new terms without bound.", true);
+        return;
+    }
+
+    for (const auto& u : unbounded)
+    {
+        std::string rule, term, cycle;
+        string::node_to_string(_n, rule, _n->lang(), u.rule, 3);
+        string::node_to_string(_n, term, _n->lang(), u.term, 3);
+        for (network::Node r : u.cycle)
+            cycle += (cycle.empty() ? "" : " → ") + std::string("[") + std::to_string(r) + "]";
+        _n->out(string::unmark_identifiers("[" + std::to_string(u.rule) + "] " + rule + " builds " + term + " on each firing; cycle " + cycle + " → [" + std::to_string(u.rule) + "]"),
+                true);
+    }
+}
@@ -0,0 +1,741 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "io/data_manager.hpp"
+#include "io/metrics.hpp"
+#include "network/reasoning.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <limits>
+#include <optional>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_trace(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .trace [off|deductions|on]");
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] != "off" && cmd[1] != "deductions" && cmd[1] != "on")
+            throw std::runtime_error("Usage: .trace [off|deductions|on]");
+        _n->set_trace(cmd[1] != "off");
+        _n->set_parse_trace(cmd[1] == "on");
+    }
+
+    _n->out(std::string("Trace: ") + (_n->parse_trace() ? "on" : (_n->trace() ? "deductions" : "off")), true);
+}
+
+void console::CommandExecutor::Impl::cmd_run(const std::vector<std::string>&)
+{
+    require_full_graph_mode(".run");
+    _n->run(true, false, false);
+    _n->diagnostic("Ready.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_run_dry(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".run-dry");
+    if (cmd.size() != 1) throw std::runtime_error("Command .run-dry takes no arguments");
+    const size_t deduced = _n->run_dry(true);
+    _n->out("Dry run: would deduce " + std::to_string(deduced) + " fact(s); the network is unchanged.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_debug(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".debug");
+    const std::string usage = "Usage: .debug [break <rule-id> | break <subject> <predicate> <object> | delete <n> | clear | step | continue | agenda [<limit>]]";
+
+    const std::string sub = cmd.size() > 1 ? cmd[1] : "";
+    if (cmd.size() == 1)
+    {
+        list_breakpoints();
+    }
+    else if (sub == "break" && cmd.size() == 3)
+    {
+        const network::Node rule = resolve_single_node(cmd[2], true);
+        if (_n->get_rules().count(rule) == 0)
+            throw std::runtime_error("Command .debug: node " + cmd[2] + " is not a rule");
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), rule, 3);
+        _breakpoints.push_back({_next_breakpoint++, rule, {}, {}, {}, "at rule " + std::to_string(rule) + ": " + string::unmark_identifiers(text)});
+        install_breakpoints();
+        _n->out("Breakpoint [" + std::to_string(_breakpoints.back().id) + "] " + _breakpoints.back().description, true);
+    }
+    else if (sub == "break" && cmd.size() == 5)
+    {
+        Breakpoint breakpoint{_next_breakpoint++, 0, {}, {}, {}, "at facts " + cmd[2] + " " + cmd[3] + " " + cmd[4]};
+        std::optional<network::Node>* parts[] = {&breakpoint.subject, &breakpoint.predicate, &breakpoint.object};
+        for (size_t i = 0; i < 3; ++i)
+        {
+            if (cmd[i + 2] == "*") continue;
+            network::Node nd = _n->get_node(cmd[i + 2], _n->lang());
+            if (nd == 0) nd = _n->get_core_node(cmd[i + 2]);
+            *parts[i] = nd;
+        }
+        _breakpoints.push_back(breakpoint);
+        install_breakpoints();
+        _n->out("Breakpoint [" + std::to_string(breakpoint.id) + "] " + breakpoint.description, true);
+    }
+    else if (sub == "delete" && cmd.size() == 3)
+    {
+        const auto it = std::find_if(_breakpoints.begin(), _breakpoints.end(), [&](const Breakpoint& b)
+                                     { return std::to_string(b.id) == cmd[2]; });
+        if (it == _breakpoints.end())
+            throw std::runtime_error("Command .debug: no breakpoint " + cmd[2]);
+        _breakpoints.erase(it);
+        install_breakpoints();
+        _n->out("Breakpoint " + cmd[2] + " deleted.", true);
+    }
+    else if (sub == "clear" && cmd.size() == 2)
+    {
+        _breakpoints.clear();
+        install_breakpoints();
+        _n->out("All breakpoints deleted.", true);
+    }
+    else if (sub == "step" && cmd.size() == 2)
+    {
+        // Every firing is a breakpoint for this one run.
+        bool fired = false;
+        _n->set_break_condition([&fired](network::Node, network::Node)
+                                { return fired = true; });
+        try
+        {
+            _n->run(true, false, false);
+        }
+        catch (...)
+        {
+            install_breakpoints();
+            throw;
+        }
+        install_breakpoints();
+        if (!fired) _n->out("No rule fires anymore.", true);
+    }
+    else if (sub == "continue" && cmd.size() == 2)
+    {
+        _breakpoint_hit = 0;
+        _n->run(true, false, false);
+        if (_breakpoint_hit != 0)
+            _n->out("Stopped at breakpoint [" + std::to_string(_breakpoint_hit) + "].", true);
+        else if (_n->fixpoint_reached())
+            _n->out("No rule fires anymore.", true);
+    }
+    else if (sub == "agenda" && cmd.size() <= 3)
+    {
+        size_t limit = 0;
+        if (cmd.size() == 3)
+        {
+            try
+            {
+                size_t pos;
+                limit = std::stoull(cmd[2], &pos);
+                if (pos != cmd[2].size() || cmd[2][0] == '-') throw std::invalid_argument(cmd[2]);
+            }
+            catch (...)
+            {
+                throw std::runtime_error("Command .debug: invalid agenda limit.");
+            }
+        }
+        list_agenda(limit);
+    }
+    else
+    {
+        throw std::runtime_error(usage);
+    }
+}
+
+void console::CommandExecutor::Impl::install_breakpoints()
+{
+    if (_breakpoints.empty())
+    {
+        _n->set_break_condition(nullptr);
+        return;
+    }
+
+    _n->set_break_condition([this](network::Node fact, network::Node rule)
+                            {
+        network::adjacency_set objects;
+        const network::Node    subject   = _n->parse_fact(fact, objects);
+        const network::Node    predicate = _n->parse_relation(fact);
+        for (const Breakpoint& b : _breakpoints)
+        {
+            const bool hit = b.rule != 0
+                               ? b.rule == rule
+                               : (!b.subject || *b.subject == subject) && (!b.predicate || *b.predicate == predicate)
+                                     && (!b.object || objects.count(*b.object) == 1);
+            if (hit)
+            {
+                if (_breakpoint_hit == 0) _breakpoint_hit = b.id;
+                return true;
+            }
+        }
+        return false; });
+}
+
+void console::CommandExecutor::Impl::list_breakpoints() const
+{
+    if (_breakpoints.empty())
+    {
+        _n->out("No breakpoints.", true);
+        return;
+    }
+    for (const Breakpoint& breakpoint : _breakpoints)
+        _n->out("[" + std::to_string(breakpoint.id) + "] " + breakpoint.description, true);
+}
+
+void console::CommandExecutor::Impl::list_agenda(const size_t limit) const
+{
+    const auto agenda = _n->agenda(limit);
+    if (agenda.empty())
+    {
+        _n->out("The agenda is empty.", true);
+        return;
+    }
+
+    for (const auto& item : agenda)
+    {
+        std::string rule;
+        string::node_to_string(_n, rule, _n->lang(), item.rule, 3);
+
+        std::vector<std::string> bindings;
+        for (const auto& [variable, value] : item.bindings)
+        {
+            std::string variable_text, value_text;
+            string::node_to_string(_n, variable_text, _n->lang(), variable, 3);
+            string::node_to_string(_n, value_text, _n->lang(), value, 3);
+            bindings.push_back(string::unmark_identifiers(variable_text) + " = " + string::unmark_identifiers(value_text));
+        }
+        std::sort(bindings.begin(), bindings.end());
+
+        std::string line = "[" + std::to_string(item.rule) + "] " + string::unmark_identifiers(rule);
+        for (size_t i = 0; i < bindings.size(); ++i)
+            line += (i == 0 ? "  with " : ", ") + bindings[i];
+        _n->out(line, true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_run_once(const std::vector<std::string>&)
+{
+    require_full_graph_mode(".run-once");
+    _n->run(true, false, true);
+    _n->diagnostic("Ready.", true);
+}
+
+#ifndef __EMSCRIPTEN__
+void console::CommandExecutor::Impl::cmd_run_md(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".run-md");
+    if (cmd.size() < 2) throw std::runtime_error("Command .run-md: Missing subdirectory parameter (e.g., '.run-md tree')");
+    const std::string& subdir = cmd[1];
+    _n->set_markdown_subdir(subdir);
+    _n->diagnostic("Running with markdown export...", true);
+    if (_data_manager)
+    {
+        _data_manager->set_logging(false);
+    }
+    _n->run(false, true, false);
+}
+#endif
+
+void console::CommandExecutor::Impl::cmd_parallel(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1)
+        throw std::runtime_error("Command .parallel takes no arguments");
+
+    _n->toggle_parallel();
+    _n->out("Parallel processing is now " + std::string(_n->use_parallel() ? "enabled" : "disabled") + ".", true);
+}
+
+void console::CommandExecutor::Impl::cmd_indexes(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 1)
+    {
+        uint8_t indexes = 0;
+        for (size_t i = 1; i < cmd.size(); ++i)
+        {
+            if (cmd[i] == "spo")
+                indexes |= network::Zelph::SPO;
+            else if (cmd[i] == "pos")
+                indexes |= network::Zelph::POS;
+            else if (cmd[i] == "osp")
+                indexes |= network::Zelph::OSP;
+            else
+                throw std::runtime_error("Usage: .indexes [spo] [pos] [osp]");
+        }
+        _n->set_triple_indexes(indexes);
+    }
+
+    const uint8_t indexes = _n->triple_indexes();
+    std::string   enabled;
+    if (indexes & network::Zelph::SPO) enabled += " spo";
+    if (indexes & network::Zelph::POS) enabled += " pos";
+    if (indexes & network::Zelph::OSP) enabled += " osp";
+    _n->out("Fact lookup orientations:" + enabled, true);
+}
+
+void console::CommandExecutor::Impl::cmd_threads(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .threads [n]");
+
+    if (cmd.size() == 2)
+    {
+        int count;
+        try
+        {
+            count = std::stoi(cmd[1]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .threads: invalid thread count.");
+        }
+        if (count < 0)
+            throw std::runtime_error("Command .threads: invalid thread count.");
+
+        _n->set_thread_count(static_cast<size_t>(count));
+    }
+
+    _n->out("Reasoning uses " + std::to_string(_n->thread_count()) + " worker thread(s).", true);
+}
+
+void console::CommandExecutor::Impl::cmd_deterministic(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .deterministic [on|off]");
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] == "on")
+            _n->set_deterministic(true);
+        else if (cmd[1] == "off")
+            _n->set_deterministic(false);
+        else
+            throw std::runtime_error("Usage: .deterministic [on|off]");
+    }
+
+    _n->out(std::string("Deterministic mode: ") + (_n->deterministic() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_max_facts(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .max-facts [n]");
+
+    if (cmd.size() == 2)
+    {
+        uint64_t count;
+        try
+        {
+            size_t pos;
+            count = std::stoull(cmd[1], &pos);
+            if (pos != cmd[1].size() || cmd[1][0] == '-') throw std::invalid_argument(cmd[1]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .max-facts: invalid number of facts.");
+        }
+        _n->set_max_facts(count);
+    }
+
+    if (_n->max_facts() == 0)
+        _n->out("Runs may deduce any number of facts.", true);
+    else
+        _n->out("Runs may deduce at most " + std::to_string(_n->max_facts()) + " facts.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_max_term_depth(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .max-term-depth [n]");
+
+    if (cmd.size() == 2)
+    {
+        unsigned long depth;
+        try
+        {
+            size_t pos;
+            depth = std::stoul(cmd[1], &pos);
+            if (pos != cmd[1].size() || cmd[1][0] == '-' || depth > std::numeric_limits<uint32_t>::max()) throw std::invalid_argument(cmd[1]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .max-term-depth: invalid depth.");
+        }
+        _n->set_max_term_depth(static_cast<uint32_t>(depth));
+    }
+
+    if (_n->max_term_depth() == 0)
+        _n->out("Rule firings may nest the terms they build without limit.", true);
+    else
+        _n->out("Rule firings may nest the terms they build at most " + std::to_string(_n->max_term_depth()) + " deep.", true);
+}
+
+uint64_t console::CommandExecutor::Impl::parse_bytes(const std::string& text)
+{
+    size_t   pos;
+    uint64_t bytes = std::stoull(text, &pos);
+    if (text[0] == '-') throw std::invalid_argument(text);
+    if (pos + 1 == text.size())
+    {
+        switch (text[pos])
+        {
+        case 'K':
+        case 'k':
+            bytes <<= 10;
+            break;
+        case 'M':
+        case 'm':
+            bytes <<= 20;
+            break;
+        case 'G':
+        case 'g':
+            bytes <<= 30;
+            break;
+        default:
+            throw std::invalid_argument(text);
+        }
+    }
+    else if (pos != text.size())
+    {
+        throw std::invalid_argument(text);
+    }
+    return bytes;
+}
+
+void console::CommandExecutor::Impl::cmd_max_memory(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .max-memory [bytes]");
+
+    if (cmd.size() == 2)
+    {
+        uint64_t bytes;
+        try
+        {
+            bytes = parse_bytes(cmd[1]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .max-memory: invalid size, expected bytes or a number with K, M or G.");
+        }
+        _n->set_max_memory(static_cast<size_t>(bytes));
+    }
+
+    if (_n->max_memory() == 0)
+        _n->out("Runs may use any amount of memory.", true);
+    else
+        _n->out("Runs stop when the process uses more than " + std::to_string(_n->max_memory()) + " bytes.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_max_iterations(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .max-iterations [n]");
+
+    if (cmd.size() == 2)
+    {
+        int count;
+        try
+        {
+            size_t pos;
+            count = std::stoi(cmd[1], &pos);
+            if (pos != cmd[1].size() || count < 0) throw std::invalid_argument(cmd[1]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .max-iterations: invalid number of iterations.");
+        }
+        _n->set_max_iterations(count);
+    }
+
+    if (_n->max_iterations() == 0)
+        _n->out("Runs continue until they reach their fixpoint.", true);
+    else
+        _n->out("Runs pause after " + std::to_string(_n->max_iterations()) + " iteration(s).", true);
+}
+
+void console::CommandExecutor::Impl::cmd_query_limits(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 3)
+        throw std::runtime_error("Usage: .query-limits [ms [matches]]");
+
+    auto number = [](const std::string& arg, const char* what)
+    {
+        try
+        {
+            size_t         pos;
+            const uint64_t value = std::stoull(arg, &pos);
+            if (pos != arg.size() || arg[0] == '-') throw std::invalid_argument(arg);
+            return value;
+        }
+        catch (...)
+        {
+            throw std::runtime_error(std::string("Command .query-limits: invalid ") + what + ".");
+        }
+    };
+
+    if (cmd.size() >= 2) _n->set_query_timeout(std::chrono::milliseconds(number(cmd[1], "time")));
+    if (cmd.size() == 3) _n->set_max_query_matches(number(cmd[2], "number of matches"));
+
+    const auto timeout = _n->query_timeout().count();
+    const auto matches = _n->max_query_matches();
+    _n->out("Queries may take " + (timeout == 0 ? std::string("any time") : std::to_string(timeout) + " ms")
+                + " and process " + (matches == 0 ? std::string("any number of") : std::to_string(matches)) + " matches.",
+            true);
+}
+
+void console::CommandExecutor::Impl::cmd_max_deductions(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .max-deductions [n]");
+
+    if (cmd.size() == 2)
+    {
+        uint64_t count;
+        try
+        {
+            size_t pos;
+            count = std::stoull(cmd[1], &pos);
+            if (pos != cmd[1].size() || cmd[1][0] == '-') throw std::invalid_argument(cmd[1]);
+        }
+        catch (...)
+        {
+            throw std::runtime_error("Command .max-deductions: invalid number of deductions.");
+        }
+        _n->set_max_deductions(count);
+    }
+
+    if (_n->max_deductions() == 0)
+        _n->out("Runs continue until they reach their fixpoint.", true);
+    else
+        _n->out("Runs pause after " + std::to_string(_n->max_deductions()) + " deductions.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_metrics(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .metrics [on|off]");
+
+    if (cmd.size() == 1)
+    {
+        std::string text = io::prometheus_metrics(_n);
+        if (!text.empty() && text.back() == '\n') text.pop_back();
+        _n->out(text, true);
+        return;
+    }
+
+    if (cmd[1] == "on")
+        _n->set_metrics_enabled(true);
+    else if (cmd[1] == "off")
+        _n->set_metrics_enabled(false);
+    else
+        throw std::runtime_error("Usage: .metrics [on|off]");
+
+    _n->out(std::string("Metrics collection: ") + (_n->metrics_enabled() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_rule_profile(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .rule-profile [on|off]");
+
+    if (cmd.size() == 1)
+    {
+        std::string text = io::rule_profile_report(_n);
+        if (!text.empty() && text.back() == '\n') text.pop_back();
+        _n->out(text, true);
+        return;
+    }
+
+    if (cmd[1] == "on")
+        _n->set_rule_profiling(true);
+    else if (cmd[1] == "off")
+        _n->set_rule_profiling(false);
+    else
+        throw std::runtime_error("Usage: .rule-profile [on|off]");
+
+    _n->out(std::string("Rule profiling: ") + (_n->rule_profiling() ? "on" : "off"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_semi_naive(const std::vector<std::string>& cmd)
+{
+    auto status = [this]() -> std::string
+    {
+        if (!_n->seminaive()) return "off";
+        return _n->seminaive_check() ? "check" : "on";
+    };
+
+    if (cmd.size() == 1)
+    {
+        _n->out("Semi-naive evaluation: " + status(), true);
+        return;
+    }
+
+    if (cmd.size() != 2)
+        throw std::runtime_error("Usage: .semi-naive [on|off|check]");
+
+    if (cmd[1] == "on")
+    {
+        _n->set_seminaive(true);
+        _n->set_seminaive_check(false);
+    }
+    else if (cmd[1] == "off")
+    {
+        _n->set_seminaive(false);
+        _n->set_seminaive_check(false);
+    }
+    else if (cmd[1] == "check")
+    {
+        _n->set_seminaive(true);
+        _n->set_seminaive_check(true);
+    }
+    else
+    {
+        throw std::runtime_error("Usage: .semi-naive [on|off|check]");
+    }
+
+    _n->out("Semi-naive evaluation: " + status(), true);
+}
+
+void console::CommandExecutor::Impl::cmd_stratification(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .stratification [check|strict|lenient]");
+
+    if (cmd.size() == 2 && cmd[1] == "check")
+    {
+        auto violations = _n->stratification_violations();
+        if (violations.empty())
+        {
+            _n->out("The rule set is stratifiable.", true);
+            return;
+        }
+
+        for (const auto& v : violations)
+        {
+            std::string producer, pattern, consumer;
+            string::node_to_string(_n, producer, _n->lang(), v.producer, 3);
+            string::node_to_string(_n, pattern, _n->lang(), v.pattern, 3);
+            string::node_to_string(_n, consumer, _n->lang(), v.consumer, 3);
+            _n->out(string::unmark_identifiers("[" + std::to_string(v.producer) + "] " + producer + " may grow ¬" + pattern
+                                               + " of [" + std::to_string(v.consumer) + "] " + consumer),
+                    true);
+        }
+        return;
+    }
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] == "strict")
+            _n->set_strict_stratification(true);
+        else if (cmd[1] == "lenient")
+            _n->set_strict_stratification(false);
+        else
+            throw std::runtime_error("Usage: .stratification [check|strict|lenient]");
+    }
+
+    _n->out("Stratification: " + std::string(_n->strict_stratification() ? "strict" : "lenient"), true);
+}
+
+void console::CommandExecutor::Impl::cmd_plan(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() < 2)
+        throw std::runtime_error("Usage: .plan <query|rule-id>");
+
+    network::Node target = 0;
+    if (cmd.size() == 2 && std::all_of(cmd[1].begin(), cmd[1].end(), ::isdigit))
+    {
+        target = resolve_single_node(cmd[1], true);
+        if (_n->get_rules().count(target) == 0)
+            throw std::runtime_error("Command .plan: node " + cmd[1] + " is not a rule");
+    }
+    else
+    {
+        const std::string statement  = join_statement(cmd);
+        const std::string janet_code = _script_engine->parse_zelph_to_janet(statement);
+        if (janet_code.empty())
+            throw std::runtime_error("Could not parse query");
+        target = _script_engine->evaluate_expression(janet_code);
+        if (target == 0)
+            throw std::runtime_error("Invalid query");
+    }
+
+    size_t number = 0;
+    for (const auto& step : _n->query_plan(target))
+    {
+        std::string condition;
+        string::node_to_string(_n, condition, _n->lang(), step.condition, 3);
+
+        std::string line = std::to_string(++number) + ". " + condition + "  " + step.access;
+        if (step.access != "none")
+            line += ", " + std::to_string(step.candidates) + (step.per_binding ? " candidates per binding" : " candidates");
+        if (step.negated) line += ", negated";
+        if (!step.binds.empty())
+        {
+            line += ", binds";
+            for (network::Node var : step.binds)
+            {
+                std::string name;
+                string::node_to_string(_n, name, _n->lang(), var, 1);
+                line += " " + name;
+            }
+        }
+        _n->out(string::unmark_identifiers(line), true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_query_cache(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() > 2)
+        throw std::runtime_error("Usage: .query-cache [on|off|clear]");
+
+    if (cmd.size() == 2)
+    {
+        if (cmd[1] == "on")
+            _n->set_query_cache(true);
+        else if (cmd[1] == "off")
+            _n->set_query_cache(false);
+        else if (cmd[1] == "clear")
+            _n->clear_query_cache();
+        else
+            throw std::runtime_error("Usage: .query-cache [on|off|clear]");
+    }
+
+    if (!_n->query_cache())
+    {
+        _n->out("Query cache: off", true);
+        return;
+    }
+    _n->out("Query cache: on, " + std::to_string(_n->query_cache_size()) + " entries, "
+                + std::to_string(_n->query_cache_hits()) + " hits",
+            true);
+}
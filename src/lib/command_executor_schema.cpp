@@ -0,0 +1,171 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "io/cardinality.hpp"
+#include "io/schema.hpp"
+#include "io/shacl.hpp"
+#include "network/reasoning.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_schema_violations(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .schema-violations takes no arguments");
+
+    auto render = [this](network::Node nd)
+    {
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), nd, 3);
+        return string::unmark_identifiers(text);
+    };
+
+    bool found = false;
+    for (const auto& conflict : _n->conflicts())
+    {
+        const auto violation = io::schema_violation(_n, conflict.facts);
+        if (!violation) continue;
+        found = true;
+        _n->out(render(violation->fact), true);
+        _n->out(std::string("  ") + (violation->subject ? "subject " : "object ") + render(violation->node)
+                    + " is not ~ " + render(violation->expected)
+                    + " (" + render(violation->relation) + (violation->subject ? " domain)" : " range)"),
+                true);
+    }
+    if (!found) _n->out("No schema violations found.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_validate(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .validate takes no arguments");
+
+    auto render = [this](network::Node nd)
+    {
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), nd, 3);
+        return string::unmark_identifiers(text);
+    };
+
+    const auto violations = io::cardinality_violations(_n);
+    if (violations.empty())
+    {
+        _n->out("No violations found.", true);
+        return;
+    }
+
+    for (const auto& violation : violations)
+    {
+        _n->out(render(violation.subject) + " has " + std::to_string(violation.count) + " objects of "
+                    + render(violation.relation) + " (at most " + std::to_string(violation.limit) + ")",
+                true);
+        for (network::Node fact : violation.facts)
+            _n->out("  " + render(fact), true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_validate_shapes(const std::vector<std::string>& cmd) const
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .validate-shapes takes no arguments");
+
+    auto render = [this](network::Node nd)
+    {
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), nd, 3);
+        return string::unmark_identifiers(text);
+    };
+
+    const auto report = io::validate_shapes(_n);
+    _n->out("Shapes: " + std::to_string(report.shapes) + ", focus nodes: " + std::to_string(report.focus)
+                + (report.skipped ? ", skipped property shapes: " + std::to_string(report.skipped) : ""),
+            true);
+    for (const auto& result : report.results)
+        _n->out(render(result.focus) + " " + render(result.path) + ": " + io::component_name(result.component)
+                    + " – " + io::result_message(_n, result),
+                true);
+    _n->out(report.results.empty() ? "Conforms." : std::to_string(report.results.size()) + " results.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_view(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() == 1)
+    {
+        const auto views = _n->views();
+        if (views.empty()) _n->out("No views.", true);
+        for (const std::string& name : views)
+            _n->out(name + ": " + std::to_string(_n->view(name).size()) + " answer(s)", true);
+        return;
+    }
+
+    if (cmd.size() > 2)
+    {
+        require_full_graph_mode(".view");
+        const std::string statement  = join_statement(std::vector<std::string>(cmd.begin() + 1, cmd.end()));
+        const std::string janet_code = _script_engine->parse_zelph_to_janet(statement);
+        if (janet_code.empty())
+            throw std::runtime_error("Could not parse query");
+        const network::Node condition = _script_engine->evaluate_expression(janet_code);
+        if (condition == 0)
+            throw std::runtime_error("Invalid query");
+        _n->create_view(cmd[1], condition);
+        _n->out("View " + cmd[1] + ": " + std::to_string(_n->view(cmd[1]).size()) + " answer(s)", true);
+        return;
+    }
+
+    const auto answers = _n->view(cmd[1]);
+    if (answers.empty()) _n->out("No answers.", true);
+    for (const auto& answer : answers)
+    {
+        std::vector<std::string> bindings;
+        for (const auto& [variable, value] : answer)
+        {
+            std::string variable_text, value_text;
+            string::node_to_string(_n, variable_text, _n->lang(), variable, 3);
+            string::node_to_string(_n, value_text, _n->lang(), value, 3);
+            bindings.push_back(string::unmark_identifiers(variable_text) + " = " + string::unmark_identifiers(value_text));
+        }
+        std::sort(bindings.begin(), bindings.end());
+
+        std::string line;
+        for (size_t i = 0; i < bindings.size(); ++i)
+            line += (i == 0 ? "" : ", ") + bindings[i];
+        _n->out(line, true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_remove_view(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 2)
+        throw std::runtime_error("Usage: .remove-view <name>");
+    if (!_n->remove_view(cmd[1]))
+        throw std::runtime_error("Command .remove-view: Unknown view " + cmd[1]);
+    _n->out("Removed view " + cmd[1] + ".", true);
+}
@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "command_executor.hpp"
+
+#include "command_executor_impl.hpp"
+#include "network/reasoning.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+
+using namespace zelph;
+
+void console::CommandExecutor::Impl::cmd_retract(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".retract");
+    network::Node fact = resolve_fact(cmd);
+
+    size_t withdrawn = 0;
+    _n->retract(fact, withdrawn);
+    _n->out("Retracted fact " + std::to_string(fact) + ", withdrew " + std::to_string(withdrawn) + " deduced facts.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_revise(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".revise");
+    const auto arrow = std::find(cmd.begin(), cmd.end(), "->");
+
+    std::vector<std::string> old_fact(cmd.begin(), arrow);
+    network::Node            fact        = resolve_fact(old_fact);
+    network::Node            replacement = 0;
+    if (arrow != cmd.end())
+    {
+        std::vector<std::string> new_fact{cmd[0]};
+        new_fact.insert(new_fact.end(), arrow + 1, cmd.end());
+        replacement = resolve_fact(new_fact);
+    }
+
+    const auto withdrawn = _n->revise(fact, replacement);
+    _n->out("Revised fact " + std::to_string(fact) + ", withdrew " + std::to_string(withdrawn.size()) + " deduced facts.", true);
+    for (const auto& parts : withdrawn)
+    {
+        std::string text;
+        string::node_to_string(_n, text, _n->lang(), parts.subject, 3);
+        text += " ";
+        std::string predicate;
+        string::node_to_string(_n, predicate, _n->lang(), parts.predicate, 3);
+        text += predicate;
+        for (network::Node object : parts.objects)
+        {
+            std::string rendered;
+            string::node_to_string(_n, rendered, _n->lang(), object, 3);
+            text += " " + rendered;
+        }
+        _n->out("  " + string::unmark_identifiers(text), true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_cleanup(const std::vector<std::string>& cmd)
+{
+    require_full_graph_mode(".cleanup");
+    if (cmd.size() != 1)
+        throw std::runtime_error("Command .cleanup takes no arguments");
+
+    size_t removed_facts = 0;
+    size_t removed_preds = 0;
+
+    _n->diagnostic("Scanning for unused predicates and zombie facts...", true);
+
+    _n->purge_unused_predicates(removed_facts, removed_preds);
+
+    _n->out("Purged " + std::to_string(removed_facts) + " zombie facts.", true);
+    _n->out("Removed " + std::to_string(removed_preds) + " unused predicates.", true);
+
+    _n->diagnostic("Cleaning up isolated nodes...", true);
+
+    size_t cleanup_count = 0;
+    _n->cleanup_isolated(cleanup_count);
+    _n->out("Cleanup: removed " + std::to_string(cleanup_count) + " isolated nodes/names.", true);
+
+    _n->diagnostic("Cleaning up name mappings...", true);
+    size_t names_removed = _n->cleanup_names();
+    _n->out("Removed " + std::to_string(names_removed) + " dangling name entries.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_cluster(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() == 1)
+    {
+        const std::string active = _n->active_cluster_name();
+        _n->out("Active cluster: " + (active.empty() ? "default" : active), true);
+        for (const auto& [name, count] : _n->list_clusters())
+            _n->out("  " + name + ": " + std::to_string(count) + " node(s)", true);
+        return;
+    }
+    if (cmd.size() != 2) throw std::runtime_error("Usage: .cluster [name]");
+    if (_n->in_transaction()) throw std::runtime_error(".cluster: not available inside a transaction");
+
+    if (cmd[1] == "default")
+    {
+        _n->deactivate_cluster();
+        _n->out("Active cluster: default", true);
+    }
+    else
+    {
+        _n->set_active_cluster(cmd[1]);
+        _n->out("Active cluster: " + cmd[1], true);
+    }
+}
+
+void console::CommandExecutor::Impl::cmd_cluster_drop(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 2) throw std::runtime_error("Usage: .cluster-drop <name>");
+    if (cmd[1] == "default") throw std::runtime_error(".cluster-drop: the default cluster cannot be dropped");
+    const size_t removed = _n->drop_cluster(cmd[1]);
+    _n->out("Dropped cluster " + cmd[1] + ": removed " + std::to_string(removed) + " node(s).", true);
+}
+
+void console::CommandExecutor::Impl::cmd_begin(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .begin takes no arguments");
+    _n->begin_transaction();
+    _n->out("Transaction opened.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_commit(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .commit takes no arguments");
+    _n->commit_transaction();
+    _n->out("Transaction committed.", true);
+}
+
+void console::CommandExecutor::Impl::cmd_rollback(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 1) throw std::runtime_error("Command .rollback takes no arguments");
+    const size_t removed = _n->rollback_transaction();
+    _n->out("Transaction rolled back: removed " + std::to_string(removed) + " node(s).", true);
+}
+
+void console::CommandExecutor::Impl::cmd_cluster_merge(const std::vector<std::string>& cmd)
+{
+    if (cmd.size() != 3) throw std::runtime_error("Usage: .cluster-merge <from> <to>  (to may be 'default')");
+    const std::string to = (cmd[2] == "default") ? "" : cmd[2];
+    if (!_n->merge_cluster(cmd[1], to))
+        throw std::runtime_error(".cluster-merge: unknown cluster '" + cmd[1] + "'");
+    _n->out("Merged cluster " + cmd[1] + " into " + cmd[2] + ".", true);
+}
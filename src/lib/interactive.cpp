@@ -26,113 +26,161 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "interactive.hpp"
 
 #include "command_executor.hpp"
+#include "interactive_impl.hpp"
+#include "io/base_ontology.hpp"
 #include "network/reasoning.hpp"
+#include "network/reasoning_cancelled.hpp"
+#include "network/reasoning_limit_exceeded.hpp"
+#include "process_error.hpp"
 #include "repl_state.hpp"
 #include "script_engine.hpp"
 #include "string/node_to_string.hpp"
 #include "string/string_utils.hpp"
 
+#include <algorithm>
+#include <exception>
 #include <memory>
+#include <mutex>
+#include <set>
+#include <sstream>
 #include <utility>
 
 using namespace zelph;
 
-class console::Interactive::Impl
+void console::Interactive::Impl::init(io::OutputHandler output)
 {
-public:
-    explicit Impl(Interactive* enclosing, io::OutputHandler output)
-        : _repl_state(std::make_shared<ReplState>())
-        , _interactive(enclosing)
+    _n             = std::make_unique<network::Reasoning>(output);
+    _script_engine = std::make_unique<ScriptEngine>(_n.get());
+
+    _n->set_lang("zelph");
+
+    _n->register_core_node(_n->core.RelationTypeCategory, "->");
+    _n->register_core_node(_n->core.Causes, "=>");
+    _n->register_core_node(_n->core.IsA, "~");
+    _n->register_core_node(_n->core.Unequal, "!=");
+    _n->register_core_node(_n->core.Contradiction, "!");
+    _n->register_core_node(_n->core.Cons, "cons");
+    _n->register_core_node(_n->core.Nil, "nil");
+    _n->register_core_node(_n->core.PartOf, "in");
+    _n->register_core_node(_n->core.Conjunction, "conjunction");
+    _n->register_core_node(_n->core.Negation, "negation");
+
+    _script_engine->initialize();
+
+    // Initialize CommandExecutor with references to our state
+    _command_executor = std::make_unique<CommandExecutor>(
+        _n.get(),
+        _script_engine.get(),
+        _repl_state,
+        [this](const std::string& line)
+        { _interactive->process(line); });
+
+    // zelph/import delegates to the same implementation as the .import
+    // command (path resolution including the standard library, argument
+    // passing, auto-run handling).
+    _script_engine->set_import_handler(
+        [this](const std::string& path, const std::vector<std::string>& args)
+        { _command_executor->import_file(path, args); });
+
+    // zelph/save and zelph/load delegate to the same implementation as
+    // the .save/.load commands (including all checks and side effects).
+    // Passing a pre-tokenized command vector (instead of a raw line
+    // through process) keeps filenames with spaces intact.
+    _script_engine->set_command_handler(
+        [this](const std::vector<std::string>& cmd)
+        { _command_executor->execute(cmd); });
+
+    install_observers();
+    for (const auto& [name, function] : _builtins)
+        install_builtin(name, function);
+}
+
+std::vector<console::ScriptLineError> console::Interactive::Impl::process_stream(std::istream& in, const bool datalog)
+{
+    std::vector<ScriptLineError> errors;
+    try
     {
-        init(output);
+        auto on_error = [&errors](const size_t number, const process_error& ex)
+        { errors.push_back({number, ex.line(), ex.kind(), ex.reason(), ex.column()}); };
+        if (datalog)
+            _command_executor->import_datalog(in, on_error);
+        else
+            _command_executor->import_stream(in, on_error);
     }
-
-    void init(io::OutputHandler output)
+    catch (const process_error&)
     {
-        _n             = std::make_unique<network::Reasoning>(output);
-        _script_engine = std::make_unique<ScriptEngine>(_n.get());
-
-        _n->set_lang("zelph");
-
-        _n->register_core_node(_n->core.RelationTypeCategory, "->");
-        _n->register_core_node(_n->core.Causes, "=>");
-        _n->register_core_node(_n->core.IsA, "~");
-        _n->register_core_node(_n->core.Unequal, "!=");
-        _n->register_core_node(_n->core.Contradiction, "!");
-        _n->register_core_node(_n->core.Cons, "cons");
-        _n->register_core_node(_n->core.Nil, "nil");
-        _n->register_core_node(_n->core.PartOf, "in");
-        _n->register_core_node(_n->core.Conjunction, "conjunction");
-        _n->register_core_node(_n->core.Negation, "negation");
-
-        _script_engine->initialize();
-
-        // Initialize CommandExecutor with references to our state
-        _command_executor = std::make_unique<CommandExecutor>(
-            _n.get(),
-            _script_engine.get(),
-            _repl_state,
-            [this](const std::string& line)
-            { _interactive->process(line); });
-
-        // zelph/import delegates to the same implementation as the .import
-        // command (path resolution including the standard library, argument
-        // passing, auto-run handling).
-        _script_engine->set_import_handler(
-            [this](const std::string& path, const std::vector<std::string>& args)
-            { _command_executor->import_file(path, args); });
-
-        // zelph/save and zelph/load delegate to the same implementation as
-        // the .save/.load commands (including all checks and side effects).
-        // Passing a pre-tokenized command vector (instead of a raw line
-        // through process) keeps filenames with spaces intact.
-        _script_engine->set_command_handler(
-            [this](const std::vector<std::string>& cmd)
-            { _command_executor->execute(cmd); });
+        // cancelled while processing a line, which is already in errors
     }
-
-    void reset_reasoning()
+    catch (const network::reasoning_cancelled& ex)
     {
-        _command_executor.reset();              // destroy first (depends on both)
-        _script_engine.reset();                 // destroy second (depends on _n)
-        auto output = _n->get_output_handler(); // save what's needed
-        _n.reset();                             // destroy last
+        errors.push_back({0, "", ProcessErrorKind::Cancelled, ex.what()});
+    }
+    catch (const network::reasoning_limit_exceeded& ex)
+    {
+        errors.push_back({0, "", ProcessErrorKind::ResourceLimit, ex.what()});
+    }
+    catch (std::exception& ex)
+    {
+        errors.push_back({0, "", ProcessErrorKind::Reasoning, ex.what()});
+    }
+    return errors;
+}
+
+void console::Interactive::Impl::reset_reasoning()
+{
+    _command_executor.reset();              // destroy first (depends on both)
+    _script_engine.reset();                 // destroy second (depends on _n)
+    auto output = _n->get_output_handler(); // save what's needed
+    _n.reset();                             // destroy last
 
 #ifndef __EMSCRIPTEN__
-        _repl_state->partial_load_mode = false;
-        _repl_state->partial_load_source.clear();
+    _repl_state->partial_load_mode = false;
+    _repl_state->partial_load_source.clear();
 #endif
-        _repl_state->janet_buffer.clear();
-        _repl_state->zelph_buffer.clear();
-        _repl_state->accumulating_inline_janet = false;
-        _repl_state->accumulating_zelph        = false;
-        _repl_state->script_mode               = ScriptMode::Zelph;
-        _repl_state->reset_requested           = false;
-        _repl_state->accumulating_keyword      = false;
-        _repl_state->active_keyword.clear();
-        _repl_state->keyword_buffer.clear();
-        _repl_state->last_graph_html_path.clear();
-
-        zelph::string::reset_last_node();
-
-        init(output);
-        _n->out("Cleared network and re-initialized core nodes.");
+    _repl_state->janet_buffer.clear();
+    _repl_state->zelph_buffer.clear();
+    _repl_state->accumulating_inline_janet = false;
+    _repl_state->accumulating_zelph        = false;
+    _repl_state->script_mode               = ScriptMode::Zelph;
+    _repl_state->reset_requested           = false;
+    _repl_state->accumulating_keyword      = false;
+    _repl_state->active_keyword.clear();
+    _repl_state->keyword_buffer.clear();
+    _repl_state->last_graph_html_path.clear();
+    _repl_state->included.clear();
+
+    zelph::string::reset_last_node();
+
+    {
+        std::lock_guard<std::mutex> lock(_mtx_new_facts);
+        _new_facts.clear();
     }
 
-    // Member function to delegate to CommandExecutor
-    void process_command(const std::vector<std::string>& cmd);
+    // The facts the syncs owned went with the network.
+    for (auto& [name, sync] : _syncs)
+        sync.facts.clear();
+
+    init(output);
+    _n->out("Cleared network and re-initialized core nodes.");
+}
 
-    std::unique_ptr<network::Reasoning> _n;
-    std::unique_ptr<ScriptEngine>       _script_engine;
-    std::unique_ptr<CommandExecutor>    _command_executor;
-    std::shared_ptr<ReplState>          _repl_state;
+// Delegation method
+void console::Interactive::Impl::process_command(const std::vector<std::string>& cmd)
+{
+    _command_executor->execute(cmd);
 
-    Impl(const Impl&)            = delete;
-    Impl& operator=(const Impl&) = delete;
+    if (_repl_state->reset_requested)
+    {
+        _repl_state->reset_requested = false;
+        reset_reasoning();
+    }
 
-private:
-    const Interactive* _interactive;
-};
+    if (_repl_state->journal_requested)
+    {
+        _repl_state->journal_requested = false;
+        open_journal(_repl_state->journal_request);
+    }
+}
 
 console::Interactive::Interactive(io::OutputHandler output)
     : _pImpl(new Impl(this, std::move(output)))
@@ -141,14 +189,59 @@ console::Interactive::Interactive(io::OutputHandler output)
 
 console::Interactive::~Interactive()
 {
+    _pImpl->stop_schedules();
     delete _pImpl;
 }
 
 void console::Interactive::process_file(const std::string& file, const std::vector<std::string>& args) const
 {
+    const auto lock = _pImpl->write_lock();
     _pImpl->_command_executor->import_file(file, args);
 }
 
+void console::Interactive::process_script(std::istream& in) const
+{
+    const auto lock   = _pImpl->write_lock();
+    auto       errors = _pImpl->process_stream(in);
+    if (!errors.empty()) throw script_error(std::move(errors));
+}
+
+void console::Interactive::process_datalog(std::istream& in) const
+{
+    const auto lock   = _pImpl->write_lock();
+    auto       errors = _pImpl->process_stream(in, true);
+    if (!errors.empty()) throw script_error(std::move(errors));
+}
+
+void console::Interactive::load_stdlib() const
+{
+    const auto lock = _pImpl->write_lock();
+
+    // As in constrain, a rule that exists already is kept and the new one
+    // removed, so that loading the ontology twice adds nothing. The rules
+    // of the relation declarations are not among the new ones when loaded
+    // again, and replace a rule of the same text when loaded first (see
+    // network::Reasoning::declare_relation_property), so the rules that
+    // existed before are taken after the script.
+    std::set<uint64_t> earlier;
+    for (const Rule& rule : rules())
+        earlier.insert(rule.id);
+
+    std::istringstream in(io::base_ontology);
+    process_script(in);
+
+    std::set<std::string> known_rules;
+    const auto            all = rules();
+    for (const Rule& rule : all)
+    {
+        if (earlier.count(rule.id) == 1) known_rules.insert(rule.text);
+    }
+    for (const Rule& rule : all)
+    {
+        if (earlier.count(rule.id) == 0 && !known_rules.insert(rule.text).second) remove_rule(rule.id);
+    }
+}
+
 std::string console::Interactive::get_version()
 {
     return network::Zelph::get_version();
@@ -156,11 +249,13 @@ std::string console::Interactive::get_version()
 
 bool console::Interactive::is_auto_run_active() const
 {
+    const auto lock = _pImpl->read_lock();
     return _pImpl->_repl_state->auto_run;
 }
 
 bool console::Interactive::is_accumulating() const
 {
+    const auto lock = _pImpl->read_lock();
     const auto& s = _pImpl->_repl_state;
     return s->accumulating_zelph
         || s->accumulating_inline_janet
@@ -168,8 +263,68 @@ bool console::Interactive::is_accumulating() const
         || s->script_mode == ScriptMode::Janet;
 }
 
+std::vector<std::string> console::Interactive::commands() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_command_executor->command_names();
+}
+
 void console::Interactive::process(std::string line) const
 {
+    const auto lock = _pImpl->write_lock();
+    // Stage currently being executed, reported via process_error::kind().
+    ProcessErrorKind kind   = ProcessErrorKind::Statement;
+    size_t           column = 0; // of a syntax error, see process_error::column
+
+    // Facts stated by a line without a following run are reported when it ends.
+    struct NewFactsGuard
+    {
+        Impl* impl;
+        ~NewFactsGuard()
+        {
+            try
+            {
+                impl->report_new_facts();
+            }
+            catch (...)
+            {
+            }
+        }
+    } new_facts_guard{_pImpl};
+
+    // The line is journaled when it ends without an error, followed by the
+    // facts deduced meanwhile; lines of an .import are covered by the
+    // .import line.
+    struct JournalGuard
+    {
+        Impl*              impl;
+        const std::string& line;
+        bool               record{impl->_process_depth++ == 0};
+        int                exceptions{std::uncaught_exceptions()};
+        ~JournalGuard()
+        {
+            if (--impl->_process_depth > 0) return;
+            try
+            {
+                impl->write_journal(record && std::uncaught_exceptions() == exceptions ? &line : nullptr);
+            }
+            catch (std::exception& ex)
+            {
+                impl->_n->error(ex.what(), true);
+            }
+        }
+    } journal_guard{_pImpl, line};
+
+    // Facts stated by a line that is not part of a script come from input.
+    // The origin is restored through _pImpl, as .new replaces the network.
+    struct OriginGuard
+    {
+        Impl*                          impl;
+        network::Reasoning::Provenance previous{impl->_n->origin()};
+        ~OriginGuard() { impl->_n->set_origin(previous); }
+    } origin_guard{_pImpl};
+    if (origin_guard.previous.source.empty()) _pImpl->_n->set_origin({"input"});
+
     try
     {
         auto& state = _pImpl->_repl_state;
@@ -184,6 +339,7 @@ void console::Interactive::process(std::string line) const
                 _pImpl->_n->profiler_reset_epoch();
 
                 bool dispatched = false;
+                kind            = ProcessErrorKind::Script;
                 try
                 {
                     dispatched = _pImpl->_script_engine->invoke_keyword(
@@ -206,8 +362,9 @@ void console::Interactive::process(std::string line) const
                     state->keyword_buffer.clear();
                     state->keyword_prev_blank = false;
 
+                    kind = ProcessErrorKind::Reasoning;
                     if (state->auto_run)
-                        _pImpl->_n->run(true, false, false, true);
+                        _pImpl->auto_run();
                 }
                 else
                 {
@@ -237,7 +394,9 @@ void console::Interactive::process(std::string line) const
 
             if (!parts.empty() && !parts[0].empty() && parts[0][0] == '.')
             {
+                if (parts[0] == ".journal") journal_guard.record = false;
                 _pImpl->_n->profiler_reset_epoch();
+                kind = ProcessErrorKind::Command;
                 _pImpl->process_command(parts);
                 return;
             }
@@ -253,12 +412,14 @@ void console::Interactive::process(std::string line) const
 
             if (zelph::ScriptEngine::is_expression_complete(state->janet_buffer))
             {
+                kind = ProcessErrorKind::Script;
                 _pImpl->_script_engine->process_janet(state->janet_buffer, false);
                 state->janet_buffer.clear();
                 state->accumulating_inline_janet = false;
 
+                kind = ProcessErrorKind::Reasoning;
                 if (state->auto_run)
-                    _pImpl->_n->run(true, false, false, true);
+                    _pImpl->auto_run();
             }
             return;
         }
@@ -282,10 +443,12 @@ void console::Interactive::process(std::string line) const
                     state->janet_buffer.clear();
                     state->script_mode = ScriptMode::Zelph;
 
+                    kind = ProcessErrorKind::Script;
                     _pImpl->_script_engine->process_janet(code, false);
 
+                    kind = ProcessErrorKind::Reasoning;
                     if (state->auto_run)
-                        _pImpl->_n->run(true, false, false, true);
+                        _pImpl->auto_run();
                 }
                 else
                 {
@@ -310,10 +473,12 @@ void console::Interactive::process(std::string line) const
             if (zelph::ScriptEngine::is_expression_complete(janet_code))
             {
                 _pImpl->_n->profiler_reset_epoch();
+                kind = ProcessErrorKind::Script;
                 _pImpl->_script_engine->process_janet(janet_code, false);
 
+                kind = ProcessErrorKind::Reasoning;
                 if (state->auto_run)
-                    _pImpl->_n->run(true, false, false, true);
+                    _pImpl->auto_run();
             }
             else
             {
@@ -367,11 +532,13 @@ void console::Interactive::process(std::string line) const
         state->zelph_buffer.clear();
         state->accumulating_zelph = false;
 
+        kind                    = ProcessErrorKind::Syntax;
         std::string transformed = _pImpl->_script_engine->parse_zelph_to_janet(complete_stmt);
 
         if (!transformed.empty())
         {
             _pImpl->_n->profiler_reset_epoch();
+            kind = ProcessErrorKind::Statement;
             _pImpl->_script_engine->process_janet(transformed, true);
         }
         else
@@ -379,92 +546,107 @@ void console::Interactive::process(std::string line) const
             size_t u_first = complete_stmt.find_first_not_of(" \t\n");
             if (u_first != std::string::npos)
             {
+                // The statement ends with this line; an error in an earlier
+                // line of it has no column here.
+                const size_t offset     = _pImpl->_script_engine->syntax_error_offset(complete_stmt);
+                const size_t line_start = complete_stmt.size() - line.size();
+                if (offset != std::string::npos && offset >= line_start && offset < complete_stmt.size())
+                {
+                    column = 1 + static_cast<size_t>(std::count_if(line.begin(), line.begin() + static_cast<std::ptrdiff_t>(offset - line_start), [](const char c)
+                                                                   { return (static_cast<unsigned char>(c) & 0xC0) != 0x80; }));
+                }
                 throw std::runtime_error("Syntax error: Could not parse statement.");
             }
         }
 
+        kind = ProcessErrorKind::Reasoning;
         if (state->auto_run)
         {
-            _pImpl->_n->run(true, false, false, true);
+            _pImpl->auto_run();
         }
     }
+    catch (const process_error& ex)
+    {
+        // Nested input (.import): keep the innermost kind, prefix this line.
+        throw process_error("Error in line \"" + line + "\": " + ex.what(), line, ex.kind(), ex.reason());
+    }
+    catch (const network::reasoning_cancelled& ex)
+    {
+        _pImpl->report_error(ProcessErrorKind::Cancelled, line, ex.what());
+        throw process_error("Error in line \"" + line + "\": " + ex.what(), line, ProcessErrorKind::Cancelled, ex.what());
+    }
+    catch (const network::reasoning_limit_exceeded& ex)
+    {
+        _pImpl->report_error(ProcessErrorKind::ResourceLimit, line, ex.what());
+        throw process_error("Error in line \"" + line + "\": " + ex.what(), line, ProcessErrorKind::ResourceLimit, ex.what());
+    }
+    catch (const assertion_failed& ex)
+    {
+        _pImpl->report_error(ProcessErrorKind::Assertion, line, ex.what());
+        throw process_error("Error in line \"" + line + "\": " + ex.what(), line, ProcessErrorKind::Assertion, ex.what());
+    }
     catch (std::exception& ex)
     {
-        throw std::runtime_error("Error in line \"" + line + "\": " + ex.what());
+        _pImpl->report_error(kind, line, ex.what(), column);
+        throw process_error("Error in line \"" + line + "\": " + ex.what(), line, kind, ex.what(), column);
     }
 }
 
 void console::Interactive::import_file(const std::string& file) const
 {
+    const auto lock = _pImpl->write_lock();
     _pImpl->_command_executor->import_file(file);
 }
 
-// Delegation method
-void console::Interactive::Impl::process_command(const std::vector<std::string>& cmd)
+void console::Interactive::run(const bool print_deductions, const bool generate_markdown, const bool suppress_repetition) const
 {
-    _command_executor->execute(cmd);
-
-    if (_repl_state->reset_requested)
-    {
-        _repl_state->reset_requested = false;
-        reset_reasoning();
-    }
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->run(print_deductions, generate_markdown, suppress_repetition);
 }
 
-void console::Interactive::run(const bool print_deductions, const bool generate_markdown, const bool suppress_repetition) const
+void console::Interactive::cancel() const
 {
-    _pImpl->_n->run(print_deductions, generate_markdown, suppress_repetition);
+    _pImpl->_n->request_cancel();
 }
 
 std::string console::Interactive::get_lang() const
 {
+    const auto lock = _pImpl->read_lock();
     return _pImpl->_n->get_lang();
 }
 
 void console::Interactive::set_output_handler(io::OutputHandler output) const
 {
+    const auto lock = _pImpl->read_lock();
     _pImpl->_n->set_output_handler(std::move(output));
 }
 
 void console::Interactive::out(const std::string& text, bool newline) const
 {
+    const auto lock = _pImpl->read_lock();
     _pImpl->_n->emit(io::OutputChannel::Out, text, newline);
 }
 
 void console::Interactive::err(const std::string& text, bool newline) const
 {
+    const auto lock = _pImpl->read_lock();
     _pImpl->_n->emit(io::OutputChannel::Error, text, newline);
 }
 
 void console::Interactive::log(const std::string& text, bool newline) const
 {
+    const auto lock = _pImpl->read_lock();
     _pImpl->_n->emit(io::OutputChannel::Diagnostic, text, newline);
 }
 
 void console::Interactive::prompt(const std::string& text, bool newline) const
 {
+    const auto lock = _pImpl->read_lock();
     _pImpl->_n->emit(io::OutputChannel::Prompt, text, newline);
 }
 
 std::string console::Interactive::take_last_graph_html() const
 {
+    const auto lock = _pImpl->write_lock();
     return std::exchange(_pImpl->_repl_state->last_graph_html_path, std::string{});
 }
-
-#ifdef PROVIDE_C_INTERFACE
-console::Interactive interactive;
-
-extern "C" void zelph_process_c(const char* line, size_t len)
-{
-    if (len > 0)
-    {
-        std::string l(line, 0, len);
-        interactive.process(l);
-    }
-}
-
-extern "C" void zelph_run()
-{
-    interactive.run(true, false, false);
-}
-#endif
@@ -25,11 +25,27 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #pragma once
 
+#include "io/bulk_loader.hpp"
+#include "io/conceptnet.hpp"
+#include "io/csv.hpp"
+#include "io/datalog.hpp"
+#include "io/row_template.hpp"
+#include "io/wordnet.hpp"
+#include "io/fact_store.hpp"
 #include "io/output.hpp"
+#include "network/run_progress.hpp"
 
 #include <zelph_export.h>
 
+#include <chrono>
+#include <cstdint>
+#include <functional>
+#include <iosfwd>
+#include <map>
+#include <memory>
+#include <optional>
 #include <string>
+#include <utility>
 #include <vector>
 
 namespace zelph::console
@@ -37,6 +53,25 @@ namespace zelph::console
     // The command-line interface (REPL). It manages user input, translates commands into operations
     // on the DataManager or zelph instance, and visualizes results. It holds the current state of
     // how the data was loaded via the DataManager.
+    //
+    // Concurrency: one instance may be shared by several threads. Methods
+    // that only read the network (facts, rules, explain, conflicts,
+    // confidence, validity, contexts, in_transaction, the export_*
+    // methods, get_lang, thread_count, fixpoint_reached, metrics, is_auto_run_active,
+    // is_accumulating, commands and output via out/err/log/prompt) may run in parallel
+    // with each other. The query variants (query, query_each,
+    // query_bounded, query_report, query_probabilities, aggregate,
+    // explain_query, ask) run in parallel with the readers, but one at a
+    // time among themselves, since they evaluate through the Janet VM. All
+    // other methods, including sparql, are writers: they run one at a time
+    // and wait for running readers and queries, which in turn wait for
+    // them. A reader or query, e.g. through an answer handler, must not
+    // call a writer. cancel() never
+    // waits and is meant to be called while a writer runs. Callbacks
+    // (on_deduction, on_event, on_progress, confidence combiners, output handlers
+    // running on a reasoning thread) are called while a writer holds the instance and
+    // must not call back into it. A loader returned by bulk_loader is not
+    // covered by this and counts as a writer for as long as it is fed.
     class ZELPH_EXPORT Interactive
     {
     public:
@@ -46,12 +81,1154 @@ namespace zelph::console
         void               import_file(const std::string& file) const;
         void               process(std::string line) const;
         void               run(const bool print_deductions, const bool generate_markdown, const bool suppress_repetition) const;
+
+        // Stops a reasoning pass that is in progress on another thread (see
+        // network::Reasoning::request_cancel). The interrupted run() or
+        // process() throws; facts derived until then are kept. Without a
+        // run in progress, nothing happens.
+        void cancel() const;
+
+        // Number of worker threads used by run() (see .threads); 0 selects
+        // one per hardware thread. Deductions are inserted in the same order
+        // for every thread count.
+        void   set_thread_count(size_t count) const;
+        size_t thread_count() const;
+
+        // Deterministic mode (see .deterministic and
+        // network::Reasoning::set_deterministic), off by default: identical
+        // input gives the same deductions and the same answers, in the same
+        // order, at the cost of sorting the candidate facts of each match.
+        void set_deterministic(bool on) const;
+        bool deterministic() const;
+
+        // Fact lookup orientations the matcher may use, as a bit mask of
+        // network::Zelph::TripleIndex: 1 = SPO, 2 = POS, 4 = OSP (see
+        // .indexes). POS is always included. Each condition uses the
+        // enabled one with the fewest candidate facts.
+        void    set_triple_indexes(uint8_t indexes) const;
+        uint8_t triple_indexes() const;
+
+        // Resource limits of run() and of the runs after process(), 0 for
+        // none: the facts a run may deduce and the memory the process may
+        // use (see .max-facts and .max-memory). A run exceeding one stops
+        // and keeps the facts deduced so far; process() then throws a
+        // console::process_error of kind ResourceLimit, run() a
+        // network::reasoning_limit_exceeded.
+        void set_max_facts(uint64_t count) const;
+        void set_max_memory(size_t bytes) const;
+
+        // Limit on how deeply rule firings may nest the terms they build
+        // (see .max-term-depth), 0 for none. Exceeding it stops the run
+        // like a resource limit, with a reason naming the rule.
+        void set_max_term_depth(uint32_t depth) const;
+
+        // Bounds of run() and of the runs after process(), 0 for none: the
+        // iterations and deductions after which a run pauses (see
+        // .max-iterations and .max-deductions). A paused run returns
+        // normally and fixpoint_reached() is false until a later run
+        // finishes the work; each run continues where the last one stopped.
+        void set_max_iterations(int count) const;
+        void set_max_deductions(uint64_t count) const;
+        bool fixpoint_reached() const;
+
+        // Registers a callback for every new fact a rule deduces during run()
+        // or process() (see network::Reasoning::set_deduction_observer).
+        // Facts and rules are given by node ID and rendered like REPL output.
+        // The callback runs on a reasoning thread, one call at a time, and
+        // must not call back into this instance. An empty callback removes it.
+        struct Deduction
+        {
+            uint64_t    fact;
+            uint64_t    rule;
+            std::string fact_text;
+            std::string rule_text;
+        };
+        using DeductionCallback = std::function<void(const Deduction&)>;
+        void on_deduction(DeductionCallback callback) const;
+
+        // Registers a callback reporting the progress of run() and of the
+        // runs after process(): about every interval_seconds while a run is
+        // in progress, from a helper thread, and once more when it completes
+        // or pauses (see network::Reasoning::set_progress_observer). Like
+        // on_deduction, it must not call back into this instance. An empty
+        // callback removes it.
+        using ProgressCallback = std::function<void(const network::RunProgress&)>;
+        void on_progress(ProgressCallback callback, double interval_seconds = 1.0) const;
+
+        // Registers a callback for structured log events, each a type and
+        // key/value attributes in a fixed order:
+        //   fact_added     fact, fact_text         a fact stated via process()
+        //   rule_fired     fact, rule, fact_text,  a rule deduced a new fact
+        //                  rule_text
+        //   contradiction  rule, rule_text, then   a run detected a new
+        //                  fact, fact_text per     contradiction (see conflicts)
+        //                  matched fact
+        //   schema_violation fact, fact_text,      the contradiction broke a
+        //                  relation, position,     domain or range constraint
+        //                  node, expected          (see constrain)
+        //   parse_error    kind, line, reason,     a line of process() failed
+        //                  column if known           at parsing or a later stage
+        //   error          kind, line, reason        (see process_error::column)
+        //   sync_error     name, reason            a scheduled sync failed
+        // Nodes are given by ID and rendered like REPL output. rule_fired and
+        // contradiction are sent from a reasoning thread, one call at a time;
+        // like on_deduction, the callback must not call back into this
+        // instance. An empty callback removes it.
+        struct Event
+        {
+            std::string                                      type;
+            std::vector<std::pair<std::string, std::string>> attributes;
+        };
+        using EventCallback = std::function<void(const Event&)>;
+        void on_event(EventCallback callback) const;
+
+        // Monitoring of a long-running instance (see .metrics): the network
+        // size as gauges and, while collection is enabled, counters of runs,
+        // deductions per rule, contradictions and query latencies, in the
+        // Prometheus text exposition format (see io::prometheus_metrics).
+        // Collection is off by default; enabling it starts from zero.
+        void        set_metrics_enabled(bool enabled) const;
+        std::string metrics() const;
+
+        // Rule coverage and cost (see .rule-profile), off by default: while
+        // enabled, runs record for each rule its firings, the new facts they
+        // produced and the time spent evaluating it. rule_profile returns the
+        // records of the last such run, the most expensive rule first and
+        // with the rules that never fired (firings 0) included, and
+        // rule_profile_report the same as a pprof-style table (see
+        // io::rule_profile_report).
+        struct RuleProfile
+        {
+            uint64_t    rule;
+            std::string text;
+            uint64_t    firings;
+            uint64_t    deductions;
+            double      seconds;
+        };
+        void                     set_rule_profiling(bool enabled) const;
+        std::vector<RuleProfile> rule_profile() const;
+        std::string              rule_profile_report() const;
+
+        // Shape of the network (see .graph-stats and io::GraphStats):
+        // statements, edges, weakly connected components, the relations with
+        // their number of statements (most frequent first) and the in- and
+        // out-degree distributions (degree -> number of nodes).
+        struct GraphStats
+        {
+            struct Relation
+            {
+                uint64_t    predicate;
+                std::string name;
+                uint64_t    statements;
+            };
+            uint64_t                     nodes{0};
+            uint64_t                     statements{0};
+            uint64_t                     edges{0};
+            uint64_t                     components{0};
+            std::vector<Relation>        relations;
+            std::map<uint64_t, uint64_t> in_degrees;
+            std::map<uint64_t, uint64_t> out_degrees;
+        };
+        GraphStats graph_stats() const;
+
+        // The figures of .stat: nodes, rules, the languages that have names
+        // and the memory the process uses (0 where it cannot be measured).
+        struct Statistics
+        {
+            uint64_t                 nodes{0};
+            uint64_t                 rules{0};
+            std::vector<std::string> languages;
+            size_t                   memory_bytes{0};
+        };
+        Statistics statistics() const;
+
+        // How two nodes, given by name in the current language, are
+        // connected (see .paths and network::Zelph::find_paths): up to
+        // max_paths simple paths of at most max_depth statements (0: no
+        // bound), shortest first, optionally following only the given
+        // relations or only from subject to object. Each step names the
+        // statement it follows; forward is false where it leads from an
+        // object back to the subject. An unknown name is thrown as
+        // console::process_error.
+        struct PathOptions
+        {
+            size_t                   max_paths{1};
+            size_t                   max_depth{6};
+            std::vector<std::string> relations;
+            bool                     directed{false};
+        };
+        struct PathStep
+        {
+            uint64_t    fact;
+            uint64_t    from;
+            uint64_t    predicate;
+            uint64_t    to;
+            bool        forward;
+            std::string from_name;
+            std::string predicate_name;
+            std::string to_name;
+        };
+        using Path = std::vector<PathStep>;
+        std::vector<Path> find_paths(const std::string& from, const std::string& to, const PathOptions& options) const;
+
+        // A bounded slice of the network as an independent instance, e.g.
+        // to export only the ancestry part of a large knowledge base: the
+        // statements within depth steps of the roots (named in the current
+        // language), following only the relations that follow accepts by
+        // name (all if empty). Nodes keep their names in all languages;
+        // deduced statements become stated ones, rules are not copied.
+        // subgraph returns a new instance with the same output handler,
+        // copy_subgraph adds the statements to target (which must be another
+        // instance) and returns their number. An unknown root is thrown as
+        // console::process_error.
+        using RelationFilter = std::function<bool(const std::string& relation)>;
+        std::unique_ptr<Interactive> subgraph(const std::vector<std::string>& roots, int depth, const RelationFilter& follow = {}) const;
+        size_t                       copy_subgraph(const std::vector<std::string>& roots, int depth, const RelationFilter& follow, const Interactive& target) const;
+
+        // Lets an ontology drive inference: translates the axioms of a
+        // pragmatic OWL subset stated in the network (subClassOf, domain,
+        // range, inverseOf, symmetric and transitive properties, e.g. loaded
+        // from a Turtle file) into rules (see .owl-rules and io::owl_rules).
+        // Returns the axioms found, the rules added and the axioms skipped.
+        struct OwlTranslation
+        {
+            size_t axioms{0};
+            size_t rules{0};
+            size_t skipped{0};
+        };
+        OwlTranslation owl_rules() const;
+
         std::string        get_lang() const;
         static std::string get_version();
         bool               is_auto_run_active() const;
         bool               is_accumulating() const;
         void               process_file(const std::string& file, const std::vector<std::string>& args = {}) const;
 
+        // The names of the REPL commands (e.g. ".help"), sorted; used to
+        // complete them (see repl::Repl).
+        std::vector<std::string> commands() const;
+
+        // Processes a whole script (the content of a .zph file) like
+        // process_file: statements and Janet blocks may span lines, auto-run
+        // is suspended until the end. Unlike process_file, a failing line
+        // does not end the script; the remaining lines are processed and a
+        // console::script_error listing every failed line with its number is
+        // thrown at the end. Only a cancellation stops the script early.
+        void process_script(std::istream& in) const;
+
+        // Processes a Datalog program like process_script: each clause is
+        // translated into a zelph statement (see io::translate_datalog), so
+        // that parent(paul, peter). states "paul parent peter" and
+        // ancestor(X, Y) :- parent(X, Y). adds a rule. Failed clauses are
+        // thrown as one console::script_error at the end, with the lines
+        // they start on. .import reads files ending in .dl the same way.
+        void process_datalog(std::istream& in) const;
+
+        // Loads the base ontology built into zelph (io::base_ontology, the
+        // same as .import base): is-a (~) and part-of as transitive
+        // relations, "is opposite of" as a symmetric one and the temporal
+        // relations "is before", "is after" and "is during", all declared
+        // with declare_relation. Loading it again adds nothing.
+        void load_stdlib() const;
+
+        // Keeps an append-only journal of the input (see .journal and
+        // io::Journal): replays file if it exists, which rebuilds the network
+        // of an earlier session, then appends every line that process()
+        // accepts, followed by the facts deduced from it. Failed replayed
+        // lines are thrown as console::script_error once the journal is open,
+        // a file that cannot be opened as console::process_error. An empty
+        // file closes the journal; journal() returns the open one or "".
+        // Only the input of process() is journaled, not other methods.
+        void        set_journal(const std::string& file) const;
+        std::string journal() const;
+
+        // Answers a zelph statement with variables (e.g. "X ~ human") and
+        // returns the bindings instead of printing "Answer: ..." lines. Each
+        // element maps a variable name to its value, rendered like REPL
+        // output. A statement without variables is rejected rather than
+        // stated. Errors are thrown as console::process_error.
+        using QueryBinding = std::map<std::string, std::string>;
+        std::vector<QueryBinding> query(const std::string& statement) const;
+
+        // Like query, but also tells how the statement was understood:
+        // pattern is the parsed statement, rendered like REPL output, and
+        // notice is the REPL's answer to a query without answers (see
+        // .no-matches and network::Reasoning::no_match_notice), whether or
+        // not that is switched on; it is empty if there are answers.
+        struct QueryReport
+        {
+            std::string               pattern;
+            std::vector<QueryBinding> answers;
+            std::string               notice;
+        };
+        QueryReport query_report(const std::string& statement) const;
+
+        // Like query, but streams the answers: each is handed to the handler
+        // as soon as it is found, so the bindings of a query with millions of
+        // answers are never held at once. A handler that takes its time
+        // holds up the evaluation; one that returns false stops it. offset
+        // skips the first answers and a limit other than 0 stops after that
+        // many, e.g. for pages of an HTTP API; answers come in the order
+        // they are found, which is the same for every call while the network
+        // does not change. Returns the number of answers handed over. The
+        // handler must not call back into this object, which stays locked
+        // until the query ends. Errors are thrown as console::process_error.
+        using AnswerHandler = std::function<bool(const QueryBinding& answer)>;
+        size_t query_each(const std::string& statement, const AnswerHandler& handler, size_t offset = 0, size_t limit = 0) const;
+
+        // Like query, but bounded (see .query-limits and
+        // network::Reasoning::set_query_timeout): the query stops after
+        // timeout or once it has processed max_matches matches, 0 for no
+        // bound, instead of the session's limits. A stopped query returns
+        // the answers found so far with truncated set, e.g. for queries of
+        // interactive users that must not stall the engine.
+        struct QueryOptions
+        {
+            std::chrono::milliseconds timeout{0};
+            uint64_t                  max_matches{0};
+        };
+        struct BoundedResult
+        {
+            std::vector<QueryBinding> answers;
+            bool                      truncated{false};
+            std::string               reason; // the bound that stopped the query
+        };
+        BoundedResult query_bounded(const std::string& statement, const QueryOptions& options) const;
+
+        // Like query, but leaves out answers whose confidence is below
+        // min_confidence (see .min-confidence) instead of the session's
+        // threshold.
+        std::vector<QueryBinding> query(const std::string& statement, double min_confidence) const;
+
+        // Like query, but leaves out answers unless all the facts they
+        // matched are valid at the given time point (see .as-of).
+        std::vector<QueryBinding> query_as_of(const std::string& statement, int64_t time) const;
+
+        // Like query, but only uses facts holding in at least one of the
+        // contexts (see .context-scope) instead of the session's scope.
+        std::vector<QueryBinding> query_in(const std::string& statement, const std::vector<std::string>& contexts) const;
+
+        // Like query, but also returns the probability of each answer: the
+        // product of the probabilities of the facts it matched. After a run
+        // in probabilistic mode (see set_probabilistic), deduced facts hold
+        // their marginal probabilities.
+        struct WeightedBinding
+        {
+            QueryBinding binding;
+            double       probability;
+        };
+        std::vector<WeightedBinding> query_probabilities(const std::string& statement) const;
+
+        // Aggregates the answers of a query instead of returning them, e.g.
+        // aggregate("paul has_descendant X", {}, {Aggregate::count()}). The
+        // answers are grouped by their values of the group_by variables;
+        // each row holds those values and one value per aggregate: count()
+        // counts the answers, count("X") the distinct values of X, min and
+        // max compare numerically if all values are numbers and as text
+        // otherwise, and sum adds numbers (integers exactly). Rows are
+        // ordered by group. Without group_by there is exactly one row, in
+        // which min and max are empty and count and sum 0 if there are no
+        // answers. A variable the answers do not bind, or a sum over a value
+        // that is not a number, is a process_error of kind Statement.
+        struct Aggregate
+        {
+            enum class Function
+            {
+                Count,
+                Min,
+                Max,
+                Sum
+            };
+            Function    function{Function::Count};
+            std::string variable;
+
+            static Aggregate count(std::string variable = "") { return {Function::Count, std::move(variable)}; }
+            static Aggregate min(std::string variable) { return {Function::Min, std::move(variable)}; }
+            static Aggregate max(std::string variable) { return {Function::Max, std::move(variable)}; }
+            static Aggregate sum(std::string variable) { return {Function::Sum, std::move(variable)}; }
+        };
+        struct AggregateRow
+        {
+            QueryBinding             group;
+            std::vector<std::string> values;
+        };
+        std::vector<AggregateRow> aggregate(const std::string& statement, const std::vector<std::string>& group_by, const std::vector<Aggregate>& aggregates) const;
+
+        // Answers a single fact pattern built from terms instead of a
+        // statement, so names need no quoting or escaping (e.g. a pattern
+        // of Term::var("A"), Term::constant("is ancestor of") and
+        // Term::constant("pius")). A constant is the name of a node in the
+        // current language; if there is none, there are no answers and the
+        // network is left unchanged. A variable is bound under its name in
+        // the answers, the same name meaning the same variable; one without
+        // a name matches anything and is not reported. A pattern without
+        // variables has one empty answer if the fact holds. Errors are
+        // thrown as console::process_error.
+        struct Term
+        {
+            bool        variable{false};
+            std::string name;
+
+            static Term var(std::string name = "") { return {true, std::move(name)}; }
+            static Term constant(std::string name) { return {false, std::move(name)}; }
+        };
+        struct Triple
+        {
+            Term subject;
+            Term predicate;
+            Term object;
+        };
+        std::vector<QueryBinding> match(const Triple& pattern) const;
+
+        // Answers a SPARQL SELECT query (the subset of stdlib/sparql.zph,
+        // which is imported on first use). variables lists the projected
+        // variables in order; each row maps them to their values, rendered
+        // like the sparql keyword's output. Unbound variables are missing
+        // from a row. Errors are thrown as console::process_error.
+        struct SparqlResult
+        {
+            std::vector<std::string>  variables;
+            std::vector<QueryBinding> rows;
+        };
+        SparqlResult sparql(const std::string& query) const;
+
+        // All statements of the network (see io::exportable_facts: rules,
+        // predicate declarations and facts containing variables are left
+        // out), ordered by fact node ID. Names are rendered like REPL output;
+        // deduced tells whether a rule derived the fact in this session.
+        struct Fact
+        {
+            uint64_t                 id;
+            std::string              subject;
+            std::string              predicate;
+            std::vector<std::string> objects;
+            bool                     deduced;
+        };
+        std::vector<Fact> facts() const;
+
+        // facts() for callers that stream them, e.g. GET /facts: handler
+        // gets the statements in pages of at most page_size, in the same
+        // order and form. Only their IDs are kept for the whole listing; each
+        // page is rendered under the lock and handed over without it, so
+        // writers may run in between (statements removed meanwhile are left
+        // out) and the handler may call back into this instance.
+        using FactPageHandler = std::function<void(const std::vector<Fact>& page)>;
+        void facts_each(const FactPageHandler& handler, size_t page_size = 1000) const;
+
+        // The statements of facts() that match a pattern (see .facts): names
+        // are compared as rendered, a variable matches any name (the same
+        // name the same one) and the object term matches if any object does.
+        std::vector<Fact> facts(const Triple& pattern) const;
+
+        // A live feed of the network's changes, e.g. for dashboards: every
+        // fact stated (reported like fact_added events), retracted (with the
+        // deductions that stayed withdrawn, see retract) or deduced by a rule,
+        // as kind "added", "retracted" or "deduced". With a pattern, only
+        // facts matching it are reported; names are compared as rendered, a
+        // variable matches any name (the same name the same one) and the
+        // object term matches if any object does (see match for the terms).
+        // Any number of watches can be active; watch returns the ID for
+        // unwatch. Deductions are reported from a reasoning thread, one call
+        // at a time, together with the rule as on_deduction reports it; like
+        // on_event, the callback must not call back into this instance.
+        struct Change
+        {
+            std::string              kind;
+            Fact                     fact;
+            std::optional<Deduction> deduction; // only for "deduced"
+        };
+        using ChangeCallback = std::function<void(const Change&)>;
+        uint64_t watch(ChangeCallback callback, const std::optional<Triple>& pattern = std::nullopt) const;
+        void     unwatch(uint64_t watch) const;
+
+        // The statements two instances differ in, e.g. yesterday's network
+        // against today's, or the deductions of two versions of a rule set.
+        // Node IDs differ between instances, so statements are compared by
+        // their rendering as in facts(). added holds the statements of b
+        // missing in a, removed those of a missing in b, each with the IDs of
+        // its own instance and sorted like facts().
+        struct Diff
+        {
+            std::vector<Fact> added;
+            std::vector<Fact> removed;
+        };
+        static Diff diff(const Interactive& a, const Interactive& b);
+
+        // Removes a stated fact or rule (a Fact::id) and withdraws the
+        // deductions that lose their support; returns how many were
+        // withdrawn (see network::Reasoning::retract). Same as .retract.
+        // Errors are thrown as console::process_error.
+        size_t retract(uint64_t fact) const;
+
+        // Belief revision (see network::Reasoning::revise and .revise):
+        // removes a stated fact, optionally in favour of a replacement
+        // statement (e.g. "pluto ~ dwarf-planet"), which is stated first,
+        // and recomputes only the deductions that depended on the fact.
+        // Returns the deductions that stayed withdrawn. Errors are thrown
+        // as console::process_error.
+        std::vector<Fact> revise(uint64_t fact, const std::string& replacement = "") const;
+
+        // Transactions (see .begin): after begin, every node created by
+        // process, run or the other methods is recorded; commit keeps them,
+        // rollback removes them again and returns their number. Changes to
+        // nodes that existed before are not undone. Errors, e.g. begin
+        // inside an open transaction, are thrown as console::process_error.
+        void   begin() const;
+        void   commit() const;
+        size_t rollback() const;
+        bool   in_transaction() const;
+
+        // Speculative reasoning ("what if this fact held?") without copying
+        // the network: body runs inside a transaction that is always rolled
+        // back, so the statements, deductions and rules it adds are gone
+        // when speculate returns, also if body throws. New nodes live in an
+        // overlay of the network (see .cluster), which makes this cheap
+        // regardless of the network's size. Other threads wait until body
+        // returns. Names and settings body changes on existing nodes are
+        // not undone, and body must not commit. Cannot be used inside an
+        // open transaction; errors are thrown as console::process_error.
+        void speculate(const std::function<void(const Interactive&)>& body) const;
+
+        // Previews a run (see .run-dry): returns the facts run() would
+        // deduce now, rendered like facts(), and leaves the network as it
+        // was, so the effect of a new rule on a large network can be checked
+        // before it is run for real. The IDs of the returned facts no longer
+        // exist. Nothing is printed and no callback learns of the deductions
+        // (see network::Reasoning::run_dry). Cannot be used inside an open
+        // transaction; errors are thrown as console::process_error.
+        std::vector<Fact> run_dry() const;
+
+        // Why a rule deduced the fact (a Fact::id): the rule and the facts
+        // its conditions matched, deduced premises explained in turn (see
+        // network::Reasoning::explain). Stated premises have rule 0. Same
+        // as .explain. A fact that was not deduced in this session is
+        // thrown as console::process_error.
+        struct Proof
+        {
+            uint64_t           fact;
+            std::string        fact_text;
+            uint64_t           rule;
+            std::string        rule_text;
+            std::vector<Proof> premises;
+        };
+        Proof explain(uint64_t fact) const;
+
+        // Natural-language questions: the translator turns a question into
+        // zelph query statements, e.g. by prompting an LLM with the names
+        // of the network's relations, and ask() answers each statement like
+        // query(). With every answer come the proofs of the facts it matched
+        // (a stated fact is a Proof with rule 0), so that a paraphrase of
+        // the answer can be checked against the reasoning. A statement that
+        // fails has its error set instead of failing the question. The
+        // translator is called without the instance locked, so it may read
+        // the network through this instance; it survives .reset. Without a
+        // translator, or if it throws, ask() throws a process_error of kind
+        // Command. An empty translator removes it.
+        using Translator = std::function<std::vector<std::string>(const std::string& question)>;
+        struct AnsweredQuery
+        {
+            std::string                     statement;
+            std::string                     error;
+            std::vector<QueryBinding>       answers;
+            std::vector<std::vector<Proof>> proofs; // per answer, one per matched fact
+        };
+        struct QuestionAnswer
+        {
+            std::string                question;
+            std::vector<AnsweredQuery> queries;
+        };
+        void           set_translator(Translator translator) const;
+        QuestionAnswer ask(const std::string& question) const;
+
+        // Built-in predicates implemented by the embedder, e.g. regular
+        // expressions, distances or database lookups: a condition (S name O)
+        // of a rule or query is not matched against facts but answered by
+        // the function (see network::Reasoning::register_builtin). It gets
+        // the subject followed by the objects: a bound one with its node
+        // and its value rendered like REPL output, an unbound variable with
+        // its name. It returns one solution per match, binding variable
+        // names to values (names of nodes, created if needed); none fails
+        // the condition. If it throws, the run or query fails with a
+        // process_error carrying the message. Calls are serialized but may
+        // come from reasoning threads; the function must not call back into
+        // this instance. Register a builtin before stating the rules that
+        // use it; builtins survive .reset. An empty function removes one.
+        struct BuiltinArg
+        {
+            uint64_t    node{0}; // 0 for an unbound variable
+            std::string value;
+            std::string variable;
+        };
+        using BuiltinSolution = std::map<std::string, std::string>;
+        using BuiltinFunction = std::function<std::vector<BuiltinSolution>(const std::vector<BuiltinArg>& args)>;
+        void register_builtin(const std::string& name, BuiltinFunction function) const;
+
+        // Contradictions found by run() that still hold, each with the rule
+        // that detected it and the facts its conditions matched (see
+        // network::Reasoning::conflicts). Same as .conflicts; declare
+        // mutually exclusive relations with stdlib/exclusive.zph.
+        struct Conflict
+        {
+            uint64_t                 rule;
+            std::string              rule_text;
+            std::vector<uint64_t>    facts;
+            std::vector<std::string> fact_texts;
+        };
+        std::vector<Conflict> conflicts() const;
+
+        // Domain and range constraints (see stdlib/schema.zph): constrain
+        // declares that relation expects instances (~) of domain_class as
+        // subject and of range_class as object, an empty class leaving that
+        // side open, and adds the schema rules unless they exist. Like
+        // add_rule, it does not run the rules; statements that break the
+        // schema are found by the next run as contradictions, reported as
+        // schema_violation events (see on_event). schema_violations lists
+        // those that still hold with the statement, whether its subject or
+        // its object is affected, and the class expected. Errors are thrown
+        // as console::process_error.
+        struct SchemaViolation
+        {
+            uint64_t    fact;
+            std::string fact_text;
+            std::string relation;
+            bool        subject;
+            std::string node;
+            std::string expected;
+        };
+        void                         constrain(const std::string& relation, const std::string& domain_class, const std::string& range_class) const;
+        std::vector<SchemaViolation> schema_violations() const;
+
+        // Cardinality constraints: limit_cardinality declares that every
+        // subject has at most max objects of relation (R max_cardinality
+        // max; scripts can also state R ~ functional for a limit of 1), the
+        // smallest declared limit applying. validate checks the statements
+        // of the network against these limits and returns each subject that
+        // exceeds one, with the number of distinct objects it has and the
+        // statements that give them. Errors are thrown as
+        // console::process_error.
+        struct Violation
+        {
+            std::string              relation;
+            uint64_t                 subject;
+            std::string              subject_text;
+            size_t                   limit;
+            size_t                   count;
+            std::vector<uint64_t>    facts;
+            std::vector<std::string> fact_texts;
+        };
+        void                   limit_cardinality(const std::string& relation, size_t max) const;
+        std::vector<Violation> validate() const;
+
+        // SHACL shapes (see io/shacl.hpp for the supported subset): shapes
+        // stated in the network, e.g. loaded from a Turtle shapes graph,
+        // or declared with declare_shape, which states a node shape named
+        // shape targeting the instances of target_class, with one property
+        // shape per entry of properties (an empty value_class and a
+        // max_count of nullopt leave that constraint out). validate_shapes
+        // checks the focus nodes of all shapes and returns a report in the
+        // terms of a SHACL validation report: conforms is true if there are
+        // no results, and component is the name of the SHACL constraint
+        // component, e.g. sh:MinCountConstraintComponent. Errors are thrown
+        // as console::process_error.
+        struct PropertyShape
+        {
+            std::string           path;
+            std::string           value_class;
+            size_t                min_count{0};
+            std::optional<size_t> max_count;
+        };
+        struct ShapeResult
+        {
+            uint64_t    focus;
+            std::string focus_text;
+            std::string path;
+            std::string component;
+            uint64_t    value; // sh:ClassConstraintComponent: the nonconforming value, else 0
+            std::string value_text;
+            std::string message;
+        };
+        struct ShapeReport
+        {
+            bool                     conforms;
+            size_t                   shapes;
+            size_t                   focus_nodes;
+            size_t                   skipped;
+            std::vector<ShapeResult> results;
+        };
+        void        declare_shape(const std::string& shape, const std::string& target_class, const std::vector<PropertyShape>& properties) const;
+        ShapeReport validate_shapes() const;
+
+        // Combines another knowledge base into this one: the stated facts
+        // and the rules of other that this instance lacks are added
+        // (deductions are left to the rules), then the rules run. A
+        // contradiction that involves a merged fact is handled by policy:
+        // KeepBoth keeps it and reports it, PreferLeft retracts the merged
+        // facts it involves, and Fail undoes the whole merge and throws
+        // console::process_error. Fail cannot be used inside an open
+        // transaction (see begin).
+        enum class MergePolicy
+        {
+            KeepBoth,
+            PreferLeft,
+            Fail
+        };
+        struct MergeReport
+        {
+            size_t                facts_added{0};
+            size_t                facts_duplicate{0};
+            size_t                rules_added{0};
+            size_t                rules_duplicate{0};
+            std::vector<Conflict> conflicts; // new contradictions, as found before the policy applied
+            std::vector<Fact>     rejected;  // merged facts retracted under PreferLeft
+        };
+        MergeReport merge(const Interactive& other, MergePolicy policy) const;
+
+        // The inference rules of the network, ordered by node ID and
+        // rendered like .list-rules. A disabled rule is kept but skipped by
+        // run() (see network::Reasoning::set_rule_enabled). Rules run in
+        // strata, lowest first, each to a fixpoint before the next one
+        // joins (see network::Reasoning::set_rule_stratum). The deductions
+        // of a default rule are assumptions that contradictions override,
+        // by priority (see network::Reasoning::set_rule_default and
+        // .default-rule); defeats lists those withdrawn. add_rule states
+        // "condition => consequence" like a script line, without running
+        // the rules, and returns the rule's ID. Errors are thrown as
+        // console::process_error.
+        struct Rule
+        {
+            uint64_t    id;
+            std::string text;
+            bool        enabled;
+            int         stratum;
+            bool        is_default;
+            int         priority; // of a default rule
+        };
+        std::vector<Rule> rules() const;
+        uint64_t          add_rule(const std::string& condition, const std::string& consequence) const;
+        void              set_rule_enabled(uint64_t rule, bool enabled) const;
+        void              set_rule_stratum(uint64_t rule, int stratum) const;
+        void              set_rule_default(uint64_t rule, bool is_default, int priority = 0) const;
+        struct Defeat
+        {
+            Fact     assumption; // no longer in the network
+            uint64_t rule;       // the default rule that deduced it
+            uint64_t by;         // the rule that found the contradiction
+        };
+        std::vector<Defeat> defeats() const;
+        void              remove_rule(uint64_t rule) const;
+
+        // Rule templates (see .template, .apply and io::RuleTemplate):
+        // define_template("implies", {"R", "S"}, "(X R Y) => (X S Y)")
+        // declares the rule once, apply_template("implies", {"is mother
+        // of", "is parent of"}) adds it for two relations, or for each group
+        // of arguments if there are more than parameters. Identical to the
+        // commands; errors are thrown as console::process_error.
+        void define_template(const std::string& name, const std::vector<std::string>& parameters, const std::string& rule) const;
+        void apply_template(const std::string& name, const std::vector<std::string>& arguments) const;
+
+        // Declares a relation "transitive", "symmetric", "reflexive" or
+        // "inverse" of another (see .relation and
+        // network::Reasoning::declare_relation_property), e.g.
+        // declare_relation("parent", "inverse", "child"). Identical to the
+        // command; errors are thrown as console::process_error.
+        void declare_relation(const std::string& relation, const std::string& property, const std::string& inverse = "") const;
+
+        // Declares two concepts identical (see .same-as and
+        // network::Reasoning::merge_concepts): from is merged into into and
+        // its name resolves to into from now on. Returns the number of facts
+        // restated. Identical to the command; errors are thrown as
+        // console::process_error.
+        size_t merge_concepts(const std::string& from, const std::string& into) const;
+
+        // The is-a hierarchy (see .kind-of, .instances and
+        // network::Zelph::is_kind_of), answered from its index without
+        // applying rules: whether node is a kind of category, and the names
+        // of all nodes that are a kind of category, sorted. An unknown name
+        // is thrown as console::process_error.
+        bool                     is_kind_of(const std::string& node, const std::string& category) const;
+        std::vector<std::string> instances_of(const std::string& category) const;
+
+        // Materialized views (see .view and network::Reasoning::create_view):
+        // create_view registers a query (a statement with variables) under a
+        // name, replacing a view of that name; its answers are kept up to
+        // date as facts are stated or deduced and view reads them, in the
+        // form of query(). remove_view returns false for an unknown name.
+        // Errors, e.g. an unknown view, are thrown as console::process_error.
+        void                      create_view(const std::string& name, const std::string& statement) const;
+        std::vector<QueryBinding> view(const std::string& name) const;
+        bool                      remove_view(const std::string& name) const;
+        std::vector<std::string>  views() const;
+
+        // How a query or rule is evaluated (see .plan and
+        // network::Reasoning::query_plan): the leaf conditions in join
+        // order, each with its lookup orientation ("spo", "pos", "osp", or
+        // "none" for ≠) and the candidate facts it visits, per binding of
+        // the earlier steps if per_binding is set. explain_query takes a
+        // statement with variables like query; errors are thrown as
+        // console::process_error.
+        struct PlanStep
+        {
+            std::string              condition;
+            std::string              relation; // empty for a relation variable
+            std::string              access;
+            uint64_t                 candidates{0};
+            bool                     per_binding{false};
+            bool                     negated{false};
+            std::vector<std::string> binds;
+        };
+        std::vector<PlanStep> explain_query(const std::string& statement) const;
+        std::vector<PlanStep> explain_rule(uint64_t rule) const;
+
+        // Vocabularies without script text (see .name). alias gives a
+        // concept the name in lang and returns its ID. The concept is the
+        // node named concept_name in the current language, or the core node
+        // of that name (e.g. "~"), and is created if there is none. A node
+        // has one name per language, so a previous name of the concept in
+        // lang is replaced; a name already used by another node is thrown
+        // as console::process_error. resolve_name looks a name up in lang
+        // (the current language if empty), core node names included,
+        // without creating anything.
+        uint64_t                alias(const std::string& concept_name, const std::string& lang, const std::string& name) const;
+        std::optional<uint64_t> resolve_name(const std::string& name, const std::string& lang = "") const;
+
+        // "Did you mean" lookups (see .search): the concepts whose name in
+        // lang (the current language if empty) equals query, starts with
+        // it or contains it, in this order, and with fuzzy also names a
+        // few typos away (kind "exact", "prefix", "substring" or "fuzzy").
+        // Names are compared case-folded through an index that is built by
+        // the first search, so later searches do not walk all names.
+        // Substring and fuzzy matching need at least three characters.
+        struct ConceptMatch
+        {
+            uint64_t    id{0};
+            std::string name;
+            std::string kind;
+            uint32_t    distance{0}; // edits of a fuzzy match
+        };
+        std::vector<ConceptMatch> search_concepts(const std::string& query, size_t limit = 10, bool fuzzy = false, const std::string& lang = "") const;
+
+        // Concept suggestions for queries (see .suggest), off by default.
+        // When on, a query without answers that mentions a concept known
+        // only from queries, typically a misspelt name, is an error: query()
+        // throws a process_error of kind Statement whose reason names it and
+        // up to three near misses, e.g. "Unknown concept 'piuss' – did you
+        // mean pius?"; process() reports the same as a diagnostic.
+        void set_suggest_concepts(bool suggest) const;
+        bool suggest_concepts() const;
+
+        // No-match reports (see .no-matches), off by default. When on,
+        // process() answers a query without answers with the notice of
+        // query_report instead of printing nothing.
+        void set_report_no_matches(bool report) const;
+        bool report_no_matches() const;
+
+        // Query cache (see .query-cache and network::Reasoning::cached_query),
+        // off by default. When on, query() answers a repeated statement from
+        // the cache until facts of its relations change; the queries with
+        // probabilities or premises are always evaluated. Turning it off
+        // drops the cached answers.
+        void set_query_cache(bool enabled) const;
+        bool query_cache() const;
+
+        // The trace (see .trace). At Deductions, the default, run() and the
+        // runs after process() print each deduced fact together with the
+        // condition it was derived from; Off silences that, contradictions
+        // are always printed. Parsing also reports how process() understood
+        // each statement: as fact, rule or query, with its subjects,
+        // relations and objects and the names that became concepts (see
+        // network::Reasoning::describe_statement), as a diagnostic.
+        enum class TraceLevel
+        {
+            Off,
+            Deductions,
+            Parsing
+        };
+        void       set_trace(TraceLevel level) const;
+        TraceLevel trace() const;
+
+        // Native arithmetic (see .arithmetic), off by default. When on,
+        // rule and query conditions like X > 1000000 or (A + B) = C compute
+        // on nodes named by numbers instead of matching facts.
+        void set_native_arithmetic(bool enabled) const;
+        bool native_arithmetic() const;
+
+        // String builtins (see .strings), off by default. When on,
+        // conditions like X starts_with al or (F ++ L) = N test and compose
+        // the names of nodes, e.g. to derive labels within rules.
+        void set_string_builtins(bool enabled) const;
+        bool string_builtins() const;
+
+        // List builtins (see .lists), off by default. When on, conditions
+        // like X element_of L, L head H, L tail T and L length N work on
+        // lists stated as fact objects, e.g. route has_waypoints <a b c>.
+        void set_list_builtins(bool enabled) const;
+        bool list_builtins() const;
+
+        // Embedding vectors of concepts, e.g. from a language model, for
+        // combining reasoning with semantic similarity (see .embedding and
+        // network::Zelph::set_embedding). All vectors have one dimension.
+        // similar_concepts returns the k concepts closest to a concept (left
+        // out itself) or to a vector by cosine similarity, best first.
+        // Invalid arguments throw a process_error of kind Command. Session
+        // state, not saved with the network.
+        struct SimilarConcept
+        {
+            uint64_t    id{0};
+            std::string name;
+            double      similarity{0}; // cosine, in [-1,1]
+        };
+        void                        set_embedding(uint64_t node, std::vector<float> values) const;
+        bool                        remove_embedding(uint64_t node) const;
+        std::vector<float>          embedding(uint64_t node) const;
+        std::vector<SimilarConcept> similar_concepts(uint64_t node, size_t k = 10) const;
+        std::vector<SimilarConcept> similar_concepts(const std::vector<float>& values, size_t k = 10) const;
+
+        // Concept IDs for callers that state many facts: intern returns the
+        // node a script would use for name (current language, core node
+        // names included) and creates it if there is none, so that hot
+        // loops look names up once. add_fact states subject predicate
+        // object by node IDs and returns the fact; add_facts does so for
+        // each triple and returns the facts in order. Like bulk_load, no
+        // rules are run; new facts are reported as fact_added events.
+        // Unknown nodes and variables are thrown as console::process_error;
+        // facts of add_facts stated until then are kept.
+        struct FactIds
+        {
+            uint64_t subject{0};
+            uint64_t predicate{0};
+            uint64_t object{0};
+        };
+        uint64_t              intern(const std::string& name) const;
+        uint64_t              add_fact(uint64_t subject, uint64_t predicate, uint64_t object) const;
+        std::vector<uint64_t> add_facts(const std::vector<FactIds>& facts) const;
+
+        // Matching of names (see .normalize, .case-fold and .quotes). Names
+        // are NFC-normalized by default, so composed and decomposed
+        // spellings of a character denote the same node; case folding makes
+        // names case-insensitive. Both apply to names stored afterwards.
+        // set_quote_pairs adds quote characters such as „...“ to the parser,
+        // besides "..."; invalid delimiters are thrown as
+        // console::process_error.
+        enum class Normalization
+        {
+            None,
+            NFC,
+            NFKC
+        };
+        void set_name_normalization(Normalization form) const;
+        void set_case_folding(bool enabled) const;
+        void set_quote_pairs(const std::vector<std::pair<std::string, std::string>>& pairs) const;
+
+        // The confidence of a fact (a Fact::id) in [0,1], 1 unless set (see
+        // network::Reasoning::confidence). Same as .confidence. How a rule
+        // combines the confidences of the facts its conditions matched is
+        // set per rule, or for all rules without a setting of their own by
+        // passing rule 0 (see .confidence-combination). A combiner maps the
+        // premise confidences to the deduction's; it may run on several
+        // reasoning threads at once and must not call back into this
+        // instance. Errors are thrown as console::process_error.
+        enum class Combination
+        {
+            None,
+            Min,
+            Product
+        };
+        using ConfidenceCombiner = std::function<double(const std::vector<double>& premises)>;
+        double confidence(uint64_t fact) const;
+        void   set_confidence(uint64_t fact, double confidence) const;
+        void   set_confidence_combination(uint64_t rule, Combination combination) const;
+        void   set_confidence_combiner(uint64_t rule, ConfidenceCombiner combiner) const;
+
+        // Probabilistic inference (see .probabilistic): runs compute the
+        // marginal probability of each deduced fact as the noisy-OR of its
+        // derivations, each weighted by its rule's weight in [0,1] (default
+        // 1, see .rule-weight). Errors are thrown as console::process_error.
+        void set_probabilistic(bool on) const;
+        void set_rule_weight(uint64_t rule, double weight) const;
+
+        // The validity interval of a fact (see .validity). Time points are
+        // integers in a unit of the caller's choice; both ends are
+        // inclusive and unset for an open end. Errors are thrown as
+        // console::process_error.
+        struct Validity
+        {
+            std::optional<int64_t> from;
+            std::optional<int64_t> to;
+        };
+        Validity validity(uint64_t fact) const;
+        void     set_validity(uint64_t fact, const Validity& validity) const;
+
+        // Named contexts (see .context): a fact holds in the contexts it was
+        // added to, or in every context if none. An empty list of contexts
+        // removes the limit of a rule or of the session's scope. Errors are
+        // thrown as console::process_error.
+        std::vector<std::string> contexts(uint64_t fact) const;
+        void                     add_to_context(uint64_t fact, const std::string& context) const;
+        void                     remove_from_context(uint64_t fact, const std::string& context) const;
+        void                     set_rule_contexts(uint64_t rule, const std::vector<std::string>& contexts) const;
+        void                     set_context_scope(const std::vector<std::string>& contexts) const;
+
+        // Qualifiers of a fact (see .qualify): facts whose subject is the
+        // fact, like (berlin is_capital_of germany) since 1990, which a
+        // script states just like that and queries and rules match like any
+        // other fact, e.g. (X is_capital_of germany) since T. qualify states
+        // one, the predicate and value being names in the current language,
+        // and returns its id; qualifiers lists them, ordered by id. Errors
+        // are thrown as console::process_error.
+        struct Qualifier
+        {
+            uint64_t    id;
+            std::string predicate;
+            std::string value;
+        };
+        uint64_t               qualify(uint64_t fact, const std::string& predicate, const std::string& value) const;
+        std::vector<Qualifier> qualifiers(uint64_t fact) const;
+
+        // Where a fact came from (see .provenance): source "script" with the
+        // file and line, a loader like "rdf" with the file, "input" for a
+        // line passed to process, "api" for add_facts and qualify, or "rule"
+        // with the rule that deduced it. The source is empty if unknown.
+        // set_source_scope limits queries and reasoning to facts from the
+        // sources (see .source-scope); an empty list removes the limit.
+        // Errors are thrown as console::process_error.
+        struct Provenance
+        {
+            std::string source;
+            std::string file;
+            uint64_t    line{0};
+            uint64_t    rule{0};
+        };
+        Provenance provenance(uint64_t fact) const;
+        void       set_source_scope(const std::vector<std::string>& sources) const;
+
+        // Trust (see .trust): a weight between 0 and 1 per source, 1 unless
+        // set. A stated fact has the trust of its source, a deduced fact that
+        // of its least trusted premise. Query answers and deductions need the
+        // minimum trust, and a rule's deductions also the rule's own. Errors
+        // are thrown as console::process_error.
+        void   set_source_trust(const std::string& source, double trust) const;
+        double trust(uint64_t fact) const;
+        void   set_min_trust(double threshold) const;
+        void   set_rule_min_trust(uint64_t rule, double threshold) const;
+
+        // Streams a large fact file ("subject predicate object" per line, see
+        // io::BulkLoader) into the network, bypassing the script parser. Rules
+        // are not run. Errors are thrown as console::process_error naming the
+        // offending line; facts read until then are kept.
+        io::BulkProgress bulk_load(std::istream& in, const io::BulkOptions& options = {}) const;
+
+        // Loader for callers that produce the input in chunks (e.g. the C
+        // interface, fed from a Go io.Reader). It must not outlive this
+        // instance; errors are thrown as std::runtime_error.
+        std::unique_ptr<io::BulkLoader> bulk_loader(const io::BulkOptions& options = {}) const;
+
+        // Imports the rows of a CSV file as facts, one per row, according to
+        // a column mapping (see io::import_csv and io::parse_csv_mapping).
+        // Same as .import-csv; rules are not run. Rows that yield no fact are
+        // returned in the result's errors, a mapping that does not fit the
+        // file is thrown as console::process_error. Facts read until then
+        // are kept.
+        io::CsvResult import_csv(std::istream& in, const io::CsvMapping& mapping) const;
+
+        // Seed the network from lexical resources, same as .import-conceptnet
+        // and .import-wordnet (see io::import_conceptnet and
+        // io::import_wordnet): ConceptNet assertions filtered by language,
+        // relation and weight, WordNet synsets with their words and the
+        // pointers between them. Rules are not run. A malformed line is
+        // thrown as console::process_error; facts read until then are kept.
+        io::BulkProgress import_conceptnet(std::istream& in, const io::ConceptNetOptions& options = {}) const;
+        io::BulkProgress import_wordnet(std::istream& in, const io::WordNetOptions& options = {}) const;
+
+        // Keeps the facts made from a query result in step with it, e.g. the
+        // rows of an SQL query the embedder runs (see .syncs). sync_rows
+        // expands the rows with the template (see io::RowTemplate) and states
+        // the facts with source "sync" and the name as file. A sync owns the
+        // facts it created; those of its previous call that are missing from
+        // the new rows are retracted, with the deductions that lose their
+        // support. Facts that existed before are left alone. Rules are not
+        // run. Errors are thrown as console::process_error; a template that
+        // does not fit the rows changes nothing.
+        struct SyncResult
+        {
+            size_t rows{0};
+            size_t facts{0}; // owned by the sync afterwards
+            size_t added{0};
+            size_t retracted{0};
+        };
+        SyncResult sync_rows(const std::string& name, const io::RowTemplate& row_template, const io::RowSet& rows) const;
+
+        // Re-syncs on a schedule: fetch is called on a thread of its own at
+        // once and then every interval, outside the lock of this instance,
+        // and its rows are synchronized like sync_rows. A failure is reported
+        // as a sync_error event (see on_event) and in syncs(), and retried at
+        // the next interval. Scheduling a name again replaces its schedule.
+        // unschedule_sync stops it and keeps the facts, drop_sync also
+        // retracts them and forgets the sync; it returns their number.
+        // Schedules end with this instance.
+        using RowFetcher = std::function<io::RowSet()>;
+        void   schedule_sync(const std::string& name, const io::RowTemplate& row_template, RowFetcher fetch, std::chrono::milliseconds interval) const;
+        void   unschedule_sync(const std::string& name) const;
+        size_t drop_sync(const std::string& name) const;
+
+        struct SyncStatus
+        {
+            std::string               name;
+            SyncResult                last;      // of the last successful sync
+            int64_t                   synced_at; // its Unix time in seconds
+            std::string               error;     // of the last scheduled attempt, empty if it succeeded
+            std::chrono::milliseconds interval;  // 0 without a schedule
+        };
+        std::vector<SyncStatus> syncs() const;
+
+        // Disk-backed fact stores for networks larger than RAM (see .store
+        // and io::FactStore). create_store writes the named facts of the
+        // network to a store file and returns their number. open_store
+        // opens a store, replacing the open one (an empty file closes it);
+        // store_match lists up to limit (0: all) of its facts matching a
+        // pattern, an empty part matching anything, and store_import states
+        // them in the network and returns their number. Errors are thrown
+        // as console::process_error.
+        uint64_t                    create_store(const std::string& file) const;
+        void                        open_store(const std::string& file, size_t cache_bytes = 64 << 20) const;
+        std::vector<io::StoredFact> store_match(const std::string& subject, const std::string& predicate, const std::string& object, size_t limit = 0) const;
+        uint64_t                    store_import(const std::string& subject, const std::string& predicate, const std::string& object) const;
+
+        // Write the facts within depth steps of the node named root (current
+        // language) as a GraphViz digraph or a Mermaid flowchart, deduced
+        // facts dashed (see io::export_dot). Same output as .export-graph.
+        // An unknown root is thrown as console::process_error.
+        void export_dot(std::ostream& out, const std::string& root, int depth = 2) const;
+        void export_mermaid(std::ostream& out, const std::string& root, int depth = 2) const;
+
+        // Writes the facts and rules as Datalog clauses for Soufflé or
+        // SWI-Prolog (see io::export_datalog). Same as .export-datalog.
+        io::DatalogCounts export_datalog(std::ostream& out, const io::DatalogOptions& options = {}) const;
+
+#ifndef __EMSCRIPTEN__
+        // Persist the network to / restore it from a .bin file. Identical to
+        // the .save and .load commands (all checks and side effects, e.g.
+        // .load disabling auto-run), but file names need no quoting.
+        // Errors are thrown as console::process_error.
+        void save(const std::string& file) const;
+        void load(const std::string& file) const;
+
+        // Writes all facts as N-Triples, or as N-Quads with deduced facts in
+        // a named graph (see io::export_rdf). Same mapping as .export-rdf.
+        void export_rdf(std::ostream& out, bool quads = false, const std::string& base_iri = "urn:zelph:") const;
+
+        // Writes all facts as JSON-LD compacted with the given context (JSON
+        // text, see io::export_jsonld). Same mapping as .export-jsonld.
+        void export_jsonld(std::ostream& out, const std::string& context = "") const;
+
+        // Writes all facts as a Cypher script for Neo4j, and imports a graph
+        // exported from Neo4j as JSON Lines (see io::export_cypher and
+        // io::import_neo4j). Same as .export-cypher and .import-neo4j; import
+        // errors are thrown as console::process_error, facts read until then
+        // are kept. Returns the number of facts created.
+        void   export_cypher(std::ostream& out) const;
+        size_t import_neo4j(std::istream& in, const std::string& name_property = "name") const;
+#endif
+
         void set_output_handler(io::OutputHandler output) const;
         void out(const std::string& text, bool newline = true) const;
         void err(const std::string& text, bool newline = true) const;
@@ -67,7 +1244,106 @@ namespace zelph::console
         Interactive& operator=(const Interactive&) = delete;
 
     private:
+        friend class Session;
+        friend class Debugger;
+
+        std::vector<QueryBinding> run_query(const std::string& statement, std::vector<double>* probabilities, std::vector<std::vector<uint64_t>>* premises = nullptr, uint64_t* pattern = nullptr) const;
+
         class Impl;
         Impl* const _pImpl;
     };
+
+    // One user or connection of an Interactive shared by several, e.g. by
+    // a knowledge server. Each session keeps its own settings: the language,
+    // active context and stratum, the context and source scopes, the minimum
+    // confidence and trust and the as-of time. Its dot-commands (.lang,
+    // .context, .min-trust, ...) change them for this session only. Facts a
+    // session states come from source "session:<name>" (see .provenance),
+    // so .trust can weigh them per user. undo removes the facts and rules
+    // added by the session's last line that added any, together with the
+    // deductions that lose their support, and returns their number; lines
+    // are undone in reverse order. Network-wide settings (e.g. .threads or
+    // .auto-run) are shared. Sessions on different threads run one at a
+    // time like the writers of the Interactive (see there); a session itself
+    // must be used by one thread at a time and must not outlive its
+    // Interactive. Errors are thrown as console::process_error.
+    class ZELPH_EXPORT Session
+    {
+    public:
+        Session(const Interactive& interactive, std::string name);
+        ~Session();
+
+        const std::string&                     name() const { return _name; }
+        void                                   process(const std::string& line);
+        std::vector<Interactive::QueryBinding> query(const std::string& statement);
+        size_t                                 undo();
+        size_t                                 undo_depth() const { return _undo.size(); }
+
+        Session(const Session&)            = delete;
+        Session& operator=(const Session&) = delete;
+
+    private:
+        class Scope;
+        struct Settings;
+
+        void keep_statements(Interactive::Impl& impl, const std::vector<uint64_t>& facts);
+
+        const Interactive&                 _interactive;
+        std::string                        _name;
+        std::unique_ptr<Settings>          _settings;
+        uint64_t                           _lines{0};
+        std::vector<std::vector<uint64_t>> _undo; // facts added per line
+    };
+
+    // Steps through inference one rule firing at a time (see .debug), e.g.
+    // to see what a new rule does. step lets the rules fire once, resume
+    // runs until a firing hits a breakpoint: a rule, or a pattern the
+    // deduced fact matches (compared like the patterns of
+    // Interactive::watch). Both return that firing, or nothing if the run
+    // ended without one, at the fixpoint or at a bound like
+    // Interactive::set_max_deductions. Deductions are kept and reported to
+    // the callbacks as in Interactive::run. agenda lists the matches waiting
+    // to fire, each rule with the bindings of its variables (see
+    // network::Reasoning::agenda), at most limit of them, 0 for all.
+    // Breakpoints belong to the debugger and are ignored by runs outside
+    // it; the debugger must not outlive its Interactive. Errors are thrown
+    // as console::process_error.
+    class ZELPH_EXPORT Debugger
+    {
+    public:
+        explicit Debugger(const Interactive& interactive);
+        ~Debugger();
+
+        struct Breakpoint
+        {
+            uint64_t                           id;
+            uint64_t                           rule{0}; // 0 for a pattern
+            std::optional<Interactive::Triple> pattern;
+        };
+        uint64_t                       break_on_rule(uint64_t rule);
+        uint64_t                       break_on_fact(const Interactive::Triple& pattern);
+        void                           remove_breakpoint(uint64_t breakpoint);
+        const std::vector<Breakpoint>& breakpoints() const { return _breakpoints; }
+
+        std::optional<Interactive::Deduction> step();
+        std::optional<Interactive::Deduction> resume();
+
+        struct Candidate
+        {
+            uint64_t                                         rule;
+            std::string                                      rule_text;
+            std::vector<std::pair<std::string, std::string>> bindings; // by variable name
+        };
+        std::vector<Candidate> agenda(size_t limit = 0) const;
+
+        Debugger(const Debugger&)            = delete;
+        Debugger& operator=(const Debugger&) = delete;
+
+    private:
+        std::optional<Interactive::Deduction> run_until(const std::function<bool(uint64_t fact, uint64_t rule)>& stop);
+
+        const Interactive&      _interactive;
+        std::vector<Breakpoint> _breakpoints;
+        uint64_t                _next_breakpoint{1};
+    };
 }
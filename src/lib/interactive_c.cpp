@@ -0,0 +1,543 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+#include "network/reasoning_cancelled.hpp"
+#include "network/reasoning_limit_exceeded.hpp"
+
+#include <exception>
+#include <sstream>
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Backs the handle-less legacy functions zelph_process_c and zelph_run.
+zelph_instance default_instance;
+
+extern "C" zelph_instance* zelph_default()
+{
+    return &default_instance;
+}
+
+extern "C" zelph_instance* zelph_new()
+{
+    return new zelph_instance;
+}
+
+extern "C" void zelph_delete(zelph_instance* z)
+{
+    if (z != &default_instance) delete z;
+}
+
+// Returns 0 on success, otherwise 1 + console::ProcessErrorKind
+// (1 syntax, 2 command, 3 script, 4 statement, 5 reasoning, 6 cancelled,
+// 7 resource limit, 8 assertion).
+extern "C" int zelph_process_h(zelph_instance* z, const char* line, size_t len)
+{
+    z->clear_error();
+
+    if (len > 0)
+    {
+        std::string l(line, 0, len);
+        try
+        {
+            z->interactive.process(l);
+        }
+        catch (const console::process_error& ex)
+        {
+            return z->record_error(ex);
+        }
+        catch (const std::exception& ex)
+        {
+            return z->record_error(console::ProcessErrorKind::Statement, ex.what(), l);
+        }
+    }
+    return 0;
+}
+
+// Processes a whole script (see console::Interactive::process_script) and
+// returns the number of failed lines, 0 on success. They are read with the
+// zelph_script_error_* accessors until the next call; the first one is also
+// available via zelph_last_error.
+extern "C" int zelph_process_script_h(zelph_instance* z, const char* text, size_t len)
+{
+    z->clear_error();
+    z->last_script_errors.clear();
+
+    std::istringstream in(std::string(text, len));
+    try
+    {
+        z->interactive.process_script(in);
+    }
+    catch (const console::script_error& ex)
+    {
+        z->last_script_errors = ex.errors();
+        const auto& first     = z->last_script_errors.front();
+        z->record_error(first.kind, first.reason, first.line, first.column);
+    }
+    return static_cast<int>(z->last_script_errors.size());
+}
+
+// Loads the built-in base ontology (see console::Interactive::load_stdlib).
+// Returns 0 or the error code of zelph_process_h.
+extern "C" int zelph_load_stdlib_h(zelph_instance* z)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.load_stdlib();
+    }
+    catch (const console::script_error& ex)
+    {
+        const auto& first = ex.errors().front();
+        return z->record_error(first.kind, first.reason, first.line, first.column);
+    }
+    return 0;
+}
+
+// Processes a Datalog program (see console::Interactive::process_datalog);
+// failed clauses are reported like the lines of zelph_process_script_h.
+extern "C" int zelph_process_datalog_h(zelph_instance* z, const char* text, size_t len)
+{
+    z->clear_error();
+    z->last_script_errors.clear();
+
+    std::istringstream in(std::string(text, len));
+    try
+    {
+        z->interactive.process_datalog(in);
+    }
+    catch (const console::script_error& ex)
+    {
+        z->last_script_errors = ex.errors();
+        const auto& first     = z->last_script_errors.front();
+        z->record_error(first.kind, first.reason, first.line, first.column);
+    }
+    return static_cast<int>(z->last_script_errors.size());
+}
+
+// Replays and opens a journal (see console::Interactive::set_journal), or
+// closes it for an empty file. Returns the number of failed replayed lines
+// like zelph_process_script_h, or -code if the file cannot be opened.
+extern "C" int zelph_set_journal_h(zelph_instance* z, const char* file)
+{
+    z->clear_error();
+    z->last_script_errors.clear();
+    try
+    {
+        z->interactive.set_journal(file);
+    }
+    catch (const console::script_error& ex)
+    {
+        z->last_script_errors = ex.errors();
+        const auto& first     = z->last_script_errors.front();
+        z->record_error(first.kind, first.reason, first.line, first.column);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_script_errors.size());
+}
+
+static const console::ScriptLineError* script_error_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_script_errors.size()) return nullptr;
+    return &z->last_script_errors[i];
+}
+
+// 1-based line number of the failed line, 0 for the run after the last line.
+extern "C" uint64_t zelph_script_error_line_number(const zelph_instance* z, int i)
+{
+    const auto* e = script_error_at(z, i);
+    return e ? e->number : 0;
+}
+
+extern "C" const char* zelph_script_error_line(const zelph_instance* z, int i)
+{
+    const auto* e = script_error_at(z, i);
+    return e ? e->line.c_str() : "";
+}
+
+// The error code of the line, as returned by zelph_process_h.
+extern "C" int zelph_script_error_code(const zelph_instance* z, int i)
+{
+    const auto* e = script_error_at(z, i);
+    return e ? 1 + static_cast<int>(e->kind) : 0;
+}
+
+extern "C" const char* zelph_script_error_text(const zelph_instance* z, int i)
+{
+    const auto* e = script_error_at(z, i);
+    return e ? e->reason.c_str() : "";
+}
+
+// 1-based column of a syntax error in the line, 0 if unknown.
+extern "C" uint64_t zelph_script_error_column(const zelph_instance* z, int i)
+{
+    const auto* e = script_error_at(z, i);
+    return e ? e->column : 0;
+}
+
+extern "C" int zelph_run_h(zelph_instance* z)
+{
+    z->clear_error();
+
+    try
+    {
+        z->interactive.run(true, false, false);
+    }
+    catch (const network::reasoning_cancelled& ex)
+    {
+        return z->record_error(console::ProcessErrorKind::Cancelled, ex.what(), "");
+    }
+    catch (const network::reasoning_limit_exceeded& ex)
+    {
+        return z->record_error(console::ProcessErrorKind::ResourceLimit, ex.what(), "");
+    }
+    catch (const std::exception& ex)
+    {
+        return z->record_error(console::ProcessErrorKind::Reasoning, ex.what(), "");
+    }
+    return 0;
+}
+
+// Thread-safe: stops a zelph_run_h/zelph_process_h call in progress on
+// another thread, which then returns the "cancelled" code. Lets a Go
+// caller honour context cancellation and deadlines. Ignored when no call
+// is reasoning.
+extern "C" void zelph_cancel(zelph_instance* z)
+{
+    z->interactive.cancel();
+}
+
+// Sets the number of reasoning worker threads (see .threads), e.g. to
+// runtime.GOMAXPROCS(0) of a Go caller; 0 selects one per hardware thread.
+// zelph_thread_count_h returns the number in use.
+extern "C" void zelph_set_thread_count_h(zelph_instance* z, size_t count)
+{
+    z->interactive.set_thread_count(count);
+}
+
+extern "C" size_t zelph_thread_count_h(zelph_instance* z)
+{
+    return z->interactive.thread_count();
+}
+
+// Deterministic mode (see .deterministic) for reproducible deductions and
+// answers, e.g. in golden tests of a Go caller.
+extern "C" void zelph_set_deterministic_h(zelph_instance* z, int on)
+{
+    z->interactive.set_deterministic(on != 0);
+}
+
+extern "C" int zelph_deterministic_h(zelph_instance* z)
+{
+    return z->interactive.deterministic() ? 1 : 0;
+}
+
+// Fact lookup orientations (see .indexes) as a bit mask: 1 = SPO, 2 = POS,
+// 4 = OSP, e.g. for a Go WithIndexes(SPO, POS, OSP) option. POS is always
+// included; zelph_triple_indexes_h returns the mask in effect.
+extern "C" void zelph_set_triple_indexes_h(zelph_instance* z, uint8_t indexes)
+{
+    z->interactive.set_triple_indexes(indexes);
+}
+
+extern "C" uint8_t zelph_triple_indexes_h(zelph_instance* z)
+{
+    return z->interactive.triple_indexes();
+}
+
+// Resource limits of reasoning (see .max-facts and .max-memory), 0 for none.
+// A run exceeding one fails with error code 7 (resource limit), so a Go
+// caller can map it to its own error value.
+extern "C" void zelph_set_max_facts_h(zelph_instance* z, uint64_t count)
+{
+    z->interactive.set_max_facts(count);
+}
+
+extern "C" void zelph_set_max_memory_h(zelph_instance* z, size_t bytes)
+{
+    z->interactive.set_max_memory(bytes);
+}
+
+// See .max-term-depth, 0 for none.
+extern "C" void zelph_set_max_term_depth_h(zelph_instance* z, uint32_t depth)
+{
+    z->interactive.set_max_term_depth(depth);
+}
+
+// Bounds of reasoning (see .max-iterations and .max-deductions), 0 for none.
+// A run reaching one pauses without an error; zelph_fixpoint_reached_h
+// returns 0 until repeated zelph_run calls have completed it.
+extern "C" void zelph_set_max_iterations_h(zelph_instance* z, int count)
+{
+    z->interactive.set_max_iterations(count);
+}
+
+extern "C" void zelph_set_max_deductions_h(zelph_instance* z, uint64_t count)
+{
+    z->interactive.set_max_deductions(count);
+}
+
+extern "C" int zelph_fixpoint_reached_h(zelph_instance* z)
+{
+    return z->interactive.fixpoint_reached() ? 1 : 0;
+}
+
+// Redirects the output of the instance: zelph_set_output_h receives
+// answers, deductions and prompts, zelph_set_error_output_h errors and
+// diagnostics (reasoning statistics, warnings). Janet's print and eprint
+// in scripts go the same ways. Each call gets text without its trailing
+// newline; newline tells whether one follows. nullptr restores stdout
+// and stderr. A callback may run on a reasoning thread and must not call
+// back into the instance.
+using zelph_output_fn = void (*)(const char* text, size_t len, int newline, void* user);
+
+extern "C" void zelph_set_output_h(zelph_instance* z, zelph_output_fn callback, void* user)
+{
+    z->out_fn   = callback;
+    z->out_user = user;
+    z->install_output();
+}
+
+extern "C" void zelph_set_error_output_h(zelph_instance* z, zelph_output_fn callback, void* user)
+{
+    z->err_fn   = callback;
+    z->err_user = user;
+    z->install_output();
+}
+
+// Registers a callback for every fact deduced by a rule (see
+// console::Interactive::on_deduction); pass nullptr to remove it. The
+// strings are only valid during the call. It runs on a reasoning thread
+// (not the one that called zelph_run_h), one call at a time.
+using zelph_deduction_fn = void (*)(uint64_t fact, uint64_t rule, const char* fact_text, const char* rule_text, void* user);
+
+extern "C" void zelph_on_deduction_h(zelph_instance* z, zelph_deduction_fn callback, void* user)
+{
+    if (!callback)
+    {
+        z->interactive.on_deduction(nullptr);
+        return;
+    }
+
+    z->interactive.on_deduction([callback, user](const console::Interactive::Deduction& d)
+                                { callback(d.fact, d.rule, d.fact_text.c_str(), d.rule_text.c_str(), user); });
+}
+
+// Registers a callback reporting the progress of reasoning runs (see
+// console::Interactive::on_progress) about every interval_ms milliseconds
+// and once more, with finished set to 1, when a run completes or pauses;
+// pass nullptr to remove it. It runs on a helper thread of the run.
+using zelph_progress_fn = void (*)(int iterations, uint64_t matches, uint64_t deductions, double seconds, int finished, void* user);
+
+extern "C" void zelph_on_progress_h(zelph_instance* z, uint64_t interval_ms, zelph_progress_fn callback, void* user)
+{
+    if (!callback)
+    {
+        z->interactive.on_progress(nullptr);
+        return;
+    }
+
+    z->interactive.on_progress([callback, user](const network::RunProgress& p)
+                               { callback(p.iterations, p.matches, p.deductions, p.seconds, p.finished ? 1 : 0, user); },
+                               static_cast<double>(interval_ms) / 1000);
+}
+
+// Registers a callback for structured log events (see
+// console::Interactive::on_event): the event type and count attributes as
+// parallel arrays of keys and values, valid during the call. Pass nullptr
+// to remove it.
+using zelph_event_fn = void (*)(const char* type, int count, const char* const* keys, const char* const* values, void* user);
+
+extern "C" void zelph_on_event_h(zelph_instance* z, zelph_event_fn callback, void* user)
+{
+    if (!callback)
+    {
+        z->interactive.on_event(nullptr);
+        return;
+    }
+
+    z->interactive.on_event([callback, user](const console::Interactive::Event& e)
+                            {
+        std::vector<const char*> keys;
+        std::vector<const char*> values;
+        for (const auto& [key, value] : e.attributes)
+        {
+            keys.push_back(key.c_str());
+            values.push_back(value.c_str());
+        }
+        callback(e.type.c_str(), static_cast<int>(keys.size()), keys.data(), values.data(), user); });
+}
+
+// Watches the changes of the network (see console::Interactive::watch):
+// kind is "added", "retracted" or "deduced", the fact is given by its ID
+// and its rendered parts, valid during the call. Non-empty subject,
+// predicate and object names restrict the watch to facts with these
+// parts; NULL or "" matches any. Returns the ID for zelph_unwatch_h.
+using zelph_change_fn = void (*)(const char* kind, uint64_t fact, const char* subject, const char* predicate, int object_count, const char* const* objects, void* user);
+
+extern "C" uint64_t zelph_watch_h(zelph_instance* z, const char* subject, const char* predicate, const char* object, zelph_change_fn callback, void* user)
+{
+    auto term = [](const char* name)
+    {
+        return name && *name ? console::Interactive::Term::constant(name) : console::Interactive::Term::var();
+    };
+
+    std::optional<console::Interactive::Triple> pattern;
+    if ((subject && *subject) || (predicate && *predicate) || (object && *object))
+        pattern = console::Interactive::Triple{term(subject), term(predicate), term(object)};
+
+    return z->interactive.watch([callback, user](const console::Interactive::Change& c)
+                                {
+        std::vector<const char*> objects;
+        for (const auto& o : c.fact.objects)
+            objects.push_back(o.c_str());
+        callback(c.kind.c_str(), c.fact.id, c.fact.subject.c_str(), c.fact.predicate.c_str(), static_cast<int>(objects.size()), objects.data(), user); },
+                                pattern);
+}
+
+extern "C" void zelph_unwatch_h(zelph_instance* z, uint64_t watch)
+{
+    z->interactive.unwatch(watch);
+}
+
+extern "C" int zelph_process_c(const char* line, size_t len)
+{
+    return zelph_process_h(&default_instance, line, len);
+}
+
+extern "C" int zelph_run()
+{
+    return zelph_run_h(&default_instance);
+}
+
+// Concept suggestions (see .suggest), suggest 0 or 1. While on, a query
+// without answers that mentions an unknown concept fails with error code
+// 4 (statement) and the suggestions as reason (see zelph_last_error).
+extern "C" void zelph_set_suggest_concepts_h(zelph_instance* z, int suggest)
+{
+    z->interactive.set_suggest_concepts(suggest != 0);
+}
+
+extern "C" int zelph_suggest_concepts_h(zelph_instance* z)
+{
+    return z->interactive.suggest_concepts() ? 1 : 0;
+}
+
+// No-match reports (see .no-matches), report 0 or 1, off by default.
+extern "C" void zelph_set_report_no_matches_h(zelph_instance* z, int report)
+{
+    z->interactive.set_report_no_matches(report != 0);
+}
+
+extern "C" int zelph_report_no_matches_h(zelph_instance* z)
+{
+    return z->interactive.report_no_matches() ? 1 : 0;
+}
+
+// The query cache (see console::Interactive::set_query_cache) for servers
+// that repeat the same queries against a mostly static network.
+extern "C" void zelph_set_query_cache_h(zelph_instance* z, int enabled)
+{
+    z->interactive.set_query_cache(enabled != 0);
+}
+
+extern "C" int zelph_query_hache_h(zelph_instance* z)
+{
+    return z->interactive.query_cache() ? 1 : 0;
+}
+
+// Trace (see .trace): level 0 is off, 1 traces deductions (the default),
+// 2 also the parsing of each statement (see console::Interactive::TraceLevel).
+extern "C" void zelph_set_trace_h(zelph_instance* z, int level)
+{
+    z->interactive.set_trace(level <= 0 ? console::Interactive::TraceLevel::Off
+                             : level == 1 ? console::Interactive::TraceLevel::Deductions
+                                          : console::Interactive::TraceLevel::Parsing);
+}
+
+extern "C" int zelph_trace_h(zelph_instance* z)
+{
+    return static_cast<int>(z->interactive.trace());
+}
+
+// Native arithmetic (see .arithmetic), enabled 0 or 1.
+extern "C" void zelph_set_native_arithmetic_h(zelph_instance* z, int enabled)
+{
+    z->interactive.set_native_arithmetic(enabled != 0);
+}
+
+extern "C" int zelph_native_arithmetic_h(zelph_instance* z)
+{
+    return z->interactive.native_arithmetic() ? 1 : 0;
+}
+
+// String builtins (see .strings), enabled 0 or 1.
+extern "C" void zelph_set_string_builtins_h(zelph_instance* z, int enabled)
+{
+    z->interactive.set_string_builtins(enabled != 0);
+}
+
+extern "C" int zelph_string_builtins_h(zelph_instance* z)
+{
+    return z->interactive.string_builtins() ? 1 : 0;
+}
+
+// List builtins (see .lists), enabled 0 or 1.
+extern "C" void zelph_set_list_builtins_h(zelph_instance* z, int enabled)
+{
+    z->interactive.set_list_builtins(enabled != 0);
+}
+
+extern "C" int zelph_list_builtins_h(zelph_instance* z)
+{
+    return z->interactive.list_builtins() ? 1 : 0;
+}
+
+// Error text of the last failed call (without the "Error in line" prefix),
+// or an empty string. Valid until the next call on the same instance.
+extern "C" const char* zelph_last_error(const zelph_instance* z)
+{
+    return z->last_error.c_str();
+}
+
+// Input line of the last failed zelph_process_h call, or an empty string.
+extern "C" const char* zelph_last_error_line(const zelph_instance* z)
+{
+    return z->last_error_line.c_str();
+}
+
+// 1-based column of the last syntax error in zelph_last_error_line, 0 if
+// unknown (see console::process_error::column).
+extern "C" uint64_t zelph_last_error_column(const zelph_instance* z)
+{
+    return z->last_error_column;
+}
+#endif
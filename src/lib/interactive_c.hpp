@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "interactive.hpp"
+
+#include "process_error.hpp"
+
+#include <memory>
+#include <sstream>
+#include <utility>
+
+// One knowledge base as seen through the C interface. Every instance owns
+// its own network, Janet VM and error/answer buffers, so several of them
+// can coexist in one process (e.g. one per cgo caller or test case). The
+// buffers are not guarded by the instance's lock (see Interactive), so a
+// handle is used by one thread at a time; only zelph_cancel may be called
+// from another thread while a call is in progress.
+struct zelph_instance
+{
+    zelph::console::Interactive interactive;
+
+    // Details of the most recent failure. Exceptions must not cross the C
+    // boundary (cgo cannot unwind them), so they are caught here and
+    // queried via zelph_last_error*.
+    std::string last_error;
+    std::string last_error_line;
+    size_t      last_error_column{0};
+
+    // Answers of the most recent zelph_query_h or zelph_sparql_h call, and
+    // the projected variables of the latter.
+    std::vector<std::vector<std::pair<std::string, std::string>>> last_answers;
+    std::vector<std::string>                                      last_variables;
+
+    // Answer probabilities of the most recent zelph_query_probabilities_h
+    // call, parallel to last_answers.
+    std::vector<double> last_probabilities;
+
+    // Notice of the most recent zelph_query_h call if it had no answers
+    // (see console::Interactive::query_report).
+    std::string last_query_notice;
+
+    // Why the most recent zelph_query_bounded_h call stopped before it had
+    // all answers, or "" if it did not.
+    std::string last_truncation;
+
+    // Snapshot taken by the most recent zelph_facts_h call.
+    std::vector<zelph::console::Interactive::Fact> last_facts;
+
+    // Snapshot taken by the most recent zelph_rules_h call.
+    std::vector<zelph::console::Interactive::Rule> last_rules;
+
+    // The debugger of the zelph_debug_* calls, created by the first, and
+    // the firing and agenda its most recent calls returned.
+    std::unique_ptr<zelph::console::Debugger>             debugger;
+    std::optional<zelph::console::Interactive::Deduction> last_firing;
+    std::vector<zelph::console::Debugger::Candidate>      last_agenda;
+
+    // Contexts read by the most recent zelph_contexts_h call.
+    std::vector<std::string> last_contexts;
+
+    // Proof tree of the most recent zelph_explain_h call, in preorder.
+    struct ProofStep
+    {
+        int                                       depth;
+        const zelph::console::Interactive::Proof* proof;
+    };
+    zelph::console::Interactive::Proof last_proof;
+    std::vector<ProofStep>             last_proof_steps;
+
+    // Snapshot taken by the most recent zelph_conflicts_h call.
+    std::vector<zelph::console::Interactive::Conflict> last_conflicts;
+
+    // Snapshot taken by the most recent zelph_schema_violations_h call.
+    std::vector<zelph::console::Interactive::SchemaViolation> last_schema_violations;
+
+    // Snapshot taken by the most recent zelph_validate_h call.
+    std::vector<zelph::console::Interactive::Violation> last_violations;
+
+    // Report of the most recent zelph_validate_shapes_h call.
+    zelph::console::Interactive::ShapeReport last_shape_report{};
+
+    // Callbacks set by zelph_set_output_h (answers and prompts) and
+    // zelph_set_error_output_h (errors and diagnostics); channels without
+    // one go to the process streams, like a plain Interactive.
+    using output_fn = void (*)(const char* text, size_t len, int newline, void* user);
+    output_fn out_fn{nullptr};
+    void*     out_user{nullptr};
+    output_fn err_fn{nullptr};
+    void*     err_user{nullptr};
+
+    void install_output()
+    {
+        interactive.set_output_handler([out = out_fn, out_user = out_user, err = err_fn, err_user = err_user](const zelph::io::OutputEvent& e)
+                                       {
+            const bool to_out = e.channel == zelph::io::OutputChannel::Out || e.channel == zelph::io::OutputChannel::Prompt;
+            const auto fn     = to_out ? out : err;
+            if (!fn)
+            {
+                zelph::io::default_output_handler(e);
+                return;
+            }
+            fn(e.text.data(), e.text.size(), e.newline ? 1 : 0, to_out ? out_user : err_user); });
+    }
+
+    // Text returned by the most recent zelph_metrics_h call.
+    std::string last_metrics;
+
+    // Snapshot taken by the most recent zelph_rule_profile_h call, and the
+    // text of the most recent zelph_rule_profile_report_h call.
+    std::vector<zelph::console::Interactive::RuleProfile> last_rule_profile;
+    std::string                                    last_rule_profile_report;
+
+    // Text returned by the most recent zelph_export_datalog_h call.
+    std::string last_datalog;
+
+    // Snapshot taken by the most recent zelph_graph_stats_h call, with the
+    // degree distributions flattened.
+    zelph::console::Interactive::GraphStats    last_graph_stats;
+    std::vector<std::pair<uint64_t, uint64_t>> last_in_degrees;
+    std::vector<std::pair<uint64_t, uint64_t>> last_out_degrees;
+
+    // Paths found by the most recent zelph_find_paths_h call.
+    std::vector<zelph::console::Interactive::Path> last_paths;
+
+    // Failed lines of the most recent zelph_process_script_h call.
+    std::vector<zelph::console::ScriptLineError> last_script_errors;
+
+    // Bulk load in progress (zelph_bulk_begin_h .. zelph_bulk_end_h).
+    std::unique_ptr<zelph::io::BulkLoader> bulk;
+
+    // Rows without a fact of the most recent zelph_import_csv_h call.
+    std::vector<zelph::io::CsvError> last_csv_errors;
+
+    // Sync in progress (zelph_sync_begin_h .. zelph_sync_end_h).
+    std::string                           sync_name;
+    std::optional<zelph::io::RowTemplate> sync_template;
+    zelph::io::RowSet                     sync_rows;
+
+    // Facts found by the most recent zelph_store_match_h call.
+    std::vector<zelph::io::StoredFact> last_stored_facts;
+
+    // Plan of the most recent zelph_explain_query_h or zelph_explain_rule_h call.
+    std::vector<zelph::console::Interactive::PlanStep> last_plan;
+
+    // Matches of the most recent zelph_search_concepts_h call.
+    std::vector<zelph::console::Interactive::ConceptMatch> last_concepts;
+
+    // Concepts found by the most recent zelph_similar_concepts_h or
+    // zelph_similar_to_h call.
+    std::vector<zelph::console::Interactive::SimilarConcept> last_similar;
+
+    // Names found by the most recent zelph_instances_of_h call.
+    std::vector<std::string> last_instances;
+
+    // Result of the most recent zelph_ask_h call.
+    zelph::console::Interactive::QuestionAnswer last_question;
+
+    // Qualifiers read by the most recent zelph_qualifiers_h call.
+    std::vector<zelph::console::Interactive::Qualifier> last_qualifiers;
+    // Provenance read by the most recent zelph_provenance_h call.
+    zelph::console::Interactive::Provenance last_provenance;
+
+    // Rows of the most recent zelph_aggregate_h call.
+    std::vector<zelph::console::Interactive::AggregateRow> last_aggregate;
+
+    int record_error(const zelph::console::ProcessErrorKind kind, const std::string& message, const std::string& line, const size_t column = 0)
+    {
+        last_error        = message;
+        last_error_line   = line;
+        last_error_column = column;
+        return 1 + static_cast<int>(kind);
+    }
+
+    int record_error(const zelph::console::process_error& ex)
+    {
+        return record_error(ex.kind(), ex.reason(), ex.line(), ex.column());
+    }
+
+    void clear_error()
+    {
+        last_error.clear();
+        last_error_line.clear();
+        last_error_column = 0;
+    }
+};
+
+// Splits a newline-separated list of context names, skipping empty lines.
+inline std::vector<std::string> split_contexts(const char* text, size_t len)
+{
+    std::vector<std::string> contexts;
+    std::istringstream       in(std::string(text, 0, len));
+    for (std::string line; std::getline(in, line);)
+        if (!line.empty()) contexts.push_back(line);
+    return contexts;
+}
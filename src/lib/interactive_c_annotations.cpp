@@ -0,0 +1,384 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Reads a fact's confidence (see .confidence) into *confidence. Returns 0
+// or an error code as zelph_process_h.
+extern "C" int zelph_confidence_h(zelph_instance* z, uint64_t fact, double* confidence)
+{
+    z->clear_error();
+    try
+    {
+        *confidence = z->interactive.confidence(fact);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+extern "C" int zelph_set_confidence_h(zelph_instance* z, uint64_t fact, double confidence)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_confidence(fact, confidence);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Sets how a rule (0: all rules without a setting of their own) combines
+// premise confidences: 0 none, 1 min, 2 product (see
+// .confidence-combination).
+extern "C" int zelph_set_confidence_combination_h(zelph_instance* z, uint64_t rule, int combination)
+{
+    z->clear_error();
+    if (combination < 0 || combination > 2)
+        return z->record_error(console::ProcessErrorKind::Command, "Unknown confidence combination " + std::to_string(combination), "");
+
+    try
+    {
+        z->interactive.set_confidence_combination(rule, static_cast<console::Interactive::Combination>(combination));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Installs a custom combination for a rule (0: all rules without a setting
+// of their own). The combiner receives the premise confidences and returns
+// the deduction's; it may run on several reasoning threads at once and
+// must not call into the instance.
+using zelph_combiner_fn = double (*)(const double* premises, size_t count, void* user);
+
+extern "C" int zelph_set_confidence_combiner_h(zelph_instance* z, uint64_t rule, zelph_combiner_fn combiner, void* user)
+{
+    z->clear_error();
+    if (!combiner)
+        return z->record_error(console::ProcessErrorKind::Command, "A custom confidence combination needs a combiner", "");
+
+    try
+    {
+        z->interactive.set_confidence_combiner(rule, [combiner, user](const std::vector<double>& premises)
+                                               { return combiner(premises.data(), premises.size(), user); });
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Reads the contexts a fact holds in (none: every context). Returns their
+// number, or the negated error code of zelph_process_h; context i is read
+// with zelph_context_at until the next zelph_contexts_h call.
+extern "C" int zelph_contexts_h(zelph_instance* z, uint64_t fact)
+{
+    z->clear_error();
+    z->last_contexts.clear();
+    try
+    {
+        z->last_contexts = z->interactive.contexts(fact);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_contexts.size());
+}
+
+extern "C" const char* zelph_context_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_contexts.size()) return "";
+    return z->last_contexts[i].c_str();
+}
+
+// States the qualifier (fact predicate value), with predicate and value
+// named in the current language. Returns its id, or 0 on error (see
+// zelph_last_error).
+extern "C" uint64_t zelph_qualify_h(zelph_instance* z, uint64_t fact, const char* predicate, size_t predicate_len, const char* value, size_t value_len)
+{
+    z->clear_error();
+    try
+    {
+        return z->interactive.qualify(fact, std::string(predicate, 0, predicate_len), std::string(value, 0, value_len));
+    }
+    catch (const console::process_error& ex)
+    {
+        z->record_error(ex);
+        return 0;
+    }
+}
+
+// Reads the qualifiers of a fact. Returns their number, or the negated
+// error code of zelph_process_h; qualifier i is read with
+// zelph_qualifier_id/predicate/value until the next zelph_qualifiers_h call.
+extern "C" int zelph_qualifiers_h(zelph_instance* z, uint64_t fact)
+{
+    z->clear_error();
+    z->last_qualifiers.clear();
+    try
+    {
+        z->last_qualifiers = z->interactive.qualifiers(fact);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_qualifiers.size());
+}
+
+static const console::Interactive::Qualifier* qualifier_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_qualifiers.size()) return nullptr;
+    return &z->last_qualifiers[i];
+}
+
+extern "C" uint64_t zelph_qualifier_id(const zelph_instance* z, int i)
+{
+    const auto* q = qualifier_at(z, i);
+    return q ? q->id : 0;
+}
+
+extern "C" const char* zelph_qualifier_predicate(const zelph_instance* z, int i)
+{
+    const auto* q = qualifier_at(z, i);
+    return q ? q->predicate.c_str() : nullptr;
+}
+
+extern "C" const char* zelph_qualifier_value(const zelph_instance* z, int i)
+{
+    const auto* q = qualifier_at(z, i);
+    return q ? q->value.c_str() : nullptr;
+}
+
+// Reads where a fact came from (see .provenance): line and rule are set,
+// source and file are read with zelph_provenance_source/file until the next
+// zelph_provenance_h call. Returns 0, or the error code of zelph_process_h.
+extern "C" int zelph_provenance_h(zelph_instance* z, uint64_t fact, uint64_t* line, uint64_t* rule)
+{
+    z->clear_error();
+    z->last_provenance = {};
+    try
+    {
+        z->last_provenance = z->interactive.provenance(fact);
+        *line              = z->last_provenance.line;
+        *rule              = z->last_provenance.rule;
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+extern "C" const char* zelph_provenance_source(const zelph_instance* z)
+{
+    return z->last_provenance.source.c_str();
+}
+
+extern "C" const char* zelph_provenance_file(const zelph_instance* z)
+{
+    return z->last_provenance.file.c_str();
+}
+
+// Limits queries and reasoning to facts from the sources, given as
+// newline-separated names; none removes the limit (see .source-scope).
+extern "C" void zelph_set_source_scope_h(zelph_instance* z, const char* sources, size_t len)
+{
+    z->interactive.set_source_scope(split_contexts(sources, len));
+}
+
+// Sets the trust of a source (see .trust). Returns 0, or the error code of
+// zelph_process_h.
+extern "C" int zelph_set_source_trust_h(zelph_instance* z, const char* source, size_t len, double trust)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_source_trust(std::string(source, 0, len), trust);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Reads the trust of a fact. Returns 0, or the error code of
+// zelph_process_h.
+extern "C" int zelph_trust_h(zelph_instance* z, uint64_t fact, double* trust)
+{
+    z->clear_error();
+    try
+    {
+        *trust = z->interactive.trust(fact);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Sets the trust that query answers and deductions need (see .min-trust),
+// or that of a rule's deductions if rule is not 0 (see .rule-trust).
+// Returns 0, or the error code of zelph_process_h.
+extern "C" int zelph_set_min_trust_h(zelph_instance* z, uint64_t rule, double threshold)
+{
+    z->clear_error();
+    try
+    {
+        if (rule == 0)
+            z->interactive.set_min_trust(threshold);
+        else
+            z->interactive.set_rule_min_trust(rule, threshold);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Adds a fact to a context (add != 0) or removes it from one. Returns 0,
+// or the error code of zelph_process_h.
+extern "C" int zelph_fact_context_h(zelph_instance* z, uint64_t fact, const char* context, size_t len, int add)
+{
+    z->clear_error();
+    try
+    {
+        if (add)
+            z->interactive.add_to_context(fact, std::string(context, 0, len));
+        else
+            z->interactive.remove_from_context(fact, std::string(context, 0, len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Limits a rule to the contexts, given as newline-separated names; none
+// removes the limit (see .rule-context). Returns 0, or the error code of
+// zelph_process_h.
+extern "C" int zelph_set_rule_contexts_h(zelph_instance* z, uint64_t rule, const char* contexts, size_t len)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_rule_contexts(rule, split_contexts(contexts, len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Limits queries and reasoning to the contexts, given as newline-separated
+// names; none removes the limit (see .context-scope).
+extern "C" void zelph_set_context_scope_h(zelph_instance* z, const char* contexts, size_t len)
+{
+    z->interactive.set_context_scope(split_contexts(contexts, len));
+}
+
+// Reads the validity interval of a fact (see .validity). has_from and
+// has_to are set to 0 for an open end. Returns 0, or the error code of
+// zelph_process_h.
+extern "C" int zelph_validity_h(zelph_instance* z, uint64_t fact, int* has_from, int64_t* from, int* has_to, int64_t* to)
+{
+    z->clear_error();
+    try
+    {
+        const auto validity = z->interactive.validity(fact);
+        *has_from           = validity.from ? 1 : 0;
+        *from               = validity.from.value_or(0);
+        *has_to             = validity.to ? 1 : 0;
+        *to                 = validity.to.value_or(0);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Sets the validity interval of a fact; an end whose has_ flag is 0 is
+// open. Returns 0, or the error code of zelph_process_h.
+extern "C" int zelph_set_validity_h(zelph_instance* z, uint64_t fact, int has_from, int64_t from, int has_to, int64_t to)
+{
+    z->clear_error();
+    console::Interactive::Validity validity;
+    if (has_from) validity.from = from;
+    if (has_to) validity.to = to;
+
+    try
+    {
+        z->interactive.set_validity(fact, validity);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Switches probabilistic inference on (non-zero) or off (see .probabilistic).
+extern "C" void zelph_set_probabilistic_h(zelph_instance* z, int on)
+{
+    z->interactive.set_probabilistic(on != 0);
+}
+
+// Sets the weight of a rule in [0,1] (see .rule-weight). Returns 0, or the
+// error code of zelph_process_h.
+extern "C" int zelph_set_rule_weight_h(zelph_instance* z, uint64_t rule, double weight)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_rule_weight(rule, weight);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+#endif
@@ -0,0 +1,258 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+
+#include <set>
+#include <utility>
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Monitoring metrics (see .metrics): zelph_metrics_h returns them in the
+// Prometheus text format, valid until the next call on this handle, for
+// example to be served by a Go promhttp handler.
+extern "C" void zelph_set_metrics_enabled_h(zelph_instance* z, int enabled)
+{
+    z->interactive.set_metrics_enabled(enabled != 0);
+}
+
+extern "C" const char* zelph_metrics_h(zelph_instance* z)
+{
+    z->last_metrics = z->interactive.metrics();
+    return z->last_metrics.c_str();
+}
+
+// Rule profiling (see .rule-profile): zelph_rule_profile_h snapshots the
+// profile of the last profiled run and returns its number of rules, read
+// by index with zelph_rule_profile_entry (1, or 0 for an index out of
+// range); zelph_rule_profile_report_h returns the pprof-style table, valid
+// until the next call on this handle.
+extern "C" void zelph_set_rule_profiling_h(zelph_instance* z, int enabled)
+{
+    z->interactive.set_rule_profiling(enabled != 0);
+}
+
+extern "C" int zelph_rule_profile_h(zelph_instance* z)
+{
+    z->last_rule_profile = z->interactive.rule_profile();
+    return static_cast<int>(z->last_rule_profile.size());
+}
+
+extern "C" int zelph_rule_profile_entry(const zelph_instance* z, int i, uint64_t* rule, uint64_t* firings, uint64_t* deductions, double* seconds)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_rule_profile.size()) return 0;
+    const auto& p = z->last_rule_profile[i];
+    *rule         = p.rule;
+    *firings      = p.firings;
+    *deductions   = p.deductions;
+    *seconds      = p.seconds;
+    return 1;
+}
+
+extern "C" const char* zelph_rule_profile_report_h(zelph_instance* z)
+{
+    z->last_rule_profile_report = z->interactive.rule_profile_report();
+    return z->last_rule_profile_report.c_str();
+}
+
+// Graph statistics (see .graph-stats). zelph_graph_stats_h takes a
+// snapshot, stores the totals and returns the number of relations, which
+// are read by index; zelph_graph_stats_degrees returns the number of
+// distinct in- (out=0) or out-degrees (out=1), read by
+// zelph_graph_stats_degree in ascending order.
+extern "C" int zelph_graph_stats_h(zelph_instance* z, uint64_t* nodes, uint64_t* statements, uint64_t* edges, uint64_t* components)
+{
+    z->last_graph_stats = z->interactive.graph_stats();
+    z->last_in_degrees.assign(z->last_graph_stats.in_degrees.begin(), z->last_graph_stats.in_degrees.end());
+    z->last_out_degrees.assign(z->last_graph_stats.out_degrees.begin(), z->last_graph_stats.out_degrees.end());
+    *nodes      = z->last_graph_stats.nodes;
+    *statements = z->last_graph_stats.statements;
+    *edges      = z->last_graph_stats.edges;
+    *components = z->last_graph_stats.components;
+    return static_cast<int>(z->last_graph_stats.relations.size());
+}
+
+// Network statistics (see .stat): stores the numbers of nodes and rules and
+// the memory of the process in bytes (0 if unknown) and returns the number
+// of languages with names.
+extern "C" int zelph_statistics_h(zelph_instance* z, uint64_t* nodes, uint64_t* rules, uint64_t* memory_bytes)
+{
+    const auto statistics = z->interactive.statistics();
+    *nodes                = statistics.nodes;
+    *rules                = statistics.rules;
+    *memory_bytes         = statistics.memory_bytes;
+    return static_cast<int>(statistics.languages.size());
+}
+
+static const console::Interactive::GraphStats::Relation* graph_relation_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_graph_stats.relations.size()) return nullptr;
+    return &z->last_graph_stats.relations[i];
+}
+
+extern "C" uint64_t zelph_graph_stats_relation(const zelph_instance* z, int i)
+{
+    const auto* r = graph_relation_at(z, i);
+    return r ? r->predicate : 0;
+}
+
+extern "C" const char* zelph_graph_stats_relation_name(const zelph_instance* z, int i)
+{
+    const auto* r = graph_relation_at(z, i);
+    return r ? r->name.c_str() : "";
+}
+
+extern "C" uint64_t zelph_graph_stats_relation_count(const zelph_instance* z, int i)
+{
+    const auto* r = graph_relation_at(z, i);
+    return r ? r->statements : 0;
+}
+
+extern "C" int zelph_graph_stats_degrees(const zelph_instance* z, int out)
+{
+    return static_cast<int>((out ? z->last_out_degrees : z->last_in_degrees).size());
+}
+
+extern "C" int zelph_graph_stats_degree(const zelph_instance* z, int out, int i, uint64_t* degree, uint64_t* nodes)
+{
+    const auto& degrees = out ? z->last_out_degrees : z->last_in_degrees;
+    if (i < 0 || static_cast<size_t>(i) >= degrees.size()) return 0;
+    *degree = degrees[i].first;
+    *nodes  = degrees[i].second;
+    return 1;
+}
+
+// Finds up to max_paths paths from one node to another (see
+// console::Interactive::find_paths); relations, if relation_count is not
+// 0, holds the NUL-terminated names of the relations that may be
+// followed. Returns the number of paths, whose steps are read by index,
+// or the negated error code of zelph_process_h.
+extern "C" int zelph_find_paths_h(zelph_instance* z, const char* from, size_t from_len, const char* to, size_t to_len,
+                                  size_t max_paths, size_t max_depth, int directed, const char* const* relations, size_t relation_count)
+{
+    z->clear_error();
+    z->last_paths.clear();
+
+    console::Interactive::PathOptions options;
+    options.max_paths = max_paths;
+    options.max_depth = max_depth;
+    options.directed  = directed != 0;
+    for (size_t i = 0; i < relation_count; ++i)
+        options.relations.emplace_back(relations[i]);
+    try
+    {
+        z->last_paths = z->interactive.find_paths(std::string(from, 0, from_len), std::string(to, 0, to_len), options);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_paths.size());
+}
+
+extern "C" int zelph_path_length(const zelph_instance* z, int path)
+{
+    if (path < 0 || static_cast<size_t>(path) >= z->last_paths.size()) return 0;
+    return static_cast<int>(z->last_paths[path].size());
+}
+
+static const console::Interactive::PathStep* path_step_at(const zelph_instance* z, int path, int step)
+{
+    if (step < 0 || step >= zelph_path_length(z, path)) return nullptr;
+    return &z->last_paths[path][step];
+}
+
+// Reads a step of a path. Returns 0 if there is no such step.
+// zelph_path_step_text renders its from node (part 0), predicate (1) or
+// to node (2).
+extern "C" int zelph_path_step(const zelph_instance* z, int path, int step, uint64_t* fact, uint64_t* from, uint64_t* predicate, uint64_t* to, int* forward)
+{
+    const auto* s = path_step_at(z, path, step);
+    if (!s) return 0;
+    *fact      = s->fact;
+    *from      = s->from;
+    *predicate = s->predicate;
+    *to        = s->to;
+    *forward   = s->forward ? 1 : 0;
+    return 1;
+}
+
+extern "C" const char* zelph_path_step_text(const zelph_instance* z, int path, int step, int part)
+{
+    const auto* s = path_step_at(z, path, step);
+    if (!s) return "";
+    return part == 0 ? s->from_name.c_str() : part == 1 ? s->predicate_name.c_str() : s->to_name.c_str();
+}
+
+// Translates the OWL axioms in the network into rules (see
+// console::Interactive::owl_rules). Returns the number of rules added or the
+// negated error code of zelph_process_h; axioms receives the number of
+// axioms found if not null.
+extern "C" int zelph_owl_rules_h(zelph_instance* z, int* axioms)
+{
+    z->clear_error();
+    try
+    {
+        const auto translation = z->interactive.owl_rules();
+        if (axioms) *axioms = static_cast<int>(translation.axioms);
+        return static_cast<int>(translation.rules);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// Copies the statements within depth steps of the NUL-terminated roots
+// into target, typically a fresh zelph_new() handle (see
+// console::Interactive::copy_subgraph). If relation_count is not 0, only
+// the named relations are followed. Returns the number of statements
+// copied or the negated error code of zelph_process_h.
+extern "C" int zelph_subgraph_h(zelph_instance* z, const char* const* roots, size_t root_count, int depth,
+                                const char* const* relations, size_t relation_count, zelph_instance* target)
+{
+    z->clear_error();
+
+    const std::vector<std::string> root_names(roots, roots + root_count);
+    console::Interactive::RelationFilter follow;
+    if (relation_count > 0)
+    {
+        std::set<std::string> names(relations, relations + relation_count);
+        follow = [names = std::move(names)](const std::string& relation)
+        { return names.count(relation) == 1; };
+    }
+    try
+    {
+        return static_cast<int>(z->interactive.copy_subgraph(root_names, depth, follow, target->interactive));
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+#endif
@@ -0,0 +1,432 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+#include "string/string_utils.hpp"
+
+#include <exception>
+#include <fstream>
+#include <sstream>
+#include <utility>
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Datalog export (see .export-datalog): the facts and rules as Soufflé
+// clauses, or SWI-Prolog ones if prolog is non-zero, valid until the next
+// call on this handle, e.g. for a Go caller to copy to an io.Writer.
+extern "C" const char* zelph_export_datalog_h(zelph_instance* z, int prolog, int deduced)
+{
+    std::ostringstream out;
+    z->interactive.export_datalog(out, {prolog ? io::DatalogDialect::Prolog : io::DatalogDialect::Souffle, deduced != 0});
+    z->last_datalog = out.str();
+    return z->last_datalog.c_str();
+}
+
+// Streaming bulk load of plain facts (see .bulk-load): begin, feed the
+// input in chunks of any size (lines may span chunks), end. The optional
+// progress callback is invoked every progress_interval lines and once at
+// the end. zelph_bulk_end_h returns the number of loaded facts, or the
+// negated error code; a failed feed aborts the load (facts read until
+// then are kept) and must not be followed by further feeds.
+using zelph_bulk_progress_fn = void (*)(uint64_t lines, uint64_t facts, uint64_t bytes, double seconds, void* user);
+
+extern "C" int zelph_bulk_begin_h(zelph_instance* z, uint64_t progress_interval, zelph_bulk_progress_fn progress, void* user)
+{
+    z->clear_error();
+
+    io::BulkOptions options;
+    options.progress_interval = progress_interval;
+    if (progress) options.progress = [progress, user](const io::BulkProgress& p)
+    { progress(p.lines, p.facts, p.bytes, p.seconds, user); };
+
+    z->bulk = z->interactive.bulk_loader(options);
+    return 0;
+}
+
+extern "C" int zelph_bulk_feed_h(zelph_instance* z, const char* data, size_t len)
+{
+    z->clear_error();
+    if (!z->bulk) return z->record_error(console::ProcessErrorKind::Command, "no bulk load in progress", "");
+
+    try
+    {
+        z->bulk->feed(std::string_view(data, len));
+    }
+    catch (const std::exception& ex)
+    {
+        z->bulk.reset();
+        return z->record_error(console::ProcessErrorKind::Statement, ex.what(), "");
+    }
+    return 0;
+}
+
+extern "C" long long zelph_bulk_end_h(zelph_instance* z)
+{
+    z->clear_error();
+    if (!z->bulk) return -z->record_error(console::ProcessErrorKind::Command, "no bulk load in progress", "");
+
+    try
+    {
+        const long long facts = static_cast<long long>(z->bulk->finish().facts);
+        z->bulk.reset();
+        return facts;
+    }
+    catch (const std::exception& ex)
+    {
+        z->bulk.reset();
+        return -z->record_error(console::ProcessErrorKind::Statement, ex.what(), "");
+    }
+}
+
+// Imports CSV text as facts (see .import-csv). mapping holds the arguments
+// of the command after the file name, e.g. "name \"=lives in\" city
+// delimiter=;". Returns the number of facts created, or the negated error
+// code. Rows that yielded no fact are read with the zelph_csv_error_*
+// accessors until the next call.
+extern "C" long long zelph_import_csv_h(zelph_instance* z, const char* data, size_t len, const char* mapping, size_t mapping_len)
+{
+    z->clear_error();
+    z->last_csv_errors.clear();
+
+    io::CsvMapping parsed;
+    try
+    {
+        parsed = io::parse_csv_mapping(zelph::string::tokenize_quoted(std::string(mapping, mapping_len)));
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Command, ex.what(), "");
+    }
+
+    std::istringstream in(std::string(data, len));
+    try
+    {
+        io::CsvResult result = z->interactive.import_csv(in, parsed);
+        z->last_csv_errors   = std::move(result.errors);
+        return static_cast<long long>(result.facts);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+extern "C" int zelph_csv_errors(const zelph_instance* z)
+{
+    return static_cast<int>(z->last_csv_errors.size());
+}
+
+// 1-based line of the row, its column (empty for the whole row) and the
+// message; out-of-range indexes yield 0 and empty strings.
+extern "C" uint64_t zelph_csv_error_line(const zelph_instance* z, int i)
+{
+    return i >= 0 && static_cast<size_t>(i) < z->last_csv_errors.size() ? z->last_csv_errors[i].line : 0;
+}
+
+extern "C" const char* zelph_csv_error_column(const zelph_instance* z, int i)
+{
+    return i >= 0 && static_cast<size_t>(i) < z->last_csv_errors.size() ? z->last_csv_errors[i].column.c_str() : "";
+}
+
+extern "C" const char* zelph_csv_error_text(const zelph_instance* z, int i)
+{
+    return i >= 0 && static_cast<size_t>(i) < z->last_csv_errors.size() ? z->last_csv_errors[i].message.c_str() : "";
+}
+
+// Imports a ConceptNet assertions file (see .import-conceptnet), read from
+// path since the dumps are too large to pass in memory. options holds the
+// arguments of the command after the file name, e.g. "languages=en,de
+// relations=IsA,PartOf". Returns the number of facts created, or the
+// negated error code.
+extern "C" long long zelph_import_conceptnet_h(zelph_instance* z, const char* path, size_t path_len, const char* options, size_t options_len)
+{
+    z->clear_error();
+
+    io::ConceptNetOptions parsed;
+    try
+    {
+        parsed = io::parse_conceptnet_options(zelph::string::tokenize_quoted(std::string(options, options_len)));
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Command, ex.what(), "");
+    }
+
+    const std::string file(path, path_len);
+    std::ifstream     in(file, std::ios::binary);
+    if (!in) return -z->record_error(console::ProcessErrorKind::Command, "cannot open '" + file + "'", "");
+    try
+    {
+        return static_cast<long long>(z->interactive.import_conceptnet(in, parsed).facts);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// Imports WordNet data files (see .import-wordnet); path is a data file or
+// the dict directory holding them. options as for the command, e.g.
+// "relations=all glosses". Returns the number of facts created, or the
+// negated error code.
+extern "C" long long zelph_import_wordnet_h(zelph_instance* z, const char* path, size_t path_len, const char* options, size_t options_len)
+{
+    z->clear_error();
+
+    io::WordNetOptions parsed;
+    try
+    {
+        parsed = io::parse_wordnet_options(zelph::string::tokenize_quoted(std::string(options, options_len)));
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Command, ex.what(), "");
+    }
+
+    const std::vector<std::string> files = io::wordnet_data_files(std::string(path, path_len));
+    if (files.empty()) return -z->record_error(console::ProcessErrorKind::Command, "no WordNet data files in '" + std::string(path, path_len) + "'", "");
+
+    long long facts = 0;
+    for (const std::string& file : files)
+    {
+        std::ifstream in(file, std::ios::binary);
+        if (!in) return -z->record_error(console::ProcessErrorKind::Command, "cannot open '" + file + "'", "");
+        try
+        {
+            facts += static_cast<long long>(z->interactive.import_wordnet(in, parsed).facts);
+        }
+        catch (const console::process_error& ex)
+        {
+            return -z->record_error(ex);
+        }
+    }
+    return facts;
+}
+
+// Synchronizes the rows of a query result with the network (see
+// console::Interactive::sync_rows), e.g. those of a database/sql query that
+// a Go caller runs on its own schedule: begin with the sync's name, the
+// template and the column names, add the rows, end. A NULL value is passed
+// as a null pointer. zelph_sync_begin_h and zelph_sync_row_h return 0 or
+// the error code; zelph_sync_end_h returns the number of facts the sync
+// owns, or the negated error code, and the added and retracted facts if
+// the pointers are not null. zelph_drop_sync_h retracts the facts of a
+// sync and returns their number, or the negated error code.
+extern "C" int zelph_sync_begin_h(zelph_instance* z, const char* name, size_t name_len, const char* row_template, size_t template_len,
+                                  const char* const* columns, size_t column_count)
+{
+    z->clear_error();
+    z->sync_template.reset();
+    z->sync_rows = {};
+
+    try
+    {
+        z->sync_template.emplace(std::string(row_template, template_len));
+    }
+    catch (const std::exception& ex)
+    {
+        return z->record_error(console::ProcessErrorKind::Command, ex.what(), "");
+    }
+    z->sync_name = std::string(name, name_len);
+    for (size_t i = 0; i < column_count; ++i)
+        z->sync_rows.columns.emplace_back(columns[i]);
+    return 0;
+}
+
+extern "C" int zelph_sync_row_h(zelph_instance* z, const char* const* values, size_t count)
+{
+    z->clear_error();
+    if (!z->sync_template) return z->record_error(console::ProcessErrorKind::Command, "no sync in progress", "");
+    if (count != z->sync_rows.columns.size())
+        return z->record_error(console::ProcessErrorKind::Command, "expected " + std::to_string(z->sync_rows.columns.size()) + " values", "");
+
+    auto& row = z->sync_rows.rows.emplace_back();
+    for (size_t i = 0; i < count; ++i)
+        row.push_back(values[i] ? std::optional<std::string>(values[i]) : std::nullopt);
+    return 0;
+}
+
+extern "C" long long zelph_sync_end_h(zelph_instance* z, uint64_t* added, uint64_t* retracted)
+{
+    z->clear_error();
+    if (!z->sync_template) return -z->record_error(console::ProcessErrorKind::Command, "no sync in progress", "");
+
+    const io::RowTemplate row_template = std::move(*z->sync_template);
+    const io::RowSet      rows         = std::move(z->sync_rows);
+    z->sync_template.reset();
+    z->sync_rows = {};
+    try
+    {
+        const auto result = z->interactive.sync_rows(z->sync_name, row_template, rows);
+        if (added) *added = result.added;
+        if (retracted) *retracted = result.retracted;
+        return static_cast<long long>(result.facts);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+extern "C" long long zelph_drop_sync_h(zelph_instance* z, const char* name, size_t len)
+{
+    z->clear_error();
+    try
+    {
+        return static_cast<long long>(z->interactive.drop_sync(std::string(name, len)));
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// Fact stores (see console::Interactive::open_store). zelph_create_store_h
+// and zelph_store_import_h return the number of facts, zelph_store_match_h
+// takes a snapshot of the matching facts (an empty part matches anything)
+// and returns their number, read with zelph_stored_fact_*; all three
+// return the negated error code of zelph_process_h on error.
+// zelph_open_store_h returns 0 or the error code; an empty file closes the
+// store.
+extern "C" long long zelph_create_store_h(zelph_instance* z, const char* file, size_t len)
+{
+    z->clear_error();
+    try
+    {
+        return static_cast<long long>(z->interactive.create_store(std::string(file, 0, len)));
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+extern "C" int zelph_open_store_h(zelph_instance* z, const char* file, size_t len, size_t cache_bytes)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.open_store(std::string(file, 0, len), cache_bytes);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+extern "C" long long zelph_store_match_h(zelph_instance* z, const char* subject, size_t subject_len, const char* predicate, size_t predicate_len,
+                                         const char* object, size_t object_len, size_t limit)
+{
+    z->clear_error();
+    z->last_stored_facts.clear();
+    try
+    {
+        z->last_stored_facts = z->interactive.store_match(std::string(subject, 0, subject_len), std::string(predicate, 0, predicate_len),
+                                                          std::string(object, 0, object_len), limit);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<long long>(z->last_stored_facts.size());
+}
+
+static const io::StoredFact* stored_fact_at(const zelph_instance* z, long long i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_stored_facts.size()) return nullptr;
+    return &z->last_stored_facts[i];
+}
+
+extern "C" const char* zelph_stored_fact_subject(const zelph_instance* z, long long i)
+{
+    const auto* f = stored_fact_at(z, i);
+    return f ? f->subject.c_str() : "";
+}
+
+extern "C" const char* zelph_stored_fact_predicate(const zelph_instance* z, long long i)
+{
+    const auto* f = stored_fact_at(z, i);
+    return f ? f->predicate.c_str() : "";
+}
+
+extern "C" const char* zelph_stored_fact_object(const zelph_instance* z, long long i)
+{
+    const auto* f = stored_fact_at(z, i);
+    return f ? f->object.c_str() : "";
+}
+
+extern "C" long long zelph_store_import_h(zelph_instance* z, const char* subject, size_t subject_len, const char* predicate, size_t predicate_len,
+                                          const char* object, size_t object_len)
+{
+    z->clear_error();
+    try
+    {
+        return static_cast<long long>(z->interactive.store_import(std::string(subject, 0, subject_len), std::string(predicate, 0, predicate_len),
+                                                                  std::string(object, 0, object_len)));
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+#ifndef __EMSCRIPTEN__
+// Writes the network to a .bin file (see .save). Returns 0 or an error
+// code as zelph_process_h; Go callers stream it to an io.Writer themselves.
+extern "C" int zelph_save_h(zelph_instance* z, const char* file, size_t len)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.save(std::string(file, 0, len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Loads a .bin file written by zelph_save_h (facts, deductions, rules
+// and names) without re-running any script. See .load.
+extern "C" int zelph_load_h(zelph_instance* z, const char* file, size_t len)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.load(std::string(file, 0, len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+#endif
+#endif
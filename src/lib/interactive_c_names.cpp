@@ -0,0 +1,250 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+
+#include <algorithm>
+#include <utility>
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Names a concept in a language (see console::Interactive::alias) and
+// stores its node ID in *node. Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_alias_h(zelph_instance* z, const char* concept_name, size_t concept_len, const char* lang, size_t lang_len, const char* name, size_t name_len, uint64_t* node)
+{
+    z->clear_error();
+    try
+    {
+        *node = z->interactive.alias(std::string(concept_name, 0, concept_len), std::string(lang, 0, lang_len), std::string(name, 0, name_len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Looks a name up in a language (current language if lang_len is 0).
+// Returns 1 and stores the node ID in *node if found, 0 otherwise.
+extern "C" int zelph_resolve_name_h(zelph_instance* z, const char* name, size_t name_len, const char* lang, size_t lang_len, uint64_t* node)
+{
+    const auto found = z->interactive.resolve_name(std::string(name, 0, name_len), lang_len ? std::string(lang, 0, lang_len) : "");
+    if (!found) return 0;
+    *node = *found;
+    return 1;
+}
+
+// Searches concept names (see console::Interactive::search_concepts; lang_len
+// 0 for the current language, fuzzy 0 or 1), takes a snapshot of the
+// matches and returns their number, read with zelph_concept_match_*.
+extern "C" size_t zelph_search_concepts_h(zelph_instance* z, const char* query, size_t query_len, size_t limit, int fuzzy, const char* lang, size_t lang_len)
+{
+    z->last_concepts = z->interactive.search_concepts(std::string(query, 0, query_len), limit, fuzzy != 0, lang_len ? std::string(lang, 0, lang_len) : "");
+    return z->last_concepts.size();
+}
+
+static const console::Interactive::ConceptMatch* concept_match_at(const zelph_instance* z, size_t i)
+{
+    return i < z->last_concepts.size() ? &z->last_concepts[i] : nullptr;
+}
+
+extern "C" uint64_t zelph_concept_match_id(const zelph_instance* z, size_t i)
+{
+    const auto* m = concept_match_at(z, i);
+    return m ? m->id : 0;
+}
+
+extern "C" const char* zelph_concept_match_name(const zelph_instance* z, size_t i)
+{
+    const auto* m = concept_match_at(z, i);
+    return m ? m->name.c_str() : "";
+}
+
+extern "C" const char* zelph_concept_match_kind(const zelph_instance* z, size_t i)
+{
+    const auto* m = concept_match_at(z, i);
+    return m ? m->kind.c_str() : "";
+}
+
+// Embeddings (see console::Interactive::set_embedding), e.g. for a Go
+// SetEmbedding(concept, []float32): values points to dimension floats.
+// Returns 0 or the error code of zelph_process_h.
+extern "C" int zelph_set_embedding_h(zelph_instance* z, uint64_t node, const float* values, size_t dimension)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_embedding(node, std::vector<float>(values, values + dimension));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Copies up to capacity values of the node's embedding to values and
+// returns its dimension, 0 if the node has none.
+extern "C" size_t zelph_embedding_h(zelph_instance* z, uint64_t node, float* values, size_t capacity)
+{
+    const std::vector<float> embedding = z->interactive.embedding(node);
+    std::copy_n(embedding.begin(), std::min(capacity, embedding.size()), values);
+    return embedding.size();
+}
+
+// The k concepts most similar to a concept or to a vector (see
+// console::Interactive::similar_concepts). Both take a snapshot and return
+// its size, read with zelph_similar_concept_*, or the negated error code.
+extern "C" long long zelph_similar_concepts_h(zelph_instance* z, uint64_t node, size_t k)
+{
+    z->clear_error();
+    z->last_similar.clear();
+    try
+    {
+        z->last_similar = z->interactive.similar_concepts(node, k);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<long long>(z->last_similar.size());
+}
+
+extern "C" long long zelph_similar_to_h(zelph_instance* z, const float* values, size_t dimension, size_t k)
+{
+    z->clear_error();
+    z->last_similar.clear();
+    try
+    {
+        z->last_similar = z->interactive.similar_concepts(std::vector<float>(values, values + dimension), k);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<long long>(z->last_similar.size());
+}
+
+static const console::Interactive::SimilarConcept* similar_concept_at(const zelph_instance* z, long long i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_similar.size()) return nullptr;
+    return &z->last_similar[i];
+}
+
+extern "C" uint64_t zelph_similar_concept_id(const zelph_instance* z, long long i)
+{
+    const auto* c = similar_concept_at(z, i);
+    return c ? c->id : 0;
+}
+
+extern "C" const char* zelph_similar_concept_name(const zelph_instance* z, long long i)
+{
+    const auto* c = similar_concept_at(z, i);
+    return c ? c->name.c_str() : "";
+}
+
+extern "C" double zelph_similar_concept_similarity(const zelph_instance* z, long long i)
+{
+    const auto* c = similar_concept_at(z, i);
+    return c ? c->similarity : 0;
+}
+
+// Returns the node for a name (see console::Interactive::intern), or 0 with
+// the error set (see zelph_last_error).
+extern "C" uint64_t zelph_intern_h(zelph_instance* z, const char* name, size_t name_len)
+{
+    z->clear_error();
+    try
+    {
+        return z->interactive.intern(std::string(name, 0, name_len));
+    }
+    catch (const console::process_error& ex)
+    {
+        z->record_error(ex);
+        return 0;
+    }
+}
+
+// States count facts given as consecutive subject, predicate and object
+// IDs in triples (3 * count values) and stores their fact IDs in facts,
+// which may be null. Returns the number of facts stated, which is less
+// than count on error.
+extern "C" size_t zelph_add_facts_h(zelph_instance* z, const uint64_t* triples, size_t count, uint64_t* facts)
+{
+    z->clear_error();
+    size_t stated = 0;
+    try
+    {
+        for (; stated < count; ++stated)
+        {
+            const uint64_t fact = z->interactive.add_fact(triples[3 * stated], triples[3 * stated + 1], triples[3 * stated + 2]);
+            if (facts) facts[stated] = fact;
+        }
+    }
+    catch (const console::process_error& ex)
+    {
+        z->record_error(ex);
+    }
+    return stated;
+}
+
+// Name matching (see .normalize and .case-fold). form is 0 for none, 1
+// for NFC (the default) and 2 for NFKC.
+extern "C" void zelph_set_name_normalization_h(zelph_instance* z, int form)
+{
+    z->interactive.set_name_normalization(form == 0   ? console::Interactive::Normalization::None
+                                          : form == 2 ? console::Interactive::Normalization::NFKC
+                                                      : console::Interactive::Normalization::NFC);
+}
+
+extern "C" void zelph_set_case_folding_h(zelph_instance* z, int enabled)
+{
+    z->interactive.set_case_folding(enabled != 0);
+}
+
+// Replaces the additional quote pairs of the parser (see .quotes) with
+// count pairs of NUL-terminated delimiters. Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_set_quote_pairs_h(zelph_instance* z, const char* const* opens, const char* const* closes, size_t count)
+{
+    z->clear_error();
+    std::vector<std::pair<std::string, std::string>> pairs;
+    for (size_t i = 0; i < count; ++i)
+        pairs.emplace_back(opens[i], closes[i]);
+    try
+    {
+        z->interactive.set_quote_pairs(pairs);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+#endif
@@ -0,0 +1,844 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+
+#include <algorithm>
+#include <chrono>
+#include <exception>
+#include <iterator>
+#include <sstream>
+#include <utility>
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Query plans (see console::Interactive::explain_query). Both functions
+// take a snapshot of the plan and return its number of steps, read with
+// zelph_plan_step_*, or the negated error code of zelph_process_h.
+extern "C" long long zelph_explain_query_h(zelph_instance* z, const char* statement, size_t len)
+{
+    z->clear_error();
+    z->last_plan.clear();
+    try
+    {
+        z->last_plan = z->interactive.explain_query(std::string(statement, 0, len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<long long>(z->last_plan.size());
+}
+
+extern "C" long long zelph_explain_rule_h(zelph_instance* z, uint64_t rule)
+{
+    z->clear_error();
+    z->last_plan.clear();
+    try
+    {
+        z->last_plan = z->interactive.explain_rule(rule);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<long long>(z->last_plan.size());
+}
+
+static const console::Interactive::PlanStep* plan_step_at(const zelph_instance* z, long long i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_plan.size()) return nullptr;
+    return &z->last_plan[i];
+}
+
+extern "C" const char* zelph_plan_step_condition(const zelph_instance* z, long long i)
+{
+    const auto* s = plan_step_at(z, i);
+    return s ? s->condition.c_str() : "";
+}
+
+extern "C" const char* zelph_plan_step_access(const zelph_instance* z, long long i)
+{
+    const auto* s = plan_step_at(z, i);
+    return s ? s->access.c_str() : "";
+}
+
+extern "C" uint64_t zelph_plan_step_candidates(const zelph_instance* z, long long i)
+{
+    const auto* s = plan_step_at(z, i);
+    return s ? s->candidates : 0;
+}
+
+// 1 if the candidates are an average per binding of the earlier steps.
+extern "C" int zelph_plan_step_per_binding(const zelph_instance* z, long long i)
+{
+    const auto* s = plan_step_at(z, i);
+    return s && s->per_binding ? 1 : 0;
+}
+
+// Answers a zelph statement with variables. Returns the number of
+// answers (>= 0), or the negated error code of zelph_process_h on failure.
+// Bindings are read via zelph_query_binding_count/_variable/_value and
+// stay valid until the next zelph_query_h call on the same instance.
+extern "C" int zelph_query_h(zelph_instance* z, const char* statement, size_t len)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+    z->last_query_notice.clear();
+
+    std::string stmt(statement, 0, len);
+    try
+    {
+        auto report          = z->interactive.query_report(stmt);
+        z->last_query_notice = std::move(report.notice);
+        for (const auto& answer : report.answers)
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Statement, ex.what(), stmt);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// Former name of zelph_query_h, kept for existing callers.
+extern "C" int zelph_query_c(zelph_instance* z, const char* statement, size_t len)
+{
+    return zelph_query_h(z, statement, len);
+}
+
+// Answers a statement with variables within bounds (see
+// console::Interactive::query_bounded): at most timeout_ms milliseconds and
+// max_matches matches, 0 for no bound. Returns the number of answers found,
+// read like those of zelph_query_h, or the negated error code of
+// zelph_process_h. zelph_query_truncated tells whether a bound stopped the
+// query, so that the answers are partial.
+extern "C" int zelph_query_bounded_h(zelph_instance* z, const char* statement, size_t len, uint64_t timeout_ms, uint64_t max_matches)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+    z->last_truncation.clear();
+
+    try
+    {
+        auto result = z->interactive.query_bounded(std::string(statement, 0, len),
+                                                   {std::chrono::milliseconds(timeout_ms), max_matches});
+        if (result.truncated) z->last_truncation = result.reason.empty() ? "truncated" : result.reason;
+        for (const auto& answer : result.answers)
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// 1 if a bound stopped the most recent zelph_query_bounded_h call, else 0;
+// zelph_query_truncation returns the bound, valid until the next call.
+extern "C" int zelph_query_truncated(const zelph_instance* z)
+{
+    return z->last_truncation.empty() ? 0 : 1;
+}
+
+extern "C" const char* zelph_query_truncation(const zelph_instance* z)
+{
+    return z->last_truncation.c_str();
+}
+
+// Streams the answers of a statement with variables (see
+// console::Interactive::query_each): the callback receives each answer as
+// its variable names and values, valid during the call, and returns
+// nonzero for the next one or 0 to stop. The query waits for the callback,
+// so a consumer that blocks (e.g. a Go channel without reader) applies
+// backpressure. offset skips answers, a limit other than 0 bounds their
+// number. Returns the number of answers handed over, or the negated error
+// code of zelph_process_h on failure.
+using zelph_answer_fn = int (*)(int count, const char* const* variables, const char* const* values, void* user);
+
+extern "C" int zelph_query_each_h(zelph_instance* z, const char* statement, size_t len, uint64_t offset, uint64_t limit, zelph_answer_fn callback, void* user)
+{
+    z->clear_error();
+
+    std::string stmt(statement, 0, len);
+    try
+    {
+        const size_t handed = z->interactive.query_each(stmt, [callback, user](const console::Interactive::QueryBinding& answer)
+                                                        {
+            std::vector<const char*> variables;
+            std::vector<const char*> values;
+            for (const auto& [name, value] : answer)
+            {
+                variables.push_back(name.c_str());
+                values.push_back(value.c_str());
+            }
+            return callback(static_cast<int>(variables.size()), variables.data(), values.data(), user) != 0; },
+                                                        offset,
+                                                        limit);
+        return static_cast<int>(handed);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// How the statement of the most recent zelph_query_h call was parsed, if it
+// had no answers (see .no-matches), or "". Valid until the next call.
+extern "C" const char* zelph_query_notice(const zelph_instance* z)
+{
+    return z->last_query_notice.c_str();
+}
+
+// A console::Session of an instance, created by zelph_session_new and
+// freed by zelph_session_delete before the instance. Errors and answers of
+// the zelph_session_* calls are read from the instance as usual, so
+// concurrent callers (e.g. one goroutine per connection) serialize them.
+struct zelph_session
+{
+    console::Session session;
+};
+
+// Returns a new session named name, or nullptr on error (see
+// zelph_last_error).
+extern "C" zelph_session* zelph_session_new_h(zelph_instance* z, const char* name, size_t len)
+{
+    z->clear_error();
+    try
+    {
+        return new zelph_session{console::Session(z->interactive, std::string(name, 0, len))};
+    }
+    catch (const console::process_error& ex)
+    {
+        z->record_error(ex);
+        return nullptr;
+    }
+}
+
+extern "C" void zelph_session_delete(zelph_session* s)
+{
+    delete s;
+}
+
+// Like zelph_process_h, with the session's settings.
+extern "C" int zelph_session_process_h(zelph_instance* z, zelph_session* s, const char* line, size_t len)
+{
+    z->clear_error();
+
+    std::string l(line, 0, len);
+    try
+    {
+        s->session.process(l);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    catch (const std::exception& ex)
+    {
+        return z->record_error(console::ProcessErrorKind::Statement, ex.what(), l);
+    }
+    return 0;
+}
+
+// Like zelph_query_h, with the session's settings.
+extern "C" int zelph_session_query_h(zelph_instance* z, zelph_session* s, const char* statement, size_t len)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+
+    std::string stmt(statement, 0, len);
+    try
+    {
+        for (const auto& answer : s->session.query(stmt))
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Statement, ex.what(), stmt);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// Undoes the session's last line that added facts (see
+// console::Session::undo). Returns the number of facts removed, or the
+// negated error code of zelph_process_h.
+extern "C" int zelph_session_undo_h(zelph_instance* z, zelph_session* s)
+{
+    z->clear_error();
+    try
+    {
+        return static_cast<int>(s->session.undo());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// Like zelph_query_h, but leaves out answers whose confidence is below
+// min_confidence (see .min-confidence).
+extern "C" int zelph_query_min_confidence_h(zelph_instance* z, const char* statement, size_t len, double min_confidence)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+
+    std::string stmt(statement, 0, len);
+    try
+    {
+        for (const auto& answer : z->interactive.query(stmt, min_confidence))
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// Like zelph_query_h, but also records the probability of each answer
+// (see console::Interactive::query_probabilities), read via
+// zelph_query_probability.
+extern "C" int zelph_query_probabilities_h(zelph_instance* z, const char* statement, size_t len)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+
+    std::string stmt(statement, 0, len);
+    try
+    {
+        for (const auto& answer : z->interactive.query_probabilities(stmt))
+        {
+            z->last_answers.emplace_back(answer.binding.begin(), answer.binding.end());
+            z->last_probabilities.push_back(answer.probability);
+        }
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+extern "C" double zelph_query_probability(const zelph_instance* z, int answer)
+{
+    if (answer < 0 || static_cast<size_t>(answer) >= z->last_probabilities.size()) return 0;
+    return z->last_probabilities[answer];
+}
+
+// Like zelph_query_h, but leaves out answers unless all the facts they
+// matched are valid at the time point (see .as-of).
+extern "C" int zelph_query_as_of_h(zelph_instance* z, const char* statement, size_t len, int64_t time)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+
+    std::string stmt(statement, 0, len);
+    try
+    {
+        for (const auto& answer : z->interactive.query_as_of(stmt, time))
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// Answers a fact pattern of three terms (see console::Interactive::match)
+// without building a statement: a term is a variable name if its is_var
+// flag is set (an empty name matches anything), otherwise the name of a
+// node, taken literally. Returns and stores answers like zelph_query_h.
+extern "C" int zelph_match_h(zelph_instance* z,
+                             const char* subject, size_t subject_len, int subject_is_var,
+                             const char* predicate, size_t predicate_len, int predicate_is_var,
+                             const char* object, size_t object_len, int object_is_var)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+
+    auto term = [](const char* name, size_t len, int is_var)
+    {
+        return console::Interactive::Term{is_var != 0, std::string(name, 0, len)};
+    };
+    const console::Interactive::Triple pattern{term(subject, subject_len, subject_is_var),
+                                               term(predicate, predicate_len, predicate_is_var),
+                                               term(object, object_len, object_is_var)};
+    try
+    {
+        for (const auto& answer : z->interactive.match(pattern))
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Statement, ex.what(), "");
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// Answers a SPARQL SELECT query (see console::Interactive::sparql). Returns
+// the number of result rows (>= 0), or the negated error code of
+// zelph_process_h on failure. Rows are read like zelph_query_h answers,
+// holding the bound variables in projection order; the projected variables
+// themselves via zelph_sparql_variable_count/_variable.
+extern "C" int zelph_sparql_h(zelph_instance* z, const char* query, size_t len)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+
+    std::string text(query, 0, len);
+    try
+    {
+        auto result       = z->interactive.sparql(text);
+        z->last_variables = result.variables;
+        for (const auto& row : result.rows)
+        {
+            auto& answer = z->last_answers.emplace_back();
+            for (const auto& variable : result.variables)
+            {
+                auto it = row.find(variable);
+                if (it != row.end()) answer.emplace_back(variable, it->second);
+            }
+        }
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Script, ex.what(), text);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+extern "C" int zelph_sparql_variable_count(const zelph_instance* z)
+{
+    return static_cast<int>(z->last_variables.size());
+}
+
+extern "C" const char* zelph_sparql_variable(const zelph_instance* z, int index)
+{
+    if (index < 0 || index >= zelph_sparql_variable_count(z)) return "";
+    return z->last_variables[index].c_str();
+}
+
+extern "C" int zelph_query_binding_count(const zelph_instance* z, int answer)
+{
+    if (answer < 0 || static_cast<size_t>(answer) >= z->last_answers.size()) return 0;
+    return static_cast<int>(z->last_answers[answer].size());
+}
+
+extern "C" const char* zelph_query_variable(const zelph_instance* z, int answer, int binding)
+{
+    if (binding < 0 || binding >= zelph_query_binding_count(z, answer)) return "";
+    return z->last_answers[answer][binding].first.c_str();
+}
+
+extern "C" const char* zelph_query_value(const zelph_instance* z, int answer, int binding)
+{
+    if (binding < 0 || binding >= zelph_query_binding_count(z, answer)) return "";
+    return z->last_answers[answer][binding].second.c_str();
+}
+
+// Takes a snapshot of all statements (see console::Interactive::facts) and
+// returns their number, or the negated error code of zelph_process_h. The
+// accessors below read fact i of it; strings stay valid until the next
+// zelph_facts_h call on the same instance.
+extern "C" int zelph_facts_h(zelph_instance* z)
+{
+    z->clear_error();
+    z->last_facts.clear();
+    try
+    {
+        z->last_facts = z->interactive.facts();
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Statement, ex.what(), "");
+    }
+    return static_cast<int>(z->last_facts.size());
+}
+
+// Like zelph_facts_h, but takes only the statements matching a pattern
+// (see console::Interactive::facts): non-empty subject, predicate and
+// object names must equal the fact's parts; NULL or "" matches any.
+extern "C" int zelph_facts_matching_h(zelph_instance* z, const char* subject, const char* predicate, const char* object)
+{
+    auto term = [](const char* name)
+    {
+        return name && *name ? console::Interactive::Term::constant(name) : console::Interactive::Term::var();
+    };
+
+    z->clear_error();
+    z->last_facts.clear();
+    try
+    {
+        z->last_facts = z->interactive.facts(console::Interactive::Triple{term(subject), term(predicate), term(object)});
+    }
+    catch (const std::exception& ex)
+    {
+        return -z->record_error(console::ProcessErrorKind::Statement, ex.what(), "");
+    }
+    return static_cast<int>(z->last_facts.size());
+}
+
+static const console::Interactive::Fact* fact_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_facts.size()) return nullptr;
+    return &z->last_facts[i];
+}
+
+extern "C" uint64_t zelph_fact_id(const zelph_instance* z, int i)
+{
+    const auto* f = fact_at(z, i);
+    return f ? f->id : 0;
+}
+
+extern "C" const char* zelph_fact_subject(const zelph_instance* z, int i)
+{
+    const auto* f = fact_at(z, i);
+    return f ? f->subject.c_str() : "";
+}
+
+extern "C" const char* zelph_fact_predicate(const zelph_instance* z, int i)
+{
+    const auto* f = fact_at(z, i);
+    return f ? f->predicate.c_str() : "";
+}
+
+extern "C" int zelph_fact_object_count(const zelph_instance* z, int i)
+{
+    const auto* f = fact_at(z, i);
+    return f ? static_cast<int>(f->objects.size()) : 0;
+}
+
+extern "C" const char* zelph_fact_object(const zelph_instance* z, int i, int object)
+{
+    if (object < 0 || object >= zelph_fact_object_count(z, i)) return "";
+    return z->last_facts[i].objects[object].c_str();
+}
+
+// 1 if a rule deduced fact i in this session, 0 if it was stated or imported.
+extern "C" int zelph_fact_deduced(const zelph_instance* z, int i)
+{
+    const auto* f = fact_at(z, i);
+    return f && f->deduced ? 1 : 0;
+}
+
+// Like zelph_query_h, but only uses facts holding in at least one of the
+// contexts, given as newline-separated names (see .context-scope).
+extern "C" int zelph_query_in_h(zelph_instance* z, const char* statement, size_t len, const char* contexts, size_t contexts_len)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+
+    std::string stmt(statement, 0, len);
+    try
+    {
+        for (const auto& answer : z->interactive.query_in(stmt, split_contexts(contexts, contexts_len)))
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// Aggregates the answers of a query (see console::Interactive::aggregate).
+// group_by lists variables and aggregates lists specs like "count",
+// "count X", "min X", "max X" or "sum X", both newline-separated. Returns
+// the number of rows, or the negated error code of zelph_process_h (2 for
+// an unknown spec); row i is read with zelph_aggregate_group and
+// zelph_aggregate_value until the next zelph_aggregate_h call.
+extern "C" int zelph_aggregate_h(zelph_instance* z, const char* statement, size_t len, const char* group_by, size_t group_by_len, const char* aggregates, size_t aggregates_len)
+{
+    z->clear_error();
+    z->last_aggregate.clear();
+
+    using Aggregate = console::Interactive::Aggregate;
+    std::vector<Aggregate> specs;
+    for (const std::string& spec : split_contexts(aggregates, aggregates_len))
+    {
+        std::istringstream in(spec);
+        std::string        function, variable, rest;
+        in >> function >> variable >> rest;
+        if (function == "count")
+            specs.push_back(Aggregate::count(variable));
+        else if (function == "min" && !variable.empty())
+            specs.push_back(Aggregate::min(variable));
+        else if (function == "max" && !variable.empty())
+            specs.push_back(Aggregate::max(variable));
+        else if (function == "sum" && !variable.empty())
+            specs.push_back(Aggregate::sum(variable));
+        else
+            return -z->record_error(console::ProcessErrorKind::Command, "Unknown aggregate: " + spec, spec);
+        if (!rest.empty()) return -z->record_error(console::ProcessErrorKind::Command, "Unknown aggregate: " + spec, spec);
+    }
+
+    try
+    {
+        z->last_aggregate = z->interactive.aggregate(std::string(statement, 0, len), split_contexts(group_by, group_by_len), specs);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_aggregate.size());
+}
+
+// The value of a group_by variable in row i, or NULL.
+extern "C" const char* zelph_aggregate_group(const zelph_instance* z, int i, const char* variable)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_aggregate.size()) return nullptr;
+    const auto& group = z->last_aggregate[i].group;
+    const auto  it    = group.find(variable);
+    return it == group.end() ? nullptr : it->second.c_str();
+}
+
+// The value of aggregate j in row i, or NULL.
+extern "C" const char* zelph_aggregate_value(const zelph_instance* z, int i, int j)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_aggregate.size()) return nullptr;
+    const auto& values = z->last_aggregate[i].values;
+    if (j < 0 || static_cast<size_t>(j) >= values.size()) return nullptr;
+    return values[j].c_str();
+}
+
+// Explains a deduced fact (see console::Interactive::explain). Returns the
+// number of proof steps -- the fact itself first, then its premises in
+// preorder -- or the negated error code. Step i is read with the
+// zelph_proof_* accessors; zelph_proof_depth gives its level in the tree
+// (0 for the explained fact). Valid until the next zelph_explain_h call.
+extern "C" int zelph_explain_h(zelph_instance* z, uint64_t fact)
+{
+    z->clear_error();
+    z->last_proof_steps.clear();
+    try
+    {
+        z->last_proof = z->interactive.explain(fact);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+
+    std::function<void(const console::Interactive::Proof&, int)> flatten = [&](const console::Interactive::Proof& proof, int depth)
+    {
+        z->last_proof_steps.push_back({depth, &proof});
+        for (const auto& premise : proof.premises)
+            flatten(premise, depth + 1);
+    };
+    flatten(z->last_proof, 0);
+    return static_cast<int>(z->last_proof_steps.size());
+}
+
+// Natural-language questions (see console::Interactive::ask). The
+// translator writes the zelph statements for the question to buffer, one
+// per line, and returns their length in bytes; if that exceeds capacity,
+// it is called again with a buffer of that size. Pass nullptr to remove it.
+using zelph_translate_fn = size_t (*)(const char* question, size_t len, char* buffer, size_t capacity, void* user);
+
+extern "C" void zelph_set_translator_h(zelph_instance* z, zelph_translate_fn translator, void* user)
+{
+    if (!translator)
+    {
+        z->interactive.set_translator(nullptr);
+        return;
+    }
+
+    z->interactive.set_translator([translator, user](const std::string& question)
+                                  {
+        std::string buffer(4096, '\0');
+        size_t      length = translator(question.data(), question.size(), buffer.data(), buffer.size(), user);
+        if (length > buffer.size())
+        {
+            buffer.assign(length, '\0');
+            length = translator(question.data(), question.size(), buffer.data(), buffer.size(), user);
+        }
+        buffer.resize(std::min(length, buffer.size()));
+
+        std::vector<std::string> statements;
+        std::istringstream       lines(buffer);
+        for (std::string line; std::getline(lines, line);)
+        {
+            if (!line.empty() && line.back() == '\r') line.pop_back();
+            if (!line.empty()) statements.push_back(line);
+        }
+        return statements; });
+}
+
+// Answers a question and returns the number of statements it was
+// translated into, or the negated error code of zelph_process_h. The
+// statements, their answers and proofs are read with zelph_ask_* until the
+// next zelph_ask_h call; zelph_ask_error is "" for a statement that succeeded.
+extern "C" long long zelph_ask_h(zelph_instance* z, const char* question, size_t len)
+{
+    z->clear_error();
+    z->last_proof_steps.clear();
+    z->last_question = {};
+    try
+    {
+        z->last_question = z->interactive.ask(std::string(question, 0, len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<long long>(z->last_question.queries.size());
+}
+
+static const console::Interactive::AnsweredQuery* answered_query_at(const zelph_instance* z, long long i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_question.queries.size()) return nullptr;
+    return &z->last_question.queries[i];
+}
+
+extern "C" const char* zelph_ask_statement(const zelph_instance* z, long long i)
+{
+    const auto* q = answered_query_at(z, i);
+    return q ? q->statement.c_str() : "";
+}
+
+extern "C" const char* zelph_ask_error(const zelph_instance* z, long long i)
+{
+    const auto* q = answered_query_at(z, i);
+    return q ? q->error.c_str() : "";
+}
+
+extern "C" int zelph_ask_answer_count(const zelph_instance* z, long long i)
+{
+    const auto* q = answered_query_at(z, i);
+    return q ? static_cast<int>(q->answers.size()) : 0;
+}
+
+extern "C" int zelph_ask_binding_count(const zelph_instance* z, long long i, int answer)
+{
+    if (answer < 0 || answer >= zelph_ask_answer_count(z, i)) return 0;
+    return static_cast<int>(answered_query_at(z, i)->answers[answer].size());
+}
+
+extern "C" const char* zelph_ask_variable(const zelph_instance* z, long long i, int answer, int binding)
+{
+    if (binding < 0 || binding >= zelph_ask_binding_count(z, i, answer)) return "";
+    return std::next(answered_query_at(z, i)->answers[answer].begin(), binding)->first.c_str();
+}
+
+extern "C" const char* zelph_ask_value(const zelph_instance* z, long long i, int answer, int binding)
+{
+    if (binding < 0 || binding >= zelph_ask_binding_count(z, i, answer)) return "";
+    return std::next(answered_query_at(z, i)->answers[answer].begin(), binding)->second.c_str();
+}
+
+// Takes the proofs of an answer as the proof steps read with zelph_proof_*
+// (one tree per matched fact, each from depth 0, in preorder) and returns
+// their number. Valid until the next zelph_ask_h or zelph_explain_h call.
+extern "C" int zelph_ask_proof_h(zelph_instance* z, long long i, int answer)
+{
+    z->last_proof_steps.clear();
+    if (answer < 0 || answer >= zelph_ask_answer_count(z, i)) return 0;
+
+    std::function<void(const console::Interactive::Proof&, int)> flatten = [&](const console::Interactive::Proof& proof, int depth)
+    {
+        z->last_proof_steps.push_back({depth, &proof});
+        for (const auto& premise : proof.premises)
+            flatten(premise, depth + 1);
+    };
+    for (const auto& proof : answered_query_at(z, i)->proofs[answer])
+        flatten(proof, 0);
+    return static_cast<int>(z->last_proof_steps.size());
+}
+
+static const console::Interactive::Proof* proof_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_proof_steps.size()) return nullptr;
+    return z->last_proof_steps[i].proof;
+}
+
+extern "C" int zelph_proof_depth(const zelph_instance* z, int i)
+{
+    return proof_at(z, i) ? z->last_proof_steps[i].depth : -1;
+}
+
+extern "C" uint64_t zelph_proof_fact(const zelph_instance* z, int i)
+{
+    const auto* p = proof_at(z, i);
+    return p ? p->fact : 0;
+}
+
+extern "C" const char* zelph_proof_fact_text(const zelph_instance* z, int i)
+{
+    const auto* p = proof_at(z, i);
+    return p ? p->fact_text.c_str() : "";
+}
+
+// The rule that derived step i, 0 (and "") for a stated fact.
+extern "C" uint64_t zelph_proof_rule(const zelph_instance* z, int i)
+{
+    const auto* p = proof_at(z, i);
+    return p ? p->rule : 0;
+}
+
+extern "C" const char* zelph_proof_rule_text(const zelph_instance* z, int i)
+{
+    const auto* p = proof_at(z, i);
+    return p ? p->rule_text.c_str() : "";
+}
+#endif
@@ -0,0 +1,390 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+
+#include <memory>
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Snapshots the rules (see console::Interactive::rules) and returns their
+// count, read with the zelph_rule_* accessors until the next zelph_rules_h
+// call on the same instance.
+extern "C" int zelph_rules_h(zelph_instance* z)
+{
+    z->clear_error();
+    z->last_rules = z->interactive.rules();
+    return static_cast<int>(z->last_rules.size());
+}
+
+static const console::Interactive::Rule* rule_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_rules.size()) return nullptr;
+    return &z->last_rules[i];
+}
+
+extern "C" uint64_t zelph_rule_id(const zelph_instance* z, int i)
+{
+    const auto* r = rule_at(z, i);
+    return r ? r->id : 0;
+}
+
+extern "C" const char* zelph_rule_text(const zelph_instance* z, int i)
+{
+    const auto* r = rule_at(z, i);
+    return r ? r->text.c_str() : "";
+}
+
+extern "C" int zelph_rule_enabled(const zelph_instance* z, int i)
+{
+    const auto* r = rule_at(z, i);
+    return r && r->enabled ? 1 : 0;
+}
+
+extern "C" int zelph_rule_stratum(const zelph_instance* z, int i)
+{
+    const auto* r = rule_at(z, i);
+    return r ? r->stratum : 0;
+}
+
+extern "C" int zelph_rule_is_default(const zelph_instance* z, int i)
+{
+    const auto* r = rule_at(z, i);
+    return r && r->is_default ? 1 : 0;
+}
+
+extern "C" int zelph_rule_priority(const zelph_instance* z, int i)
+{
+    const auto* r = rule_at(z, i);
+    return r ? r->priority : 0;
+}
+
+// States "condition => consequence" and stores the rule's ID in *rule.
+// Returns 0 or an error code as zelph_process_h.
+extern "C" int zelph_add_rule_h(zelph_instance* z, const char* condition, size_t condition_len, const char* consequence, size_t consequence_len, uint64_t* rule)
+{
+    z->clear_error();
+    try
+    {
+        *rule = z->interactive.add_rule(std::string(condition, 0, condition_len), std::string(consequence, 0, consequence_len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Disables (enabled = 0) or re-enables a rule, see .disable-rule.
+extern "C" int zelph_set_rule_enabled_h(zelph_instance* z, uint64_t rule, int enabled)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_rule_enabled(rule, enabled != 0);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Puts a rule into a stratum, see .rule-stratum.
+extern "C" int zelph_set_rule_stratum_h(zelph_instance* z, uint64_t rule, int stratum)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_rule_stratum(rule, stratum);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Marks a rule as a default rule with a priority (is_default = 1) or not,
+// see .default-rule.
+extern "C" int zelph_set_rule_default_h(zelph_instance* z, uint64_t rule, int is_default, int priority)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.set_rule_default(rule, is_default != 0, priority);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Takes the assumptions that contradictions defeated (see .defeats) like
+// zelph_facts_h takes all statements and returns their number.
+extern "C" int zelph_defeats_h(zelph_instance* z)
+{
+    z->clear_error();
+    z->last_facts.clear();
+    for (const auto& defeat : z->interactive.defeats())
+        z->last_facts.push_back(defeat.assumption);
+    return static_cast<int>(z->last_facts.size());
+}
+
+extern "C" int zelph_remove_rule_h(zelph_instance* z, uint64_t rule)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.remove_rule(rule);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+static console::Debugger& debugger_of(zelph_instance* z)
+{
+    if (!z->debugger) z->debugger = std::make_unique<console::Debugger>(z->interactive);
+    return *z->debugger;
+}
+
+// Breakpoints of the step debugger (see console::Debugger): on a rule, or
+// on deduced facts with the given parts, where NULL or "" matches any. The
+// ID for zelph_debug_remove_h is stored in *breakpoint. Return 0 or the
+// error code of zelph_process_h.
+extern "C" int zelph_debug_break_rule_h(zelph_instance* z, uint64_t rule, uint64_t* breakpoint)
+{
+    z->clear_error();
+    try
+    {
+        *breakpoint = debugger_of(z).break_on_rule(rule);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+extern "C" int zelph_debug_break_fact_h(zelph_instance* z, const char* subject, const char* predicate, const char* object, uint64_t* breakpoint)
+{
+    auto term = [](const char* name)
+    {
+        return name && *name ? console::Interactive::Term::constant(name) : console::Interactive::Term::var();
+    };
+
+    z->clear_error();
+    *breakpoint = debugger_of(z).break_on_fact({term(subject), term(predicate), term(object)});
+    return 0;
+}
+
+extern "C" int zelph_debug_remove_h(zelph_instance* z, uint64_t breakpoint)
+{
+    z->clear_error();
+    try
+    {
+        debugger_of(z).remove_breakpoint(breakpoint);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Lets the rules fire once (step) or until a breakpoint (resume). Returns
+// 1 if they stopped at a firing, read with zelph_debug_firing_*, 0 if the
+// run ended without one, or the negated error code of zelph_process_h.
+static int debug_run(zelph_instance* z, const bool step)
+{
+    z->clear_error();
+    z->last_firing.reset();
+    try
+    {
+        console::Debugger& debugger = debugger_of(z);
+        z->last_firing              = step ? debugger.step() : debugger.resume();
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return z->last_firing ? 1 : 0;
+}
+
+extern "C" int zelph_debug_step_h(zelph_instance* z)
+{
+    return debug_run(z, true);
+}
+
+extern "C" int zelph_debug_resume_h(zelph_instance* z)
+{
+    return debug_run(z, false);
+}
+
+extern "C" uint64_t zelph_debug_firing_fact(const zelph_instance* z)
+{
+    return z->last_firing ? z->last_firing->fact : 0;
+}
+
+extern "C" uint64_t zelph_debug_firing_rule(const zelph_instance* z)
+{
+    return z->last_firing ? z->last_firing->rule : 0;
+}
+
+extern "C" const char* zelph_debug_firing_fact_text(const zelph_instance* z)
+{
+    return z->last_firing ? z->last_firing->fact_text.c_str() : "";
+}
+
+// Snapshots at most limit candidate firings (0 for all, see
+// console::Debugger::agenda) and returns their count, read with
+// the zelph_agenda_* accessors, or the negated error code of zelph_process_h.
+extern "C" int zelph_debug_agenda_h(zelph_instance* z, size_t limit)
+{
+    z->clear_error();
+    z->last_agenda.clear();
+    try
+    {
+        z->last_agenda = debugger_of(z).agenda(limit);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_agenda.size());
+}
+
+static const console::Debugger::Candidate* candidate_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_agenda.size()) return nullptr;
+    return &z->last_agenda[i];
+}
+
+extern "C" uint64_t zelph_agenda_rule(const zelph_instance* z, int i)
+{
+    const auto* c = candidate_at(z, i);
+    return c ? c->rule : 0;
+}
+
+extern "C" const char* zelph_agenda_rule_text(const zelph_instance* z, int i)
+{
+    const auto* c = candidate_at(z, i);
+    return c ? c->rule_text.c_str() : "";
+}
+
+// Number of bindings of candidate i; zelph_agenda_binding gives the
+// variable (value = 0) or the value (value = 1) of binding j, or "" for
+// out-of-range indexes.
+extern "C" int zelph_agenda_binding_count(const zelph_instance* z, int i)
+{
+    const auto* c = candidate_at(z, i);
+    return c ? static_cast<int>(c->bindings.size()) : 0;
+}
+
+extern "C" const char* zelph_agenda_binding(const zelph_instance* z, int i, int j, int value)
+{
+    const auto* c = candidate_at(z, i);
+    if (!c || j < 0 || static_cast<size_t>(j) >= c->bindings.size()) return "";
+    return value ? c->bindings[j].second.c_str() : c->bindings[j].first.c_str();
+}
+
+// Built-in predicates (see console::Interactive::register_builtin). The
+// callback reads the arguments of a call with zelph_builtin_arg_*, adds
+// solutions with zelph_builtin_solution and binds their variables with
+// zelph_builtin_bind; zelph_builtin_fail makes the run or query fail with
+// the message. The call handle is only valid during the callback. Pass
+// nullptr to remove a builtin.
+struct zelph_builtin_call
+{
+    const std::vector<console::Interactive::BuiltinArg>* args;
+    std::vector<console::Interactive::BuiltinSolution>   solutions;
+    std::string                                          error;
+};
+
+using zelph_builtin_fn = void (*)(zelph_builtin_call* call, void* user);
+
+extern "C" void zelph_register_builtin_h(zelph_instance* z, const char* name, size_t len, zelph_builtin_fn callback, void* user)
+{
+    if (!callback)
+    {
+        z->interactive.register_builtin(std::string(name, 0, len), nullptr);
+        return;
+    }
+
+    z->interactive.register_builtin(std::string(name, 0, len), [callback, user](const std::vector<console::Interactive::BuiltinArg>& args)
+                                    {
+        zelph_builtin_call call{&args, {}, {}};
+        callback(&call, user);
+        if (!call.error.empty()) throw std::runtime_error(call.error);
+        return call.solutions; });
+}
+
+extern "C" size_t zelph_builtin_arg_count(const zelph_builtin_call* call)
+{
+    return call->args->size();
+}
+
+// The node of argument i, 0 for an unbound variable.
+extern "C" uint64_t zelph_builtin_arg_node(const zelph_builtin_call* call, size_t i)
+{
+    return i < call->args->size() ? (*call->args)[i].node : 0;
+}
+
+extern "C" const char* zelph_builtin_arg_value(const zelph_builtin_call* call, size_t i)
+{
+    return i < call->args->size() ? (*call->args)[i].value.c_str() : "";
+}
+
+// The name of argument i if it is an unbound variable, else "".
+extern "C" const char* zelph_builtin_arg_variable(const zelph_builtin_call* call, size_t i)
+{
+    return i < call->args->size() ? (*call->args)[i].variable.c_str() : "";
+}
+
+extern "C" void zelph_builtin_solution(zelph_builtin_call* call)
+{
+    call->solutions.emplace_back();
+}
+
+// Binds a variable in the last solution (starting one if there is none).
+extern "C" void zelph_builtin_bind(zelph_builtin_call* call, const char* variable, size_t variable_len, const char* value, size_t value_len)
+{
+    if (call->solutions.empty()) call->solutions.emplace_back();
+    call->solutions.back()[std::string(variable, 0, variable_len)] = std::string(value, 0, value_len);
+}
+
+extern "C" void zelph_builtin_fail(zelph_builtin_call* call, const char* message, size_t len)
+{
+    call->error = std::string(message, 0, len);
+    if (call->error.empty()) call->error = "failed";
+}
+#endif
@@ -0,0 +1,396 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+#include "string/string_utils.hpp"
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Defines a rule template (see console::Interactive::define_template);
+// parameters are separated by blanks. Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_define_template_h(zelph_instance* z, const char* name, const char* parameters, const char* rule)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.define_template(name, zelph::string::tokenize_quoted(parameters), rule);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Adds the rule of a template for the given arguments (see
+// console::Interactive::apply_template), e.g. for two relations with a
+// template of two parameters. Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_apply_template_h(zelph_instance* z, const char* name, const char* const* arguments, size_t count)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.apply_template(name, std::vector<std::string>(arguments, arguments + count));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Declares a property of a relation (see
+// console::Interactive::declare_relation); inverse names the other relation
+// of "inverse" and may be null otherwise. Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_declare_relation_h(zelph_instance* z, const char* relation, const char* property, const char* inverse)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.declare_relation(relation, property, inverse ? inverse : "");
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Declares two concepts identical by merging from into into (see
+// console::Interactive::merge_concepts). Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_merge_concepts_h(zelph_instance* z, const char* from, const char* into)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.merge_concepts(from, into);
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Whether node is a kind of category (see console::Interactive::is_kind_of):
+// 1 or 0, or the negated error code of zelph_process_h.
+extern "C" int zelph_is_kind_of_h(zelph_instance* z, const char* node, const char* category)
+{
+    z->clear_error();
+    try
+    {
+        return z->interactive.is_kind_of(node, category) ? 1 : 0;
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// All nodes that are a kind of category (see
+// console::Interactive::instances_of). Takes a snapshot and returns its
+// size, read with zelph_instance_of_name, or the negated error code.
+extern "C" long long zelph_instances_of_h(zelph_instance* z, const char* category)
+{
+    z->clear_error();
+    z->last_instances.clear();
+    try
+    {
+        z->last_instances = z->interactive.instances_of(category);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<long long>(z->last_instances.size());
+}
+
+extern "C" const char* zelph_instance_of_name(const zelph_instance* z, long long i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_instances.size()) return "";
+    return z->last_instances[i].c_str();
+}
+
+// Registers a query as a materialized view (see
+// console::Interactive::create_view). Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_create_view_h(zelph_instance* z, const char* name, const char* statement, size_t len)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.create_view(name, std::string(statement, 0, len));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Reads the answers of a view (see console::Interactive::view) like
+// zelph_query_h: returns their number, or the negated error code, and the
+// bindings are read via zelph_query_binding_count/_variable/_value.
+extern "C" int zelph_view_h(zelph_instance* z, const char* name)
+{
+    z->clear_error();
+    z->last_answers.clear();
+    z->last_probabilities.clear();
+    z->last_variables.clear();
+    z->last_query_notice.clear();
+    try
+    {
+        for (const auto& answer : z->interactive.view(name))
+            z->last_answers.emplace_back(answer.begin(), answer.end());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_answers.size());
+}
+
+// Removes a view; returns 1, or 0 if there is none of that name.
+extern "C" int zelph_remove_view_h(zelph_instance* z, const char* name)
+{
+    return z->interactive.remove_view(name) ? 1 : 0;
+}
+
+// Declares the domain and range of a relation (see
+// console::Interactive::constrain); an empty or null class leaves that side
+// open. Returns 0 or the error code of zelph_process_h.
+extern "C" int zelph_constrain_h(zelph_instance* z, const char* relation, const char* domain_class, const char* range_class)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.constrain(relation, domain_class ? domain_class : "", range_class ? range_class : "");
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Snapshots the schema violations that still hold (see
+// console::Interactive::schema_violations) and returns their count, read
+// with the zelph_schema_violation* accessors until the next call.
+extern "C" int zelph_schema_violations_h(zelph_instance* z)
+{
+    z->clear_error();
+    z->last_schema_violations = z->interactive.schema_violations();
+    return static_cast<int>(z->last_schema_violations.size());
+}
+
+static const console::Interactive::SchemaViolation* schema_violation_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_schema_violations.size()) return nullptr;
+    return &z->last_schema_violations[i];
+}
+
+// Reads a violation: the statement and whether its subject (1) or object
+// (0) is affected. Returns 0 if there is no such violation.
+// zelph_schema_violation_text renders the statement (part 0), the relation
+// (1), the affected node (2) or the class expected (3).
+extern "C" int zelph_schema_violation(const zelph_instance* z, int i, uint64_t* fact, int* subject)
+{
+    const auto* v = schema_violation_at(z, i);
+    if (!v) return 0;
+    *fact    = v->fact;
+    *subject = v->subject ? 1 : 0;
+    return 1;
+}
+
+extern "C" const char* zelph_schema_violation_text(const zelph_instance* z, int i, int part)
+{
+    const auto* v = schema_violation_at(z, i);
+    if (!v) return "";
+    switch (part)
+    {
+    case 0:
+        return v->fact_text.c_str();
+    case 1:
+        return v->relation.c_str();
+    case 2:
+        return v->node.c_str();
+    default:
+        return v->expected.c_str();
+    }
+}
+
+// Declares a maximum number of objects per subject for relation (see
+// console::Interactive::limit_cardinality). Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_limit_cardinality_h(zelph_instance* z, const char* relation, uint64_t max)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.limit_cardinality(relation, static_cast<size_t>(max));
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Snapshots the cardinality violations (see console::Interactive::validate)
+// and returns their count, read with the zelph_violation* accessors until
+// the next call.
+extern "C" int zelph_validate_h(zelph_instance* z)
+{
+    z->clear_error();
+    z->last_violations = z->interactive.validate();
+    return static_cast<int>(z->last_violations.size());
+}
+
+static const console::Interactive::Violation* violation_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_violations.size()) return nullptr;
+    return &z->last_violations[i];
+}
+
+// Reads a violation: the subject, the limit and the number of distinct
+// objects. Returns 0 if there is no such violation.
+extern "C" int zelph_violation(const zelph_instance* z, int i, uint64_t* subject, uint64_t* limit, uint64_t* count)
+{
+    const auto* v = violation_at(z, i);
+    if (!v) return 0;
+    *subject = v->subject;
+    *limit   = v->limit;
+    *count   = v->count;
+    return 1;
+}
+
+extern "C" const char* zelph_violation_relation(const zelph_instance* z, int i)
+{
+    const auto* v = violation_at(z, i);
+    return v ? v->relation.c_str() : "";
+}
+
+extern "C" const char* zelph_violation_subject_text(const zelph_instance* z, int i)
+{
+    const auto* v = violation_at(z, i);
+    return v ? v->subject_text.c_str() : "";
+}
+
+extern "C" int zelph_violation_fact_count(const zelph_instance* z, int i)
+{
+    const auto* v = violation_at(z, i);
+    return v ? static_cast<int>(v->facts.size()) : 0;
+}
+
+extern "C" uint64_t zelph_violation_fact(const zelph_instance* z, int i, int fact)
+{
+    if (fact < 0 || fact >= zelph_violation_fact_count(z, i)) return 0;
+    return z->last_violations[i].facts[fact];
+}
+
+extern "C" const char* zelph_violation_fact_text(const zelph_instance* z, int i, int fact)
+{
+    if (fact < 0 || fact >= zelph_violation_fact_count(z, i)) return "";
+    return z->last_violations[i].fact_texts[fact].c_str();
+}
+
+// Adds a property shape to the node shape named shape, which targets the
+// instances of target_class (see console::Interactive::declare_shape); each
+// call adds one property shape. An empty or null value_class and a negative
+// max_count leave that constraint out. Returns 0 or the error code of
+// zelph_process_h.
+extern "C" int zelph_declare_shape_h(zelph_instance* z, const char* shape, const char* target_class, const char* path, const char* value_class, uint64_t min_count, int64_t max_count)
+{
+    z->clear_error();
+    try
+    {
+        console::Interactive::PropertyShape property{path, value_class ? value_class : "", static_cast<size_t>(min_count), std::nullopt};
+        if (max_count >= 0) property.max_count = static_cast<size_t>(max_count);
+        z->interactive.declare_shape(shape, target_class, {property});
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+// Validates the shapes of the network (see
+// console::Interactive::validate_shapes), snapshots the report and returns
+// the number of results, read with the zelph_shape_result* accessors until
+// the next call. conforms, if not null, is set to 1 if there are none.
+extern "C" int zelph_validate_shapes_h(zelph_instance* z, int* conforms)
+{
+    z->clear_error();
+    z->last_shape_report = z->interactive.validate_shapes();
+    if (conforms) *conforms = z->last_shape_report.conforms ? 1 : 0;
+    return static_cast<int>(z->last_shape_report.results.size());
+}
+
+static const console::Interactive::ShapeResult* shape_result_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_shape_report.results.size()) return nullptr;
+    return &z->last_shape_report.results[i];
+}
+
+// Reads a result: its focus node and, for sh:ClassConstraintComponent, the
+// nonconforming value (else 0). Returns 0 if there is no such result.
+// zelph_shape_result_text renders the focus node (part 0), the path (1),
+// the constraint component (2), the value (3) or the message (4).
+extern "C" int zelph_shape_result(const zelph_instance* z, int i, uint64_t* focus, uint64_t* value)
+{
+    const auto* r = shape_result_at(z, i);
+    if (!r) return 0;
+    *focus = r->focus;
+    *value = r->value;
+    return 1;
+}
+
+extern "C" const char* zelph_shape_result_text(const zelph_instance* z, int i, int part)
+{
+    const auto* r = shape_result_at(z, i);
+    if (!r) return "";
+    switch (part)
+    {
+    case 0:
+        return r->focus_text.c_str();
+    case 1:
+        return r->path.c_str();
+    case 2:
+        return r->component.c_str();
+    case 3:
+        return r->value_text.c_str();
+    default:
+        return r->message.c_str();
+    }
+}
+#endif
@@ -0,0 +1,252 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_c.hpp"
+
+#include <exception>
+#include <iterator>
+#include <utility>
+
+using namespace zelph;
+
+#ifdef PROVIDE_C_INTERFACE
+// Previews a run (see console::Interactive::run_dry): takes the facts it
+// would deduce like zelph_facts_h takes all statements and returns their
+// number, or the negated error code of zelph_process_h.
+extern "C" int zelph_run_dry_h(zelph_instance* z)
+{
+    z->clear_error();
+    z->last_facts.clear();
+    try
+    {
+        z->last_facts = z->interactive.run_dry();
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_facts.size());
+}
+
+// Compares two instances (see console::Interactive::diff). The snapshot
+// read by the zelph_fact_* accessors of a then holds the statements added
+// in b, followed by those removed from a; *added receives the number of
+// added ones. Returns the total number, or the negated error code.
+extern "C" int zelph_diff_h(zelph_instance* a, const zelph_instance* b, int* added)
+{
+    a->clear_error();
+    a->last_facts.clear();
+    try
+    {
+        auto diff = console::Interactive::diff(a->interactive, b->interactive);
+        *added        = static_cast<int>(diff.added.size());
+        a->last_facts = std::move(diff.added);
+        a->last_facts.insert(a->last_facts.end(), std::make_move_iterator(diff.removed.begin()), std::make_move_iterator(diff.removed.end()));
+    }
+    catch (const std::exception& ex)
+    {
+        return -a->record_error(console::ProcessErrorKind::Statement, ex.what(), "");
+    }
+    return static_cast<int>(a->last_facts.size());
+}
+
+// Runs body on z inside a transaction that is rolled back afterwards (see
+// console::Interactive::speculate); body may call any function on z.
+// Returns what body returns, or the negated error code of zelph_process_h
+// if the transaction could not be opened or body threw.
+extern "C" int zelph_speculate_h(zelph_instance* z, int (*body)(zelph_instance* z, void* user), void* user)
+{
+    z->clear_error();
+    int result = 0;
+    try
+    {
+        z->interactive.speculate([&](const console::Interactive&)
+                                 { result = body(z, user); });
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return result;
+}
+
+// Retracts a stated fact or rule by node ID (see .retract). Returns the
+// number of withdrawn deductions, or the negated error code.
+extern "C" int zelph_retract_h(zelph_instance* z, uint64_t fact)
+{
+    z->clear_error();
+    try
+    {
+        return static_cast<int>(z->interactive.retract(fact));
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// Revises a stated fact (see console::Interactive::revise), replacing it
+// by the statement of len bytes if len is not 0. Takes the deductions
+// that stayed withdrawn like zelph_facts_h takes all statements and
+// returns their number, or the negated error code.
+extern "C" int zelph_revise_h(zelph_instance* z, uint64_t fact, const char* replacement, size_t len)
+{
+    z->clear_error();
+    z->last_facts.clear();
+    try
+    {
+        z->last_facts = z->interactive.revise(fact, len ? std::string(replacement, 0, len) : std::string());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_facts.size());
+}
+
+// Transactions (see console::Interactive::begin). zelph_begin_h and
+// zelph_commit_h return 0 or the error code of zelph_process_h;
+// zelph_rollback_h returns the number of removed nodes or the negated
+// error code.
+extern "C" int zelph_begin_h(zelph_instance* z)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.begin();
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+extern "C" int zelph_commit_h(zelph_instance* z)
+{
+    z->clear_error();
+    try
+    {
+        z->interactive.commit();
+    }
+    catch (const console::process_error& ex)
+    {
+        return z->record_error(ex);
+    }
+    return 0;
+}
+
+extern "C" int zelph_rollback_h(zelph_instance* z)
+{
+    z->clear_error();
+    try
+    {
+        return static_cast<int>(z->interactive.rollback());
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+}
+
+// Snapshots the contradictions that still hold (see
+// console::Interactive::conflicts) and returns their count, read with the
+// zelph_conflict_* accessors until the next zelph_conflicts_h call.
+extern "C" int zelph_conflicts_h(zelph_instance* z)
+{
+    z->clear_error();
+    z->last_conflicts = z->interactive.conflicts();
+    return static_cast<int>(z->last_conflicts.size());
+}
+
+static const console::Interactive::Conflict* conflict_at(const zelph_instance* z, int i)
+{
+    if (i < 0 || static_cast<size_t>(i) >= z->last_conflicts.size()) return nullptr;
+    return &z->last_conflicts[i];
+}
+
+extern "C" uint64_t zelph_conflict_rule(const zelph_instance* z, int i)
+{
+    const auto* c = conflict_at(z, i);
+    return c ? c->rule : 0;
+}
+
+extern "C" const char* zelph_conflict_rule_text(const zelph_instance* z, int i)
+{
+    const auto* c = conflict_at(z, i);
+    return c ? c->rule_text.c_str() : "";
+}
+
+extern "C" int zelph_conflict_fact_count(const zelph_instance* z, int i)
+{
+    const auto* c = conflict_at(z, i);
+    return c ? static_cast<int>(c->facts.size()) : 0;
+}
+
+extern "C" uint64_t zelph_conflict_fact(const zelph_instance* z, int i, int fact)
+{
+    if (fact < 0 || fact >= zelph_conflict_fact_count(z, i)) return 0;
+    return z->last_conflicts[i].facts[fact];
+}
+
+extern "C" const char* zelph_conflict_fact_text(const zelph_instance* z, int i, int fact)
+{
+    if (fact < 0 || fact >= zelph_conflict_fact_count(z, i)) return "";
+    return z->last_conflicts[i].fact_texts[fact].c_str();
+}
+
+// Merges other into z (see console::Interactive::merge); policy is 0 for
+// keep-both, 1 for prefer-left and 2 for fail. Stores the counts, leaves
+// the new contradictions in the snapshot of the zelph_conflict_*
+// accessors and the facts retracted under prefer-left in that of the
+// zelph_fact_* accessors. Returns the number of new contradictions, or the
+// negated error code of zelph_process_h.
+extern "C" int zelph_merge_h(zelph_instance* z, const zelph_instance* other, int policy,
+                             uint64_t* facts_added, uint64_t* facts_duplicate, uint64_t* rules_added, uint64_t* rules_duplicate)
+{
+    z->clear_error();
+    z->last_conflicts.clear();
+    z->last_facts.clear();
+    try
+    {
+        auto report = z->interactive.merge(other->interactive, policy == 1   ? console::Interactive::MergePolicy::PreferLeft
+                                                               : policy == 2 ? console::Interactive::MergePolicy::Fail
+                                                                             : console::Interactive::MergePolicy::KeepBoth);
+        *facts_added     = report.facts_added;
+        *facts_duplicate = report.facts_duplicate;
+        *rules_added     = report.rules_added;
+        *rules_duplicate = report.rules_duplicate;
+        z->last_conflicts = std::move(report.conflicts);
+        z->last_facts     = std::move(report.rejected);
+    }
+    catch (const console::process_error& ex)
+    {
+        return -z->record_error(ex);
+    }
+    return static_cast<int>(z->last_conflicts.size());
+}
+#endif
@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+
+#include <exception>
+#include <utility>
+
+using namespace zelph;
+
+double console::Interactive::confidence(const uint64_t fact) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        return _pImpl->_n->confidence(fact);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_confidence(const uint64_t fact, const double confidence) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_confidence(fact, confidence);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_confidence_combination(const uint64_t rule, const Combination combination) const
+{
+    const auto lock = _pImpl->write_lock();
+    using Mode = network::Reasoning::ConfidenceCombination;
+
+    Mode mode = Mode::None;
+    if (combination == Combination::Min)
+        mode = Mode::Min;
+    else if (combination == Combination::Product)
+        mode = Mode::Product;
+
+    try
+    {
+        _pImpl->_n->set_confidence_combination(rule, mode);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_confidence_combiner(const uint64_t rule, ConfidenceCombiner combiner) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_confidence_combination(rule, network::Reasoning::ConfidenceCombination::Custom, std::move(combiner));
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_probabilistic(const bool on) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_probabilistic(on);
+}
+
+void console::Interactive::set_rule_weight(const uint64_t rule, const double weight) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_rule_weight(rule, weight);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
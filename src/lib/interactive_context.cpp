@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/fact_structure.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+
+#include <exception>
+
+using namespace zelph;
+
+console::Interactive::Validity console::Interactive::validity(const uint64_t fact) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        const auto validity = _pImpl->_n->validity(fact);
+        return {validity.from, validity.to};
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_validity(const uint64_t fact, const Validity& validity) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_validity(fact, {validity.from, validity.to});
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::vector<std::string> console::Interactive::contexts(const uint64_t fact) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        const auto contexts = _pImpl->_n->contexts(fact);
+        return {contexts.begin(), contexts.end()};
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::add_to_context(const uint64_t fact, const std::string& context) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->add_to_context(fact, context);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::remove_from_context(const uint64_t fact, const std::string& context) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->remove_from_context(fact, context);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_rule_contexts(const uint64_t rule, const std::vector<std::string>& contexts) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_rule_contexts(rule, {contexts.begin(), contexts.end()});
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_context_scope(const std::vector<std::string>& contexts) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_context_scope({contexts.begin(), contexts.end()});
+}
+
+uint64_t console::Interactive::qualify(const uint64_t fact, const std::string& predicate, const std::string& value) const
+{
+    const auto lock = _pImpl->write_lock();
+    const auto p    = intern(predicate);
+    const auto v    = intern(value);
+    try
+    {
+        network::Reasoning::OriginScope origin(*_pImpl->_n, {"api"});
+        const network::Node qualifier = _pImpl->_n->qualify(fact, p, v);
+        _pImpl->report_new_facts();
+        return qualifier;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::vector<console::Interactive::Qualifier> console::Interactive::qualifiers(const uint64_t fact) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        std::vector<Qualifier> result;
+        for (network::Node qualifier : _pImpl->_n->qualifiers(fact))
+        {
+            const network::FactStructure structure = network::get_preferred_structure(_pImpl->_n.get(), qualifier, 0);
+            result.push_back({qualifier, _pImpl->render(structure.predicate), _pImpl->render(*structure.objects.begin())});
+        }
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "network/reasoning_cancelled.hpp"
+#include "network/reasoning_limit_exceeded.hpp"
+#include "process_error.hpp"
+
+#include <algorithm>
+#include <exception>
+#include <utility>
+
+using namespace zelph;
+
+console::Debugger::Debugger(const Interactive& interactive)
+    : _interactive(interactive)
+{
+}
+
+console::Debugger::~Debugger() = default;
+
+uint64_t console::Debugger::break_on_rule(const uint64_t rule)
+{
+    const auto lock = _interactive._pImpl->read_lock();
+    if (_interactive._pImpl->_n->get_rules().count(rule) == 0)
+    {
+        const std::string message = "Node " + std::to_string(rule) + " is not a rule";
+        throw process_error(message, std::to_string(rule), ProcessErrorKind::Command, message);
+    }
+    _breakpoints.push_back({_next_breakpoint++, rule, std::nullopt});
+    return _breakpoints.back().id;
+}
+
+uint64_t console::Debugger::break_on_fact(const Interactive::Triple& pattern)
+{
+    _breakpoints.push_back({_next_breakpoint++, 0, pattern});
+    return _breakpoints.back().id;
+}
+
+void console::Debugger::remove_breakpoint(const uint64_t breakpoint)
+{
+    const auto it = std::find_if(_breakpoints.begin(), _breakpoints.end(), [breakpoint](const Breakpoint& b)
+                                 { return b.id == breakpoint; });
+    if (it == _breakpoints.end())
+    {
+        const std::string message = "Unknown breakpoint " + std::to_string(breakpoint);
+        throw process_error(message, std::to_string(breakpoint), ProcessErrorKind::Command, message);
+    }
+    _breakpoints.erase(it);
+}
+
+std::optional<console::Interactive::Deduction> console::Debugger::step()
+{
+    return run_until([](uint64_t, uint64_t)
+                     { return true; });
+}
+
+std::optional<console::Interactive::Deduction> console::Debugger::resume()
+{
+    const Interactive::Impl& impl = *_interactive._pImpl;
+    return run_until([this, &impl](const uint64_t fact, const uint64_t rule)
+                     {
+        std::optional<Interactive::Fact> described;
+        for (const Breakpoint& breakpoint : _breakpoints)
+        {
+            if (!breakpoint.pattern)
+            {
+                if (breakpoint.rule == rule) return true;
+                continue;
+            }
+            if (!described) described = impl.describe(fact, true);
+            if (Interactive::Impl::matches(*breakpoint.pattern, *described)) return true;
+        }
+        return false; });
+}
+
+// Runs with stop as the break condition and returns the firing it stopped
+// at. The previous condition (that of .debug) is restored afterwards, so
+// that other runs ignore the breakpoints.
+std::optional<console::Interactive::Deduction> console::Debugger::run_until(const std::function<bool(uint64_t fact, uint64_t rule)>& stop)
+{
+    const auto          lock = _interactive._pImpl->write_lock();
+    Interactive::Impl&  impl = *_interactive._pImpl;
+    network::Reasoning* n    = impl._n.get();
+
+    const network::Reasoning::BreakCondition               previous = n->break_condition();
+    std::optional<std::pair<network::Node, network::Node>> hit;
+    n->set_break_condition([&](network::Node fact, network::Node rule)
+                           {
+        if (hit || !stop(fact, rule)) return false;
+        hit.emplace(fact, rule);
+        return true; });
+
+    try
+    {
+        n->run(false, false, false);
+    }
+    catch (const network::reasoning_cancelled& ex)
+    {
+        n->set_break_condition(previous);
+        throw process_error(std::string("Error in debugger: ") + ex.what(), ".debug", ProcessErrorKind::Cancelled, ex.what());
+    }
+    catch (const network::reasoning_limit_exceeded& ex)
+    {
+        n->set_break_condition(previous);
+        throw process_error(std::string("Error in debugger: ") + ex.what(), ".debug", ProcessErrorKind::ResourceLimit, ex.what());
+    }
+    catch (std::exception& ex)
+    {
+        n->set_break_condition(previous);
+        throw process_error(std::string("Error in debugger: ") + ex.what(), ".debug", ProcessErrorKind::Command, ex.what());
+    }
+    n->set_break_condition(previous);
+
+    if (!hit) return std::nullopt;
+    return Interactive::Deduction{hit->first, hit->second, impl.render(hit->first), impl.render(hit->second)};
+}
+
+std::vector<console::Debugger::Candidate> console::Debugger::agenda(const size_t limit) const
+{
+    const auto               lock = _interactive._pImpl->write_lock();
+    const Interactive::Impl& impl = *_interactive._pImpl;
+    try
+    {
+        std::vector<Candidate> result;
+        for (const auto& item : impl._n->agenda(limit))
+        {
+            Candidate& candidate = result.emplace_back(Candidate{item.rule, impl.render(item.rule), {}});
+            for (const auto& [variable, value] : item.bindings)
+                candidate.bindings.emplace_back(impl.render(variable), impl.render(value));
+            std::sort(candidate.bindings.begin(), candidate.bindings.end());
+        }
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in debugger: ") + ex.what(), ".debug agenda", ProcessErrorKind::Command, ex.what());
+    }
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "process_error.hpp"
+
+#include <exception>
+#include <utility>
+
+using namespace zelph;
+
+void console::Interactive::set_embedding(const uint64_t node, std::vector<float> values) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_embedding(node, std::move(values));
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(node), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+bool console::Interactive::remove_embedding(const uint64_t node) const
+{
+    const auto lock = _pImpl->write_lock();
+    return _pImpl->_n->remove_embedding(node);
+}
+
+std::vector<float> console::Interactive::embedding(const uint64_t node) const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->embedding(node);
+}
+
+std::vector<console::Interactive::SimilarConcept> console::Interactive::similar_concepts(const uint64_t node, const size_t k) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        std::vector<SimilarConcept> result;
+        for (const auto& [id, similarity] : _pImpl->_n->similar_nodes(node, k))
+            result.push_back(SimilarConcept{id, _pImpl->render(id), similarity});
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(node), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::vector<console::Interactive::SimilarConcept> console::Interactive::similar_concepts(const std::vector<float>& values, const size_t k) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        std::vector<SimilarConcept> result;
+        for (const auto& [id, similarity] : _pImpl->_n->similar_nodes(values, k))
+            result.push_back(SimilarConcept{id, _pImpl->render(id), similarity});
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), "", ProcessErrorKind::Command, ex.what());
+    }
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/owl.hpp"
+#include "io/subgraph.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+
+#include <exception>
+#include <memory>
+
+using namespace zelph;
+
+std::vector<console::Interactive::Path> console::Interactive::find_paths(const std::string& from, const std::string& to, const PathOptions& options) const
+{
+    const auto                lock = _pImpl->read_lock();
+    const network::Reasoning* n    = _pImpl->_n.get();
+    try
+    {
+        auto resolve = [n](const std::string& name)
+        {
+            network::Node nd = n->get_node(name, n->lang());
+            if (nd == 0) nd = n->get_core_node(name);
+            if (nd == 0) throw std::runtime_error("Unknown node '" + name + "'");
+            return nd;
+        };
+
+        network::Zelph::PathOptions network_options;
+        network_options.max_paths = options.max_paths;
+        network_options.max_depth = options.max_depth;
+        network_options.directed  = options.directed;
+        for (const std::string& relation : options.relations)
+            network_options.relations.insert(resolve(relation));
+
+        std::vector<Path> result;
+        for (const auto& path : n->find_paths(resolve(from), resolve(to), network_options))
+        {
+            Path& steps = result.emplace_back();
+            for (const auto& step : path)
+                steps.push_back({step.fact, step.from, step.predicate, step.to, step.forward, _pImpl->render(step.from), _pImpl->render(step.predicate), _pImpl->render(step.to)});
+        }
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        const std::string line = ".paths " + from + " " + to;
+        throw process_error(ex.what(), line, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::unique_ptr<console::Interactive> console::Interactive::subgraph(const std::vector<std::string>& roots, const int depth, const RelationFilter& follow) const
+{
+    auto result = std::make_unique<Interactive>(_pImpl->_n->get_output_handler());
+    copy_subgraph(roots, depth, follow, *result);
+    return result;
+}
+
+size_t console::Interactive::copy_subgraph(const std::vector<std::string>& roots, const int depth, const RelationFilter& follow, const Interactive& target) const
+{
+    std::string line = ".subgraph " + std::to_string(depth);
+    for (const std::string& root : roots)
+        line += " " + root;
+
+    if (&target == this)
+        throw process_error("The target of a subgraph must be another instance", line, ProcessErrorKind::Command, "The target of a subgraph must be another instance");
+
+    const auto                lock        = _pImpl->read_lock();
+    const auto                target_lock = target._pImpl->write_lock();
+    const network::Reasoning* n           = _pImpl->_n.get();
+    try
+    {
+        std::vector<network::Node> root_nodes;
+        for (const std::string& root : roots)
+        {
+            network::Node nd = n->get_node(root, n->lang());
+            if (nd == 0) nd = n->get_core_node(root);
+            if (nd == 0) throw std::runtime_error("Unknown node '" + root + "'");
+            root_nodes.push_back(nd);
+        }
+
+        std::function<bool(network::Node)> accepts;
+        if (follow) accepts = [this, &follow](const network::Node predicate)
+        { return follow(_pImpl->render(predicate)); };
+
+        return io::copy_subgraph(n, target._pImpl->_n.get(), root_nodes, depth, accepts);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), line, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+console::Interactive::OwlTranslation console::Interactive::owl_rules() const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        const io::OwlTranslation translation = io::owl_rules(_pImpl->_n.get());
+        return {translation.axioms, translation.rules, translation.skipped};
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".owl-rules", ProcessErrorKind::Command, ex.what());
+    }
+}
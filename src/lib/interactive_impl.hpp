@@ -0,0 +1,291 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "interactive.hpp"
+
+#include "command_executor.hpp"
+#include "io/journal.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+#include "repl_state.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <atomic>
+#include <cassert>
+#include <chrono>
+#include <memory>
+#include <mutex>
+#include <set>
+#include <shared_mutex>
+#include <thread>
+#include <utility>
+
+class zelph::console::Interactive::Impl
+{
+public:
+    explicit Impl(Interactive* enclosing, io::OutputHandler output)
+        : _repl_state(std::make_shared<ReplState>())
+        , _interactive(enclosing)
+    {
+        init(output);
+    }
+
+    void init(io::OutputHandler output);
+
+    std::string render(const network::Node nd) const
+    {
+        std::string value;
+        string::node_to_string(_n.get(), value, _n->lang(), nd, 3);
+        return string::unmark_identifiers(value);
+    }
+
+    std::vector<Interactive::PlanStep> plan(const network::Node condition_or_rule) const
+    {
+        std::vector<Interactive::PlanStep> result;
+        for (const auto& step : _n->query_plan(condition_or_rule))
+        {
+            auto& entry = result.emplace_back(Interactive::PlanStep{render(step.condition), step.relation ? render(step.relation) : "", step.access, step.candidates, step.per_binding, step.negated, {}});
+            for (network::Node var : step.binds)
+                entry.binds.push_back(render(var));
+        }
+        return result;
+    }
+
+    // A deduced fact with its derivation (see Interactive::explain), a
+    // stated one with rule 0 and no premises.
+    Interactive::Proof proof(const network::Node fact) const
+    {
+        std::function<Proof(const network::Reasoning::Proof&)> convert = [&](const network::Reasoning::Proof& p)
+        {
+            Proof result{p.fact, render(p.fact), p.rule, p.rule ? render(p.rule) : "", {}};
+            for (const auto& premise : p.premises)
+                result.premises.push_back(convert(premise));
+            return result;
+        };
+
+        if (!_n->is_deduced(fact)) return Proof{fact, render(fact), 0, "", {}};
+        return convert(_n->explain(fact));
+    }
+
+    // Registers a builtin of register_builtin with the network, translating
+    // between nodes and the arguments and values of the embedder.
+    void install_builtin(const std::string& name, const BuiltinFunction& function);
+
+    // Connects on_deduction, on_event and the journal to the network; called
+    // again whenever one of them changes and after .reset replaced the network.
+    void install_observers();
+
+    // A fact as facts() lists it, from its parts.
+    Fact describe(const network::Reasoning::RetractedFact& parts) const;
+    Fact describe(const network::Node fact, const bool deduced) const;
+
+    static bool matches(const Triple& pattern, const Fact& fact);
+
+    void notify_watches(const std::string& kind, const Fact& fact, const std::optional<Deduction>& deduction = std::nullopt) const;
+
+    // True for the patterns of rules and queries, facts with a variable
+    // somewhere in them.
+    bool contains_var(const network::Node nd, const int depth = 0) const;
+
+    // Reports the facts stated since the last call as fact_added events
+    // and to the watches.
+    // The fact observer also sees the patterns of rules and queries,
+    // predicate declarations and, in classic mode, deductions; these are
+    // left out here, once the facts are complete.
+    void report_new_facts();
+
+    // Deductions made while a line is processed are journaled after it,
+    // others (e.g. of run()) at once. A failing write must not end the run.
+    void journal_deduction(const Deduction& deduction);
+
+    // Called when the outermost process() ends: appends its line unless it
+    // failed (line is null), then the deductions made meanwhile.
+    void write_journal(const std::string* line);
+
+    // Closes the journal and, unless file is empty, replays file (if it
+    // exists) and opens it for appending (see .journal). The journal is
+    // opened even if replaying some lines failed; they are thrown afterwards.
+    void open_journal(const std::string& file);
+
+    // Processes a script like process_file, but continues after failing
+    // lines and returns them (see Interactive::process_script), or a
+    // Datalog program (see Interactive::process_datalog).
+    std::vector<ScriptLineError> process_stream(std::istream& in, const bool datalog = false);
+
+    // The run that follows a statement when auto-run is on; the facts the
+    // statement added are reported before the deductions.
+    void auto_run()
+    {
+        report_new_facts();
+        _n->run(true, false, false, true);
+    }
+
+    // Reports a failed line of process() as a parse_error or error event;
+    // a known column (see process_error::column) is added as a field.
+    void report_error(const ProcessErrorKind kind, const std::string& line, const std::string& reason, const size_t column = 0) const
+    {
+        if (!_event_callback) return;
+        Event event{kind == ProcessErrorKind::Syntax ? "parse_error" : "error",
+                    {{"kind", to_string(kind)}, {"line", line}, {"reason", reason}}};
+        if (column != 0) event.attributes.emplace_back("column", std::to_string(column));
+        _event_callback(event);
+    }
+
+    void reset_reasoning();
+
+    // Member function to delegate to CommandExecutor
+    void process_command(const std::vector<std::string>& cmd);
+
+    // See Interactive::sync_rows; called under the write lock.
+    SyncResult sync(const std::string& name, const io::RowTemplate& row_template, const io::RowSet& rows);
+
+    // Stops the threads of schedule_sync when the instance goes. They take
+    // the write lock for each sync, so they are joined outside of it.
+    void stop_schedules();
+
+    // Holds _mtx for one public method of Interactive: shared for readers,
+    // exclusive for writers (see the class comment of Interactive). A thread
+    // already holding it does not lock again, so methods may call each other
+    // and .import may process its lines through Interactive::process. A
+    // reader must not call a writer: the shared lock cannot be upgraded.
+    class Lock
+    {
+    public:
+        Lock(const Impl* impl, const bool exclusive)
+        {
+            const auto held = std::find_if(_held.begin(), _held.end(), [impl](const auto& h)
+                                           { return h.first == impl; });
+            if (held != _held.end())
+            {
+                assert((held->second || !exclusive) && "a writer was called while the thread holds the instance shared");
+                return;
+            }
+
+            if (exclusive)
+                impl->_mtx.lock();
+            else
+                impl->_mtx.lock_shared();
+
+            _impl      = impl;
+            _exclusive = exclusive;
+            _held.emplace_back(impl, exclusive);
+        }
+
+        ~Lock()
+        {
+            if (!_impl) return;
+
+            _held.erase(std::find(_held.begin(), _held.end(), std::make_pair(_impl, _exclusive)));
+
+            if (_exclusive)
+                _impl->_mtx.unlock();
+            else
+                _impl->_mtx.unlock_shared();
+        }
+
+        Lock(const Lock&)            = delete;
+        Lock& operator=(const Lock&) = delete;
+
+    private:
+        const Impl* _impl{nullptr};
+        bool        _exclusive{false};
+
+        static inline thread_local std::vector<std::pair<const Impl*, bool>> _held; // exclusive?
+    };
+
+    Lock read_lock() const { return {this, false}; }
+    Lock write_lock() const { return {this, true}; }
+
+    // Held by the query methods. They only add pattern nodes to the network,
+    // which it synchronizes itself, so they run next to the readers. What
+    // else a query changes is guarded by _mtx_query, which lets one query
+    // run at a time: the Janet VM with its scoped variables, the collector,
+    // answer handler and limits of _n, and its neural cache. The query cache
+    // has a mutex of its own.
+    struct QueryLock
+    {
+        Lock                                   shared;
+        std::unique_lock<std::recursive_mutex> query;
+    };
+    QueryLock query_lock() const { return {read_lock(), std::unique_lock<std::recursive_mutex>(_mtx_query)}; }
+
+    std::unique_ptr<network::Reasoning> _n;
+    std::unique_ptr<ScriptEngine>       _script_engine;
+    std::unique_ptr<CommandExecutor>    _command_executor;
+    std::shared_ptr<ReplState>          _repl_state;
+
+    // Set by on_deduction and on_event; kept here so that they survive
+    // .reset, which replaces _n.
+    DeductionCallback          _deduction_callback;
+    EventCallback              _event_callback;
+    Translator                 _translator; // see ask
+
+    // See watch; only changed under the write lock, which runs hold too.
+    struct Watch
+    {
+        ChangeCallback        callback;
+        std::optional<Triple> pattern;
+    };
+    std::map<uint64_t, Watch> _watches;
+    uint64_t                  _next_watch{1};
+
+    std::map<std::string, BuiltinFunction> _builtins; // see register_builtin, survive .reset
+    std::vector<network::Node>  _new_facts;             // created outside of runs, guarded by _mtx_new_facts
+    std::vector<network::Node>* _session_facts{nullptr}; // of the line a Session processes, guarded by _mtx_new_facts
+    std::mutex                  _mtx_new_facts;
+
+    // See .journal; survives .reset like the callbacks. _process_depth counts
+    // the nested process() calls, only the outermost line is journaled.
+    std::unique_ptr<io::Journal>                     _journal;
+    std::atomic<int>                                 _process_depth{0};
+    std::vector<std::pair<std::string, std::string>> _journal_deductions; // fact and rule, guarded by _mtx_journal
+    std::mutex                                       _mtx_journal;
+
+    // See sync_rows and schedule_sync; changed under the write lock. A
+    // schedule is stopped under it (request_stop) and joined outside of it.
+    struct Sync
+    {
+        std::set<network::Node>   facts; // owned, see sync_rows
+        SyncResult                last;
+        int64_t                   synced_at{0};
+        std::string               error;
+        std::chrono::milliseconds interval{0};
+        std::jthread              schedule;
+    };
+    std::map<std::string, Sync> _syncs;
+
+    Impl(const Impl&)            = delete;
+    Impl& operator=(const Impl&) = delete;
+
+private:
+    const Interactive*           _interactive;
+    mutable std::shared_mutex    _mtx;
+    mutable std::recursive_mutex _mtx_query; // see query_lock
+};
@@ -0,0 +1,260 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/graph_export.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+
+#ifndef __EMSCRIPTEN__
+    #include "io/cypher.hpp"
+    #include "io/jsonld.hpp"
+    #include "io/rdf.hpp"
+#endif
+
+#include <exception>
+#include <memory>
+
+using namespace zelph;
+
+#ifndef __EMSCRIPTEN__
+void console::Interactive::save(const std::string& file) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->process_command({".save", file});
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), file, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::load(const std::string& file) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->process_command({".load", file});
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), file, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::export_rdf(std::ostream& out, const bool quads, const std::string& base_iri) const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n = _pImpl->_n.get();
+
+    std::function<bool(network::Node)> is_deduced;
+    if (quads) is_deduced = [n](network::Node fact)
+    { return n->is_deduced(fact); };
+
+    io::export_rdf(n, out, base_iri, is_deduced);
+}
+
+void console::Interactive::export_jsonld(std::ostream& out, const std::string& context) const
+{
+    const auto lock = _pImpl->read_lock();
+    io::export_jsonld(_pImpl->_n.get(), out, context);
+}
+
+void console::Interactive::export_cypher(std::ostream& out) const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n = _pImpl->_n.get();
+    io::export_cypher(n, out, [n](network::Node fact)
+                      { return n->is_deduced(fact); });
+}
+
+size_t console::Interactive::import_neo4j(std::istream& in, const std::string& name_property) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        return io::import_neo4j(_pImpl->_n.get(), in, "<input>", name_property);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in Neo4j import: ") + ex.what(), "", ProcessErrorKind::Statement, ex.what());
+    }
+}
+#endif
+
+void console::Interactive::export_dot(std::ostream& out, const std::string& root, const int depth) const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n  = _pImpl->_n.get();
+    const network::Node       nd = n->get_node(root);
+    if (nd == 0) throw process_error("Unknown node '" + root + "'", root, ProcessErrorKind::Command, "Unknown node '" + root + "'");
+
+    io::export_dot(n, out, nd, depth, [n](network::Node fact)
+                   { return n->is_deduced(fact); });
+}
+
+void console::Interactive::export_mermaid(std::ostream& out, const std::string& root, const int depth) const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n  = _pImpl->_n.get();
+    const network::Node       nd = n->get_node(root);
+    if (nd == 0) throw process_error("Unknown node '" + root + "'", root, ProcessErrorKind::Command, "Unknown node '" + root + "'");
+
+    io::export_mermaid(n, out, nd, depth, [n](network::Node fact)
+                       { return n->is_deduced(fact); });
+}
+
+zelph::io::DatalogCounts console::Interactive::export_datalog(std::ostream& out, const io::DatalogOptions& options) const
+{
+    const auto lock = _pImpl->read_lock();
+    return io::export_datalog(_pImpl->_n.get(), out, options);
+}
+
+io::BulkProgress console::Interactive::bulk_load(std::istream& in, const io::BulkOptions& options) const
+{
+    const auto lock = _pImpl->write_lock();
+    auto loader = bulk_loader(options);
+    try
+    {
+        loader->feed(in);
+        return loader->finish();
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in bulk load: ") + ex.what(), "", ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+std::unique_ptr<zelph::io::BulkLoader> console::Interactive::bulk_loader(const io::BulkOptions& options) const
+{
+    return std::make_unique<io::BulkLoader>(_pImpl->_n.get(), options);
+}
+
+zelph::io::CsvResult console::Interactive::import_csv(std::istream& in, const io::CsvMapping& mapping) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        return io::import_csv(_pImpl->_n.get(), in, mapping);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in CSV import: ") + ex.what(), "", ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+zelph::io::BulkProgress console::Interactive::import_conceptnet(std::istream& in, const io::ConceptNetOptions& options) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        return io::import_conceptnet(_pImpl->_n.get(), in, options);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in ConceptNet import: ") + ex.what(), "", ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+zelph::io::BulkProgress console::Interactive::import_wordnet(std::istream& in, const io::WordNetOptions& options) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        return io::import_wordnet(_pImpl->_n.get(), in, options);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in WordNet import: ") + ex.what(), "", ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+uint64_t console::Interactive::create_store(const std::string& file) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        uint64_t skipped = 0;
+        return io::write_fact_store(_pImpl->_n.get(), file, skipped);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".store-create " + file, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::open_store(const std::string& file, const size_t cache_bytes) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_repl_state->fact_store = file.empty() ? nullptr : std::make_shared<io::FactStore>(file, cache_bytes);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".store " + file, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::vector<zelph::io::StoredFact> console::Interactive::store_match(const std::string& subject, const std::string& predicate, const std::string& object, const size_t limit) const
+{
+    const auto                  lock = _pImpl->read_lock();
+    std::vector<io::StoredFact> result;
+    try
+    {
+        if (!_pImpl->_repl_state->fact_store) throw std::runtime_error("No fact store is open");
+        _pImpl->_repl_state->fact_store->match(subject, predicate, object, [&](const io::StoredFact& fact)
+                                               {
+            result.push_back(fact);
+            return limit == 0 || result.size() < limit; });
+    }
+    catch (std::exception& ex)
+    {
+        const std::string line = ".store-query " + subject + " " + predicate + " " + object;
+        throw process_error(ex.what(), line, ProcessErrorKind::Command, ex.what());
+    }
+    return result;
+}
+
+uint64_t console::Interactive::store_import(const std::string& subject, const std::string& predicate, const std::string& object) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        if (!_pImpl->_repl_state->fact_store) throw std::runtime_error("No fact store is open");
+        const uint64_t facts = io::import_from_store(_pImpl->_n.get(), *_pImpl->_repl_state->fact_store, subject, predicate, object);
+        _pImpl->report_new_facts();
+        return facts;
+    }
+    catch (std::exception& ex)
+    {
+        const std::string line = ".store-import " + subject + " " + predicate + " " + object;
+        throw process_error(ex.what(), line, ProcessErrorKind::Command, ex.what());
+    }
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/journal.hpp"
+#include "process_error.hpp"
+
+#include <exception>
+#include <fstream>
+#include <memory>
+#include <mutex>
+#include <utility>
+
+using namespace zelph;
+
+void console::Interactive::Impl::journal_deduction(const Deduction& deduction)
+{
+    std::lock_guard<std::mutex> lock(_mtx_journal);
+    if (_process_depth > 0)
+    {
+        _journal_deductions.emplace_back(deduction.fact_text, deduction.rule_text);
+        return;
+    }
+    try
+    {
+        _journal->deduction(deduction.fact_text, deduction.rule_text);
+    }
+    catch (...)
+    {
+    }
+}
+
+void console::Interactive::Impl::write_journal(const std::string* line)
+{
+    std::vector<std::pair<std::string, std::string>> deductions;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_journal);
+        deductions.swap(_journal_deductions);
+    }
+    if (!_journal) return;
+
+    if (line) _journal->line(*line);
+    for (const auto& [fact, rule] : deductions)
+        _journal->deduction(fact, rule);
+}
+
+void console::Interactive::Impl::open_journal(const std::string& file)
+{
+    _journal.reset();
+    _repl_state->journal_file.clear();
+    install_observers();
+    if (file.empty()) return;
+
+    std::vector<ScriptLineError> errors;
+    if (std::ifstream in(file, std::ios::binary); in)
+        errors = process_stream(in);
+
+    _journal                  = std::make_unique<io::Journal>(file);
+    _repl_state->journal_file = file;
+    install_observers();
+
+    if (!errors.empty()) throw script_error(std::move(errors));
+}
+
+void console::Interactive::set_journal(const std::string& file) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->open_journal(file);
+    }
+    catch (const script_error&)
+    {
+        throw;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".journal " + file, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::string console::Interactive::journal() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_repl_state->journal_file;
+}
@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/facts.hpp"
+#include "io/subgraph.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+#include "script_engine.hpp"
+
+#include <exception>
+#include <set>
+#include <tuple>
+
+using namespace zelph;
+
+console::Interactive::Diff console::Interactive::diff(const Interactive& a, const Interactive& b)
+{
+    using Key = std::tuple<std::string, std::string, std::vector<std::string>>;
+    auto keys = [](const std::vector<Fact>& facts)
+    {
+        std::set<Key> result;
+        for (const Fact& fact : facts)
+            result.emplace(fact.subject, fact.predicate, fact.objects);
+        return result;
+    };
+
+    const std::vector<Fact> facts_a = a.facts();
+    const std::vector<Fact> facts_b = &a == &b ? facts_a : b.facts();
+    const std::set<Key>     keys_a  = keys(facts_a);
+    const std::set<Key>     keys_b  = keys(facts_b);
+
+    Diff result;
+    for (const Fact& fact : facts_b)
+        if (keys_a.count({fact.subject, fact.predicate, fact.objects}) == 0) result.added.push_back(fact);
+    for (const Fact& fact : facts_a)
+        if (keys_b.count({fact.subject, fact.predicate, fact.objects}) == 0) result.removed.push_back(fact);
+    return result;
+}
+
+console::Interactive::MergeReport console::Interactive::merge(const Interactive& other, const MergePolicy policy) const
+{
+    const std::string line = ".merge";
+    if (&other == this)
+        throw process_error("An instance cannot be merged into itself", line, ProcessErrorKind::Command, "An instance cannot be merged into itself");
+
+    const auto                lock       = _pImpl->write_lock();
+    const auto                other_lock = other._pImpl->read_lock();
+    network::Reasoning*       n          = _pImpl->_n.get();
+    const network::Reasoning* source     = other._pImpl->_n.get();
+
+    if (policy == MergePolicy::Fail && n->in_transaction())
+        throw process_error("A merge with policy Fail cannot run inside a transaction", line, ProcessErrorKind::Command, "A merge with policy Fail cannot run inside a transaction");
+
+    auto conflict_keys = [this]()
+    {
+        std::set<std::vector<uint64_t>> keys;
+        for (const Conflict& conflict : conflicts())
+            keys.insert(conflict.facts);
+        return keys;
+    };
+
+    MergeReport report;
+    try
+    {
+        const auto known_conflicts = conflict_keys();
+        if (policy == MergePolicy::Fail) n->begin_transaction();
+
+        std::set<network::Node> merged;
+        io::NodeCopier          copier(source, n);
+        for (const io::ExportedFact& statement : io::exportable_facts(source))
+        {
+            if (source->is_deduced(statement.relation)) continue;
+
+            const network::Node    subject   = copier.copy(statement.subject);
+            const network::Node    predicate = copier.copy(statement.predicate);
+            network::adjacency_set objects;
+            for (const network::Node object : statement.objects)
+                objects.insert(copier.copy(object));
+
+            if (n->check_fact(subject, predicate, objects).is_correct())
+            {
+                ++report.facts_duplicate;
+                continue;
+            }
+            merged.insert(n->fact(subject, predicate, objects));
+            ++report.facts_added;
+        }
+
+        std::set<std::string> known_rules;
+        for (const Rule& rule : rules())
+            known_rules.insert(rule.text);
+        for (const Rule& rule : other.rules())
+        {
+            if (known_rules.count(rule.text) == 1)
+            {
+                ++report.rules_duplicate;
+                continue;
+            }
+            const std::string   code = _pImpl->_script_engine->parse_zelph_to_janet(rule.text);
+            const network::Node id   = code.empty() ? 0 : _pImpl->_script_engine->evaluate_expression(code);
+            if (n->get_rules().count(id) == 0)
+                throw std::runtime_error("Could not merge rule " + rule.text);
+            if (!rule.enabled) n->set_rule_enabled(id, false);
+            if (rule.stratum != 0) n->set_rule_stratum(id, rule.stratum);
+            if (rule.is_default) n->set_rule_default(id, true, rule.priority);
+            known_rules.insert(rule.text);
+            ++report.rules_added;
+        }
+
+        n->run(false, false, false);
+
+        for (const Conflict& conflict : conflicts())
+            if (known_conflicts.count(conflict.facts) == 0) report.conflicts.push_back(conflict);
+
+        if (policy == MergePolicy::Fail)
+        {
+            if (!report.conflicts.empty())
+            {
+                n->rollback_transaction();
+                std::string reason = "Merge rejected: " + std::to_string(report.conflicts.size()) + " contradiction(s), e.g.";
+                for (const std::string& fact : report.conflicts.front().fact_texts)
+                    reason += " " + fact;
+                throw process_error(reason, line, ProcessErrorKind::Statement, reason);
+            }
+            n->commit_transaction();
+        }
+        else if (policy == MergePolicy::PreferLeft)
+        {
+            std::set<network::Node> rejected;
+            for (const Conflict& conflict : report.conflicts)
+                for (const uint64_t fact : conflict.facts)
+                    if (merged.count(fact) == 1) rejected.insert(fact);
+
+            for (const Fact& fact : facts())
+                if (rejected.count(fact.id) == 1) report.rejected.push_back(fact);
+            for (const network::Node fact : rejected)
+            {
+                size_t withdrawn = 0;
+                n->retract(fact, withdrawn);
+            }
+        }
+    }
+    catch (const process_error&)
+    {
+        throw;
+    }
+    catch (std::exception& ex)
+    {
+        if (policy == MergePolicy::Fail && n->in_transaction()) n->rollback_transaction();
+        throw process_error(ex.what(), line, ProcessErrorKind::Statement, ex.what());
+    }
+    return report;
+}
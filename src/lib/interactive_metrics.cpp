@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/graph_stats.hpp"
+#include "io/metrics.hpp"
+#include "network/reasoning.hpp"
+#include "platform/platform_utils.hpp"
+
+using namespace zelph;
+
+void console::Interactive::set_metrics_enabled(const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_metrics_enabled(enabled);
+}
+
+console::Interactive::GraphStats console::Interactive::graph_stats() const
+{
+    const auto           lock  = _pImpl->read_lock();
+    const io::GraphStats stats = io::graph_stats(_pImpl->_n.get());
+
+    GraphStats result;
+    result.nodes       = stats.nodes;
+    result.statements  = stats.statements;
+    result.edges       = stats.edges;
+    result.components  = stats.components;
+    result.in_degrees  = stats.in_degrees;
+    result.out_degrees = stats.out_degrees;
+    for (const auto& [predicate, count] : stats.relations)
+        result.relations.push_back({predicate, _pImpl->render(predicate), count});
+    return result;
+}
+
+console::Interactive::Statistics console::Interactive::statistics() const
+{
+    const auto                lock = _pImpl->read_lock();
+    const network::Reasoning* n    = _pImpl->_n.get();
+
+    Statistics result;
+    result.nodes        = n->count();
+    result.rules        = n->rule_count();
+    result.languages    = n->get_languages();
+    result.memory_bytes = platform::get_process_memory_usage();
+    return result;
+}
+
+std::string console::Interactive::metrics() const
+{
+    const auto lock = _pImpl->read_lock();
+    return io::prometheus_metrics(_pImpl->_n.get());
+}
+
+void console::Interactive::set_rule_profiling(const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_rule_profiling(enabled);
+}
+
+std::vector<console::Interactive::RuleProfile> console::Interactive::rule_profile() const
+{
+    const auto               lock = _pImpl->read_lock();
+    std::vector<RuleProfile> result;
+    for (const auto& p : _pImpl->_n->rule_profile())
+        result.push_back({p.rule, _pImpl->render(p.rule), p.firings, p.deductions, p.seconds});
+    return result;
+}
+
+std::string console::Interactive::rule_profile_report() const
+{
+    const auto lock = _pImpl->read_lock();
+    return io::rule_profile_report(_pImpl->_n.get());
+}
@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+#include "script_engine.hpp"
+#include "string/string_utils.hpp"
+
+#include <exception>
+#include <utility>
+
+using namespace zelph;
+
+uint64_t console::Interactive::alias(const std::string& concept_name, const std::string& lang, const std::string& name) const
+{
+    const auto          lock = _pImpl->write_lock();
+    network::Reasoning* n    = _pImpl->_n.get();
+
+    try
+    {
+        if (concept_name.empty() || lang.empty() || name.empty())
+            throw std::runtime_error("Concept, language and name must not be empty");
+
+        network::Node node = n->get_node(concept_name, n->lang());
+        if (node == 0) node = n->get_core_node(concept_name);
+        if (node == 0) node = n->node(concept_name);
+
+        network::Node owner = n->get_node(name, lang);
+        if (owner == 0) owner = n->get_core_node(name);
+        if (owner != 0 && owner != node)
+            throw std::runtime_error("Name '" + name + "' is already in use by node " + std::to_string(owner) + " in language '" + lang + "'");
+
+        n->set_name(node, name, lang, false);
+        return node;
+    }
+    catch (std::exception& ex)
+    {
+        const std::string line = concept_name + " " + lang + " " + name;
+        throw process_error(ex.what(), line, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+uint64_t console::Interactive::intern(const std::string& name) const
+{
+    const auto          lock = _pImpl->write_lock();
+    network::Reasoning* n    = _pImpl->_n.get();
+
+    if (name.empty())
+        throw process_error("Name must not be empty", name, ProcessErrorKind::Command, "Name must not be empty");
+
+    network::Node node = n->get_node(name, n->lang());
+    if (node == 0) node = n->get_core_node(name);
+    if (node == 0) node = n->node(name, n->lang());
+    return node;
+}
+
+uint64_t console::Interactive::add_fact(const uint64_t subject, const uint64_t predicate, const uint64_t object) const
+{
+    return add_facts({{subject, predicate, object}}).front();
+}
+
+std::vector<uint64_t> console::Interactive::add_facts(const std::vector<FactIds>& facts) const
+{
+    const auto          lock = _pImpl->write_lock();
+    network::Reasoning* n    = _pImpl->_n.get();
+
+    network::Reasoning::OriginScope origin(*n, {"api"});
+
+    std::vector<uint64_t> result;
+    result.reserve(facts.size());
+    for (const FactIds& ids : facts)
+    {
+        const std::string line = std::to_string(ids.subject) + " " + std::to_string(ids.predicate) + " " + std::to_string(ids.object);
+        try
+        {
+            for (network::Node node : {ids.subject, ids.predicate, ids.object})
+            {
+                if (node == 0 || !n->exists(node))
+                    throw std::runtime_error("Unknown node " + std::to_string(node));
+                if (network::Zelph::is_var(node))
+                    throw std::runtime_error("Node " + std::to_string(node) + " is a variable");
+            }
+            result.push_back(n->fact(ids.subject, ids.predicate, {ids.object}));
+        }
+        catch (std::exception& ex)
+        {
+            _pImpl->report_new_facts();
+            throw process_error(std::string("Error in fact \"") + line + "\": " + ex.what(), line, ProcessErrorKind::Statement, ex.what());
+        }
+    }
+    _pImpl->report_new_facts();
+    return result;
+}
+
+void console::Interactive::set_name_normalization(const Normalization form) const
+{
+    const auto lock = _pImpl->write_lock();
+    switch (form)
+    {
+    case Normalization::None:
+        _pImpl->_n->set_name_normalization(string::unicode::Normalization::None);
+        break;
+    case Normalization::NFC:
+        _pImpl->_n->set_name_normalization(string::unicode::Normalization::NFC);
+        break;
+    case Normalization::NFKC:
+        _pImpl->_n->set_name_normalization(string::unicode::Normalization::NFKC);
+        break;
+    }
+}
+
+void console::Interactive::set_case_folding(const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_case_folding(enabled);
+}
+
+void console::Interactive::set_quote_pairs(const std::vector<std::pair<std::string, std::string>>& pairs) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_script_engine->set_quote_pairs(pairs);
+    }
+    catch (std::exception& ex)
+    {
+        std::string line = ".quotes";
+        for (const auto& [open, close] : pairs)
+            line += " " + open + " " + close;
+        throw process_error(ex.what(), line, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::optional<uint64_t> console::Interactive::resolve_name(const std::string& name, const std::string& lang) const
+{
+    const auto                lock = _pImpl->read_lock();
+    const network::Reasoning* n    = _pImpl->_n.get();
+
+    network::Node node = n->get_node(name, lang.empty() ? n->lang() : lang);
+    if (node == 0) node = n->get_core_node(name);
+    if (node == 0) return std::nullopt;
+    return node;
+}
+
+std::vector<console::Interactive::ConceptMatch> console::Interactive::search_concepts(const std::string& query, const size_t limit, const bool fuzzy, const std::string& lang) const
+{
+    const auto lock = _pImpl->read_lock();
+
+    std::vector<ConceptMatch> result;
+    for (auto& match : _pImpl->_n->search_names(query, limit, fuzzy, lang))
+    {
+        std::string kind;
+        switch (match.kind)
+        {
+        case network::NameIndex::MatchKind::Exact:
+            kind = "exact";
+            break;
+        case network::NameIndex::MatchKind::Prefix:
+            kind = "prefix";
+            break;
+        case network::NameIndex::MatchKind::Substring:
+            kind = "substring";
+            break;
+        case network::NameIndex::MatchKind::Fuzzy:
+            kind = "fuzzy";
+            break;
+        }
+        result.push_back(ConceptMatch{match.node, std::move(match.name), kind, match.distance});
+    }
+    return result;
+}
+
+void console::Interactive::set_suggest_concepts(const bool suggest) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_suggest_concepts(suggest);
+}
+
+bool console::Interactive::suggest_concepts() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->suggest_concepts();
+}
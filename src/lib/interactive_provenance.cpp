@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+
+#include <exception>
+
+using namespace zelph;
+
+console::Interactive::Provenance console::Interactive::provenance(const uint64_t fact) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        const network::Reasoning::Provenance provenance = _pImpl->_n->provenance(fact);
+        return {provenance.source, provenance.file, provenance.line, provenance.rule};
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_source_scope(const std::vector<std::string>& sources) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_source_scope({sources.begin(), sources.end()});
+}
+
+void console::Interactive::set_source_trust(const std::string& source, const double trust) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_source_trust(source, trust);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), source, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+double console::Interactive::trust(const uint64_t fact) const
+{
+    const auto lock = _pImpl->read_lock();
+    try
+    {
+        return _pImpl->_n->trust(fact);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_min_trust(const double threshold) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_min_trust(threshold);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(threshold), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_rule_min_trust(const uint64_t rule, const double threshold) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_rule_min_trust(rule, threshold);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
@@ -0,0 +1,646 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/facts.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <charconv>
+#include <chrono>
+#include <cmath>
+#include <cstdlib>
+#include <exception>
+#include <iomanip>
+#include <memory>
+#include <set>
+#include <sstream>
+#include <utility>
+
+using namespace zelph;
+
+std::vector<console::Interactive::QueryBinding> console::Interactive::query(const std::string& statement) const
+{
+    const auto lock = _pImpl->query_lock();
+    return run_query(statement, nullptr);
+}
+
+size_t console::Interactive::query_each(const std::string& statement, const AnswerHandler& handler, const size_t offset, const size_t limit) const
+{
+    const auto       lock = _pImpl->query_lock();
+    ProcessErrorKind kind = ProcessErrorKind::Syntax;
+
+    try
+    {
+        const std::string code = _pImpl->_script_engine->parse_zelph_to_janet(statement);
+        if (code.empty())
+            throw std::runtime_error("Syntax error: Could not parse statement.");
+
+        _pImpl->_n->profiler_reset_epoch();
+        kind = ProcessErrorKind::Statement;
+
+        size_t skipped = 0;
+        size_t handed  = 0;
+        _pImpl->_script_engine->query_each(code, [&](const ScriptEngine::QueryBindings::value_type& answer)
+                                           {
+            if (skipped < offset)
+            {
+                ++skipped;
+                return true;
+            }
+
+            QueryBinding binding;
+            for (const auto& [name, node] : answer)
+            {
+                std::string value;
+                string::node_to_string(_pImpl->_n.get(), value, _pImpl->_n->lang(), node, 3);
+                binding[name] = string::unmark_identifiers(value);
+            }
+            ++handed;
+            return handler(binding) && (limit == 0 || handed < limit); });
+        return handed;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in query \"" + statement + "\": " + ex.what(), statement, kind, ex.what());
+    }
+}
+
+console::Interactive::BoundedResult console::Interactive::query_bounded(const std::string& statement, const QueryOptions& options) const
+{
+    const auto          lock = _pImpl->query_lock();
+    network::Reasoning* n    = _pImpl->_n.get();
+
+    struct BoundsGuard
+    {
+        network::Reasoning*       n;
+        std::chrono::milliseconds timeout{n->query_timeout()};
+        uint64_t                  max_matches{n->max_query_matches()};
+        ~BoundsGuard()
+        {
+            n->set_query_timeout(timeout);
+            n->set_max_query_matches(max_matches);
+        }
+    } guard{n};
+    n->set_query_timeout(options.timeout);
+    n->set_max_query_matches(options.max_matches);
+
+    BoundedResult result;
+    result.answers   = run_query(statement, nullptr);
+    result.truncated = n->query_truncated();
+    if (result.truncated) result.reason = n->query_truncation();
+    return result;
+}
+
+console::Interactive::QueryReport console::Interactive::query_report(const std::string& statement) const
+{
+    const auto    lock    = _pImpl->query_lock();
+    network::Node pattern = 0;
+    QueryReport   report;
+    report.answers = run_query(statement, nullptr, nullptr, &pattern);
+    if (pattern)
+    {
+        report.pattern = _pImpl->render(pattern);
+        if (report.answers.empty()) report.notice = _pImpl->_n->no_match_notice(pattern);
+    }
+    return report;
+}
+
+std::vector<console::Interactive::WeightedBinding> console::Interactive::query_probabilities(const std::string& statement) const
+{
+    const auto lock = _pImpl->query_lock();
+    std::vector<double> probabilities;
+    auto                bindings = run_query(statement, &probabilities);
+
+    std::vector<WeightedBinding> result;
+    result.reserve(bindings.size());
+    for (size_t i = 0; i < bindings.size(); ++i)
+        result.push_back({std::move(bindings[i]), probabilities[i]});
+    return result;
+}
+
+// A rendered value as a number: an exact integer or, failing that, a
+// double. Used by aggregate.
+static bool parse_aggregate_number(const std::string& text, long long& integer, double& real, bool& integral)
+{
+    if (text.empty()) return false;
+
+    const char* const end = text.data() + text.size();
+    const auto [ptr, ec]  = std::from_chars(text.data(), end, integer);
+    integral              = ec == std::errc() && ptr == end;
+    if (integral)
+    {
+        real = static_cast<double>(integer);
+        return true;
+    }
+
+    if (!(std::isdigit(static_cast<unsigned char>(text[0])) || text[0] == '-' || text[0] == '.')) return false;
+    if (text.find_first_of("xXnN") != std::string::npos) return false;
+    char* parsed = nullptr;
+    real         = std::strtod(text.c_str(), &parsed);
+    return parsed == text.c_str() + text.size() && std::isfinite(real);
+}
+
+static std::string format_aggregate_number(const double value)
+{
+    if (std::trunc(value) == value && std::fabs(value) < 1e15) return std::to_string(static_cast<long long>(value));
+
+    std::ostringstream out;
+    out << std::setprecision(15) << value;
+    return out.str();
+}
+
+std::vector<console::Interactive::AggregateRow> console::Interactive::aggregate(const std::string& statement, const std::vector<std::string>& group_by, const std::vector<Aggregate>& aggregates) const
+{
+    const auto lock    = _pImpl->query_lock();
+    const auto answers = run_query(statement, nullptr);
+
+    auto value_of = [&](const QueryBinding& answer, const std::string& variable) -> const std::string&
+    {
+        const auto it = answer.find(variable);
+        if (it == answer.end())
+            throw process_error("Error in line \"" + statement + "\": the answers do not bind " + variable, statement, ProcessErrorKind::Statement, "the answers do not bind " + variable);
+        return it->second;
+    };
+
+    std::map<QueryBinding, std::vector<const QueryBinding*>> groups;
+    if (group_by.empty()) groups[{}];
+    for (const auto& answer : answers)
+    {
+        QueryBinding group;
+        for (const auto& variable : group_by)
+            group[variable] = value_of(answer, variable);
+        groups[group].push_back(&answer);
+    }
+
+    std::vector<AggregateRow> rows;
+    for (const auto& [group, members] : groups)
+    {
+        AggregateRow row{group, {}};
+        for (const auto& aggregate : aggregates)
+        {
+            if (aggregate.function == Aggregate::Function::Count)
+            {
+                if (aggregate.variable.empty())
+                {
+                    row.values.push_back(std::to_string(members.size()));
+                    continue;
+                }
+                std::set<std::string> distinct;
+                for (const QueryBinding* answer : members)
+                    distinct.insert(value_of(*answer, aggregate.variable));
+                row.values.push_back(std::to_string(distinct.size()));
+                continue;
+            }
+
+            bool                                               numeric     = true;
+            bool                                               exact       = true;
+            long long                                          integer_sum = 0;
+            double                                             real_sum    = 0;
+            std::vector<std::pair<const std::string*, double>> values;
+            for (const QueryBinding* answer : members)
+            {
+                const std::string& text = value_of(*answer, aggregate.variable);
+                long long          integer;
+                double             real;
+                bool               integral;
+                if (!parse_aggregate_number(text, integer, real, integral))
+                {
+                    if (aggregate.function == Aggregate::Function::Sum)
+                        throw process_error("Error in line \"" + statement + "\": cannot sum " + text + ", which is not a number", statement, ProcessErrorKind::Statement, "cannot sum " + text + ", which is not a number");
+                    numeric = false;
+                }
+                else
+                {
+                    if (!integral || __builtin_add_overflow(integer_sum, integer, &integer_sum)) exact = false;
+                    real_sum += real;
+                }
+                values.emplace_back(&text, real);
+            }
+
+            if (aggregate.function == Aggregate::Function::Sum)
+            {
+                row.values.push_back(exact ? std::to_string(integer_sum) : format_aggregate_number(real_sum));
+                continue;
+            }
+
+            const bool  maximum = aggregate.function == Aggregate::Function::Max;
+            std::string best_text;
+            double      best_number = 0;
+            bool        first       = true;
+            for (const auto& [text, number] : values)
+            {
+                const bool better = numeric ? (maximum ? number > best_number : number < best_number)
+                                            : (maximum ? *text > best_text : *text < best_text);
+                if (first || better)
+                {
+                    best_text   = *text;
+                    best_number = number;
+                    first       = false;
+                }
+            }
+            row.values.push_back(best_text);
+        }
+        rows.push_back(std::move(row));
+    }
+    return rows;
+}
+
+std::vector<console::Interactive::QueryBinding> console::Interactive::run_query(const std::string& statement, std::vector<double>* probabilities, std::vector<std::vector<uint64_t>>* premises, uint64_t* pattern) const
+{
+    ProcessErrorKind kind = ProcessErrorKind::Syntax;
+
+    try
+    {
+        const std::string code = _pImpl->_script_engine->parse_zelph_to_janet(statement);
+        if (code.empty())
+            throw std::runtime_error("Syntax error: Could not parse statement.");
+
+        _pImpl->_n->profiler_reset_epoch();
+        kind         = ProcessErrorKind::Statement;
+        std::vector<std::vector<network::Node>> matched;
+        network::Node                           parsed  = 0;
+        auto                                    answers = _pImpl->_script_engine->query(statement, code, probabilities, premises ? &matched : nullptr, &parsed);
+        if (pattern) *pattern = parsed;
+        if (premises)
+        {
+            premises->clear();
+            for (const auto& facts : matched)
+                premises->emplace_back(facts.begin(), facts.end());
+        }
+
+        std::vector<QueryBinding> result;
+        result.reserve(answers.size());
+
+        for (const auto& answer : answers)
+        {
+            auto& binding = result.emplace_back();
+            for (const auto& [name, node] : answer)
+            {
+                std::string value;
+                string::node_to_string(_pImpl->_n.get(), value, _pImpl->_n->lang(), node, 3);
+                binding[name] = string::unmark_identifiers(value);
+            }
+        }
+
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in query \"" + statement + "\": " + ex.what(), statement, kind, ex.what());
+    }
+}
+
+std::vector<console::Interactive::QueryBinding> console::Interactive::match(const Triple& pattern) const
+{
+    const auto          lock = _pImpl->write_lock();
+    network::Reasoning* n    = _pImpl->_n.get();
+
+    try
+    {
+        std::map<std::string, network::Node> variables;
+        std::map<network::Node, std::string> var_to_name;
+
+        auto resolve = [&](const Term& term) -> network::Node
+        {
+            if (!term.variable) return n->get_node(term.name, n->lang());
+            if (!term.name.empty())
+            {
+                auto it = variables.find(term.name);
+                if (it != variables.end()) return it->second;
+            }
+
+            const network::Node v = n->var();
+            if (!term.name.empty())
+            {
+                n->set_name(v, term.name, n->lang(), false);
+                variables[term.name] = v;
+                var_to_name[v]       = term.name;
+            }
+            return v;
+        };
+
+        // Constants first, so that an unknown name leaves no variables behind.
+        for (const Term* term : {&pattern.subject, &pattern.predicate, &pattern.object})
+            if (!term->variable && n->get_node(term->name, n->lang()) == 0) return {};
+
+        const network::Node subject   = resolve(pattern.subject);
+        const network::Node predicate = resolve(pattern.predicate);
+        const network::Node object    = resolve(pattern.object);
+
+        if (!pattern.subject.variable && !pattern.predicate.variable && !pattern.object.variable)
+        {
+            if (n->check_fact(subject, predicate, {object}).is_correct()) return {QueryBinding{}};
+            return {};
+        }
+
+        n->profiler_reset_epoch();
+        const network::Node condition = n->fact(subject, predicate, {object});
+
+        std::vector<std::shared_ptr<network::Variables>> results;
+        n->set_query_collector(&results);
+        try
+        {
+            n->apply_rule(0, condition);
+        }
+        catch (...)
+        {
+            n->set_query_collector(nullptr);
+            throw;
+        }
+        n->set_query_collector(nullptr);
+
+        std::vector<QueryBinding> result;
+        result.reserve(results.size());
+        for (const auto& vars : results)
+        {
+            auto& binding = result.emplace_back();
+            for (const auto& [var, value] : *vars)
+            {
+                auto it = var_to_name.find(var);
+                if (it != var_to_name.end()) binding[it->second] = _pImpl->render(value);
+            }
+        }
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        const std::string statement = pattern.subject.name + " " + pattern.predicate.name + " " + pattern.object.name;
+        throw process_error("Error in pattern \"" + statement + "\": " + ex.what(), statement, ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+std::vector<console::Interactive::QueryBinding> console::Interactive::query(const std::string& statement, const double min_confidence) const
+{
+    const auto lock = _pImpl->query_lock();
+    network::Reasoning* n        = _pImpl->_n.get();
+    const double        previous = n->min_confidence();
+
+    try
+    {
+        n->set_min_confidence(min_confidence);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), statement, ProcessErrorKind::Statement, ex.what());
+    }
+
+    try
+    {
+        auto result = query(statement);
+        n->set_min_confidence(previous);
+        return result;
+    }
+    catch (...)
+    {
+        n->set_min_confidence(previous);
+        throw;
+    }
+}
+
+std::vector<console::Interactive::QueryBinding> console::Interactive::query_as_of(const std::string& statement, const int64_t time) const
+{
+    const auto lock = _pImpl->write_lock();
+    network::Reasoning*          n        = _pImpl->_n.get();
+    const std::optional<int64_t> previous = n->as_of();
+
+    n->set_as_of(time);
+    try
+    {
+        auto result = query(statement);
+        n->set_as_of(previous);
+        return result;
+    }
+    catch (...)
+    {
+        n->set_as_of(previous);
+        throw;
+    }
+}
+
+std::vector<console::Interactive::QueryBinding> console::Interactive::query_in(const std::string& statement, const std::vector<std::string>& contexts) const
+{
+    const auto lock = _pImpl->write_lock();
+    network::Reasoning*                   n        = _pImpl->_n.get();
+    const network::Reasoning::ContextSet previous = n->context_scope();
+
+    n->set_context_scope({contexts.begin(), contexts.end()});
+    try
+    {
+        auto result = query(statement);
+        n->set_context_scope(previous);
+        return result;
+    }
+    catch (...)
+    {
+        n->set_context_scope(previous);
+        throw;
+    }
+}
+
+std::vector<console::Interactive::Fact> console::Interactive::facts() const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n = _pImpl->_n.get();
+
+    auto render = [n](network::Node nd)
+    {
+        std::string value;
+        string::node_to_string(n, value, n->lang(), nd, 3);
+        return string::unmark_identifiers(value);
+    };
+
+    auto statements = io::exportable_facts(n);
+    std::sort(statements.begin(), statements.end(), [](const io::ExportedFact& a, const io::ExportedFact& b)
+              { return a.relation < b.relation; });
+
+    std::vector<Fact> result;
+    result.reserve(statements.size());
+    for (const auto& statement : statements)
+    {
+        auto& fact     = result.emplace_back();
+        fact.id        = statement.relation;
+        fact.subject   = render(statement.subject);
+        fact.predicate = render(statement.predicate);
+        fact.deduced   = n->is_deduced(statement.relation);
+        for (network::Node object : statement.objects)
+            fact.objects.push_back(render(object));
+        std::sort(fact.objects.begin(), fact.objects.end());
+    }
+    return result;
+}
+
+void console::Interactive::facts_each(const FactPageHandler& handler, const size_t page_size) const
+{
+    std::vector<network::Node> ids;
+    {
+        const auto lock = _pImpl->read_lock();
+        for (const auto& statement : io::exportable_facts(_pImpl->_n.get()))
+            ids.push_back(statement.relation);
+    }
+    std::sort(ids.begin(), ids.end());
+
+    const size_t step = std::max<size_t>(page_size, 1);
+    for (size_t begin = 0; begin < ids.size(); begin += step)
+    {
+        std::vector<Fact> page;
+        {
+            const auto                lock = _pImpl->read_lock();
+            const network::Reasoning* n    = _pImpl->_n.get();
+            for (size_t i = begin; i < std::min(ids.size(), begin + step); ++i)
+                if (n->exists(ids[i])) page.push_back(_pImpl->describe(ids[i], n->is_deduced(ids[i])));
+        }
+        handler(page);
+    }
+}
+
+std::vector<console::Interactive::Fact> console::Interactive::facts(const Triple& pattern) const
+{
+    auto result = facts();
+    result.erase(std::remove_if(result.begin(), result.end(), [&pattern](const Fact& fact)
+                                { return !Impl::matches(pattern, fact); }),
+                 result.end());
+    return result;
+}
+
+console::Interactive::SparqlResult console::Interactive::sparql(const std::string& query) const
+{
+    const auto lock = _pImpl->write_lock();
+    ProcessErrorKind kind = ProcessErrorKind::Command;
+
+    try
+    {
+        if (!_pImpl->_script_engine->has_keyword("sparql"))
+            _pImpl->process_command({".import", "sparql"});
+
+        _pImpl->_n->profiler_reset_epoch();
+        kind      = ProcessErrorKind::Script;
+        auto rows = _pImpl->_script_engine->call_rows("sparql-rows", query);
+
+        SparqlResult result;
+        if (rows.empty()) return result;
+
+        for (const auto& name : rows.front())
+            result.variables.push_back(name.value_or(""));
+
+        result.rows.reserve(rows.size() - 1);
+        for (size_t i = 1; i < rows.size(); ++i)
+        {
+            auto& binding = result.rows.emplace_back();
+            for (size_t j = 0; j < rows[i].size() && j < result.variables.size(); ++j)
+                if (rows[i][j]) binding[result.variables[j]] = *rows[i][j];
+        }
+
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in SPARQL query: ") + ex.what(), query, kind, ex.what());
+    }
+}
+
+void console::Interactive::set_translator(Translator translator) const
+{
+    const auto lock     = _pImpl->write_lock();
+    _pImpl->_translator = std::move(translator);
+}
+
+console::Interactive::QuestionAnswer console::Interactive::ask(const std::string& question) const
+{
+    Translator translator;
+    {
+        const auto lock = _pImpl->read_lock();
+        translator      = _pImpl->_translator;
+    }
+    if (!translator)
+        throw process_error("No translator is set", question, ProcessErrorKind::Command, "No translator is set");
+
+    std::vector<std::string> statements;
+    try
+    {
+        statements = translator(question);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in translating \"" + question + "\": " + ex.what(), question, ProcessErrorKind::Command, ex.what());
+    }
+
+    const auto     lock = _pImpl->query_lock();
+    QuestionAnswer result{question, {}};
+    for (const auto& statement : statements)
+    {
+        auto& query = result.queries.emplace_back(AnsweredQuery{statement, "", {}, {}});
+        try
+        {
+            std::vector<std::vector<uint64_t>> premises;
+            query.answers = run_query(statement, nullptr, &premises);
+            for (const auto& facts : premises)
+            {
+                auto& proofs = query.proofs.emplace_back();
+                for (uint64_t fact : facts)
+                    proofs.push_back(_pImpl->proof(fact));
+            }
+        }
+        catch (const process_error& ex)
+        {
+            query.error = ex.reason();
+        }
+    }
+    return result;
+}
+
+std::vector<console::Interactive::PlanStep> console::Interactive::explain_query(const std::string& statement) const
+{
+    const auto       lock = _pImpl->query_lock();
+    ProcessErrorKind kind = ProcessErrorKind::Syntax;
+
+    try
+    {
+        const std::string code = _pImpl->_script_engine->parse_zelph_to_janet(statement);
+        if (code.empty())
+            throw std::runtime_error("Syntax error: Could not parse statement.");
+
+        kind = ProcessErrorKind::Statement;
+        if (!ScriptEngine::has_variables(code))
+            throw std::runtime_error("A query needs a variable (e.g. X ~ human); without one the statement would be stated as a fact");
+
+        const network::Node condition = _pImpl->_script_engine->evaluate_expression(code);
+        if (condition == 0)
+            throw std::runtime_error("Invalid pattern");
+
+        return _pImpl->plan(condition);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in query \"" + statement + "\": " + ex.what(), statement, kind, ex.what());
+    }
+}
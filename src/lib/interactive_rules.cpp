@@ -0,0 +1,228 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <exception>
+
+using namespace zelph;
+
+void console::Interactive::Impl::install_builtin(const std::string& name, const BuiltinFunction& function)
+{
+    const network::Node relation = _n->node(name);
+    if (!function)
+    {
+        _n->register_builtin(relation, nullptr);
+        return;
+    }
+
+    _n->register_builtin(relation, [this, function](const std::vector<network::Node>& args)
+                         {
+        std::vector<BuiltinArg>              terms;
+        std::map<std::string, network::Node> variables;
+        for (network::Node arg : args)
+        {
+            if (network::Zelph::is_var(arg))
+            {
+                const std::string variable = render(arg);
+                variables[variable]        = arg;
+                terms.push_back(BuiltinArg{0, "", variable});
+            }
+            else
+            {
+                terms.push_back(BuiltinArg{arg, render(arg), ""});
+            }
+        }
+
+        std::vector<network::Variables> solutions;
+        for (const auto& solution : function(terms))
+        {
+            auto& bindings = solutions.emplace_back();
+            for (const auto& [variable, value] : solution)
+            {
+                auto it = variables.find(variable);
+                if (it == variables.end())
+                    throw std::runtime_error("'" + variable + "' is not an unbound variable of the condition");
+                if (value.empty())
+                    throw std::runtime_error("Empty value for '" + variable + "'");
+                bindings[it->second] = _n->node(value);
+            }
+        }
+        return solutions; });
+}
+
+console::Interactive::Proof console::Interactive::explain(const uint64_t fact) const
+{
+    const auto lock = _pImpl->read_lock();
+    if (!_pImpl->_n->is_deduced(fact))
+    {
+        const std::string message = "Fact " + std::to_string(fact) + " was not deduced";
+        throw process_error(message, std::to_string(fact), ProcessErrorKind::Command, message);
+    }
+
+    return _pImpl->proof(fact);
+}
+
+void console::Interactive::register_builtin(const std::string& name, BuiltinFunction function) const
+{
+    const auto lock = _pImpl->write_lock();
+    if (function)
+        _pImpl->_builtins[name] = function;
+    else
+        _pImpl->_builtins.erase(name);
+    _pImpl->install_builtin(name, function);
+}
+
+std::vector<console::Interactive::Rule> console::Interactive::rules() const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n = _pImpl->_n.get();
+
+    network::adjacency_set ids = n->get_rules();
+    std::vector<Rule>      result;
+    result.reserve(ids.size());
+    for (network::Node id : ids)
+    {
+        auto& rule   = result.emplace_back();
+        rule.id      = id;
+        rule.enabled = n->is_rule_enabled(id);
+        rule.stratum    = n->rule_stratum(id);
+        rule.is_default = n->is_default_rule(id);
+        rule.priority   = n->default_priority(id);
+        string::node_to_string(n, rule.text, n->lang(), id, 3);
+        rule.text = string::unmark_identifiers(rule.text);
+    }
+    std::sort(result.begin(), result.end(), [](const Rule& a, const Rule& b)
+              { return a.id < b.id; });
+    return result;
+}
+
+uint64_t console::Interactive::add_rule(const std::string& condition, const std::string& consequence) const
+{
+    const auto lock = _pImpl->write_lock();
+    const std::string statement = condition + " => " + consequence;
+    ProcessErrorKind  kind      = ProcessErrorKind::Syntax;
+
+    try
+    {
+        const std::string code = _pImpl->_script_engine->parse_zelph_to_janet(statement);
+        if (code.empty())
+            throw std::runtime_error("Syntax error: Could not parse statement.");
+
+        kind                     = ProcessErrorKind::Statement;
+        const network::Node rule = _pImpl->_script_engine->evaluate_expression(code);
+        if (_pImpl->_n->get_rules().count(rule) == 0)
+            throw std::runtime_error("Statement is not a rule");
+        return rule;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in rule \"" + statement + "\": " + ex.what(), statement, kind, ex.what());
+    }
+}
+
+void console::Interactive::set_rule_enabled(const uint64_t rule, const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_rule_enabled(rule, enabled);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_rule_stratum(const uint64_t rule, const int stratum) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_rule_stratum(rule, stratum);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::set_rule_default(const uint64_t rule, const bool is_default, const int priority) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->set_rule_default(rule, is_default, priority);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::vector<console::Interactive::Defeat> console::Interactive::defeats() const
+{
+    const auto          lock = _pImpl->read_lock();
+    std::vector<Defeat> result;
+    for (const auto& defeat : _pImpl->_n->defeats())
+        result.push_back({_pImpl->describe(defeat.assumption), defeat.rule, defeat.by});
+    return result;
+}
+
+std::vector<console::Interactive::PlanStep> console::Interactive::explain_rule(const uint64_t rule) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        if (_pImpl->_n->get_rules().count(rule) == 0)
+            throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+        return _pImpl->plan(rule);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::remove_rule(const uint64_t rule) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->remove_rule(rule);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), std::to_string(rule), ProcessErrorKind::Command, ex.what());
+    }
+}
@@ -0,0 +1,279 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/cardinality.hpp"
+#include "io/schema.hpp"
+#include "io/shacl.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <exception>
+#include <set>
+#include <utility>
+
+using namespace zelph;
+
+void console::Interactive::define_template(const std::string& name, const std::vector<std::string>& parameters, const std::string& rule) const
+{
+    const auto lock = _pImpl->write_lock();
+
+    std::vector<std::string> cmd{".template", name};
+    cmd.insert(cmd.end(), parameters.begin(), parameters.end());
+    cmd.push_back(":");
+    for (auto& token : zelph::string::tokenize_quoted(rule))
+        cmd.push_back(std::move(token));
+    try
+    {
+        _pImpl->process_command(cmd);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), rule, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::apply_template(const std::string& name, const std::vector<std::string>& arguments) const
+{
+    const auto lock = _pImpl->write_lock();
+
+    std::vector<std::string> cmd{".apply", name};
+    cmd.insert(cmd.end(), arguments.begin(), arguments.end());
+    try
+    {
+        _pImpl->process_command(cmd);
+    }
+    catch (const process_error&)
+    {
+        throw;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), name, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::declare_relation(const std::string& relation, const std::string& property, const std::string& inverse) const
+{
+    const auto lock = _pImpl->write_lock();
+
+    std::vector<std::string> cmd{".relation", relation, property};
+    if (!inverse.empty()) cmd.push_back(inverse);
+    try
+    {
+        _pImpl->process_command(cmd);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), relation, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+size_t console::Interactive::merge_concepts(const std::string& from, const std::string& into) const
+{
+    const auto lock = _pImpl->write_lock();
+
+    network::Node concepts[2];
+    for (size_t i = 0; i < 2; ++i)
+    {
+        const std::string& name = i == 0 ? from : into;
+        concepts[i]             = _pImpl->_n->get_node(name, _pImpl->_n->lang());
+        if (!concepts[i])
+            throw process_error("Unknown node '" + name + "'", name, ProcessErrorKind::Command, "Unknown node '" + name + "'");
+    }
+    try
+    {
+        return _pImpl->_n->merge_concepts(concepts[0], concepts[1]).size();
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), from, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+bool console::Interactive::is_kind_of(const std::string& node, const std::string& category) const
+{
+    const auto lock = _pImpl->read_lock();
+
+    network::Node nodes[2];
+    for (size_t i = 0; i < 2; ++i)
+    {
+        const std::string& name = i == 0 ? node : category;
+        nodes[i]                = _pImpl->_n->get_node(name, _pImpl->_n->lang());
+        if (!nodes[i])
+            throw process_error("Unknown node '" + name + "'", ".kind-of " + node + " " + category, ProcessErrorKind::Command, "Unknown node '" + name + "'");
+    }
+    return _pImpl->_n->is_kind_of(nodes[0], nodes[1]);
+}
+
+std::vector<std::string> console::Interactive::instances_of(const std::string& category) const
+{
+    const auto          lock = _pImpl->read_lock();
+    const network::Node cat  = _pImpl->_n->get_node(category, _pImpl->_n->lang());
+    if (!cat)
+        throw process_error("Unknown node '" + category + "'", ".instances " + category, ProcessErrorKind::Command, "Unknown node '" + category + "'");
+
+    std::vector<std::string> result;
+    for (const network::Node nd : _pImpl->_n->instances_of(cat))
+        result.push_back(_pImpl->render(nd));
+    std::sort(result.begin(), result.end());
+    return result;
+}
+
+void console::Interactive::constrain(const std::string& relation, const std::string& domain_class, const std::string& range_class) const
+{
+    const auto lock = _pImpl->write_lock();
+    const auto line = ".constrain " + relation + " " + domain_class + " " + range_class;
+    try
+    {
+        // A schema rule that exists already is kept and the new one removed.
+        std::set<std::string> known_rules;
+        for (const Rule& rule : rules())
+            known_rules.insert(rule.text);
+        for (const auto& [condition, consequence] : io::schema_rules)
+        {
+            const uint64_t id   = add_rule(condition, consequence);
+            const auto     text = _pImpl->render(id);
+            if (!known_rules.insert(text).second) remove_rule(id);
+        }
+
+        network::Reasoning* n = _pImpl->_n.get();
+        const network::Node R = n->node(relation, n->lang());
+        if (!domain_class.empty()) n->fact(R, n->node("domain", n->lang()), {n->node(domain_class, n->lang())});
+        if (!range_class.empty()) n->fact(R, n->node("range", n->lang()), {n->node(range_class, n->lang())});
+    }
+    catch (const process_error&)
+    {
+        throw;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), line, ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+std::vector<console::Interactive::SchemaViolation> console::Interactive::schema_violations() const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n = _pImpl->_n.get();
+
+    std::vector<SchemaViolation> result;
+    for (const auto& c : n->conflicts())
+    {
+        const auto violation = io::schema_violation(n, c.facts);
+        if (!violation) continue;
+        result.push_back({violation->fact,
+                          _pImpl->render(violation->fact),
+                          _pImpl->render(violation->relation),
+                          violation->subject,
+                          _pImpl->render(violation->node),
+                          _pImpl->render(violation->expected)});
+    }
+    return result;
+}
+
+void console::Interactive::limit_cardinality(const std::string& relation, const size_t max) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        network::Reasoning* n = _pImpl->_n.get();
+        n->fact(n->node(relation, n->lang()), n->node(io::max_cardinality_name, n->lang()), {n->node(std::to_string(max), n->lang())});
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), relation + " " + io::max_cardinality_name + " " + std::to_string(max), ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+std::vector<console::Interactive::Violation> console::Interactive::validate() const
+{
+    const auto lock = _pImpl->read_lock();
+
+    std::vector<Violation> result;
+    for (const auto& v : io::cardinality_violations(_pImpl->_n.get()))
+    {
+        Violation violation{_pImpl->render(v.relation), v.subject, _pImpl->render(v.subject), v.limit, v.count, {}, {}};
+        for (network::Node fact : v.facts)
+        {
+            violation.facts.push_back(fact);
+            violation.fact_texts.push_back(_pImpl->render(fact));
+        }
+        result.push_back(std::move(violation));
+    }
+    return result;
+}
+
+void console::Interactive::declare_shape(const std::string& shape, const std::string& target_class, const std::vector<PropertyShape>& properties) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        network::Reasoning* n    = _pImpl->_n.get();
+        const std::string   lang = n->lang();
+        auto                node = [&](const std::string& name)
+        { return n->node(name, lang); };
+
+        const network::Node S = node(shape);
+        n->fact(S, n->core.IsA, {node("sh:NodeShape")});
+        n->fact(S, node("sh:targetClass"), {node(target_class)});
+        for (const auto& property : properties)
+        {
+            const network::Node P = n->create_node();
+            n->fact(S, node("sh:property"), {P});
+            n->fact(P, node("sh:path"), {node(property.path)});
+            if (!property.value_class.empty()) n->fact(P, node("sh:class"), {node(property.value_class)});
+            if (property.min_count > 0) n->fact(P, node("sh:minCount"), {node(std::to_string(property.min_count))});
+            if (property.max_count) n->fact(P, node("sh:maxCount"), {node(std::to_string(*property.max_count))});
+        }
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), shape + " sh:targetClass " + target_class, ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+console::Interactive::ShapeReport console::Interactive::validate_shapes() const
+{
+    const auto lock = _pImpl->read_lock();
+    const auto report = io::validate_shapes(_pImpl->_n.get());
+
+    ShapeReport result{report.results.empty(), report.shapes, report.focus, report.skipped, {}};
+    for (const auto& r : report.results)
+    {
+        result.results.push_back({r.focus,
+                                  _pImpl->render(r.focus),
+                                  _pImpl->render(r.path),
+                                  io::component_name(r.component),
+                                  r.value,
+                                  r.value ? _pImpl->render(r.value) : std::string(),
+                                  io::result_message(_pImpl->_n.get(), r)});
+    }
+    return result;
+}
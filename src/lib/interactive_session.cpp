@@ -0,0 +1,226 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+
+#include <exception>
+#include <memory>
+#include <mutex>
+#include <set>
+#include <utility>
+
+using namespace zelph;
+
+// The settings a session keeps for itself (see Session).
+struct console::Session::Settings
+{
+    std::string                    lang;
+    std::string                    active_context;
+    int                            active_stratum{0};
+    network::Reasoning::ContextSet context_scope;
+    std::set<std::string>          source_scope;
+    double                         min_confidence{0};
+    double                         min_trust{0};
+    std::optional<int64_t>         as_of;
+    network::Reasoning::Provenance origin;
+
+    void read(const network::Reasoning& n)
+    {
+        lang           = n.lang();
+        active_context = n.active_context();
+        active_stratum = n.active_stratum();
+        context_scope  = n.context_scope();
+        source_scope   = n.source_scope();
+        min_confidence = n.min_confidence();
+        min_trust      = n.min_trust();
+        as_of          = n.as_of();
+        origin         = n.origin();
+    }
+
+    void apply(network::Reasoning& n) const
+    {
+        n.set_lang(lang);
+        n.set_active_context(active_context);
+        n.set_active_stratum(active_stratum);
+        n.set_context_scope(context_scope);
+        n.set_source_scope(source_scope);
+        n.set_min_confidence(min_confidence);
+        n.set_min_trust(min_trust);
+        n.set_as_of(as_of);
+        n.set_origin(origin);
+    }
+};
+
+// Holds the Interactive while a session uses it, with the session's
+// settings in place of the previous ones. Changes made meanwhile (e.g. by
+// .context) are kept for the session. The network is looked up again at
+// the end, as .new replaces it.
+class console::Session::Scope
+{
+public:
+    explicit Scope(Session& session)
+        : _session(session)
+        , _impl(*session._interactive._pImpl)
+        , _lock(_impl.write_lock())
+    {
+        _previous.read(*_impl._n);
+        _session._settings->apply(*_impl._n);
+    }
+
+    ~Scope()
+    {
+        _session._settings->read(*_impl._n);
+        _previous.apply(*_impl._n);
+    }
+
+    Interactive::Impl& impl() const { return _impl; }
+
+    Scope(const Scope&)            = delete;
+    Scope& operator=(const Scope&) = delete;
+
+private:
+    Session&                      _session;
+    Interactive::Impl&            _impl;
+    const Interactive::Impl::Lock _lock;
+    Settings                      _previous;
+};
+
+console::Session::Session(const Interactive& interactive, std::string name)
+    : _interactive(interactive)
+    , _name(std::move(name))
+    , _settings(std::make_unique<Settings>())
+{
+    if (_name.empty())
+        throw process_error("Session name must not be empty", _name, ProcessErrorKind::Command, "Session name must not be empty");
+
+    const auto lock = _interactive._pImpl->read_lock();
+    _settings->read(*_interactive._pImpl->_n);
+    _settings->origin = {"session:" + _name};
+}
+
+console::Session::~Session() = default;
+
+void console::Session::process(const std::string& line)
+{
+    Scope                      scope(*this);
+    Interactive::Impl&         impl = scope.impl();
+    std::vector<network::Node> facts;
+
+    impl._n->set_origin({"session:" + _name, "", ++_lines});
+    {
+        std::lock_guard<std::mutex> lock(impl._mtx_new_facts);
+        impl._session_facts = &facts;
+    }
+    impl.install_observers();
+
+    // Stops recording also if the line fails; what it added until then is
+    // undone with it.
+    struct RecordingGuard
+    {
+        Interactive::Impl& impl;
+        ~RecordingGuard()
+        {
+            {
+                std::lock_guard<std::mutex> lock(impl._mtx_new_facts);
+                impl._session_facts = nullptr;
+            }
+            impl.install_observers();
+        }
+    };
+
+    {
+        RecordingGuard recording{impl};
+        try
+        {
+            _interactive.process(line);
+        }
+        catch (...)
+        {
+            keep_statements(impl, facts);
+            throw;
+        }
+    }
+    keep_statements(impl, facts);
+}
+
+std::vector<console::Interactive::QueryBinding> console::Session::query(const std::string& statement)
+{
+    Scope scope(*this);
+    return _interactive.query(statement);
+}
+
+size_t console::Session::undo()
+{
+    Scope scope(*this);
+    if (_undo.empty()) return 0;
+
+    std::vector<uint64_t> facts = std::move(_undo.back());
+    _undo.pop_back();
+
+    network::Reasoning* n       = scope.impl()._n.get();
+    size_t              removed = 0;
+    for (auto it = facts.rbegin(); it != facts.rend(); ++it)
+    {
+        if (!n->exists(*it) || n->is_deduced(*it)) continue;
+        try
+        {
+            size_t withdrawn = 0;
+            n->retract(*it, withdrawn);
+            removed += 1 + withdrawn;
+        }
+        catch (std::exception& ex)
+        {
+            throw process_error(std::string("Error in undo: ") + ex.what(), std::to_string(*it), ProcessErrorKind::Command, ex.what());
+        }
+    }
+    return removed;
+}
+
+// Pushes the facts and rules a line stated onto the undo stack, leaving out
+// deductions, the patterns of rules and queries and predicate declarations.
+void console::Session::keep_statements(Interactive::Impl& impl, const std::vector<network::Node>& facts)
+{
+    const network::Reasoning* n = impl._n.get();
+
+    std::vector<uint64_t> statements;
+    for (network::Node fact : facts)
+    {
+        if (!n->exists(fact) || n->is_deduced(fact)) continue;
+
+        network::adjacency_set objects;
+        const network::Node    subject   = n->parse_fact(fact, objects);
+        const network::Node    predicate = n->parse_relation(fact);
+        if (subject == 0) continue;
+        if (predicate == n->core.IsA && objects.count(n->core.RelationTypeCategory) == 1) continue;
+        if (predicate != n->core.Causes && impl.contains_var(fact)) continue;
+
+        statements.push_back(fact);
+    }
+    if (!statements.empty()) _undo.push_back(std::move(statements));
+}
@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+
+using namespace zelph;
+
+void console::Interactive::set_report_no_matches(const bool report) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_report_no_matches(report);
+}
+
+bool console::Interactive::report_no_matches() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->report_no_matches();
+}
+
+void console::Interactive::set_query_cache(const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_query_cache(enabled);
+}
+
+bool console::Interactive::query_cache() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->query_cache();
+}
+
+void console::Interactive::set_trace(const TraceLevel level) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_trace(level != TraceLevel::Off);
+    _pImpl->_n->set_parse_trace(level == TraceLevel::Parsing);
+}
+
+console::Interactive::TraceLevel console::Interactive::trace() const
+{
+    const auto lock = _pImpl->read_lock();
+    if (_pImpl->_n->parse_trace()) return TraceLevel::Parsing;
+    return _pImpl->_n->trace() ? TraceLevel::Deductions : TraceLevel::Off;
+}
+
+void console::Interactive::set_native_arithmetic(const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_native_arithmetic(enabled);
+}
+
+bool console::Interactive::native_arithmetic() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->native_arithmetic();
+}
+
+void console::Interactive::set_string_builtins(const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_string_builtins(enabled);
+}
+
+bool console::Interactive::string_builtins() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->string_builtins();
+}
+
+void console::Interactive::set_list_builtins(const bool enabled) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_list_builtins(enabled);
+}
+
+bool console::Interactive::list_builtins() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->list_builtins();
+}
+
+void console::Interactive::set_thread_count(const size_t count) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_thread_count(count);
+}
+
+size_t console::Interactive::thread_count() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->thread_count();
+}
+
+void console::Interactive::set_deterministic(const bool on) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_deterministic(on);
+}
+
+bool console::Interactive::deterministic() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->deterministic();
+}
+
+void console::Interactive::set_triple_indexes(const uint8_t indexes) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_triple_indexes(indexes);
+}
+
+uint8_t console::Interactive::triple_indexes() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->triple_indexes();
+}
+
+void console::Interactive::set_max_facts(const uint64_t count) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_max_facts(count);
+}
+
+void console::Interactive::set_max_memory(const size_t bytes) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_max_memory(bytes);
+}
+
+void console::Interactive::set_max_term_depth(const uint32_t depth) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_max_term_depth(depth);
+}
+
+void console::Interactive::set_max_iterations(const int count) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_max_iterations(count);
+}
+
+void console::Interactive::set_max_deductions(const uint64_t count) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_max_deductions(count);
+}
+
+bool console::Interactive::fixpoint_reached() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->fixpoint_reached();
+}
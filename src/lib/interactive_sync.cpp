@@ -0,0 +1,213 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "process_error.hpp"
+
+#include <chrono>
+#include <condition_variable>
+#include <exception>
+#include <mutex>
+#include <set>
+#include <stop_token>
+#include <thread>
+#include <utility>
+
+using namespace zelph;
+
+console::Interactive::SyncResult console::Interactive::Impl::sync(const std::string& name, const io::RowTemplate& row_template, const io::RowSet& rows)
+{
+    if (name.empty()) throw std::runtime_error("Sync name must not be empty");
+    const auto facts = row_template.expand(rows); // before anything changes
+
+    network::Reasoning::OriginScope origin(*_n, {"sync", name});
+    Sync&                           sync = _syncs[name];
+    std::set<network::Node>         owned;
+    SyncResult                      result{rows.rows.size(), 0, 0, 0};
+    try
+    {
+        for (const auto& [s, p, o] : facts)
+        {
+            const network::Node subject   = _n->node(s);
+            const network::Node predicate = _n->node(p);
+            const network::Node object    = _n->node(o);
+            const bool          known     = _n->check_fact(subject, predicate, {object}).is_known();
+            const network::Node fact      = _n->fact(subject, predicate, {object});
+            if (!known)
+            {
+                owned.insert(fact);
+                ++result.added;
+            }
+            else if (sync.facts.count(fact))
+            {
+                owned.insert(fact);
+            }
+        }
+    }
+    catch (...)
+    {
+        // Facts created before the failure are retracted by the next sync.
+        sync.facts.insert(owned.begin(), owned.end());
+        report_new_facts();
+        throw;
+    }
+
+    for (const network::Node fact : sync.facts)
+    {
+        if (owned.count(fact) || !_n->exists(fact)) continue;
+        size_t withdrawn = 0;
+        _n->retract(fact, withdrawn);
+        ++result.retracted;
+    }
+
+    sync.facts     = std::move(owned);
+    result.facts   = sync.facts.size();
+    sync.last      = result;
+    sync.synced_at = std::chrono::duration_cast<std::chrono::seconds>(std::chrono::system_clock::now().time_since_epoch()).count();
+    report_new_facts();
+    return result;
+}
+
+void console::Interactive::Impl::stop_schedules()
+{
+    std::vector<std::jthread> schedules;
+    {
+        const auto lock = write_lock();
+        for (auto& [name, sync] : _syncs)
+        {
+            sync.schedule.request_stop();
+            schedules.push_back(std::move(sync.schedule));
+        }
+    }
+}
+
+console::Interactive::SyncResult console::Interactive::sync_rows(const std::string& name, const io::RowTemplate& row_template, const io::RowSet& rows) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        return _pImpl->sync(name, row_template, rows);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in sync '" + name + "': " + ex.what(), name, ProcessErrorKind::Statement, ex.what());
+    }
+}
+
+void console::Interactive::schedule_sync(const std::string& name, const io::RowTemplate& row_template, RowFetcher fetch, const std::chrono::milliseconds interval) const
+{
+    if (name.empty() || !fetch || interval.count() <= 0)
+        throw process_error("A scheduled sync needs a name, a fetch function and a positive interval", name, ProcessErrorKind::Command, "invalid schedule");
+
+    std::jthread previous; // joined when the lock is released
+    const auto   lock = _pImpl->write_lock();
+    auto&        sync = _pImpl->_syncs[name];
+    sync.schedule.request_stop();
+    previous      = std::move(sync.schedule);
+    sync.interval = interval;
+    sync.error.clear();
+
+    sync.schedule = std::jthread([this, name, row_template, fetch = std::move(fetch), interval](const std::stop_token stop)
+                                 {
+        std::mutex                  mtx;
+        std::condition_variable_any wake;
+        while (!stop.stop_requested())
+        {
+            try
+            {
+                const io::RowSet rows = fetch();
+                const auto       lock = _pImpl->write_lock();
+                if (stop.stop_requested()) return;
+                _pImpl->sync(name, row_template, rows);
+                _pImpl->_syncs[name].error.clear();
+            }
+            catch (const std::exception& ex)
+            {
+                const auto lock = _pImpl->write_lock();
+                if (stop.stop_requested()) return;
+                _pImpl->_syncs[name].error = ex.what();
+                if (_pImpl->_event_callback) _pImpl->_event_callback({"sync_error", {{"name", name}, {"reason", ex.what()}}});
+            }
+
+            std::unique_lock<std::mutex> wait(mtx);
+            wake.wait_for(wait, stop, interval, []
+                          { return false; });
+        } });
+}
+
+void console::Interactive::unschedule_sync(const std::string& name) const
+{
+    std::jthread schedule;
+    const auto   lock = _pImpl->write_lock();
+    auto         it   = _pImpl->_syncs.find(name);
+    if (it == _pImpl->_syncs.end())
+        throw process_error("Unknown sync '" + name + "'", name, ProcessErrorKind::Command, "unknown sync");
+
+    it->second.schedule.request_stop();
+    schedule            = std::move(it->second.schedule);
+    it->second.interval = std::chrono::milliseconds{0};
+}
+
+size_t console::Interactive::drop_sync(const std::string& name) const
+{
+    std::jthread schedule;
+    const auto   lock = _pImpl->write_lock();
+    auto         it   = _pImpl->_syncs.find(name);
+    if (it == _pImpl->_syncs.end())
+        throw process_error("Unknown sync '" + name + "'", name, ProcessErrorKind::Command, "unknown sync");
+
+    it->second.schedule.request_stop();
+    schedule = std::move(it->second.schedule);
+
+    size_t retracted = 0;
+    try
+    {
+        for (const network::Node fact : it->second.facts)
+        {
+            if (!_pImpl->_n->exists(fact)) continue;
+            size_t withdrawn = 0;
+            _pImpl->_n->retract(fact, withdrawn);
+            ++retracted;
+        }
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in sync '" + name + "': " + ex.what(), name, ProcessErrorKind::Statement, ex.what());
+    }
+    _pImpl->_syncs.erase(it);
+    return retracted;
+}
+
+std::vector<console::Interactive::SyncStatus> console::Interactive::syncs() const
+{
+    const auto              lock = _pImpl->read_lock();
+    std::vector<SyncStatus> result;
+    for (const auto& [name, sync] : _pImpl->_syncs)
+        result.push_back({name, sync.last, sync.synced_at, sync.error, sync.interval});
+    return result;
+}
@@ -0,0 +1,211 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "network/reasoning.hpp"
+#include "network/reasoning_cancelled.hpp"
+#include "network/reasoning_limit_exceeded.hpp"
+#include "process_error.hpp"
+#include "script_engine.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <exception>
+
+using namespace zelph;
+
+void console::Interactive::begin() const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->begin_transaction();
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".begin", ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::commit() const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        _pImpl->_n->commit_transaction();
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".commit", ProcessErrorKind::Command, ex.what());
+    }
+}
+
+size_t console::Interactive::rollback() const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        return _pImpl->_n->rollback_transaction();
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".rollback", ProcessErrorKind::Command, ex.what());
+    }
+}
+
+void console::Interactive::speculate(const std::function<void(const Interactive&)>& body) const
+{
+    const auto lock = _pImpl->write_lock();
+    begin();
+
+    struct Rollback
+    {
+        const Interactive* interactive;
+        ~Rollback()
+        {
+            if (interactive->in_transaction()) interactive->rollback();
+        }
+    } rollback{this};
+
+    body(*this);
+}
+
+std::vector<console::Interactive::Fact> console::Interactive::run_dry() const
+{
+    const auto        lock = _pImpl->write_lock();
+    std::vector<Fact> result;
+    try
+    {
+        _pImpl->_n->run_dry(false, [&](network::Node fact, network::Node)
+                            { result.push_back(_pImpl->describe(fact, true)); });
+        return result;
+    }
+    catch (const network::reasoning_cancelled& ex)
+    {
+        throw process_error(std::string("Error in dry run: ") + ex.what(), ".run-dry", ProcessErrorKind::Cancelled, ex.what());
+    }
+    catch (const network::reasoning_limit_exceeded& ex)
+    {
+        throw process_error(std::string("Error in dry run: ") + ex.what(), ".run-dry", ProcessErrorKind::ResourceLimit, ex.what());
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in dry run: ") + ex.what(), ".run-dry", ProcessErrorKind::Command, ex.what());
+    }
+}
+
+bool console::Interactive::in_transaction() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->in_transaction();
+}
+
+size_t console::Interactive::retract(const uint64_t fact) const
+{
+    const auto lock = _pImpl->write_lock();
+    try
+    {
+        size_t withdrawn = 0;
+        _pImpl->_n->retract(fact, withdrawn);
+        return withdrawn;
+    }
+    catch (const network::reasoning_cancelled& ex)
+    {
+        throw process_error(std::string("Error in retract: ") + ex.what(), std::to_string(fact), ProcessErrorKind::Cancelled, ex.what());
+    }
+    catch (const network::reasoning_limit_exceeded& ex)
+    {
+        throw process_error(std::string("Error in retract: ") + ex.what(), std::to_string(fact), ProcessErrorKind::ResourceLimit, ex.what());
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in retract: ") + ex.what(), std::to_string(fact), ProcessErrorKind::Command, ex.what());
+    }
+}
+
+std::vector<console::Interactive::Fact> console::Interactive::revise(const uint64_t fact, const std::string& replacement) const
+{
+    const auto       lock = _pImpl->write_lock();
+    ProcessErrorKind kind = ProcessErrorKind::Syntax;
+    try
+    {
+        network::Node stated = 0;
+        if (!replacement.empty())
+        {
+            const std::string code = _pImpl->_script_engine->parse_zelph_to_janet(replacement);
+            if (code.empty())
+                throw std::runtime_error("Syntax error: Could not parse statement.");
+            kind   = ProcessErrorKind::Statement;
+            stated = _pImpl->_script_engine->evaluate_expression(code);
+        }
+
+        kind = ProcessErrorKind::Command;
+        std::vector<Fact> withdrawn;
+        for (const auto& parts : _pImpl->_n->revise(fact, stated))
+            withdrawn.push_back(_pImpl->describe(parts));
+        return withdrawn;
+    }
+    catch (const network::reasoning_cancelled& ex)
+    {
+        throw process_error(std::string("Error in revise: ") + ex.what(), std::to_string(fact), ProcessErrorKind::Cancelled, ex.what());
+    }
+    catch (const network::reasoning_limit_exceeded& ex)
+    {
+        throw process_error(std::string("Error in revise: ") + ex.what(), std::to_string(fact), ProcessErrorKind::ResourceLimit, ex.what());
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(std::string("Error in revise: ") + ex.what(), replacement.empty() ? std::to_string(fact) : replacement, kind, ex.what());
+    }
+}
+
+std::vector<console::Interactive::Conflict> console::Interactive::conflicts() const
+{
+    const auto lock = _pImpl->read_lock();
+    const network::Reasoning* n = _pImpl->_n.get();
+
+    auto render = [n](network::Node nd)
+    {
+        std::string value;
+        string::node_to_string(n, value, n->lang(), nd, 3);
+        return string::unmark_identifiers(value);
+    };
+
+    std::vector<Conflict> result;
+    for (const auto& c : n->conflicts())
+    {
+        auto& conflict     = result.emplace_back();
+        conflict.rule      = c.rule;
+        conflict.rule_text = c.rule ? render(c.rule) : "";
+        for (network::Node fact : c.facts)
+        {
+            conflict.facts.push_back(fact);
+            conflict.fact_texts.push_back(render(fact));
+        }
+    }
+    return result;
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "process_error.hpp"
+#include "script_engine.hpp"
+
+#include <exception>
+
+using namespace zelph;
+
+void console::Interactive::create_view(const std::string& name, const std::string& statement) const
+{
+    const auto       lock = _pImpl->write_lock();
+    ProcessErrorKind kind = ProcessErrorKind::Syntax;
+
+    try
+    {
+        const std::string code = _pImpl->_script_engine->parse_zelph_to_janet(statement);
+        if (code.empty())
+            throw std::runtime_error("Syntax error: Could not parse statement.");
+
+        kind                          = ProcessErrorKind::Statement;
+        const network::Node condition = _pImpl->_script_engine->evaluate_expression(code);
+        if (condition == 0)
+            throw std::runtime_error("Invalid pattern");
+
+        _pImpl->_n->create_view(name, condition);
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error("Error in view \"" + name + "\": " + ex.what(), statement, kind, ex.what());
+    }
+}
+
+std::vector<console::Interactive::QueryBinding> console::Interactive::view(const std::string& name) const
+{
+    const auto lock = _pImpl->write_lock(); // reading brings the view up to date
+
+    try
+    {
+        std::vector<QueryBinding> result;
+        for (const auto& answer : _pImpl->_n->view(name))
+        {
+            auto& binding = result.emplace_back();
+            for (const auto& [variable, value] : answer)
+                binding[_pImpl->render(variable)] = _pImpl->render(value);
+        }
+        return result;
+    }
+    catch (std::exception& ex)
+    {
+        throw process_error(ex.what(), ".view " + name, ProcessErrorKind::Command, ex.what());
+    }
+}
+
+bool console::Interactive::remove_view(const std::string& name) const
+{
+    const auto lock = _pImpl->write_lock();
+    return _pImpl->_n->remove_view(name);
+}
+
+std::vector<std::string> console::Interactive::views() const
+{
+    const auto lock = _pImpl->read_lock();
+    return _pImpl->_n->views();
+}
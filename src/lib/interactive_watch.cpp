@@ -0,0 +1,230 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "interactive.hpp"
+
+#include "interactive_impl.hpp"
+#include "io/schema.hpp"
+#include "network/reasoning.hpp"
+
+#include <algorithm>
+#include <chrono>
+#include <mutex>
+#include <utility>
+
+using namespace zelph;
+
+void console::Interactive::Impl::install_observers()
+{
+    if (_deduction_callback || _event_callback || _journal || !_watches.empty())
+    {
+        _n->set_deduction_observer([this](network::Node fact, network::Node rule)
+                                   {
+            Deduction deduction{fact, rule, render(fact), render(rule)};
+            if (_journal) journal_deduction(deduction);
+            if (_deduction_callback) _deduction_callback(deduction);
+            if (!_watches.empty()) notify_watches("deduced", describe(fact, true), deduction);
+            if (_event_callback)
+                _event_callback({"rule_fired",
+                                 {{"fact", std::to_string(fact)},
+                                  {"rule", std::to_string(rule)},
+                                  {"fact_text", deduction.fact_text},
+                                  {"rule_text", deduction.rule_text}}}); });
+    }
+    else
+    {
+        _n->set_deduction_observer(nullptr);
+    }
+
+    if (_event_callback)
+    {
+        _n->set_contradiction_observer([this](network::Node rule, const std::vector<network::Node>& facts)
+                                       {
+            Event event{"contradiction", {{"rule", std::to_string(rule)}, {"rule_text", rule ? render(rule) : ""}}};
+            for (network::Node fact : facts)
+            {
+                event.attributes.emplace_back("fact", std::to_string(fact));
+                event.attributes.emplace_back("fact_text", render(fact));
+            }
+            _event_callback(event);
+
+            if (const auto violation = io::schema_violation(_n.get(), facts))
+                _event_callback({"schema_violation",
+                                 {{"fact", std::to_string(violation->fact)},
+                                  {"fact_text", render(violation->fact)},
+                                  {"relation", render(violation->relation)},
+                                  {"position", violation->subject ? "subject" : "object"},
+                                  {"node", render(violation->node)},
+                                  {"expected", render(violation->expected)}}}); });
+    }
+    else
+    {
+        _n->set_contradiction_observer(nullptr);
+    }
+
+    // fact_added events, watches and sessions (see Session::undo) need
+    // the facts that lines add.
+    if (_event_callback || !_watches.empty() || _session_facts)
+    {
+        _n->set_fact_observer([this](network::Node fact)
+                              {
+            std::lock_guard<std::mutex> lock(_mtx_new_facts);
+            if (_event_callback || !_watches.empty()) _new_facts.push_back(fact);
+            if (_session_facts) _session_facts->push_back(fact); });
+    }
+    else
+    {
+        _n->set_fact_observer(nullptr);
+    }
+
+    if (!_watches.empty())
+    {
+        _n->set_retraction_observer([this](const network::Reasoning::RetractedFact& retracted)
+                                    { notify_watches("retracted", describe(retracted)); });
+    }
+    else
+    {
+        _n->set_retraction_observer(nullptr);
+    }
+}
+
+console::Interactive::Fact console::Interactive::Impl::describe(const network::Reasoning::RetractedFact& parts) const
+{
+    Fact fact{parts.fact, render(parts.subject), render(parts.predicate), {}, parts.deduced};
+    for (network::Node object : parts.objects)
+        fact.objects.push_back(render(object));
+    std::sort(fact.objects.begin(), fact.objects.end());
+    return fact;
+}
+
+console::Interactive::Fact console::Interactive::Impl::describe(const network::Node fact, const bool deduced) const
+{
+    network::Reasoning::RetractedFact parts{fact, 0, 0, {}, deduced};
+    parts.subject   = _n->parse_fact(fact, parts.objects);
+    parts.predicate = _n->parse_relation(fact);
+    return describe(parts);
+}
+
+bool console::Interactive::Impl::matches(const Triple& pattern, const Fact& fact)
+{
+    std::map<std::string, std::string> bound;
+    auto                               match = [](const Term& term, const std::string& name, std::map<std::string, std::string>& bindings)
+    {
+        if (!term.variable) return term.name == name;
+        if (term.name.empty()) return true;
+        const auto [it, inserted] = bindings.emplace(term.name, name);
+        return inserted || it->second == name;
+    };
+
+    if (!match(pattern.subject, fact.subject, bound) || !match(pattern.predicate, fact.predicate, bound)) return false;
+    return std::any_of(fact.objects.begin(), fact.objects.end(), [&](const std::string& object)
+                       {
+        auto bindings = bound;
+        return match(pattern.object, object, bindings); });
+}
+
+void console::Interactive::Impl::notify_watches(const std::string& kind, const Fact& fact, const std::optional<Deduction>& deduction) const
+{
+    for (const auto& [id, watch] : _watches)
+        if (!watch.pattern || matches(*watch.pattern, fact)) watch.callback({kind, fact, deduction});
+}
+
+bool console::Interactive::Impl::contains_var(const network::Node nd, const int depth) const
+{
+    if (network::Zelph::is_var(nd)) return true;
+    if (depth > 64) return false;
+    network::adjacency_set objects;
+    const network::Node    subject = _n->parse_fact(nd, objects);
+    if (subject == 0) return false;
+    if (contains_var(subject, depth + 1)) return true;
+    return std::any_of(objects.begin(), objects.end(), [&](network::Node o)
+                       { return contains_var(o, depth + 1); });
+}
+
+void console::Interactive::Impl::report_new_facts()
+{
+    std::vector<network::Node> facts;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_new_facts);
+        facts.swap(_new_facts);
+    }
+    if (!_event_callback && _watches.empty()) return;
+
+    const network::Reasoning* n = _n.get();
+
+    for (network::Node fact : facts)
+    {
+        if (!n->exists(fact) || n->is_deduced(fact)) continue;
+
+        network::adjacency_set objects;
+        const network::Node    subject   = n->parse_fact(fact, objects);
+        const network::Node    predicate = n->parse_relation(fact);
+        if (subject == 0 || predicate == n->core.Causes) continue;
+        if (predicate == n->core.IsA && objects.count(n->core.RelationTypeCategory) == 1) continue;
+        if (contains_var(fact)) continue;
+
+        if (_event_callback) _event_callback({"fact_added", {{"fact", std::to_string(fact)}, {"fact_text", render(fact)}}});
+        if (!_watches.empty()) notify_watches("added", describe(fact, false));
+    }
+}
+
+void console::Interactive::on_deduction(DeductionCallback callback) const
+{
+    const auto lock             = _pImpl->write_lock();
+    _pImpl->_deduction_callback = std::move(callback);
+    _pImpl->install_observers();
+}
+
+uint64_t console::Interactive::watch(ChangeCallback callback, const std::optional<Triple>& pattern) const
+{
+    const auto     lock = _pImpl->write_lock();
+    const uint64_t id   = _pImpl->_next_watch++;
+    _pImpl->_watches.emplace(id, Impl::Watch{std::move(callback), pattern});
+    _pImpl->install_observers();
+    return id;
+}
+
+void console::Interactive::unwatch(const uint64_t watch) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_watches.erase(watch);
+    _pImpl->install_observers();
+}
+
+void console::Interactive::on_event(EventCallback callback) const
+{
+    const auto lock         = _pImpl->write_lock();
+    _pImpl->_event_callback = std::move(callback);
+    _pImpl->install_observers();
+    std::lock_guard<std::mutex> facts_lock(_pImpl->_mtx_new_facts);
+    _pImpl->_new_facts.clear();
+}
+
+void console::Interactive::on_progress(ProgressCallback callback, const double interval_seconds) const
+{
+    const auto lock = _pImpl->write_lock();
+    _pImpl->_n->set_progress_observer(std::move(callback),
+                                      std::chrono::milliseconds(static_cast<int64_t>(interval_seconds * 1000)));
+}
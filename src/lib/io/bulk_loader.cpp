@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "bulk_loader.hpp"
+
+#include "network/zelph.hpp"
+
+#include <stdexcept>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+std::vector<std::string> zelph::io::split_bulk_fields(std::string_view line)
+{
+    std::vector<std::string> fields;
+    if (line.find('\t') != std::string_view::npos)
+    {
+        size_t start = 0;
+        while (true)
+        {
+            const size_t tab = line.find('\t', start);
+            fields.emplace_back(line.substr(start, tab == std::string_view::npos ? std::string_view::npos : tab - start));
+            if (tab == std::string_view::npos) break;
+            start = tab + 1;
+        }
+        return fields;
+    }
+
+    size_t i = 0;
+    while (i < line.size())
+    {
+        if (line[i] == ' ')
+        {
+            ++i;
+            continue;
+        }
+        std::string field;
+        if (line[i] == '"')
+        {
+            const size_t end = line.find('"', i + 1);
+            if (end == std::string_view::npos) throw std::runtime_error("unterminated quote");
+            field = line.substr(i + 1, end - i - 1);
+            i     = end + 1;
+        }
+        else
+        {
+            const size_t end = std::min(line.find(' ', i), line.size());
+            field            = line.substr(i, end - i);
+            i                = end;
+        }
+        fields.push_back(std::move(field));
+    }
+    return fields;
+}
+
+BulkLoader::BulkLoader(network::Zelph* n, BulkOptions options)
+    : _n(n)
+    , _options(std::move(options))
+    , _start(std::chrono::steady_clock::now())
+{
+}
+
+BulkLoader::~BulkLoader()
+{
+    if (!_finished) _n->invalidate_fact_structures_cache();
+}
+
+void BulkLoader::feed(std::string_view chunk)
+{
+    if (_finished) throw std::runtime_error("BulkLoader::feed() called after finish()");
+    _progress.bytes += chunk.size();
+
+    size_t start = 0;
+    while (true)
+    {
+        const size_t newline = chunk.find('\n', start);
+        if (newline == std::string_view::npos) break;
+
+        if (_pending.empty())
+        {
+            process_line(chunk.substr(start, newline - start));
+        }
+        else
+        {
+            _pending.append(chunk.substr(start, newline - start));
+            process_line(_pending);
+            _pending.clear();
+        }
+        start = newline + 1;
+    }
+    _pending.append(chunk.substr(start));
+}
+
+void BulkLoader::feed(std::istream& in)
+{
+    std::vector<char> buffer(1 << 20);
+    while (in)
+    {
+        in.read(buffer.data(), static_cast<std::streamsize>(buffer.size()));
+        if (in.gcount() > 0) feed(std::string_view(buffer.data(), static_cast<size_t>(in.gcount())));
+    }
+}
+
+const BulkProgress& BulkLoader::finish()
+{
+    if (_finished) return _progress;
+    if (!_pending.empty())
+    {
+        const std::string last = std::move(_pending);
+        _pending.clear();
+        process_line(last);
+    }
+    _finished = true;
+
+    _n->invalidate_fact_structures_cache();
+    _progress.seconds = std::chrono::duration<double>(std::chrono::steady_clock::now() - _start).count();
+    if (_options.progress) _options.progress(_progress);
+    return _progress;
+}
+
+void BulkLoader::process_line(std::string_view line)
+{
+    ++_progress.lines;
+    if (!line.empty() && line.back() == '\r') line.remove_suffix(1);
+
+    const size_t first = line.find_first_not_of(" \t");
+    if (first != std::string_view::npos && line[first] != '#')
+    {
+        std::vector<std::string> fields;
+        try
+        {
+            fields = split_bulk_fields(line);
+        }
+        catch (const std::exception& ex)
+        {
+            throw std::runtime_error("line " + std::to_string(_progress.lines) + ": " + ex.what());
+        }
+        if (fields.size() != 3 || fields[0].empty() || fields[1].empty() || fields[2].empty())
+            throw std::runtime_error("line " + std::to_string(_progress.lines) + ": expected subject, predicate and object, got '" + std::string(line) + "'");
+
+        const Node subject   = name_node(fields[0]);
+        const Node predicate = name_node(fields[1]);
+        const Node object    = name_node(fields[2]);
+
+        // The trusted path expects predicates to be declared as such.
+        if (_typed_predicates.insert(predicate).second)
+            _n->fact_import_trusted_single_object(predicate, _n->core.IsA, _n->core.RelationTypeCategory);
+
+        _n->fact_import_trusted_single_object(subject, predicate, object);
+        ++_progress.facts;
+    }
+
+    if (_options.progress_interval && _options.progress && _progress.lines % _options.progress_interval == 0) report();
+}
+
+Node BulkLoader::name_node(const std::string& name)
+{
+    auto it = _names.find(name);
+    if (it != _names.end()) return it->second;
+    return _names.emplace(name, _n->node(name, _options.lang)).first->second;
+}
+
+void BulkLoader::report()
+{
+    _progress.seconds = std::chrono::duration<double>(std::chrono::steady_clock::now() - _start).count();
+    _options.progress(_progress);
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <chrono>
+#include <cstdint>
+#include <functional>
+#include <istream>
+#include <string>
+#include <string_view>
+#include <unordered_map>
+#include <unordered_set>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    struct BulkProgress
+    {
+        uint64_t lines{0};
+        uint64_t facts{0};
+        uint64_t bytes{0};
+        double   seconds{0};
+
+        double facts_per_second() const { return seconds > 0 ? static_cast<double>(facts) / seconds : 0; }
+    };
+
+    struct BulkOptions
+    {
+        // Language of the node names; empty means the current language.
+        std::string lang;
+
+        // The progress callback is invoked every progress_interval lines
+        // (0: only once, when loading is finished).
+        uint64_t                                  progress_interval{100000};
+        std::function<void(const BulkProgress&)> progress;
+    };
+
+    // Splits a line of a plain fact file into its fields (see BulkLoader).
+    // An unterminated quote is thrown as std::runtime_error.
+    std::vector<std::string> split_bulk_fields(std::string_view line);
+
+    // Streams plain facts into a network without going through the script
+    // parser: one "subject predicate object" per line, separated by tabs
+    // or, if a line contains no tab, by spaces (double quotes group names
+    // containing spaces). Empty lines and lines starting with # are
+    // skipped. Facts are inserted via the trusted import path of the
+    // Wikidata importer, i.e. without the per-fact checks, probability
+    // handling and fact-creation notifications of Zelph::fact(); derived
+    // caches are invalidated when loading ends. Input arrives in
+    // chunks of arbitrary size (feed), so callers can stream from any
+    // source. Errors are thrown as std::runtime_error naming the line;
+    // facts before it are kept.
+    class BulkLoader
+    {
+    public:
+        explicit BulkLoader(network::Zelph* n, BulkOptions options = {});
+        ~BulkLoader(); // an unfinished (failed) load still invalidates the caches
+
+        BulkLoader(const BulkLoader&)            = delete;
+        BulkLoader& operator=(const BulkLoader&) = delete;
+
+        void                feed(std::string_view chunk);
+        void                feed(std::istream& in); // reads to the end of the stream
+        const BulkProgress& finish();
+
+    private:
+        void          process_line(std::string_view line);
+        network::Node name_node(const std::string& name);
+        void          report();
+
+        network::Zelph*                                _n;
+        BulkOptions                                    _options;
+        std::string                                    _pending; // incomplete last line
+        BulkProgress                                   _progress;
+        std::chrono::steady_clock::time_point          _start;
+        std::unordered_map<std::string, network::Node> _names;
+        std::unordered_set<network::Node>              _typed_predicates;
+        bool                                           _finished{false};
+    };
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "cardinality.hpp"
+
+#include "facts.hpp"
+#include "network/zelph.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <map>
+#include <optional>
+#include <set>
+#include <stdexcept>
+#include <string>
+#include <utility>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    // The number a node is named by, if its name consists of decimal digits.
+    std::optional<size_t> number(const zelph::network::Zelph* n, Node nd)
+    {
+        const std::string name = n->get_name(nd, n->lang(), false);
+        if (name.empty() || !std::all_of(name.begin(), name.end(), [](unsigned char c)
+                                         { return std::isdigit(c); }))
+            return std::nullopt;
+        try
+        {
+            return std::stoull(name);
+        }
+        catch (const std::out_of_range&)
+        {
+            return std::nullopt;
+        }
+    }
+}
+
+std::vector<CardinalityViolation> zelph::io::cardinality_violations(const network::Zelph* n)
+{
+    const Node functional      = n->get_node(functional_class, n->lang());
+    const Node max_cardinality = n->get_node(max_cardinality_name, n->lang());
+    if (!functional && !max_cardinality) return {};
+
+    const auto facts = exportable_facts(n);
+
+    std::map<Node, size_t> limits;
+    auto                   declare = [&](Node relation, size_t limit)
+    {
+        auto [it, inserted] = limits.try_emplace(relation, limit);
+        if (!inserted) it->second = std::min(it->second, limit);
+    };
+    for (const auto& fact : facts)
+    {
+        for (Node object : fact.objects)
+        {
+            if (functional && fact.predicate == n->core.IsA && object == functional)
+                declare(fact.subject, 1);
+            else if (max_cardinality && fact.predicate == max_cardinality)
+                if (const auto limit = number(n, object)) declare(fact.subject, *limit);
+        }
+    }
+    if (limits.empty()) return {};
+
+    struct Usage
+    {
+        std::set<Node>    objects;
+        std::vector<Node> facts;
+    };
+    std::map<std::pair<Node, Node>, Usage> usages;
+    for (const auto& fact : facts)
+    {
+        if (!limits.contains(fact.predicate)) continue;
+        Usage& usage = usages[{fact.predicate, fact.subject}];
+        usage.objects.insert(fact.objects.begin(), fact.objects.end());
+        usage.facts.push_back(fact.relation);
+    }
+
+    std::vector<CardinalityViolation> result;
+    for (auto& [key, usage] : usages)
+    {
+        const size_t limit = limits.at(key.first);
+        if (usage.objects.size() <= limit) continue;
+        result.push_back({key.first, key.second, limit, usage.objects.size(), std::move(usage.facts)});
+    }
+    return result;
+}
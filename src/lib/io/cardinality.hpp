@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <cstddef>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // Cardinality constraints of relations: (R ~ functional) allows every
+    // subject at most one object of R, (R max_cardinality N) at most N, N
+    // being a node named by a decimal number. If a relation has several
+    // declarations, the smallest limit applies; declarations whose limit is
+    // not a number are ignored.
+    inline constexpr const char* functional_class     = "functional";
+    inline constexpr const char* max_cardinality_name = "max_cardinality";
+
+    // A subject with more objects of relation than limit: count is the
+    // number of distinct objects, facts are the statements that give them.
+    struct CardinalityViolation
+    {
+        network::Node              relation{0};
+        network::Node              subject{0};
+        size_t                     limit{0};
+        size_t                     count{0};
+        std::vector<network::Node> facts;
+    };
+
+    // All cardinality violations of the statements of the network (see
+    // exportable_facts), by relation and subject. The declarations are read
+    // with the names in the current language.
+    std::vector<CardinalityViolation> cardinality_violations(const network::Zelph* n);
+}
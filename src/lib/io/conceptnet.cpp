@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "conceptnet.hpp"
+
+#include "json.hpp"
+
+#include "network/zelph.hpp"
+
+#include <chrono>
+#include <cstdlib>
+#include <stdexcept>
+#include <string_view>
+#include <unordered_map>
+#include <unordered_set>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    struct Concept
+    {
+        std::string lang;
+        std::string term;
+    };
+
+    // /c/en/ice_cream/n/wn/food -> {"en", "ice cream"}; false for URIs
+    // that are not concepts.
+    bool parse_concept(std::string_view uri, Concept& result)
+    {
+        if (!uri.starts_with("/c/")) return false;
+        uri.remove_prefix(3);
+
+        const size_t slash = uri.find('/');
+        if (slash == std::string_view::npos || slash == 0) return false;
+        result.lang = uri.substr(0, slash);
+
+        uri.remove_prefix(slash + 1);
+        result.term = uri.substr(0, uri.find('/'));
+        if (result.term.empty()) return false;
+        for (char& c : result.term)
+            if (c == '_') c = ' ';
+        return true;
+    }
+
+    std::set<std::string> split_list(const std::string& list)
+    {
+        std::set<std::string> result;
+        for (size_t start = 0; start <= list.size();)
+        {
+            size_t comma = list.find(',', start);
+            if (comma == std::string::npos) comma = list.size();
+            if (comma > start) result.insert(list.substr(start, comma - start));
+            start = comma + 1;
+        }
+        return result;
+    }
+}
+
+ConceptNetOptions zelph::io::parse_conceptnet_options(const std::vector<std::string>& args)
+{
+    ConceptNetOptions options;
+    for (const std::string& option : args)
+    {
+        if (option == "qualify")
+            options.qualify = true;
+        else if (option.starts_with("languages="))
+            options.languages = split_list(option.substr(10));
+        else if (option.starts_with("relations="))
+            options.relations = split_list(option.substr(10));
+        else if (option.starts_with("min-weight="))
+        {
+            const std::string value = option.substr(11);
+            char*             end   = nullptr;
+            options.min_weight      = std::strtod(value.c_str(), &end);
+            if (value.empty() || *end != '\0') throw std::runtime_error("invalid weight '" + value + "'");
+        }
+        else
+            throw std::runtime_error("unknown option '" + option + "'");
+    }
+    return options;
+}
+
+BulkProgress zelph::io::import_conceptnet(network::Zelph* n, std::istream& in, const ConceptNetOptions& options, const std::string& file_name)
+{
+    const auto   start = std::chrono::steady_clock::now();
+    BulkProgress progress;
+
+    auto report = [&]
+    {
+        progress.seconds = std::chrono::duration<double>(std::chrono::steady_clock::now() - start).count();
+        options.progress(progress);
+    };
+
+    std::unordered_map<std::string, Node> names;
+    std::unordered_set<Node>              typed_predicates;
+    auto                                  name_node = [&](const std::string& name)
+    {
+        auto it = names.find(name);
+        if (it != names.end()) return it->second;
+        return names.emplace(name, n->node(name, options.lang)).first->second;
+    };
+
+    // Caches are invalidated also if a malformed line ends the import.
+    struct Invalidate
+    {
+        network::Zelph* n;
+        ~Invalidate() { n->invalidate_fact_structures_cache(); }
+    } invalidate{n};
+
+    std::vector<std::string_view> fields;
+    for (std::string line; std::getline(in, line);)
+    {
+        ++progress.lines;
+        progress.bytes += line.size() + 1;
+        if (!line.empty() && line.back() == '\r') line.pop_back();
+
+        if (!line.empty())
+        {
+            fields.clear();
+            std::string_view rest(line);
+            while (true)
+            {
+                const size_t tab = rest.find('\t');
+                fields.push_back(rest.substr(0, tab));
+                if (tab == std::string_view::npos) break;
+                rest.remove_prefix(tab + 1);
+            }
+            if (fields.size() < 4 || !fields[1].starts_with("/r/"))
+                throw std::runtime_error(file_name + ":" + std::to_string(progress.lines) + ": expected assertion, relation, start and end separated by tabs");
+
+            const std::string relation(fields[1].substr(3));
+            Concept           subject, object;
+            bool              keep = (options.relations.empty() || options.relations.count(relation))
+                    && parse_concept(fields[2], subject) && parse_concept(fields[3], object)
+                    && (options.languages.empty() || (options.languages.count(subject.lang) && options.languages.count(object.lang)));
+
+            if (keep && options.min_weight > 0)
+            {
+                double weight = 1;
+                if (fields.size() > 4 && !fields[4].empty())
+                {
+                    const JsonValue  metadata = JsonParser(std::string(fields[4]), file_name, progress.lines).parse();
+                    const JsonValue* w        = metadata.find("weight");
+                    if (w && w->type == JsonValue::Type::Number) weight = std::strtod(w->text.c_str(), nullptr);
+                }
+                keep = weight >= options.min_weight;
+            }
+
+            if (keep)
+            {
+                const Node predicate = name_node(relation);
+                if (typed_predicates.insert(predicate).second)
+                    n->fact_import_trusted_single_object(predicate, n->core.IsA, n->core.RelationTypeCategory);
+
+                const Node s = name_node(options.qualify ? subject.lang + ":" + subject.term : subject.term);
+                const Node o = name_node(options.qualify ? object.lang + ":" + object.term : object.term);
+                if (s != o) // e.g. /r/Synonym between the same term in two languages
+                {
+                    n->fact_import_trusted_single_object(s, predicate, o);
+                    ++progress.facts;
+                }
+            }
+        }
+
+        if (options.progress_interval && options.progress && progress.lines % options.progress_interval == 0) report();
+    }
+
+    if (options.progress)
+        report();
+    else
+        progress.seconds = std::chrono::duration<double>(std::chrono::steady_clock::now() - start).count();
+    return progress;
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "bulk_loader.hpp"
+
+#include <istream>
+#include <set>
+#include <string>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    struct ConceptNetOptions
+    {
+        // Languages of the concepts to keep, e.g. {"en", "de"}; an assertion
+        // is kept if both of its concepts are in one of them. Empty keeps all.
+        std::set<std::string> languages;
+
+        // Relations to keep, named without /r/, e.g. {"IsA", "PartOf",
+        // "dbpedia/genre"}. Empty keeps all.
+        std::set<std::string> relations;
+
+        // Assertions with a smaller weight (from the JSON column) are left
+        // out; 0 keeps all.
+        double min_weight{0};
+
+        // Name concepts "en:dog" instead of "dog", so that words spelled the
+        // same in several languages stay apart.
+        bool qualify{false};
+
+        // Language of the node names; empty means the current language.
+        std::string lang;
+
+        // See BulkOptions.
+        uint64_t                                  progress_interval{100000};
+        std::function<void(const BulkProgress&)> progress;
+    };
+
+    // Parses the options of .import-conceptnet: languages=en,de,
+    // relations=IsA,PartOf, min-weight=<number> and qualify. Throws
+    // std::runtime_error for an unknown or malformed option.
+    ConceptNetOptions parse_conceptnet_options(const std::vector<std::string>& args);
+
+    // Imports the assertions of a ConceptNet 5 CSV dump (tab-separated:
+    // assertion URI, relation, start concept, end concept, JSON metadata;
+    // the published file is gzipped and must be unpacked first). Each kept
+    // assertion /r/R /c/<lang>/<term>[/...] /c/<lang>/<term>[/...] becomes
+    // the fact "term R term", with underscores in terms replaced by blanks
+    // and the part of speech and sense dropped. Concepts that are not /c/
+    // URIs (e.g. of /r/ExternalURL) are skipped. Facts are inserted via the
+    // trusted import path like BulkLoader, without recorded provenance. A
+    // malformed line is thrown as std::runtime_error naming file_name and
+    // the line; facts before it are kept. Returns lines, facts and bytes.
+    BulkProgress import_conceptnet(network::Zelph*          n,
+                                   std::istream&            in,
+                                   const ConceptNetOptions& options   = {},
+                                   const std::string&       file_name = "<input>");
+}
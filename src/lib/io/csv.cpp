@@ -0,0 +1,342 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+
+#include "csv.hpp"
+
+#include "network/zelph.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <charconv>
+#include <cmath>
+#include <cstdlib>
+#include <iomanip>
+#include <optional>
+#include <sstream>
+#include <stdexcept>
+#include <unordered_map>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    std::string trim(const std::string& s)
+    {
+        const size_t first = s.find_first_not_of(" \t");
+        if (first == std::string::npos) return "";
+        return s.substr(first, s.find_last_not_of(" \t") - first + 1);
+    }
+
+    std::string lower(std::string s)
+    {
+        std::transform(s.begin(), s.end(), s.begin(), [](unsigned char c)
+                       { return static_cast<char>(std::tolower(c)); });
+        return s;
+    }
+
+    // Reads one record; returns false at the end of the input. line is the
+    // number of the last line read and is advanced past line breaks inside
+    // quoted cells.
+    bool read_record(std::istream& in, const char delimiter, std::vector<std::string>& cells, size_t& line, const std::string& file_name)
+    {
+        cells.clear();
+        std::string text;
+        if (!std::getline(in, text)) return false;
+        ++line;
+
+        const size_t first = line;
+        std::string  cell;
+        bool         quoted = false;
+        for (size_t i = 0;; ++i)
+        {
+            if (i == text.size())
+            {
+                if (!quoted) break;
+                // A line break inside a quoted cell belongs to the cell.
+                if (!std::getline(in, text))
+                    throw std::runtime_error(file_name + ":" + std::to_string(first) + ": unterminated quote");
+                ++line;
+                cell += '\n';
+                i = static_cast<size_t>(-1);
+                continue;
+            }
+
+            const char c = text[i];
+            if (quoted)
+            {
+                if (c != '"')
+                    cell += c;
+                else if (i + 1 < text.size() && text[i + 1] == '"')
+                    cell += text[++i];
+                else
+                    quoted = false;
+            }
+            else if (c == '"' && trim(cell).empty())
+            {
+                cell.clear();
+                quoted = true;
+            }
+            else if (c == delimiter)
+            {
+                cells.push_back(std::move(cell));
+                cell.clear();
+            }
+            else if (c != '\r' || i + 1 != text.size())
+            {
+                cell += c;
+            }
+        }
+        cells.push_back(std::move(cell));
+        return true;
+    }
+
+    std::optional<std::string> convert(const std::string& text, const CsvType type)
+    {
+        switch (type)
+        {
+        case CsvType::String:
+            return text;
+        case CsvType::Integer:
+        {
+            const std::string digits = text[0] == '+' ? text.substr(1) : text;
+            if (digits.empty() || digits[0] == '+' || (text[0] == '+' && digits[0] == '-')) return std::nullopt;
+            long long         value  = 0;
+            const auto [ptr, ec]     = std::from_chars(digits.data(), digits.data() + digits.size(), value);
+            if (ec != std::errc() || ptr != digits.data() + digits.size()) return std::nullopt;
+            return std::to_string(value);
+        }
+        case CsvType::Number:
+        {
+            const size_t digit = text[0] == '-' || text[0] == '+' ? 1 : 0;
+            if (digit >= text.size() || !(std::isdigit(static_cast<unsigned char>(text[digit])) || text[digit] == '.')) return std::nullopt;
+            if (text.find_first_of("xXnN", digit) != std::string::npos) return std::nullopt;
+
+            char*        end   = nullptr;
+            const double value = std::strtod(text.c_str(), &end);
+            if (end != text.c_str() + text.size() || !std::isfinite(value)) return std::nullopt;
+
+            // Written like the results of the arithmetic builtins.
+            if (std::trunc(value) == value && std::fabs(value) < 1e15) return std::to_string(static_cast<long long>(value));
+            std::ostringstream out;
+            out << std::setprecision(15) << value;
+            return out.str();
+        }
+        case CsvType::Boolean:
+        {
+            const std::string word = lower(text);
+            if (word == "true" || word == "yes" || word == "1") return "true";
+            if (word == "false" || word == "no" || word == "0") return "false";
+            return std::nullopt;
+        }
+        }
+        return std::nullopt;
+    }
+
+    const char* type_name(const CsvType type)
+    {
+        switch (type)
+        {
+        case CsvType::Integer:
+            return "integer";
+        case CsvType::Number:
+            return "number";
+        case CsvType::Boolean:
+            return "boolean";
+        default:
+            return "string";
+        }
+    }
+
+    CsvField parse_field(std::string spec)
+    {
+        CsvField     field;
+        const size_t colon = spec.rfind(':');
+        if (colon != std::string::npos)
+        {
+            static const std::unordered_map<std::string, CsvType> types{
+                {"string", CsvType::String}, {"int", CsvType::Integer}, {"number", CsvType::Number}, {"bool", CsvType::Boolean}};
+            auto it = types.find(spec.substr(colon + 1));
+            if (it != types.end())
+            {
+                field.type = it->second;
+                spec.erase(colon);
+            }
+        }
+
+        if (spec.starts_with("="))
+        {
+            field.constant = spec.substr(1);
+            if (field.constant.empty()) throw std::runtime_error("empty constant '='");
+            if (field.type != CsvType::String)
+            {
+                auto value = convert(field.constant, field.type);
+                if (!value) throw std::runtime_error("constant '" + field.constant + "' is not a " + type_name(field.type));
+                field.constant = *value;
+            }
+        }
+        else
+        {
+            if (spec.empty()) throw std::runtime_error("empty column name");
+            field.column = spec;
+        }
+        return field;
+    }
+}
+
+CsvMapping zelph::io::parse_csv_mapping(const std::vector<std::string>& spec)
+{
+    if (spec.size() < 3) throw std::runtime_error("expected a subject, a relation and an object (each a column or =constant)");
+
+    CsvMapping mapping;
+    mapping.subject  = parse_field(spec[0]);
+    mapping.relation = parse_field(spec[1]);
+    mapping.object   = parse_field(spec[2]);
+
+    for (size_t i = 3; i < spec.size(); ++i)
+    {
+        const std::string& option = spec[i];
+        if (option == "noheader")
+            mapping.header = false;
+        else if (option == "delimiter=tab")
+            mapping.delimiter = '\t';
+        else if (option.starts_with("delimiter=") && option.size() == 11)
+            mapping.delimiter = option[10];
+        else if (option.starts_with("lang=") && option.size() > 5)
+            mapping.lang = option.substr(5);
+        else
+            throw std::runtime_error("unknown option '" + option + "'");
+    }
+
+    if (mapping.delimiter == '"' || mapping.delimiter == '\n') throw std::runtime_error("invalid delimiter");
+    return mapping;
+}
+
+CsvResult zelph::io::import_csv(network::Zelph* n, std::istream& in, const CsvMapping& mapping, const std::string& file_name)
+{
+    CsvResult                result;
+    std::vector<std::string> cells;
+    size_t                   line = 0;
+
+    // Column positions of the mapped fields, from the header or the
+    // 1-based numbers that name them without one.
+    std::unordered_map<std::string, size_t> columns;
+    if (mapping.header)
+    {
+        if (!read_record(in, mapping.delimiter, cells, line, file_name)) return result;
+        for (size_t i = 0; i < cells.size(); ++i)
+            columns.emplace(trim(cells[i]), i);
+    }
+
+    auto position = [&](const CsvField& field) -> size_t
+    {
+        if (field.is_constant()) return 0;
+        if (mapping.header)
+        {
+            auto it = columns.find(field.column);
+            if (it == columns.end()) throw std::runtime_error(file_name + ": no column '" + field.column + "' in the header");
+            return it->second;
+        }
+        size_t     number = 0;
+        const auto [ptr, ec] = std::from_chars(field.column.data(), field.column.data() + field.column.size(), number);
+        if (ec != std::errc() || ptr != field.column.data() + field.column.size() || number == 0)
+            throw std::runtime_error(file_name + ": without a header, columns are numbered from 1, not '" + field.column + "'");
+        return number - 1;
+    };
+
+    const CsvField* fields[3]{&mapping.subject, &mapping.relation, &mapping.object};
+    size_t          positions[3];
+    for (size_t i = 0; i < 3; ++i)
+        positions[i] = position(*fields[i]);
+
+    std::unordered_map<std::string, Node> names;
+    auto                                  name_node = [&](const std::string& name)
+    {
+        auto it = names.find(name);
+        if (it != names.end()) return it->second;
+        return names.emplace(name, n->node(name, mapping.lang)).first->second;
+    };
+
+    while (true)
+    {
+        const size_t row_line = line + 1;
+        if (!read_record(in, mapping.delimiter, cells, line, file_name)) break;
+        if (cells.size() == 1 && trim(cells[0]).empty()) continue; // blank line
+        ++result.rows;
+
+        std::string parts[3];
+        bool        empty = false;
+        bool        ok    = true;
+        for (size_t i = 0; i < 3 && ok; ++i)
+        {
+            const CsvField& field = *fields[i];
+            if (field.is_constant())
+            {
+                parts[i] = field.constant;
+                continue;
+            }
+            if (positions[i] >= cells.size())
+            {
+                result.errors.push_back({row_line, "", "expected at least " + std::to_string(positions[i] + 1) + " cells, got " + std::to_string(cells.size())});
+                ok = false;
+                break;
+            }
+
+            const std::string text = trim(cells[positions[i]]);
+            if (text.empty())
+            {
+                empty = true;
+                continue;
+            }
+            auto value = convert(text, field.type);
+            if (!value)
+            {
+                result.errors.push_back({row_line, field.column, "'" + text + "' is not a " + type_name(field.type)});
+                ok = false;
+                break;
+            }
+            parts[i] = std::move(*value);
+        }
+        if (!ok) continue;
+        if (empty)
+        {
+            ++result.skipped;
+            continue;
+        }
+
+        try
+        {
+            n->fact(name_node(parts[0]), name_node(parts[1]), {name_node(parts[2])});
+            ++result.facts;
+        }
+        catch (const std::exception& ex)
+        {
+            result.errors.push_back({row_line, "", ex.what()});
+        }
+    }
+
+    return result;
+}
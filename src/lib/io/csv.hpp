@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+
+#pragma once
+
+#include <cstddef>
+#include <istream>
+#include <string>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // How the text of a CSV cell becomes a node name. String keeps it as it
+    // is (after trimming), the others check it and write it in a canonical
+    // form, so that "007", " 7" and "+7" all name the node 7 and numeric
+    // builtins can compare the values: Integer accepts an optional sign and
+    // digits, Number also a fraction and exponent (written without trailing
+    // zeros), Boolean true/false, yes/no, 1/0 in any case (written true or
+    // false).
+    enum class CsvType
+    {
+        String,
+        Integer,
+        Number,
+        Boolean
+    };
+
+    // One part of the facts made from a row: the cell of a column, named by
+    // its header or, without a header row, by its 1-based position, or a
+    // constant that is the same for every row (e.g. a fixed relation).
+    struct CsvField
+    {
+        std::string column;
+        std::string constant;
+        CsvType     type{CsvType::String};
+
+        bool is_constant() const { return column.empty(); }
+    };
+
+    // Which columns form the subject, relation and object of the fact that
+    // each row yields.
+    struct CsvMapping
+    {
+        CsvField subject;
+        CsvField relation;
+        CsvField object;
+
+        char        delimiter{','};
+        bool        header{true};
+        std::string lang; // language of the node names; empty means the current language
+    };
+
+    // Parses the mapping of .import-csv: the subject, relation and object,
+    // each a column or =constant, optionally followed by :int, :number,
+    // :bool or :string, then the options delimiter=<c> (tab for a tab),
+    // noheader and lang=<code>. Errors are thrown as std::runtime_error.
+    CsvMapping parse_csv_mapping(const std::vector<std::string>& spec);
+
+    // A row that yielded no fact.
+    struct CsvError
+    {
+        size_t      line{0}; // 1-based line of the row's first line
+        std::string column;  // empty if the row as a whole is at fault
+        std::string message;
+    };
+
+    struct CsvResult
+    {
+        size_t                rows{0};    // data rows read, the header excluded
+        size_t                facts{0};   // facts created
+        size_t                skipped{0}; // rows with an empty subject, relation or object
+        std::vector<CsvError> errors;
+    };
+
+    // Imports the rows of a CSV file (RFC 4180: quoted cells may contain the
+    // delimiter, line breaks and doubled quotes) as one fact per row
+    // according to the mapping. Rows with an empty cell in a mapped column
+    // are skipped; rows that are too short or whose cells do not convert to
+    // their type are collected as errors and the import goes on with the
+    // next row. A mapping that names a missing column or an unterminated
+    // quote is thrown as std::runtime_error naming file_name, facts read
+    // until then are kept.
+    CsvResult import_csv(network::Zelph*    n,
+                         std::istream&      in,
+                         const CsvMapping&  mapping,
+                         const std::string& file_name = "<input>");
+}
@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "cypher.hpp"
+#include "json.hpp"
+#include "rdf.hpp"
+
+#include "network/zelph.hpp"
+
+#include <algorithm>
+#include <map>
+#include <set>
+#include <unordered_map>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    std::string name_of(const zelph::network::Zelph* n, const Node nd)
+    {
+        std::string name = n->get_name(nd, "", true);
+        if (name.empty()) name = n->get_core_name(nd);
+        return name;
+    }
+
+    std::string backtick(const std::string& s)
+    {
+        std::string result = "`";
+        for (const char c : s)
+        {
+            if (c == '`') result += '`';
+            result += c;
+        }
+        return result + "`";
+    }
+
+    std::string key(const Node nd)
+    {
+        return "{zelph_id: \"" + std::to_string(nd) + "\"}";
+    }
+
+    std::string string_member(const JsonValue& v, const std::string& member)
+    {
+        const JsonValue* m = v.find(member);
+        return m && (m->type == JsonValue::Type::String || m->type == JsonValue::Type::Number) ? m->text : "";
+    }
+}
+
+size_t zelph::io::export_cypher(const network::Zelph*                      n,
+                                std::ostream&                              out,
+                                const std::function<bool(network::Node)>& is_deduced)
+{
+    auto facts = exportable_facts(n);
+    std::sort(facts.begin(), facts.end(), [](const ExportedFact& a, const ExportedFact& b)
+              { return a.relation < b.relation; });
+
+    std::set<Node> nodes;
+    for (const auto& fact : facts)
+    {
+        nodes.insert(fact.subject);
+        nodes.insert(fact.objects.begin(), fact.objects.end());
+    }
+
+    out << "CREATE CONSTRAINT zelph_id IF NOT EXISTS FOR (n:Zelph) REQUIRE n.zelph_id IS UNIQUE;\n";
+    for (const Node nd : nodes)
+    {
+        out << "MERGE (n:Zelph " << key(nd) << ")";
+        const std::string name = name_of(n, nd);
+        if (!name.empty()) out << " SET n.name = " << json_quote(name);
+        out << ";\n";
+    }
+
+    size_t relationships = 0;
+    for (const auto& fact : facts)
+    {
+        std::string type = name_of(n, fact.predicate);
+        if (type.empty()) type = "_" + std::to_string(fact.predicate);
+
+        for (const Node object : fact.objects)
+        {
+            out << "MATCH (s:Zelph " << key(fact.subject) << "), (o:Zelph " << key(object) << ") MERGE (s)-[r:" << backtick(type) << "]->(o)";
+            if (is_deduced) out << " SET r.deduced = " << (is_deduced(fact.relation) ? "true" : "false");
+            out << ";\n";
+            ++relationships;
+        }
+    }
+
+    return relationships;
+}
+
+size_t zelph::io::import_neo4j(network::Zelph* n, std::istream& in, const std::string& file_name, const std::string& name_property)
+{
+    std::vector<JsonValue> nodes;
+    std::vector<JsonValue> relationships;
+
+    size_t line_number = 0;
+    for (std::string line; std::getline(in, line);)
+    {
+        ++line_number;
+        if (line.find_first_not_of(" \t\r") == std::string::npos) continue;
+
+        JsonValue record = JsonParser(line, file_name, line_number).parse();
+        const std::string type = string_member(record, "type");
+        if (type == "node")
+            nodes.push_back(std::move(record));
+        else if (type == "relationship")
+            relationships.push_back(std::move(record));
+        else
+            throw std::runtime_error(file_name + ":" + std::to_string(line_number) + ": expected a record of type \"node\" or \"relationship\"");
+    }
+
+    auto fail = [&](const JsonValue& v, const std::string& message)
+    {
+        throw std::runtime_error(file_name + ":" + std::to_string(v.line) + ": " + message);
+    };
+
+    size_t facts = 0;
+    auto   emit  = [&](const JsonValue& v, Node subject, Node predicate, Node object)
+    {
+        try
+        {
+            n->fact(subject, predicate, {object});
+            ++facts;
+        }
+        catch (const std::exception& ex)
+        {
+            fail(v, std::string("cannot represent relationship: ") + ex.what());
+        }
+    };
+
+    std::unordered_map<std::string, Node> by_id;
+    for (const auto& record : nodes)
+    {
+        const std::string id = string_member(record, "id");
+        if (id.empty()) fail(record, "node without id");
+
+        std::string name;
+        if (const JsonValue* properties = record.find("properties"))
+            name = string_member(*properties, name_property);
+
+        const Node nd = name.empty() ? n->create_node() : n->node(name);
+        by_id[id]     = nd;
+
+        if (const JsonValue* labels = record.find("labels"))
+        {
+            for (const auto& label : labels->items)
+                if (label.type == JsonValue::Type::String && label.text != "Zelph")
+                    emit(record, nd, n->core.IsA, n->node(label.text));
+        }
+    }
+
+    auto endpoint = [&](const JsonValue& record, const std::string& member)
+    {
+        const JsonValue* end = record.find(member);
+        const std::string id = end ? string_member(*end, "id") : "";
+        auto it = by_id.find(id);
+        if (it == by_id.end()) fail(record, "relationship " + member + " refers to an unknown node '" + id + "'");
+        return it->second;
+    };
+
+    for (const auto& record : relationships)
+    {
+        const std::string type = string_member(record, "label");
+        if (type.empty()) fail(record, "relationship without label");
+        emit(record, endpoint(record, "start"), n->node(type), endpoint(record, "end"));
+    }
+
+    return facts;
+}
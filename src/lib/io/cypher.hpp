@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <functional>
+#include <istream>
+#include <ostream>
+#include <string>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // Writes all statements (see exportable_facts) as a Cypher script that
+    // rebuilds them in Neo4j, e.g. via cypher-shell. Every node becomes a
+    // :Zelph node keyed by its zelph node ID (property zelph_id), with its
+    // name in the current language as property name. Every fact becomes a
+    // relationship per object, typed by the predicate's name (~ stays ~).
+    // Statements use MERGE, so running the script again updates instead of
+    // duplicating. If is_deduced is given, relationships carry the property
+    // deduced. Nested statements appear as unnamed nodes. Returns the
+    // number of relationships written.
+    size_t export_cypher(const network::Zelph*                      n,
+                         std::ostream&                              out,
+                         const std::function<bool(network::Node)>& is_deduced = {});
+
+    // Imports a graph exported from Neo4j as JSON Lines, the format of
+    // apoc.export.json.all: one {"type":"node",...} or
+    // {"type":"relationship",...} object per line. Nodes are named by the
+    // property name_property in the current language (unnamed if missing),
+    // each label except Zelph yields a fact "node ~ Label", and each
+    // relationship a fact "start TYPE end". Relationship properties are not
+    // imported. Errors name file_name and the line. Returns the number of
+    // facts created.
+    size_t import_neo4j(network::Zelph*    n,
+                        std::istream&      in,
+                        const std::string& file_name     = "<input>",
+                        const std::string& name_property = "name");
+}
@@ -24,6 +24,8 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 */
 
 #include "data_manager.hpp"
+#include "jsonld.hpp"
+#include "rdf.hpp"
 #include "wikidata/wikidata.hpp"
 
 using namespace zelph::io;
@@ -104,13 +106,23 @@ std::shared_ptr<DataManager> DataManager::create(network::Zelph* n, const std::f
         }
     }
 
-    // 2. If no source file was found, but input is .bin, we treat it as a generic saved network.
+    // 2. RDF documents (Turtle and its subset N-Triples, JSON-LD) are imported directly, without a cache.
+    if (input_path.extension() == ".ttl" || input_path.extension() == ".nt")
+    {
+        return std::make_shared<RdfDataManager>(n, input_path);
+    }
+    if (input_path.extension() == ".jsonld")
+    {
+        return std::make_shared<JsonLdDataManager>(n, input_path);
+    }
+
+    // 3. If no source file was found, but input is .bin, we treat it as a generic saved network.
     if (input_path.extension() == ".bin")
     {
         return std::make_shared<GenericDataManager>(n, input_path);
     }
 
-    // 3. Fallback / Unknown format
+    // 4. Fallback / Unknown format
     // If we have a file that exists but isn't .bin and resolve_original returned empty (or something unknown),
     // we default to Generic, but Generic currently only supports .bin loading in this impl.
     // However, if source_path was valid but unknown extension, we might throw or assume generic.
@@ -35,7 +35,9 @@ namespace zelph::io
     enum class DataType
     {
         Generic,
-        Wikidata
+        Wikidata,
+        Rdf,
+        JsonLd
     };
 
     // An abstract strategy class responsible for populating a zelph network from external files.
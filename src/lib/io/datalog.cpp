@@ -0,0 +1,793 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "datalog.hpp"
+#include "facts.hpp"
+
+#include "network/reasoning.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <cstring>
+#include <iterator>
+#include <optional>
+#include <string>
+#include <unordered_map>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    class ClauseWriter
+    {
+    public:
+        ClauseWriter(const zelph::network::Reasoning* n, DatalogDialect dialect)
+            : _n(n)
+            , _dialect(dialect)
+        {
+        }
+
+        std::string symbol(const Node nd) const
+        {
+            std::string name = zelph::network::Zelph::is_hash(nd) ? "" : _n->get_name(nd, "", true);
+            if (name.empty()) name = _n->get_core_name(nd);
+            if (name.empty()) name = "_" + std::to_string(nd);
+
+            const char  quote  = _dialect == DatalogDialect::Souffle ? '"' : '\'';
+            std::string result = std::string(1, quote);
+            for (const char c : name)
+            {
+                if (c == quote || c == '\\') result += '\\';
+                if (c == '\n')
+                    result += "\\n";
+                else
+                    result += c;
+            }
+            return result + quote;
+        }
+
+        std::string literal(const std::string& s, const std::string& p, const std::string& o) const
+        {
+            return "fact(" + s + ", " + p + ", " + o + ")";
+        }
+
+        std::string negated(const std::string& literal) const
+        {
+            return _dialect == DatalogDialect::Souffle ? "!" + literal : "tnot(" + literal + ")";
+        }
+
+        std::string unequal(const std::string& a, const std::string& b) const
+        {
+            return a + (_dialect == DatalogDialect::Souffle ? " != " : " \\== ") + b;
+        }
+
+        std::string contradiction() const
+        {
+            return _dialect == DatalogDialect::Souffle ? "contradiction()" : "contradiction";
+        }
+
+        std::string comment() const
+        {
+            return _dialect == DatalogDialect::Souffle ? "// " : "% ";
+        }
+
+    private:
+        const zelph::network::Reasoning* _n;
+        DatalogDialect                   _dialect;
+    };
+
+    // One rule as clauses, or the reason why it has none.
+    class RuleTranslation
+    {
+    public:
+        RuleTranslation(const zelph::network::Reasoning* n, const ClauseWriter& writer)
+            : _n(n)
+            , _writer(writer)
+        {
+        }
+
+        bool translate(const Node rule)
+        {
+            zelph::network::adjacency_set consequences;
+            const Node                    condition = _n->parse_fact(rule, consequences);
+            if (!conditions(condition, false)) return false;
+
+            // A variable that occurs once, in a negated condition, stands
+            // for any value (_); all others must be bound by a positive one.
+            for (const auto& v : _variables)
+            {
+                if (!v.bound && (v.outside_negation || v.uses > 1)) return fail("variable " + v.name + " is not bound by a positive condition");
+            }
+
+            std::vector<Node> sorted(consequences.begin(), consequences.end());
+            std::sort(sorted.begin(), sorted.end());
+            for (const Node consequence : sorted)
+            {
+                if (consequence == _n->core.Contradiction)
+                {
+                    _heads.push_back(_writer.contradiction());
+                    _uses_contradiction = true;
+                    continue;
+                }
+                auto head = pattern(consequence);
+                if (!head) return false;
+                _heads.push_back(*head);
+            }
+            for (const auto& v : _variables)
+            {
+                if (!v.bound && v.outside_negation) return fail("variable " + v.name + " of a consequence is not bound by a condition");
+            }
+
+            // Negations and inequalities only filter, so they follow the
+            // literals that bind their variables.
+            _body.insert(_body.end(), _filters.begin(), _filters.end());
+            for (auto& clause : _body)
+                clause = render(clause);
+            for (auto& clause : _heads)
+                clause = render(clause);
+            return true;
+        }
+
+        const std::vector<std::string>& heads() const { return _heads; }
+        const std::vector<std::string>& body() const { return _body; }
+        const std::string&              reason() const { return _reason; }
+        bool                            uses_contradiction() const { return _uses_contradiction; }
+
+    private:
+        bool fail(const std::string& reason)
+        {
+            _reason = reason;
+            return false;
+        }
+
+        bool is_conjunction(const Node nd) const
+        {
+            return _n->check_fact(nd, _n->core.IsA, {_n->core.Conjunction}).is_known();
+        }
+
+        // The leaf conditions of a rule: the condition itself or the
+        // elements of a conjunction set, recursively (see
+        // Reasoning::collect_conditions).
+        bool conditions(const Node condition, bool negated)
+        {
+            if (!condition || !_n->exists(condition)) return fail("no condition");
+
+            if (_n->check_fact(condition, _n->core.IsA, {_n->core.Negation}).is_known())
+            {
+                if (negated) return fail("double negation");
+                if (is_conjunction(condition)) return fail("negated conjunction");
+                negated = true;
+            }
+
+            if (is_conjunction(condition))
+            {
+                std::vector<Node> elements;
+                for (Node rel : _n->get_right(condition))
+                {
+                    if (_n->parse_relation(rel) != _n->core.PartOf) continue;
+                    zelph::network::adjacency_set objs;
+                    Node                          element = _n->parse_fact(rel, objs);
+                    if (element && objs.count(condition) == 1) elements.push_back(element);
+                }
+                std::sort(elements.begin(), elements.end());
+                for (const Node element : elements)
+                {
+                    if (!conditions(element, negated)) return false;
+                }
+                return true;
+            }
+
+            zelph::network::adjacency_set objects;
+            const Node                    subject   = _n->parse_fact(condition, objects);
+            const Node                    predicate = _n->parse_relation(condition);
+            if (subject == 0 || objects.size() != 1) return fail("condition is not a statement with one object");
+
+            if (predicate == _n->core.Unequal)
+            {
+                auto a = term(subject);
+                auto b = term(*objects.begin());
+                if (!a || !b) return false;
+                _filters.push_back(negated ? *a + " = " + *b : _writer.unequal(*a, *b));
+                return true;
+            }
+            if (_n->is_builtin(predicate)) return fail("builtin " + _n->get_name(predicate, "", true));
+
+            _used.clear();
+            _negating    = negated;
+            auto literal = pattern(condition);
+            _negating    = false;
+            if (!literal) return false;
+            if (negated)
+                _filters.push_back(_writer.negated(*literal));
+            else
+            {
+                _body.push_back(*literal);
+                for (const size_t i : _used)
+                    _variables[i].bound = true;
+            }
+            return true;
+        }
+
+        std::optional<std::string> pattern(const Node nd)
+        {
+            zelph::network::adjacency_set objects;
+            const Node                    subject = _n->parse_fact(nd, objects);
+            if (subject == 0 || objects.size() != 1)
+            {
+                fail("pattern is not a statement with one object");
+                return std::nullopt;
+            }
+
+            auto s = term(subject);
+            auto p = term(_n->parse_relation(nd));
+            auto o = term(*objects.begin());
+            if (!s || !p || !o) return std::nullopt;
+            return _writer.literal(*s, *p, *o);
+        }
+
+        std::optional<std::string> term(const Node nd)
+        {
+            if (zelph::network::Zelph::is_var(nd))
+            {
+                auto [it, inserted] = _index.try_emplace(nd, _variables.size());
+                if (inserted) _variables.push_back({name_of_var(nd)});
+
+                Variable& v = _variables[it->second];
+                ++v.uses;
+                v.outside_negation = v.outside_negation || !_negating;
+                _used.push_back(it->second);
+                return "\x01" + std::to_string(it->second) + "\x01";
+            }
+            if (zelph::network::Zelph::is_hash(nd))
+            {
+                fail("nested statement");
+                return std::nullopt;
+            }
+            return _writer.symbol(nd);
+        }
+
+        struct Variable
+        {
+            std::string name;
+            size_t      uses{0};
+            bool        outside_negation{false};
+            bool        bound{false}; // by a positive condition
+        };
+
+        // A Datalog name for a variable: its own if it is one, V<n> otherwise.
+        std::string name_of_var(const Node nd) const
+        {
+            std::string name  = _n->get_name(nd, "", true);
+            bool        valid = !name.empty() && std::isupper(static_cast<unsigned char>(name[0]))
+                          && std::all_of(name.begin(), name.end(), [](char c)
+                                         { return std::isalnum(static_cast<unsigned char>(c)) || c == '_'; });
+            auto taken = [&](const std::string& candidate)
+            {
+                return std::any_of(_variables.begin(), _variables.end(), [&](const Variable& v)
+                                   { return v.name == candidate; });
+            };
+            for (size_t i = _variables.size(); !valid || taken(name); ++i)
+            {
+                name  = "V" + std::to_string(i);
+                valid = true;
+            }
+            return name;
+        }
+
+        // Replaces the placeholders of term() by the variables' names.
+        std::string render(const std::string& text) const
+        {
+            std::string result;
+            for (size_t i = 0; i < text.size(); ++i)
+            {
+                if (text[i] != '\x01')
+                {
+                    result += text[i];
+                    continue;
+                }
+                const size_t    end = text.find('\x01', i + 1);
+                const Variable& v   = _variables[std::stoul(text.substr(i + 1, end - i - 1))];
+                result += v.bound ? v.name : "_";
+                i = end;
+            }
+            return result;
+        }
+
+        const zelph::network::Reasoning* _n;
+        const ClauseWriter&              _writer;
+        std::unordered_map<Node, size_t> _index; // of a variable in _variables
+        std::vector<Variable>            _variables;
+        std::vector<size_t>              _used; // variables of the current pattern
+        bool                             _negating{false};
+        std::vector<std::string>         _body;
+        std::vector<std::string>         _filters;
+        std::vector<std::string>         _heads;
+        std::string                      _reason;
+        bool                             _uses_contradiction{false};
+    };
+
+    std::string join(const std::vector<std::string>& parts)
+    {
+        std::string result;
+        for (const auto& part : parts)
+            result += (result.empty() ? "" : ", ") + part;
+        return result;
+    }
+}
+
+DatalogCounts zelph::io::export_datalog(const network::Reasoning* n, std::ostream& out, const DatalogOptions& options)
+{
+    const ClauseWriter writer(n, options.dialect);
+    DatalogCounts      counts;
+
+    auto facts = exportable_facts(n);
+    std::sort(facts.begin(), facts.end(), [](const ExportedFact& a, const ExportedFact& b)
+              { return a.relation < b.relation; });
+
+    std::vector<std::string> clauses;
+    for (const auto& fact : facts)
+    {
+        if (!options.deduced && n->is_deduced(fact.relation)) continue;
+        if (fact.predicate == n->core.IsA && fact.objects.count(n->core.Conjunction) == 1) continue; // condition sets of rules
+
+        std::vector<Node> objects(fact.objects.begin(), fact.objects.end());
+        std::sort(objects.begin(), objects.end());
+        for (const Node object : objects)
+        {
+            clauses.push_back(writer.literal(writer.symbol(fact.subject), writer.symbol(fact.predicate), writer.symbol(object)) + ".");
+            ++counts.facts;
+        }
+    }
+
+    const auto        all_rules = n->get_rules();
+    std::vector<Node> rules(all_rules.begin(), all_rules.end());
+    std::sort(rules.begin(), rules.end());
+
+    bool contradiction = false;
+    for (const Node rule : rules)
+    {
+        if (!n->is_rule_enabled(rule)) continue;
+
+        RuleTranslation translation(n, writer);
+        if (!translation.translate(rule))
+        {
+            clauses.push_back(writer.comment() + "rule " + std::to_string(rule) + " skipped: " + translation.reason());
+            ++counts.skipped;
+            continue;
+        }
+
+        for (const auto& head : translation.heads())
+            clauses.push_back(head + " :- " + join(translation.body()) + ".");
+        contradiction = contradiction || translation.uses_contradiction();
+        ++counts.rules;
+    }
+
+    if (options.dialect == DatalogDialect::Souffle)
+    {
+        out << ".decl fact(s: symbol, p: symbol, o: symbol)\n"
+            << ".output fact\n";
+        if (contradiction)
+            out << ".decl contradiction()\n"
+                << ".output contradiction\n";
+    }
+    else
+    {
+        // Tabling makes recursive rules such as transitivity terminate and
+        // gives negation (tnot) the well-founded semantics.
+        out << ":- table fact/3.\n";
+        if (contradiction) out << ":- table contradiction/0.\n";
+    }
+    out << "\n";
+
+    for (const auto& clause : clauses)
+        out << clause << "\n";
+
+    return counts;
+}
+
+namespace
+{
+    struct Token
+    {
+        enum class Kind
+        {
+            Atom,
+            Var,
+            String,
+            Number,
+            Punct
+        };
+
+        Kind        kind;
+        std::string text;
+    };
+
+    struct Literal
+    {
+        bool        negated{false};
+        bool        unequal{false};
+        bool        contradiction{false};
+        std::string subject, predicate, object;
+        bool        ground{true};
+    };
+
+    // Parses the tokens of one clause (without the final dot) into a zelph
+    // statement; errors are thrown as std::runtime_error.
+    class ClauseParser
+    {
+    public:
+        explicit ClauseParser(const std::vector<Token>& tokens)
+            : _tokens(tokens)
+        {
+        }
+
+        std::string statement()
+        {
+            if (accept("?-"))
+            {
+                const std::vector<Literal> goals = literals();
+                expect_end();
+                std::string result;
+                for (const auto& goal : goals)
+                    result += (result.empty() ? "" : ", ") + zelph(goal);
+                return result;
+            }
+
+            const Literal head = literal();
+            if (head.negated || head.unequal) throw std::runtime_error("a head must be a positive literal");
+
+            if (!accept(":-"))
+            {
+                expect_end();
+                if (head.contradiction) throw std::runtime_error("a contradiction needs conditions");
+                if (!head.ground) throw std::runtime_error("a fact must not contain variables");
+                return zelph(head);
+            }
+
+            const std::vector<Literal> body = literals();
+            expect_end();
+            if (std::none_of(body.begin(), body.end(), [](const Literal& l)
+                             { return !l.negated && !l.unequal; }))
+                throw std::runtime_error("a rule needs a positive condition");
+
+            std::string conditions;
+            for (const auto& condition : body)
+                conditions += (conditions.empty() ? "" : ", ") + zelph(condition);
+            return "(" + conditions + ") => " + (head.contradiction ? "!" : "(" + zelph(head) + ")");
+        }
+
+    private:
+        const Token* peek(size_t ahead = 0) const
+        {
+            return _pos + ahead < _tokens.size() ? &_tokens[_pos + ahead] : nullptr;
+        }
+
+        bool accept(const std::string& punct)
+        {
+            const Token* t = peek();
+            if (!t || t->kind != Token::Kind::Punct || t->text != punct) return false;
+            ++_pos;
+            return true;
+        }
+
+        void expect(const std::string& punct)
+        {
+            if (!accept(punct)) throw std::runtime_error("expected '" + punct + "'" + found());
+        }
+
+        void expect_end() const
+        {
+            if (peek()) throw std::runtime_error("unexpected '" + peek()->text + "'");
+        }
+
+        std::string found() const
+        {
+            return peek() ? " before '" + peek()->text + "'" : " at the end of the clause";
+        }
+
+        std::vector<Literal> literals()
+        {
+            std::vector<Literal> result{literal()};
+            while (accept(","))
+                result.push_back(literal());
+            if (peek() && peek()->text == ";") throw std::runtime_error("disjunctions are not supported, write one rule per alternative");
+            return result;
+        }
+
+        Literal literal()
+        {
+            const Token* t = peek();
+            if (!t) throw std::runtime_error("expected a literal at the end of the clause");
+
+            const bool not_keyword = t->kind == Token::Kind::Atom && t->text == "not" && peek(1) && (peek(1)->kind == Token::Kind::Atom || peek(1)->text == "(");
+            if (accept("\\+") || accept("!") || not_keyword)
+            {
+                if (not_keyword) ++_pos;
+                const bool parenthesized = accept("(");
+                Literal    result        = literal();
+                if (parenthesized) expect(")");
+                if (result.negated || result.unequal || result.contradiction) throw std::runtime_error("only predicates can be negated");
+                result.negated = true;
+                return result;
+            }
+
+            // Inequality between two terms.
+            if (t->kind != Token::Kind::Atom || !(peek(1) && peek(1)->text == "("))
+            {
+                if (peek(1) && (peek(1)->text == "!=" || peek(1)->text == "\\=" || peek(1)->text == "\\=="))
+                {
+                    Literal result;
+                    result.unequal = true;
+                    result.subject = term();
+                    ++_pos;
+                    result.object = term();
+                    return result;
+                }
+                if (peek(1) && peek(1)->text == "=") throw std::runtime_error("equality of terms is not supported");
+            }
+
+            if (t->kind != Token::Kind::Atom) throw std::runtime_error("expected a predicate" + found());
+            const std::string name = t->text;
+            ++_pos;
+
+            std::vector<std::string> args;
+            if (accept("("))
+            {
+                if (!accept(")"))
+                {
+                    args.push_back(term());
+                    while (accept(","))
+                        args.push_back(term());
+                    expect(")");
+                }
+            }
+
+            Literal result;
+            if (args.empty() && (name == "contradiction" || name == "false"))
+                result.contradiction = true;
+            else if (args.size() == 1)
+            {
+                result.subject   = args[0];
+                result.predicate = "~";
+                result.object    = quote(name);
+            }
+            else if (args.size() == 2)
+            {
+                result.subject   = args[0];
+                result.predicate = quote(name);
+                result.object    = args[1];
+            }
+            else if (args.size() == 3 && name == "fact")
+            {
+                result.subject   = args[0];
+                result.predicate = args[1];
+                result.object    = args[2];
+            }
+            else
+                throw std::runtime_error("predicate " + name + "/" + std::to_string(args.size()) + " has no zelph equivalent, use unary or binary predicates or fact/3");
+            result.ground = _ground;
+            return result;
+        }
+
+        std::string term()
+        {
+            const Token* t = peek();
+            if (!t || t->kind == Token::Kind::Punct) throw std::runtime_error("expected a term" + found());
+            ++_pos;
+            if (peek() && peek()->text == "(") throw std::runtime_error("compound terms such as " + t->text + "(...) are not supported");
+
+            if (t->kind != Token::Kind::Var) return quote(t->text);
+
+            _ground = false;
+            if (t->text == "_") return "_anon" + std::to_string(++_anonymous);
+            if (t->text.size() == 1 || t->text[0] == '_') return t->text;
+            return "_" + t->text;
+        }
+
+        static std::string quote(const std::string& name)
+        {
+            if (name.find_first_of("\"\n") != std::string::npos) throw std::runtime_error("names must not contain double quotes or line breaks");
+            return "\"" + name + "\"";
+        }
+
+        static std::string zelph(const Literal& l)
+        {
+            if (l.unequal) return l.subject + " != " + l.object;
+            const std::string fact = l.subject + " " + l.predicate + " " + l.object;
+            return l.negated ? "¬(" + fact + ")" : fact;
+        }
+
+        const std::vector<Token>& _tokens;
+        size_t                    _pos{0};
+        size_t                    _anonymous{0};
+        bool                      _ground{true};
+    };
+}
+
+std::vector<DatalogClause> zelph::io::translate_datalog(std::istream& in)
+{
+    const std::string text{std::istreambuf_iterator<char>(in), std::istreambuf_iterator<char>()};
+
+    std::vector<DatalogClause> result;
+    std::vector<Token>         tokens;
+    DatalogClause              clause;
+    size_t                     start     = 0; // of the clause's text
+    size_t                     line      = 1;
+    bool                       directive = false;
+
+    auto is_ident = [](const char c)
+    {
+        return std::isalnum(static_cast<unsigned char>(c)) || c == '_' || static_cast<unsigned char>(c) >= 0x80;
+    };
+    auto skip_line = [&](size_t& i)
+    {
+        while (i < text.size() && text[i] != '\n')
+            ++i;
+    };
+
+    size_t i = 0;
+    while (i < text.size())
+    {
+        const char c    = text[i];
+        const char next = i + 1 < text.size() ? text[i + 1] : '\0';
+
+        if (c == '\n') ++line;
+        if (std::isspace(static_cast<unsigned char>(c)))
+        {
+            ++i;
+            continue;
+        }
+        if (c == '%' || (c == '/' && next == '/') || (tokens.empty() && clause.error.empty() && c == '#'))
+        {
+            skip_line(i);
+            continue;
+        }
+        if (c == '/' && next == '*')
+        {
+            const size_t end = text.find("*/", i + 2);
+            const size_t to  = end == std::string::npos ? text.size() : end + 2;
+            line += std::count(text.begin() + i, text.begin() + to, '\n');
+            i = to;
+            continue;
+        }
+
+        if (tokens.empty() && clause.error.empty() && clause.line == 0)
+        {
+            clause.line = line;
+            start       = i;
+            // Soufflé directives (.decl, .input, .output, .type) end with
+            // their line.
+            if (c == '.' && std::isalpha(static_cast<unsigned char>(next)))
+            {
+                skip_line(i);
+                clause.line = 0;
+                continue;
+            }
+            // Prolog directives (:- table fact/3.) end with their dot.
+            directive = c == ':' && next == '-';
+        }
+
+        // The dot that ends a clause.
+        if (c == '.' && (next == '\0' || std::isspace(static_cast<unsigned char>(next)) || next == '%'))
+        {
+            clause.text = text.substr(start, i + 1 - start);
+            if (clause.error.empty() && !directive && !tokens.empty())
+            {
+                try
+                {
+                    clause.statement = ClauseParser(tokens).statement();
+                }
+                catch (const std::exception& ex)
+                {
+                    clause.error = ex.what();
+                }
+            }
+            if (!clause.error.empty() || !clause.statement.empty()) result.push_back(clause);
+            tokens.clear();
+            clause    = DatalogClause{};
+            directive = false;
+            ++i;
+            continue;
+        }
+
+        if (!clause.error.empty() || directive)
+        {
+            ++i; // skip the rest of a malformed clause
+            continue;
+        }
+
+        if (c == '"' || c == '\'')
+        {
+            std::string value;
+            size_t      j = i + 1;
+            for (; j < text.size() && text[j] != c; ++j)
+            {
+                if (text[j] == '\n') ++line;
+                if (text[j] == '\\' && j + 1 < text.size())
+                {
+                    const char e = text[++j];
+                    value += e == 'n' ? '\n' : e == 't' ? '\t' : e;
+                }
+                else
+                    value += text[j];
+            }
+            if (j >= text.size())
+            {
+                clause.error = "unterminated string";
+                i            = j;
+                continue;
+            }
+            tokens.push_back({Token::Kind::String, value});
+            i = j + 1;
+        }
+        else if (std::isdigit(static_cast<unsigned char>(c)) || (c == '-' && std::isdigit(static_cast<unsigned char>(next))))
+        {
+            size_t j = i + 1;
+            while (j < text.size() && (std::isdigit(static_cast<unsigned char>(text[j])) || (text[j] == '.' && j + 1 < text.size() && std::isdigit(static_cast<unsigned char>(text[j + 1])))))
+                ++j;
+            tokens.push_back({Token::Kind::Number, text.substr(i, j - i)});
+            i = j;
+        }
+        else if (is_ident(c))
+        {
+            size_t j = i;
+            while (j < text.size() && is_ident(text[j]))
+                ++j;
+            const bool var = std::isupper(static_cast<unsigned char>(c)) || c == '_';
+            tokens.push_back({var ? Token::Kind::Var : Token::Kind::Atom, text.substr(i, j - i)});
+            i = j;
+        }
+        else
+        {
+            std::string punct;
+            for (const char* p : {"\\==", ":-", "?-", "!=", "\\=", "\\+", "(", ")", ",", ";", "!", "="})
+            {
+                if (text.compare(i, std::strlen(p), p) == 0)
+                {
+                    punct = p;
+                    break;
+                }
+            }
+            if (punct.empty())
+            {
+                clause.error = std::string("unexpected character '") + c + "'";
+                ++i;
+                continue;
+            }
+            tokens.push_back({Token::Kind::Punct, punct});
+            i += punct.size();
+        }
+    }
+
+    if (!directive && (!tokens.empty() || !clause.error.empty()))
+    {
+        clause.text = text.substr(start);
+        if (clause.error.empty()) clause.error = "missing '.' at the end of the clause";
+        result.push_back(clause);
+    }
+    return result;
+}
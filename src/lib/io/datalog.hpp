@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <istream>
+#include <ostream>
+#include <string>
+#include <vector>
+
+namespace zelph::network
+{
+    class Reasoning;
+}
+
+namespace zelph::io
+{
+    enum class DatalogDialect
+    {
+        Souffle, // .dl, with declarations and ! for negation
+        Prolog   // .pl for SWI-Prolog, tabled, with tnot for negation
+    };
+
+    struct DatalogOptions
+    {
+        DatalogDialect dialect{DatalogDialect::Souffle};
+        bool           deduced{false}; // also export the facts deduced by rules
+    };
+
+    struct DatalogCounts
+    {
+        size_t facts{0};
+        size_t rules{0};   // rules written, each as one clause per consequence
+        size_t skipped{0}; // rules that have no Datalog equivalent
+    };
+
+    // Writes the statements (see exportable_facts) and enabled rules as
+    // Datalog clauses over one relation fact(Subject, Predicate, Object),
+    // so that a rule set can be checked against Soufflé or SWI-Prolog:
+    // "berlin "capital of" germany" becomes fact("berlin", "capital of",
+    // "germany"), and a rule a clause with a fact literal per condition
+    // (negated ones with ! or tnot), X != Y as inequality and => ! as
+    // contradiction. Nodes are named in the current language, unnamed
+    // nodes and nested statements as _<node ID>. Deduced facts are left
+    // out unless options.deduced is set, so that the engine derives them
+    // again. A variable that occurs only once, in a negated condition, is
+    // written as _. Rules with nested statements, builtins, negated
+    // conjunctions or other variables that no positive condition binds
+    // are written as comments instead.
+    DatalogCounts export_datalog(const network::Reasoning* n, std::ostream& out, const DatalogOptions& options = {});
+
+    // A clause of a Datalog program as a zelph statement, or the reason
+    // why it has none.
+    struct DatalogClause
+    {
+        size_t      line{0}; // where the clause starts, from 1
+        std::string text;    // the clause as written
+        std::string statement;
+        std::string error;
+    };
+
+    // Translates a Datalog program (Soufflé or Prolog syntax) into zelph
+    // statements, one per clause: p(a, b). becomes "a" "p" "b", a unary
+    // h(a) "a" ~ "h", fact(s, p, o) (as written by export_datalog) "s" "p"
+    // "o", and a rule h :- b1, b2. the rule (b1, b2) => (h). Negated
+    // literals (\+ p, !p, not p) become ¬(...), X != Y (also \= and \==)
+    // stays an inequality, and contradiction or false as head => !.
+    // Variables keep their name if it is a single uppercase letter and get
+    // a leading underscore otherwise; each _ is a variable of its own.
+    // ?- goal. becomes a query. Comments (%, //, /* */), directives
+    // (:- ... and .decl, .input, .output etc.) and empty clauses are
+    // skipped. A clause that cannot be translated, e.g. a fact with
+    // variables or a predicate with more than two arguments, has an error
+    // instead of a statement; the following clauses are read on.
+    std::vector<DatalogClause> translate_datalog(std::istream& in);
+}
@@ -0,0 +1,378 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "fact_store.hpp"
+
+#include "bulk_loader.hpp"
+#include "facts.hpp"
+#include "network/zelph.hpp"
+
+#include <algorithm>
+#include <cstring>
+#include <stdexcept>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    constexpr char     magic[8]   = {'z', 'e', 'l', 'p', 'h', 'f', 's', '\0'};
+    constexpr uint32_t version    = 1;
+    constexpr uint32_t byte_order = 0x01020304;
+
+    std::string name_of(const zelph::network::Zelph* n, const Node nd)
+    {
+        std::string name = n->get_name(nd, "", true);
+        if (name.empty()) name = n->get_core_name(nd);
+        return name;
+    }
+
+    template <typename T>
+    void put(std::ofstream& out, const T& value)
+    {
+        out.write(reinterpret_cast<const char*>(&value), sizeof(T));
+    }
+
+    void write_facts(std::ofstream& out, std::vector<std::array<uint64_t, 3>>& facts, const std::array<int, 3>& order)
+    {
+        for (auto& fact : facts)
+            fact = {fact[order[0]], fact[order[1]], fact[order[2]]};
+        std::sort(facts.begin(), facts.end());
+        for (const auto& fact : facts)
+            out.write(reinterpret_cast<const char*>(fact.data()), sizeof(fact));
+    }
+}
+
+void FactStoreWriter::add(const std::string& subject, const std::string& predicate, const std::string& object)
+{
+    _facts.push_back({id(subject), id(predicate), id(object)});
+}
+
+uint64_t FactStoreWriter::id(const std::string& name)
+{
+    auto it = _ids.find(name);
+    if (it != _ids.end()) return it->second;
+    _names.push_back(name);
+    return _ids.emplace(name, _names.size()).first->second;
+}
+
+uint64_t FactStoreWriter::write(const std::string& file)
+{
+    std::sort(_facts.begin(), _facts.end());
+    _facts.erase(std::unique(_facts.begin(), _facts.end()), _facts.end());
+
+    FactStore::Header header;
+    std::memcpy(header.magic, magic, sizeof(magic));
+    header.version     = version;
+    header.byte_order  = byte_order;
+    header.fact_count  = _facts.size();
+    header.name_count  = _names.size();
+    header.heap_offset = sizeof(FactStore::Header);
+
+    std::vector<uint64_t> heap_offsets;
+    heap_offsets.reserve(_names.size());
+    uint64_t heap_size = 0;
+    for (const std::string& name : _names)
+    {
+        heap_offsets.push_back(header.heap_offset + heap_size);
+        heap_size += sizeof(uint32_t) + name.size();
+    }
+    header.id_table_offset   = header.heap_offset + heap_size;
+    header.name_index_offset = header.id_table_offset + _names.size() * sizeof(uint64_t);
+    header.spo_offset        = header.name_index_offset + _names.size() * sizeof(uint64_t);
+    header.pos_offset        = header.spo_offset + _facts.size() * 3 * sizeof(uint64_t);
+    header.osp_offset        = header.pos_offset + _facts.size() * 3 * sizeof(uint64_t);
+
+    std::ofstream out(file, std::ios::binary | std::ios::trunc);
+    if (!out) throw std::runtime_error("Cannot write fact store '" + file + "'");
+
+    put(out, header);
+    for (const std::string& name : _names)
+    {
+        if (name.size() > UINT32_MAX) throw std::runtime_error("Name too long for a fact store");
+        put(out, static_cast<uint32_t>(name.size()));
+        out.write(name.data(), static_cast<std::streamsize>(name.size()));
+    }
+    for (uint64_t offset : heap_offsets)
+        put(out, offset);
+
+    std::vector<uint64_t> by_name(_names.size());
+    for (uint64_t i = 0; i < by_name.size(); ++i)
+        by_name[i] = i + 1;
+    std::sort(by_name.begin(), by_name.end(), [this](uint64_t a, uint64_t b)
+              { return _names[a - 1] < _names[b - 1]; });
+    for (uint64_t id : by_name)
+        put(out, id);
+
+    // Each pass permutes the tuples from the order of the previous one.
+    write_facts(out, _facts, {0, 1, 2}); // (s, p, o)
+    write_facts(out, _facts, {1, 2, 0}); // (p, o, s)
+    write_facts(out, _facts, {1, 2, 0}); // (o, s, p)
+
+    out.flush();
+    if (!out) throw std::runtime_error("Cannot write fact store '" + file + "'");
+    return header.fact_count;
+}
+
+uint64_t zelph::io::write_fact_store(const network::Zelph* n, const std::string& file, uint64_t& skipped)
+{
+    FactStoreWriter writer;
+    skipped = 0;
+    for (const ExportedFact& fact : exportable_facts(n))
+    {
+        const std::string subject   = name_of(n, fact.subject);
+        const std::string predicate = name_of(n, fact.predicate);
+        const std::string object    = fact.objects.size() == 1 ? name_of(n, *fact.objects.begin()) : "";
+        if (subject.empty() || predicate.empty() || object.empty())
+        {
+            ++skipped;
+            continue;
+        }
+        writer.add(subject, predicate, object);
+    }
+    return writer.write(file);
+}
+
+uint64_t zelph::io::write_fact_store(std::istream& in, const std::string& file)
+{
+    FactStoreWriter writer;
+    std::string     line;
+    uint64_t        number = 0;
+    while (std::getline(in, line))
+    {
+        ++number;
+        if (!line.empty() && line.back() == '\r') line.pop_back();
+        const size_t first = line.find_first_not_of(" \t");
+        if (first == std::string::npos || line[first] == '#') continue;
+
+        std::vector<std::string> fields;
+        try
+        {
+            fields = split_bulk_fields(line);
+        }
+        catch (const std::exception& ex)
+        {
+            throw std::runtime_error("line " + std::to_string(number) + ": " + ex.what());
+        }
+        if (fields.size() != 3 || fields[0].empty() || fields[1].empty() || fields[2].empty())
+            throw std::runtime_error("line " + std::to_string(number) + ": expected subject, predicate and object, got '" + line + "'");
+        writer.add(fields[0], fields[1], fields[2]);
+    }
+    return writer.write(file);
+}
+
+uint64_t zelph::io::import_from_store(network::Zelph* n, const FactStore& store, const std::string& subject, const std::string& predicate, const std::string& object)
+{
+    std::unordered_map<std::string, Node> nodes;
+    auto                                  node = [&](const std::string& name)
+    {
+        auto it = nodes.find(name);
+        if (it != nodes.end()) return it->second;
+        Node nd = n->get_node(name, n->lang());
+        if (nd == 0) nd = n->get_core_node(name);
+        if (nd == 0) nd = n->node(name, n->lang());
+        return nodes.emplace(name, nd).first->second;
+    };
+
+    uint64_t count = 0;
+    store.match(subject, predicate, object, [&](const StoredFact& fact)
+                {
+        n->fact(node(fact.subject), node(fact.predicate), {node(fact.object)});
+        ++count;
+        return true; });
+    return count;
+}
+
+FactStore::FactStore(std::string file, const size_t cache_bytes)
+    : _file(std::move(file))
+    , _capacity_pages(std::max<size_t>(1, cache_bytes / page_size))
+    , _in(_file, std::ios::binary)
+{
+    if (!_in) throw std::runtime_error("Cannot open fact store '" + _file + "'");
+
+    _in.read(reinterpret_cast<char*>(&_header), sizeof(_header));
+    if (!_in || std::memcmp(_header.magic, magic, sizeof(magic)) != 0)
+        throw std::runtime_error("'" + _file + "' is not a fact store");
+    if (_header.byte_order != byte_order)
+        throw std::runtime_error("Fact store '" + _file + "' was written on a machine with a different byte order");
+    if (_header.version != version)
+        throw std::runtime_error("Fact store '" + _file + "' has unsupported format version " + std::to_string(_header.version));
+}
+
+FactStoreStats FactStore::stats() const
+{
+    std::lock_guard<std::mutex> lock(_mtx);
+    return {_header.fact_count, _header.name_count, _pages.size(), _capacity_pages, page_size, _hits, _misses};
+}
+
+void FactStore::read(uint64_t offset, void* out, size_t size) const
+{
+    std::lock_guard<std::mutex> lock(_mtx);
+
+    char* dst = static_cast<char*>(out);
+    while (size > 0)
+    {
+        const uint64_t page  = offset / page_size;
+        const size_t   start = static_cast<size_t>(offset % page_size);
+
+        auto it = _page_index.find(page);
+        if (it != _page_index.end())
+        {
+            ++_hits;
+            _pages.splice(_pages.begin(), _pages, it->second);
+        }
+        else
+        {
+            ++_misses;
+            std::vector<char> data(page_size);
+            _in.clear();
+            _in.seekg(static_cast<std::streamoff>(page * page_size));
+            _in.read(data.data(), static_cast<std::streamsize>(page_size));
+            data.resize(static_cast<size_t>(_in.gcount()));
+
+            _pages.emplace_front(page, std::move(data));
+            _page_index[page] = _pages.begin();
+            if (_pages.size() > _capacity_pages)
+            {
+                _page_index.erase(_pages.back().first);
+                _pages.pop_back();
+            }
+        }
+
+        const std::vector<char>& data = _pages.front().second;
+        if (start >= data.size()) throw std::runtime_error("Fact store '" + _file + "' is truncated");
+
+        const size_t n = std::min(size, data.size() - start);
+        std::memcpy(dst, data.data() + start, n);
+        dst += n;
+        offset += n;
+        size -= n;
+    }
+}
+
+uint64_t FactStore::read_u64(const uint64_t offset) const
+{
+    uint64_t value;
+    read(offset, &value, sizeof(value));
+    return value;
+}
+
+std::string FactStore::name(const uint64_t id) const
+{
+    const uint64_t offset = read_u64(_header.id_table_offset + (id - 1) * sizeof(uint64_t));
+    uint32_t       size;
+    read(offset, &size, sizeof(size));
+    std::string result(size, '\0');
+    if (size > 0) read(offset + sizeof(size), result.data(), size);
+    return result;
+}
+
+uint64_t FactStore::find(const std::string& name) const
+{
+    uint64_t low = 0, high = _header.name_count;
+    while (low < high)
+    {
+        const uint64_t    mid       = low + (high - low) / 2;
+        const uint64_t    id        = read_u64(_header.name_index_offset + mid * sizeof(uint64_t));
+        const std::string candidate = this->name(id);
+        if (candidate == name) return id;
+        if (candidate < name)
+            low = mid + 1;
+        else
+            high = mid;
+    }
+    return 0;
+}
+
+std::array<uint64_t, 3> FactStore::entry(const uint64_t index_offset, const uint64_t i) const
+{
+    std::array<uint64_t, 3> result;
+    read(index_offset + i * sizeof(result), result.data(), sizeof(result));
+    return result;
+}
+
+void FactStore::match(const std::string& subject, const std::string& predicate, const std::string& object, const std::function<bool(const StoredFact&)>& visit) const
+{
+    uint64_t s = 0, p = 0, o = 0;
+    if (!subject.empty() && (s = find(subject)) == 0) return;
+    if (!predicate.empty() && (p = find(predicate)) == 0) return;
+    if (!object.empty() && (o = find(object)) == 0) return;
+
+    // The index whose order starts with the bound parts, the key of the
+    // pattern in that order and, per position of a stored tuple, the part
+    // of the fact it holds (0 subject, 1 predicate, 2 object).
+    uint64_t                index = _header.spo_offset;
+    std::array<uint64_t, 3> key{s, p, o};
+    std::array<int, 3>      parts{0, 1, 2};
+    if (p && !s)
+    {
+        index = _header.pos_offset;
+        key   = {p, o, 0};
+        parts = {1, 2, 0};
+    }
+    else if (o && !p)
+    {
+        index = _header.osp_offset;
+        key   = {o, s, 0};
+        parts = {2, 0, 1};
+    }
+    const size_t prefix = key[0] == 0 ? 0 : key[1] == 0 ? 1 : key[2] == 0 ? 2 : 3;
+
+    auto compare = [&](const std::array<uint64_t, 3>& e)
+    {
+        for (size_t i = 0; i < prefix; ++i)
+            if (e[i] != key[i]) return e[i] < key[i] ? -1 : 1;
+        return 0;
+    };
+
+    uint64_t low = 0, high = _header.fact_count;
+    while (low < high)
+    {
+        const uint64_t mid = low + (high - low) / 2;
+        if (compare(entry(index, mid)) < 0)
+            low = mid + 1;
+        else
+            high = mid;
+    }
+
+    std::unordered_map<uint64_t, std::string> names;
+    auto                                      name_of_id = [&](uint64_t id) -> const std::string&
+    {
+        auto it = names.find(id);
+        if (it == names.end()) it = names.emplace(id, this->name(id)).first;
+        return it->second;
+    };
+
+    for (uint64_t i = low; i < _header.fact_count; ++i)
+    {
+        const std::array<uint64_t, 3> e = entry(index, i);
+        if (compare(e) != 0) break;
+
+        std::array<uint64_t, 3> fact;
+        for (size_t k = 0; k < 3; ++k)
+            fact[parts[k]] = e[k];
+        if (!visit({name_of_id(fact[0]), name_of_id(fact[1]), name_of_id(fact[2])})) break;
+    }
+}
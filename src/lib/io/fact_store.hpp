@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <array>
+#include <cstdint>
+#include <fstream>
+#include <functional>
+#include <istream>
+#include <list>
+#include <mutex>
+#include <string>
+#include <unordered_map>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    struct StoredFact
+    {
+        std::string subject;
+        std::string predicate;
+        std::string object;
+    };
+
+    // Collects named facts and writes them as a fact store file (see
+    // FactStore). Only the node IDs of the facts are kept in memory, 24
+    // bytes per fact, plus each distinct name once; the network itself is
+    // not needed. Duplicate facts are written once.
+    class FactStoreWriter
+    {
+    public:
+        void     add(const std::string& subject, const std::string& predicate, const std::string& object);
+        uint64_t write(const std::string& file); // returns the number of facts written
+
+    private:
+        uint64_t id(const std::string& name);
+
+        std::unordered_map<std::string, uint64_t> _ids;
+        std::vector<std::string>                  _names; // _names[id - 1]
+        std::vector<std::array<uint64_t, 3>>      _facts;
+    };
+
+    // Writes the facts of the network with a single object whose subject,
+    // predicate and object all have names (as the exporters, see
+    // exportable_facts) to a fact store file. skipped receives the number of
+    // facts left out because a part has no name.
+    uint64_t write_fact_store(const network::Zelph* n, const std::string& file, uint64_t& skipped);
+
+    // Writes the facts of a plain fact file (the format of BulkLoader) to a
+    // fact store file without loading them into a network. Malformed lines
+    // are thrown as std::runtime_error naming the line.
+    uint64_t write_fact_store(std::istream& in, const std::string& file);
+
+    struct FactStoreStats
+    {
+        uint64_t facts{0};
+        uint64_t names{0};
+        uint64_t cached_pages{0};
+        uint64_t capacity_pages{0};
+        uint64_t page_size{0};
+        uint64_t hits{0};
+        uint64_t misses{0};
+    };
+
+    // Read-only, disk-backed set of facts for networks larger than RAM (see
+    // .store). The file holds a name table and each fact three times, sorted
+    // by subject, by predicate and by object, so any pattern with a bound
+    // part is answered by a binary search and a scan of adjacent entries.
+    // The file is never loaded as a whole: it is read in pages of
+    // page_size bytes, of which the cache_bytes most recently used stay in
+    // memory (an LRU cache). Opening reads only the header. Safe to use from
+    // several threads. Errors (missing file, wrong format) are thrown as
+    // std::runtime_error.
+    class FactStore
+    {
+    public:
+        static constexpr uint64_t page_size = 64 * 1024;
+
+        FactStore(std::string file, size_t cache_bytes);
+
+        FactStore(const FactStore&)            = delete;
+        FactStore& operator=(const FactStore&) = delete;
+
+        const std::string& file() const { return _file; }
+        uint64_t           fact_count() const { return _header.fact_count; }
+        FactStoreStats     stats() const;
+
+        // Calls visit for each fact matching the pattern, in the order of the
+        // index used; an empty name matches anything. visit returns false to
+        // stop. Names unknown to the store match no fact.
+        void match(const std::string& subject, const std::string& predicate, const std::string& object, const std::function<bool(const StoredFact&)>& visit) const;
+
+        struct Header
+        {
+            char     magic[8]{};
+            uint32_t version{0};
+            uint32_t byte_order{0};
+            uint64_t fact_count{0};
+            uint64_t name_count{0};
+            uint64_t heap_offset{0};       // names, each a uint32_t length and its bytes
+            uint64_t id_table_offset{0};   // per ID, the heap offset of its name
+            uint64_t name_index_offset{0}; // the IDs ordered by name
+            uint64_t spo_offset{0};        // the facts as (subject, predicate, object), sorted
+            uint64_t pos_offset{0};        // as (predicate, object, subject), sorted
+            uint64_t osp_offset{0};        // as (object, subject, predicate), sorted
+        };
+
+    private:
+        void                    read(uint64_t offset, void* out, size_t size) const;
+        uint64_t                read_u64(uint64_t offset) const;
+        std::string             name(uint64_t id) const;
+        uint64_t                find(const std::string& name) const; // 0 if unknown
+        std::array<uint64_t, 3> entry(uint64_t index_offset, uint64_t i) const;
+
+        std::string _file;
+        Header      _header;
+        size_t      _capacity_pages;
+
+        mutable std::mutex                                               _mtx; // guards the members below
+        mutable std::ifstream                                            _in;
+        mutable std::list<std::pair<uint64_t, std::vector<char>>>        _pages; // most recently used first
+        mutable std::unordered_map<uint64_t, decltype(_pages)::iterator> _page_index;
+        mutable uint64_t                                                 _hits{0};
+        mutable uint64_t                                                 _misses{0};
+    };
+
+    // States the facts of the store matching the pattern (see
+    // FactStore::match) in the network, naming nodes in the current
+    // language, so that rules can work on a region of a store too large to
+    // load. Returns the number of facts matched.
+    uint64_t import_from_store(network::Zelph* n, const FactStore& store, const std::string& subject, const std::string& predicate, const std::string& object);
+}
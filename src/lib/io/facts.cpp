@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "facts.hpp"
+
+#include "network/zelph.hpp"
+
+#include <algorithm>
+#include <functional>
+#include <unordered_map>
+#include <unordered_set>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+std::vector<ExportedFact> zelph::io::exportable_facts(const network::Zelph* n)
+{
+    // Collect all facts first: a fact whose subject or objects are facts
+    // themselves (nested statements) is only exportable if none of them
+    // contains a variable, which requires the components of every fact.
+    std::unordered_map<Node, ExportedFact> facts;
+    for (Node predicate : n->get_sources(n->core.IsA, n->core.RelationTypeCategory, true))
+    {
+        if (predicate == n->core.Causes) continue; // rules
+
+        for (Node relation : n->get_left(predicate))
+        {
+            // A fact about the predicate itself (knows ~ ->) is linked to it
+            // as well, but as subject; it is collected via its own predicate.
+            network::adjacency_set objects;
+            const Node             subject = n->parse_fact(relation, objects);
+            if (subject == 0 || subject == predicate) continue;
+            facts.emplace(relation, ExportedFact{relation, subject, predicate, std::move(objects)});
+        }
+    }
+
+    // Elements of a rule's condition set are patterns, not statements.
+    std::unordered_set<Node> rule_terms;
+    for (Node rule : n->get_rules())
+    {
+        network::adjacency_set deductions;
+        rule_terms.insert(n->parse_fact(rule, deductions));
+    }
+
+    std::unordered_map<Node, bool> has_var;
+    std::function<bool(Node, int)> contains_var = [&](Node nd, int depth) -> bool
+    {
+        if (network::Zelph::is_var(nd)) return true;
+        auto fact = facts.find(nd);
+        if (fact == facts.end() || depth > 64) return false;
+        if (auto cached = has_var.find(nd); cached != has_var.end()) return cached->second;
+
+        bool result = contains_var(fact->second.subject, depth + 1);
+        for (auto it = fact->second.objects.begin(); !result && it != fact->second.objects.end(); ++it)
+            result = contains_var(*it, depth + 1);
+        has_var[nd] = result;
+        return result;
+    };
+
+    std::vector<ExportedFact> result;
+    for (const auto& [relation, fact] : facts)
+    {
+        if (fact.objects.count(n->core.RelationTypeCategory) == 1) continue; // predicate declarations
+        if (fact.predicate == n->core.PartOf && std::any_of(fact.objects.begin(), fact.objects.end(), [&](Node o)
+                                                             { return rule_terms.count(o) == 1; }))
+            continue;
+        if (contains_var(relation, 0)) continue;
+        result.push_back(fact);
+    }
+    return result;
+}
@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "graph_export.hpp"
+
+#include "network/zelph.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <map>
+#include <string>
+#include <unordered_set>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    bool contains_var(const zelph::network::Zelph* n, Node nd, int depth)
+    {
+        if (zelph::network::Zelph::is_var(nd)) return true;
+        if (!zelph::network::Zelph::is_hash(nd) || depth > 8) return false;
+
+        zelph::network::adjacency_set objects;
+        const Node                    subject = n->parse_fact(nd, objects);
+        if (subject == 0 || subject == nd) return false;
+        if (contains_var(n, subject, depth + 1)) return true;
+        return std::any_of(objects.begin(), objects.end(), [&](Node o)
+                           { return o != nd && contains_var(n, o, depth + 1); });
+    }
+
+    std::string label_of(const zelph::network::Zelph* n, Node nd)
+    {
+        std::string result;
+        zelph::string::node_to_string(n, result, n->lang(), nd);
+        result = zelph::string::unmark_identifiers(result);
+        return result.empty() ? std::to_string(nd) : result;
+    }
+
+    // Nodes in order of first appearance, each with its label.
+    std::vector<std::pair<Node, std::string>> nodes_of(const zelph::network::Zelph* n, Node root, const std::vector<GraphEdge>& edges)
+    {
+        std::vector<std::pair<Node, std::string>> result;
+        std::unordered_set<Node>                  seen;
+        auto                                      add = [&](Node nd)
+        {
+            if (seen.insert(nd).second) result.emplace_back(nd, label_of(n, nd));
+        };
+
+        add(root);
+        for (const auto& edge : edges)
+        {
+            add(edge.subject);
+            add(edge.object);
+        }
+        return result;
+    }
+
+    std::string dot_quote(const std::string& s)
+    {
+        std::string result = "\"";
+        for (char c : s)
+        {
+            if (c == '"' || c == '\\') result += '\\';
+            result += c == '\n' ? ' ' : c;
+        }
+        return result + "\"";
+    }
+
+    std::string mermaid_quote(const std::string& s)
+    {
+        std::string result = "\"";
+        for (char c : s)
+        {
+            if (c == '"')
+                result += "#quot;";
+            else
+                result += c == '\n' ? ' ' : c;
+        }
+        return result + "\"";
+    }
+}
+
+std::vector<GraphEdge> zelph::io::neighborhood(const network::Zelph* n, const Node root, const int depth, const size_t max_edges)
+{
+    return neighborhood(n, std::vector<Node>{root}, depth, max_edges, {});
+}
+
+std::vector<GraphEdge> zelph::io::neighborhood(const network::Zelph*                      n,
+                                               const std::vector<Node>&                   roots,
+                                               const int                                  depth,
+                                               const size_t                               max_edges,
+                                               const std::function<bool(network::Node)>& follow)
+{
+    std::vector<GraphEdge>   edges;
+    std::unordered_set<Node> visited_relations;
+    std::unordered_set<Node> expanded(roots.begin(), roots.end());
+    std::vector<Node>        frontier(expanded.begin(), expanded.end());
+    std::sort(frontier.begin(), frontier.end());
+
+    for (int level = 0; level < depth && !frontier.empty(); ++level)
+    {
+        std::vector<Node> next;
+        for (Node nd : frontier)
+        {
+            network::adjacency_set candidates = n->get_left(nd);
+            for (Node r : n->get_right(nd))
+                candidates.insert(r);
+
+            std::vector<Node> relations(candidates.begin(), candidates.end());
+            std::sort(relations.begin(), relations.end());
+
+            for (Node relation : relations)
+            {
+                if (!network::Zelph::is_hash(relation) || !visited_relations.insert(relation).second) continue;
+
+                const Node predicate = n->parse_relation(relation);
+                if (predicate == 0 || predicate == n->core.Causes) continue;
+                if (follow && !follow(predicate)) continue;
+
+                network::adjacency_set objects;
+                const Node             subject = n->parse_fact(relation, objects);
+                if (subject == 0 || objects.count(n->core.RelationTypeCategory) == 1) continue;
+                if (subject != nd && objects.count(nd) == 0) continue; // nd is the predicate
+                if (contains_var(n, relation, 0)) continue;
+
+                std::vector<Node> sorted_objects(objects.begin(), objects.end());
+                std::sort(sorted_objects.begin(), sorted_objects.end());
+                for (Node object : sorted_objects)
+                {
+                    if (edges.size() == max_edges) return edges;
+                    edges.push_back({relation, subject, predicate, object});
+                    if (expanded.insert(object).second) next.push_back(object);
+                }
+                if (expanded.insert(subject).second) next.push_back(subject);
+            }
+        }
+        frontier = std::move(next);
+    }
+
+    return edges;
+}
+
+size_t zelph::io::export_dot(const network::Zelph*                      n,
+                             std::ostream&                              out,
+                             const Node                                 root,
+                             const int                                  depth,
+                             const std::function<bool(network::Node)>& is_deduced)
+{
+    const auto edges = neighborhood(n, root, depth);
+
+    out << "digraph " << dot_quote(label_of(n, root)) << " {\n";
+    out << "  rankdir=LR;\n";
+    out << "  node [shape=box, style=rounded];\n";
+    for (const auto& [nd, label] : nodes_of(n, root, edges))
+    {
+        out << "  n" << nd << " [label=" << dot_quote(label) << (nd == root ? ", penwidth=2" : "") << "];\n";
+    }
+    for (const auto& edge : edges)
+    {
+        const bool deduced = is_deduced && is_deduced(edge.relation);
+        out << "  n" << edge.subject << " -> n" << edge.object << " [label=" << dot_quote(label_of(n, edge.predicate))
+            << (deduced ? ", style=dashed" : "") << "];\n";
+    }
+    out << "}\n";
+
+    return edges.size();
+}
+
+size_t zelph::io::export_mermaid(const network::Zelph*                      n,
+                                 std::ostream&                              out,
+                                 const Node                                 root,
+                                 const int                                  depth,
+                                 const std::function<bool(network::Node)>& is_deduced)
+{
+    const auto edges = neighborhood(n, root, depth);
+
+    out << "flowchart LR\n";
+    for (const auto& [nd, label] : nodes_of(n, root, edges))
+    {
+        out << "  n" << nd << "[" << mermaid_quote(label) << "]\n";
+    }
+    for (const auto& edge : edges)
+    {
+        const bool deduced = is_deduced && is_deduced(edge.relation);
+        out << "  n" << edge.subject << (deduced ? " -.->|" : " -->|") << mermaid_quote(label_of(n, edge.predicate)) << "| n" << edge.object << "\n";
+    }
+    out << "  style n" << root << " stroke-width:3px\n";
+
+    return edges.size();
+}
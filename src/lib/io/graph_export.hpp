@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <functional>
+#include <ostream>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    struct GraphEdge
+    {
+        network::Node relation;
+        network::Node subject;
+        network::Node predicate;
+        network::Node object;
+    };
+
+    // The facts within `depth` steps of root: depth 1 yields the facts that
+    // have root as subject or object, each further step adds the facts of
+    // the nodes reached so far. A fact with several objects yields one edge
+    // per object. Rules, predicate declarations (P ~ ->) and facts
+    // containing variables are left out, as are facts that only mention a
+    // node as their predicate (e.g. every ~ fact for root ~). Stops after
+    // max_edges edges, so the neighborhood of a hub stays readable.
+    std::vector<GraphEdge> neighborhood(const network::Zelph* n, network::Node root, int depth, size_t max_edges = 1000);
+
+    // The same for several roots at once, following only the facts whose
+    // predicate is accepted by follow (all if empty).
+    std::vector<GraphEdge> neighborhood(const network::Zelph*                      n,
+                                        const std::vector<network::Node>&          roots,
+                                        int                                        depth,
+                                        size_t                                     max_edges,
+                                        const std::function<bool(network::Node)>& follow);
+
+    // Render the neighborhood of root (see above) as a GraphViz digraph or a
+    // Mermaid flowchart. Nodes are labelled by their name in the current
+    // language, edges by their predicate; root is emphasized. Edges for which
+    // is_deduced returns true are drawn dashed, asserted ones solid.
+    // Return the number of edges written.
+    size_t export_dot(const network::Zelph*                      n,
+                      std::ostream&                              out,
+                      network::Node                              root,
+                      int                                        depth,
+                      const std::function<bool(network::Node)>& is_deduced = {});
+    size_t export_mermaid(const network::Zelph*                      n,
+                          std::ostream&                              out,
+                          network::Node                              root,
+                          int                                        depth,
+                          const std::function<bool(network::Node)>& is_deduced = {});
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "graph_stats.hpp"
+
+#include "facts.hpp"
+#include "network/zelph.hpp"
+
+#include <algorithm>
+#include <unordered_map>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    // Weakly connected components by union-find with path halving.
+    class Components
+    {
+    public:
+        void join(const Node a, const Node b)
+        {
+            const Node ra = find(a);
+            const Node rb = find(b);
+            if (ra != rb) _parent[ra] = rb;
+        }
+
+        uint64_t count()
+        {
+            uint64_t result = 0;
+            for (const auto& [node, parent] : _parent)
+                if (node == parent) ++result;
+            return result;
+        }
+
+    private:
+        Node find(Node x)
+        {
+            _parent.try_emplace(x, x);
+            while (_parent[x] != x)
+            {
+                _parent[x] = _parent[_parent[x]];
+                x          = _parent[x];
+            }
+            return x;
+        }
+
+        std::unordered_map<Node, Node> _parent;
+    };
+}
+
+GraphStats zelph::io::graph_stats(const network::Zelph* n)
+{
+    GraphStats stats;
+    stats.nodes = n->count();
+
+    std::unordered_map<Node, uint64_t> relations;
+    std::unordered_map<Node, uint64_t> in_degree;
+    std::unordered_map<Node, uint64_t> out_degree;
+    Components                         components;
+
+    for (const ExportedFact& fact : exportable_facts(n))
+    {
+        ++stats.statements;
+        ++relations[fact.predicate];
+        out_degree.try_emplace(fact.subject, 0);
+        in_degree.try_emplace(fact.subject, 0);
+        for (const Node object : fact.objects)
+        {
+            ++stats.edges;
+            ++out_degree[fact.subject];
+            ++in_degree[object];
+            out_degree.try_emplace(object, 0);
+            components.join(fact.subject, object);
+        }
+        if (fact.objects.empty()) components.join(fact.subject, fact.subject);
+    }
+
+    stats.components = components.count();
+
+    stats.relations.assign(relations.begin(), relations.end());
+    std::sort(stats.relations.begin(), stats.relations.end(), [](const auto& a, const auto& b)
+              { return a.second != b.second ? a.second > b.second : a.first < b.first; });
+
+    for (const auto& [node, degree] : in_degree)
+        ++stats.in_degrees[degree];
+    for (const auto& [node, degree] : out_degree)
+        ++stats.out_degrees[degree];
+
+    return stats;
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <cstdint>
+#include <map>
+#include <utility>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // Shape of the network (see .graph-stats), for checking imports and
+    // planning capacity. An edge is one subject-object pair of a statement
+    // (see exportable_facts), so a statement with two objects counts
+    // twice. Degrees and components cover the nodes that occur as subject
+    // or object of a statement; components ignore edge direction.
+    struct GraphStats
+    {
+        uint64_t nodes{0};
+        uint64_t statements{0};
+        uint64_t edges{0};
+        uint64_t components{0};
+
+        // Predicates with their number of statements, most frequent first.
+        std::vector<std::pair<network::Node, uint64_t>> relations;
+
+        // Degree -> number of nodes with that degree.
+        std::map<uint64_t, uint64_t> in_degrees;
+        std::map<uint64_t, uint64_t> out_degrees;
+    };
+
+    GraphStats graph_stats(const network::Zelph* n);
+}
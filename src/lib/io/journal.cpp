@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "journal.hpp"
+
+#include <algorithm>
+#include <stdexcept>
+
+using namespace zelph::io;
+
+Journal::Journal(const std::filesystem::path& file)
+    : _file(file)
+    , _out(file, std::ios::binary | std::ios::app)
+{
+    if (!_out) throw std::runtime_error("Cannot open journal " + file.string());
+}
+
+void Journal::line(const std::string& text)
+{
+    write(text);
+}
+
+// A deduction is one comment line; line breaks in the rendered nodes would
+// end the comment early.
+void Journal::deduction(const std::string& fact, const std::string& rule)
+{
+    std::string text = "# deduced: " + fact;
+    if (!rule.empty()) text += "  <=  " + rule;
+    std::replace(text.begin(), text.end(), '\n', ' ');
+    std::replace(text.begin(), text.end(), '\r', ' ');
+    write(text);
+}
+
+void Journal::write(const std::string& text)
+{
+    std::lock_guard<std::mutex> lock(_mtx);
+    _out << text << '\n';
+    _out.flush();
+    if (!_out) throw std::runtime_error("Cannot write to journal " + _file.string());
+}
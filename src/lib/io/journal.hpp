@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <filesystem>
+#include <fstream>
+#include <mutex>
+#include <string>
+
+namespace zelph::io
+{
+    // Append-only log of the input accepted by a zelph instance (see
+    // .journal). Each accepted line is written verbatim, so the journal is
+    // itself a zelph script and the network can be rebuilt by processing
+    // it again. Deduced facts are written as comments, which replay skips
+    // (the rules deduce them again) but which keep an audit trail of how
+    // the knowledge base evolved. Every entry is flushed at once, so a
+    // crash loses at most the line being processed.
+    class Journal
+    {
+    public:
+        // Opens file for appending, creating it if needed. Throws
+        // std::runtime_error if it cannot be opened.
+        explicit Journal(const std::filesystem::path& file);
+
+        void line(const std::string& text);
+        void deduction(const std::string& fact, const std::string& rule);
+
+        const std::filesystem::path& file() const { return _file; }
+
+    private:
+        void write(const std::string& text);
+
+        std::filesystem::path _file;
+        std::ofstream         _out;
+        std::mutex            _mtx;
+    };
+}
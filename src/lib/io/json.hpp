@@ -0,0 +1,283 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <cctype>
+#include <cstdint>
+#include <stdexcept>
+#include <string>
+#include <utility>
+#include <vector>
+
+namespace zelph::io
+{
+    // Minimal JSON document model and parser shared by the JSON based
+    // importers (JSON-LD, Neo4j). Numbers keep their lexical form.
+    struct JsonValue
+    {
+        enum class Type
+        {
+            Null,
+            Bool,
+            Number,
+            String,
+            Array,
+            Object
+        };
+
+        Type                                           type{Type::Null};
+        std::string                                    text; // string contents, or lexical form of numbers and booleans
+        std::vector<JsonValue>                         items;
+        std::vector<std::pair<std::string, JsonValue>> members; // in document order
+        size_t                                         line{0};
+
+        const JsonValue* find(const std::string& key) const
+        {
+            for (const auto& [k, v] : members)
+                if (k == key) return &v;
+            return nullptr;
+        }
+    };
+
+    class JsonParser
+    {
+    public:
+        // Errors name file_name and the line, counted from first_line.
+        JsonParser(const std::string& source, std::string file_name, const size_t first_line = 1)
+            : _src(source)
+            , _file_name(std::move(file_name))
+            , _line(first_line)
+        {
+        }
+
+        JsonValue parse()
+        {
+            JsonValue result = value();
+            skip_ws();
+            if (_pos < _src.size()) fail("unexpected content after the JSON value");
+            return result;
+        }
+
+    private:
+        const std::string& _src;
+        const std::string  _file_name;
+        size_t             _pos{0};
+        size_t             _line;
+
+        [[noreturn]] void fail(const std::string& message) const
+        {
+            throw std::runtime_error(_file_name + ":" + std::to_string(_line) + ": " + message);
+        }
+
+        char peek() const { return _pos < _src.size() ? _src[_pos] : '\0'; }
+
+        char next()
+        {
+            if (_pos >= _src.size()) fail("unexpected end of input");
+            const char c = _src[_pos++];
+            if (c == '\n') ++_line;
+            return c;
+        }
+
+        void skip_ws()
+        {
+            while (_pos < _src.size() && std::isspace(static_cast<unsigned char>(_src[_pos])))
+                next();
+        }
+
+        void expect(const char c)
+        {
+            skip_ws();
+            if (peek() != c) fail(std::string("expected '") + c + "'");
+            next();
+        }
+
+        JsonValue value()
+        {
+            skip_ws();
+            JsonValue v;
+            v.line = _line;
+            const char c = peek();
+            if (c == '{')
+            {
+                v.type = JsonValue::Type::Object;
+                next();
+                skip_ws();
+                while (peek() != '}')
+                {
+                    if (!v.members.empty()) expect(',');
+                    skip_ws();
+                    if (peek() != '"') fail("expected a member name");
+                    std::string key = string();
+                    expect(':');
+                    v.members.emplace_back(std::move(key), value());
+                    skip_ws();
+                }
+                next();
+            }
+            else if (c == '[')
+            {
+                v.type = JsonValue::Type::Array;
+                next();
+                skip_ws();
+                while (peek() != ']')
+                {
+                    if (!v.items.empty()) expect(',');
+                    v.items.push_back(value());
+                    skip_ws();
+                }
+                next();
+            }
+            else if (c == '"')
+            {
+                v.type = JsonValue::Type::String;
+                v.text = string();
+            }
+            else if (c == '-' || std::isdigit(static_cast<unsigned char>(c)))
+            {
+                v.type = JsonValue::Type::Number;
+                while (std::isdigit(static_cast<unsigned char>(peek())) || peek() == '-' || peek() == '+' || peek() == '.' || peek() == 'e' || peek() == 'E')
+                    v.text += next();
+            }
+            else if (literal("true") || literal("false"))
+            {
+                v.type = JsonValue::Type::Bool;
+                v.text = c == 't' ? "true" : "false";
+            }
+            else if (!literal("null"))
+            {
+                fail(c ? std::string("unexpected character '") + c + "'" : "unexpected end of input");
+            }
+            return v;
+        }
+
+        bool literal(const std::string& word)
+        {
+            if (_src.compare(_pos, word.size(), word) != 0) return false;
+            _pos += word.size();
+            return true;
+        }
+
+        std::string string()
+        {
+            next(); // '"'
+            std::string s;
+            while (true)
+            {
+                const char c = next();
+                if (c == '"') return s;
+                if (c == '\n') fail("unterminated string");
+                if (c != '\\')
+                {
+                    s += c;
+                    continue;
+                }
+                switch (const char e = next())
+                {
+                case 'n': s += '\n'; break;
+                case 't': s += '\t'; break;
+                case 'r': s += '\r'; break;
+                case 'b': s += '\b'; break;
+                case 'f': s += '\f'; break;
+                case 'u': s += utf8(code_point()); break;
+                default: s += e; break; // " \ /
+                }
+            }
+        }
+
+        uint32_t hex4()
+        {
+            uint32_t cp = 0;
+            for (int i = 0; i < 4; ++i)
+            {
+                const char c = next();
+                if (!std::isxdigit(static_cast<unsigned char>(c))) fail("invalid \\u escape");
+                cp = cp * 16 + static_cast<uint32_t>(std::isdigit(static_cast<unsigned char>(c)) ? c - '0' : std::tolower(c) - 'a' + 10);
+            }
+            return cp;
+        }
+
+        uint32_t code_point()
+        {
+            const uint32_t high = hex4();
+            if (high < 0xD800 || high > 0xDBFF) return high;
+            if (next() != '\\' || next() != 'u') fail("unpaired surrogate in \\u escape");
+            return 0x10000 + ((high - 0xD800) << 10) + (hex4() - 0xDC00);
+        }
+
+        static std::string utf8(const uint32_t cp)
+        {
+            std::string s;
+            if (cp < 0x80)
+                s += static_cast<char>(cp);
+            else if (cp < 0x800)
+            {
+                s += static_cast<char>(0xC0 | (cp >> 6));
+                s += static_cast<char>(0x80 | (cp & 0x3F));
+            }
+            else if (cp < 0x10000)
+            {
+                s += static_cast<char>(0xE0 | (cp >> 12));
+                s += static_cast<char>(0x80 | ((cp >> 6) & 0x3F));
+                s += static_cast<char>(0x80 | (cp & 0x3F));
+            }
+            else
+            {
+                s += static_cast<char>(0xF0 | (cp >> 18));
+                s += static_cast<char>(0x80 | ((cp >> 12) & 0x3F));
+                s += static_cast<char>(0x80 | ((cp >> 6) & 0x3F));
+                s += static_cast<char>(0x80 | (cp & 0x3F));
+            }
+            return s;
+        }
+    };
+
+    // s as a JSON string literal, control characters escaped as \u00XX.
+    inline std::string json_quote(const std::string& s)
+    {
+        std::string result = "\"";
+        for (const unsigned char c : s)
+        {
+            if (c == '"' || c == '\\')
+            {
+                result += '\\';
+                result += static_cast<char>(c);
+            }
+            else if (c < 0x20)
+            {
+                static const char hex[] = "0123456789abcdef";
+                result += "\\u00";
+                result += hex[c >> 4];
+                result += hex[c & 0x0F];
+            }
+            else
+            {
+                result += static_cast<char>(c);
+            }
+        }
+        return result + "\"";
+    }
+}
@@ -0,0 +1,478 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "jsonld.hpp"
+#include "json.hpp"
+#include "rdf.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <fstream>
+#include <map>
+#include <sstream>
+#include <unordered_map>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    const std::string rdf_type      = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type";
+    const std::string default_vocab = "urn:zelph:";
+
+    void write_json(std::ostream& out, const JsonValue& v, const std::string& indent)
+    {
+        switch (v.type)
+        {
+        case JsonValue::Type::Null: out << "null"; break;
+        case JsonValue::Type::Bool:
+        case JsonValue::Type::Number: out << v.text; break;
+        case JsonValue::Type::String: out << json_quote(v.text); break;
+        case JsonValue::Type::Array:
+            out << "[";
+            for (size_t i = 0; i < v.items.size(); ++i)
+            {
+                out << (i ? ", " : "");
+                write_json(out, v.items[i], indent);
+            }
+            out << "]";
+            break;
+        case JsonValue::Type::Object:
+            out << "{";
+            for (size_t i = 0; i < v.members.size(); ++i)
+            {
+                out << (i ? ",\n" : "\n") << indent << "  " << json_quote(v.members[i].first) << ": ";
+                write_json(out, v.members[i].second, indent + "  ");
+            }
+            out << (v.members.empty() ? "}" : "\n" + indent + "}");
+            break;
+        }
+    }
+
+    bool is_absolute(const std::string& iri)
+    {
+        const size_t colon = iri.find(':');
+        return colon != std::string::npos && colon > 0 && iri.rfind("_:", 0) != 0;
+    }
+
+    // The active context: term definitions (prefixes are terms as well)
+    // plus @vocab and @base.
+    class Context
+    {
+    public:
+        using Fail = std::function<void(size_t line, const std::string&)>;
+
+        void merge(const JsonValue& ctx, const Fail& fail)
+        {
+            switch (ctx.type)
+            {
+            case JsonValue::Type::Null: *this = Context{}; break;
+            case JsonValue::Type::Array:
+                for (const auto& item : ctx.items) merge(item, fail);
+                break;
+            case JsonValue::Type::String: fail(ctx.line, "remote context '" + ctx.text + "' is not supported, please inline it"); break;
+            case JsonValue::Type::Object:
+                for (const auto& [key, def] : ctx.members)
+                {
+                    if (key == "@vocab") _vocab = def.type == JsonValue::Type::String ? expand(def.text, true) : "";
+                    else if (key == "@base") _base = def.type == JsonValue::Type::String ? def.text : "";
+                    else if (key.starts_with("@")) continue; // @version, @language, ...
+                    else if (def.type == JsonValue::Type::String) define(key, def.text, false);
+                    else if (def.type == JsonValue::Type::Null) _terms.erase(key);
+                    else if (def.type == JsonValue::Type::Object)
+                    {
+                        const JsonValue* id   = def.find("@id");
+                        const JsonValue* type = def.find("@type");
+                        define(key, id && id->type == JsonValue::Type::String ? id->text : key,
+                               type && type->type == JsonValue::Type::String && (type->text == "@id" || type->text == "@vocab"));
+                    }
+                    else fail(def.line, "invalid definition of term '" + key + "'");
+                }
+                break;
+            default: fail(ctx.line, "invalid @context"); break;
+            }
+        }
+
+        void set_default_vocab()
+        {
+            if (_vocab.empty()) _vocab = default_vocab;
+        }
+        bool has_vocab() const { return !_vocab.empty(); }
+
+        // IRI of a key, @type value or term (vocab_relative) or of an @id value.
+        std::string expand(const std::string& value, const bool vocab_relative) const
+        {
+            if (value.starts_with("@") || value.starts_with("_:")) return value;
+            if (vocab_relative)
+            {
+                auto term = _terms.find(value);
+                if (term != _terms.end()) return term->second.iri;
+            }
+            const size_t colon = value.find(':');
+            if (colon != std::string::npos)
+            {
+                auto prefix = _terms.find(value.substr(0, colon));
+                if (prefix != _terms.end() && value.compare(colon + 1, 2, "//") != 0) return prefix->second.iri + value.substr(colon + 1);
+                return value; // absolute IRI
+            }
+            return (vocab_relative && !_vocab.empty() ? _vocab : _base) + value;
+        }
+
+        bool is_id_typed(const std::string& key) const
+        {
+            auto term = _terms.find(key);
+            return term != _terms.end() && term->second.id_typed;
+        }
+
+        // The zelph name of an IRI: a term, the remainder after @vocab, a
+        // prefixed name or the IRI itself, whichever expands back to it.
+        std::string name(const std::string& iri) const
+        {
+            for (const auto& [term, def] : _terms)
+                if (def.iri == iri) return term;
+            if (!_vocab.empty() && iri.size() > _vocab.size() && iri.starts_with(_vocab))
+            {
+                const std::string rest = iri.substr(_vocab.size());
+                if (expand(rest, true) == iri) return rest;
+            }
+            const std::string prefixed = compact_prefix(iri);
+            return prefixed.empty() ? iri : prefixed;
+        }
+
+        // IRI of a zelph name (inverse of name()).
+        std::string iri(const std::string& name) const
+        {
+            const std::string result = expand(name, true);
+            return is_absolute(result) ? result : default_vocab + name;
+        }
+
+        // Form of an IRI for @id values, where terms and @vocab do not apply.
+        std::string id_form(const std::string& iri) const
+        {
+            const std::string prefixed = compact_prefix(iri);
+            return prefixed.empty() ? iri : prefixed;
+        }
+
+    private:
+        struct Term
+        {
+            std::string iri;
+            bool        id_typed{false};
+        };
+
+        std::map<std::string, Term> _terms;
+        std::string                 _vocab;
+        std::string                 _base;
+
+        void define(const std::string& term, const std::string& iri, const bool id_typed)
+        {
+            _terms[term] = Term{expand(iri, false), id_typed};
+        }
+
+        std::string compact_prefix(const std::string& iri) const
+        {
+            std::string best;
+            size_t      best_size = 0;
+            for (const auto& [term, def] : _terms)
+            {
+                const std::string& ns = def.iri;
+                if (ns.size() > best_size && iri.size() > ns.size() && iri.starts_with(ns)
+                    && (ns.back() == '/' || ns.back() == '#' || ns.back() == ':'))
+                {
+                    const std::string candidate = term + ":" + iri.substr(ns.size());
+                    if (expand(candidate, false) == iri)
+                    {
+                        best      = candidate;
+                        best_size = ns.size();
+                    }
+                }
+            }
+            return best;
+        }
+    };
+
+    class JsonLdImporter
+    {
+    public:
+        JsonLdImporter(zelph::network::Zelph* n, std::string file_name)
+            : _n(n)
+            , _file_name(std::move(file_name))
+        {
+        }
+
+        size_t import(const JsonValue& document)
+        {
+            top_level(document, Context{});
+            return _triples;
+        }
+
+    private:
+        zelph::network::Zelph*                _n;
+        const std::string                     _file_name;
+        size_t                                _triples{0};
+        std::unordered_map<std::string, Node> _blank_nodes;
+
+        [[noreturn]] void fail(const size_t line, const std::string& message) const
+        {
+            throw std::runtime_error(_file_name + ":" + std::to_string(line) + ": " + message);
+        }
+
+        Context::Fail failer() const
+        {
+            return [this](size_t line, const std::string& message)
+            { fail(line, message); };
+        }
+
+        void top_level(const JsonValue& v, Context ctx)
+        {
+            if (v.type == JsonValue::Type::Array)
+            {
+                for (const auto& item : v.items) top_level(item, ctx);
+                return;
+            }
+            if (v.type != JsonValue::Type::Object) fail(v.line, "expected a node object");
+
+            if (const JsonValue* local = v.find("@context")) ctx.merge(*local, failer());
+
+            // A wrapper consisting only of @context and @graph is not a node itself.
+            const JsonValue* graph   = v.find("@graph");
+            const bool       wrapper = graph && std::all_of(v.members.begin(), v.members.end(), [](const auto& m)
+                                                            { return m.first == "@context" || m.first == "@graph"; });
+            if (wrapper)
+            {
+                if (graph->type == JsonValue::Type::Array)
+                    for (const auto& item : graph->items) top_level(item, ctx);
+                else
+                    top_level(*graph, ctx);
+            }
+            else
+            {
+                node_object(v, ctx);
+            }
+        }
+
+        Node iri_node(const std::string& iri, const Context& ctx)
+        {
+            if (iri == rdf_type) return _n->core.IsA;
+            if (iri.starts_with("_:"))
+            {
+                auto [it, inserted] = _blank_nodes.try_emplace(iri, 0);
+                if (inserted) it->second = _n->create_node();
+                return it->second;
+            }
+            return _n->node(ctx.name(iri));
+        }
+
+        void emit(const Node subject, const Node predicate, const Node object)
+        {
+            _n->fact(subject, predicate, {object});
+            ++_triples;
+        }
+
+        Node node_object(const JsonValue& v, Context ctx)
+        {
+            if (const JsonValue* local = v.find("@context")) ctx.merge(*local, failer());
+
+            const JsonValue* id      = v.find("@id");
+            const Node       subject = id && id->type == JsonValue::Type::String ? iri_node(ctx.expand(id->text, false), ctx) : _n->create_node();
+
+            for (const auto& [key, value] : v.members)
+            {
+                if (key == "@type")
+                {
+                    for_each_value(value, [&](const JsonValue& t)
+                                   {
+                        if (t.type != JsonValue::Type::String) fail(t.line, "@type values must be strings");
+                        emit(subject, _n->core.IsA, iri_node(ctx.expand(t.text, true), ctx)); });
+                }
+                else if (key == "@graph")
+                {
+                    for_each_value(value, [&](const JsonValue& item)
+                                   { node_object(item, ctx); });
+                }
+                else if (!key.starts_with("@"))
+                {
+                    const std::string predicate_iri = ctx.expand(key, true);
+                    if (!is_absolute(predicate_iri)) continue; // not mapped to an IRI: dropped, as in JSON-LD
+
+                    const Node predicate = iri_node(predicate_iri, ctx);
+                    const bool id_typed  = ctx.is_id_typed(key);
+                    for_each_value(value, [&](const JsonValue& item)
+                                   {
+                        if (item.type != JsonValue::Type::Null) emit(subject, predicate, object(item, ctx, id_typed)); });
+                }
+            }
+            return subject;
+        }
+
+        Node object(const JsonValue& v, const Context& ctx, const bool id_typed)
+        {
+            switch (v.type)
+            {
+            case JsonValue::Type::String:
+                return id_typed ? iri_node(ctx.expand(v.text, false), ctx) : _n->node(v.text);
+            case JsonValue::Type::Number:
+            case JsonValue::Type::Bool:
+                return _n->node(v.text);
+            case JsonValue::Type::Object:
+                if (const JsonValue* literal = v.find("@value"))
+                {
+                    if (literal->type == JsonValue::Type::Object || literal->type == JsonValue::Type::Array) fail(literal->line, "invalid @value");
+                    return _n->node(literal->text);
+                }
+                if (const JsonValue* list = v.find("@list"))
+                {
+                    std::vector<Node> elements;
+                    for_each_value(*list, [&](const JsonValue& item)
+                                   {
+                        if (item.type != JsonValue::Type::Null) elements.push_back(object(item, ctx, id_typed)); });
+                    return elements.empty() ? _n->core.Nil : _n->list(elements);
+                }
+                return node_object(v, ctx);
+            default:
+                fail(v.line, "arrays of arrays are not supported");
+            }
+        }
+
+        template <typename F>
+        static void for_each_value(const JsonValue& v, F&& f)
+        {
+            if (v.type == JsonValue::Type::Array)
+                for (const auto& item : v.items) f(item);
+            else
+                f(v);
+        }
+    };
+}
+
+JsonLdDataManager::JsonLdDataManager(network::Zelph* n, const std::filesystem::path& input_path)
+    : DataManager(n, input_path)
+{
+}
+
+void JsonLdDataManager::load()
+{
+    std::ifstream stream(_input_path, std::ios::binary);
+    if (stream.fail()) throw std::runtime_error("Could not open file '" + _input_path.string() + "'");
+
+    std::stringstream buffer;
+    buffer << stream.rdbuf();
+    const std::string source = buffer.str();
+
+    _n->diagnostic("Importing JSON-LD from " + _input_path.string() + "...", true);
+
+    const std::string file_name = _input_path.filename().string();
+    const JsonValue   document  = JsonParser(source, file_name).parse();
+    const size_t      triples   = JsonLdImporter(_n, file_name).import(document);
+
+    _n->diagnostic("Imported " + std::to_string(triples) + " triples.", true);
+}
+
+size_t zelph::io::export_jsonld(const network::Zelph* n, std::ostream& out, const std::string& context)
+{
+    JsonValue context_value;
+    context_value.type = JsonValue::Type::Object;
+    if (!context.empty())
+    {
+        context_value = JsonParser(context, "context").parse();
+        if (const JsonValue* inner = context_value.find("@context")) context_value = JsonValue(*inner);
+    }
+
+    Context ctx;
+    ctx.merge(context_value, [](size_t line, const std::string& message)
+              { throw std::runtime_error("context:" + std::to_string(line) + ": " + message); });
+    if (!ctx.has_vocab())
+    {
+        // Cover all names not mapped by the context, so .load restores them.
+        if (context_value.type != JsonValue::Type::Object) throw std::runtime_error("context: expected an object");
+        JsonValue vocab;
+        vocab.type = JsonValue::Type::String;
+        vocab.text = default_vocab;
+        context_value.members.emplace_back("@vocab", vocab);
+        ctx.set_default_vocab();
+    }
+
+    auto node_iri = [&](Node nd) -> std::string
+    {
+        const std::string name = n->get_name(nd, "", true);
+        return name.empty() ? "_:n" + std::to_string(nd) : ctx.iri(name);
+    };
+    // Keys and @type values: terms and @vocab apply.
+    auto vocab_form = [&](Node nd) -> std::string
+    {
+        const std::string iri = node_iri(nd);
+        if (iri.starts_with("_:")) return iri;
+        const std::string name = ctx.name(iri);
+        return ctx.expand(name, true) == iri ? name : iri;
+    };
+    auto id_form = [&](Node nd) -> std::string
+    {
+        const std::string iri = node_iri(nd);
+        return iri.starts_with("_:") ? iri : ctx.id_form(iri);
+    };
+
+    // subject @id -> (key -> values), sorted for stable output
+    std::map<std::string, std::map<std::string, std::vector<std::string>>> nodes;
+    size_t                                                                 triples = 0;
+    for (const auto& fact : exportable_facts(n))
+    {
+        const bool type = fact.predicate == n->core.IsA;
+        auto&      values = nodes[id_form(fact.subject)][type ? "@type" : vocab_form(fact.predicate)];
+        for (Node object : fact.objects)
+        {
+            values.push_back(type ? json_quote(vocab_form(object)) : "{\"@id\": " + json_quote(id_form(object)) + "}");
+            ++triples;
+        }
+    }
+
+    out << "{\n  \"@context\": ";
+    write_json(out, context_value, "  ");
+    out << ",\n  \"@graph\": [";
+    bool first_node = true;
+    for (auto& [id, properties] : nodes)
+    {
+        out << (first_node ? "\n" : ",\n") << "    {\n      \"@id\": " << json_quote(id);
+        first_node = false;
+        for (auto& [key, values] : properties)
+        {
+            std::sort(values.begin(), values.end());
+            out << ",\n      " << json_quote(key) << ": ";
+            if (values.size() == 1)
+            {
+                out << values.front();
+                continue;
+            }
+            out << "[";
+            for (size_t i = 0; i < values.size(); ++i)
+                out << (i ? ", " : "") << values[i];
+            out << "]";
+        }
+        out << "\n    }";
+    }
+    out << (nodes.empty() ? "]\n}\n" : "\n  ]\n}\n");
+
+    return triples;
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "data_manager.hpp"
+
+#include <ostream>
+#include <string>
+
+namespace zelph::io
+{
+    // Imports JSON-LD documents (.jsonld) via .load. The document's
+    // @context (inline objects, arrays of them; remote contexts are not
+    // supported) defines the mapping between IRIs and zelph names:
+    //   - An IRI becomes the name of the term mapping to it, else its
+    //     remainder after @vocab, else its prefixed form (ex:alice), else
+    //     the IRI itself.
+    //   - @type maps onto ~, @list onto cons lists, nested node objects and
+    //     objects without @id onto unnamed nodes.
+    //   - Values are named by their lexical form (like RDF literals),
+    //     strings of terms declared with "@type": "@id" are IRIs.
+    //   - Keys that do not expand to an IRI are dropped, as in JSON-LD.
+    class JsonLdDataManager : public DataManager
+    {
+    public:
+        JsonLdDataManager(network::Zelph* n, const std::filesystem::path& input_path);
+        void     load() override;
+        DataType get_type() const override { return DataType::JsonLd; }
+    };
+
+    // Writes all statements (see exportable_facts) as a JSON-LD document,
+    // one node object per subject in "@graph". context is the JSON text of
+    // a context object (optionally wrapped in {"@context": ...}) and is
+    // written to the document; names are the inverse of the import mapping,
+    // so that .load restores them. A context without @vocab gets the
+    // vocabulary urn:zelph:, which covers all other names. Objects are always
+    // written as node references ({"@id": ...}). Returns the number of
+    // triples written.
+    size_t export_jsonld(const network::Zelph* n, std::ostream& out, const std::string& context = "");
+}
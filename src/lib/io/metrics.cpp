@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "metrics.hpp"
+
+#include "facts.hpp"
+#include "network/reasoning.hpp"
+#include "string/node_to_string.hpp"
+
+#include <algorithm>
+#include <iomanip>
+#include <sstream>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    void header(std::ostream& out, const std::string& name, const std::string& type, const std::string& help)
+    {
+        out << "# HELP " << name << " " << help << "\n"
+            << "# TYPE " << name << " " << type << "\n";
+    }
+
+    void histogram(std::ostream& out, const std::string& name, const std::string& help, const zelph::network::Histogram& h)
+    {
+        header(out, name, "histogram", help);
+        uint64_t cumulative = 0;
+        for (size_t i = 0; i < h.bounds.size(); ++i)
+        {
+            cumulative += h.counts[i];
+            out << name << "_bucket{le=\"" << h.bounds[i] << "\"} " << cumulative << "\n";
+        }
+        out << name << "_bucket{le=\"+Inf\"} " << h.count << "\n"
+            << name << "_sum " << h.sum << "\n"
+            << name << "_count " << h.count << "\n";
+    }
+}
+
+std::string zelph::io::prometheus_metrics(const network::Reasoning* n)
+{
+    const auto facts   = exportable_facts(n);
+    const auto deduced = std::count_if(facts.begin(), facts.end(), [n](const ExportedFact& fact)
+                                       { return n->is_deduced(fact.relation); });
+
+    std::ostringstream out;
+    out << std::setprecision(12);
+
+    header(out, "zelph_nodes", "gauge", "Nodes in the network.");
+    out << "zelph_nodes " << n->count() << "\n";
+    header(out, "zelph_facts", "gauge", "Statements in the network, stated and deduced.");
+    out << "zelph_facts " << facts.size() << "\n";
+    header(out, "zelph_deduced_facts", "gauge", "Statements in the network deduced by rules.");
+    out << "zelph_deduced_facts " << deduced << "\n";
+    header(out, "zelph_rules", "gauge", "Rules in the network.");
+    out << "zelph_rules " << n->rule_count() << "\n";
+    header(out, "zelph_metrics_enabled", "gauge", "Whether the counters below are collected.");
+    out << "zelph_metrics_enabled " << (n->metrics_enabled() ? 1 : 0) << "\n";
+
+    const network::RunMetrics metrics = n->metrics();
+
+    header(out, "zelph_runs_total", "counter", "Reasoning runs, including failed and paused ones.");
+    out << "zelph_runs_total " << metrics.runs << "\n";
+    header(out, "zelph_deductions_total", "counter", "Facts deduced by rules.");
+    out << "zelph_deductions_total " << metrics.deductions << "\n";
+
+    header(out, "zelph_rule_firings_total", "counter", "Facts deduced, per rule node.");
+    std::vector<std::pair<Node, uint64_t>> firings(metrics.rule_firings.begin(), metrics.rule_firings.end());
+    std::sort(firings.begin(), firings.end());
+    for (const auto& [rule, count] : firings)
+        out << "zelph_rule_firings_total{rule=\"" << rule << "\"} " << count << "\n";
+
+    header(out, "zelph_contradictions_total", "counter", "Contradictions detected by rules.");
+    out << "zelph_contradictions_total " << metrics.contradictions << "\n";
+
+    histogram(out, "zelph_run_duration_seconds", "Duration of reasoning runs.", metrics.run_seconds);
+    histogram(out, "zelph_query_duration_seconds", "Duration of queries.", metrics.query_seconds);
+
+    return out.str();
+}
+
+std::string zelph::io::rule_profile_report(const network::Reasoning* n)
+{
+    const auto profile = n->rule_profile();
+    if (profile.empty()) return "No rule profile (see .rule-profile).\n";
+
+    auto rule_text = [n](const Node rule)
+    {
+        std::string text;
+        string::node_to_string(n, text, n->lang(), rule, 3);
+        return "[" + std::to_string(rule) + "] " + string::unmark_identifiers(text);
+    };
+
+    double total = 0;
+    for (const auto& p : profile)
+        total += p.seconds;
+
+    std::ostringstream out;
+    out << std::fixed << "Rule profile of the last run: " << profile.size() << " rules, " << std::setprecision(4) << total << "s in total\n";
+    out << std::setw(10) << "flat" << std::setw(8) << "flat%" << std::setw(8) << "sum%"
+        << std::setw(10) << "firings" << std::setw(11) << "new facts" << "  rule\n";
+
+    double                   sum = 0;
+    std::vector<std::string> never;
+    for (const auto& p : profile)
+    {
+        if (p.firings == 0)
+        {
+            never.push_back(rule_text(p.rule));
+            continue;
+        }
+
+        const double share = total > 0 ? 100 * p.seconds / total : 0;
+        sum += share;
+        out << std::setw(9) << std::setprecision(4) << p.seconds << "s"
+            << std::setw(7) << std::setprecision(2) << share << "%"
+            << std::setw(7) << sum << "%"
+            << std::setw(10) << p.firings << std::setw(11) << p.deductions << "  " << rule_text(p.rule) << "\n";
+    }
+
+    if (!never.empty())
+    {
+        out << "Never fired:\n";
+        for (const auto& text : never)
+            out << "  " << text << "\n";
+    }
+
+    return out.str();
+}
@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <string>
+
+namespace zelph::network
+{
+    class Reasoning;
+}
+
+namespace zelph::io
+{
+    // The state and the metrics of the network (see
+    // Reasoning::set_metrics_enabled) in the Prometheus text exposition
+    // format, ready to be served on a /metrics endpoint. The gauges are
+    // computed on each call and need no enabled metrics.
+    std::string prometheus_metrics(const network::Reasoning* n);
+
+    // The rule profile of the last profiled run (see
+    // Reasoning::rule_profile) as a table in the style of pprof's -top
+    // output: the rules that fired, the most expensive first, with their
+    // evaluation time, its share and the running total of the shares, their
+    // firings and new facts, followed by the rules that never fired.
+    std::string rule_profile_report(const network::Reasoning* n);
+}
@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "owl.hpp"
+
+#include "facts.hpp"
+#include "network/zelph.hpp"
+#include "string/node_to_string.hpp"
+
+#include <array>
+#include <map>
+#include <string>
+#include <unordered_set>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    const std::string rdfs_ns = "http://www.w3.org/2000/01/rdf-schema#";
+    const std::string owl_ns  = "http://www.w3.org/2002/07/owl#";
+    const std::string xsd_ns  = "http://www.w3.org/2001/XMLSchema#";
+
+    // Whether name denotes the term local of the vocabulary ns (with the
+    // conventional prefix).
+    bool is_term(const std::string& name, const std::string& ns, const std::string& prefix, const std::string& local)
+    {
+        return name == prefix + ":" + local || name == ns + local;
+    }
+
+    // A term of a rule pattern: a node, or one of the variables X, Y and Z,
+    // which are created afresh for every rule like for a statement of a
+    // script.
+    struct Term
+    {
+        Term(Node nd)
+            : node(nd)
+        {
+        }
+        constexpr explicit Term(char name)
+            : var(name)
+        {
+        }
+
+        Node node{0};
+        char var{0};
+    };
+
+    using Triple = std::array<Term, 3>;
+
+    const Term X('X'), Y('Y'), Z('Z');
+
+    class RuleBuilder
+    {
+    public:
+        explicit RuleBuilder(zelph::network::Zelph* n)
+            : _n(n)
+        {
+            for (Node rule : n->get_rules())
+                _existing.insert(text(rule));
+        }
+
+        // Adds conditions => consequence unless a rule with the same text
+        // exists, in which case the new one is removed again (like by
+        // .remove-rule). The conditions of a conjunction form a set, like
+        // in zelph/rule.
+        bool add(const std::vector<Triple>& conditions, const Triple& consequence)
+        {
+            std::map<char, Node> vars;
+            auto                 resolve = [&](const Term& term)
+            {
+                if (!term.var) return term.node;
+                auto [it, inserted] = vars.try_emplace(term.var, 0);
+                if (inserted)
+                {
+                    it->second = _n->var();
+                    _n->set_name(it->second, std::string(1, term.var), _n->lang(), false);
+                }
+                return it->second;
+            };
+            auto fact = [&](const Triple& t)
+            { return _n->fact(resolve(t[0]), resolve(t[1]), {resolve(t[2])}); };
+
+            std::unordered_set<Node> condition_nodes;
+            for (const auto& t : conditions)
+                condition_nodes.insert(fact(t));
+
+            Node condition = *condition_nodes.begin();
+            if (condition_nodes.size() > 1)
+            {
+                condition = _n->set(condition_nodes);
+                _n->fact(condition, _n->core.IsA, {_n->core.Conjunction});
+            }
+
+            const Node rule = _n->fact(condition, _n->core.Causes, {fact(consequence)});
+            if (_existing.insert(text(rule)).second) return true;
+
+            _n->remove_rule(rule);
+            return false;
+        }
+
+    private:
+        std::string text(Node rule) const
+        {
+            std::string result;
+            zelph::string::node_to_string(_n, result, _n->lang(), rule, 3);
+            return result;
+        }
+
+        zelph::network::Zelph*          _n;
+        std::unordered_set<std::string> _existing;
+    };
+}
+
+OwlTranslation zelph::io::owl_rules(network::Zelph* n)
+{
+    const std::string lang = n->lang();
+    auto              name = [&](Node nd)
+    { return n->get_name(nd, lang, false); };
+
+    OwlTranslation result;
+    RuleBuilder    rules(n);
+
+    auto add = [&](const std::vector<Triple>& conditions, const Triple& consequence)
+    {
+        if (rules.add(conditions, consequence)) ++result.rules;
+    };
+
+    for (const auto& fact : exportable_facts(n))
+    {
+        const std::string predicate = name(fact.predicate);
+
+        for (Node object : fact.objects)
+        {
+            const std::string object_name = name(object);
+
+            enum class Axiom
+            {
+                None,
+                SubClassOf,
+                Domain,
+                Range,
+                InverseOf,
+                Symmetric,
+                Transitive
+            } axiom = Axiom::None;
+
+            if (is_term(predicate, rdfs_ns, "rdfs", "subClassOf"))
+                axiom = Axiom::SubClassOf;
+            else if (is_term(predicate, rdfs_ns, "rdfs", "domain"))
+                axiom = Axiom::Domain;
+            else if (is_term(predicate, rdfs_ns, "rdfs", "range"))
+                axiom = Axiom::Range;
+            else if (is_term(predicate, owl_ns, "owl", "inverseOf"))
+                axiom = Axiom::InverseOf;
+            else if (fact.predicate == n->core.IsA && is_term(object_name, owl_ns, "owl", "SymmetricProperty"))
+                axiom = Axiom::Symmetric;
+            else if (fact.predicate == n->core.IsA && is_term(object_name, owl_ns, "owl", "TransitiveProperty"))
+                axiom = Axiom::Transitive;
+
+            if (axiom == Axiom::None) continue;
+            ++result.axioms;
+
+            const bool named_object = axiom == Axiom::Symmetric || axiom == Axiom::Transitive || !object_name.empty();
+            if (name(fact.subject).empty() || !named_object
+                || (axiom == Axiom::SubClassOf && is_term(object_name, owl_ns, "owl", "Thing"))
+                || (axiom == Axiom::Range && (object_name.starts_with("xsd:") || object_name.starts_with(xsd_ns) || is_term(object_name, rdfs_ns, "rdfs", "Literal"))))
+            {
+                ++result.skipped;
+                continue;
+            }
+
+            const Node s = fact.subject;
+            switch (axiom)
+            {
+            case Axiom::SubClassOf:
+                add({{X, n->core.IsA, s}}, {X, n->core.IsA, object});
+                break;
+            case Axiom::Domain:
+                add({{X, s, Y}}, {X, n->core.IsA, object});
+                break;
+            case Axiom::Range:
+                add({{X, s, Y}}, {Y, n->core.IsA, object});
+                break;
+            case Axiom::InverseOf:
+                add({{X, s, Y}}, {Y, object, X});
+                add({{X, object, Y}}, {Y, s, X});
+                break;
+            case Axiom::Symmetric:
+                add({{X, s, Y}}, {Y, s, X});
+                break;
+            case Axiom::Transitive:
+                add({{X, s, Y}, {Y, s, Z}}, {X, s, Z});
+                break;
+            case Axiom::None:
+                break;
+            }
+        }
+    }
+
+    return result;
+}
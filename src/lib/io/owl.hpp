@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <cstddef>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // Translates the axioms of a pragmatic OWL subset stated in the network
+    // (typically imported from an ontology via .load) into rules, so that
+    // the ontology drives inference (see .owl-rules):
+    //   C rdfs:subClassOf D          (X ~ C) => (X ~ D)
+    //   P rdfs:domain C              (X P Y) => (X ~ C)
+    //   P rdfs:range C               (X P Y) => (Y ~ C)
+    //   P owl:inverseOf Q            (X P Y) => (Y Q X) and (X Q Y) => (Y P X)
+    //   P ~ owl:SymmetricProperty    (X P Y) => (Y P X)
+    //   P ~ owl:TransitiveProperty   (X P Y, Y P Z) => (X P Z)
+    // The vocabulary is recognized by its full IRI or the conventional rdfs:
+    // and owl: prefixes, in the current language. Axioms about unnamed
+    // classes (restrictions, unions and the like), owl:Thing as superclass
+    // and datatype ranges (xsd:..., rdfs:Literal) are skipped. A rule whose
+    // text already exists is not added again, so translating twice is safe.
+    struct OwlTranslation
+    {
+        size_t axioms{0};  // axioms of the subset found
+        size_t rules{0};   // rules added
+        size_t skipped{0}; // axioms that cannot be translated
+    };
+
+    OwlTranslation owl_rules(network::Zelph* n);
+}
@@ -0,0 +1,653 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "rdf.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <fstream>
+#include <map>
+#include <sstream>
+#include <unordered_map>
+#include <unordered_set>
+#include <vector>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    const std::string rdf_ns   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#";
+    const std::string rdf_type = rdf_ns + "type";
+
+    // Recursive-descent parser for the Turtle grammar (W3C REC 2014),
+    // emitting facts directly while parsing. N-Triples documents are
+    // valid Turtle and take the same path.
+    class TurtleParser
+    {
+    public:
+        TurtleParser(zelph::network::Zelph* n, std::string source, std::string file_name)
+            : _n(n)
+            , _src(std::move(source))
+            , _file_name(std::move(file_name))
+        {
+        }
+
+        size_t parse()
+        {
+            skip_ws();
+            while (!eof())
+            {
+                if (peek() == '@')
+                {
+                    directive();
+                }
+                else if (keyword("PREFIX") || keyword("BASE"))
+                {
+                    sparql_directive();
+                }
+                else
+                {
+                    triples();
+                    expect('.');
+                }
+                skip_ws();
+            }
+            return _triples;
+        }
+
+    private:
+        zelph::network::Zelph*                _n;
+        const std::string                     _src;
+        const std::string                     _file_name;
+        size_t                                _pos{0};
+        size_t                                _line{1};
+        size_t                                _triples{0};
+        std::string                           _base;
+        std::map<std::string, std::string>    _prefixes; // prefix -> namespace IRI
+        std::unordered_map<std::string, Node> _blank_nodes;
+
+        [[noreturn]] void fail(const std::string& message) const
+        {
+            throw std::runtime_error(_file_name + ":" + std::to_string(_line) + ": " + message);
+        }
+
+        bool eof() const { return _pos >= _src.size(); }
+        char peek(size_t ahead = 0) const { return _pos + ahead < _src.size() ? _src[_pos + ahead] : '\0'; }
+
+        char next()
+        {
+            if (eof()) fail("unexpected end of input");
+            const char c = _src[_pos++];
+            if (c == '\n') ++_line;
+            return c;
+        }
+
+        void skip_ws()
+        {
+            while (!eof())
+            {
+                const char c = peek();
+                if (c == '#')
+                {
+                    while (!eof() && peek() != '\n') ++_pos;
+                }
+                else if (c == ' ' || c == '\t' || c == '\r' || c == '\n')
+                {
+                    next();
+                }
+                else
+                {
+                    break;
+                }
+            }
+        }
+
+        void expect(const char c)
+        {
+            skip_ws();
+            if (peek() != c) fail(std::string("expected '") + c + "'" + (eof() ? " at end of input" : std::string(", found '") + peek() + "'"));
+            next();
+        }
+
+        static bool is_delimiter(const char c)
+        {
+            return c == '\0' || c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '<' || c == '[' || c == '(' || c == '"' || c == '\'' || c == '#';
+        }
+
+        // Case-insensitive SPARQL-style keyword followed by whitespace.
+        bool keyword(const std::string& word) const
+        {
+            for (size_t i = 0; i < word.size(); ++i)
+                if (std::toupper(static_cast<unsigned char>(peek(i))) != word[i]) return false;
+            return is_delimiter(peek(word.size()));
+        }
+
+        static bool is_name_char(const char c)
+        {
+            return std::isalnum(static_cast<unsigned char>(c)) || c == '_' || c == '-' || c == '.' || c == '%'
+                || (static_cast<unsigned char>(c) & 0x80) != 0; // UTF-8 continuation of PN_CHARS
+        }
+
+        // --- Directives ---
+
+        void directive()
+        {
+            next(); // '@'
+            if (_src.compare(_pos, 6, "prefix") == 0)
+            {
+                _pos += 6;
+                prefix_declaration();
+            }
+            else if (_src.compare(_pos, 4, "base") == 0)
+            {
+                _pos += 4;
+                skip_ws();
+                _base = iri_ref();
+            }
+            else
+            {
+                fail("unknown directive");
+            }
+            expect('.');
+        }
+
+        void sparql_directive()
+        {
+            if (keyword("PREFIX"))
+            {
+                _pos += 6;
+                prefix_declaration();
+            }
+            else
+            {
+                _pos += 4;
+                skip_ws();
+                _base = iri_ref();
+            }
+        }
+
+        void prefix_declaration()
+        {
+            skip_ws();
+            std::string prefix;
+            while (!eof() && peek() != ':')
+            {
+                if (!is_name_char(peek())) fail("invalid prefix name");
+                prefix += next();
+            }
+            expect(':');
+            skip_ws();
+            _prefixes[prefix] = iri_ref();
+        }
+
+        // --- Terms ---
+
+        std::string resolve(const std::string& iri) const
+        {
+            const size_t colon = iri.find(':');
+            const size_t slash = iri.find('/');
+            if (colon != std::string::npos && (slash == std::string::npos || colon < slash)) return iri; // absolute
+            return _base + iri;
+        }
+
+        std::string iri_ref()
+        {
+            if (peek() != '<') fail("expected IRI");
+            next();
+            std::string iri;
+            while (peek() != '>')
+            {
+                const char c = next();
+                if (c == '\n' || c == ' ') fail("unterminated IRI");
+                if (c == '\\') iri += unicode_escape();
+                else iri += c;
+            }
+            next();
+            return resolve(iri);
+        }
+
+        std::string prefixed_name()
+        {
+            std::string prefix;
+            while (!eof() && peek() != ':' && is_name_char(peek()))
+                prefix += next();
+            if (peek() != ':') fail("expected prefixed name or IRI, found '" + prefix + std::string(1, peek()) + "'");
+            next();
+
+            std::string local;
+            while (!eof() && (is_name_char(peek()) || peek() == ':' || peek() == '\\'))
+            {
+                if (peek() == '\\')
+                {
+                    next();
+                    local += next(); // PN_LOCAL_ESC
+                }
+                else
+                {
+                    local += next();
+                }
+            }
+            while (!local.empty() && local.back() == '.') // a trailing '.' terminates the statement
+            {
+                local.pop_back();
+                --_pos;
+            }
+
+            auto it = _prefixes.find(prefix);
+            if (it == _prefixes.end()) fail("undeclared prefix '" + prefix + ":'");
+            return it->second + local;
+        }
+
+        // Prefixed form if a declared prefix covers the IRI, the IRI otherwise.
+        std::string compact(const std::string& iri) const
+        {
+            const std::pair<const std::string, std::string>* best = nullptr;
+            for (const auto& entry : _prefixes)
+            {
+                if (iri.size() > entry.second.size() && iri.starts_with(entry.second)
+                    && (!best || entry.second.size() > best->second.size()))
+                    best = &entry;
+            }
+            return best ? best->first + ":" + iri.substr(best->second.size()) : iri;
+        }
+
+        Node iri_node(const std::string& iri)
+        {
+            if (iri == rdf_type) return _n->core.IsA;
+            if (iri == rdf_ns + "nil") return _n->core.Nil;
+            return _n->node(compact(iri));
+        }
+
+        Node blank_node_label()
+        {
+            _pos += 2; // "_:"
+            std::string label;
+            while (!eof() && is_name_char(peek()))
+                label += next();
+            while (!label.empty() && label.back() == '.')
+            {
+                label.pop_back();
+                --_pos;
+            }
+            if (label.empty()) fail("empty blank node label");
+
+            auto [it, inserted] = _blank_nodes.try_emplace(label, 0);
+            if (inserted) it->second = _n->create_node();
+            return it->second;
+        }
+
+        std::string unicode_escape()
+        {
+            const char kind = next();
+            if (kind != 'u' && kind != 'U') fail("invalid escape in IRI");
+            return utf8(hex_code_point(kind == 'u' ? 4 : 8));
+        }
+
+        uint32_t hex_code_point(const int digits)
+        {
+            uint32_t cp = 0;
+            for (int i = 0; i < digits; ++i)
+            {
+                const char c = next();
+                if (!std::isxdigit(static_cast<unsigned char>(c))) fail("invalid \\u escape");
+                cp = cp * 16 + static_cast<uint32_t>(std::isdigit(static_cast<unsigned char>(c)) ? c - '0' : std::tolower(c) - 'a' + 10);
+            }
+            return cp;
+        }
+
+        static std::string utf8(const uint32_t cp)
+        {
+            std::string s;
+            if (cp < 0x80)
+                s += static_cast<char>(cp);
+            else if (cp < 0x800)
+            {
+                s += static_cast<char>(0xC0 | (cp >> 6));
+                s += static_cast<char>(0x80 | (cp & 0x3F));
+            }
+            else if (cp < 0x10000)
+            {
+                s += static_cast<char>(0xE0 | (cp >> 12));
+                s += static_cast<char>(0x80 | ((cp >> 6) & 0x3F));
+                s += static_cast<char>(0x80 | (cp & 0x3F));
+            }
+            else
+            {
+                s += static_cast<char>(0xF0 | (cp >> 18));
+                s += static_cast<char>(0x80 | ((cp >> 12) & 0x3F));
+                s += static_cast<char>(0x80 | ((cp >> 6) & 0x3F));
+                s += static_cast<char>(0x80 | (cp & 0x3F));
+            }
+            return s;
+        }
+
+        std::string string_literal()
+        {
+            const char quote = next();
+            const bool lng   = peek() == quote && peek(1) == quote;
+            if (lng) _pos += 2;
+
+            std::string value;
+            while (true)
+            {
+                if (eof()) fail("unterminated string literal");
+                const char c = peek();
+                if (c == quote)
+                {
+                    if (!lng)
+                    {
+                        next();
+                        break;
+                    }
+                    if (peek(1) == quote && peek(2) == quote)
+                    {
+                        _pos += 3;
+                        break;
+                    }
+                }
+                if (!lng && (c == '\n' || c == '\r')) fail("line break in short string literal");
+
+                next();
+                if (c != '\\')
+                {
+                    value += c;
+                    continue;
+                }
+
+                const char e = next();
+                switch (e)
+                {
+                case 't': value += '\t'; break;
+                case 'b': value += '\b'; break;
+                case 'n': value += '\n'; break;
+                case 'r': value += '\r'; break;
+                case 'f': value += '\f'; break;
+                case '"': value += '"'; break;
+                case '\'': value += '\''; break;
+                case '\\': value += '\\'; break;
+                case 'u': value += utf8(hex_code_point(4)); break;
+                case 'U': value += utf8(hex_code_point(8)); break;
+                default: fail(std::string("invalid escape sequence '\\") + e + "'");
+                }
+            }
+            return value;
+        }
+
+        Node literal()
+        {
+            std::string value;
+            const char  c = peek();
+
+            if (c == '"' || c == '\'')
+            {
+                value = string_literal();
+                if (peek() == '@')
+                {
+                    next();
+                    while (!eof() && (std::isalnum(static_cast<unsigned char>(peek())) || peek() == '-'))
+                        next(); // language tags are not represented
+                }
+                else if (peek() == '^' && peek(1) == '^')
+                {
+                    _pos += 2;
+                    if (peek() == '<') iri_ref();
+                    else prefixed_name(); // datatypes are not represented
+                }
+            }
+            else
+            {
+                // Numeric or boolean literal: kept as its lexical form.
+                while (!eof() && !is_delimiter(peek()) && peek() != ',' && peek() != ';' && peek() != ']' && peek() != ')')
+                {
+                    if (peek() == '.' && !std::isdigit(static_cast<unsigned char>(peek(1)))) break; // statement end
+                    value += next();
+                }
+                if (value.empty()) fail("expected RDF term");
+            }
+
+            if (value.empty()) fail("empty literals cannot be represented as zelph nodes");
+            return _n->node(value);
+        }
+
+        bool at_literal_start() const
+        {
+            const char c = peek();
+            if (c == '"' || c == '\'' || c == '+' || c == '-' || std::isdigit(static_cast<unsigned char>(c))) return true;
+            if (c == '.' && std::isdigit(static_cast<unsigned char>(peek(1)))) return true;
+            for (const char* b : {"true", "false"})
+            {
+                const size_t len = std::char_traits<char>::length(b);
+                if (_src.compare(_pos, len, b) != 0) continue;
+                const char after = peek(len);
+                if (after == '.' ? !is_name_char(peek(len + 1)) : (after != ':' && !is_name_char(after))) return true;
+            }
+            return false;
+        }
+
+        Node collection()
+        {
+            next(); // '('
+            std::vector<Node> elements;
+            skip_ws();
+            while (peek() != ')')
+            {
+                if (eof()) fail("unterminated collection");
+                elements.push_back(object_term());
+                skip_ws();
+            }
+            next();
+            return _n->list(elements);
+        }
+
+        Node blank_node_property_list()
+        {
+            next(); // '['
+            Node blank = _n->create_node();
+            skip_ws();
+            if (peek() != ']') predicate_object_list(blank);
+            expect(']');
+            return blank;
+        }
+
+        Node subject_term()
+        {
+            skip_ws();
+            const char c = peek();
+            if (c == '<') return iri_node(iri_ref());
+            if (c == '_' && peek(1) == ':') return blank_node_label();
+            if (c == '[') return blank_node_property_list();
+            if (c == '(') return collection();
+            return iri_node(prefixed_name());
+        }
+
+        Node object_term()
+        {
+            skip_ws();
+            if (at_literal_start()) return literal();
+            return subject_term();
+        }
+
+        Node verb()
+        {
+            skip_ws();
+            if (peek() == 'a' && is_delimiter(peek(1))) // the keyword "a"
+            {
+                next();
+                return _n->core.IsA;
+            }
+            if (peek() == '<') return iri_node(iri_ref());
+            return iri_node(prefixed_name());
+        }
+
+        // --- Statements ---
+
+        void emit(const Node subject, const Node predicate, const Node object)
+        {
+            try
+            {
+                _n->fact(subject, predicate, {object});
+                ++_triples;
+            }
+            catch (const std::exception& ex)
+            {
+                fail(std::string("cannot represent triple: ") + ex.what());
+            }
+        }
+
+        void predicate_object_list(const Node subject)
+        {
+            while (true)
+            {
+                const Node predicate = verb();
+
+                emit(subject, predicate, object_term());
+                skip_ws();
+                while (peek() == ',')
+                {
+                    next();
+                    emit(subject, predicate, object_term());
+                    skip_ws();
+                }
+
+                if (peek() != ';') return;
+                while (peek() == ';')
+                {
+                    next();
+                    skip_ws();
+                }
+                if (peek() == '.' || peek() == ']' || eof()) return;
+            }
+        }
+
+        void triples()
+        {
+            skip_ws();
+            const bool property_list = peek() == '[';
+            const Node subject       = subject_term();
+            skip_ws();
+
+            // "[ ... ] ." is a complete statement on its own.
+            if (property_list && peek() == '.') return;
+            predicate_object_list(subject);
+        }
+    };
+
+    bool is_absolute_iri(const std::string& name)
+    {
+        return name.find("://") != std::string::npos || name.rfind("urn:", 0) == 0;
+    }
+
+    // IRIREF of the N-Triples grammar: everything except controls, space
+    // and <>"{}|^`\ may appear literally (UTF-8 included).
+    std::string escape_iri(const std::string& iri)
+    {
+        static const std::string forbidden = "<>\"{}|^`\\";
+        std::string              result;
+        result.reserve(iri.size());
+        for (const unsigned char c : iri)
+        {
+            if (c <= 0x20 || forbidden.find(static_cast<char>(c)) != std::string::npos)
+            {
+                static const char hex[] = "0123456789ABCDEF";
+                result += '%';
+                result += hex[c >> 4];
+                result += hex[c & 0x0F];
+            }
+            else
+            {
+                result += static_cast<char>(c);
+            }
+        }
+        return result;
+    }
+
+    class NTriplesWriter
+    {
+    public:
+        NTriplesWriter(const zelph::network::Zelph* n, std::string base_iri)
+            : _n(n)
+            , _base(std::move(base_iri))
+        {
+        }
+
+        std::string term(Node nd) const
+        {
+            if (nd == _n->core.IsA) return "<" + rdf_type + ">";
+
+            const std::string name = _n->get_name(nd, "", true);
+            if (name.empty()) return "_:n" + std::to_string(nd);
+            return "<" + escape_iri(is_absolute_iri(name) ? name : _base + name) + ">";
+        }
+
+    private:
+        const zelph::network::Zelph* _n;
+        const std::string            _base;
+    };
+}
+
+RdfDataManager::RdfDataManager(network::Zelph* n, const std::filesystem::path& input_path)
+    : DataManager(n, input_path)
+{
+}
+
+void RdfDataManager::load()
+{
+    std::ifstream stream(_input_path, std::ios::binary);
+    if (stream.fail()) throw std::runtime_error("Could not open file '" + _input_path.string() + "'");
+
+    std::stringstream buffer;
+    buffer << stream.rdbuf();
+
+    _n->diagnostic("Importing RDF from " + _input_path.string() + "...", true);
+
+    TurtleParser parser(_n, buffer.str(), _input_path.filename().string());
+    const size_t triples = parser.parse();
+
+    _n->diagnostic("Imported " + std::to_string(triples) + " triples.", true);
+}
+
+size_t zelph::io::export_rdf(const network::Zelph*                      n,
+                             std::ostream&                              out,
+                             const std::string&                         base_iri,
+                             const std::function<bool(network::Node)>& is_deduced)
+{
+    const NTriplesWriter writer(n, base_iri);
+    const std::string    deduced_graph = " <" + escape_iri(base_iri + "graph:deduced") + ">";
+    size_t               lines         = 0;
+
+    for (const auto& fact : exportable_facts(n))
+    {
+        const std::string prefix = writer.term(fact.subject) + " " + writer.term(fact.predicate) + " ";
+        const std::string suffix = is_deduced && is_deduced(fact.relation) ? deduced_graph + " .\n" : " .\n";
+        for (Node object : fact.objects)
+        {
+            out << prefix << writer.term(object) << suffix;
+            ++lines;
+        }
+    }
+
+    return lines;
+}
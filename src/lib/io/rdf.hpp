@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "data_manager.hpp"
+#include "facts.hpp"
+
+#include <functional>
+#include <ostream>
+#include <vector>
+
+namespace zelph::io
+{
+    // Imports RDF documents in Turtle syntax (.ttl) or its subset
+    // N-Triples (.nt) via .load. Every triple becomes a zelph fact in the
+    // current language:
+    //   - IRIs are named by their prefixed form if a declared prefix
+    //     covers them (ex:alice), otherwise by the full IRI (no brackets).
+    //   - rdf:type (and the keyword "a") maps onto the core relation ~.
+    //   - Literals are named by their lexical form; language tags and
+    //     datatypes are not represented.
+    //   - Blank nodes become unnamed nodes, scoped to the document.
+    //   - Collections ( ... ) become cons lists, like <...> in zelph.
+    // Parse errors report the file name and line number.
+    class RdfDataManager : public DataManager
+    {
+    public:
+        RdfDataManager(network::Zelph* n, const std::filesystem::path& input_path);
+        void     load() override;
+        DataType get_type() const override { return DataType::Rdf; }
+    };
+
+    // Writes every fact of the network as N-Triples, one line per
+    // subject/predicate/object (a fact with several objects yields several
+    // lines). Rules and facts containing variables are skipped.
+    //   - Names that already are IRIs (containing "://", or starting with
+    //     "urn:") are written as is, all other names are appended
+    //     (percent-encoded where needed) to base_iri.
+    //   - ~ is written as rdf:type, unnamed nodes become blank nodes.
+    // If is_deduced is given, the output is N-Quads: facts for which it
+    // returns true are placed in the named graph <base_iri>graph:deduced,
+    // all others in the default graph. Returns the number of lines written.
+    size_t export_rdf(const network::Zelph*                      n,
+                      std::ostream&                              out,
+                      const std::string&                         base_iri   = "urn:zelph:",
+                      const std::function<bool(network::Node)>& is_deduced = {});
+}
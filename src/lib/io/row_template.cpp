@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+
+#include "row_template.hpp"
+
+#include "bulk_loader.hpp"
+
+#include <algorithm>
+#include <sstream>
+#include <stdexcept>
+
+using namespace zelph::io;
+
+RowTemplate::RowTemplate(const std::string& text)
+    : _text(text)
+{
+    std::istringstream in(text);
+    size_t             number = 0;
+    for (std::string line; std::getline(in, line);)
+    {
+        ++number;
+        if (!line.empty() && line.back() == '\r') line.pop_back();
+        const size_t first = line.find_first_not_of(" \t");
+        if (first == std::string::npos || line[first] == '#') continue;
+
+        auto fail = [&](const std::string& message)
+        {
+            throw std::runtime_error("template line " + std::to_string(number) + ": " + message);
+        };
+
+        std::vector<std::string> fields;
+        try
+        {
+            fields = split_bulk_fields(line);
+        }
+        catch (const std::exception& ex)
+        {
+            fail(ex.what());
+        }
+        if (fields.size() != 3) fail("expected subject, predicate and object, got '" + line + "'");
+
+        std::array<Term, 3>& terms = _lines.emplace_back();
+        for (size_t i = 0; i < 3; ++i)
+        {
+            const std::string& field = fields[i];
+            Term&              term  = terms[i];
+            std::string        literal;
+            for (size_t j = 0; j < field.size(); ++j)
+            {
+                const char c = field[j];
+                if ((c == '{' || c == '}') && j + 1 < field.size() && field[j + 1] == c)
+                {
+                    literal += c;
+                    ++j;
+                }
+                else if (c == '{')
+                {
+                    const size_t end = field.find('}', j + 1);
+                    if (end == std::string::npos) fail("unterminated placeholder in '" + field + "'");
+                    const std::string column = field.substr(j + 1, end - j - 1);
+                    if (column.empty()) fail("empty placeholder in '" + field + "'");
+
+                    if (!literal.empty()) term.push_back({std::move(literal), -1});
+                    literal.clear();
+
+                    auto it = std::find(_placeholders.begin(), _placeholders.end(), column);
+                    if (it == _placeholders.end()) it = _placeholders.insert(_placeholders.end(), column);
+                    term.push_back({"", static_cast<int>(it - _placeholders.begin())});
+                    j = end;
+                }
+                else if (c == '}')
+                {
+                    fail("unmatched } in '" + field + "'");
+                }
+                else
+                {
+                    literal += c;
+                }
+            }
+            if (!literal.empty()) term.push_back({std::move(literal), -1});
+            if (term.empty()) fail("empty name");
+        }
+    }
+
+    if (_lines.empty()) throw std::runtime_error("template without facts");
+}
+
+std::vector<std::array<std::string, 3>> RowTemplate::expand(const RowSet& rows) const
+{
+    std::vector<size_t> columns;
+    for (const std::string& placeholder : _placeholders)
+    {
+        auto it = std::find(rows.columns.begin(), rows.columns.end(), placeholder);
+        if (it == rows.columns.end()) throw std::runtime_error("no column '" + placeholder + "' in the result");
+        columns.push_back(static_cast<size_t>(it - rows.columns.begin()));
+    }
+
+    std::vector<std::array<std::string, 3>> facts;
+    for (const auto& row : rows.rows)
+    {
+        if (row.size() != rows.columns.size())
+            throw std::runtime_error("a row has " + std::to_string(row.size()) + " values for " + std::to_string(rows.columns.size()) + " columns");
+
+        for (const auto& terms : _lines)
+        {
+            std::array<std::string, 3> fact;
+            bool                       complete = true;
+            for (size_t i = 0; i < 3 && complete; ++i)
+            {
+                for (const Piece& piece : terms[i])
+                {
+                    if (piece.placeholder < 0)
+                    {
+                        fact[i] += piece.text;
+                        continue;
+                    }
+                    const auto& value = row[columns[piece.placeholder]];
+                    if (!value || value->empty())
+                    {
+                        complete = false;
+                        break;
+                    }
+                    fact[i] += *value;
+                }
+            }
+            if (complete) facts.push_back(std::move(fact));
+        }
+    }
+    return facts;
+}
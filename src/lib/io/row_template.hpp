@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+
+#pragma once
+
+#include <array>
+#include <optional>
+#include <string>
+#include <vector>
+
+namespace zelph::io
+{
+    // The result of a query against a database or another tabular source:
+    // column names and rows of values in the same order, std::nullopt for
+    // NULL.
+    struct RowSet
+    {
+        std::vector<std::string>                             columns;
+        std::vector<std::vector<std::optional<std::string>>> rows;
+    };
+
+    // Turns the rows of a RowSet into facts. The template holds one fact per
+    // line in the syntax of .bulk-load (tab-separated, or space-separated
+    // with double quotes grouping names with blanks), in which {column}
+    // stands for the value of that column and {{ and }} for literal braces:
+    //   {name} "works at" {company}
+    //   {name} ~ employee
+    //   "{first} {last}" age {age}
+    // Errors in the template are thrown as std::runtime_error.
+    class RowTemplate
+    {
+    public:
+        explicit RowTemplate(const std::string& text);
+
+        const std::string&              text() const { return _text; }
+        const std::vector<std::string>& placeholders() const { return _placeholders; }
+
+        // The facts (subject, predicate, object names) of all rows, in the
+        // order of the rows and template lines. A line is left out for a row
+        // in which one of its placeholders is NULL or empty. A placeholder
+        // that names no column is thrown as std::runtime_error.
+        std::vector<std::array<std::string, 3>> expand(const RowSet& rows) const;
+
+    private:
+        // A part of a fact: literal text and placeholders, the latter as
+        // indexes into _placeholders.
+        struct Piece
+        {
+            std::string text;
+            int         placeholder{-1};
+        };
+        using Term = std::vector<Piece>;
+
+        std::string                      _text;
+        std::vector<std::string>         _placeholders;
+        std::vector<std::array<Term, 3>> _lines;
+    };
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "rule_template.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <cstring>
+#include <functional>
+#include <stdexcept>
+
+using namespace zelph::io;
+
+namespace
+{
+    bool is_delimiter(const char c)
+    {
+        return std::isspace(static_cast<unsigned char>(c)) || (c != '\0' && std::strchr("(){}<>,", c));
+    }
+
+    // Calls f for each token of the rule outside quotes with its position
+    // and length.
+    void for_each_token(const std::string& rule, const std::function<void(size_t, size_t)>& f)
+    {
+        for (size_t i = 0; i < rule.size();)
+        {
+            if (rule[i] == '"')
+            {
+                const size_t end = rule.find('"', i + 1);
+                i                = end == std::string::npos ? rule.size() : end + 1;
+            }
+            else if (is_delimiter(rule[i]))
+            {
+                ++i;
+            }
+            else
+            {
+                size_t end = i;
+                while (end < rule.size() && rule[end] != '"' && !is_delimiter(rule[end]))
+                    ++end;
+                f(i, end - i);
+                i = end;
+            }
+        }
+    }
+}
+
+RuleTemplate::RuleTemplate(std::vector<std::string> parameters, std::string rule)
+    : _parameters(std::move(parameters))
+    , _rule(std::move(rule))
+{
+    if (_parameters.empty()) throw std::runtime_error("a template needs at least one parameter");
+    if (_rule.find("=>") == std::string::npos) throw std::runtime_error("'" + _rule + "' is not a rule");
+
+    std::vector<bool> used(_parameters.size(), false);
+    for (size_t i = 0; i < _parameters.size(); ++i)
+    {
+        const std::string& p = _parameters[i];
+        if (p.empty() || p.find('"') != std::string::npos || std::any_of(p.begin(), p.end(), is_delimiter))
+            throw std::runtime_error("parameter '" + p + "' must be a single token");
+        if (std::find(_parameters.begin(), _parameters.begin() + i, p) != _parameters.begin() + i)
+            throw std::runtime_error("parameter '" + p + "' is given twice");
+    }
+
+    for_each_token(_rule, [&](const size_t pos, const size_t len)
+                   {
+        auto it = std::find(_parameters.begin(), _parameters.end(), _rule.substr(pos, len));
+        if (it != _parameters.end()) used[it - _parameters.begin()] = true; });
+
+    for (size_t i = 0; i < _parameters.size(); ++i)
+    {
+        if (!used[i]) throw std::runtime_error("parameter '" + _parameters[i] + "' does not occur in the rule");
+    }
+}
+
+std::string RuleTemplate::instantiate(const std::vector<std::string>& arguments) const
+{
+    if (arguments.size() != _parameters.size())
+        throw std::runtime_error("expected " + std::to_string(_parameters.size()) + " arguments, got " + std::to_string(arguments.size()));
+    for (const std::string& argument : arguments)
+    {
+        if (argument.empty() || argument.find_first_of("\"\n") != std::string::npos)
+            throw std::runtime_error("argument '" + argument + "' must be a name without quotes or line breaks");
+    }
+
+    std::string result;
+    size_t      copied = 0;
+    for_each_token(_rule, [&](const size_t pos, const size_t len)
+                   {
+        auto it = std::find(_parameters.begin(), _parameters.end(), _rule.substr(pos, len));
+        if (it == _parameters.end()) return;
+        result += _rule.substr(copied, pos - copied) + "\"" + arguments[it - _parameters.begin()] + "\"";
+        copied = pos + len; });
+    return result + _rule.substr(copied);
+}
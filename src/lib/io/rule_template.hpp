@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <string>
+#include <vector>
+
+namespace zelph::io
+{
+    // A rule skeleton whose parameters stand for relations (see .template),
+    // so that one definition yields the rule for many relations: with the
+    // parameter R, "(X R Y, Y R Z) => (X R Z)" becomes
+    // (X "is ancestor of" Y, Y "is ancestor of" Z) => (X "is ancestor of" Z)
+    // for the argument "is ancestor of". A parameter is a token of the rule
+    // outside quotes, usually written like a variable so that the skeleton
+    // reads like a rule. Errors are thrown as std::runtime_error.
+    class RuleTemplate
+    {
+    public:
+        RuleTemplate(std::vector<std::string> parameters, std::string rule);
+
+        const std::vector<std::string>& parameters() const { return _parameters; }
+        const std::string&              rule() const { return _rule; }
+
+        // The rule with each parameter replaced by the argument at its
+        // position, quoted.
+        std::string instantiate(const std::vector<std::string>& arguments) const;
+
+    private:
+        std::vector<std::string> _parameters;
+        std::string              _rule;
+    };
+}
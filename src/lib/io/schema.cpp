@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "schema.hpp"
+
+#include "network/zelph.hpp"
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+std::optional<SchemaViolation> zelph::io::schema_violation(const network::Zelph* n, const std::vector<Node>& facts)
+{
+    const Node domain = n->get_node("domain", n->lang());
+    const Node range  = n->get_node("range", n->lang());
+    if (!domain && !range) return std::nullopt;
+
+    for (Node declaration : facts)
+    {
+        const Node kind = n->parse_relation(declaration);
+        if (kind == 0 || (kind != domain && kind != range)) continue;
+
+        network::adjacency_set classes;
+        const Node             relation = n->parse_fact(declaration, classes);
+        if (relation == 0 || classes.size() != 1) continue;
+
+        for (Node fact : facts)
+        {
+            if (fact == declaration || n->parse_relation(fact) != relation) continue;
+
+            network::adjacency_set objects;
+            const Node             subject = n->parse_fact(fact, objects);
+            if (subject == 0 || objects.empty()) continue;
+
+            SchemaViolation violation;
+            violation.fact     = fact;
+            violation.relation = relation;
+            violation.subject  = kind == domain;
+            violation.node     = violation.subject ? subject : *objects.begin();
+            violation.expected = *classes.begin();
+            return violation;
+        }
+    }
+    return std::nullopt;
+}
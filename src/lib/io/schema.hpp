@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <optional>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // Domain and range constraints of relations (see stdlib/schema.zph):
+    // (R domain C) requires the subject of every R statement to be an
+    // instance (~) of C, (R range C) its object. The two rules below
+    // detect statements that break the schema as contradictions.
+    inline constexpr const char* schema_rules[][2] = {
+        {"(R domain C, A R B, ¬(A ~ C))", "!"},
+        {"(R range C, A R B, ¬(B ~ C))", "!"}};
+
+    // A contradiction of one of these rules, read back from the facts it
+    // matched: fact (A R B) breaks the declaration (R domain C) if subject
+    // is true, (R range C) otherwise; node is A or B, expected is C.
+    struct SchemaViolation
+    {
+        network::Node fact{0};
+        network::Node relation{0};
+        bool          subject{true};
+        network::Node node{0};
+        network::Node expected{0};
+    };
+
+    // The schema violation described by the facts of a contradiction (see
+    // network::Reasoning::conflicts), or none if it is another kind of
+    // contradiction. domain and range are the nodes named so in the current
+    // language.
+    std::optional<SchemaViolation> schema_violation(const network::Zelph* n, const std::vector<network::Node>& facts);
+}
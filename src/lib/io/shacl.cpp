@@ -0,0 +1,308 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "shacl.hpp"
+
+#include "facts.hpp"
+#include "network/zelph.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <optional>
+#include <set>
+#include <stdexcept>
+#include <string>
+#include <unordered_map>
+#include <utility>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    const std::string sh_ns   = "http://www.w3.org/ns/shacl#";
+    const std::string rdfs_ns = "http://www.w3.org/2000/01/rdf-schema#";
+
+    // The local name of a term of the vocabulary ns, written with its IRI
+    // or with the conventional prefix, or an empty string.
+    std::string local_name(const std::string& name, const std::string& ns, const std::string& prefix)
+    {
+        if (name.starts_with(ns)) return name.substr(ns.size());
+        if (name.starts_with(prefix + ":")) return name.substr(prefix.size() + 1);
+        return {};
+    }
+
+    std::optional<size_t> number(const std::string& name)
+    {
+        if (name.empty() || !std::all_of(name.begin(), name.end(), [](unsigned char c)
+                                         { return std::isdigit(c); }))
+            return std::nullopt;
+        try
+        {
+            return std::stoull(name);
+        }
+        catch (const std::out_of_range&)
+        {
+            return std::nullopt;
+        }
+    }
+
+    class Validator
+    {
+    public:
+        explicit Validator(const zelph::network::Zelph* n)
+            : _n(n)
+        {
+            for (const auto& fact : exportable_facts(n))
+            {
+                for (Node object : fact.objects)
+                {
+                    _out[fact.subject].emplace_back(fact.predicate, object);
+                    _by_predicate[fact.predicate].emplace_back(fact.subject, object);
+                    if (local_name(name(fact.predicate), rdfs_ns, "rdfs") == "subClassOf")
+                    {
+                        _superclasses[fact.subject].push_back(object);
+                        _subclasses[object].push_back(fact.subject);
+                    }
+                }
+            }
+        }
+
+        ShapeReport validate()
+        {
+            ShapeReport report;
+
+            std::set<Node> shapes;
+            for (const auto& [subject, edges] : _out)
+                for (const auto& [predicate, object] : edges)
+                    if ((predicate == _n->core.IsA && sh(object) == "NodeShape") || sh(predicate).starts_with("target"))
+                        shapes.insert(subject);
+
+            for (Node shape : shapes)
+            {
+                std::set<Node> focus;
+                bool           targeted = false;
+                for (const auto& [predicate, object] : edges(shape))
+                {
+                    const std::string term = sh(predicate);
+                    if (term == "targetClass")
+                    {
+                        for (Node cls : subclasses(object))
+                            for (const auto& [instance, of] : triples(_n->core.IsA))
+                                if (of == cls) focus.insert(instance);
+                    }
+                    else if (term == "targetNode")
+                        focus.insert(object);
+                    else if (term == "targetSubjectsOf")
+                    {
+                        for (const auto& [s, o] : triples(object))
+                            focus.insert(s);
+                    }
+                    else if (term == "targetObjectsOf")
+                    {
+                        for (const auto& [s, o] : triples(object))
+                            focus.insert(o);
+                    }
+                    else
+                        continue;
+                    targeted = true;
+                }
+                if (!targeted) continue;
+
+                ++report.shapes;
+                report.focus += focus.size();
+                for (const auto& [predicate, property] : edges(shape))
+                    if (sh(predicate) == "property") check(property, focus, report);
+            }
+            return report;
+        }
+
+    private:
+        void check(Node property, const std::set<Node>& focus, ShapeReport& report)
+        {
+            Node                  path = 0;
+            std::optional<size_t> min_count, max_count;
+            std::vector<Node>     classes;
+            for (const auto& [predicate, object] : edges(property))
+            {
+                const std::string term = sh(predicate);
+                if (term == "path")
+                    path = name(object).empty() ? 0 : object;
+                else if (term == "minCount")
+                    min_count = number(name(object));
+                else if (term == "maxCount")
+                    max_count = number(name(object));
+                else if (term == "class")
+                    classes.push_back(object);
+            }
+            if (path == 0)
+            {
+                ++report.skipped;
+                return;
+            }
+
+            for (Node f : focus)
+            {
+                std::set<Node> values;
+                for (const auto& [predicate, object] : edges(f))
+                    if (predicate == path) values.insert(object);
+
+                ShapeResult result;
+                result.focus = f;
+                result.path  = path;
+                result.shape = property;
+                result.count = values.size();
+                if (min_count && values.size() < *min_count)
+                {
+                    result.component = ShapeResult::Component::MinCount;
+                    result.bound     = *min_count;
+                    report.results.push_back(result);
+                }
+                if (max_count && values.size() > *max_count)
+                {
+                    result.component = ShapeResult::Component::MaxCount;
+                    result.bound     = *max_count;
+                    report.results.push_back(result);
+                }
+                for (Node cls : classes)
+                {
+                    for (Node value : values)
+                    {
+                        if (is_instance(value, cls)) continue;
+                        result.component = ShapeResult::Component::Class;
+                        result.bound     = 0;
+                        result.value     = value;
+                        result.expected  = cls;
+                        report.results.push_back(result);
+                    }
+                }
+            }
+        }
+
+        bool is_instance(Node value, Node cls)
+        {
+            for (const auto& [predicate, object] : edges(value))
+                if (predicate == _n->core.IsA && superclasses(object).contains(cls)) return true;
+            return false;
+        }
+
+        // cls and the classes it is rdfs:subClassOf, transitively.
+        const std::set<Node>& superclasses(Node cls)
+        {
+            auto [it, inserted] = _superclass_closure.try_emplace(cls);
+            if (inserted) it->second = closure(cls, _superclasses);
+            return it->second;
+        }
+
+        std::set<Node> subclasses(Node cls) const
+        {
+            return closure(cls, _subclasses);
+        }
+
+        static std::set<Node> closure(Node start, const std::unordered_map<Node, std::vector<Node>>& edges)
+        {
+            std::set<Node>    result{start};
+            std::vector<Node> pending{start};
+            while (!pending.empty())
+            {
+                const Node current = pending.back();
+                pending.pop_back();
+                const auto it = edges.find(current);
+                if (it == edges.end()) continue;
+                for (Node next : it->second)
+                    if (result.insert(next).second) pending.push_back(next);
+            }
+            return result;
+        }
+
+        const std::vector<std::pair<Node, Node>>& edges(Node subject) const
+        {
+            const auto it = _out.find(subject);
+            return it == _out.end() ? _none : it->second;
+        }
+
+        const std::vector<std::pair<Node, Node>>& triples(Node predicate) const
+        {
+            const auto it = _by_predicate.find(predicate);
+            return it == _by_predicate.end() ? _none : it->second;
+        }
+
+        std::string name(Node nd) const
+        {
+            return _n->get_name(nd, _n->lang(), false);
+        }
+
+        std::string sh(Node nd) const
+        {
+            return local_name(name(nd), sh_ns, "sh");
+        }
+
+        const zelph::network::Zelph*                                 _n;
+        std::unordered_map<Node, std::vector<std::pair<Node, Node>>> _out;          // subject -> (predicate, object)
+        std::unordered_map<Node, std::vector<std::pair<Node, Node>>> _by_predicate; // predicate -> (subject, object)
+        std::unordered_map<Node, std::vector<Node>>                  _superclasses;
+        std::unordered_map<Node, std::vector<Node>>                  _subclasses;
+        std::unordered_map<Node, std::set<Node>>                     _superclass_closure;
+        const std::vector<std::pair<Node, Node>>                     _none;
+    };
+}
+
+const char* zelph::io::component_name(const ShapeResult::Component component)
+{
+    switch (component)
+    {
+    case ShapeResult::Component::MinCount:
+        return "sh:MinCountConstraintComponent";
+    case ShapeResult::Component::MaxCount:
+        return "sh:MaxCountConstraintComponent";
+    case ShapeResult::Component::Class:
+        return "sh:ClassConstraintComponent";
+    }
+    return "";
+}
+
+std::string zelph::io::result_message(const network::Zelph* n, const ShapeResult& result)
+{
+    switch (result.component)
+    {
+    case ShapeResult::Component::MinCount:
+        return std::to_string(result.count) + " values, at least " + std::to_string(result.bound) + " required";
+    case ShapeResult::Component::MaxCount:
+        return std::to_string(result.count) + " values, at most " + std::to_string(result.bound) + " allowed";
+    case ShapeResult::Component::Class:
+        break;
+    }
+    std::string value, expected;
+    zelph::string::node_to_string(n, value, n->lang(), result.value, 3);
+    zelph::string::node_to_string(n, expected, n->lang(), result.expected, 3);
+    return zelph::string::unmark_identifiers(value) + " is not ~ " + zelph::string::unmark_identifiers(expected);
+}
+
+ShapeReport zelph::io::validate_shapes(const network::Zelph* n)
+{
+    return Validator(n).validate();
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <cstddef>
+#include <string>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // Validation against SHACL shapes stated in the network, e.g. imported
+    // from a Turtle shapes graph. The vocabulary is recognized by its full
+    // IRI or by the conventional prefix sh:. Supported are
+    //   - node shapes (S ~ sh:NodeShape, or any node with a target), with
+    //     the targets sh:targetClass, sh:targetNode, sh:targetSubjectsOf
+    //     and sh:targetObjectsOf,
+    //   - their property shapes (S sh:property P) with a predicate as
+    //     sh:path and the constraints sh:minCount, sh:maxCount and sh:class.
+    // Like in SHACL, a value conforms to sh:class C if it is an instance (~)
+    // of C or of a class that is rdfs:subClassOf C, transitively. Property
+    // shapes with other paths (inverse or sequence paths) are skipped, as
+    // are other constraint components.
+    struct ShapeResult
+    {
+        enum class Component
+        {
+            MinCount,
+            MaxCount,
+            Class
+        };
+
+        network::Node focus{0};
+        network::Node path{0};
+        network::Node shape{0}; // the property shape
+        Component     component{Component::MinCount};
+        size_t        count{0};    // MinCount, MaxCount: number of values
+        size_t        bound{0};    // MinCount, MaxCount: the limit
+        network::Node value{0};    // Class: the value that is no instance
+        network::Node expected{0}; // Class: the class
+    };
+
+    // The prefixed name of the SHACL constraint component, e.g.
+    // sh:MinCountConstraintComponent.
+    const char* component_name(ShapeResult::Component component);
+
+    // A one-line description of result, e.g. "2 values, at most 1 allowed".
+    std::string result_message(const network::Zelph* n, const ShapeResult& result);
+
+    struct ShapeReport
+    {
+        size_t                   shapes{0};  // node shapes with at least one target
+        size_t                   focus{0};   // focus nodes checked, counted per shape
+        size_t                   skipped{0}; // property shapes that were not validated
+        std::vector<ShapeResult> results;
+    };
+
+    ShapeReport validate_shapes(const network::Zelph* n);
+}
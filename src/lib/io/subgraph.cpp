@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "subgraph.hpp"
+
+#include "graph_export.hpp"
+#include "network/zelph.hpp"
+
+#include <limits>
+#include <map>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+NodeCopier::NodeCopier(const network::Zelph* source, network::Zelph* target)
+    : _source(source)
+    , _target(target)
+{
+}
+
+Node NodeCopier::copy(const Node nd, const int depth)
+{
+    if (auto it = _copied.find(nd); it != _copied.end()) return it->second;
+
+    Node result = 0;
+    if (const std::string core = _source->get_core_name(nd); !core.empty())
+        result = _target->get_core_node(core);
+
+    if (result == 0 && network::Zelph::is_hash(nd) && depth < 64)
+    {
+        // A statement used as subject or object of another one.
+        const Node             predicate = _source->parse_relation(nd);
+        network::adjacency_set objects;
+        const Node             subject = predicate ? _source->parse_fact(nd, objects) : 0;
+        if (subject != 0 && subject != nd)
+        {
+            network::adjacency_set copied_objects;
+            for (const Node object : objects)
+                copied_objects.insert(copy(object, depth + 1));
+            result = _target->fact(copy(subject, depth + 1), copy(predicate, depth + 1), copied_objects);
+        }
+    }
+
+    if (result == 0)
+    {
+        for (const std::string& lang : _source->get_languages())
+        {
+            const std::string name = _source->get_name(nd, lang, false);
+            if (name.empty()) continue;
+            if (result == 0)
+                result = _target->node(name, lang);
+            else if (_target->get_node(name, lang) == 0)
+                _target->set_name(result, name, lang, false);
+        }
+    }
+
+    if (result == 0) result = _target->create_node();
+    _copied.emplace(nd, result);
+    return result;
+}
+
+size_t zelph::io::copy_subgraph(const network::Zelph*                      source,
+                                network::Zelph*                            target,
+                                const std::vector<Node>&                   roots,
+                                const int                                  depth,
+                                const std::function<bool(network::Node)>& follow)
+{
+    // neighborhood yields one edge per object; regroup them into facts.
+    struct Statement
+    {
+        Node                   subject;
+        Node                   predicate;
+        network::adjacency_set objects;
+    };
+    std::map<Node, Statement> statements;
+    for (const GraphEdge& edge : neighborhood(source, roots, depth, std::numeric_limits<size_t>::max(), follow))
+    {
+        Statement& statement = statements.try_emplace(edge.relation, Statement{edge.subject, edge.predicate, {}}).first->second;
+        statement.objects.insert(edge.object);
+    }
+
+    NodeCopier copier(source, target);
+    for (const auto& [relation, statement] : statements)
+    {
+        network::adjacency_set objects;
+        for (const Node object : statement.objects)
+            objects.insert(copier.copy(object));
+        target->fact(copier.copy(statement.subject), copier.copy(statement.predicate), objects);
+    }
+    return statements.size();
+}
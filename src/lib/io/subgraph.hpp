@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network/network_types.hpp"
+
+#include <functional>
+#include <unordered_map>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    // Carries nodes of one network over to another: core nodes by their
+    // core name, named nodes by their names in all languages, statements
+    // (e.g. nested as subject or object) as a copy of the statement, and
+    // other nodes as new unnamed nodes. Each node is copied once.
+    class NodeCopier
+    {
+    public:
+        NodeCopier(const network::Zelph* source, network::Zelph* target);
+
+        network::Node copy(network::Node nd, int depth = 0);
+
+    private:
+        const network::Zelph*                            _source;
+        network::Zelph*                                  _target;
+        std::unordered_map<network::Node, network::Node> _copied;
+    };
+
+    // Copies the statements within depth steps of roots (see neighborhood)
+    // from source into target, following only the predicates accepted by
+    // follow (all if empty). Nodes are carried over by their names in all
+    // languages, core nodes by their core name, and statements nested as
+    // subject or object are copied with the statements referring to them.
+    // Deduced statements become stated ones; rules are not copied. Returns
+    // the number of statements copied.
+    size_t copy_subgraph(const network::Zelph*                      source,
+                         network::Zelph*                            target,
+                         const std::vector<network::Node>&          roots,
+                         int                                        depth,
+                         const std::function<bool(network::Node)>& follow = {});
+}
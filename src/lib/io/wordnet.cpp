@@ -0,0 +1,241 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "wordnet.hpp"
+
+#include "network/zelph.hpp"
+
+#include <chrono>
+#include <filesystem>
+#include <sstream>
+#include <stdexcept>
+#include <unordered_map>
+#include <unordered_set>
+
+using namespace zelph::io;
+using zelph::network::Node;
+
+namespace
+{
+    const std::unordered_map<std::string, std::string> relation_names{
+        {"@", "hypernym"},
+        {"@i", "instance_hypernym"},
+        {"~", "hyponym"},
+        {"~i", "instance_hyponym"},
+        {"#m", "member_holonym"},
+        {"#s", "substance_holonym"},
+        {"#p", "part_holonym"},
+        {"%m", "member_meronym"},
+        {"%s", "substance_meronym"},
+        {"%p", "part_meronym"},
+        {"!", "antonym"},
+        {"*", "entailment"},
+        {">", "cause"},
+        {"&", "similar_to"},
+        {"=", "attribute"},
+        {"^", "also_see"},
+        {"$", "verb_group"},
+        {"<", "participle"},
+        {"\\", "pertainym"},
+        {"+", "derivation"},
+        {";c", "domain_topic"},
+        {"-c", "member_topic"},
+        {";r", "domain_region"},
+        {"-r", "member_region"},
+        {";u", "domain_usage"},
+        {"-u", "member_usage"}};
+
+    std::string synset_name(const std::string& offset, const std::string& pos)
+    {
+        return "wn:" + offset + "-" + (pos == "s" ? "a" : pos);
+    }
+
+    // ice_cream -> ice cream, beautiful(a) -> beautiful
+    std::string word_name(std::string word)
+    {
+        if (!word.empty() && word.back() == ')')
+        {
+            const size_t open = word.rfind('(');
+            if (open != std::string::npos && open > 0) word.erase(open);
+        }
+        for (char& c : word)
+            if (c == '_') c = ' ';
+        return word;
+    }
+}
+
+WordNetOptions zelph::io::parse_wordnet_options(const std::vector<std::string>& args)
+{
+    WordNetOptions options;
+    for (const std::string& option : args)
+    {
+        if (option == "glosses")
+            options.glosses = true;
+        else if (option == "nolemmas")
+            options.lemmas = false;
+        else if (option == "relations=all")
+            options.relations.clear();
+        else if (option.starts_with("relations="))
+        {
+            options.relations.clear();
+            std::istringstream list(option.substr(10));
+            for (std::string relation; std::getline(list, relation, ',');)
+            {
+                if (relation.empty()) continue;
+                bool known = false;
+                for (const auto& [symbol, name] : relation_names)
+                    known = known || name == relation;
+                if (!known) throw std::runtime_error("unknown WordNet relation '" + relation + "'");
+                options.relations.insert(relation);
+            }
+            if (options.relations.empty()) throw std::runtime_error("expected relations after relations=");
+        }
+        else
+            throw std::runtime_error("unknown option '" + option + "'");
+    }
+    return options;
+}
+
+std::string zelph::io::wordnet_relation(const std::string& symbol)
+{
+    auto it = relation_names.find(symbol);
+    return it == relation_names.end() ? "" : it->second;
+}
+
+std::vector<std::string> zelph::io::wordnet_data_files(const std::string& path)
+{
+    if (!std::filesystem::is_directory(path)) return {path};
+
+    std::vector<std::string> result;
+    for (const char* pos : {"noun", "verb", "adj", "adv"})
+    {
+        const std::filesystem::path file = std::filesystem::path(path) / (std::string("data.") + pos);
+        if (std::filesystem::exists(file)) result.push_back(file.string());
+    }
+    return result;
+}
+
+BulkProgress zelph::io::import_wordnet(network::Zelph* n, std::istream& in, const WordNetOptions& options, const std::string& file_name)
+{
+    const auto   start = std::chrono::steady_clock::now();
+    BulkProgress progress;
+
+    auto report = [&]
+    {
+        progress.seconds = std::chrono::duration<double>(std::chrono::steady_clock::now() - start).count();
+        options.progress(progress);
+    };
+
+    std::unordered_map<std::string, Node> names;
+    std::unordered_set<Node>              typed_predicates;
+    auto                                  name_node = [&](const std::string& name)
+    {
+        auto it = names.find(name);
+        if (it != names.end()) return it->second;
+        return names.emplace(name, n->node(name, options.lang)).first->second;
+    };
+
+    auto emit = [&](const std::string& subject, const std::string& relation, const std::string& object)
+    {
+        const Node predicate = name_node(relation);
+        if (typed_predicates.insert(predicate).second)
+            n->fact_import_trusted_single_object(predicate, n->core.IsA, n->core.RelationTypeCategory);
+        n->fact_import_trusted_single_object(name_node(subject), predicate, name_node(object));
+        ++progress.facts;
+    };
+
+    // Caches are invalidated also if a malformed line ends the import.
+    struct Invalidate
+    {
+        network::Zelph* n;
+        ~Invalidate() { n->invalidate_fact_structures_cache(); }
+    } invalidate{n};
+
+    for (std::string line; std::getline(in, line);)
+    {
+        ++progress.lines;
+        progress.bytes += line.size() + 1;
+        if (!line.empty() && line.back() == '\r') line.pop_back();
+
+        if (!line.empty() && line[0] != ' ')
+        {
+            auto fail = [&](const std::string& message)
+            {
+                throw std::runtime_error(file_name + ":" + std::to_string(progress.lines) + ": " + message);
+            };
+
+            const size_t bar = line.find(" | ");
+            std::istringstream fields(line.substr(0, bar));
+
+            std::string offset, lex_filenum, pos, count;
+            if (!(fields >> offset >> lex_filenum >> pos >> count)) fail("expected synset offset, lexicographer file, part of speech and word count");
+            const std::string synset = synset_name(offset, pos);
+
+            size_t words = 0;
+            try
+            {
+                words = std::stoul(count, nullptr, 16);
+            }
+            catch (const std::exception&)
+            {
+                fail("invalid word count '" + count + "'");
+            }
+            for (size_t i = 0; i < words; ++i)
+            {
+                std::string word, lex_id;
+                if (!(fields >> word >> lex_id)) fail("expected " + std::to_string(words) + " words");
+                if (options.lemmas) emit(synset, "lemma", word_name(word));
+            }
+
+            size_t pointers = 0;
+            if (!(fields >> pointers)) fail("expected a pointer count");
+            for (size_t i = 0; i < pointers; ++i)
+            {
+                std::string symbol, target, target_pos, source_target;
+                if (!(fields >> symbol >> target >> target_pos >> source_target)) fail("expected " + std::to_string(pointers) + " pointers");
+
+                const std::string relation = wordnet_relation(symbol);
+                if (relation.empty()) fail("unknown pointer symbol '" + symbol + "'");
+                if (options.relations.empty() || options.relations.count(relation))
+                    emit(synset, relation, synset_name(target, target_pos));
+            }
+
+            if (options.glosses && bar != std::string::npos)
+            {
+                std::string gloss = line.substr(bar + 3);
+                gloss.erase(gloss.find_last_not_of(' ') + 1);
+                if (!gloss.empty()) emit(synset, "gloss", gloss);
+            }
+        }
+
+        if (options.progress_interval && options.progress && progress.lines % options.progress_interval == 0) report();
+    }
+
+    if (options.progress)
+        report();
+    else
+        progress.seconds = std::chrono::duration<double>(std::chrono::steady_clock::now() - start).count();
+    return progress;
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "bulk_loader.hpp"
+
+#include <istream>
+#include <set>
+#include <string>
+#include <vector>
+
+namespace zelph::network
+{
+    class Zelph;
+}
+
+namespace zelph::io
+{
+    struct WordNetOptions
+    {
+        // Pointers to import, by name: hypernym, instance_hypernym, hyponym,
+        // instance_hyponym, member_holonym, substance_holonym, part_holonym,
+        // member_meronym, substance_meronym, part_meronym, antonym,
+        // entailment, cause, similar_to, attribute, also_see, verb_group,
+        // participle, pertainym, derivation, domain_topic, member_topic,
+        // domain_region, member_region, domain_usage, member_usage. Empty
+        // imports all. Hyponyms and meronyms are the inverses of hypernyms
+        // and holonyms, hence not imported by default.
+        std::set<std::string> relations{"hypernym", "instance_hypernym"};
+
+        bool lemmas{true};   // "<synset> lemma <word>" for each word of a synset
+        bool glosses{false}; // "<synset> gloss <definition and examples>"
+
+        // Language of the node names; empty means the current language.
+        std::string lang;
+
+        // See BulkOptions.
+        uint64_t                                  progress_interval{100000};
+        std::function<void(const BulkProgress&)> progress;
+    };
+
+    // The name of a WordNet pointer symbol (e.g. "@" -> "hypernym"), or an
+    // empty string for an unknown one.
+    std::string wordnet_relation(const std::string& symbol);
+
+    // Parses the options of .import-wordnet: relations=<name>,... (or
+    // relations=all), glosses and nolemmas. Throws std::runtime_error for an
+    // unknown option or relation.
+    WordNetOptions parse_wordnet_options(const std::vector<std::string>& args);
+
+    // The data files of a WordNet dict directory (data.noun, data.verb,
+    // data.adj and data.adv, those that exist), or path itself if it is
+    // not a directory.
+    std::vector<std::string> wordnet_data_files(const std::string& path);
+
+    // Imports a WordNet 3 data file in the Princeton format (data.noun,
+    // data.verb, data.adj or data.adv; lines starting with blanks are the
+    // license header). Each synset is a node named wn:<offset>-<pos>, e.g.
+    // wn:02084071-n for the synset of dog, pos one of n, v, a (also for
+    // adjective satellites) and r. Pointers become facts between synsets,
+    // e.g. "wn:02084071-n hypernym wn:02083346-n"; lexical pointers between
+    // single words are imported between their synsets as well. Words have
+    // underscores replaced by blanks and adjective markers such as (a)
+    // removed. Facts are inserted via the trusted import path like
+    // BulkLoader. A malformed line is thrown as std::runtime_error naming
+    // file_name and the line; facts before it are kept.
+    BulkProgress import_wordnet(network::Zelph*       n,
+                                std::istream&         in,
+                                const WordNetOptions& options   = {},
+                                const std::string&    file_name = "<input>");
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "name_index.hpp"
+
+#include "string/string_utils.hpp"
+
+#include <algorithm>
+#include <limits>
+#include <numeric>
+#include <string_view>
+#include <tuple>
+
+using namespace zelph::network;
+
+namespace
+{
+    // Prefix and substring candidates ranked per search, as a multiple of
+    // the limit: a one-letter prefix among millions of names must not
+    // collect them all.
+    constexpr size_t candidate_factor = 16;
+    constexpr size_t min_candidates   = 256;
+
+    uint32_t pack_trigram(const char* p)
+    {
+        return (static_cast<uint32_t>(static_cast<unsigned char>(p[0])) << 16)
+             | (static_cast<uint32_t>(static_cast<unsigned char>(p[1])) << 8)
+             | static_cast<uint32_t>(static_cast<unsigned char>(p[2]));
+    }
+
+    // Levenshtein distance of a and b, or bound + 1 once it exceeds bound.
+    uint32_t edit_distance(std::string_view a, std::string_view b, const uint32_t bound)
+    {
+        if (a.size() > b.size()) std::swap(a, b);
+        if (b.size() - a.size() > bound) return bound + 1;
+
+        std::vector<uint32_t> row(a.size() + 1);
+        std::iota(row.begin(), row.end(), 0u);
+        for (size_t j = 1; j <= b.size(); ++j)
+        {
+            uint32_t diagonal = row[0];
+            row[0]            = static_cast<uint32_t>(j);
+            uint32_t lowest   = row[0];
+            for (size_t i = 1; i <= a.size(); ++i)
+            {
+                const uint32_t above = row[i];
+                row[i]               = std::min({row[i] + 1, row[i - 1] + 1, diagonal + (a[i - 1] == b[j - 1] ? 0u : 1u)});
+                diagonal             = above;
+                lowest               = std::min(lowest, row[i]);
+            }
+            if (lowest > bound) return bound + 1;
+        }
+        return row[a.size()];
+    }
+}
+
+NameIndex::NameIndex(std::vector<std::pair<std::string, Node>> names)
+{
+    _entries.reserve(names.size());
+    for (auto& [name, node] : names)
+    {
+        std::string key = string::unicode::normalize(name, string::unicode::Normalization::NFC, true);
+        _entries.push_back(Entry{std::move(key), std::move(name), node});
+    }
+    std::sort(_entries.begin(), _entries.end(), [](const Entry& a, const Entry& b)
+              { return std::tie(a.key, a.node) < std::tie(b.key, b.node); });
+
+    for (uint32_t i = 0; i < _entries.size(); ++i)
+        for (uint32_t trigram : trigrams_of(_entries[i].key))
+            _postings[trigram].push_back(i); // ascending, as i is
+}
+
+std::vector<uint32_t> NameIndex::trigrams_of(const std::string& text) const
+{
+    std::vector<uint32_t> trigrams;
+    for (size_t i = 0; i + 3 <= text.size(); ++i)
+        trigrams.push_back(pack_trigram(text.data() + i));
+    std::sort(trigrams.begin(), trigrams.end());
+    trigrams.erase(std::unique(trigrams.begin(), trigrams.end()), trigrams.end());
+    return trigrams;
+}
+
+std::vector<NameIndex::Match> NameIndex::search(const std::string& query, const size_t limit, const bool fuzzy) const
+{
+    if (query.empty()) return {};
+
+    const size_t cap = limit == 0 ? std::numeric_limits<size_t>::max() : std::max(limit * candidate_factor, min_candidates);
+
+    // (kind, distance, entry) of each match
+    std::vector<std::tuple<MatchKind, uint32_t, uint32_t>> found;
+    auto                                                   has_prefix = [&](const uint32_t i)
+    { return _entries[i].key.compare(0, query.size(), query) == 0; };
+
+    // Exact and prefix matches are a contiguous range of the sorted keys.
+    auto first = std::lower_bound(_entries.begin(), _entries.end(), query, [](const Entry& e, const std::string& q)
+                                  { return e.key < q; });
+    for (auto i = static_cast<uint32_t>(first - _entries.begin()); i < _entries.size() && has_prefix(i) && found.size() < cap; ++i)
+        found.emplace_back(_entries[i].key.size() == query.size() ? MatchKind::Exact : MatchKind::Prefix, 0, i);
+
+    const std::vector<uint32_t> trigrams = trigrams_of(query);
+    if (!trigrams.empty())
+    {
+        // Substring matches: the entries in the posting lists of all the
+        // query's trigrams, walking the shortest list.
+        std::vector<const std::vector<uint32_t>*> lists;
+        for (uint32_t trigram : trigrams)
+        {
+            auto it = _postings.find(trigram);
+            if (it != _postings.end()) lists.push_back(&it->second);
+        }
+
+        if (lists.size() == trigrams.size())
+        {
+            std::sort(lists.begin(), lists.end(), [](const auto* a, const auto* b)
+                      { return a->size() < b->size(); });
+
+            size_t substrings = 0;
+            for (uint32_t i : *lists.front())
+            {
+                if (substrings == cap) break;
+                if (has_prefix(i)) continue;
+                if (!std::all_of(lists.begin() + 1, lists.end(), [i](const auto* list)
+                                 { return std::binary_search(list->begin(), list->end(), i); }))
+                    continue;
+                if (_entries[i].key.find(query) == std::string::npos) continue;
+
+                found.emplace_back(MatchKind::Substring, 0, i);
+                ++substrings;
+            }
+        }
+
+        // Fuzzy matches: an entry within distance d of the query shares
+        // all but at most 3 * d of its trigrams, so only entries sharing
+        // enough of them are compared.
+        if (fuzzy && (limit == 0 || found.size() < limit))
+        {
+            const uint32_t bound     = query.size() <= 5 ? 1 : 2;
+            const size_t   threshold = trigrams.size() > 3 * bound ? trigrams.size() - 3 * bound : 1;
+
+            ankerl::unordered_dense::map<uint32_t, uint32_t> shared;
+            for (const auto* list : lists)
+                for (uint32_t i : *list)
+                    ++shared[i];
+
+            ankerl::unordered_dense::set<uint32_t> matched;
+            for (const auto& match : found)
+                matched.insert(std::get<2>(match));
+
+            for (const auto& [i, count] : shared)
+            {
+                if (count < threshold || matched.count(i)) continue;
+                const uint32_t distance = edit_distance(_entries[i].key, query, bound);
+                if (distance <= bound) found.emplace_back(MatchKind::Fuzzy, distance, i);
+            }
+        }
+    }
+
+    std::sort(found.begin(), found.end(), [this](const auto& a, const auto& b)
+              {
+                  const auto& [kind_a, distance_a, i_a] = a;
+                  const auto& [kind_b, distance_b, i_b] = b;
+                  return std::make_tuple(kind_a, distance_a, _entries[i_a].key.size(), i_a)
+                       < std::make_tuple(kind_b, distance_b, _entries[i_b].key.size(), i_b); });
+
+    std::vector<Match> matches;
+    for (const auto& [kind, distance, i] : found)
+    {
+        if (limit != 0 && matches.size() == limit) break;
+        matches.push_back(Match{_entries[i].node, _entries[i].name, kind, distance});
+    }
+    return matches;
+}
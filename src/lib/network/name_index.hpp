@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "network_types.hpp"
+
+#include <ankerl/unordered_dense.h>
+
+#include <cstdint>
+#include <string>
+#include <utility>
+#include <vector>
+
+namespace zelph::network
+{
+    // Search structure over the names of one language: the case-folded
+    // names sorted for prefix lookups, and a trigram index that finds the
+    // names containing a substring, or resembling one, without visiting
+    // the others. Built once from a snapshot of the names and immutable
+    // afterwards (see Zelph::search_names).
+    class NameIndex
+    {
+    public:
+        enum class MatchKind
+        {
+            Exact,
+            Prefix,
+            Substring,
+            Fuzzy
+        };
+
+        struct Match
+        {
+            Node        node{0};
+            std::string name;
+            MatchKind   kind{MatchKind::Exact};
+            uint32_t    distance{0}; // edit distance of a fuzzy match, in bytes
+        };
+
+        // names: (name, node) pairs; the keys are folded with
+        // string::unicode::normalize.
+        explicit NameIndex(std::vector<std::pair<std::string, Node>> names);
+
+        // Matches of a folded query, exact ones first, then prefix,
+        // substring and, if fuzzy, fuzzy matches; within each kind the
+        // closest and shortest names first. Substring and fuzzy matches
+        // need a query of at least three bytes. limit 0 returns all.
+        std::vector<Match> search(const std::string& query, size_t limit, bool fuzzy) const;
+
+        size_t size() const { return _entries.size(); }
+
+    private:
+        struct Entry
+        {
+            std::string key; // folded name
+            std::string name;
+            Node        node{0};
+        };
+
+        std::vector<uint32_t> trigrams_of(const std::string& text) const;
+
+        std::vector<Entry>                                             _entries; // sorted by key
+        ankerl::unordered_dense::map<uint32_t, std::vector<uint32_t>> _postings; // trigram -> entries containing it
+    };
+}
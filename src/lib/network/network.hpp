@@ -127,6 +127,7 @@ namespace zelph::network
             }
 
             note_created(relation);
+            note_unobserved_change();
 
             auto [rel_right_it, inserted_right] =
                 (subject == object)
@@ -207,8 +208,15 @@ namespace zelph::network
             std::unique_lock<std::shared_mutex> lock_right(_smtx_right);
             _left.erase(node);
             _right.erase(node);
+            note_unobserved_change();
         }
 
+        // Counts the changes that bypass Zelph's fact-creation observer:
+        // node removals, trusted bulk inserts and loads. Reasoning compares
+        // it between runs to decide whether an incremental run is complete.
+        uint64_t unobserved_changes() const { return _unobserved_changes.load(std::memory_order_relaxed); }
+        void     note_unobserved_change() { _unobserved_changes.fetch_add(1, std::memory_order_relaxed); }
+
         void merge(Node from, Node into)
         {
             if (from == into)
@@ -780,6 +788,7 @@ namespace zelph::network
         mutable std::shared_mutex _mtx_weights;
         mutable std::shared_mutex _smtx_left;
         mutable std::shared_mutex _smtx_right;
+        std::atomic<uint64_t>     _unobserved_changes{0};
 
 #ifdef NDEBUG
     private:
@@ -27,12 +27,16 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #include "contradiction_error.hpp"
 #include "fact_structure.hpp"
+#include "reasoning_cancelled.hpp"
+#include "reasoning_limit_exceeded.hpp"
 #include "string/node_to_string.hpp"
 #include "string/string_utils.hpp"
 #include "zelph_impl.hpp"
 
+#include <algorithm>
 #include <cassert>
 #include <cmath>
+#include <sstream>
 #include <vector>
 
 using namespace zelph::network;
@@ -42,6 +46,29 @@ Reasoning::Reasoning(const io::OutputHandler& output)
     , _pool{std::make_unique<concurrency::ThreadPool>(std::thread::hardware_concurrency())}
     , _prof{this}
 {
+    observe_between_runs();
+
+    // A deduced fact that is stated outside a run is stated from then on:
+    // it no longer depends on its premises (see retract). Inside a run,
+    // existing facts are restated by instantiating consequences.
+    set_fact_restatement_observer([this](Node f)
+                                  {
+        if (_running > 0) return;
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        if (_deduced_facts.erase(f) == 0) return;
+        _derivations.erase(f);
+        _supports.erase(f); });
+}
+
+void Reasoning::set_thread_count(const size_t count)
+{
+    _pool = std::make_unique<concurrency::ThreadPool>(count == 0 ? std::max(std::thread::hardware_concurrency(), 1u) : count);
+}
+
+void Reasoning::set_deterministic(const bool on)
+{
+    if (on != _deterministic && _query_cache) clear_query_cache();
+    _deterministic = on;
 }
 
 void Reasoning::set_markdown_subdir(const std::string& subdir)
@@ -54,6 +81,85 @@ void Reasoning::set_query_collector(std::vector<std::shared_ptr<Variables>>* col
     _query_results = collector;
 }
 
+void Reasoning::set_rule_enabled(const Node rule, const bool enabled)
+{
+    if (get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+
+    if (enabled)
+        _disabled_rules.erase(rule);
+    else
+        _disabled_rules.insert(rule);
+}
+
+void Reasoning::set_rule_stratum(const Node rule, const int stratum)
+{
+    if (get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+
+    if (stratum == 0)
+        _rule_strata.erase(rule);
+    else
+        _rule_strata[rule] = stratum;
+}
+
+int Reasoning::rule_stratum(const Node rule) const
+{
+    auto it = _rule_strata.find(rule);
+    return it == _rule_strata.end() ? 0 : it->second;
+}
+
+// All rules, in the order the passes apply them: by stratum, then node ID.
+std::vector<Node> Reasoning::ordered_rules() const
+{
+    const adjacency_set& rules = _pImpl->get_left(core.Causes);
+    std::vector<Node>    result(rules.begin(), rules.end());
+    std::sort(result.begin(), result.end(), [this](Node a, Node b)
+              {
+        const int sa = rule_stratum(a), sb = rule_stratum(b);
+        return sa != sb ? sa < sb : a < b; });
+    return result;
+}
+
+void Reasoning::remove_rule(const Node rule)
+{
+    Zelph::remove_rule(rule);
+    _disabled_rules.erase(rule);
+    _rule_combinations.erase(rule);
+    _rule_weights.erase(rule);
+    _rule_contexts.erase(rule);
+    _rule_strata.erase(rule);
+    _default_rules.erase(rule);
+    for (const PropertyRule& p : _property_rules)
+        if (p.rule == rule)
+        {
+            const RelationDeclaration declaration = p.declaration;
+            std::erase_if(_property_rules, [&](const PropertyRule& q)
+                          { return q.declaration == declaration; });
+            break;
+        }
+
+    std::lock_guard<std::mutex> lock(_mtx_network);
+    for (auto& [fact, supports] : _supports)
+        std::erase_if(supports, [rule](const Support& s)
+                      { return s.rule == rule; });
+}
+
+void Reasoning::remove_rules()
+{
+    Zelph::remove_rules();
+    _disabled_rules.clear();
+    _rule_combinations.clear();
+    _rule_weights.clear();
+    _rule_contexts.clear();
+    _rule_strata.clear();
+    _default_rules.clear();
+    _property_rules.clear();
+
+    std::lock_guard<std::mutex> lock(_mtx_network);
+    _supports.clear();
+}
+
 void Reasoning::run(const bool print_deductions, const bool generate_markdown, const bool suppress_repetition, const bool silent)
 {
     chrono::StopWatch watch;
@@ -65,6 +171,32 @@ void Reasoning::run(const bool print_deductions, const bool generate_markdown, c
     _contradiction        = false;
     _total_matches        = 0;
     _total_contradictions = 0;
+    _run_deductions       = 0;
+    _fixpoint_reached     = false;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_limit);
+        _limit_reason.clear();
+        _pause_reason.clear();
+    }
+    start_rule_profile();
+
+    // Runs nested in this one (e.g. by retract) share its ID, so that
+    // request_cancel reaches them, too; once the outermost run ends, a
+    // request for it has nothing left to stop.
+    struct RunGuard
+    {
+        Reasoning* r;
+        explicit RunGuard(Reasoning* reasoning)
+            : r(reasoning)
+        {
+            if (r->_running++ == 0) r->_run_id = ++r->_runs_started;
+        }
+        ~RunGuard()
+        {
+            if (--r->_running == 0) r->_run_id = 0;
+            r->_cancel_requested = false;
+        }
+    } run_guard{this};
 
     if (_generate_markdown)
     {
@@ -75,11 +207,194 @@ void Reasoning::run(const bool print_deductions, const bool generate_markdown, c
         _markdown = std::make_unique<io::Markdown>(std::filesystem::path("mkdocs") / "docs" / _markdown_subdir, this);
     }
 
+    if (_strict_stratification)
+    {
+        const auto violations = stratification_violations();
+        if (!violations.empty())
+        {
+            const StratificationViolation& v = violations.front();
+            std::string                    producer, pattern, consumer;
+            string::node_to_string(this, producer, _lang, v.producer, 3);
+            string::node_to_string(this, pattern, _lang, v.pattern, 3);
+            string::node_to_string(this, consumer, _lang, v.consumer, 3);
+            throw std::runtime_error(string::unmark_identifiers(
+                "Rule set is not stratifiable: the consequences of " + producer + " may grow ¬" + pattern
+                + ", which is negated by " + consumer));
+        }
+    }
+
+    struct ProgressGuard
+    {
+        Reasoning* r;
+        ~ProgressGuard()
+        {
+            r->stop_progress_reports();
+            r->record_run(std::chrono::duration<double>(std::chrono::steady_clock::now() - r->_run_started).count());
+        }
+    } progress_guard{this};
+    start_progress_reports();
+
     if (!silent)
         diagnostic("Starting reasoning with " + std::to_string(_pool->count()) + " worker threads.");
 
     uint64_t seminaive_violations = 0;
 
+    if (!_seminaive || suppress_repetition)
+        invalidate_incremental(); // these passes leave deductions unaccounted for
+
+    // Strata run as consecutive stages (see set_rule_stratum): the rules of
+    // higher strata are disabled for the duration of a stage. Single-pass
+    // mode has no fixpoint to stage; its pass follows ordered_rules().
+    std::vector<int> strata;
+    if (!suppress_repetition)
+    {
+        for (Node rule : _pImpl->get_left(core.Causes))
+            if (is_rule_enabled(rule)) strata.push_back(rule_stratum(rule));
+        std::sort(strata.begin(), strata.end());
+        strata.erase(std::unique(strata.begin(), strata.end()), strata.end());
+    }
+
+    auto run_stages = [&]() -> uint64_t
+    {
+        if (strata.size() <= 1) return run_stage_with_properties(suppress_repetition, silent);
+
+        struct DisabledGuard
+        {
+            Reasoning*               r;
+            std::unordered_set<Node> saved;
+            ~DisabledGuard() { r->_disabled_rules = std::move(saved); }
+        } disabled_guard{this, _disabled_rules};
+
+        uint64_t violations = 0;
+        for (size_t stage = 0; stage < strata.size() && !stop_requested(); ++stage)
+        {
+            _disabled_rules = disabled_guard.saved;
+            for (Node rule : _pImpl->get_left(core.Causes))
+                if (rule_stratum(rule) > strata[stage]) _disabled_rules.insert(rule);
+
+            if (!silent)
+                diagnostic_stream() << "--- Stratum " << strata[stage] << " ---" << std::endl;
+            violations += run_stage_with_properties(false, silent);
+        }
+        return violations;
+    };
+    seminaive_violations = run_stages();
+
+    // Assumptions of default rules defeated by a contradiction go with the
+    // deductions that depended on them, and the stages run again to
+    // re-derive those that have another derivation (see set_rule_default).
+    if (!_default_rules.empty())
+    {
+        while (!stop_requested() && withdraw_defeated())
+        {
+            if (!silent)
+                diagnostic_stream() << "--- Defeated assumptions withdrawn ---" << std::endl;
+            seminaive_violations += run_stages();
+        }
+        _rederive_rules.clear();
+    }
+
+    std::string pause_reason;
+    if (stop_requested())
+    {
+        _done             = false;
+        _cancel_requested = false;
+        throw_builtin_error();
+
+        std::string limit_reason;
+        {
+            std::lock_guard<std::mutex> lock(_mtx_limit);
+            limit_reason.swap(_limit_reason);
+            pause_reason.swap(_pause_reason);
+        }
+        if (!limit_reason.empty())
+        {
+            if (!silent)
+                diagnostic_stream() << "Reasoning stopped after " << _run_deductions << " deductions: " << limit_reason << "." << std::endl;
+            throw reasoning_limit_exceeded(limit_reason);
+        }
+
+        if (pause_reason.empty())
+        {
+            if (!silent)
+                diagnostic_stream() << "Reasoning cancelled after " << _total_matches << " matches." << std::endl;
+            throw reasoning_cancelled();
+        }
+
+        out("Reasoning paused after " + std::to_string(_run_deductions) + " deductions: " + pause_reason
+                + ". Run again to continue.",
+            true);
+    }
+
+    if (_probabilistic)
+    {
+        update_marginals();
+        if (!silent)
+            diagnostic_stream() << "Marginal probabilities computed for " << _supports.size() << " deduced fact(s)." << std::endl;
+    }
+
+    if (!silent)
+        diagnostic_stream() << "Reasoning complete. Total unification matches processed: " << _total_matches
+                            << ". Total contradictions found: " << _total_contradictions << "." << std::endl;
+
+    if (_skipped > 0) diagnostic(" (skipped " + std::to_string(_skipped) + " deductions)", true);
+
+    if (_contradiction)
+    {
+        diagnostic("Found one or more contradictions!", true);
+    }
+
+    if (_done && suppress_repetition)
+    {
+        out("Warning: Additional reasoning iterations are required, but have been suppressed.", true);
+    }
+
+    _fixpoint_reached = pause_reason.empty() && !(_done && suppress_repetition);
+    refresh_views();
+
+    if (!silent)
+        diagnostic_stream() << "Reasoning summary: " << _total_matches << " matches processed, "
+                            << _total_contradictions << " contradictions found." << std::endl;
+    static std::unordered_set<Node> logged_relations;
+
+    if (_pool && !silent)
+    {
+        diagnostic_stream() << "Parallel unifications activated for " << logged_relations.size()
+                            << " distinct fixed relations." << std::endl;
+    }
+
+    logged_relations.clear();
+
+    watch.stop();
+
+    if (!silent)
+        diagnostic_stream() << "Reasoning complete in " << watch.format() << " – "
+                            << _total_matches << " matches processed, "
+                            << _total_contradictions << " contradictions found." << std::endl;
+
+    if (seminaive_violations > 0)
+    {
+        // The graph itself is complete at this point: the safety net kept
+        // re-applying classic evaluation until quiescence. The throw turns
+        // the incompleteness of delta seeding into a hard failure for tests
+        // and a visible error in the REPL.
+        throw std::runtime_error(
+            "Semi-naive completeness violation: the classic verification pass derived new facts in "
+            + std::to_string(seminaive_violations)
+            + " extra pass(es) after the delta drained. The final graph is complete, but delta "
+              "seeding missed at least one derivation. Please report this rule set at https://github.com/acrion/zelph/issues.");
+    }
+
+    stop_progress_reports();
+    if (_on_progress) _on_progress(progress(true));
+}
+
+// One complete evaluation of the enabled rules: semi-naive, single pass or
+// classic. Returns the safety-net violations of semi-naive evaluation.
+uint64_t Reasoning::run_stage(const bool suppress_repetition, const bool silent)
+{
+    uint64_t seminaive_violations = 0;
+
     if (_seminaive && !suppress_repetition)
     {
         seminaive_violations = run_fixpoint_seminaive(silent);
@@ -89,11 +404,12 @@ void Reasoning::run(const bool print_deductions, const bool generate_markdown, c
         // Single-pass mode never reaches a fixpoint, so the stratified
         // two-phase schedule does not apply; keep the historic behaviour
         // (one classic pass over all rules). The "suppressed" warning
-        // below still reports pending work via _done.
-        _done = false;
+        // in run() still reports pending work via _done.
+        _done           = false;
+        _run_iterations = 1;
         if (!silent)
             diagnostic_stream() << "--- Reasoning iteration 1 (single pass) ---" << std::endl;
-        for (Node rule : _pImpl->get_left(core.Causes))
+        for (Node rule : ordered_rules())
             apply_rule(rule, 0);
         _pool->wait();
     }
@@ -113,10 +429,11 @@ void Reasoning::run(const bool print_deductions, const bool generate_markdown, c
         // negated by another deferred rule, results within phase 2 depend
         // on rule order -- the classic limitation of non-stratifiable
         // programs. Contradiction-only deferred rules (consequence !) are
-        // always safe: they produce no facts.
+        // always safe: they produce no facts. stratification_violations()
+        // detects such rule sets; strict mode rejects them in run().
         std::vector<Node> positive_rules;
         std::vector<Node> deferred_rules;
-        for (Node rule : _pImpl->get_left(core.Causes))
+        for (Node rule : ordered_rules())
         {
             adjacency_set deductions;
             Node          condition = parse_fact(rule, deductions);
@@ -136,14 +453,15 @@ void Reasoning::run(const bool print_deductions, const bool generate_markdown, c
         {
             do
             {
-                _done = false;
-                ++iteration;
+                if (iterations_exhausted(iteration)) break;
+                _done           = false;
+                _run_iterations = ++iteration;
                 if (!silent)
                     diagnostic_stream() << "--- Reasoning iteration " << iteration << " ---" << std::endl;
                 for (Node rule : positive_rules)
                     apply_rule(rule, 0);
                 _pool->wait();
-            } while (_done);
+            } while (_done && !stop_requested());
 
             deferred_derived = false;
             if (!deferred_rules.empty())
@@ -156,63 +474,41 @@ void Reasoning::run(const bool print_deductions, const bool generate_markdown, c
                 _pool->wait();
                 deferred_derived = _done;
             }
-        } while (deferred_derived);
+        } while (deferred_derived && !stop_requested());
         _done = false;
     }
 
-    if (!silent)
-        diagnostic_stream() << "Reasoning complete. Total unification matches processed: " << _total_matches
-                            << ". Total contradictions found: " << _total_contradictions << "." << std::endl;
-
-    if (_skipped > 0) diagnostic(" (skipped " + std::to_string(_skipped) + " deductions)", true);
 
-    if (_contradiction)
-    {
-        diagnostic("Found one or more contradictions!", true);
-    }
+    return seminaive_violations;
+}
 
-    if (_done && suppress_repetition)
-    {
-        out("Warning: Additional reasoning iterations are required, but have been suppressed.", true);
-    }
+void Reasoning::apply_rule(const Node& rule, Node condition)
+{
+    if (stop_requested()) return;
+    if (rule && !is_rule_enabled(rule)) return;
 
-    if (!silent)
-        diagnostic_stream() << "Reasoning summary: " << _total_matches << " matches processed, "
-                            << _total_contradictions << " contradictions found." << std::endl;
-    static std::unordered_set<Node> logged_relations;
+    _prof.note_rule_applied(rule ? rule : condition);
 
-    if (_pool && !silent)
+    struct QueryTimer
     {
-        diagnostic_stream() << "Parallel unifications activated for " << logged_relations.size()
-                            << " distinct fixed relations." << std::endl;
-    }
-
-    logged_relations.clear();
-
-    watch.stop();
-
-    if (!silent)
-        diagnostic_stream() << "Reasoning complete in " << watch.format() << " – "
-                            << _total_matches << " matches processed, "
-                            << _total_contradictions << " contradictions found." << std::endl;
+        Reasoning*                            r;
+        std::chrono::steady_clock::time_point started{std::chrono::steady_clock::now()};
+        ~QueryTimer()
+        {
+            if (r) r->record_query(std::chrono::duration<double>(std::chrono::steady_clock::now() - started).count());
+        }
+    } query_timer{rule == 0 && _metrics_enabled ? this : nullptr};
 
-    if (seminaive_violations > 0)
+    struct RuleTimer
     {
-        // The graph itself is complete at this point: the safety net kept
-        // re-applying classic evaluation until quiescence. The throw turns
-        // the incompleteness of delta seeding into a hard failure for tests
-        // and a visible error in the REPL.
-        throw std::runtime_error(
-            "Semi-naive completeness violation: the classic verification pass derived new facts in "
-            + std::to_string(seminaive_violations)
-            + " extra pass(es) after the delta drained. The final graph is complete, but delta "
-              "seeding missed at least one derivation. Please report this rule set at https://github.com/acrion/zelph/issues.");
-    }
-}
-
-void Reasoning::apply_rule(const Node& rule, Node condition)
-{
-    _prof.note_rule_applied(rule ? rule : condition);
+        Reasoning*                            r;
+        Node                                  rule;
+        std::chrono::steady_clock::time_point started{std::chrono::steady_clock::now()};
+        ~RuleTimer()
+        {
+            if (r) r->record_rule_time(rule, std::chrono::duration<double>(std::chrono::steady_clock::now() - started).count());
+        }
+    } rule_timer{rule != 0 && _rule_profiling ? this : nullptr, rule};
 
     _nn_pred        = get_node("nn", "zelph");
     _nn_layers_pred = get_node("nn-layers", "zelph");
@@ -249,32 +545,116 @@ void Reasoning::apply_rule(const Node& rule, Node condition)
         }
         catch (const contradiction_error& error)
         {
-            std::lock_guard<std::mutex> lock(_mtx_output);
-            _contradiction = true;
-            ++_total_contradictions;
+            report_contradiction(error);
+        }
 
-            if (_print_deductions || _generate_markdown)
-            {
-                std::string output;
-                string::node_to_string(this, output, _lang, error.get_fact(), 3, error.get_variables(), error.get_parent());
-                std::string message = "«" + get_formatted_name(core.Contradiction, _lang) + "» ⇐ " + output;
+        _pool->wait();
+    }
 
-                if (_print_deductions)
-                {
-                    out(string::unmark_identifiers(message), true);
-                }
+    if (rule == 0) throw_builtin_error();
+    if (_answers_stopped)
+    {
+        _answers_stopped  = false;
+        _cancel_requested = false;
+    }
+}
 
-                if (_generate_markdown)
-                {
-                    _markdown->add("Contradictions", message);
-                }
-            }
+// Shared by all evaluation paths: counts and prints the contradiction and
+// records it for conflicts().
+void Reasoning::report_contradiction(const contradiction_error& error)
+{
+    if (!_default_rules.empty() && defeat_assumptions(error)) return;
+
+    std::vector<Node> key;
+    collect_premises(error.get_fact(), error.get_variables(), key);
+    std::sort(key.begin(), key.end());
+    key.insert(key.begin(), error.get_parent());
+
+    std::lock_guard<std::mutex> lock(_mtx_output);
+    _contradiction = true;
+    ++_total_contradictions;
+    const auto [conflict, inserted] = _conflicts.insert(std::move(key));
+    if (inserted) record_contradiction();
+    if (inserted && _on_contradiction)
+        _on_contradiction(conflict->front(), std::vector<Node>(conflict->begin() + 1, conflict->end()));
+
+    if (_print_deductions || _generate_markdown)
+    {
+        std::string output;
+        string::node_to_string(this, output, _lang, error.get_fact(), 3, error.get_variables(), error.get_parent());
+        std::string message = "«" + get_formatted_name(core.Contradiction, _lang) + "» ⇐ " + output;
+
+        if (_print_deductions)
+        {
+            out(string::unmark_identifiers(message), true);
         }
 
-        _pool->wait();
+        if (_generate_markdown)
+        {
+            _markdown->add("Contradictions", message);
+        }
+    }
+}
+
+// Shared by all evaluation paths: prints a query answer or hands it to the
+// answer handler or query collector, unless its confidence is below the threshold, it is not
+// valid at the as-of time, it holds in no context of the scope or a premise
+// comes from a source out of scope or is not trusted enough.
+void Reasoning::report_answer(const Node condition, const Node rule, const std::shared_ptr<Variables>& bindings, const double confidence)
+{
+    if (_min_confidence > 0 && answer_confidence(condition, *bindings, confidence) < _min_confidence) return;
+    if (_as_of && !valid_as_of(condition, *bindings)) return;
+
+    std::optional<ContextSet> contexts;
+    if (!_context_scope.empty() && !premise_contexts(0, condition, *bindings, contexts)) return;
+    if (!premise_sources(condition, *bindings) || !premise_trust(0, condition, *bindings)) return;
+
+    std::lock_guard<std::mutex> lock(_mtx_output);
+    ++_answers_reported;
+    if (_answer_handler)
+    {
+        if (!_answers_stopped && !_answer_handler(*bindings))
+        {
+            _answers_stopped  = true;
+            _cancel_requested = true; // unwinds the evaluation like a cancelled run
+        }
+    }
+    else if (_query_results)
+    {
+        _query_results->push_back(bindings);
+    }
+    else
+    {
+        print_answer(condition, rule, *bindings);
     }
 }
 
+void Reasoning::print_answer(const Node condition, const Node rule, const Variables& bindings)
+{
+    std::string output;
+    string::node_to_string(this, output, _lang, condition, 3, bindings, rule);
+    if (_probabilistic)
+    {
+        std::ostringstream probability;
+        probability << answer_probability(condition, bindings);
+        output += "  (probability " + probability.str() + ")";
+    }
+    out("Answer: " + string::unmark_identifiers(output), true);
+}
+
+std::vector<Reasoning::Conflict> Reasoning::conflicts() const
+{
+    std::vector<Conflict> result;
+    for (const auto& key : _conflicts)
+    {
+        if (!std::all_of(key.begin(), key.end(), [this](Node nd)
+                         { return nd == 0 || exists(nd); }))
+            continue;
+        result.push_back(Conflict{key.front(), std::vector<Node>(key.begin() + 1, key.end())});
+    }
+    return result;
+}
+
 // Greedy Sort to optimize execution order based on variable bindings
 std::shared_ptr<std::vector<Node>> Reasoning::optimize_order(const adjacency_set& conditions, const Variables& current_vars, int depth)
 {
@@ -387,6 +767,11 @@ std::shared_ptr<std::vector<Node>> Reasoning::optimize_order(const adjacency_set
             if (_nn_pred != 0 && rels_for_score.size() == 1 && *rels_for_score.begin() == _nn_pred)
                 score -= 800;
 
+            // Builtins, like !=, get the bindings of all the other
+            // positive conditions.
+            if (rels_for_score.size() == 1 && is_builtin(*rels_for_score.begin()))
+                score -= 600;
+
             // Prefer conditions whose predicate has fewer matching facts
             if (rels_for_score.size() == 1)
             {
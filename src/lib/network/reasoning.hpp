@@ -32,20 +32,31 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "network_types.hpp"
 #include "neural.hpp"
 #include "reasoning_profiler.hpp"
+#include "run_metrics.hpp"
+#include "run_progress.hpp"
 #include "zelph.hpp"
 
 #include <zelph_export.h>
 
 #include <atomic>
+#include <chrono>
+#include <condition_variable>
+#include <functional>
 #include <map>
 #include <memory>
 #include <mutex>
+#include <optional>
+#include <set>
 #include <string>
+#include <thread>
+#include <unordered_map>
 #include <unordered_set>
 #include <vector>
 
 namespace zelph::network
 {
+    class contradiction_error;
+
     struct RulePos
     {
         Node                                      node;
@@ -86,6 +97,15 @@ namespace zelph::network
         explicit Reasoning(const io::OutputHandler& output = io::default_output_handler);
         void set_markdown_subdir(const std::string& subdir);
         void set_query_collector(std::vector<std::shared_ptr<Variables>>* collector);
+
+        // Streams the answers of a query (apply_rule with rule 0) to the
+        // handler instead of collecting them: each answer is handed over as
+        // soon as it is found, on the calling thread and with _mtx_output
+        // held, so a slow handler holds up the evaluation and no answers
+        // pile up. The query stops when the handler returns false. Takes
+        // precedence over the query collector; nullptr switches it off.
+        using AnswerHandler = std::function<bool(const Variables& bindings)>;
+        void set_answer_handler(AnswerHandler handler) { _answer_handler = std::move(handler); }
         void run(const bool print_deductions, const bool generate_markdown, const bool suppress_repetition, const bool silent = false);
         void apply_rule(const network::Node& rule, network::Node condition);
         void profiler_reset_epoch()
@@ -94,12 +114,352 @@ namespace zelph::network
             _nn_cache.clear();
         }
 
+        // Ask a running run() to stop. Thread-safe, meant to be called from
+        // another thread (e.g. a cancelled caller context). The run stops at
+        // the next rule or match boundary, keeps every fact derived so far
+        // and throws reasoning_cancelled. The request applies to the run in
+        // progress only: one made while no run is in progress, or arriving
+        // after it ended, is ignored, so it never stops a later run or a
+        // query.
+        void request_cancel()
+        {
+            const uint64_t run = _run_id.load(std::memory_order_relaxed);
+            if (run != 0) _cancelled_run.store(run, std::memory_order_relaxed);
+        }
+        bool stop_requested() const
+        {
+            if (_cancel_requested.load(std::memory_order_relaxed)) return true;
+            const uint64_t run = _run_id.load(std::memory_order_relaxed);
+            return run != 0 && _cancelled_run.load(std::memory_order_relaxed) == run;
+        }
+
+        // Number of worker threads that scan for matches of a rule
+        // condition in parallel (see Unification); 0 selects one per
+        // hardware thread, the default. Matches are processed in the order
+        // a single thread would find them, so deductions are inserted in the
+        // same order for every thread count. Not to be called during a run.
+        void   set_thread_count(size_t count);
+        size_t thread_count() const { return _pool->count(); }
+
+        // Deterministic mode, off by default: identical input then gives the
+        // same deductions in the same order and the same answers in the
+        // same order, also across save and load. The matcher visits the
+        // candidate facts of a condition in ascending order of their IDs
+        // instead of the order of the hash sets holding them, which depends
+        // on the history of insertions and removals; each semi-naive
+        // iteration seeds its delta in that order, too; and query answers
+        // (see apply_query) are sorted by the IDs of their values, except
+        // those streamed to an answer handler, which come in match order.
+        // Costs a sort of every candidate set the matcher visits. Changing
+        // the mode drops the cached query answers (see set_query_cache),
+        // whose order depends on it. Not to be called during a run.
+        void set_deterministic(bool on);
+
+        // --- Implemented in reasoning_limits.cpp ---
+
+        // Resource limits of a run, 0 for none (the default): the number of
+        // facts it may deduce, and the memory the process may use (resident
+        // plus swapped, measured on Linux only). A run exceeding one stops
+        // like a cancelled run, keeps the facts derived so far and throws
+        // reasoning_limit_exceeded. Session state, not persisted.
+        void     set_max_facts(uint64_t count);
+        uint64_t max_facts() const { return _max_facts; }
+        void     set_max_memory(size_t bytes);
+        size_t   max_memory() const { return _max_memory; }
+
+        // Guard against rules that build new terms without bound (see
+        // unbounded_rules), 0 for none (the default). The depth of a rule
+        // firing is one more than the deepest term among its bindings; the
+        // fresh nodes, nested statements and facts it creates get that
+        // depth, stated nodes have depth 0. A firing deeper than the limit
+        // stops the run like a resource limit, with a reason naming the
+        // rule. Depths are only recorded while a limit is set. Session
+        // state, not persisted.
+        void     set_max_term_depth(uint32_t depth);
+        uint32_t max_term_depth() const { return _max_term_depth; }
+
+        // Bounds of a run, 0 for none (the default): the fixpoint iterations
+        // it may perform and the facts it may deduce. Unlike a limit, a bound
+        // pauses the run without an error; fixpoint_reached() then returns
+        // false and the next run continues where this one stopped (in
+        // semi-naive mode from the facts it had not yet seeded, otherwise
+        // with a new classic pass). Session state, not persisted.
+        void     set_max_iterations(int count);
+        int      max_iterations() const { return _max_iterations; }
+        void     set_max_deductions(uint64_t count);
+        uint64_t max_deductions() const { return _max_deductions; }
+        bool     fixpoint_reached() const { return _fixpoint_reached; }
+
+        // Bounds of the queries of users (see apply_query), 0 for none (the
+        // default): the time a query may take and the matches it may
+        // process (the candidate facts its conditions bind). A query
+        // exceeding one stops like a cancelled run and keeps the answers
+        // found so far; query_truncated() and query_truncation(), which
+        // tells the bound, then hold until the next query. Session state,
+        // not persisted.
+        void                      set_query_timeout(std::chrono::milliseconds timeout);
+        std::chrono::milliseconds query_timeout() const { return _query_timeout; }
+        void                      set_max_query_matches(uint64_t count);
+        uint64_t                  max_query_matches() const { return _max_query_matches; }
+        bool                      query_truncated() const { return _query_truncated; }
+        std::string               query_truncation() const;
+
+        // apply_rule(0, condition) within the query bounds. Without an
+        // answer handler or query collector, a truncated query's printed
+        // answers are followed by a notice. Views, pruning and other
+        // internal evaluations of conditions are not bounded.
+        void apply_query(Node condition);
+
+        // True if the fact was created by a rule deduction in this session
+        // and not stated since (as opposed to being stated or imported).
+        // Session state, not persisted by .save. Not meant to be called
+        // during a run.
+        bool is_deduced(Node fact) const { return _deduced_facts.count(fact) == 1; }
+
+        // Invoked for every fact a rule deduction creates (never for facts
+        // that already existed), with the rule that derived it. Called from
+        // the reasoning threads, one call at a time and before the deduction
+        // is printed; the observer must not modify the network. Empty by
+        // default.
+        using DeductionObserver = std::function<void(Node fact, Node rule)>;
+        void set_deduction_observer(DeductionObserver observer) { _on_deduction = std::move(observer); }
+
+        // Invoked for every contradiction a run newly detects (see
+        // conflicts), with the rule that detected it and the facts its
+        // conditions matched. Called like the deduction observer.
+        using ContradictionObserver = std::function<void(Node rule, const std::vector<Node>& facts)>;
+        void set_contradiction_observer(ContradictionObserver observer) { _on_contradiction = std::move(observer); }
+
+        // Invoked for every fact created outside of a run: stated facts, but
+        // also the patterns of rules and queries and other parser side
+        // effects. Called while the fact is being created, so the observer
+        // must neither modify nor inspect the network.
+        using FactObserver = std::function<void(Node fact)>;
+        void set_fact_observer(FactObserver observer) { _on_new_fact = std::move(observer); }
+
+        // Invoked by retract, once reasoning has been re-run, for the
+        // retracted fact and for every deduction that stayed withdrawn. The
+        // fact nodes no longer exist, so the observer is given their parts,
+        // which do. Called on the thread that retracts.
+        struct RetractedFact
+        {
+            Node          fact;
+            Node          subject;
+            Node          predicate;
+            adjacency_set objects;
+            bool          deduced;
+        };
+        using RetractionObserver = std::function<void(const RetractedFact&)>;
+        void set_retraction_observer(RetractionObserver observer) { _on_retraction = std::move(observer); }
+
+        // --- Implemented in reasoning_progress.cpp ---
+
+        // Invoked about every `interval` while a run is in progress, from a
+        // helper thread and one call at a time, and once more with
+        // RunProgress::finished set when the run completes or pauses (not
+        // when it fails). The observer must not modify the network and must
+        // not throw. Empty by default; not to be changed during a run.
+        using ProgressObserver = std::function<void(const RunProgress&)>;
+        void        set_progress_observer(ProgressObserver observer, std::chrono::milliseconds interval = std::chrono::seconds(1));
+        RunProgress progress(bool finished) const;
+
+        // --- Implemented in reasoning_metrics.cpp ---
+
+        // Counters for monitoring a long-lived network: runs and their
+        // duration, deductions per rule, contradictions and the duration of
+        // queries (a pattern applied without a rule, as by .prune-facts as
+        // well). Collected only while enabled, which they are not by
+        // default; enabling them starts from zero. Session state, not
+        // persisted.
+        void       set_metrics_enabled(bool enabled);
+        bool       metrics_enabled() const { return _metrics_enabled; }
+        RunMetrics metrics() const;
+
+        // Rule profiling (see .rule-profile), off by default: while enabled,
+        // each run records per rule its firings, new facts and evaluation
+        // time. rule_profile returns the records of the last such run, one
+        // for every rule it ran (enabled rules never firing included), the
+        // most expensive first. Session state, not persisted.
+        void                     set_rule_profiling(bool enabled) { _rule_profiling = enabled; }
+        bool                     rule_profiling() const { return _rule_profiling; }
+        std::vector<RuleProfile> rule_profile() const;
+
+        // A disabled rule stays in the network (listed by .list-rules and
+        // saved by .save) but is skipped by run(). Session state, not
+        // persisted. Not meant to be called during a run. set_rule_enabled
+        // and remove_rule throw if the node is not a rule.
+        void set_rule_enabled(Node rule, bool enabled);
+        bool is_rule_enabled(Node rule) const { return _disabled_rules.count(rule) == 0; }
+        void remove_rule(Node rule);
+        void remove_rules();
+
+        // Rules run in strata, 0 by default: run() first runs the rules of
+        // the lowest stratum to a fixpoint, then adds the next stratum and
+        // runs again, and so on, so that e.g. classification rules are
+        // saturated before the rules that depend on them first fire. Each
+        // stage is a complete run (including the deferred negation
+        // stratum), so the result is a fixpoint of all rules. Within a
+        // pass, rules are applied in order of stratum, then node ID. While
+        // the active stratum is not 0, the script engine puts every rule
+        // stated at the top level into it. set_rule_stratum throws if the
+        // node is not a rule. Session state, not persisted.
+        void set_rule_stratum(Node rule, int stratum);
+        int  rule_stratum(Node rule) const;
+        void set_active_stratum(int stratum) { _active_stratum = stratum; }
+        int  active_stratum() const { return _active_stratum; }
+
+        // --- Implemented in reasoning_defaults.cpp ---
+
+        // Default rules ("birds fly"): their deductions are assumptions that
+        // more certain knowledge overrides. A contradiction whose premises
+        // rest on deductions of default rules (directly or through other
+        // deductions) is not reported; instead the assumptions of the lowest
+        // priority among them are defeated: at the end of the run they are
+        // withdrawn with the deductions that depended on them, and they are
+        // not deduced again. An exception is stated as a contradiction rule,
+        // e.g. "penguins don't fly" as (X ~ penguin, X can fly) => !, and
+        // always wins over defaults; among defaults, the higher priority
+        // wins, and assumptions of equal priority are all defeated. revise
+        // lifts a defeat when one of the contradiction's other premises
+        // goes, retract lifts them all. Changing a rule's default status
+        // makes the next run start with a classic pass. set_rule_default
+        // throws if the node is not a rule. Session state, not persisted.
+        struct Defeat
+        {
+            RetractedFact     assumption; // no longer in the network
+            Node              rule{0};    // the default rule that deduced it
+            Node              by{0};      // the rule that found the contradiction
+            std::vector<Node> premises;   // the contradiction's other premises
+        };
+        void                set_rule_default(Node rule, bool is_default, int priority = 0);
+        bool                is_default_rule(Node rule) const { return _default_rules.count(rule) == 1; }
+        int                 default_priority(Node rule) const;
+        std::vector<Defeat> defeats() const;
+
+        // --- Implemented in reasoning_transaction.cpp ---
+
+        // A transaction records every node created after begin_transaction
+        // in a cluster of its own. commit_transaction keeps them, in the
+        // cluster that was active before (if any); rollback_transaction
+        // removes them again, including the facts deduced from them, and
+        // returns their number. Changes to nodes that existed before (their
+        // confidence, names or removal) are not undone. Transactions do not
+        // nest: begin_transaction throws inside one, the others outside.
+        // Not meant to be called during a run.
+        void   begin_transaction();
+        void   commit_transaction();
+        size_t rollback_transaction();
+        bool   in_transaction() const { return _transaction.has_value(); }
+
+        // Runs inference like run() inside a transaction that is rolled back
+        // afterwards, also if the run throws, so the network is left as it
+        // was (see .run-dry). visit is called for each fact the run deduced,
+        // with the rule that derived it, while the fact still exists; the
+        // deduction observer is not told. Returns the number of deductions.
+        // Throws inside an open transaction.
+        size_t run_dry(bool print_deductions, const DeductionObserver& visit = nullptr);
+
+        // Like Zelph::drop_cluster, but also forgets the session state of
+        // the removed nodes (deductions, validity, rule settings, ...).
+        size_t drop_cluster(const std::string& name);
+
+        // --- Implemented in reasoning_debug.cpp ---
+
+        // Support for stepping through inference (see .debug). The break
+        // condition is asked after every rule firing that creates a fact,
+        // like the deduction observer; if it returns true, the run pauses
+        // (see set_max_deductions) and the next run continues from there. A
+        // condition that always returns true steps one firing per run.
+        // Retraction re-runs reasoning without it. Empty by default.
+        using BreakCondition = std::function<bool(Node fact, Node rule)>;
+        void set_break_condition(BreakCondition condition) { _break_condition = std::move(condition); }
+        const BreakCondition& break_condition() const { return _break_condition; }
+
+        // The candidate firings a run would consider next: for each enabled
+        // rule, in the order of ordered_rules(), the matches of its
+        // condition some consequence of which does not exist yet (a
+        // consequence with a variable the condition does not bind always
+        // counts as missing). At most limit entries, 0 for all. Not meant
+        // to be called during a run.
+        struct AgendaItem
+        {
+            Node      rule{0};
+            Variables bindings;
+        };
+        std::vector<AgendaItem> agenda(size_t limit = 0);
+
+        // --- Implemented in reasoning_explain.cpp ---
+
+        // How a deduced fact came about: the rule that derived it and the
+        // facts its conditions matched, deduced premises explained in turn
+        // down to stated facts (rule 0). Negated conditions contribute no
+        // premise. The first derivation of each fact is recorded during
+        // run() (rule, condition and bindings); session state, not
+        // persisted. Not meant to be called during a run.
+        struct Proof
+        {
+            Node               fact{0};
+            Node               rule{0};
+            std::vector<Proof> premises;
+        };
+        Proof explain(Node fact) const;
+
+        // The facts a query answer matched: the leaf conditions of the
+        // query (the condition itself or the elements of a conjunction)
+        // with the answer's bindings applied. Negated conditions matched
+        // the absence of a fact and contribute none.
+        std::vector<Node> answer_premises(Node condition, const Variables& bindings) const;
+
+        // Contradictions found by run() that still hold: the rule that
+        // detected each (consequence !, or a deduction contradicting a
+        // known fact) and the facts its conditions matched, sorted. Each
+        // distinct conflict is listed once; conflicts whose rule or facts
+        // have been removed since are left out. Session state, not
+        // persisted. Not meant to be called during a run.
+        struct Conflict
+        {
+            Node              rule{0};
+            std::vector<Node> facts;
+        };
+        std::vector<Conflict> conflicts() const;
+
         // --- Implemented in reasoning_pruning.cpp ---
 
         void prune_facts(Node pattern, size_t& removed_count);
         void prune_nodes(Node pattern, size_t& removed_facts, size_t& removed_nodes);
         void purge_unused_predicates(size_t& removed_facts, size_t& removed_predicates);
 
+        // Removes a stated fact or rule together with every deduction that
+        // loses its support, as revise does without a replacement: the
+        // deductions whose recorded derivation depends on the fact are
+        // removed, and the rules that could produce one of them re-derive
+        // those still supported by the remaining facts (with the same node
+        // IDs). With rules of negated conditions or in probabilistic mode,
+        // all deduced facts are withdrawn and reasoning is re-run instead.
+        // withdrawn_count is the number that stayed gone. The deduction
+        // observer is not called for re-derived facts. Throws if
+        // fact is not a fact node or was itself deduced, and in a network
+        // loaded from a file (see Zelph::loaded_from_file), whose deduced
+        // facts would be taken for stated ones.
+        void retract(Node fact, size_t& withdrawn_count);
+
+        // --- Implemented in reasoning_revision.cpp ---
+
+        // Belief revision: removes a stated fact like retract, optionally in
+        // favour of a replacement that has already been stated (e.g. "pluto
+        // ~ dwarf planet" for "pluto ~ planet"), but recomputes only the
+        // affected part of the deduction closure: the deductions whose
+        // recorded derivation depends on the fact are removed, and the rules
+        // that could produce one of them are applied again to re-derive
+        // those still supported. Other deductions stay in place. Returns the
+        // deductions that stayed withdrawn; the retraction observer is
+        // called as by retract. With rules of negated conditions or in
+        // probabilistic mode, where adding a fact may change other
+        // deductions, it recomputes everything, as retract does there.
+        // Throws like retract, or if replacement (0 for none) is not another
+        // fact.
+        std::vector<RetractedFact> revise(Node fact, Node replacement = 0);
+
         // --- Implemented in reasoning_seminaive.cpp ---
 
         void set_seminaive(bool on);
@@ -107,11 +467,482 @@ namespace zelph::network
         void set_seminaive_check(bool on);
         bool seminaive_check() const;
 
+        // Makes the next run start with a classic pass over all facts
+        // instead of only the facts created since the last run. Needed after
+        // changes that can enable deductions without creating a fact (e.g. a
+        // fact's validity or contexts); run() calls it for classic runs.
+        void invalidate_incremental();
+
+        // --- Implemented in reasoning_stratify.cpp ---
+
+        // run() evaluates all rules with negated conditions in ONE deferred
+        // stratum. A rule set does not fit that schedule if a pattern
+        // negated by a deferred rule may be grown by the consequences of
+        // another deferred rule (directly or through a chain of rules) or
+        // directly by its own. stratification_violations() finds these
+        // cases statically: patterns are compared structurally (a variable
+        // matches anything), consequences with a variable predicate are not
+        // traced into negations and disabled rules are ignored. The check is
+        // conservative, so a module that orders its derivations by term
+        // structure may be reported although its results are sound. In
+        // strict mode (off by default) run() throws instead of evaluating a
+        // rule set with violations.
+        struct StratificationViolation
+        {
+            Node producer{0}; // deferred rule whose consequences may grow the pattern
+            Node consumer{0}; // deferred rule negating the pattern
+            Node pattern{0};  // the negated condition
+        };
+        std::vector<StratificationViolation> stratification_violations() const;
+
+        // A rule builds new terms if a consequence contains a variable its
+        // conditions do not bind (a fresh node per firing) or a nested
+        // statement with variables. Such a rule can keep a run from
+        // terminating if its consequences may match its own positive
+        // conditions, directly or through a chain of other rules.
+        // unbounded_rules() finds these cycles statically, comparing
+        // patterns like stratification_violations (disabled rules are
+        // ignored). The check is conservative: modules whose terms shrink
+        // along the recursion, such as the arithmetic scripts, terminate
+        // although they are reported. See set_max_term_depth for the guard
+        // at run time.
+        struct UnboundedRule
+        {
+            Node              rule{0};
+            Node              term{0};  // the fresh variable or nested statement of a consequence
+            std::vector<Node> cycle;    // the rules through which it feeds back, starting with rule
+        };
+        std::vector<UnboundedRule> unbounded_rules() const;
+        void                                 set_strict_stratification(bool on) { _strict_stratification = on; }
+        bool                                 strict_stratification() const { return _strict_stratification; }
+
+        // --- Implemented in reasoning_plan.cpp ---
+
+        // How the matcher evaluates a query condition, or the condition of
+        // a rule: its leaf conditions in the order optimize_order joins
+        // them, negations last, each with the orientation its candidate
+        // facts are found by (see Zelph::TripleIndex) and their number.
+        // The number is exact where the lookup starts from a constant or
+        // scans the relation; where it starts from a part an earlier step
+        // binds, it is the average per binding, estimated from a sample of
+        // the relation. ≠ conditions compare bindings and look up nothing.
+        struct PlanStep
+        {
+            Node              condition{0};
+            Node              relation{0};        // 0 for a relation variable
+            std::string       access;             // "spo", "pos", "osp" or "none" (≠, builtins)
+            uint64_t          candidates{0};
+            bool              per_binding{false}; // candidates for each binding of the earlier steps
+            bool              negated{false};
+            std::vector<Node> binds;              // variables bound first by this step
+        };
+        std::vector<PlanStep> query_plan(Node condition_or_rule);
+
+        // The named nodes of a query pattern that occur in no fact but
+        // patterns with variables, i.e. concepts only the query itself
+        // mentions (typically a misspelt name: parsing the query creates
+        // the node). unknown_concept_hint describes the first of them with
+        // up to three near-miss names (see Zelph::search_names), e.g.
+        // "Unknown concept 'piuss' – did you mean pius?", or is empty.
+        std::vector<Node> unknown_concepts(Node pattern) const;
+        std::string       unknown_concept_hint(Node pattern) const;
+
+        // Tells that a query pattern has no answers and how it was parsed:
+        // the pattern as rendered, then for each of its conditions the
+        // subject, relation and objects, with variables marked, e.g. "No
+        // matches for pattern X is_parent_of anna (subject: variable X,
+        // relation: is_parent_of, object: anna)".
+        std::string no_match_notice(Node pattern) const;
+
+        // Describes a statement the way it was parsed, for the parse trace:
+        // whether it is a fact, a rule or a query (a statement with variables
+        // that is no rule), its conditions and consequences like
+        // no_match_notice, and the names that became concepts: for a fact the
+        // ones no other statement mentions, for a query unknown_concepts.
+        // E.g. "Parsed as fact paul is_parent_of peter (subject: paul,
+        // relation: is_parent_of, object: peter); new concepts: paul,
+        // is_parent_of, peter".
+        std::string describe_statement(Node statement, bool query) const;
+
+        // With suggestions on, a query without answers that mentions an
+        // unknown concept reports unknown_concept_hint (the REPL as a
+        // diagnostic, ScriptEngine::query as an error). Off by default;
+        // session state, not persisted. answers_reported counts the query
+        // answers reported so far, whether printed or collected.
+        void     set_suggest_concepts(bool suggest) { _suggest_concepts = suggest; }
+        bool     suggest_concepts() const { return _suggest_concepts; }
+        uint64_t answers_reported() const { return _answers_reported; }
+
+        // With no-match reports on, the REPL answers a query without answers
+        // with no_match_notice instead of printing nothing (see .no-matches).
+        // Off by default; session state, not persisted.
+        void set_report_no_matches(bool report) { _report_no_matches = report; }
+        bool report_no_matches() const { return _report_no_matches; }
+
+        // With tracing on, the default, runs print each fact they deduce
+        // together with the condition it was derived from (see .trace);
+        // off, only contradictions are printed. Session state, not persisted.
+        void set_trace(bool trace) { _trace = trace; }
+        bool trace() const { return _trace; }
+
+        // With the parse trace on, off by default, the REPL also reports
+        // each statement it reads with describe_statement, as a diagnostic.
+        void set_parse_trace(bool trace) { _parse_trace = trace; }
+        bool parse_trace() const { return _parse_trace; }
+
+        // --- Implemented in reasoning_builtin.cpp ---
+
+        // Built-in predicates: a condition whose relation is a builtin is
+        // not matched against facts but answered by calling its function
+        // with the condition's subject followed by its objects, resolved
+        // through the bindings so far; an unbound variable is passed as
+        // itself (see Zelph::is_var). The function returns one Variables
+        // per solution, binding some of those variables (an empty one keeps
+        // the bindings as they are); no solution fails the condition. As
+        // with !=, builtin conditions are evaluated after the conditions
+        // that bind variables. Calls are serialized but may come from
+        // reasoning threads, and must not change the network except by
+        // creating nodes. If the function throws, the run or query stops
+        // and run() or apply_rule() throws std::runtime_error with its
+        // message. An empty function removes the builtin. Not to be changed
+        // during a run; session state, not persisted.
+        using Builtin = std::function<std::vector<Variables>(const std::vector<Node>& args)>;
+        void register_builtin(Node relation, Builtin function);
+        bool is_builtin(Node relation) const { return _builtins.count(relation) == 1 || _native.count(relation) == 1; }
+
+        // --- Implemented in reasoning_arithmetic.cpp ---
+
+        // Native arithmetic on numeric literals, i.e. nodes named like
+        // "1000000", "-3" or "2.5" in the current language: with it enabled,
+        // the relations <, <=, >, >= compare two bound numbers, and
+        // (A op B) = C with op one of + - * / computes A op B and binds C
+        // to the node named by the result, or compares it with a bound C
+        // (X = Y alone compares two numbers). These relations then count as
+        // builtins (see above), so they no longer match facts; the
+        // rule-based arithmetic of stdlib/arithmetic.zph, which derives
+        // such facts for &-literals, should not be used at the same time.
+        // Either side of a condition may be a term like (A + B), evaluated
+        // recursively. Off by default; session state, not persisted.
+        void set_native_arithmetic(bool enabled);
+        bool native_arithmetic() const { return _native_arithmetic; }
+
+        // --- Implemented in reasoning_strings.cpp ---
+
+        // String builtins on the names of nodes in the current language:
+        // with them enabled, A starts_with B, A ends_with B and
+        // A equals_ignoring_case B test two bound names (the latter after
+        // Unicode case folding), and (A ++ B) = C binds C to the node named
+        // by the concatenation, creating it if needed, or compares it with
+        // a bound C. Terms nest and mix with those of native arithmetic, as
+        // in ((F ++ "_") ++ L) = N. Off by default; session state, not
+        // persisted.
+        void set_string_builtins(bool enabled);
+        bool string_builtins() const { return _native_strings; }
+
+        // --- Implemented in reasoning_qualifiers.cpp ---
+
+        // Qualifiers (reification): facts are nodes, so a fact can be the
+        // subject of other facts that qualify it, e.g.
+        // (berlin is_capital_of germany) since 1990. qualify states such a
+        // fact (qualified predicate value) and returns it; qualifiers lists
+        // the facts whose subject is the qualified fact, ordered by ID. Both
+        // throw std::runtime_error for a node that is not a fact.
+        Node              qualify(Node qualified, Node predicate, Node value);
+        std::vector<Node> qualifiers(Node qualified) const;
+
+        // --- Implemented in reasoning_provenance.cpp ---
+
+        // Provenance: where a fact came from. Facts created outside of runs
+        // are recorded with the origin set at the time, e.g. source "script"
+        // with the file and line, "rdf" with the loaded file or "api"; the
+        // first origin of a fact is kept. Deduced facts report source "rule"
+        // and the rule that made them. Facts created while no origin was set
+        // (e.g. by .bulk-load) have no record and report an empty source.
+        // provenance throws std::runtime_error for a node that is not a fact.
+        struct Provenance
+        {
+            std::string source;
+            std::string file;
+            uint64_t    line{0};
+            Node        rule{0};
+        };
+        Provenance        provenance(Node fact) const;
+        void              set_origin(const Provenance& origin) { _origin = origin; }
+        const Provenance& origin() const { return _origin; }
+
+        // Sets an origin for its lifetime and restores the previous one.
+        class OriginScope
+        {
+        public:
+            OriginScope(Reasoning& r, const Provenance& origin)
+                : _r(r), _previous(r.origin())
+            {
+                _r.set_origin(origin);
+            }
+            ~OriginScope() { _r.set_origin(_previous); }
+
+            OriginScope(const OriginScope&)            = delete;
+            OriginScope& operator=(const OriginScope&) = delete;
+
+        private:
+            Reasoning& _r;
+            Provenance _previous;
+        };
+
+        // While the source scope is not empty, only facts from one of its
+        // sources take part in inference and answer queries; a deduced fact
+        // takes part if its premises do.
+        void                         set_source_scope(const std::set<std::string>& scope) { _source_scope = scope; }
+        const std::set<std::string>& source_scope() const { return _source_scope; }
+
+        // --- Implemented in reasoning_trust.cpp ---
+
+        // Trust: each source (see provenance) has a weight between 0 and 1,
+        // 1 unless set. A stated fact has the trust of its source (1 without
+        // a recorded source), a deduced fact that of its least trusted
+        // premise. Query answers and deductions need the minimum trust of the
+        // session, and a rule's deductions also its own minimum. 0 (the
+        // default) requires nothing. Session state, not persisted. The
+        // setters throw for a weight out of range or a node that is not a
+        // rule, trust for a node that is not a fact.
+        void                                        set_source_trust(const std::string& source, double trust);
+        double                                      source_trust(const std::string& source) const;
+        std::vector<std::pair<std::string, double>> list_source_trust() const { return {_source_trust.begin(), _source_trust.end()}; }
+        double                                      trust(Node fact) const;
+        void                                        set_min_trust(double threshold);
+        double                                      min_trust() const { return _min_trust; }
+        void                                        set_rule_min_trust(Node rule, double threshold);
+        double                                      rule_min_trust(Node rule) const;
+
+        // --- Implemented in reasoning_lists.cpp ---
+
+        // List builtins on cons lists like <a b c> (see Zelph::list),
+        // registered as builtins (see register_builtin) while enabled:
+        // E element_of L binds an unbound E to each distinct element of L in
+        // turn, L head H and L tail T bind or test the first element and the
+        // rest of a non-empty list, and L length N binds or tests the node
+        // named by the number of elements. L must be bound to a list,
+        // otherwise the condition fails. Off by default; session state, not
+        // persisted.
+        void set_list_builtins(bool enabled);
+        bool list_builtins() const { return _list_builtins; }
+
+        // --- Implemented in reasoning_confidence.cpp ---
+
+        // A fact's confidence is its probability in the weight store, in
+        // [0,1] and 1 unless set. Above 0.5 a fact counts as true, below
+        // 0.5 as false (see Answer). Both throw if the node is not a fact.
+        double confidence(Node fact) const;
+        void   set_confidence(Node fact, double confidence);
+
+        // How a rule turns the confidences of the facts its conditions
+        // matched into the confidence of its deductions: None ignores them
+        // (the default), Min takes the weakest, Product multiplies them and
+        // Custom calls the combiner. The confidence of ≈ conditions
+        // multiplies the result. A deduction whose confidence is not above
+        // 0.5 is not made, as the fact would count as false. Rule 0 sets
+        // the combination of all rules without one of their own. The
+        // combiner may run on several reasoning threads at once and must
+        // not touch the network. Session state, not persisted. Not meant to be called
+        // during a run.
+        enum class ConfidenceCombination
+        {
+            None,
+            Min,
+            Product,
+            Custom
+        };
+        using ConfidenceCombiner = std::function<double(const std::vector<double>& premises)>;
+        void                  set_confidence_combination(Node rule, ConfidenceCombination combination, ConfidenceCombiner combiner = nullptr);
+        ConfidenceCombination confidence_combination(Node rule) const;
+
+        // Query answers with a lower confidence than the threshold are left
+        // out. An answer's confidence is that of its weakest matched fact,
+        // times the confidence of its ≈ conditions. 0 (the default) keeps
+        // all answers.
+        void   set_min_confidence(double threshold);
+        double min_confidence() const { return _min_confidence; }
+
+        // Probabilistic mode: run() records every derivation of a fact and
+        // afterwards sets each deduced fact's confidence to its marginal
+        // probability, the noisy-OR of its derivations. A derivation holds
+        // with the rule's weight times the probabilities of the facts its
+        // conditions matched (and of its ≈ conditions); derivations are
+        // treated as independent. Stated facts are evidence and keep their
+        // confidence. Confidence combinations are not applied, and a
+        // probability below 0.5 does not make a fact false (no
+        // contradiction is reported for deducing it). Off by default;
+        // session state, like the rule weights (1 unless set, in [0,1]).
+        // set_rule_weight throws if the node is not a rule.
+        void   set_probabilistic(bool on)
+        {
+            if (on != _probabilistic) invalidate_incremental(); // derivations of existing facts count
+            _probabilistic = on;
+        }
+        bool   probabilistic() const { return _probabilistic; }
+        void   set_rule_weight(Node rule, double weight);
+        double rule_weight(Node rule) const;
+
+        // The probability of a query answer: the product of the
+        // probabilities of the facts it matched.
+        double answer_probability(Node condition, const Variables& bindings) const;
+
+        // --- Implemented in reasoning_temporal.cpp ---
+
+        // A fact may be valid during an interval of time points, both ends
+        // inclusive and each one open if unset. Time points are integers in
+        // a unit the script chooses (years, Unix seconds, ...). Facts
+        // without an interval are always valid. A deduction is valid where
+        // the intervals of the facts its conditions matched overlap; if
+        // they do not overlap, it is not made. Facts that already exist
+        // keep their interval. set_validity throws if the node is not a
+        // fact or the interval is empty. Session state, not persisted.
+        struct Validity
+        {
+            std::optional<int64_t> from;
+            std::optional<int64_t> to;
+
+            bool always() const { return !from && !to; }
+            bool contains(int64_t time) const { return (!from || *from <= time) && (!to || time <= *to); }
+        };
+        Validity validity(Node fact) const;
+        void     set_validity(Node fact, const Validity& validity);
+
+        // With a time set, query answers are left out unless all the facts
+        // they matched are valid at that time. Unset (the default) keeps all
+        // answers.
+        void                   set_as_of(std::optional<int64_t> time) { _as_of = time; }
+        std::optional<int64_t> as_of() const { return _as_of; }
+
+        // --- Implemented in reasoning_context.cpp ---
+
+        // Facts can be grouped into named contexts, so a fact may hold in
+        // one context and not in another. A fact without a context holds in
+        // every context. A deduction holds in the contexts shared by the
+        // facts its conditions matched, further limited to the rule's
+        // contexts if it has any; if no context is left, it is not made.
+        // Facts that already exist keep their contexts. While the active
+        // context is set, the script engine adds every fact stated at the
+        // top level to it. A non-empty scope limits queries and reasoning
+        // to facts holding in at least one of its contexts; negated
+        // conditions still see all facts. The mutators throw if the node is
+        // not a fact or rule, respectively. Session state, not persisted.
+        using ContextSet = std::set<std::string>;
+        ContextSet                                  contexts(Node fact) const;
+        void                                        add_to_context(Node fact, const std::string& context);
+        void                                        remove_from_context(Node fact, const std::string& context);
+        std::vector<std::pair<std::string, size_t>> list_contexts() const;
+        void                                        set_rule_contexts(Node rule, const ContextSet& contexts);
+        ContextSet                                  rule_contexts(Node rule) const;
+        void                                        set_context_scope(const ContextSet& scope) { _context_scope = scope; }
+        const ContextSet&                           context_scope() const { return _context_scope; }
+        void                                        set_active_context(const std::string& context) { _active_context = context; }
+        const std::string&                          active_context() const { return _active_context; }
+
+        // --- Implemented in reasoning_properties.cpp ---
+
+        // Algebraic properties of a relation R: transitive (X R Y and Y R Z
+        // imply X R Z), symmetric (X R Y implies Y R X), reflexive (X R Y
+        // implies X R X and Y R Y) or inverse of another relation S (X R Y
+        // implies Y S X, and X S Y implies Y R X). Declaring a property adds
+        // the rules that define it and returns them; a rule of the same text
+        // that exists already (e.g. from a saved network) is replaced, and
+        // declaring a property twice adds nothing. Runs do not fire these
+        // rules but close the relations with specialized passes between the
+        // rule iterations: a breadth-first closure for transitive relations
+        // and union-find components for relations that are both transitive
+        // and symmetric. The deductions are explained by the rules, as if
+        // they had fired. A disabled rule is skipped by both; the rules fire
+        // as usual in probabilistic mode and while validity intervals,
+        // contexts, sources, trust or confidences could restrict a
+        // deduction. Removing one of the rules removes the declaration.
+        // Declarations are session state; their rules are saved with the
+        // network and fire as ordinary rules after a load, until the
+        // property is declared again.
+        enum class RelationProperty
+        {
+            Transitive,
+            Symmetric,
+            Reflexive,
+            Inverse
+        };
+        struct RelationDeclaration
+        {
+            Node             relation{0};
+            RelationProperty property{RelationProperty::Transitive};
+            Node             inverse{0}; // the relation S of Inverse
+
+            bool operator==(const RelationDeclaration&) const = default;
+        };
+        std::vector<Node>                declare_relation_property(Node relation, RelationProperty property, Node inverse = 0);
+        std::vector<RelationDeclaration> relation_properties() const; // in the order of declaration
+
+        // --- Implemented in reasoning_equality.cpp ---
+
+        // Declares two concepts identical (owl:sameAs) by merging from into
+        // into, see Zelph::merge_concepts: facts asserted about either then
+        // take part in the same inferences, and the names of from resolve to
+        // into in later statements and queries. The session state of the
+        // restated facts and rules (derivations, confidence supports,
+        // validity, contexts, provenance, rule settings and declared relation
+        // properties) moves to their replacements. Not to be called during a
+        // run.
+        std::unordered_map<Node, Node> merge_concepts(Node from, Node into);
+
+        // --- Implemented in reasoning_views.cpp ---
+
+        // Materialized views: a query condition registered under a name
+        // whose answers are kept, so that reading them costs no evaluation.
+        // The answers are computed once by create_view and then maintained
+        // incrementally: the facts created since (stated or deduced) seed
+        // the conditions they match, like a semi-naive run, at the end of
+        // every run and when the view is read. A view with a negated,
+        // nested, builtin or neural condition, and every view after changes
+        // that bypass the fact-creation observer (removals, retractions,
+        // bulk imports, loads; see Network::unobserved_changes), is
+        // evaluated again instead. view returns the answers projected onto
+        // the variables of the condition, ordered by the IDs of the values;
+        // it throws std::runtime_error for an unknown name. Answers are
+        // filtered by the confidence, validity, context and trust settings
+        // in effect when they are found. Session state (not persisted).
+        void                     create_view(const std::string& name, Node condition);
+        bool                     remove_view(const std::string& name);
+        std::vector<std::string> views() const;
+        std::vector<Variables>   view(const std::string& name);
+
+        // --- Implemented in reasoning_cache.cpp ---
+
+        // Query cache for repeated identical queries: the answers to a
+        // statement (by its text and the current language) are kept and
+        // returned again until a fact of one of the relations in its
+        // pattern is created, or its confidence, validity or contexts
+        // change. A pattern with a variable relation is invalidated by any
+        // such change, one with a Janet builtin or a neural net is never
+        // cached. Changes that bypass the fact-creation observer (see
+        // Network::unobserved_changes), trust changes and other confidence,
+        // validity, context, source or trust filters invalidate every
+        // entry. Answers truncated by a query bound are not cached. Disabled
+        // by default; disabling drops the entries. Session state (not
+        // persisted).
+        using NamedAnswers = std::vector<std::map<std::string, Node>>;
+        void                        set_query_cache(bool enabled);
+        bool                        query_cache() const { return _query_cache; }
+        void                        clear_query_cache();
+        size_t                      query_cache_size() const;
+        uint64_t                    query_cache_hits() const { return _query_cache_hits; }
+        std::optional<NamedAnswers> cached_query(const std::string& statement, Node& pattern);
+        void                        cache_query(const std::string& statement, Node pattern, const NamedAnswers& answers);
+
     private:
         // --- Implemented in reasoning.cpp (orchestration) ---
 
         std::shared_ptr<std::vector<Node>> optimize_order(const adjacency_set& conditions, const Variables& current_vars, int depth);
         static bool                        contradicts(const Variables& variables, const Variables& unequals);
+        void                               report_contradiction(const contradiction_error& error);
+        void                               report_answer(Node condition, Node rule, const std::shared_ptr<Variables>& bindings, double confidence);
+        void                               print_answer(Node condition, Node rule, const Variables& bindings);
+        std::vector<Node>                  ordered_rules() const;
+        uint64_t                           run_stage(bool suppress_repetition, bool silent);
 
         // --- Implemented in reasoning_evaluate.cpp ---
 
@@ -119,25 +950,190 @@ namespace zelph::network
         bool is_negated_condition(Node condition, int depth);
         bool condition_contains_negation(Node condition, int depth);
 
+        // --- Implemented in reasoning_explain.cpp ---
+
+        Proof explain(Node fact, std::vector<Node>& path) const;
+        void  collect_premises(Node condition, const Variables& bindings, std::vector<Node>& premises) const;
+        Node  find_instance(Node pattern, const Variables& bindings, std::vector<Node>& history) const;
+
+        // --- Implemented in reasoning_defaults.cpp ---
+
+        bool defeat_assumptions(const contradiction_error& error);
+        bool withdraw_defeated();
+        bool is_defeated(Node rule, Node fact) const;
+
+        // --- Implemented in reasoning_pruning.cpp ---
+
+        std::vector<RetractedFact> recompute_without(Node fact);
+
+        // --- Implemented in reasoning_revision.cpp ---
+
+        std::vector<RetractedFact> withdraw(Node fact, Node replacement);
+        std::unordered_set<Node>   withdraw_dependents(const std::vector<Node>& roots, Node keep, std::vector<RetractedFact>& parts, std::unordered_set<Node>& rederive);
+
+        // --- Implemented in reasoning_stratify.cpp ---
+
+        struct RuleShape
+        {
+            Node              rule{0};
+            std::vector<Node> consequences; // without !
+            std::vector<Node> positive;
+            std::vector<Node> negated;
+        };
+        std::vector<RuleShape>           rule_shapes() const;
+        std::vector<std::vector<size_t>> rule_feeds(const std::vector<RuleShape>& shapes) const;
+        void                             collect_conditions(Node condition, bool negated, std::vector<Node>& positive, std::vector<Node>& negative) const;
+        void                             pattern_variables(Node pattern, std::unordered_set<Node>& vars, std::vector<Node>& history) const;
+        Node                             nested_term(Node consequence) const;
+        bool may_unify(Node a, Node b, std::vector<Node>& history) const;
+
+        // --- Implemented in reasoning_plan.cpp ---
+
+        bool        only_in_patterns(Node node) const;
+        std::string describe_part(Node part) const;
+        std::string describe_condition(Node condition) const;
+
+        // --- Implemented in reasoning_confidence.cpp ---
+
+        bool   combine_confidence(Node rule, Node condition, const Variables& bindings, double& confidence) const;
+        double answer_confidence(Node condition, const Variables& bindings, double confidence) const;
+        void   update_marginals();
+
+        // --- Implemented in reasoning_temporal.cpp ---
+
+        bool premise_validity(Node condition, const Variables& bindings, Validity& validity) const;
+        bool valid_as_of(Node condition, const Variables& bindings) const;
+
+        // --- Implemented in reasoning_context.cpp ---
+
+        bool premise_contexts(Node rule, Node condition, const Variables& bindings, std::optional<ContextSet>& contexts) const;
+
+        // --- Implemented in reasoning_provenance.cpp ---
+
+        bool premise_sources(Node condition, const Variables& bindings) const;
+        bool source_in_scope(Node fact, std::unordered_set<Node>& visited) const;
+
+        // --- Implemented in reasoning_trust.cpp ---
+
+        bool   premise_trust(Node rule, Node condition, const Variables& bindings) const;
+        double fact_trust(Node fact, std::unordered_set<Node>& visited) const;
+
+        // --- Implemented in reasoning_transaction.cpp ---
+
+        void forget_removed_nodes();
+
         // --- Implemented in reasoning_deduce.cpp ---
 
         void deduce(const Variables& variables, Node parent, const int depth, ReasoningContext& ctx, double confidence);
+        void report_deduction(Node d, Node parent, Node condition, const Variables& bindings);
         bool consequences_already_exist(const Variables&     condition_bindings,
                                         const adjacency_set& deductions,
                                         Node                 parent,
                                         const int            depth);
 
+        // --- Implemented in reasoning_properties.cpp ---
+        struct PropertyRule
+        {
+            Node                rule{0};
+            RelationDeclaration declaration;
+            bool                converse{false}; // the second rule of Reflexive (Y R Y) and Inverse (X S Y => Y R X)
+            Node                condition{0};
+            Node                x{0}, y{0}, z{0}; // the variables of the condition
+        };
+        uint64_t                           run_stage_with_properties(bool suppress_repetition, bool silent);
+        bool                               native_property(const PropertyRule& p) const;
+        uint64_t                           close_relation_properties(const std::vector<PropertyRule>& rules, std::vector<std::unordered_set<Node>>& seen);
+        uint64_t                           close_transitive(const PropertyRule& transitive, const std::vector<std::pair<Node, Node>>& added);
+        uint64_t                           close_equivalence(const PropertyRule& transitive, const PropertyRule& symmetric);
+        std::vector<std::pair<Node, Node>> relation_pairs(Node relation, std::unordered_set<Node>* seen = nullptr) const;
+        bool                               deduce_property(const PropertyRule& p, Node subject, Node object, const Variables& bindings);
+
+        // --- Implemented in reasoning_views.cpp ---
+        struct View
+        {
+            Node                                      condition{0};
+            std::vector<Node>                         variables;  // sorted
+            adjacency_set                             elements;   // conjunction elements (or the single condition)
+            std::vector<Node>                         leaves;     // the elements a new fact can seed
+            std::vector<Node>                         leaf_preds; // parallel to leaves
+            std::shared_ptr<std::unordered_set<Node>> excluded;   // the conjunction set and its elements
+            bool                                      seedable{false};
+            uint64_t                                  changes{0}; // unobserved_changes() at the last full evaluation
+            std::set<std::vector<Node>>               answers;    // values of the variables
+        };
+        void note_view_fact(Node fact, Node predicate);
+        void refresh_views();
+        void evaluate_view(View& v);
+        void seed_view(View& v, size_t leaf, Node fact, Node predicate);
+        void collect_view_answers(View& v, const std::vector<std::shared_ptr<Variables>>& found);
+
+        // --- Implemented in reasoning_cache.cpp ---
+        struct CachedQuery
+        {
+            Node                                   pattern{0};
+            NamedAnswers                           answers;
+            std::vector<std::pair<Node, uint64_t>> versions; // per relation of the pattern (0 = any relation)
+            uint64_t                               changes{0}; // unobserved_changes() when cached
+            uint64_t                               epoch{0};
+            double                                 min_confidence{0};
+            std::optional<int64_t>                 as_of;
+            ContextSet                             context_scope;
+            std::set<std::string>                  source_scope;
+            double                                 min_trust{0};
+        };
+        void note_query_cache_fact(Node fact, Node predicate);
+        void touch_query_cache(Node relation);
+        bool query_relations(Node pattern, std::set<Node>& relations, std::unordered_set<Node>& visited);
+
         // --- Implemented in reasoning_neural.cpp ---
         const NeuralNet* compiled_net(Node net_node, int depth);
         void             evaluate_neural(Node condition, const RulePos& rule, ReasoningContext& ctx, int depth);
         void             proceed_after_condition(const RulePos& rule, ReasoningContext& ctx, int depth, std::shared_ptr<Variables> vars, std::shared_ptr<Variables> uneqs, double confidence);
 
+        // --- Implemented in reasoning_builtin.cpp ---
+        void evaluate_builtin(Node condition, Node relation, const RulePos& rule, ReasoningContext& ctx, int depth);
+        void throw_builtin_error();
+
+        // --- Implemented in reasoning_arithmetic.cpp ---
+        void                       install_native();
+        std::optional<std::string> native_value(Node n, Node parent, const Variables& bindings);
+        void                       evaluate_native(Node condition, Node relation, const RulePos& rule, ReasoningContext& ctx, int depth);
+
+        // --- Implemented in reasoning_strings.cpp ---
+        static bool string_relation_holds(const std::string& relation, const std::string& a, const std::string& b);
+
+        // --- Implemented in reasoning_lists.cpp ---
+        std::optional<std::vector<Node>> list_elements(Node list) const;
+
         // --- Implemented in reasoning_seminaive.cpp ---
 
         // Delta-driven fixpoint loop (semi-naive evaluation). Returns the
         // number of safety-net violations found (always 0 unless
         // _seminaive_check is active and delta seeding missed a derivation).
         uint64_t run_fixpoint_seminaive(bool silent);
+        void     observe_between_runs();
+
+        // --- Implemented in reasoning_limits.cpp ---
+        void check_limits();
+        void check_query_bounds(bool match);
+        bool check_term_depth(Node rule, const Variables& bindings, uint32_t& depth);
+        void stop_for_limit(const std::string& reason);
+        bool iterations_exhausted(int iterations_done);
+        void pause_run(const std::string& reason);
+        bool pause_requested();
+
+        // --- Implemented in reasoning_progress.cpp ---
+        void start_progress_reports();
+        void stop_progress_reports();
+
+        // --- Implemented in reasoning_metrics.cpp ---
+        void record_run(double seconds);
+        void record_query(double seconds);
+        void record_deduction(Node rule);
+        void record_contradiction();
+        void start_rule_profile();
+        void record_firing(Node rule);
+        void record_rule_time(Node rule, double seconds);
 
         // --- Members ---
 
@@ -159,7 +1155,85 @@ namespace zelph::network
         bool                                     _prune_nodes_mode{false};
         std::unordered_set<Node>                 _facts_to_prune;
         std::unordered_set<Node>                 _nodes_to_prune;
+        std::unordered_set<Node>                 _deduced_facts; // guarded by _mtx_network
+        DeductionObserver                        _on_deduction;  // called with _mtx_output held
+        BreakCondition                           _break_condition; // called with _mtx_output held
+        ContradictionObserver                    _on_contradiction; // called with _mtx_output held
+        FactObserver                             _on_new_fact;
+        RetractionObserver                       _on_retraction;
+        std::unordered_set<Node>                 _disabled_rules;
+        std::unordered_map<Node, int>            _rule_strata;
+        int                                      _active_stratum{0};
+        std::unordered_map<Node, int>            _default_rules;   // default rule -> priority
+        std::unordered_map<Node, Defeat>         _defeats;         // by assumption; guarded by _mtx_network
+        std::vector<Node>                        _pending_defeats; // not yet withdrawn; guarded by _mtx_network
+        std::vector<PropertyRule>                _property_rules; // in the order of declaration
+        std::map<std::string, View>              _views;
+        std::atomic<bool>                        _views_active{false};
+        std::vector<std::pair<Node, Node>>       _view_delta; // guarded by _mtx_view_delta
+        std::mutex                               _mtx_view_delta;
+        std::atomic<bool>                        _query_cache{false};
+        std::map<std::string, CachedQuery>       _query_cache_entries;     // guarded by _mtx_query_cache
+        std::unordered_map<Node, uint64_t>       _relation_versions;       // guarded by _mtx_query_cache
+        uint64_t                                 _any_relation_version{0}; // guarded by _mtx_query_cache
+        uint64_t                                 _query_cache_epoch{0};    // guarded by _mtx_query_cache
+        std::atomic<uint64_t>                    _query_cache_hits{0};
+        mutable std::mutex                       _mtx_query_cache;
+
+        struct CombinationSetting
+        {
+            ConfidenceCombination combination{ConfidenceCombination::None};
+            ConfidenceCombiner    combiner;
+        };
+        CombinationSetting                           _default_combination;
+        std::unordered_map<Node, CombinationSetting> _rule_combinations;
+        double                                       _min_confidence{0};
+
+        struct Support
+        {
+            Node              rule{0};
+            double            confidence{1}; // of the ≈ conditions
+            std::vector<Node> premises;      // sorted
+        };
+        bool                                           _probabilistic{false};
+        std::unordered_map<Node, double>               _rule_weights;
+        std::unordered_map<Node, std::vector<Support>> _supports; // guarded by _mtx_network
+
+        std::unordered_map<Node, Validity> _validity; // guarded by _mtx_network
+        std::optional<int64_t>             _as_of;
+
+        std::unordered_map<Node, ContextSet> _contexts; // guarded by _mtx_network
+        std::unordered_map<Node, ContextSet> _rule_contexts;
+        ContextSet                           _context_scope;
+        std::string                          _active_context;
+
+        std::unordered_map<Node, Provenance> _provenance; // guarded by _mtx_provenance
+        mutable std::mutex                   _mtx_provenance;
+        Provenance                           _origin;
+        std::set<std::string>                _source_scope;
+
+        std::map<std::string, double>    _source_trust;
+        double                           _min_trust{0};
+        std::unordered_map<Node, double> _rule_min_trust;
+
+        std::optional<std::string> _transaction; // cluster active before begin_transaction
+
+        struct Derivation
+        {
+            Node      rule{0};
+            Node      condition{0};
+            Variables bindings;
+        };
+        std::unordered_map<Node, Derivation> _derivations; // guarded by _mtx_network
+        std::set<std::vector<Node>>          _conflicts;   // rule followed by the sorted facts; guarded by _mtx_output
         std::vector<std::shared_ptr<Variables>>* _query_results{nullptr};
+        AnswerHandler                            _answer_handler; // called with _mtx_output held
+        std::atomic<bool>                        _answers_stopped{false};
+        std::atomic<uint64_t>                    _answers_reported{0};
+        std::atomic<bool>                        _suggest_concepts{false};
+        std::atomic<bool>                        _trace{true};
+        std::atomic<bool>                        _parse_trace{false};
+        std::atomic<bool>                        _report_no_matches{false};
         ReasoningProfiler                        _prof;
 
         // --- Neural (≈) support ---
@@ -169,5 +1243,69 @@ namespace zelph::network
 
         bool _seminaive{true};
         bool _seminaive_check{false};
+
+        // Incremental runs (see run_fixpoint_seminaive): whether the last run
+        // reached its fixpoint, its enabled rules and unobserved_changes() at
+        // its end, and the facts created since.
+        std::atomic<bool>                  _incremental{false};
+        std::vector<Node>                  _incremental_rules;
+        uint64_t                           _incremental_changes{0};
+        std::vector<std::pair<Node, Node>> _pending_delta; // guarded by _mtx_pending_delta
+        std::mutex                         _mtx_pending_delta;
+        std::unordered_set<Node>           _rederive_rules; // applied classically by incremental runs during revise
+        bool _strict_stratification{false};
+
+        std::atomic<bool>     _cancel_requested{false}; // unwinds a query or run from within, e.g. at a limit
+        std::atomic<uint64_t> _run_id{0};               // of the outermost run in progress, 0 if none
+        std::atomic<uint64_t> _cancelled_run{0};        // the run request_cancel was called for
+        uint64_t              _runs_started{0};
+
+        uint64_t              _max_facts{0};
+        size_t                _max_memory{0};
+        std::atomic<uint64_t> _run_deductions{0};
+        std::string           _limit_reason; // guarded by _mtx_limit, set when a limit stopped the run
+        mutable std::mutex    _mtx_limit;
+
+        int               _max_iterations{0};
+        uint64_t          _max_deductions{0};
+        std::string       _pause_reason; // guarded by _mtx_limit, set when a bound paused the run
+        std::atomic<bool> _fixpoint_reached{true};
+
+        uint32_t                           _max_term_depth{0};
+        std::unordered_map<Node, uint32_t> _term_depths; // guarded by _mtx_network, only filled while _max_term_depth is set
+
+        std::chrono::milliseconds             _query_timeout{0};
+        uint64_t                              _max_query_matches{0};
+        std::atomic<bool>                     _query_bounded{false}; // while a query with bounds is evaluated
+        std::chrono::steady_clock::time_point _query_deadline;
+        std::atomic<uint64_t>                 _query_matches{0};
+        std::atomic<bool>                     _query_truncated{false};
+        std::string                           _query_truncation; // guarded by _mtx_limit, set when a bound stopped the query
+
+        ProgressObserver                      _on_progress;
+        std::chrono::milliseconds             _progress_interval{1000};
+        std::chrono::steady_clock::time_point _run_started;
+        std::atomic<int>                      _run_iterations{0};
+        std::thread                           _progress_thread;
+        std::mutex                            _mtx_progress;
+        std::condition_variable               _cv_progress;
+        bool                                  _progress_stop{false}; // guarded by _mtx_progress
+
+        std::unordered_map<Node, Builtin> _builtins;
+        std::mutex                        _mtx_builtin_calls;
+        std::string                       _builtin_error; // guarded by _mtx_limit, set when a builtin threw
+
+        bool                                  _native_arithmetic{false};
+        bool                                  _native_strings{false};
+        bool                                  _list_builtins{false};
+        std::unordered_map<Node, std::string> _native;     // relations of native arithmetic and string builtins, see install_native
+        std::unordered_map<Node, std::string> _native_ops; // their term operators + - * / ++
+
+        std::atomic<bool>  _metrics_enabled{false};
+        RunMetrics         _metrics; // guarded by _mtx_metrics
+        mutable std::mutex _mtx_metrics;
+
+        std::atomic<bool>                     _rule_profiling{false};
+        std::unordered_map<Node, RuleProfile> _rule_profile; // guarded by _mtx_metrics
     };
-}
\ No newline at end of file
+}
@@ -0,0 +1,270 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "string/string_utils.hpp"
+#include "zelph_impl.hpp"
+
+#include <cctype>
+#include <charconv>
+#include <cmath>
+#include <cstdlib>
+#include <iomanip>
+#include <optional>
+#include <sstream>
+
+using namespace zelph::network;
+
+namespace
+{
+    // A numeric literal: the name of a node like "1000000", "-3" or "2.5".
+    // Integers are exact, everything else (and integer overflow) is a double.
+    struct Number
+    {
+        bool      integral{true};
+        long long i{0};
+        double    d{0};
+
+        double value() const { return integral ? static_cast<double>(i) : d; }
+    };
+
+    std::optional<Number> parse_number(const std::string& s)
+    {
+        if (s.empty()) return std::nullopt;
+
+        Number n;
+        const char* const end = s.data() + s.size();
+        const auto [ptr, ec]  = std::from_chars(s.data(), end, n.i);
+        if (ec == std::errc() && ptr == end) return n;
+
+        // No "inf", "nan" or hex floats: a literal starts like a number.
+        const size_t digit = s[0] == '-' || s[0] == '+' ? 1 : 0;
+        if (digit >= s.size() || !(std::isdigit(static_cast<unsigned char>(s[digit])) || s[digit] == '.')) return std::nullopt;
+        if (s.find_first_of("xXnN", digit) != std::string::npos) return std::nullopt;
+
+        char*        parsed = nullptr;
+        const double d      = std::strtod(s.c_str(), &parsed);
+        if (parsed != s.c_str() + s.size() || !std::isfinite(d)) return std::nullopt;
+
+        n.integral = false;
+        n.d        = d;
+        return n;
+    }
+
+    std::string format_number(const Number& n)
+    {
+        if (n.integral) return std::to_string(n.i);
+        if (std::trunc(n.d) == n.d && std::fabs(n.d) < 1e15) return std::to_string(static_cast<long long>(n.d));
+
+        std::ostringstream out;
+        out << std::setprecision(15) << n.d;
+        return out.str();
+    }
+
+    int compare(const Number& a, const Number& b)
+    {
+        if (a.integral && b.integral) return a.i < b.i ? -1 : a.i > b.i ? 1 : 0;
+        return a.value() < b.value() ? -1 : a.value() > b.value() ? 1 : 0;
+    }
+
+    std::optional<Number> compute(const char op, const Number& a, const Number& b)
+    {
+        if (a.integral && b.integral)
+        {
+            Number r;
+            bool   overflow = false;
+            switch (op)
+            {
+            case '+':
+                overflow = __builtin_add_overflow(a.i, b.i, &r.i);
+                break;
+            case '-':
+                overflow = __builtin_sub_overflow(a.i, b.i, &r.i);
+                break;
+            case '*':
+                overflow = __builtin_mul_overflow(a.i, b.i, &r.i);
+                break;
+            default: // '/'
+                if (b.i == 0) return std::nullopt;
+                if (b.i == -1)
+                    overflow = __builtin_sub_overflow(0LL, a.i, &r.i);
+                else if (a.i % b.i != 0)
+                    overflow = true; // not exact, use a double
+                else
+                    r.i = a.i / b.i;
+                break;
+            }
+            if (!overflow) return r;
+        }
+
+        Number r;
+        r.integral = false;
+        switch (op)
+        {
+        case '+':
+            r.d = a.value() + b.value();
+            break;
+        case '-':
+            r.d = a.value() - b.value();
+            break;
+        case '*':
+            r.d = a.value() * b.value();
+            break;
+        default: // '/'
+            if (b.value() == 0) return std::nullopt;
+            r.d = a.value() / b.value();
+            break;
+        }
+        if (!std::isfinite(r.d)) return std::nullopt;
+        return r;
+    }
+}
+
+void Reasoning::set_native_arithmetic(const bool enabled)
+{
+    _native_arithmetic = enabled;
+    install_native();
+}
+
+// (Re)creates the relation and operator tables of native arithmetic and
+// the string builtins (see reasoning_strings.cpp), which share = and terms.
+void Reasoning::install_native()
+{
+    _native.clear();
+    _native_ops.clear();
+
+    if (_native_arithmetic)
+    {
+        for (const char* rel : {"<", "<=", ">", ">="})
+            _native[node(rel)] = rel;
+        for (const char* op : {"+", "-", "*", "/"})
+            _native_ops[node(op)] = op;
+    }
+    if (_native_strings)
+    {
+        for (const char* rel : {"starts_with", "ends_with", "equals_ignoring_case"})
+            _native[node(rel)] = rel;
+        _native_ops[node("++")] = "++";
+    }
+    if (_native_arithmetic || _native_strings) _native[node("=")] = "=";
+}
+
+// The value of a side of a native condition: the name of the bound node,
+// or for a term (A op B) the result of op, computed recursively. No value
+// for an unbound variable, an unnamed node or an operation that fails.
+std::optional<std::string> Reasoning::native_value(const Node n, const Node parent, const Variables& bindings)
+{
+    if (Zelph::Impl::is_var(n))
+    {
+        const Node bound = string::get(bindings, n, n);
+        if (Zelph::Impl::is_var(bound)) return std::nullopt;
+        return native_value(bound, 0, bindings);
+    }
+
+    adjacency_set op = filter(n, core.IsA, core.RelationTypeCategory);
+    if (op.size() == 1 && _native_ops.count(*op.begin()) == 1)
+    {
+        adjacency_set objects;
+        const Node    a = parse_fact(n, objects, parent);
+        if (a == 0 || objects.size() != 1) return std::nullopt;
+
+        const auto x = native_value(a, n, bindings);
+        const auto y = native_value(*objects.begin(), n, bindings);
+        if (!x || !y) return std::nullopt;
+
+        const std::string& name = _native_ops.at(*op.begin());
+        if (name == "++") return *x + *y;
+
+        const auto nx = parse_number(*x);
+        const auto ny = parse_number(*y);
+        if (!nx || !ny) return std::nullopt;
+        const auto result = compute(name[0], *nx, *ny);
+        if (!result) return std::nullopt;
+        return format_number(*result);
+    }
+
+    std::string name = get_name(n);
+    if (name.empty()) return std::nullopt;
+    return name;
+}
+
+// Evaluate a condition of native arithmetic or a string builtin: X < Y,
+// X <= Y, X > Y and X >= Y on numbers, the string tests, and L = R, which
+// binds an unbound R to the node named by the value of L and otherwise
+// compares both values (as numbers if both are). Either side may be a term
+// like (A + B) or (A ++ B); the only variable that can be bound is R.
+void Reasoning::evaluate_native(const Node condition, const Node relation, const RulePos& rule, ReasoningContext& ctx, const int depth)
+{
+    adjacency_set objects;
+    const Node    subject = parse_fact(condition, objects, rule.node);
+    if (subject == 0 || objects.size() != 1)
+    {
+        if (should_log(depth)) log(depth, "native", "could not parse " + format(condition));
+        return;
+    }
+
+    const std::string& rel   = _native.at(relation);
+    const Node         rhs   = Zelph::Impl::is_var(*objects.begin()) ? string::get(*rule.variables, *objects.begin(), *objects.begin()) : *objects.begin();
+    const auto         left  = native_value(subject, condition, *rule.variables);
+
+    if (rel == "=" && left && Zelph::Impl::is_var(rhs))
+    {
+        if (should_log(depth)) log(depth, "native", format(condition) + " binds " + *left);
+
+        auto bindings    = std::make_shared<Variables>(*rule.variables);
+        (*bindings)[rhs] = node(*left);
+        if (!contradicts(*bindings, *rule.unequals))
+            proceed_after_condition(rule, ctx, depth, bindings, rule.unequals, rule.confidence);
+        return;
+    }
+
+    const auto right = native_value(rhs, condition, *rule.variables);
+    bool       holds = false;
+    if (left && right)
+    {
+        const auto x = _native_arithmetic ? parse_number(*left) : std::nullopt;
+        const auto y = _native_arithmetic ? parse_number(*right) : std::nullopt;
+        if (rel == "=")
+        {
+            holds = x && y ? compare(*x, *y) == 0 : *left == *right;
+        }
+        else if (rel == "<" || rel == "<=" || rel == ">" || rel == ">=")
+        {
+            if (x && y)
+            {
+                const int c = compare(*x, *y);
+                holds       = rel == "<" ? c < 0 : rel == "<=" ? c <= 0 : rel == ">" ? c > 0 : c >= 0;
+            }
+        }
+        else
+        {
+            holds = string_relation_holds(rel, *left, *right);
+        }
+    }
+
+    if (should_log(depth)) log(depth, "native", format(condition) + (holds ? " holds" : " does not hold"));
+    if (holds) proceed_after_condition(rule, ctx, depth, rule.variables, rule.unequals, rule.confidence);
+}
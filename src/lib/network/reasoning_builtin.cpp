@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "string/string_utils.hpp"
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <stdexcept>
+
+using namespace zelph::network;
+
+void Reasoning::register_builtin(const Node relation, Builtin function)
+{
+    if (!function)
+        _builtins.erase(relation);
+    else
+        _builtins[relation] = std::move(function);
+}
+
+// Evaluate a condition (S builtin O...): call the function with the
+// resolved parts and continue once per solution it returns.
+void Reasoning::evaluate_builtin(const Node condition, const Node relation, const RulePos& rule, ReasoningContext& ctx, const int depth)
+{
+    adjacency_set objects;
+    const Node    subject = parse_fact(condition, objects, rule.node);
+    if (subject == 0)
+    {
+        if (should_log(depth)) log(depth, "builtin", "could not parse " + format(condition));
+        return;
+    }
+
+    auto resolved = [&](Node n) -> Node
+    { return Zelph::Impl::is_var(n) ? string::get(*rule.variables, n, n) : n; };
+
+    std::vector<Node> args{resolved(subject)};
+    for (Node object : objects)
+        args.push_back(resolved(object));
+
+    std::vector<Variables> solutions;
+    try
+    {
+        std::lock_guard<std::mutex> lock(_mtx_builtin_calls);
+        solutions = _builtins.at(relation)(args);
+    }
+    catch (const std::exception& ex)
+    {
+        {
+            std::lock_guard<std::mutex> lock(_mtx_limit);
+            if (_builtin_error.empty()) _builtin_error = "Builtin " + get_name(relation, _lang, true) + " failed: " + ex.what();
+        }
+        request_cancel();
+        return;
+    }
+
+    if (should_log(depth))
+        log(depth, "builtin", format(condition) + " has " + std::to_string(solutions.size()) + " solution(s)");
+
+    for (const auto& solution : solutions)
+    {
+        auto bindings = std::make_shared<Variables>(*rule.variables);
+        bool valid    = true;
+        for (const auto& [var, value] : solution)
+        {
+            // Only the variables passed unbound may be bound.
+            if (!Zelph::Impl::is_var(var) || std::find(args.begin(), args.end(), var) == args.end() || value == 0)
+            {
+                valid = false;
+                break;
+            }
+            (*bindings)[var] = value;
+        }
+        if (!valid || contradicts(*bindings, *rule.unequals)) continue;
+
+        proceed_after_condition(rule, ctx, depth, bindings, rule.unequals, rule.confidence);
+    }
+}
+
+// Rethrows the error of a builtin that stopped the run or query, if any.
+void Reasoning::throw_builtin_error()
+{
+    std::string error;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_limit);
+        error.swap(_builtin_error);
+    }
+    if (error.empty()) return;
+
+    _cancel_requested = false;
+    throw std::runtime_error(error);
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "zelph_impl.hpp"
+
+using namespace zelph::network;
+
+namespace
+{
+    // Entries beyond this are not kept; a full cache starts over.
+    constexpr size_t max_cached_queries = 1000;
+
+    std::string cache_key(const std::string& statement, const std::string& lang)
+    {
+        return statement + '\0' + lang;
+    }
+}
+
+void Reasoning::set_query_cache(const bool enabled)
+{
+    _query_cache = enabled;
+    if (!enabled) clear_query_cache();
+}
+
+void Reasoning::clear_query_cache()
+{
+    std::lock_guard<std::mutex> lock(_mtx_query_cache);
+    _query_cache_entries.clear();
+    _relation_versions.clear();
+    ++_query_cache_epoch;
+}
+
+size_t Reasoning::query_cache_size() const
+{
+    std::lock_guard<std::mutex> lock(_mtx_query_cache);
+    return _query_cache_entries.size();
+}
+
+// The cached answers to the statement, if none of the changes since they
+// were found can affect them; sets pattern to the evaluated statement.
+std::optional<Reasoning::NamedAnswers> Reasoning::cached_query(const std::string& statement, Node& pattern)
+{
+    if (!_query_cache) return std::nullopt;
+
+    std::lock_guard<std::mutex> lock(_mtx_query_cache);
+    auto                        it = _query_cache_entries.find(cache_key(statement, lang()));
+    if (it == _query_cache_entries.end()) return std::nullopt;
+
+    const CachedQuery& entry = it->second;
+    bool               valid = entry.epoch == _query_cache_epoch
+                && entry.changes == _pImpl->unobserved_changes()
+                && entry.min_confidence == _min_confidence
+                && entry.as_of == _as_of
+                && entry.context_scope == _context_scope
+                && entry.source_scope == _source_scope
+                && entry.min_trust == _min_trust
+                && exists(entry.pattern);
+    for (const auto& [relation, version] : entry.versions)
+    {
+        if (!valid) break;
+        if (relation == 0)
+        {
+            valid = version == _any_relation_version;
+            continue;
+        }
+        auto v = _relation_versions.find(relation);
+        valid  = version == (v == _relation_versions.end() ? 0 : v->second);
+    }
+    if (!valid)
+    {
+        _query_cache_entries.erase(it);
+        return std::nullopt;
+    }
+
+    ++_query_cache_hits;
+    _query_truncated = false; // cached answers are complete
+    pattern          = entry.pattern;
+    return entry.answers;
+}
+
+void Reasoning::cache_query(const std::string& statement, const Node pattern, const NamedAnswers& answers)
+{
+    if (!_query_cache || _query_truncated) return;
+
+    std::set<Node>           relations;
+    std::unordered_set<Node> visited;
+    if (!query_relations(pattern, relations, visited)) return;
+
+    CachedQuery entry;
+    entry.pattern        = pattern;
+    entry.answers        = answers;
+    entry.changes        = _pImpl->unobserved_changes();
+    entry.min_confidence = _min_confidence;
+    entry.as_of          = _as_of;
+    entry.context_scope  = _context_scope;
+    entry.source_scope   = _source_scope;
+    entry.min_trust      = _min_trust;
+
+    std::lock_guard<std::mutex> lock(_mtx_query_cache);
+    entry.epoch = _query_cache_epoch;
+    for (Node relation : relations)
+    {
+        if (relation == 0)
+        {
+            entry.versions.emplace_back(0, _any_relation_version);
+            continue;
+        }
+        auto v = _relation_versions.find(relation);
+        entry.versions.emplace_back(relation, v == _relation_versions.end() ? 0 : v->second);
+    }
+
+    if (_query_cache_entries.size() >= max_cached_queries) _query_cache_entries.clear();
+    _query_cache_entries.insert_or_assign(cache_key(statement, lang()), std::move(entry));
+}
+
+// Called by the fact-creation observers. Facts with variables are the
+// patterns of queries and rules, not facts an answer can match.
+void Reasoning::note_query_cache_fact(const Node fact, const Node predicate)
+{
+    if (!_query_cache || Zelph::Impl::is_var(predicate)) return;
+
+    adjacency_set objects;
+    const Node    subject = parse_fact(fact, objects);
+    if (Zelph::Impl::is_var(subject)) return;
+    for (Node o : objects)
+        if (Zelph::Impl::is_var(o)) return;
+
+    touch_query_cache(predicate);
+}
+
+// Invalidates the cached answers that depend on facts of the relation.
+void Reasoning::touch_query_cache(const Node relation)
+{
+    if (!_query_cache) return;
+    std::lock_guard<std::mutex> lock(_mtx_query_cache);
+    ++_relation_versions[relation];
+    ++_any_relation_version;
+}
+
+// Collects the relations of the pattern, including those of its
+// conjunction elements and nested facts (0 for a variable relation).
+// Returns false if the answers also depend on something outside the
+// network.
+bool Reasoning::query_relations(const Node pattern, std::set<Node>& relations, std::unordered_set<Node>& visited)
+{
+    if (!visited.insert(pattern).second) return true;
+
+    if (check_fact(pattern, core.IsA, {core.Conjunction}).is_known())
+    {
+        for (Node rel : _pImpl->get_right(pattern))
+        {
+            if (parse_relation(rel) != core.PartOf) continue;
+            adjacency_set objs;
+            Node          element = parse_fact(rel, objs);
+            if (element && objs.count(pattern) == 1 && !query_relations(element, relations, visited)) return false;
+        }
+        return true;
+    }
+
+    if (!is_hash(pattern)) return true;
+
+    const Node relation = parse_relation(pattern);
+    if (Zelph::Impl::is_var(relation))
+        relations.insert(0);
+    else if (_builtins.count(relation) == 1 || (_nn_pred != 0 && relation == _nn_pred))
+        return false;
+    else
+        relations.insert(relation);
+
+    adjacency_set objects;
+    const Node    subject = parse_fact(pattern, objects);
+    if (subject && !query_relations(subject, relations, visited)) return false;
+    for (Node o : objects)
+        if (!query_relations(o, relations, visited)) return false;
+    return true;
+}
@@ -0,0 +1,43 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <stdexcept>
+
+namespace zelph::network
+{
+    // Thrown by Reasoning::run when Reasoning::request_cancel was called
+    // during the run. All facts derived before the stop remain in the
+    // network; the run simply did not reach its fixpoint.
+    class reasoning_cancelled final : public std::runtime_error
+    {
+    public:
+        reasoning_cancelled()
+            : std::runtime_error("Reasoning cancelled before reaching a fixpoint")
+        {
+        }
+    };
+}
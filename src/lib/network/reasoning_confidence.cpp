@@ -0,0 +1,204 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include <algorithm>
+#include <cmath>
+#include <vector>
+
+using namespace zelph::network;
+
+double Reasoning::confidence(const Node fact) const
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+
+    return edge_weight(fact, parse_relation(fact), 1.0);
+}
+
+void Reasoning::set_confidence(const Node fact, const double confidence)
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+    if (!(confidence >= 0 && confidence <= 1))
+        throw std::runtime_error("Confidence must be between 0 and 1");
+
+    set_edge_weight(fact, parse_relation(fact), confidence);
+    touch_query_cache(parse_relation(fact));
+}
+
+void Reasoning::set_confidence_combination(const Node rule, const ConfidenceCombination combination, ConfidenceCombiner combiner)
+{
+    if (rule != 0 && get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+    if (combination == ConfidenceCombination::Custom && !combiner)
+        throw std::runtime_error("A custom confidence combination needs a combiner");
+
+    CombinationSetting setting{combination, combination == ConfidenceCombination::Custom ? std::move(combiner) : nullptr};
+    if (rule == 0)
+        _default_combination = std::move(setting);
+    else
+        _rule_combinations[rule] = std::move(setting);
+}
+
+Reasoning::ConfidenceCombination Reasoning::confidence_combination(const Node rule) const
+{
+    auto it = _rule_combinations.find(rule);
+    return it == _rule_combinations.end() ? _default_combination.combination : it->second.combination;
+}
+
+void Reasoning::set_min_confidence(const double threshold)
+{
+    if (!(threshold >= 0 && threshold <= 1))
+        throw std::runtime_error("Confidence threshold must be between 0 and 1");
+
+    _min_confidence = threshold;
+}
+
+// Combines the confidences of the facts matched by the rule's condition
+// into confidence (which holds that of its ≈ conditions). Returns false if
+// the deduction is not to be made. Called from deduce().
+bool Reasoning::combine_confidence(const Node rule, const Node condition, const Variables& bindings, double& confidence) const
+{
+    auto                      it      = _rule_combinations.find(rule);
+    const CombinationSetting& setting = it == _rule_combinations.end() ? _default_combination : it->second;
+    if (setting.combination == ConfidenceCombination::None) return true;
+
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+
+    std::vector<double> confidences;
+    confidences.reserve(premises.size());
+    for (Node premise : premises)
+        confidences.push_back(edge_weight(premise, parse_relation(premise), 1.0));
+
+    double combined = 1;
+    switch (setting.combination)
+    {
+    case ConfidenceCombination::Min:
+        for (double c : confidences)
+            combined = std::min(combined, c);
+        break;
+    case ConfidenceCombination::Product:
+        for (double c : confidences)
+            combined *= c;
+        break;
+    case ConfidenceCombination::Custom:
+        combined = std::clamp(setting.combiner(confidences), 0.0, 1.0);
+        break;
+    case ConfidenceCombination::None:
+        break;
+    }
+
+    confidence *= combined;
+    return confidence > 0.5;
+}
+
+// The confidence of a query answer: that of its weakest matched fact,
+// times the confidence of its ≈ conditions.
+double Reasoning::answer_confidence(const Node condition, const Variables& bindings, const double confidence) const
+{
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+
+    double weakest = 1;
+    for (Node premise : premises)
+        weakest = std::min(weakest, edge_weight(premise, parse_relation(premise), 1.0));
+    return weakest * confidence;
+}
+
+void Reasoning::set_rule_weight(const Node rule, const double weight)
+{
+    if (get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+    if (!(weight >= 0 && weight <= 1))
+        throw std::runtime_error("Rule weight must be between 0 and 1");
+
+    _rule_weights[rule] = weight;
+}
+
+double Reasoning::rule_weight(const Node rule) const
+{
+    auto it = _rule_weights.find(rule);
+    return it == _rule_weights.end() ? 1 : it->second;
+}
+
+double Reasoning::answer_probability(const Node condition, const Variables& bindings) const
+{
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+
+    double probability = 1;
+    for (Node premise : premises)
+        probability *= edge_weight(premise, parse_relation(premise), 1.0);
+    return probability;
+}
+
+// Sets the marginal probability of every deduced fact from the derivations
+// recorded in probabilistic mode: a fact is false only if each of its
+// derivations fails. Starting from 0 and iterating to the least fixpoint
+// lets facts that support each other (e.g. via a symmetric rule) converge
+// instead of confirming themselves.
+void Reasoning::update_marginals()
+{
+    std::unordered_map<Node, double> marginal;
+    for (const auto& [fact, supports] : _supports)
+        if (is_deduced(fact) && exists(fact)) marginal[fact] = 0;
+
+    auto probability = [&](const Node fact)
+    {
+        auto it = marginal.find(fact);
+        if (it != marginal.end()) return it->second;
+        return exists(fact) ? edge_weight(fact, parse_relation(fact), 1.0) : 0.0;
+    };
+
+    constexpr int    max_rounds = 1000;
+    constexpr double epsilon    = 1e-12;
+    for (int round = 0; round < max_rounds; ++round)
+    {
+        double change = 0;
+        for (auto& [fact, value] : marginal)
+        {
+            double none = 1;
+            for (const Support& support : _supports.at(fact))
+            {
+                double holds = support.confidence * rule_weight(support.rule);
+                for (Node premise : support.premises)
+                    holds *= probability(premise);
+                none *= 1 - holds;
+            }
+            change = std::max(change, std::abs(1 - none - value));
+            value  = 1 - none;
+        }
+        if (change < epsilon) break;
+    }
+
+    for (const auto& [fact, value] : marginal)
+    {
+        set_edge_weight(fact, parse_relation(fact), value);
+        touch_query_cache(parse_relation(fact));
+    }
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include <algorithm>
+#include <iterator>
+#include <map>
+#include <vector>
+
+using namespace zelph::network;
+
+Reasoning::ContextSet Reasoning::contexts(const Node fact) const
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+
+    auto it = _contexts.find(fact);
+    return it == _contexts.end() ? ContextSet{} : it->second;
+}
+
+void Reasoning::add_to_context(const Node fact, const std::string& context)
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+    if (context.empty())
+        throw std::runtime_error("Context name must not be empty");
+
+    invalidate_incremental();
+    std::lock_guard<std::mutex> lock(_mtx_network);
+    _contexts[fact].insert(context);
+    touch_query_cache(parse_relation(fact));
+}
+
+void Reasoning::remove_from_context(const Node fact, const std::string& context)
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+
+    invalidate_incremental();
+    std::lock_guard<std::mutex> lock(_mtx_network);
+    auto                        it = _contexts.find(fact);
+    if (it == _contexts.end()) return;
+    it->second.erase(context);
+    if (it->second.empty()) _contexts.erase(it);
+    touch_query_cache(parse_relation(fact));
+}
+
+// Each context with the number of existing facts in it, by name.
+std::vector<std::pair<std::string, size_t>> Reasoning::list_contexts() const
+{
+    std::map<std::string, size_t> counts;
+    for (const auto& [fact, contexts] : _contexts)
+    {
+        if (!exists(fact)) continue;
+        for (const auto& context : contexts)
+            ++counts[context];
+    }
+    return {counts.begin(), counts.end()};
+}
+
+void Reasoning::set_rule_contexts(const Node rule, const ContextSet& contexts)
+{
+    if (get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+
+    invalidate_incremental();
+    if (contexts.empty())
+        _rule_contexts.erase(rule);
+    else
+        _rule_contexts[rule] = contexts;
+}
+
+Reasoning::ContextSet Reasoning::rule_contexts(const Node rule) const
+{
+    auto it = _rule_contexts.find(rule);
+    return it == _rule_contexts.end() ? ContextSet{} : it->second;
+}
+
+// Intersects the contexts of the rule (0 for a query) and of the facts its
+// conditions matched; unset means every context. Returns false if no
+// context is left or none of them is in the scope. During a run, the
+// caller holds _mtx_network.
+bool Reasoning::premise_contexts(const Node rule, const Node condition, const Variables& bindings, std::optional<ContextSet>& contexts) const
+{
+    contexts.reset();
+    if (_contexts.empty() && _rule_contexts.empty()) return true;
+
+    auto intersect = [&contexts](const ContextSet& other)
+    {
+        if (!contexts)
+        {
+            contexts = other;
+            return;
+        }
+        ContextSet common;
+        std::set_intersection(contexts->begin(), contexts->end(), other.begin(), other.end(), std::inserter(common, common.end()));
+        contexts = std::move(common);
+    };
+
+    auto rule_it = _rule_contexts.find(rule);
+    if (rule_it != _rule_contexts.end()) intersect(rule_it->second);
+
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+    for (Node premise : premises)
+    {
+        auto it = _contexts.find(premise);
+        if (it != _contexts.end()) intersect(it->second);
+    }
+
+    if (!contexts) return true;
+    if (contexts->empty()) return false;
+    return _context_scope.empty()
+        || std::any_of(contexts->begin(), contexts->end(), [this](const std::string& context)
+                       { return _context_scope.count(context) == 1; });
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+
+#include "reasoning.hpp"
+
+#include <algorithm>
+#include <vector>
+
+using namespace zelph::network;
+
+std::vector<Reasoning::AgendaItem> Reasoning::agenda(const size_t limit)
+{
+    std::vector<AgendaItem> result;
+
+    for (Node rule : ordered_rules())
+    {
+        if (!is_rule_enabled(rule)) continue;
+
+        adjacency_set deductions;
+        const Node    condition = parse_fact(rule, deductions);
+        if (!condition || deductions.empty()) continue;
+
+        // The matches of the condition, collected like the answers of a query.
+        profiler_reset_epoch();
+        std::vector<std::shared_ptr<Variables>> matches;
+        set_query_collector(&matches);
+        try
+        {
+            apply_rule(0, condition);
+        }
+        catch (...)
+        {
+            set_query_collector(nullptr);
+            throw;
+        }
+        set_query_collector(nullptr);
+
+        for (const auto& bindings : matches)
+        {
+            const bool pending = std::any_of(deductions.begin(), deductions.end(), [&](Node deduction)
+                                             {
+                std::vector<Node> history;
+                return find_instance(deduction, *bindings, history) == 0; });
+            if (!pending) continue;
+
+            result.push_back({rule, *bindings});
+            if (limit != 0 && result.size() == limit) return result;
+        }
+    }
+
+    return result;
+}
@@ -30,12 +30,15 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "string/string_utils.hpp"
 #include "zelph_impl.hpp"
 
+#include <algorithm>
+
 using namespace zelph::network;
 
-void Reasoning::deduce(const Variables& variables, const Node parent, const int depth, ReasoningContext& ctx, const double confidence)
+void Reasoning::deduce(const Variables& variables, const Node parent, const int depth, ReasoningContext& ctx, const double condition_confidence)
 {
     if (logging_active())
         _prof.deduce_calls.fetch_add(1, std::memory_order_relaxed);
+    record_firing(parent);
 
     if (should_log(depth))
     {
@@ -45,6 +48,52 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
         log(depth, "deduce", "BEGIN with bindings:" + vars_str);
     }
 
+    // --- Confidence ---
+    // Premise confidences, if the rule combines them (see
+    // set_confidence_combination). Probabilistic mode leaves them to
+    // update_marginals instead.
+    double confidence = condition_confidence;
+    if (!_probabilistic && !combine_confidence(parent, ctx.current_condition, variables, confidence))
+    {
+        if (should_log(depth))
+            log(depth, "deduce", "SKIP: confidence " + std::to_string(confidence) + " is not above 0.5");
+        return;
+    }
+
+    // --- Validity, contexts, sources and trust ---
+    // The deduction holds where the intervals of its premises overlap, in
+    // the contexts they share, and is only made from sources in scope that
+    // are trusted enough.
+    Validity                  validity;
+    std::optional<ContextSet> contexts;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        if (!premise_validity(ctx.current_condition, variables, validity))
+        {
+            if (should_log(depth))
+                log(depth, "deduce", "SKIP: the validity intervals of the premises do not overlap");
+            return;
+        }
+        if (!premise_contexts(parent, ctx.current_condition, variables, contexts))
+        {
+            if (should_log(depth))
+                log(depth, "deduce", "SKIP: the premises share no context in scope");
+            return;
+        }
+        if (!premise_sources(ctx.current_condition, variables))
+        {
+            if (should_log(depth))
+                log(depth, "deduce", "SKIP: a premise comes from a source out of scope");
+            return;
+        }
+        if (!premise_trust(parent, ctx.current_condition, variables))
+        {
+            if (should_log(depth))
+                log(depth, "deduce", "SKIP: a premise is trusted less than required");
+            return;
+        }
+    }
+
     // --- Fresh Variable Detection ---
     // Variables that appear in consequences but are not bound by conditions
     // are "fresh variables": each rule firing creates a new node for them.
@@ -107,6 +156,20 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
         log(depth, "deduce", "No fresh variables");
     }
 
+    // --- Term Depth Guard ---
+    // Only firings that build terms count, see set_max_term_depth.
+    uint32_t   term_depth   = 0;
+    const bool builds_terms = _max_term_depth != 0
+                           && (!fresh_vars.empty()
+                               || std::any_of(ctx.rule_deductions.begin(), ctx.rule_deductions.end(), [this](Node deduction)
+                                              { return nested_term(deduction) != 0; }));
+    if (builds_terms && !check_term_depth(parent, variables, term_depth))
+    {
+        if (should_log(depth))
+            log(depth, "deduce", "SKIP: term depth " + std::to_string(term_depth) + " exceeds the limit");
+        return;
+    }
+
     // --- Create Fresh Nodes ---
     Variables augmented = variables;
     for (Node var : fresh_vars)
@@ -115,6 +178,7 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
         {
             std::lock_guard<std::mutex> lock(_mtx_network);
             fresh = _pImpl->create();
+            if (builds_terms) _term_depths[fresh] = term_depth;
         }
         augmented[var] = fresh;
 
@@ -125,6 +189,14 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
             log(depth, "deduce", "Created fresh node " + std::to_string(fresh) + " for " + format(var));
     }
 
+    // Probabilistic mode records which facts support each derivation.
+    std::vector<Node> premises;
+    if (_probabilistic)
+    {
+        collect_premises(ctx.current_condition, augmented, premises);
+        std::sort(premises.begin(), premises.end());
+    }
+
     // --- Process Deductions ---
     for (const Node deduction : ctx.rule_deductions)
     {
@@ -229,6 +301,15 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
                     }
                 }
 
+                // A default rule does not deduce an assumption that a
+                // contradiction defeated (see set_rule_default).
+                if (done && is_defeated(parent, Zelph::Impl::create_hash(rel, source, targets)))
+                {
+                    done = false;
+                    if (should_log(depth))
+                        log(depth, "deduce", "SKIP: assumption defeated by a contradiction");
+                }
+
                 if (done)
                 {
                     Answer answer = check_fact(source, rel, targets);
@@ -241,7 +322,7 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
                         log(depth, "deduce", "check_fact(" + format(source) + ", " + format(rel) + "," + targets_str + ") => " + (answer.is_known() ? (answer.is_wrong() ? "WRONG" : "KNOWN/exists") : "UNKNOWN/new") + (targets.count(rel) ? " [target==rel, skip]" : ""));
                     }
 
-                    if (answer.is_wrong())
+                    if (answer.is_wrong() && !_probabilistic)
                     {
                         if (logging_active())
                             _prof.check_fact_wrong.fetch_add(1, std::memory_order_relaxed);
@@ -258,8 +339,19 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
                             // Confidence < 1 (from ≈ conditions) becomes the fact's probability in
                             // the shared weight store. Note: if the fact already exists (known
                             // correct), the existing probability is NOT upgraded or touched.
-                            d       = fact(source, rel, targets, confidence);
+                            d       = fact(source, rel, targets, _probabilistic ? 1 : confidence);
                             created = true;
+                            _deduced_facts.insert(d);
+                            if (!validity.always()) _validity[d] = validity;
+                            if (contexts) _contexts[d] = *contexts;
+                            _derivations.emplace(d, Derivation{parent, ctx.current_condition, augmented});
+                            if (builds_terms)
+                            {
+                                _term_depths.emplace(d, term_depth);
+                                if (Zelph::Impl::is_hash(source)) _term_depths.emplace(source, term_depth);
+                                for (Node t : targets)
+                                    if (Zelph::Impl::is_hash(t)) _term_depths.emplace(t, term_depth);
+                            }
 
                             if (logging_active())
                             {
@@ -278,6 +370,16 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
                     }
                     else if (logging_active() && answer.is_known())
                         _prof.check_fact_known.fetch_add(1, std::memory_order_relaxed);
+
+                    Node supported = created ? d : (answer.is_known() ? answer.relation() : 0);
+                    if (_probabilistic && supported && targets.count(rel) == 0)
+                    {
+                        auto&   supports = _supports[supported];
+                        Support support{parent, confidence, premises};
+                        if (std::none_of(supports.begin(), supports.end(), [&](const Support& s)
+                                         { return s.rule == support.rule && s.premises == support.premises; }))
+                            supports.push_back(std::move(support));
+                    }
                 }
                 else if (should_log(depth))
                 {
@@ -291,55 +393,69 @@ void Reasoning::deduce(const Variables& variables, const Node parent, const int
 
         if (created)
         {
-            std::lock_guard<std::mutex> lock(_mtx_output);
-            bool                        do_print = _print_deductions;
+            report_deduction(d, parent, ctx.current_condition, augmented);
 
-            if (!do_print && _stop_watch.is_running() && _stop_watch.duration() >= 1000)
-            {
-                do_print = true;
-                _stop_watch.start();
-            }
-            else if (!do_print)
-            {
-                ++_skipped;
-            }
-            else
-            {
-                _stop_watch.start();
-            }
+            if (should_log(depth))
+                log(depth, "deduce", "CREATED fact " + format(d));
+        }
+        else if (should_log(depth) && !wrong)
+        {
+            log(depth, "deduce", "No new fact created (already exists or skipped)");
+        }
+    }
+}
 
-            if (do_print || _generate_markdown)
-            {
-                size_t skipped_val = _skipped.exchange(0);
-                if (skipped_val > 0) diagnostic(" (skipped " + std::to_string(skipped_val) + " deductions)", true);
+// Counts, observes and prints a fact created by a rule firing (see deduce)
+// or by the closure of a relation property (see close_relation_properties).
+void Reasoning::report_deduction(const Node d, const Node parent, const Node condition, const Variables& bindings)
+{
+    check_limits();
 
-                std::string input, output;
-                string::node_to_string(this, input, _lang, ctx.current_condition, 3, augmented, parent);
-                string::node_to_string(this, output, _lang, d, 3, {}, parent);
+    std::lock_guard<std::mutex> lock(_mtx_output);
+    bool                        do_print = _print_deductions && _trace;
 
-                std::string message = output + " ⇐ " + input;
+    if (_on_deduction) _on_deduction(d, parent);
+    if (_break_condition && _break_condition(d, parent))
+        pause_run("the debugger stopped after a rule firing (see .debug)");
+    record_deduction(parent);
 
-                if (do_print)
-                {
-                    out(string::unmark_identifiers(message), true);
-                }
+    if (!do_print && _trace && _stop_watch.is_running() && _stop_watch.duration() >= 1000)
+    {
+        do_print = true;
+        _stop_watch.start();
+    }
+    else if (!do_print)
+    {
+        ++_skipped;
+    }
+    else
+    {
+        _stop_watch.start();
+    }
 
-                if (_generate_markdown)
-                {
-                    _markdown->add("Deductions", message);
-                }
-            }
+    if (do_print || _generate_markdown)
+    {
+        size_t skipped_val = _skipped.exchange(0);
+        if (skipped_val > 0) diagnostic(" (skipped " + std::to_string(skipped_val) + " deductions)", true);
 
-            _done = true;
+        std::string input, output;
+        string::node_to_string(this, input, _lang, condition, 3, bindings, parent);
+        string::node_to_string(this, output, _lang, d, 3, {}, parent);
 
-            if (should_log(depth))
-                log(depth, "deduce", "CREATED fact " + format(d));
+        std::string message = output + " ⇐ " + input;
+
+        if (do_print)
+        {
+            out(string::unmark_identifiers(message), true);
         }
-        else if (should_log(depth) && !wrong)
+
+        if (_generate_markdown)
         {
-            log(depth, "deduce", "No new fact created (already exists or skipped)");
+            _markdown->add("Deductions", message);
         }
     }
+
+    _done = true;
 }
 
 // Checks whether all deduction patterns of a fresh-variable rule are already
@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "contradiction_error.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <iterator>
+#include <limits>
+#include <stdexcept>
+
+using namespace zelph::network;
+
+void Reasoning::set_rule_default(const Node rule, const bool is_default, const int priority)
+{
+    if (get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+
+    if (is_default)
+        _default_rules[rule] = priority;
+    else
+        _default_rules.erase(rule);
+
+    // Contradictions are found again by the next run, and judged by the new
+    // priorities.
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        std::erase_if(_defeats, [rule](const auto& entry)
+                      { return entry.second.rule == rule; });
+    }
+    invalidate_incremental();
+}
+
+int Reasoning::default_priority(const Node rule) const
+{
+    auto it = _default_rules.find(rule);
+    return it == _default_rules.end() ? 0 : it->second;
+}
+
+std::vector<Reasoning::Defeat> Reasoning::defeats() const
+{
+    std::vector<Defeat> result;
+    for (const auto& [fact, defeat] : _defeats)
+        result.push_back(defeat);
+    std::sort(result.begin(), result.end(), [](const Defeat& a, const Defeat& b)
+              { return a.assumption.fact < b.assumption.fact; });
+    return result;
+}
+
+// Called by report_contradiction. The assumptions a contradiction rests on
+// are the deductions of default rules among its premises, or among the
+// premises of the deductions it used, and so on. Returns false if there
+// are none, so that the contradiction is reported.
+bool Reasoning::defeat_assumptions(const contradiction_error& error)
+{
+    std::vector<Node> premises;
+    collect_premises(error.get_fact(), error.get_variables(), premises);
+
+    std::vector<Node> defeated;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+
+        std::vector<Node>        assumptions;
+        std::vector<Node>        pending(premises);
+        std::unordered_set<Node> seen;
+        while (!pending.empty())
+        {
+            const Node fact = pending.back();
+            pending.pop_back();
+            if (!seen.insert(fact).second) continue;
+
+            const auto it = _derivations.find(fact);
+            if (it == _derivations.end()) continue; // stated
+            if (is_default_rule(it->second.rule))
+                assumptions.push_back(fact);
+            else
+                collect_premises(it->second.condition, it->second.bindings, pending);
+        }
+        if (assumptions.empty()) return false;
+
+        int lowest = std::numeric_limits<int>::max();
+        for (Node assumption : assumptions)
+            lowest = std::min(lowest, default_priority(_derivations.at(assumption).rule));
+
+        for (Node assumption : assumptions)
+        {
+            const Node rule = _derivations.at(assumption).rule;
+            if (default_priority(rule) != lowest || _defeats.count(assumption) == 1) continue;
+
+            Defeat defeat;
+            defeat.assumption.fact      = assumption;
+            defeat.assumption.subject   = parse_fact(assumption, defeat.assumption.objects);
+            defeat.assumption.predicate = parse_relation(assumption);
+            defeat.assumption.deduced   = true;
+            defeat.rule                 = rule;
+            defeat.by                   = error.get_parent();
+            std::copy_if(premises.begin(), premises.end(), std::back_inserter(defeat.premises), [assumption](Node premise)
+                         { return premise != assumption; });
+            _defeats.emplace(assumption, std::move(defeat));
+            _pending_defeats.push_back(assumption);
+            defeated.push_back(assumption);
+        }
+    }
+
+    if (_print_deductions)
+    {
+        std::lock_guard<std::mutex> lock(_mtx_output);
+        for (Node assumption : defeated)
+        {
+            std::string output;
+            string::node_to_string(this, output, _lang, assumption, 3);
+            out(string::unmark_identifiers("Defeated: " + output), true);
+        }
+    }
+    return true;
+}
+
+// Called by run() after its stages: withdraws the assumptions defeated
+// since, with the deductions that depended on them, and marks the rules
+// that may re-derive some of these for the next stages. Returns false if
+// there was nothing to withdraw.
+bool Reasoning::withdraw_defeated()
+{
+    std::vector<Node> roots;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        for (Node fact : _pending_defeats)
+            if (_deduced_facts.count(fact) == 1) roots.push_back(fact);
+        _pending_defeats.clear();
+    }
+    if (roots.empty()) return false;
+
+    std::vector<RetractedFact> parts;
+    withdraw_dependents(roots, 0, parts, _rederive_rules);
+    return true;
+}
+
+bool Reasoning::is_defeated(const Node rule, const Node fact) const
+{
+    return !_defeats.empty() && is_default_rule(rule) && _defeats.count(fact) == 1;
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include <algorithm>
+
+using namespace zelph::network;
+
+std::unordered_map<Node, Node> Reasoning::merge_concepts(const Node from, const Node into)
+{
+    const std::unordered_map<Node, Node> restated = Zelph::merge_concepts(from, into);
+    invalidate_incremental();
+
+    auto replaced = [&](Node n)
+    {
+        if (n == from) return into;
+        const auto it = restated.find(n);
+        return it == restated.end() ? n : it->second;
+    };
+
+    // Moves the entry of each restated node to its replacement, unless the
+    // replacement has one already.
+    auto move_entries = [&](auto& container)
+    {
+        for (const auto& [old, replacement] : restated)
+        {
+            auto entry = container.extract(old);
+            if (!entry || !replacement) continue;
+            if constexpr (requires { entry.key(); })
+                entry.key() = replacement;
+            else
+                entry.value() = replacement;
+            container.insert(std::move(entry));
+        }
+    };
+
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        move_entries(_deduced_facts);
+        move_entries(_derivations);
+        move_entries(_supports);
+        move_entries(_validity);
+        move_entries(_contexts);
+        move_entries(_term_depths);
+
+        for (auto& [fact, derivation] : _derivations)
+        {
+            derivation.rule      = replaced(derivation.rule);
+            derivation.condition = replaced(derivation.condition);
+            for (auto& [variable, value] : derivation.bindings)
+                value = replaced(value);
+        }
+        for (auto& [fact, supports] : _supports)
+            for (Support& support : supports)
+            {
+                support.rule = replaced(support.rule);
+                std::transform(support.premises.begin(), support.premises.end(), support.premises.begin(), replaced);
+                std::sort(support.premises.begin(), support.premises.end());
+            }
+    }
+    {
+        std::lock_guard<std::mutex> lock(_mtx_provenance);
+        move_entries(_provenance);
+    }
+
+    move_entries(_disabled_rules);
+    move_entries(_rule_combinations);
+    move_entries(_rule_weights);
+    move_entries(_rule_contexts);
+    move_entries(_rule_min_trust);
+    move_entries(_rule_strata);
+    move_entries(_default_rules);
+    for (PropertyRule& p : _property_rules)
+    {
+        p.rule                 = replaced(p.rule);
+        p.condition            = replaced(p.condition);
+        p.declaration.relation = replaced(p.declaration.relation);
+        p.declaration.inverse  = p.declaration.inverse ? replaced(p.declaration.inverse) : 0;
+    }
+
+    // Whatever was dropped, e.g. a fact that could not be restated.
+    forget_removed_nodes();
+    return restated;
+}
@@ -42,6 +42,8 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
     }
 
     if (!rule.conditions || rule.index >= rule.conditions->size()) return;
+    check_query_bounds(false);
+    if (stop_requested()) return; // cancellation: unwind without further matches
 
     Node condition = (*rule.conditions)[rule.index]; // Current condition from the sorted vector
 
@@ -155,6 +157,17 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
                 return;
             }
 
+            // --- Builtin Condition ---
+            if (guard_rels.size() == 1 && is_builtin(*guard_rels.begin()))
+            {
+                // Registered builtins take precedence over native ones.
+                if (_native.count(*guard_rels.begin()) == 1 && _builtins.count(*guard_rels.begin()) == 0)
+                    evaluate_native(condition, *guard_rels.begin(), rule, ctx, depth);
+                else
+                    evaluate_builtin(condition, *guard_rels.begin(), rule, ctx, depth);
+                return;
+            }
+
             if (guard_rels.size() == 1 && *guard_rels.begin() == core.Unequal)
             {
                 if (should_log(depth))
@@ -245,16 +258,7 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
                             }
                             catch (const contradiction_error& error)
                             {
-                                std::lock_guard<std::mutex> lock(_mtx_output);
-                                _contradiction = true;
-                                ++_total_contradictions;
-
-                                std::string output;
-                                string::node_to_string(this, output, _lang, error.get_fact(), 3, error.get_variables(), error.get_parent());
-                                std::string message = "«" + get_formatted_name(core.Contradiction, _lang) + "» ⇐ " + output;
-
-                                if (_print_deductions) out(string::unmark_identifiers(message), true);
-                                if (_generate_markdown) _markdown->add("Contradictions", message);
+                                report_contradiction(error);
                             }
                         }
                         else if (_prune_mode)
@@ -291,17 +295,7 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
                         }
                         else
                         {
-                            std::lock_guard<std::mutex> lock(_mtx_output);
-                            if (_query_results)
-                            {
-                                _query_results->push_back(vars);
-                            }
-                            else
-                            {
-                                std::string output;
-                                string::node_to_string(this, output, _lang, ctx_copy.current_condition, 3, *vars, rule.node);
-                                out("Answer: " + string::unmark_identifiers(output), true);
-                            }
+                            report_answer(ctx_copy.current_condition, rule.node, vars, rule.confidence);
                         }
                     }
                 };
@@ -370,16 +364,7 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
                         }
                         catch (const contradiction_error& error)
                         {
-                            std::lock_guard<std::mutex> lock(_mtx_output);
-                            _contradiction = true;
-                            ++_total_contradictions;
-
-                            std::string output;
-                            string::node_to_string(this, output, _lang, error.get_fact(), 3, error.get_variables(), error.get_parent());
-                            std::string message = "«" + get_formatted_name(core.Contradiction, _lang) + "» ⇐ " + output;
-
-                            if (_print_deductions) out(string::unmark_identifiers(message), true);
-                            if (_generate_markdown) _markdown->add("Contradictions", message);
+                            report_contradiction(error);
                         }
                     }
                     else if (_prune_mode)
@@ -417,17 +402,7 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
                     else
                     {
                         // Normal query output / collection
-                        std::lock_guard<std::mutex> lock(_mtx_output);
-                        if (_query_results)
-                        {
-                            _query_results->push_back(bindings);
-                        }
-                        else
-                        {
-                            std::string output;
-                            string::node_to_string(this, output, _lang, ctx_copy.current_condition, 3, *bindings, rule.node);
-                            out("Answer: " + string::unmark_identifiers(output), true);
-                        }
+                        report_answer(ctx_copy.current_condition, rule.node, bindings, rule.confidence);
                     }
                 }
             };
@@ -669,22 +644,7 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
                     }
                     catch (const contradiction_error& error)
                     {
-                        std::lock_guard<std::mutex> lock(_mtx_output);
-                        _contradiction = true;
-                        ++_total_contradictions;
-
-                        std::string output;
-                        string::node_to_string(this, output, _lang, error.get_fact(), 3, error.get_variables(), error.get_parent());
-                        std::string message = "«" + get_formatted_name(core.Contradiction, _lang) + "» ⇐ " + output;
-
-                        if (_print_deductions)
-                        {
-                            out(string::unmark_identifiers(message), true);
-                        }
-                        if (_generate_markdown)
-                        {
-                            _markdown->add("Contradictions", message);
-                        }
+                        report_contradiction(error);
                     }
                 }
                 else if (_prune_mode)
@@ -721,17 +681,7 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
                 else
                 {
                     // normal query output / collection
-                    std::lock_guard<std::mutex> lock(_mtx_output);
-                    if (_query_results)
-                    {
-                        _query_results->push_back(joined);
-                    }
-                    else
-                    {
-                        std::string output;
-                        string::node_to_string(this, output, _lang, ctx_copy.current_condition, 3, *joined, rule.node);
-                        out("Answer: " + string::unmark_identifiers(output), true);
-                    }
+                    report_answer(ctx_copy.current_condition, rule.node, joined, rule.confidence);
                 }
             }
         };
@@ -750,6 +700,8 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
             int local_matches = 0;
             while (std::shared_ptr<Variables> match = u->Next())
             {
+                check_query_bounds(true);
+                if (stop_requested()) break; // e.g. paused after a firing (see set_break_condition)
                 ++local_matches;
                 process_match(match);
             }
@@ -774,6 +726,8 @@ void Reasoning::evaluate(RulePos rule, ReasoningContext& ctx, int depth)
             int local_serial_matches = 0;
             while (std::shared_ptr<Variables> match = u->Next())
             {
+                check_query_bounds(true);
+                if (stop_requested()) break;
                 ++_total_matches;
                 ++local_serial_matches;
                 process_match(match);
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "fact_structure.hpp"
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <vector>
+
+using namespace zelph::network;
+
+Reasoning::Proof Reasoning::explain(const Node fact) const
+{
+    std::vector<Node> path;
+    return explain(fact, path);
+}
+
+Reasoning::Proof Reasoning::explain(const Node fact, std::vector<Node>& path) const
+{
+    Proof proof;
+    proof.fact = fact;
+
+    auto it = _derivations.find(fact);
+    if (it == _derivations.end()) return proof; // stated or imported
+
+    const Derivation& derivation = it->second;
+    proof.rule                   = derivation.rule;
+
+    // A premise existed before the fact derived from it, so the tree is
+    // finite. The path check only guards against truncated histories
+    // (e.g. a premise retracted and re-derived later).
+    path.push_back(fact);
+    std::vector<Node> premises;
+    collect_premises(derivation.condition, derivation.bindings, premises);
+    for (Node premise : premises)
+    {
+        if (std::find(path.begin(), path.end(), premise) != path.end()) continue;
+        proof.premises.push_back(explain(premise, path));
+    }
+    path.pop_back();
+
+    return proof;
+}
+
+std::vector<Node> Reasoning::answer_premises(const Node condition, const Variables& bindings) const
+{
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+    return premises;
+}
+
+// Resolves the leaf conditions of a rule (the condition itself, or the
+// elements of a conjunction set) to the facts they matched. Negated
+// conditions matched the absence of a fact and contribute nothing.
+void Reasoning::collect_premises(const Node condition, const Variables& bindings, std::vector<Node>& premises) const
+{
+    if (!condition || check_fact(condition, core.IsA, {core.Negation}).is_known()) return;
+
+    if (check_fact(condition, core.IsA, {core.Conjunction}).is_known())
+    {
+        for (Node rel : _pImpl->get_right(condition))
+        {
+            if (parse_relation(rel) != core.PartOf) continue;
+            adjacency_set objs;
+            Node          element = parse_fact(rel, objs);
+            if (element && objs.count(condition) == 1)
+                collect_premises(element, bindings, premises);
+        }
+        return;
+    }
+
+    std::vector<Node> history;
+    const Node        premise = find_instance(condition, bindings, history);
+    if (premise && is_hash(premise) && exists(premise))
+        premises.push_back(premise);
+}
+
+// Like instantiate_fact, but never creates a fact: returns 0 if the bound
+// pattern does not exist in the network.
+Node Reasoning::find_instance(const Node pattern, const Variables& bindings, std::vector<Node>& history) const
+{
+    if (is_var(pattern))
+    {
+        auto it = bindings.find(pattern);
+        return it == bindings.end() ? 0 : it->second;
+    }
+
+    if (std::find(history.begin(), history.end(), pattern) != history.end()) return pattern;
+
+    FactStructure fs = get_preferred_structure(this, pattern, 0);
+    if (fs.subject == 0) return pattern;
+
+    history.push_back(pattern);
+    const Node    subject   = find_instance(fs.subject, bindings, history);
+    const Node    predicate = find_instance(fs.predicate, bindings, history);
+    adjacency_set objects;
+    for (Node o : fs.objects)
+        objects.insert(find_instance(o, bindings, history));
+    history.pop_back();
+
+    if (!subject || !predicate || objects.count(0) == 1) return 0;
+
+    Answer answer = check_fact(subject, predicate, objects);
+    return answer.is_known() ? answer.relation() : 0;
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <stdexcept>
+#include <string>
+
+namespace zelph::network
+{
+    // Thrown by Reasoning::run when the run exceeded one of its resource
+    // limits (see Reasoning::set_max_facts and set_max_memory). Like a
+    // cancelled run, it keeps the facts derived before the stop.
+    class reasoning_limit_exceeded final : public std::runtime_error
+    {
+    public:
+        explicit reasoning_limit_exceeded(const std::string& reason)
+            : std::runtime_error("Resource limit exceeded: " + reason)
+        {
+        }
+    };
+}
@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "platform/platform_utils.hpp"
+#include "string/node_to_string.hpp"
+
+#include <algorithm>
+#include <mutex>
+#include <string>
+
+using namespace zelph::network;
+
+void Reasoning::set_max_facts(const uint64_t count)
+{
+    _max_facts = count;
+}
+
+void Reasoning::set_max_memory(const size_t bytes)
+{
+    _max_memory = bytes;
+}
+
+void Reasoning::set_max_iterations(const int count)
+{
+    _max_iterations = count < 0 ? 0 : count;
+}
+
+void Reasoning::set_max_deductions(const uint64_t count)
+{
+    _max_deductions = count;
+}
+
+void Reasoning::set_max_term_depth(const uint32_t depth)
+{
+    _max_term_depth = depth;
+}
+
+void Reasoning::set_query_timeout(const std::chrono::milliseconds timeout)
+{
+    _query_timeout = timeout.count() < 0 ? std::chrono::milliseconds{0} : timeout;
+}
+
+void Reasoning::set_max_query_matches(const uint64_t count)
+{
+    _max_query_matches = count;
+}
+
+std::string Reasoning::query_truncation() const
+{
+    std::lock_guard<std::mutex> lock(_mtx_limit);
+    return _query_truncation;
+}
+
+void Reasoning::apply_query(const Node condition)
+{
+    {
+        std::lock_guard<std::mutex> lock(_mtx_limit);
+        _query_truncation.clear();
+    }
+    _query_truncated = false;
+    _query_matches   = 0;
+    _query_deadline  = std::chrono::steady_clock::now() + _query_timeout;
+    _query_bounded   = _query_timeout.count() != 0 || _max_query_matches != 0;
+
+    struct BoundsGuard
+    {
+        Reasoning* r;
+        ~BoundsGuard()
+        {
+            r->_query_bounded = false;
+            if (r->_query_truncated) r->_cancel_requested = false;
+        }
+    } guard{this};
+
+    // In deterministic mode, answers are collected and sorted before they
+    // are printed or handed to the caller's collector.
+    const bool                              sorted = deterministic() && !_answer_handler;
+    std::vector<std::shared_ptr<Variables>> printed;
+    const bool                              print = sorted && !_query_results;
+    if (print) _query_results = &printed;
+    try
+    {
+        apply_rule(0, condition);
+    }
+    catch (...)
+    {
+        if (print) _query_results = nullptr;
+        throw;
+    }
+    if (print) _query_results = nullptr;
+
+    if (sorted)
+    {
+        auto& answers = print ? printed : *_query_results;
+        std::stable_sort(answers.begin(), answers.end(), [](const auto& a, const auto& b)
+                         { return *a < *b; });
+        if (print)
+            for (const auto& answer : answers)
+                print_answer(condition, 0, *answer);
+    }
+
+    if (_query_truncated && !_query_results && !_answer_handler)
+        out("Query stopped: " + query_truncation() + ". The answers are incomplete.", true);
+}
+
+// Called for every fact a run deduces. Memory is sampled every 1024
+// deductions only, as reading the process statistics is comparatively slow.
+void Reasoning::check_limits()
+{
+    const uint64_t deduced = ++_run_deductions;
+
+    if (_max_facts != 0 && deduced > _max_facts)
+    {
+        stop_for_limit("the run deduced more than " + std::to_string(_max_facts) + " facts (see .max-facts)");
+    }
+    else if (_max_memory != 0 && deduced % 1024 == 0)
+    {
+        const size_t used = platform::get_process_memory_usage();
+        if (used > _max_memory)
+            stop_for_limit("the process uses " + std::to_string(used) + " bytes, more than the limit of "
+                           + std::to_string(_max_memory) + " (see .max-memory)");
+    }
+
+    if (_max_deductions != 0 && deduced == _max_deductions)
+        pause_run("the run deduced " + std::to_string(_max_deductions) + " facts (see .max-deductions)");
+}
+
+// Called for every condition (match false) and every match a query with
+// bounds processes; stops the query once it has used up one of them.
+void Reasoning::check_query_bounds(const bool match)
+{
+    if (!_query_bounded || _query_truncated) return;
+
+    std::string reason;
+    if (match && _max_query_matches != 0 && ++_query_matches > _max_query_matches)
+        reason = "the query processed more than " + std::to_string(_max_query_matches) + " matches (see .query-limits)";
+    else if (_query_timeout.count() != 0 && std::chrono::steady_clock::now() > _query_deadline)
+        reason = "the query took longer than " + std::to_string(_query_timeout.count()) + " ms (see .query-limits)";
+    if (reason.empty()) return;
+
+    {
+        std::lock_guard<std::mutex> lock(_mtx_limit);
+        _query_truncation = reason;
+    }
+    _query_truncated = true;
+    request_cancel();
+}
+
+// Called for the firings of rules that build terms while .max-term-depth is
+// set: depth becomes one more than the deepest term among the bindings. If
+// that exceeds the limit, the run is stopped and false returned.
+bool Reasoning::check_term_depth(const Node rule, const Variables& bindings, uint32_t& depth)
+{
+    depth = 0;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        for (const auto& binding : bindings)
+        {
+            auto it = _term_depths.find(binding.second);
+            if (it != _term_depths.end()) depth = std::max(depth, it->second);
+        }
+    }
+    if (++depth <= _max_term_depth) return true;
+
+    std::string formatted;
+    string::node_to_string(this, formatted, _lang, rule, 3);
+    stop_for_limit(string::unmark_identifiers(
+        "rule " + formatted + " would build a term " + std::to_string(depth) + " firings deep, more than the limit of "
+        + std::to_string(_max_term_depth) + " (see .max-term-depth); its consequences may match its conditions without bound"));
+    return false;
+}
+
+// Called before each further fixpoint iteration; pauses the run once it has
+// done as many iterations as .max-iterations allows.
+bool Reasoning::iterations_exhausted(const int iterations_done)
+{
+    if (_max_iterations == 0 || iterations_done < _max_iterations) return false;
+
+    pause_run("the run did " + std::to_string(_max_iterations) + " iteration(s) (see .max-iterations)");
+    return true;
+}
+
+// Stops the run the way request_cancel does; run() then throws
+// reasoning_limit_exceeded with the first reason instead of
+// reasoning_cancelled.
+void Reasoning::stop_for_limit(const std::string& reason)
+{
+    {
+        std::lock_guard<std::mutex> lock(_mtx_limit);
+        if (_limit_reason.empty()) _limit_reason = reason;
+    }
+    request_cancel();
+}
+
+// Stops the run like stop_for_limit, but run() then returns normally and
+// reports the run as paused.
+void Reasoning::pause_run(const std::string& reason)
+{
+    {
+        std::lock_guard<std::mutex> lock(_mtx_limit);
+        if (_pause_reason.empty()) _pause_reason = reason;
+    }
+    request_cancel();
+}
+
+bool Reasoning::pause_requested()
+{
+    std::lock_guard<std::mutex> lock(_mtx_limit);
+    return !_pause_reason.empty();
+}
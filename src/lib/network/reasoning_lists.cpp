@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "zelph_impl.hpp"
+
+#include <string>
+#include <unordered_set>
+
+using namespace zelph::network;
+
+// The elements of a cons list (see Zelph::list), or nothing if the node is
+// not a list: neither nil nor a cons cell whose rest is a list.
+std::optional<std::vector<Node>> Reasoning::list_elements(Node list) const
+{
+    std::vector<Node>        elements;
+    std::unordered_set<Node> visited;
+    while (list != core.Nil)
+    {
+        if (list == 0 || Zelph::Impl::is_var(list) || !exists(list) || parse_relation(list) != core.Cons || !visited.insert(list).second)
+            return std::nullopt;
+
+        adjacency_set rest;
+        const Node    element = parse_fact(list, rest, 0);
+        if (element == 0 || rest.size() != 1) return std::nullopt;
+        elements.push_back(element);
+        list = *rest.begin();
+    }
+    return elements;
+}
+
+void Reasoning::set_list_builtins(const bool enabled)
+{
+    _list_builtins = enabled;
+    if (!enabled)
+    {
+        for (const char* rel : {"element_of", "head", "tail", "length"})
+            register_builtin(node(rel), {});
+        return;
+    }
+
+    // E element_of L: one solution per element if E is unbound.
+    register_builtin(node("element_of"), [this](const std::vector<Node>& args)
+                     {
+        std::vector<Variables> solutions;
+        const auto             elements = args.size() == 2 ? list_elements(args[1]) : std::nullopt;
+        if (!elements) return solutions;
+
+        std::unordered_set<Node> seen;
+        for (const Node element : *elements)
+        {
+            if (!seen.insert(element).second) continue;
+            if (Zelph::Impl::is_var(args[0]))
+                solutions.push_back({{args[0], element}});
+            else if (element == args[0])
+                return std::vector<Variables>{{}};
+        }
+        return solutions; });
+
+    // L head H, L tail T: the first element and the rest of a non-empty list.
+    for (const bool head : {true, false})
+    {
+        register_builtin(node(head ? "head" : "tail"), [this, head](const std::vector<Node>& args)
+                         {
+            std::vector<Variables> solutions;
+            if (args.size() != 2 || args[0] == core.Nil || !list_elements(args[0])) return solutions;
+
+            adjacency_set rest;
+            const Node    element = parse_fact(args[0], rest, 0);
+            const Node    value   = head ? element : *rest.begin();
+            if (Zelph::Impl::is_var(args[1]))
+                solutions.push_back({{args[1], value}});
+            else if (value == args[1])
+                solutions.emplace_back();
+            return solutions; });
+    }
+
+    // L length N: N is the node named by the number of elements.
+    register_builtin(node("length"), [this](const std::vector<Node>& args)
+                     {
+        std::vector<Variables> solutions;
+        const auto             elements = args.size() == 2 ? list_elements(args[0]) : std::nullopt;
+        if (!elements) return solutions;
+
+        const std::string count = std::to_string(elements->size());
+        if (Zelph::Impl::is_var(args[1]))
+            solutions.push_back({{args[1], node(count)}});
+        else if (get_name(args[1]) == count)
+            solutions.emplace_back();
+        return solutions; });
+}
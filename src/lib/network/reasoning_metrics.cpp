@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+
+using namespace zelph::network;
+
+void Histogram::observe(const double value)
+{
+    const auto bucket = std::lower_bound(bounds.begin(), bounds.end(), value) - bounds.begin();
+    ++counts[static_cast<size_t>(bucket)];
+    sum += value;
+    ++count;
+}
+
+void Reasoning::set_metrics_enabled(const bool enabled)
+{
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    if (enabled && !_metrics_enabled) _metrics = RunMetrics{};
+    _metrics_enabled = enabled;
+}
+
+RunMetrics Reasoning::metrics() const
+{
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    return _metrics;
+}
+
+void Reasoning::record_run(const double seconds)
+{
+    if (!_metrics_enabled) return;
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    ++_metrics.runs;
+    _metrics.run_seconds.observe(seconds);
+}
+
+void Reasoning::record_query(const double seconds)
+{
+    if (!_metrics_enabled) return;
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    _metrics.query_seconds.observe(seconds);
+}
+
+void Reasoning::record_deduction(const Node rule)
+{
+    if (!_metrics_enabled && !_rule_profiling) return;
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    if (_rule_profiling)
+    {
+        RuleProfile& profile = _rule_profile[rule];
+        profile.rule         = rule;
+        ++profile.deductions;
+    }
+    if (!_metrics_enabled) return;
+    ++_metrics.deductions;
+    ++_metrics.rule_firings[rule];
+}
+
+void Reasoning::record_contradiction()
+{
+    if (!_metrics_enabled) return;
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    ++_metrics.contradictions;
+}
+
+std::vector<RuleProfile> Reasoning::rule_profile() const
+{
+    std::vector<RuleProfile> result;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_metrics);
+        for (const auto& [rule, profile] : _rule_profile)
+            if (exists(rule)) result.push_back(profile);
+    }
+    std::sort(result.begin(), result.end(), [](const RuleProfile& a, const RuleProfile& b)
+              { return a.seconds != b.seconds ? a.seconds > b.seconds : a.rule < b.rule; });
+    return result;
+}
+
+// Called when a run starts: a profiled run replaces the records of the last
+// one, starting with an empty record for each rule it will run.
+void Reasoning::start_rule_profile()
+{
+    if (!_rule_profiling) return;
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    _rule_profile.clear();
+    for (Node rule : _pImpl->get_left(core.Causes))
+        if (is_rule_enabled(rule)) _rule_profile[rule].rule = rule;
+}
+
+void Reasoning::record_firing(const Node rule)
+{
+    if (!_rule_profiling || rule == 0) return;
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    RuleProfile&                profile = _rule_profile[rule];
+    profile.rule                        = rule;
+    ++profile.firings;
+}
+
+void Reasoning::record_rule_time(const Node rule, const double seconds)
+{
+    if (!_rule_profiling || rule == 0) return;
+    std::lock_guard<std::mutex> lock(_mtx_metrics);
+    RuleProfile&                profile = _rule_profile[rule];
+    profile.rule                        = rule;
+    profile.seconds += seconds;
+}
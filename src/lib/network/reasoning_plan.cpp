@@ -0,0 +1,390 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "fact_structure.hpp"
+#include "string/node_to_string.hpp"
+#include "string/string_utils.hpp"
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <functional>
+#include <stdexcept>
+#include <unordered_set>
+#include <vector>
+
+using namespace zelph::network;
+
+namespace
+{
+    // Facts of a relation that plan estimates are sampled from.
+    constexpr size_t plan_sample = 10000;
+}
+
+std::vector<Reasoning::PlanStep> Reasoning::query_plan(const Node condition_or_rule)
+{
+    if (!condition_or_rule || !exists(condition_or_rule))
+        throw std::runtime_error("Unknown condition or rule " + std::to_string(condition_or_rule));
+
+    Node condition = condition_or_rule;
+    if (get_rules().count(condition_or_rule) == 1)
+    {
+        adjacency_set deductions;
+        condition = parse_fact(condition_or_rule, deductions);
+    }
+
+    std::vector<Node> positive;
+    std::vector<Node> negated;
+    collect_conditions(condition, false, positive, negated);
+
+    adjacency_set leaves;
+    for (Node leaf : positive)
+        leaves.insert(leaf);
+    std::vector<Node> order = *optimize_order(leaves, Variables{}, 0);
+    order.insert(order.end(), negated.begin(), negated.end());
+
+    // Average number of facts per distinct subject (or object) among the
+    // first facts of the relation.
+    auto fan_out = [this](const Node relation, const bool by_subject) -> uint64_t
+    {
+        adjacency_set facts;
+        if (!_pImpl->snapshot_left_of(relation, facts)) return 0;
+
+        std::unordered_set<Node> distinct;
+        size_t                   sampled = 0;
+        for (Node fact : facts)
+        {
+            if (sampled == plan_sample) break;
+            if (has_left_edge(fact, relation)) continue;
+
+            const FactStructure structure = get_preferred_structure(this, fact, 0);
+            if (by_subject)
+                distinct.insert(structure.subject);
+            else
+                distinct.insert(structure.objects.begin(), structure.objects.end());
+            ++sampled;
+        }
+        return distinct.empty() ? 0 : (sampled + distinct.size() - 1) / distinct.size();
+    };
+
+    const uint8_t            indexes = triple_indexes();
+    std::unordered_set<Node> bound;
+    std::vector<Node>        history;
+    std::vector<PlanStep>    plan;
+
+    for (Node leaf : order)
+    {
+        PlanStep step;
+        step.condition = leaf;
+        step.negated   = std::find(negated.begin(), negated.end(), leaf) != negated.end();
+
+        const FactStructure structure = get_preferred_structure(this, leaf, 0);
+        step.relation                 = is_var(structure.predicate) ? 0 : structure.predicate;
+
+        std::unordered_set<Node> vars;
+        pattern_variables(leaf, vars, history);
+
+        if (step.relation == core.Unequal || is_builtin(step.relation))
+        {
+            step.access = "none";
+        }
+        else
+        {
+            adjacency_set relations;
+            if (step.relation)
+                relations.insert(step.relation);
+            else
+                relations = get_sources(core.IsA, core.RelationTypeCategory, true);
+
+            uint64_t extent = 0;
+            for (Node relation : relations)
+                extent += _pImpl->left_count_of(relation);
+
+            step.access     = "pos";
+            step.candidates = extent;
+
+            // The candidates of a lookup from part, or false if part is
+            // unbound at this step.
+            auto anchored = [&](const Node part, const bool by_subject, uint64_t& count, bool& per_binding)
+            {
+                if (part == 0) return false;
+
+                std::unordered_set<Node> part_vars;
+                pattern_variables(part, part_vars, history);
+                if (part_vars.empty())
+                {
+                    count       = exists(part) ? _pImpl->right_count_of(part) * relations.size() : 0;
+                    per_binding = false;
+                    return true;
+                }
+                if (!step.relation) return false;
+                if (!std::all_of(part_vars.begin(), part_vars.end(), [&](Node var)
+                                 { return bound.count(var) == 1; }))
+                    return false;
+
+                count       = fan_out(step.relation, by_subject);
+                per_binding = true;
+                return true;
+            };
+
+            // Same choice as Unification::increment_fact_index: the fewest
+            // candidates, ties going to the subject, then to the object,
+            // then to the relation.
+            uint64_t count       = 0;
+            bool     per_binding = false;
+            if ((indexes & Zelph::SPO) && anchored(structure.subject, true, count, per_binding) && count <= step.candidates)
+            {
+                step.access      = "spo";
+                step.candidates  = count;
+                step.per_binding = per_binding;
+            }
+            const Node object = structure.objects.empty() ? 0 : *structure.objects.begin();
+            if ((indexes & Zelph::OSP) && anchored(object, false, count, per_binding)
+                && (step.access == "pos" ? count <= step.candidates : count < step.candidates))
+            {
+                step.access      = "osp";
+                step.candidates  = count;
+                step.per_binding = per_binding;
+            }
+        }
+
+        if (!step.negated)
+        {
+            for (Node var : vars)
+                if (bound.insert(var).second) step.binds.push_back(var);
+            std::sort(step.binds.begin(), step.binds.end());
+        }
+
+        plan.push_back(std::move(step));
+    }
+
+    return plan;
+}
+
+// Whether every fact the node is the subject (bidirectional), the relation
+// (incoming) or an object (outgoing) of has variables.
+bool Reasoning::only_in_patterns(const Node node) const
+{
+    adjacency_set facts = get_left(node);
+    for (Node fact : get_right(node))
+        facts.insert(fact);
+
+    std::vector<Node> history;
+    for (Node fact : facts)
+    {
+        if (get_preferred_structure(this, fact, 0).subject == 0) continue;
+        std::unordered_set<Node> vars;
+        pattern_variables(fact, vars, history);
+        if (vars.empty()) return false;
+    }
+    return true;
+}
+
+std::vector<Node> Reasoning::unknown_concepts(const Node pattern) const
+{
+    std::vector<Node> positive;
+    std::vector<Node> negated;
+    collect_conditions(pattern, false, positive, negated);
+    positive.insert(positive.end(), negated.begin(), negated.end());
+
+    std::vector<Node>        result;
+    std::unordered_set<Node> seen;
+
+    std::function<void(Node)> visit = [&](const Node part)
+    {
+        if (part == 0 || is_var(part) || !seen.insert(part).second) return;
+
+        const FactStructure structure = get_preferred_structure(this, part, 0);
+        if (structure.subject != 0)
+        {
+            visit(structure.subject);
+            visit(structure.predicate);
+            for (Node object : structure.objects)
+                visit(object);
+            return;
+        }
+
+        if (get_core_name(part).empty() && !get_name(part, _lang, true).empty() && only_in_patterns(part))
+            result.push_back(part);
+    };
+
+    for (Node leaf : positive)
+        visit(leaf);
+
+    return result;
+}
+
+std::string Reasoning::unknown_concept_hint(const Node pattern) const
+{
+    const std::vector<Node> unknown = unknown_concepts(pattern);
+    if (unknown.empty()) return "";
+
+    const std::string name = get_name(unknown.front(), _lang, true);
+
+    std::vector<std::string> suggestions;
+    for (const auto& match : search_names(name, 16, true))
+    {
+        if (suggestions.size() < 3 && match.node != unknown.front() && !only_in_patterns(match.node))
+            suggestions.push_back(match.name);
+    }
+
+    std::string hint = "Unknown concept '" + name + "'";
+    for (size_t i = 0; i < suggestions.size(); ++i)
+    {
+        hint += i == 0 ? " – did you mean " : (i + 1 == suggestions.size() ? " or " : ", ");
+        hint += suggestions[i];
+    }
+    return suggestions.empty() ? hint : hint + "?";
+}
+
+// A part of a statement as rendered, variables marked as such.
+std::string Reasoning::describe_part(const Node part) const
+{
+    std::string text;
+    string::node_to_string(this, text, _lang, part, 3);
+    return (is_var(part) ? "variable " : "") + string::unmark_identifiers(text);
+}
+
+// The subject, relation and objects of a single condition, or "" if the
+// node is not a fact.
+std::string Reasoning::describe_condition(const Node condition) const
+{
+    const FactStructure structure = get_preferred_structure(this, condition, 0);
+    if (structure.subject == 0) return "";
+
+    std::string text = "subject: " + describe_part(structure.subject) + ", relation: " + describe_part(structure.predicate);
+    for (Node object : structure.objects)
+        text += ", object: " + describe_part(object);
+    return text;
+}
+
+std::string Reasoning::no_match_notice(const Node pattern) const
+{
+    std::vector<Node> positive;
+    std::vector<Node> negated;
+    collect_conditions(pattern, false, positive, negated);
+
+    std::vector<std::string> conditions;
+    for (Node condition : positive)
+        if (auto text = describe_condition(condition); !text.empty()) conditions.push_back(text);
+    for (Node condition : negated)
+        if (auto text = describe_condition(condition); !text.empty()) conditions.push_back("negated, " + text);
+
+    std::string notice = "No matches for pattern " + describe_part(pattern);
+    for (size_t i = 0; i < conditions.size(); ++i)
+        notice += (i == 0 ? " (" : "; ") + conditions[i];
+    return conditions.empty() ? notice : notice + ")";
+}
+
+std::string Reasoning::describe_statement(const Node statement, const bool query) const
+{
+    std::vector<std::string> parts;
+    std::vector<Node>        concepts;
+    std::string              kind;
+
+    auto add_conditions = [&](const Node condition, const std::string& label)
+    {
+        std::vector<Node> positive;
+        std::vector<Node> negated;
+        collect_conditions(condition, false, positive, negated);
+        for (Node leaf : positive)
+            if (auto text = describe_condition(leaf); !text.empty()) parts.push_back(label + text);
+        for (Node leaf : negated)
+            if (auto text = describe_condition(leaf); !text.empty()) parts.push_back("negated " + label + text);
+    };
+
+    if (parse_relation(statement) == core.Causes)
+    {
+        kind = "rule";
+        adjacency_set consequences;
+        add_conditions(parse_fact(statement, consequences), "condition ");
+        for (Node consequence : consequences)
+        {
+            if (consequence == core.Contradiction)
+                parts.push_back("consequence: contradiction");
+            else if (auto text = describe_condition(consequence); !text.empty())
+                parts.push_back("consequence " + text);
+        }
+    }
+    else if (query)
+    {
+        kind     = "query";
+        concepts = unknown_concepts(statement);
+        add_conditions(statement, "");
+    }
+    else
+    {
+        kind = "fact";
+        if (auto text = describe_condition(statement); !text.empty()) parts.push_back(text);
+
+        // The named parts that no other statement mentions.
+        std::unordered_set<Node>  facts;
+        std::unordered_set<Node>  seen;
+        std::vector<Node>         leaves;
+        std::function<void(Node)> visit = [&](const Node part)
+        {
+            if (part == 0 || is_var(part) || !seen.insert(part).second) return;
+
+            const FactStructure structure = get_preferred_structure(this, part, 0);
+            if (structure.subject != 0)
+            {
+                facts.insert(part);
+                visit(structure.subject);
+                visit(structure.predicate);
+                for (Node object : structure.objects)
+                    visit(object);
+            }
+            else if (get_core_name(part).empty() && !get_name(part, _lang, true).empty())
+            {
+                leaves.push_back(part);
+            }
+        };
+        visit(statement);
+
+        for (Node leaf : leaves)
+        {
+            adjacency_set mentions = get_left(leaf);
+            for (Node fact : get_right(leaf))
+                mentions.insert(fact);
+            if (std::all_of(mentions.begin(), mentions.end(), [&](const Node fact)
+                            { return facts.count(fact) == 1 || get_preferred_structure(this, fact, 0).subject == 0; }))
+                concepts.push_back(leaf);
+        }
+    }
+
+    std::string text = "Parsed as " + kind + " " + describe_part(statement);
+    for (size_t i = 0; i < parts.size(); ++i)
+        text += (i == 0 ? " (" : "; ") + parts[i];
+    if (!parts.empty()) text += ")";
+
+    if (!concepts.empty())
+    {
+        text += query ? "; unknown concepts: " : "; new concepts: ";
+        for (size_t i = 0; i < concepts.size(); ++i)
+            text += (i == 0 ? "" : ", ") + get_name(concepts[i], _lang, true);
+    }
+    return text;
+}
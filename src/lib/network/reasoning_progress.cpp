@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+using namespace zelph::network;
+
+void Reasoning::set_progress_observer(ProgressObserver observer, const std::chrono::milliseconds interval)
+{
+    _on_progress       = std::move(observer);
+    _progress_interval = interval.count() > 0 ? interval : std::chrono::milliseconds(1);
+}
+
+RunProgress Reasoning::progress(const bool finished) const
+{
+    RunProgress p;
+    p.iterations = _run_iterations;
+    p.matches    = static_cast<uint64_t>(_total_matches.load());
+    p.deductions = _run_deductions;
+    p.seconds    = std::chrono::duration<double>(std::chrono::steady_clock::now() - _run_started).count();
+    p.finished   = finished;
+    return p;
+}
+
+// Starts the helper thread that reports the progress of a run while it is
+// in progress. The observer is called without any lock of the reasoning
+// threads held, so a slow observer delays only its own reports.
+void Reasoning::start_progress_reports()
+{
+    _run_started    = std::chrono::steady_clock::now();
+    _run_iterations = 0;
+    if (!_on_progress) return;
+
+    {
+        std::lock_guard<std::mutex> lock(_mtx_progress);
+        _progress_stop = false;
+    }
+    _progress_thread = std::thread([this]
+                                   {
+        std::unique_lock<std::mutex> lock(_mtx_progress);
+        while (!_cv_progress.wait_for(lock, _progress_interval, [this] { return _progress_stop; }))
+        {
+            lock.unlock();
+            _on_progress(progress(false));
+            lock.lock();
+        } });
+}
+
+void Reasoning::stop_progress_reports()
+{
+    if (!_progress_thread.joinable()) return;
+
+    {
+        std::lock_guard<std::mutex> lock(_mtx_progress);
+        _progress_stop = true;
+    }
+    _cv_progress.notify_all();
+    _progress_thread.join();
+}
@@ -0,0 +1,482 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "contradiction_error.hpp"
+#include "string/node_to_string.hpp"
+#include "zelph_impl.hpp"
+
+#include <deque>
+#include <unordered_map>
+
+using namespace zelph::network;
+
+namespace
+{
+    // Union-find over the nodes of a relation, with path halving. The
+    // members of each component are listed in the order they were seen.
+    class Components
+    {
+    public:
+        void unite(Node a, Node b)
+        {
+            a = find(a);
+            b = find(b);
+            if (a != b) _parent[b] = a;
+        }
+
+        std::vector<std::vector<Node>> list()
+        {
+            std::unordered_map<Node, size_t> index;
+            std::vector<std::vector<Node>>   result;
+            for (Node n : _order)
+            {
+                const auto [it, inserted] = index.try_emplace(find(n), result.size());
+                if (inserted) result.emplace_back();
+                result[it->second].push_back(n);
+            }
+            return result;
+        }
+
+    private:
+        Node find(Node n)
+        {
+            if (_parent.try_emplace(n, n).second) _order.push_back(n);
+            while (_parent[n] != n)
+            {
+                _parent[n] = _parent[_parent[n]];
+                n          = _parent[n];
+            }
+            return n;
+        }
+
+        std::unordered_map<Node, Node> _parent;
+        std::vector<Node>              _order;
+    };
+}
+
+std::vector<Node> Reasoning::declare_relation_property(const Node relation, const RelationProperty property, Node inverse)
+{
+    if (!relation || is_var(relation))
+        throw std::runtime_error("Node " + std::to_string(relation) + " is not a relation");
+    if (property != RelationProperty::Inverse)
+        inverse = 0;
+    else if (!inverse || is_var(inverse))
+        throw std::runtime_error("Node " + std::to_string(inverse) + " is not a relation");
+    else if (inverse == relation) // a relation that is its own inverse is symmetric
+        return declare_relation_property(relation, RelationProperty::Symmetric);
+
+    // R inverse of S is the same declaration as S inverse of R.
+    const RelationDeclaration declaration{relation, property, inverse};
+    const RelationDeclaration converse{inverse, property, relation};
+    std::vector<Node>         result;
+    for (const PropertyRule& p : _property_rules)
+        if (p.declaration == declaration || (inverse && p.declaration == converse))
+            result.push_back(p.rule);
+    if (!result.empty()) return result;
+
+    auto text = [this](Node rule)
+    {
+        std::string result;
+        string::node_to_string(this, result, _lang, rule, 3);
+        return result;
+    };
+    std::unordered_map<std::string, Node> known;
+    for (Node rule : get_rules())
+        known.emplace(text(rule), rule);
+
+    auto variable = [this](const char* name)
+    {
+        const Node v = var();
+        set_name(v, name, _lang, false);
+        return v;
+    };
+
+    // Adds the rule condition => consequence, with X R Y as its condition
+    // unless transitive, where it is X R Y, Y R Z.
+    auto add = [&](bool is_converse, Node from, Node to, bool swap)
+    {
+        PropertyRule p{0, declaration, is_converse, 0, variable("X"), variable("Y"), 0};
+        const Node   first = fact(p.x, from, {p.y});
+        Node         consequence;
+        switch (property)
+        {
+        case RelationProperty::Transitive:
+        {
+            p.z                 = variable("Z");
+            const Node second   = fact(p.y, from, {p.z});
+            p.condition         = set({first, second});
+            fact(p.condition, core.IsA, {core.Conjunction});
+            consequence = fact(p.x, to, {p.z});
+            break;
+        }
+        case RelationProperty::Reflexive:
+            p.condition = first;
+            consequence = is_converse ? fact(p.y, to, {p.y}) : fact(p.x, to, {p.x});
+            break;
+        default:
+            p.condition = first;
+            consequence = swap ? fact(p.y, to, {p.x}) : fact(p.x, to, {p.y});
+            break;
+        }
+        p.rule = fact(p.condition, core.Causes, {consequence});
+
+        // A rule of the same text gives way, so that the property is
+        // handled natively from now on.
+        const auto it = known.find(text(p.rule));
+        if (it != known.end() && it->second != p.rule) remove_rule(it->second);
+
+        _property_rules.push_back(p);
+        result.push_back(p.rule);
+    };
+
+    switch (property)
+    {
+    case RelationProperty::Transitive:
+        add(false, relation, relation, false);
+        break;
+    case RelationProperty::Symmetric:
+        add(false, relation, relation, true);
+        break;
+    case RelationProperty::Reflexive:
+        add(false, relation, relation, false);
+        add(true, relation, relation, false);
+        break;
+    case RelationProperty::Inverse:
+        add(false, relation, inverse, true);
+        add(true, inverse, relation, true);
+        break;
+    }
+    return result;
+}
+
+std::vector<Reasoning::RelationDeclaration> Reasoning::relation_properties() const
+{
+    std::vector<RelationDeclaration> result;
+    for (const PropertyRule& p : _property_rules)
+        if (!p.converse) result.push_back(p.declaration);
+    return result;
+}
+
+// Runs a stage (see run_stage) with the enabled property rules disabled,
+// closing their relations before it and after every stage that deduced
+// something, until neither adds a fact. After the first closure, each rule
+// starts from the facts added since it was last applied.
+uint64_t Reasoning::run_stage_with_properties(const bool suppress_repetition, const bool silent)
+{
+    std::vector<PropertyRule> native;
+    for (const PropertyRule& p : _property_rules)
+        if (is_rule_enabled(p.rule) && native_property(p)) native.push_back(p);
+    if (native.empty()) return run_stage(suppress_repetition, silent);
+
+    struct DisabledGuard
+    {
+        Reasoning*               r;
+        std::unordered_set<Node> saved;
+        ~DisabledGuard() { r->_disabled_rules = std::move(saved); }
+    } disabled_guard{this, _disabled_rules};
+    for (const PropertyRule& p : native)
+        _disabled_rules.insert(p.rule);
+
+    std::vector<std::unordered_set<Node>> seen(native.size());
+    uint64_t                              seminaive_violations = 0;
+    for (;;)
+    {
+        const uint64_t closed = close_relation_properties(native, seen);
+        if (closed > 0 && !silent)
+            diagnostic_stream() << "--- Relation properties: " << closed << " fact(s) ---" << std::endl;
+        if (stop_requested()) break;
+
+        const uint64_t deduced = _run_deductions;
+        seminaive_violations += run_stage(suppress_repetition, silent);
+        if (suppress_repetition || stop_requested() || _run_deductions == deduced) break;
+    }
+    return seminaive_violations;
+}
+
+// The passes skip the checks of deduce, so they only replace rules whose
+// deductions these checks cannot restrict.
+bool Reasoning::native_property(const PropertyRule& p) const
+{
+    if (_probabilistic || _min_confidence > 0 || _min_trust > 0 || !_context_scope.empty() || !_source_scope.empty()
+        || _default_combination.combination != ConfidenceCombination::None)
+        return false;
+    if (!_validity.empty() || !_contexts.empty()) return false;
+    return _rule_combinations.count(p.rule) == 0 && _rule_contexts.count(p.rule) == 0 && _rule_min_trust.count(p.rule) == 0
+        && _default_rules.count(p.rule) == 0;
+}
+
+// Applies the given property rules natively until none deduces anything;
+// returns the number of facts created. A transitive and a symmetric rule of
+// the same relation are closed together, as an equivalence. seen holds the
+// facts each rule has already been applied to: a rule is applied to the
+// facts of its relation that are not in it yet, and skipped if there are
+// none, so a pass after one that added nothing costs a scan of the facts.
+uint64_t Reasoning::close_relation_properties(const std::vector<PropertyRule>& rules, std::vector<std::unordered_set<Node>>& seen)
+{
+    // Like the observer between runs, but the facts are deduced: they are
+    // neither reported as stated nor given an origin.
+    set_fact_creation_observer([this](Node f, Node p)
+                               {
+        note_view_fact(f, p);
+        note_query_cache_fact(f, p);
+        if (!_incremental) return;
+        std::lock_guard<std::mutex> lock(_mtx_pending_delta);
+        _pending_delta.emplace_back(f, p); });
+    struct ObserverGuard
+    {
+        Reasoning* r;
+        ~ObserverGuard() { r->observe_between_runs(); }
+    } observer_guard{this};
+
+    auto find = [&rules](Node relation, RelationProperty property) -> const PropertyRule*
+    {
+        for (const PropertyRule& p : rules)
+            if (p.declaration.relation == relation && p.declaration.property == property) return &p;
+        return nullptr;
+    };
+
+    uint64_t total = 0;
+    for (uint64_t created = 1; created > 0 && !stop_requested();)
+    {
+        created = 0;
+        for (size_t i = 0; i < rules.size(); ++i)
+        {
+            if (stop_requested()) break;
+
+            const PropertyRule&        p        = rules[i];
+            const RelationDeclaration& d        = p.declaration;
+            const Node                 relation = d.property == RelationProperty::Inverse && p.converse ? d.inverse : d.relation;
+            const auto                 added    = relation_pairs(relation, &seen[i]);
+            if (added.empty()) continue;
+
+            uint64_t by_rule = 0;
+            switch (d.property)
+            {
+            case RelationProperty::Transitive:
+                if (const PropertyRule* symmetric = find(d.relation, RelationProperty::Symmetric))
+                    by_rule += close_equivalence(p, *symmetric);
+                else
+                    by_rule += close_transitive(p, added);
+                break;
+            case RelationProperty::Symmetric:
+                if (find(d.relation, RelationProperty::Transitive)) break;
+                for (const auto& [s, o] : added)
+                    if (deduce_property(p, o, s, {{p.x, s}, {p.y, o}})) ++by_rule;
+                break;
+            case RelationProperty::Reflexive:
+                for (const auto& [s, o] : added)
+                {
+                    const Node n = p.converse ? o : s;
+                    if (deduce_property(p, n, n, {{p.x, s}, {p.y, o}})) ++by_rule;
+                }
+                break;
+            case RelationProperty::Inverse:
+                for (const auto& [s, o] : added)
+                    if (deduce_property(p, o, s, {{p.x, s}, {p.y, o}})) ++by_rule;
+                break;
+            }
+
+            // What the rule deduced from its own relation follows from it
+            // already.
+            if (by_rule > 0) relation_pairs(relation, &seen[i]);
+            created += by_rule;
+        }
+        total += created;
+    }
+    return total;
+}
+
+// Breadth-first from every subject x that reaches an added edge a R b: a
+// node z reached over an edge y R z is related to x by x R y (stated, or
+// deduced when y was reached) and y R z, which explain x R z. The relation
+// is closed but for the added edges, so only these subjects, a and whoever
+// reaches a, can be related to nodes they were not related to before; the
+// first closure has every edge added.
+uint64_t Reasoning::close_transitive(const PropertyRule& transitive, const std::vector<std::pair<Node, Node>>& added)
+{
+    std::unordered_map<Node, std::vector<Node>> successors;
+    std::unordered_map<Node, std::vector<Node>> predecessors;
+    for (const auto& [s, o] : relation_pairs(transitive.declaration.relation))
+    {
+        successors[s].push_back(o);
+        predecessors[o].push_back(s);
+    }
+
+    // A contradiction may have kept x R a from being deduced although x
+    // reaches a, so predecessors are followed, too.
+    std::vector<Node>        subjects;
+    std::unordered_set<Node> chosen;
+    for (const auto& [a, b] : added)
+        if (chosen.insert(a).second) subjects.push_back(a);
+    for (size_t i = 0; i < subjects.size(); ++i)
+    {
+        const auto it = predecessors.find(subjects[i]);
+        if (it == predecessors.end()) continue;
+        for (Node x : it->second)
+            if (chosen.insert(x).second) subjects.push_back(x);
+    }
+
+    uint64_t created = 0;
+    for (Node x : subjects)
+    {
+        const std::vector<Node>& direct = successors.at(x);
+        std::unordered_set<Node> reached(direct.begin(), direct.end());
+        std::deque<Node>         queue(direct.begin(), direct.end());
+        while (!queue.empty() && !stop_requested())
+        {
+            const Node y = queue.front();
+            queue.pop_front();
+
+            const auto it = successors.find(y);
+            if (it == successors.end()) continue;
+            for (Node z : it->second)
+            {
+                if (!reached.insert(z).second) continue;
+                queue.push_back(z);
+                if (deduce_property(transitive, x, z, {{transitive.x, x}, {transitive.y, y}, {transitive.z, z}})) ++created;
+            }
+        }
+    }
+    return created;
+}
+
+// An equivalence relates every two members of a component of its graph,
+// including each member to itself. Instead of a closure from every member,
+// each component is found by union-find and closed over its first member r:
+// the edges are mirrored, r is related to every member along a
+// breadth-first tree and back, and then every a R b is explained by a R r
+// and r R b.
+uint64_t Reasoning::close_equivalence(const PropertyRule& transitive, const PropertyRule& symmetric)
+{
+    const Node r = transitive.declaration.relation;
+
+    uint64_t                                     created = 0;
+    Components                                   components;
+    std::unordered_map<Node, std::vector<Node>> neighbours;
+    for (const auto& [s, o] : relation_pairs(r))
+    {
+        if (deduce_property(symmetric, o, s, {{symmetric.x, s}, {symmetric.y, o}})) ++created;
+        components.unite(s, o);
+        neighbours[s].push_back(o);
+        neighbours[o].push_back(s);
+    }
+
+    auto close = [&](Node x, Node y, Node z)
+    {
+        if (deduce_property(transitive, x, z, {{transitive.x, x}, {transitive.y, y}, {transitive.z, z}})) ++created;
+    };
+
+    for (const std::vector<Node>& members : components.list())
+    {
+        if (members.size() < 2 || stop_requested()) continue;
+        const Node root = members.front();
+
+        std::unordered_set<Node> reached{root};
+        std::deque<Node>         queue{root};
+        while (!queue.empty())
+        {
+            const Node y = queue.front();
+            queue.pop_front();
+            for (Node z : neighbours[y])
+            {
+                if (!reached.insert(z).second) continue;
+                queue.push_back(z);
+                close(root, y, z);
+                if (deduce_property(symmetric, z, root, {{symmetric.x, root}, {symmetric.y, z}})) ++created;
+            }
+        }
+
+        close(root, members[1], root);
+        for (Node a : members)
+        {
+            if (stop_requested()) break;
+            if (a == root) continue;
+            for (Node b : members)
+                close(a, root, b);
+        }
+    }
+    return created;
+}
+
+// The (subject, object) pairs of the facts with the relation, without the
+// patterns of rules and queries. With seen, only those of the facts not in
+// it, which are added to it.
+std::vector<std::pair<Node, Node>> Reasoning::relation_pairs(const Node relation, std::unordered_set<Node>* seen) const
+{
+    std::vector<std::pair<Node, Node>> pairs;
+    for (Node f : _pImpl->get_right(relation))
+    {
+        if (!is_hash(f) || parse_relation(f) != relation) continue;
+        if (seen && !seen->insert(f).second) continue;
+        adjacency_set objects;
+        const Node    subject = parse_fact(f, objects);
+        if (!subject || is_var(subject)) continue;
+        for (Node o : objects)
+            if (!is_var(o)) pairs.emplace_back(subject, o);
+    }
+    return pairs;
+}
+
+// Creates the consequence of the property rule for subject and object, as
+// deduce would for the bindings; returns whether it was created. A
+// consequence known to be wrong is reported as a contradiction.
+bool Reasoning::deduce_property(const PropertyRule& p, const Node subject, const Node object, const Variables& bindings)
+{
+    const Node relation = p.declaration.property == RelationProperty::Inverse && !p.converse
+                            ? p.declaration.inverse
+                            : p.declaration.relation;
+    if (object == relation) return false;
+
+    record_firing(p.rule);
+
+    Node d     = 0;
+    bool wrong = false;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        const Answer                answer = check_fact(subject, relation, {object});
+        if (answer.is_wrong())
+        {
+            wrong = true;
+        }
+        else if (!answer.is_known())
+        {
+            d = fact(subject, relation, {object});
+            _deduced_facts.insert(d);
+            _derivations.emplace(d, Derivation{p.rule, p.condition, bindings});
+        }
+    }
+
+    if (wrong)
+    {
+        report_contradiction(contradiction_error(p.condition, bindings, p.rule));
+        return false;
+    }
+    if (!d) return false;
+
+    report_deduction(d, p.rule, p.condition, bindings);
+    return true;
+}
@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include <vector>
+
+using namespace zelph::network;
+
+Reasoning::Provenance Reasoning::provenance(const Node fact) const
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+
+    auto derivation = _derivations.find(fact);
+    if (derivation != _derivations.end()) return {"rule", "", 0, derivation->second.rule};
+
+    std::lock_guard<std::mutex> lock(_mtx_provenance);
+    auto                        it = _provenance.find(fact);
+    return it == _provenance.end() ? Provenance{} : it->second;
+}
+
+bool Reasoning::premise_sources(const Node condition, const Variables& bindings) const
+{
+    if (_source_scope.empty()) return true;
+
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+    std::unordered_set<Node> visited;
+    for (Node premise : premises)
+        if (!source_in_scope(premise, visited)) return false;
+    return true;
+}
+
+// A stated fact is in scope if its source is; a deduced fact if all of its
+// premises are. A premise existed before the fact derived from it, so the
+// visited set only guards against truncated histories (see explain).
+bool Reasoning::source_in_scope(const Node fact, std::unordered_set<Node>& visited) const
+{
+    if (!visited.insert(fact).second) return true;
+
+    auto derivation = _derivations.find(fact);
+    if (derivation != _derivations.end())
+    {
+        std::vector<Node> premises;
+        collect_premises(derivation->second.condition, derivation->second.bindings, premises);
+        for (Node premise : premises)
+            if (!source_in_scope(premise, visited)) return false;
+        return true;
+    }
+
+    std::lock_guard<std::mutex> lock(_mtx_provenance);
+    auto                        it = _provenance.find(fact);
+    return it != _provenance.end() && _source_scope.count(it->second.source) == 1;
+}
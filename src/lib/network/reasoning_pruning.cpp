@@ -88,6 +88,89 @@ void Reasoning::prune_nodes(Node pattern, size_t& removed_facts, size_t& removed
     _prune_nodes_mode = false;
 }
 
+void Reasoning::retract(Node fact, size_t& withdrawn_count)
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+    if (is_deduced(fact))
+        throw std::runtime_error("Fact " + std::to_string(fact) + " was deduced; retract its premises instead");
+    if (loaded_from_file())
+        throw std::runtime_error("Cannot retract in a network loaded from a file: it does not record which facts were deduced");
+
+    withdrawn_count = withdraw(fact, 0).size();
+}
+
+// The fallback of withdraw where removing a fact may also enable or change
+// deductions elsewhere: all deduced facts are withdrawn and reasoning is
+// re-run silently. Returns the deductions that stayed withdrawn.
+std::vector<Reasoning::RetractedFact> Reasoning::recompute_without(const Node fact)
+{
+    invalidate_fact_structures_cache();
+
+    // The parts of the facts that may go, read while they still exist.
+    std::vector<RetractedFact> candidates;
+    auto                       remember = [&](Node candidate, bool deduced)
+    {
+        auto& parts     = candidates.emplace_back();
+        parts.fact      = candidate;
+        parts.subject   = parse_fact(candidate, parts.objects);
+        parts.predicate = parse_relation(candidate);
+        parts.deduced   = deduced;
+    };
+    remember(fact, false);
+    for (Node deduced : _deduced_facts)
+        remember(deduced, true);
+
+    std::unordered_set<Node> removed;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        _pImpl->remove(fact);
+        removed.swap(_deduced_facts);
+        _derivations.clear();
+        _supports.clear();
+        _defeats.clear();
+        _pending_defeats.clear();
+        _validity.erase(fact);
+        _contexts.erase(fact);
+        for (Node deduced : removed)
+        {
+            _pImpl->remove(deduced);
+            _validity.erase(deduced);
+            _contexts.erase(deduced);
+        }
+    }
+
+    invalidate_fact_structures_cache();
+
+    // Re-derivations are not news to the observer; it only learns about
+    // deductions in regular runs. Nor may a breakpoint pause this run.
+    DeductionObserver observer        = std::move(_on_deduction);
+    BreakCondition    break_condition = std::move(_break_condition);
+    _on_deduction                     = nullptr;
+    _break_condition                  = nullptr;
+    try
+    {
+        run(false, false, false, true);
+    }
+    catch (...)
+    {
+        _on_deduction    = std::move(observer);
+        _break_condition = std::move(break_condition);
+        throw;
+    }
+    _on_deduction    = std::move(observer);
+    _break_condition = std::move(break_condition);
+
+    std::vector<RetractedFact> withdrawn;
+    for (const RetractedFact& candidate : candidates)
+    {
+        if (_deduced_facts.count(candidate.fact) == 1) continue;
+        if (candidate.deduced) withdrawn.push_back(candidate);
+        if (_on_retraction) _on_retraction(candidate);
+    }
+    return withdrawn;
+}
+
 void Reasoning::purge_unused_predicates(size_t& removed_facts, size_t& removed_predicates)
 {
     invalidate_fact_structures_cache();
@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "fact_structure.hpp"
+
+#include <algorithm>
+#include <stdexcept>
+
+using namespace zelph::network;
+
+Node Reasoning::qualify(const Node qualified, const Node predicate, const Node value)
+{
+    if (!is_hash(qualified) || !exists(qualified))
+        throw std::runtime_error("Node " + std::to_string(qualified) + " is not a fact");
+
+    return fact(qualified, predicate, {value});
+}
+
+std::vector<Node> Reasoning::qualifiers(const Node qualified) const
+{
+    if (!is_hash(qualified) || !exists(qualified))
+        throw std::runtime_error("Node " + std::to_string(qualified) + " is not a fact");
+
+    // A fact is linked both ways to its subject, so the qualifiers are
+    // among the neighbours of the qualified fact.
+    adjacency_set candidates = get_left(qualified);
+    for (Node nd : get_right(qualified))
+        candidates.insert(nd);
+
+    std::vector<Node> result;
+    for (Node candidate : candidates)
+    {
+        if (candidate == qualified || !is_hash(candidate)) continue;
+        const FactStructure structure = get_preferred_structure(this, candidate, 0);
+        if (structure.subject == qualified && structure.predicate != 0 && !structure.objects.empty())
+            result.push_back(candidate);
+    }
+    std::sort(result.begin(), result.end());
+    return result;
+}
@@ -0,0 +1,212 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <stdexcept>
+
+using namespace zelph::network;
+
+std::vector<Reasoning::RetractedFact> Reasoning::revise(const Node fact, const Node replacement)
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+    if (is_deduced(fact))
+        throw std::runtime_error("Fact " + std::to_string(fact) + " was deduced; revise its premises instead");
+    if (loaded_from_file())
+        throw std::runtime_error("Cannot revise in a network loaded from a file: it does not record which facts were deduced");
+    if (replacement && (replacement == fact || !is_hash(replacement) || !exists(replacement)))
+        throw std::runtime_error("Node " + std::to_string(replacement) + " is not a replacement fact");
+
+    // A replacement that had been deduced is stated from now on.
+    if (replacement)
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        _deduced_facts.erase(replacement);
+        _derivations.erase(replacement);
+    }
+
+    return withdraw(fact, replacement);
+}
+
+// Delete and rederive, for retract and revise: the deductions whose
+// recorded derivation used the fact, directly or through another deduction
+// that goes, are removed with it (see withdraw_dependents). A run then
+// re-applies, as a classic pass, only the rules that may re-derive one of
+// them, and seeds the facts created since the last run as usual. Returns
+// the deductions that stayed withdrawn.
+std::vector<Reasoning::RetractedFact> Reasoning::withdraw(const Node fact, const Node replacement)
+{
+    // Removing or adding a fact may change deductions of a negated
+    // condition anywhere, and the supports of probabilistic deductions are
+    // combined; both are recomputed as a whole.
+    const std::vector<RuleShape> shapes = rule_shapes();
+    if (_probabilistic || std::any_of(shapes.begin(), shapes.end(), [](const RuleShape& s)
+                                      { return !s.negated.empty(); }))
+        return recompute_without(fact);
+
+    std::vector<RetractedFact>     withdrawn;
+    std::vector<RetractedFact>     candidates;
+    std::unordered_set<Node>       rederive;
+    const std::unordered_set<Node> removed = withdraw_dependents({fact}, replacement, candidates, rederive);
+
+    // Assumptions of default rules that a contradiction involving a removed
+    // fact defeated may hold again.
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        std::erase_if(_defeats, [&](const auto& entry)
+                      {
+            const Defeat& defeat = entry.second;
+            if (std::none_of(defeat.premises.begin(), defeat.premises.end(), [&removed](Node premise)
+                             { return removed.count(premise) == 1; }))
+                return false;
+            rederive.insert(defeat.rule);
+            return true; });
+    }
+
+    // Re-derivations are not news to the observer, and no breakpoint may
+    // pause this run.
+    DeductionObserver observer        = std::move(_on_deduction);
+    BreakCondition    break_condition = std::move(_break_condition);
+    _on_deduction                     = nullptr;
+    _break_condition                  = nullptr;
+    _rederive_rules                   = std::move(rederive);
+    struct ObserverGuard
+    {
+        Reasoning*         r;
+        DeductionObserver& observer;
+        BreakCondition&    break_condition;
+        ~ObserverGuard()
+        {
+            r->_on_deduction    = std::move(observer);
+            r->_break_condition = std::move(break_condition);
+            r->_rederive_rules.clear();
+        }
+    };
+    {
+        ObserverGuard guard{this, observer, break_condition};
+        run(false, false, false, true);
+    }
+
+    for (const RetractedFact& candidate : candidates)
+    {
+        if (_deduced_facts.count(candidate.fact) == 1) continue;
+        if (candidate.deduced) withdrawn.push_back(candidate);
+        if (_on_retraction) _on_retraction(candidate);
+    }
+    return withdrawn;
+}
+
+// Removes the roots with every deduction whose recorded derivation depends
+// on one of them, as a premise or as its rule, directly or through another
+// deduction that goes, except keep. The rest of the closure keeps its derivations (each recorded one
+// predates its fact, so it cannot depend on a removed deduction without
+// being removed itself). The parts of the removed facts, roots first, are
+// appended to parts, and the rules whose consequences may unify with a
+// removed deduction to rederive. The removals are accounted for here, so
+// they do not cost the next run its incremental start. Not to be called
+// during a run.
+std::unordered_set<Node> Reasoning::withdraw_dependents(const std::vector<Node>& roots, const Node keep, std::vector<RetractedFact>& parts, std::unordered_set<Node>& rederive)
+{
+    invalidate_fact_structures_cache();
+
+    auto remember = [&](Node candidate)
+    {
+        auto& fact     = parts.emplace_back();
+        fact.fact      = candidate;
+        fact.subject   = parse_fact(candidate, fact.objects);
+        fact.predicate = parse_relation(candidate);
+        fact.deduced   = _deduced_facts.count(candidate) == 1;
+    };
+
+    const std::vector<RuleShape> shapes  = rule_shapes();
+    const bool                   in_sync = _incremental && _pImpl->unobserved_changes() == _incremental_changes;
+    std::unordered_set<Node>     removed(roots.begin(), roots.end());
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+
+        std::unordered_map<Node, std::vector<Node>> dependents;
+        for (const auto& [deduced, derivation] : _derivations)
+        {
+            std::vector<Node> premises;
+            collect_premises(derivation.condition, derivation.bindings, premises);
+            for (Node premise : premises)
+                dependents[premise].push_back(deduced);
+            dependents[derivation.rule].push_back(deduced);
+        }
+
+        std::vector<Node> pending(roots.begin(), roots.end());
+        while (!pending.empty())
+        {
+            const Node premise = pending.back();
+            pending.pop_back();
+            const auto it = dependents.find(premise);
+            if (it == dependents.end()) continue;
+            for (Node deduced : it->second)
+                if (deduced != keep && removed.insert(deduced).second) pending.push_back(deduced);
+        }
+
+        // Read while the facts still exist.
+        for (Node root : roots)
+            remember(root);
+        std::vector<Node> history;
+        for (Node node : removed)
+        {
+            if (std::find(roots.begin(), roots.end(), node) == roots.end()) remember(node);
+            if (_deduced_facts.count(node) == 0) continue;
+            for (const RuleShape& shape : shapes)
+            {
+                if (rederive.count(shape.rule) == 1) continue;
+                for (Node consequence : shape.consequences)
+                {
+                    if (!may_unify(consequence, node, history)) continue;
+                    rederive.insert(shape.rule);
+                    break;
+                }
+            }
+        }
+
+        for (Node node : removed)
+        {
+            _pImpl->remove(node);
+            _deduced_facts.erase(node);
+            _derivations.erase(node);
+            _validity.erase(node);
+            _contexts.erase(node);
+        }
+    }
+    {
+        std::lock_guard<std::mutex> lock(_mtx_pending_delta);
+        std::erase_if(_pending_delta, [&removed](const auto& entry)
+                      { return removed.count(entry.first) == 1; });
+    }
+    if (in_sync) _incremental_changes = _pImpl->unobserved_changes();
+
+    invalidate_fact_structures_cache();
+    return removed;
+}
@@ -31,6 +31,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "unification.hpp"
 #include "zelph_impl.hpp"
 
+#include <algorithm>
 #include <mutex>
 #include <utility>
 #include <vector>
@@ -74,6 +75,34 @@ bool Reasoning::seminaive_check() const
     return _seminaive_check;
 }
 
+void Reasoning::invalidate_incremental()
+{
+    _incremental = false;
+    std::lock_guard<std::mutex> lock(_mtx_pending_delta);
+    _pending_delta.clear();
+}
+
+// Between runs, the fact-creation observer collects the facts created since
+// the last complete run; the next run seeds them instead of starting with a
+// classic pass (see run_fixpoint_seminaive). It also records the origin of
+// the facts (see provenance).
+void Reasoning::observe_between_runs()
+{
+    set_fact_creation_observer([this](Node f, Node p)
+                               {
+        if (_on_new_fact) _on_new_fact(f);
+        note_view_fact(f, p);
+        note_query_cache_fact(f, p);
+        if (!_origin.source.empty())
+        {
+            std::lock_guard<std::mutex> lock(_mtx_provenance);
+            _provenance.emplace(f, _origin);
+        }
+        if (!_incremental) return;
+        std::lock_guard<std::mutex> lock(_mtx_pending_delta);
+        _pending_delta.emplace_back(f, p); });
+}
+
 // Semi-naive (delta-driven) fixpoint evaluation.
 //
 // Iteration 1 is a classic pass over the whole graph: it covers user input,
@@ -89,6 +118,16 @@ bool Reasoning::seminaive_check() const
 // once in a delta; facts created in the SAME delta find each other because
 // the seeded evaluation of the later-processed fact scans a graph that
 // already contains the earlier one.
+//
+// Incremental runs: when the previous run reached its fixpoint, the rule set
+// is unchanged and nothing bypassed the fact-creation observer since
+// (Network::unobserved_changes: removals, bulk imports, loads), that
+// fixpoint still holds for all facts but those created in the meantime.
+// Iteration 1 then skips the classic pass and seeds them as its delta
+// instead, so adding a fact to a large network only re-fires the rules
+// whose conditions it matches. Delta-unsafe and deferred rules are applied
+// classically as in every run, and so are the rules revise needs to
+// re-derive the deductions it removed.
 uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
 {
     _nn_pred        = get_node("nn", "zelph");
@@ -103,8 +142,10 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
     // leaf conditions with a variable predicate: seeded by every delta fact
     std::vector<std::pair<size_t, size_t>> wildcard_index;
 
-    for (Node rule_node : _pImpl->get_left(core.Causes))
+    for (Node rule_node : ordered_rules())
     {
+        if (!is_rule_enabled(rule_node)) continue;
+
         IndexedRule ir;
         ir.rule        = rule_node;
         Node condition = parse_fact(rule_node, ir.deductions);
@@ -159,6 +200,7 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
             //    that structure)
             //  - elements without a unique predicate
             //  - neural (approx) conditions (no fact lookup, epoch-cached)
+            //    and builtin conditions (no fact lookup)
             //  - no positive leaf at all
             for (Node cond : ir.elements)
             {
@@ -176,7 +218,7 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
                 }
                 const Node rel = *rels.begin();
 
-                if (_nn_pred != 0 && rel == _nn_pred)
+                if ((_nn_pred != 0 && rel == _nn_pred) || is_builtin(rel))
                 {
                     ir.delta_unsafe = true;
                     continue;
@@ -206,13 +248,24 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
         rules.push_back(std::move(ir));
     }
 
-    bool has_deferred = false;
+    bool              has_deferred = false;
+    std::vector<Node> rule_nodes;
     for (const IndexedRule& ir : rules)
-        if (ir.deferred)
-        {
-            has_deferred = true;
-            break;
-        }
+    {
+        if (ir.deferred) has_deferred = true;
+        rule_nodes.push_back(ir.rule);
+    }
+
+    const bool incremental = _incremental
+                          && rule_nodes == _incremental_rules
+                          && _pImpl->unobserved_changes() == _incremental_changes;
+    _incremental           = false; // set again once this run completes
+
+    std::vector<std::pair<Node, Node>> pending;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_pending_delta);
+        pending.swap(_pending_delta);
+    }
 
     // ------------------------------------------------------------------
     // Phase 1: delta capture + classic first iteration
@@ -220,47 +273,49 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
     std::mutex                         delta_mtx;
     std::vector<std::pair<Node, Node>> delta; // (fact node, predicate)
 
-    set_fact_creation_observer([&delta, &delta_mtx](Node f, Node p)
+    set_fact_creation_observer([this, &delta, &delta_mtx](Node f, Node p)
                                {
+        note_view_fact(f, p);
+        note_query_cache_fact(f, p);
         std::lock_guard<std::mutex> lock(delta_mtx);
         delta.emplace_back(f, p); });
 
-    // The observer captures locals by reference; make sure it is gone on
-    // every exit path (including exceptions).
+    // The observer captures locals by reference; make sure it is replaced
+    // on every exit path (including exceptions).
     struct ObserverGuard
     {
-        Zelph* z;
-        ~ObserverGuard() { z->set_fact_creation_observer(nullptr); }
+        Reasoning* r;
+        ~ObserverGuard() { r->observe_between_runs(); }
     } observer_guard{this};
 
-    int iteration = 1;
-    _done         = false;
-    if (!silent)
-        diagnostic_stream() << "--- Reasoning iteration 1 (classic, positive stratum) ---" << std::endl;
-    for (const IndexedRule& ir : rules)
-        if (!ir.deferred) apply_rule(ir.rule, 0);
-    _pool->wait();
-
-    // ------------------------------------------------------------------
-    // Helpers for the seeded phase
-    // ------------------------------------------------------------------
-    auto report_contradiction = [&](const contradiction_error& error)
+    int iteration   = 1;
+    _done           = false;
+    _run_iterations = 1;
+    if (incremental)
     {
-        std::lock_guard<std::mutex> lock(_mtx_output);
-        _contradiction = true;
-        ++_total_contradictions;
-
-        if (_print_deductions || _generate_markdown)
+        if (!silent)
+            diagnostic_stream() << "--- Reasoning iteration 1 (incremental, " << pending.size()
+                                << " new fact(s) since the last run) ---" << std::endl;
         {
-            std::string output;
-            string::node_to_string(this, output, _lang, error.get_fact(), 3, error.get_variables(), error.get_parent());
-            std::string message = "«" + get_formatted_name(core.Contradiction, _lang) + "» ⇐ " + output;
-
-            if (_print_deductions) out(string::unmark_identifiers(message), true);
-            if (_generate_markdown) _markdown->add("Contradictions", message);
+            std::lock_guard<std::mutex> lock(delta_mtx);
+            delta = std::move(pending);
         }
-    };
+        for (const IndexedRule& ir : rules)
+            if (ir.delta_unsafe || _rederive_rules.count(ir.rule) == 1) apply_rule(ir.rule, 0);
+        _pool->wait();
+    }
+    else
+    {
+        if (!silent)
+            diagnostic_stream() << "--- Reasoning iteration 1 (classic, positive stratum) ---" << std::endl;
+        for (const IndexedRule& ir : rules)
+            if (!ir.deferred) apply_rule(ir.rule, 0);
+        _pool->wait();
+    }
 
+    // ------------------------------------------------------------------
+    // Helpers for the seeded phase
+    // ------------------------------------------------------------------
     auto seed_rule = [&](const IndexedRule& ir, size_t leaf_idx, Node seed_fact, Node seed_pred)
     {
         const Node cond = ir.leaves[leaf_idx];
@@ -268,6 +323,8 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
         if (logging_active())
             _prof.seminaive_seeds.fetch_add(1, std::memory_order_relaxed);
 
+        const auto started = _rule_profiling ? std::chrono::steady_clock::now() : std::chrono::steady_clock::time_point{};
+
         ReasoningContext ctx;
         // current_condition stays the TOP condition so that deduce() renders
         // the same "consequence <= {conditions}" explanation as classic mode.
@@ -281,6 +338,8 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
 
         while (std::shared_ptr<Variables> match = u.Next())
         {
+            if (stop_requested()) break;
+
             // Mirror the checks of evaluate()'s process_match so that a
             // seeded first condition behaves exactly like a scanned one.
             bool excluded_hit = false;
@@ -335,6 +394,9 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
             }
         }
         u.wait_for_completion();
+
+        if (_rule_profiling)
+            record_rule_time(ir.rule, std::chrono::duration<double>(std::chrono::steady_clock::now() - started).count());
     };
 
     // ------------------------------------------------------------------
@@ -343,13 +405,22 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
     uint64_t safety_violations = 0;
     bool     negation_pending  = has_deferred;
 
-    while (true)
+    // A run paused by a bound (see Reasoning::set_max_iterations) can be
+    // resumed once the fixpoint holds for all facts but the delta, i.e.
+    // after the first pass. An incremental first iteration only queues the
+    // new facts and does not count as an iteration of its own.
+    const bool resumable      = incremental || !stop_requested();
+    const int  uncounted_pass = incremental ? 1 : 0;
+
+    std::vector<std::pair<Node, Node>> current;
+    while (!stop_requested())
     {
-        std::vector<std::pair<Node, Node>> current;
+        current.clear();
         {
             std::lock_guard<std::mutex> lock(delta_mtx);
             current.swap(delta);
         }
+        if (deterministic()) std::sort(current.begin(), current.end());
 
         if (current.empty())
         {
@@ -407,8 +478,10 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
             continue; // the extra facts are in the delta now
         }
 
-        ++iteration;
-        _done = false;
+        if (iterations_exhausted(iteration - uncounted_pass)) break;
+
+        _run_iterations = ++iteration;
+        _done           = false;
         if (!silent)
             diagnostic_stream() << "--- Reasoning iteration " << iteration
                                 << " (semi-naive, delta=" << current.size() << ") ---" << std::endl;
@@ -441,5 +514,30 @@ uint64_t Reasoning::run_fixpoint_seminaive(bool silent)
     }
 
     _done = false;
+
+    if (!stop_requested() && safety_violations == 0)
+    {
+        _incremental_rules   = std::move(rule_nodes);
+        _incremental_changes = _pImpl->unobserved_changes();
+        _incremental         = true;
+    }
+    else if (resumable && safety_violations == 0 && pause_requested())
+    {
+        // Paused: keep the facts not yet seeded (all of the interrupted
+        // iteration, as it may have stopped part way) for the next run.
+        current.insert(current.end(), delta.begin(), delta.end());
+        const size_t left = current.size();
+        {
+            std::lock_guard<std::mutex> lock(_mtx_pending_delta);
+            _pending_delta = std::move(current);
+        }
+        _incremental_rules   = std::move(rule_nodes);
+        _incremental_changes = _pImpl->unobserved_changes();
+        _incremental         = true;
+        if (!silent)
+            diagnostic_stream() << "Semi-naive evaluation paused with " << left
+                                << " fact(s) left to seed." << std::endl;
+    }
+
     return safety_violations;
 }
@@ -0,0 +1,278 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "fact_structure.hpp"
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <unordered_set>
+#include <vector>
+
+using namespace zelph::network;
+
+std::vector<Reasoning::StratificationViolation> Reasoning::stratification_violations() const
+{
+    const std::vector<RuleShape>           shapes = rule_shapes();
+    const std::vector<std::vector<size_t>> feeds  = rule_feeds(shapes);
+
+    std::vector<Node> history;
+    auto              grows = [&](const RuleShape& from, const Node pattern, const bool negated)
+    {
+        for (Node consequence : from.consequences)
+        {
+            // Meta-rules such as (A R C) would match every negation.
+            if (negated && is_var(get_preferred_structure(this, consequence, 0).predicate)) continue;
+            if (may_unify(consequence, pattern, history)) return true;
+        }
+        return false;
+    };
+
+    std::vector<StratificationViolation> violations;
+    for (size_t d = 0; d < shapes.size(); ++d)
+    {
+        if (shapes[d].negated.empty()) continue;
+
+        std::vector<bool>   reached(shapes.size(), false);
+        std::vector<size_t> pending{d};
+        reached[d] = true;
+        while (!pending.empty())
+        {
+            const size_t i = pending.back();
+            pending.pop_back();
+            for (size_t j : feeds[i])
+            {
+                if (reached[j]) continue;
+                reached[j] = true;
+                pending.push_back(j);
+            }
+        }
+
+        for (size_t e = 0; e < shapes.size(); ++e)
+        {
+            for (Node pattern : shapes[e].negated)
+            {
+                // A rule's own negation is only checked against its own
+                // consequences. Growth through the positive stratum is
+                // re-evaluated by the alternating schedule, which modules
+                // ordered by the structure of their terms rely on (see
+                // stdlib/symbolic-core.zph).
+                bool hit = e == d && grows(shapes[d], pattern, true);
+                for (size_t r = 0; !hit && e != d && r < shapes.size(); ++r)
+                    hit = reached[r] && grows(shapes[r], pattern, true);
+
+                if (hit) violations.push_back(StratificationViolation{shapes[d].rule, shapes[e].rule, pattern});
+            }
+        }
+    }
+
+    return violations;
+}
+
+std::vector<Reasoning::UnboundedRule> Reasoning::unbounded_rules() const
+{
+    const std::vector<RuleShape>           shapes = rule_shapes();
+    const std::vector<std::vector<size_t>> feeds  = rule_feeds(shapes);
+
+    std::vector<UnboundedRule> result;
+    for (size_t d = 0; d < shapes.size(); ++d)
+    {
+        std::unordered_set<Node> bound;
+        std::vector<Node>        history;
+        for (Node condition : shapes[d].positive)
+            pattern_variables(condition, bound, history);
+        for (Node condition : shapes[d].negated)
+            pattern_variables(condition, bound, history);
+
+        Node term = 0;
+        for (size_t c = 0; !term && c < shapes[d].consequences.size(); ++c)
+        {
+            std::unordered_set<Node> vars;
+            pattern_variables(shapes[d].consequences[c], vars, history);
+            for (Node var : vars)
+                if (bound.count(var) == 0 && (!term || var < term)) term = var;
+            if (!term) term = nested_term(shapes[d].consequences[c]);
+        }
+        if (!term) continue;
+
+        // Breadth-first search for the shortest path from d back to d.
+        std::vector<size_t> previous(shapes.size(), shapes.size());
+        std::vector<size_t> pending{d};
+        bool                closed = false;
+        for (size_t next = 0; !closed && next < pending.size(); ++next)
+        {
+            const size_t i = pending[next];
+            for (size_t j : feeds[i])
+            {
+                if (j == d)
+                {
+                    previous[d] = i;
+                    closed      = true;
+                    break;
+                }
+                if (previous[j] != shapes.size()) continue;
+                previous[j] = i;
+                pending.push_back(j);
+            }
+        }
+        if (!closed) continue;
+
+        UnboundedRule unbounded{shapes[d].rule, term};
+        for (size_t i = previous[d]; i != d; i = previous[i])
+            unbounded.cycle.push_back(shapes[i].rule);
+        unbounded.cycle.push_back(shapes[d].rule);
+        std::reverse(unbounded.cycle.begin(), unbounded.cycle.end());
+        result.push_back(std::move(unbounded));
+    }
+
+    return result;
+}
+
+// The enabled rules split into their consequences and leaf conditions.
+std::vector<Reasoning::RuleShape> Reasoning::rule_shapes() const
+{
+    std::vector<RuleShape> shapes;
+    for (Node rule : get_rules())
+    {
+        if (!is_rule_enabled(rule)) continue;
+
+        RuleShape     shape{rule};
+        adjacency_set deductions;
+        collect_conditions(parse_fact(rule, deductions), false, shape.positive, shape.negated);
+        for (Node deduction : deductions)
+            if (deduction != core.Contradiction) shape.consequences.push_back(deduction);
+        shapes.push_back(std::move(shape));
+    }
+    return shapes;
+}
+
+// feeds[i]: the rules having a positive condition that a consequence of
+// rule i may match. Negated conditions do not propagate: growing them can
+// only stop a rule from firing.
+std::vector<std::vector<size_t>> Reasoning::rule_feeds(const std::vector<RuleShape>& shapes) const
+{
+    std::vector<Node>                history;
+    std::vector<std::vector<size_t>> feeds(shapes.size());
+    for (size_t i = 0; i < shapes.size(); ++i)
+    {
+        for (size_t j = 0; j < shapes.size(); ++j)
+        {
+            if (std::any_of(shapes[j].positive.begin(), shapes[j].positive.end(), [&](Node condition)
+                            { return std::any_of(shapes[i].consequences.begin(), shapes[i].consequences.end(), [&](Node consequence)
+                                                 { return may_unify(consequence, condition, history); }); }))
+                feeds[i].push_back(j);
+        }
+    }
+    return feeds;
+}
+
+// Splits a rule condition into its leaf conditions (the condition itself,
+// or the elements of a conjunction set, recursively). Leaves inside a
+// negation count as negated.
+void Reasoning::collect_conditions(const Node condition, bool negated, std::vector<Node>& positive, std::vector<Node>& negative) const
+{
+    if (!condition || !exists(condition)) return;
+
+    negated = negated || check_fact(condition, core.IsA, {core.Negation}).is_known();
+
+    if (check_fact(condition, core.IsA, {core.Conjunction}).is_known())
+    {
+        for (Node rel : _pImpl->get_right(condition))
+        {
+            if (parse_relation(rel) != core.PartOf) continue;
+            adjacency_set objs;
+            Node          element = parse_fact(rel, objs);
+            if (element && objs.count(condition) == 1)
+                collect_conditions(element, negated, positive, negative);
+        }
+        return;
+    }
+
+    (negated ? negative : positive).push_back(condition);
+}
+
+// True if some fact could match both patterns: a variable matches
+// anything, other nodes must be equal or facts whose parts may unify in
+// turn. The objects of multi-object facts are not compared.
+bool Reasoning::may_unify(const Node a, const Node b, std::vector<Node>& history) const
+{
+    if (a == b || is_var(a) || is_var(b)) return true;
+    if (std::find(history.begin(), history.end(), a) != history.end()) return true;
+
+    FactStructure fa = get_preferred_structure(this, a, 0);
+    FactStructure fb = get_preferred_structure(this, b, 0);
+    if (fa.subject == 0 || fb.subject == 0 || fa.objects.size() != fb.objects.size()) return false;
+
+    history.push_back(a);
+    const bool result = may_unify(fa.predicate, fb.predicate, history)
+                     && may_unify(fa.subject, fb.subject, history)
+                     && (fa.objects.size() != 1 || may_unify(*fa.objects.begin(), *fb.objects.begin(), history));
+    history.pop_back();
+    return result;
+}
+
+// Collects the variables of a pattern, at any depth of nesting.
+void Reasoning::pattern_variables(const Node pattern, std::unordered_set<Node>& vars, std::vector<Node>& history) const
+{
+    if (pattern == 0) return;
+    if (is_var(pattern))
+    {
+        vars.insert(pattern);
+        return;
+    }
+    if (std::find(history.begin(), history.end(), pattern) != history.end()) return;
+
+    FactStructure fs = get_preferred_structure(this, pattern, 0);
+    if (fs.subject == 0) return;
+
+    history.push_back(pattern);
+    pattern_variables(fs.subject, vars, history);
+    pattern_variables(fs.predicate, vars, history);
+    for (Node object : fs.objects)
+        pattern_variables(object, vars, history);
+    history.pop_back();
+}
+
+// The subject or an object of a consequence that is itself a statement
+// with variables, i.e. a term each firing may build anew, or 0.
+Node Reasoning::nested_term(const Node consequence) const
+{
+    FactStructure fs = get_preferred_structure(this, consequence, 0);
+    if (fs.subject == 0) return 0;
+
+    std::vector<Node> parts{fs.subject};
+    parts.insert(parts.end(), fs.objects.begin(), fs.objects.end());
+    for (Node part : parts)
+    {
+        if (is_var(part) || get_preferred_structure(this, part, 0).subject == 0) continue;
+
+        std::unordered_set<Node> vars;
+        std::vector<Node>        history{consequence};
+        pattern_variables(part, vars, history);
+        if (!vars.empty()) return part;
+    }
+    return 0;
+}
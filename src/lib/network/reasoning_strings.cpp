@@ -0,0 +1,47 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "string/string_utils.hpp"
+
+using namespace zelph::network;
+
+void Reasoning::set_string_builtins(const bool enabled)
+{
+    _native_strings = enabled;
+    install_native();
+}
+
+bool Reasoning::string_relation_holds(const std::string& relation, const std::string& a, const std::string& b)
+{
+    if (relation == "starts_with")
+        return a.compare(0, b.size(), b) == 0;
+    if (relation == "ends_with")
+        return a.size() >= b.size() && a.compare(a.size() - b.size(), b.size(), b) == 0;
+    if (relation == "equals_ignoring_case")
+        return string::unicode::normalize(a, string::unicode::Normalization::NFC, true) == string::unicode::normalize(b, string::unicode::Normalization::NFC, true);
+    return false;
+}
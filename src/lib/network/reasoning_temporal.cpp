@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include <algorithm>
+#include <vector>
+
+using namespace zelph::network;
+
+Reasoning::Validity Reasoning::validity(const Node fact) const
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+
+    auto it = _validity.find(fact);
+    return it == _validity.end() ? Validity{} : it->second;
+}
+
+void Reasoning::set_validity(const Node fact, const Validity& validity)
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+    if (validity.from && validity.to && *validity.from > *validity.to)
+        throw std::runtime_error("Validity interval ends before it begins");
+
+    invalidate_incremental();
+    std::lock_guard<std::mutex> lock(_mtx_network);
+    if (validity.always())
+        _validity.erase(fact);
+    else
+        _validity[fact] = validity;
+    touch_query_cache(parse_relation(fact));
+}
+
+// Intersects the intervals of the facts the conditions matched. Returns
+// false if they do not overlap. During a run, the caller holds
+// _mtx_network.
+bool Reasoning::premise_validity(const Node condition, const Variables& bindings, Validity& validity) const
+{
+    validity = {};
+    if (_validity.empty()) return true;
+
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+
+    for (Node premise : premises)
+    {
+        auto it = _validity.find(premise);
+        if (it == _validity.end()) continue;
+
+        const Validity& v = it->second;
+        if (v.from) validity.from = validity.from ? std::max(*validity.from, *v.from) : *v.from;
+        if (v.to) validity.to = validity.to ? std::min(*validity.to, *v.to) : *v.to;
+    }
+
+    return !(validity.from && validity.to && *validity.from > *validity.to);
+}
+
+bool Reasoning::valid_as_of(const Node condition, const Variables& bindings) const
+{
+    Validity validity;
+    return premise_validity(condition, bindings, validity) && validity.contains(*_as_of);
+}
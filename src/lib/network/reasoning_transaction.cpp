@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+using namespace zelph::network;
+
+namespace
+{
+    // Parentheses keep the name apart from clusters named with .cluster.
+    const std::string transaction_cluster = "(transaction)";
+}
+
+void Reasoning::begin_transaction()
+{
+    if (_transaction)
+        throw std::runtime_error("A transaction is already open");
+
+    _transaction = active_cluster_name();
+    set_active_cluster(transaction_cluster);
+}
+
+void Reasoning::commit_transaction()
+{
+    if (!_transaction)
+        throw std::runtime_error("No transaction is open");
+
+    const std::string outer = *_transaction;
+    _transaction.reset();
+
+    merge_cluster(transaction_cluster, outer);
+    if (outer.empty())
+        deactivate_cluster();
+    else
+        set_active_cluster(outer);
+}
+
+size_t Reasoning::rollback_transaction()
+{
+    if (!_transaction)
+        throw std::runtime_error("No transaction is open");
+
+    const std::string outer = *_transaction;
+    _transaction.reset();
+
+    const size_t removed = drop_cluster(transaction_cluster);
+    if (outer.empty())
+        deactivate_cluster();
+    else
+        set_active_cluster(outer);
+    return removed;
+}
+
+size_t Reasoning::run_dry(const bool print_deductions, const DeductionObserver& visit)
+{
+    begin_transaction();
+
+    std::vector<std::pair<Node, Node>> deductions; // fact, rule
+    DeductionObserver                  observer = std::move(_on_deduction);
+    _on_deduction                               = [&deductions](Node fact, Node rule)
+    { deductions.emplace_back(fact, rule); };
+
+    try
+    {
+        run(print_deductions, false, false, !print_deductions);
+        if (visit)
+            for (const auto& [fact, rule] : deductions)
+                visit(fact, rule);
+    }
+    catch (...)
+    {
+        _on_deduction = std::move(observer);
+        rollback_transaction();
+        throw;
+    }
+
+    _on_deduction = std::move(observer);
+    rollback_transaction();
+    return deductions.size();
+}
+
+size_t Reasoning::drop_cluster(const std::string& name)
+{
+    const size_t removed = Zelph::drop_cluster(name);
+    if (removed > 0) forget_removed_nodes();
+    return removed;
+}
+
+// Erases the session state kept for nodes that no longer exist. Fact nodes
+// are content-addressed, so a stale entry would otherwise apply to a fact
+// that is stated again later. As in revise, a defeat is lifted when a fact
+// or rule it rests on goes; the next run then starts with a classic pass,
+// which may deduce the assumption again.
+void Reasoning::forget_removed_nodes()
+{
+    auto gone = [this](const auto& entry)
+    { return !exists(entry.first); };
+    auto missing = [this](Node node)
+    { return !exists(node); };
+
+    bool lifted = false;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_network);
+        std::erase_if(_deduced_facts, [this](Node fact)
+                      { return !exists(fact); });
+        std::erase_if(_derivations, gone);
+        std::erase_if(_supports, gone);
+        std::erase_if(_validity, gone);
+        std::erase_if(_contexts, gone);
+        std::erase_if(_term_depths, gone);
+
+        // The assumption itself was withdrawn, so its parts are checked.
+        std::erase_if(_defeats, [&](const auto& entry)
+                      {
+            const Defeat& defeat = entry.second;
+            const bool    stale  = missing(defeat.rule) || (defeat.by != 0 && missing(defeat.by))
+                             || std::any_of(defeat.premises.begin(), defeat.premises.end(), missing)
+                             || missing(defeat.assumption.subject) || missing(defeat.assumption.predicate)
+                             || std::any_of(defeat.assumption.objects.begin(), defeat.assumption.objects.end(), missing);
+            lifted = lifted || stale;
+            return stale; });
+        std::erase_if(_pending_defeats, [this](Node fact)
+                      { return _defeats.count(fact) == 0; });
+    }
+    if (lifted) invalidate_incremental();
+    {
+        std::lock_guard<std::mutex> lock(_mtx_provenance);
+        std::erase_if(_provenance, gone);
+    }
+
+    std::erase_if(_disabled_rules, [this](Node rule)
+                  { return !exists(rule); });
+    std::erase_if(_rule_combinations, gone);
+    std::erase_if(_rule_weights, gone);
+    std::erase_if(_rule_contexts, gone);
+    std::erase_if(_rule_min_trust, gone);
+    std::erase_if(_rule_strata, gone);
+    std::erase_if(_default_rules, gone);
+    std::erase_if(_property_rules, [this](const PropertyRule& p)
+                  { return !exists(p.rule); });
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include <algorithm>
+#include <vector>
+
+using namespace zelph::network;
+
+void Reasoning::set_source_trust(const std::string& source, const double trust)
+{
+    if (source.empty())
+        throw std::runtime_error("Source name must not be empty");
+    if (!(trust >= 0 && trust <= 1))
+        throw std::runtime_error("Trust must be between 0 and 1");
+
+    _source_trust[source] = trust;
+    if (_query_cache) clear_query_cache();
+}
+
+double Reasoning::source_trust(const std::string& source) const
+{
+    auto it = _source_trust.find(source);
+    return it == _source_trust.end() ? 1 : it->second;
+}
+
+double Reasoning::trust(const Node fact) const
+{
+    if (!is_hash(fact) || !exists(fact))
+        throw std::runtime_error("Node " + std::to_string(fact) + " is not a fact");
+
+    std::unordered_set<Node> visited;
+    return fact_trust(fact, visited);
+}
+
+void Reasoning::set_min_trust(const double threshold)
+{
+    if (!(threshold >= 0 && threshold <= 1))
+        throw std::runtime_error("Trust threshold must be between 0 and 1");
+
+    _min_trust = threshold;
+}
+
+void Reasoning::set_rule_min_trust(const Node rule, const double threshold)
+{
+    if (get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+    if (!(threshold >= 0 && threshold <= 1))
+        throw std::runtime_error("Trust threshold must be between 0 and 1");
+
+    invalidate_incremental();
+    if (threshold == 0)
+        _rule_min_trust.erase(rule);
+    else
+        _rule_min_trust[rule] = threshold;
+}
+
+double Reasoning::rule_min_trust(const Node rule) const
+{
+    auto it = _rule_min_trust.find(rule);
+    return it == _rule_min_trust.end() ? 0 : it->second;
+}
+
+// Whether the facts matched by the condition are trusted enough for the
+// rule (0 for a query answer).
+bool Reasoning::premise_trust(const Node rule, const Node condition, const Variables& bindings) const
+{
+    const double threshold = std::max(_min_trust, rule_min_trust(rule));
+    if (threshold == 0) return true;
+
+    std::vector<Node> premises;
+    collect_premises(condition, bindings, premises);
+    std::unordered_set<Node> visited;
+    for (Node premise : premises)
+        if (fact_trust(premise, visited) < threshold) return false;
+    return true;
+}
+
+// A premise existed before the fact derived from it, so the visited set
+// only guards against truncated histories (see explain).
+double Reasoning::fact_trust(const Node fact, std::unordered_set<Node>& visited) const
+{
+    if (!visited.insert(fact).second) return 1;
+
+    auto derivation = _derivations.find(fact);
+    if (derivation != _derivations.end())
+    {
+        std::vector<Node> premises;
+        collect_premises(derivation->second.condition, derivation->second.bindings, premises);
+        double trust = 1;
+        for (Node premise : premises)
+            trust = std::min(trust, fact_trust(premise, visited));
+        return trust;
+    }
+
+    std::lock_guard<std::mutex> lock(_mtx_provenance);
+    auto                        it = _provenance.find(fact);
+    return it == _provenance.end() ? 1 : source_trust(it->second.source);
+}
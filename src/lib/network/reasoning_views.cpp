@@ -0,0 +1,277 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "reasoning.hpp"
+
+#include "contradiction_error.hpp"
+#include "unification.hpp"
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <stdexcept>
+
+using namespace zelph::network;
+
+void Reasoning::create_view(const std::string& name, const Node condition)
+{
+    if (name.empty()) throw std::runtime_error("A view needs a name");
+    if (!exists(condition)) throw std::runtime_error("Invalid view condition");
+
+    View v;
+    v.condition = condition;
+
+    std::unordered_set<Node> vars;
+    std::vector<Node>        history;
+    collect_variables(this, condition, vars, 1, history);
+    if (vars.empty()) throw std::runtime_error("The condition of view " + name + " has no variables");
+    v.variables.assign(vars.begin(), vars.end());
+    std::sort(v.variables.begin(), v.variables.end());
+
+    // The same classification as the rules of a semi-naive run: a view can
+    // be seeded by a new fact if every element of its condition is a plain
+    // leaf with a unique predicate that is looked up in the network.
+    v.excluded = std::make_shared<std::unordered_set<Node>>();
+    if (check_fact(condition, core.IsA, {core.Conjunction}).is_known())
+    {
+        for (Node rel : _pImpl->get_right(condition))
+        {
+            if (parse_relation(rel) != core.PartOf) continue;
+            adjacency_set objs;
+            Node          element = parse_fact(rel, objs);
+            if (element && objs.count(condition) == 1) v.elements.insert(element);
+        }
+        v.excluded->insert(condition);
+        for (Node e : v.elements)
+            v.excluded->insert(e);
+    }
+    else
+    {
+        v.elements.insert(condition);
+    }
+
+    const Node nn = get_node("nn", "zelph");
+    v.seedable    = !v.elements.empty() && !condition_contains_negation(condition, 1);
+    for (Node cond : v.elements)
+    {
+        if (!v.seedable) break;
+        if (check_fact(cond, core.IsA, {core.Conjunction}).is_known())
+        {
+            v.seedable = false;
+            break;
+        }
+
+        adjacency_set rels = filter(cond, core.IsA, core.RelationTypeCategory);
+        if (rels.size() != 1 || (nn != 0 && *rels.begin() == nn) || is_builtin(*rels.begin()))
+        {
+            v.seedable = false;
+            break;
+        }
+        const Node rel = *rels.begin();
+        if (!Zelph::Impl::is_var(rel) && rel == core.Unequal) continue; // a guard binds nothing
+
+        v.leaves.push_back(cond);
+        v.leaf_preds.push_back(Zelph::Impl::is_var(rel) ? Node{0} : rel);
+    }
+    if (v.leaves.empty()) v.seedable = false;
+
+    evaluate_view(v);
+    _views.insert_or_assign(name, std::move(v));
+    _views_active = true;
+}
+
+bool Reasoning::remove_view(const std::string& name)
+{
+    const bool dropped = _views.erase(name) == 1;
+    if (_views.empty())
+    {
+        _views_active = false;
+        std::lock_guard<std::mutex> lock(_mtx_view_delta);
+        _view_delta.clear();
+    }
+    return dropped;
+}
+
+std::vector<std::string> Reasoning::views() const
+{
+    std::vector<std::string> result;
+    for (const auto& [name, v] : _views)
+        result.push_back(name);
+    return result;
+}
+
+std::vector<Variables> Reasoning::view(const std::string& name)
+{
+    if (_views.count(name) == 0) throw std::runtime_error("Unknown view " + name);
+    refresh_views();
+
+    const View&            v = _views.at(name);
+    std::vector<Variables> result;
+    result.reserve(v.answers.size());
+    for (const auto& values : v.answers)
+    {
+        Variables& answer = result.emplace_back();
+        for (size_t i = 0; i < v.variables.size(); ++i)
+            if (values[i]) answer[v.variables[i]] = values[i];
+    }
+    return result;
+}
+
+// Called by the fact-creation observers while views are registered.
+void Reasoning::note_view_fact(const Node fact, const Node predicate)
+{
+    if (!_views_active) return;
+    std::lock_guard<std::mutex> lock(_mtx_view_delta);
+    _view_delta.emplace_back(fact, predicate);
+}
+
+// Brings every view up to date with the facts created since its last
+// refresh: seeded where possible, evaluated again otherwise.
+void Reasoning::refresh_views()
+{
+    std::vector<std::pair<Node, Node>> delta;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_view_delta);
+        delta.swap(_view_delta);
+    }
+
+    const uint64_t changes = _pImpl->unobserved_changes();
+    for (auto& [name, v] : _views)
+    {
+        if (v.changes != changes || (!v.seedable && !delta.empty()))
+        {
+            evaluate_view(v);
+            continue;
+        }
+        if (delta.empty()) continue;
+
+        std::vector<std::shared_ptr<Variables>> found;
+        const auto                              saved = _query_results;
+        _query_results                                = &found;
+        try
+        {
+            for (const auto& [fact, predicate] : delta)
+            {
+                // Like the scans of a query, variable relations are skipped.
+                if (Zelph::Impl::is_var(predicate) || !exists(fact)) continue;
+                for (size_t i = 0; i < v.leaves.size(); ++i)
+                    if (v.leaf_preds[i] == 0 || v.leaf_preds[i] == predicate) seed_view(v, i, fact, predicate);
+            }
+            _pool->wait();
+        }
+        catch (...)
+        {
+            _query_results = saved;
+            throw;
+        }
+        _query_results = saved;
+        collect_view_answers(v, found);
+    }
+
+    // Evaluating the views may itself have created facts (e.g. terms of
+    // the conditions); they are not answers.
+    std::lock_guard<std::mutex> lock(_mtx_view_delta);
+    _view_delta.clear();
+}
+
+void Reasoning::evaluate_view(View& v)
+{
+    v.answers.clear();
+    v.changes = _pImpl->unobserved_changes();
+    if (!exists(v.condition)) return;
+
+    std::vector<std::shared_ptr<Variables>> found;
+    const auto                              saved = _query_results;
+    _query_results                                = &found;
+    try
+    {
+        apply_rule(0, v.condition);
+    }
+    catch (...)
+    {
+        _query_results = saved;
+        throw;
+    }
+    _query_results = saved;
+    collect_view_answers(v, found);
+}
+
+// Binds one leaf of the view's condition to a new fact and evaluates the
+// remaining conditions with these bindings, as seed_rule does in a
+// semi-naive run; the answers go to the query collector.
+void Reasoning::seed_view(View& v, const size_t leaf, const Node fact, const Node predicate)
+{
+    const Node cond = v.leaves[leaf];
+
+    ReasoningContext ctx;
+    ctx.current_condition = v.condition;
+
+    auto        vars = std::make_shared<Variables>();
+    auto        uneq = std::make_shared<Variables>();
+    Unification u(this, cond, 0, vars, uneq, nullptr, 2, _prof, fact, predicate);
+
+    while (std::shared_ptr<Variables> match = u.Next())
+    {
+        if (std::any_of(match->begin(), match->end(), [&v](const auto& binding)
+                        { return v.excluded->count(binding.second) == 1; }))
+            continue;
+        if (contradicts(*match, *u.Unequals()) || match->empty()) continue;
+
+        adjacency_set remaining;
+        for (Node e : v.elements)
+            if (e != cond) remaining.insert(e);
+
+        if (remaining.empty())
+        {
+            report_answer(v.condition, 0, match, 1.0);
+            continue;
+        }
+
+        RulePos          pos({0, optimize_order(remaining, *match, 1), 0, match, u.Unequals(), v.excluded});
+        ReasoningContext ctx_copy = ctx;
+        try
+        {
+            evaluate(pos, ctx_copy, 1);
+        }
+        catch (const contradiction_error& error)
+        {
+            report_contradiction(error);
+        }
+    }
+    u.wait_for_completion();
+}
+
+void Reasoning::collect_view_answers(View& v, const std::vector<std::shared_ptr<Variables>>& found)
+{
+    for (const auto& bindings : found)
+    {
+        std::vector<Node> values(v.variables.size(), 0);
+        for (size_t i = 0; i < v.variables.size(); ++i)
+        {
+            const auto it = bindings->find(v.variables[i]);
+            if (it != bindings->end()) values[i] = it->second;
+        }
+        v.answers.insert(std::move(values));
+    }
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "adjacency_set.hpp"
+
+#include <cstdint>
+#include <unordered_map>
+#include <vector>
+
+namespace zelph::network
+{
+    // Observations bucketed like a Prometheus histogram: counts[i] holds the
+    // observations up to bounds[i] that exceed the previous bound, the last
+    // element those above all bounds.
+    struct Histogram
+    {
+        std::vector<double>   bounds{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10};
+        std::vector<uint64_t> counts = std::vector<uint64_t>(bounds.size() + 1, 0);
+        double                sum{0};
+        uint64_t              count{0};
+
+        void observe(double value);
+    };
+
+    // The counters of Reasoning::metrics, accumulated since metrics were
+    // enabled.
+    struct RunMetrics
+    {
+        uint64_t                           runs{0};
+        uint64_t                           deductions{0};
+        uint64_t                           contradictions{0};
+        std::unordered_map<Node, uint64_t> rule_firings; // new facts deduced, per rule
+        Histogram                          run_seconds;
+        Histogram                          query_seconds;
+    };
+
+    // How a rule fared in the last profiled run (see
+    // Reasoning::rule_profile): the matches of its condition that reached
+    // deduction, the new facts among their consequences and the time spent
+    // evaluating the rule, deductions included.
+    struct RuleProfile
+    {
+        Node     rule{0};
+        uint64_t firings{0};
+        uint64_t deductions{0};
+        double   seconds{0};
+    };
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <cstdint>
+
+namespace zelph::network
+{
+    // A progress report of Reasoning::run (see set_progress_observer). The
+    // counts cover the run so far.
+    struct RunProgress
+    {
+        int      iterations{0}; // fixpoint iterations started
+        uint64_t matches{0};    // rule condition matches processed, i.e. rule firings
+        uint64_t deductions{0}; // new facts deduced
+        double   seconds{0};
+        bool     finished{false}; // the final report of a run that completed or paused
+
+        double deductions_per_second() const { return seconds > 0 ? static_cast<double>(deductions) / seconds : 0; }
+    };
+}
@@ -28,6 +28,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "string/string_utils.hpp"
 #include "zelph_impl.hpp"
 
+#include <algorithm>
 #include <vector>
 
 using namespace zelph::network;
@@ -529,8 +530,14 @@ Unification::Unification(
 
     if (_relation_list.empty()) return;
 
-    // Always initialize sequential fallback
-    _relation_index         = _relation_list.begin();
+    // Always initialize sequential fallback. In deterministic mode the
+    // relations and their facts are scanned in ascending order of their
+    // IDs instead of the order of the hash sets holding them, which
+    // depends on the history of insertions and removals.
+    _ordered = _n->deterministic();
+    _relations.assign(_relation_list.begin(), _relation_list.end());
+    if (_ordered) std::sort(_relations.begin(), _relations.end());
+    _relation_pos           = 0;
     _fact_index_initialized = false;
 
     if (_seed_fact == 0 && _n->use_parallel())
@@ -586,6 +593,10 @@ Unification::Unification(
             u_log(_n, _log_depth, "DIAG: subject_is_bound=" + std::to_string(s_bound) + " object_is_bound=" + std::to_string(object_is_bound) + " relation_list_size=" + std::to_string(_relation_list.size()) + " subject=" + U_NODE(_subject) + " objects_size=" + std::to_string(_objects.size()));
         }
 
+        // A bound part only saves the scan if its orientation may be used.
+        if (!(_n->triple_indexes() & Zelph::SPO)) subject_is_bound = false;
+        if (!(_n->triple_indexes() & Zelph::OSP)) object_is_bound = false;
+
         if (_pool && _relation_variable == 0 && !subject_is_bound && !object_is_bound && !concurrency::tl_is_pool_worker)
         {
             Node fixed_rel = *_relation_list.begin();
@@ -612,6 +623,7 @@ Unification::Unification(
             {
                 _use_parallel = true;
                 _snapshot_vec.assign(snapshot.begin(), snapshot.end());
+                if (_ordered) std::sort(_snapshot_vec.begin(), _snapshot_vec.end());
 
                 if (_n->logging_active())
                 {
@@ -630,18 +642,25 @@ Unification::Unification(
                     u_log(_n, _log_depth, "parallel snapshot: " + std::to_string(_snapshot_vec.size()) + " candidate facts for relation " + U_NODE(fixed_rel));
                 }
 
-                size_t threads    = std::thread::hardware_concurrency();
+                // Each chunk collects its matches separately; Next() hands
+                // them out in chunk order once all chunks are done. The
+                // order of the matches, and with it the order in which
+                // deductions are inserted, is the order of _snapshot_vec,
+                // independent of the number of threads and their timing.
+                size_t threads    = std::max<size_t>(_pool->count(), 1);
                 size_t chunks     = std::min(threads * 4, snapshot.size());
                 size_t chunk_size = snapshot.size() / chunks;
                 _active_tasks     = chunks;
+                _chunk_matches.resize(chunks);
 
                 for (size_t c = 0; c < chunks; ++c)
                 {
                     size_t start = c * chunk_size;
                     size_t end   = (c + 1 == chunks) ? _snapshot_vec.size() : (c + 1) * chunk_size;
 
-                    _pool->enqueue([this, fixed_rel, start, end]()
+                    _pool->enqueue([this, fixed_rel, c, start, end]()
                                    {
+                                   auto& matches = _chunk_matches[c];
                                    uint64_t local_scanned = 0;
                                    for (size_t i = start; i < end; ++i)
                                    {
@@ -654,11 +673,7 @@ Unification::Unification(
                                            if (fs.predicate != fixed_rel) continue;
 
                                            for (auto& r : extract_bindings(fs.subject, fs.objects, fixed_rel, _log_depth))
-                                           {
-                                               std::lock_guard<std::mutex> l(_queue_mtx);
-                                               _match_queue.push(std::move(r));
-                                               _queue_cv.notify_one();
-                                           }
+                                               matches.push_back(std::move(r));
                                        }
                                    }
                                    if (_n->logging_active())
@@ -678,7 +693,7 @@ Unification::Unification(
 
 bool Unification::increment_fact_index()
 {
-    if (_relation_index == _relation_list.end())
+    if (_relation_pos == _relations.size())
     {
         return false;
     }
@@ -689,7 +704,8 @@ bool Unification::increment_fact_index()
         {
             // Check if the Subject or Object is already bound. If so, iterate only their connections.
             bool optimized_snapshot = false;
-            Node current_rel        = *_relation_index;
+            bool object_driven      = false;
+            Node current_rel        = _relations[_relation_pos];
 
             if (_seed_fact != 0)
             {
@@ -702,7 +718,7 @@ bool Unification::increment_fact_index()
                 _facts_snapshot.insert(_seed_fact);
                 optimized_snapshot = true;
             }
-            else if (_n->use_parallel())
+            else
             {
                 // Rule-template nodes exist in the graph. The subject/object-
                 // driven shortcut must not anchor on nodes that are themselves
@@ -740,16 +756,52 @@ bool Unification::increment_fact_index()
                     return true;
                 };
 
-                // Check if Subject is bound
+                // Pick the orientation with the fewest candidates. Adjacency
+                // sizes are known without copying, so the subject's and the
+                // object's fan-out can be weighed against the relation extent
+                // before anything is snapshotted: a bound object with a
+                // handful of facts beats a bound subject that is a hub, and a
+                // small relation beats both.
+                const uint8_t indexes = _n->triple_indexes();
+
                 Node s = _subject;
                 if (Zelph::Impl::is_var(s)) s = string::get(*_variables, s, s);
                 if (_subject_grounded != 0) s = _subject_grounded; // anchor on the grounded pattern node
-                if (is_concrete_lookup_node(s))
+
+                Node o = 0;
+                if (!_objects.empty())
+                {
+                    o = *_objects.begin();
+                    if (Zelph::Impl::is_var(o)) o = string::get(*_variables, o, o);
+                }
+
+                Node   anchor = 0;
+                size_t best   = _n->_pImpl->left_count_of(current_rel);
+                if ((indexes & Zelph::SPO) && is_concrete_lookup_node(s))
+                {
+                    const size_t count = _n->_pImpl->right_count_of(s);
+                    if (count <= best)
+                    {
+                        anchor = s;
+                        best   = count;
+                    }
+                }
+                if ((indexes & Zelph::OSP) && is_concrete_lookup_node(o))
                 {
-                    // Strategy: Subject Driven
-                    // Zelph::fact -> connect(Subject, Fact). Subject points to Fact (Source->Fact).
-                    // So get_right(Subject) contains the Facts involving this subject.
-                    adjacency_set candidates = _n->get_right(s);
+                    const size_t count = _n->_pImpl->right_count_of(o);
+                    if (anchor == 0 ? count <= best : count < best)
+                    {
+                        anchor        = o;
+                        best          = count;
+                        object_driven = true;
+                    }
+                }
+
+                if (anchor != 0)
+                {
+                    // Zelph::fact -> connect(Subject, Fact) and connect(Object, Fact),
+                    // so get_right(anchor) contains the facts the anchor takes part in.
+                    adjacency_set candidates = _n->get_right(anchor);
                     _facts_snapshot.clear();
                     // Filter candidates: we only want facts that are of type 'current_rel'
                     for (Node fact : candidates)
@@ -766,35 +818,7 @@ bool Unification::increment_fact_index()
                     optimized_snapshot = true;
                     if (_n->should_log(1) && _n->should_log(_log_depth - 1))
                     {
-                        u_log(_n, _log_depth, std::string("optimized_snapshot=") + (optimized_snapshot ? "YES" : "NO") + " rel=" + U_NODE(current_rel) + " subj=" + U_NODE(s) + (optimized_snapshot ? " size=" + std::to_string(_facts_snapshot.size()) : ""));
-                    }
-                }
-                // Check if Object is bound (if Subject wasn't)
-                else if (!_objects.empty())
-                {
-                    Node o = *_objects.begin();
-                    if (Zelph::Impl::is_var(o)) o = string::get(*_variables, o, o);
-
-                    if (is_concrete_lookup_node(o))
-                    {
-                        // Strategy: Object Driven
-                        // Zelph::fact -> connect(Object, Fact). Object points to Fact.
-                        adjacency_set candidates = _n->get_right(o);
-                        _facts_snapshot.clear();
-                        for (Node fact : candidates)
-                        {
-                            if (_n->has_left_edge(fact, current_rel)) continue;
-
-                            if (_n->has_right_edge(fact, current_rel))
-                            {
-                                _facts_snapshot.insert(fact);
-                            }
-                        }
-                        optimized_snapshot = true;
-                        if (_n->should_log(1) && _n->should_log(_log_depth - 1))
-                        {
-                            u_log(_n, _log_depth, std::string("optimized_snapshot=") + (optimized_snapshot ? "YES" : "NO") + " rel=" + U_NODE(current_rel) + " obj=" + U_NODE(o) + (optimized_snapshot ? " size=" + std::to_string(_facts_snapshot.size()) : ""));
-                        }
+                        u_log(_n, _log_depth, std::string("optimized_snapshot=YES rel=") + U_NODE(current_rel) + (object_driven ? " obj=" : " subj=") + U_NODE(anchor) + " size=" + std::to_string(_facts_snapshot.size()));
                     }
                 }
             }
@@ -828,16 +852,14 @@ bool Unification::increment_fact_index()
             {
                 if (_n->should_log(1) && _n->should_log(_log_depth - 1))
                 {
-                    u_log(_n, _log_depth, "increment_fact_index: " + std::to_string(_facts_snapshot.size()) + " candidate facts for relation " + U_NODE(*_relation_index));
+                    u_log(_n, _log_depth, "increment_fact_index: " + std::to_string(_facts_snapshot.size()) + " candidate facts for relation " + U_NODE(current_rel));
                 }
 
                 _prof.relation_snapshots.fetch_add(1, std::memory_order_relaxed);
                 _prof.snapshot_facts_total.fetch_add(_facts_snapshot.size(), std::memory_order_relaxed);
                 if (optimized_snapshot)
                 {
-                    // Heuristik: subject-driven wenn subject bound branch genutzt wurde, sonst object-driven
-                    // (hier nicht perfekt unterscheidbar ohne extra flag; wenn du willst, kann ich das sauber flaggen)
-                    _prof.snapshot_subject_driven.fetch_add(1, std::memory_order_relaxed);
+                    (object_driven ? _prof.snapshot_object_driven : _prof.snapshot_subject_driven).fetch_add(1, std::memory_order_relaxed);
                 }
                 else
                 {
@@ -846,14 +868,20 @@ bool Unification::increment_fact_index()
                 if (current_rel) _prof.note_relation_scan(current_rel, _facts_snapshot.size());
             }
 
-            _fact_index             = _facts_snapshot.begin(); // used to iterate over all facts that have relation type *_relation_index
+            if (_ordered)
+            {
+                _ordered_facts.assign(_facts_snapshot.begin(), _facts_snapshot.end());
+                std::sort(_ordered_facts.begin(), _ordered_facts.end());
+                _ordered_pos = 0;
+            }
+            _fact_index             = _facts_snapshot.begin(); // used to iterate over all facts that have relation type current_relation()
             _fact_index_initialized = true;
         }
-        else if (++_fact_index == _facts_snapshot.end()) // increment and return false if we reached the end, so _relation_index will be incremented
+        else if (_ordered ? ++_ordered_pos == _ordered_facts.size() : ++_fact_index == _facts_snapshot.end()) // increment and return false if we reached the end, so _relation_pos will be incremented
         {
             return false;
         }
-    } while (_n->has_left_edge(*_fact_index, *_relation_index)); // skip nodes that represent not relations of type *_relation_index, but relations having *_relation_index as subject (using bidirectional connection to the subject)
+    } while (_n->has_left_edge(current_fact(), current_relation())); // skip nodes that represent not relations of type current_relation(), but relations having current_relation() as subject (using bidirectional connection to the subject)
 
     return true;
 }
@@ -880,7 +908,14 @@ std::shared_ptr<Variables> Unification::Next()
     {
         std::unique_lock<std::mutex> lock(_queue_mtx);
         _queue_cv.wait(lock, [this]
-                       { return !_match_queue.empty() || _active_tasks == 0; });
+                       { return _active_tasks == 0; });
+        if (!_chunk_matches.empty())
+        {
+            for (auto& matches : _chunk_matches)
+                for (auto& match : matches)
+                    _match_queue.push(std::move(match));
+            _chunk_matches.clear();
+        }
         if (_match_queue.empty()) return nullptr;
         auto match = std::move(_match_queue.front());
         _match_queue.pop();
@@ -888,11 +923,11 @@ std::shared_ptr<Variables> Unification::Next()
     }
     else
     {
-        if (_relation_variable == 0 || string::get(*_variables, _relation_variable, *_relation_index) == *_relation_index)
+        if (_relation_variable == 0 || string::get(*_variables, _relation_variable, current_relation()) == current_relation())
         {
             while (increment_fact_index()) // iterate over all matching facts
             {
-                Node fact = *_fact_index;
+                Node fact = current_fact();
 
                 if (_n->logging_active())
                     _prof.facts_scanned_sequential.fetch_add(1, std::memory_order_relaxed);
@@ -912,9 +947,9 @@ std::shared_ptr<Variables> Unification::Next()
                 for (const auto& fs : structs)
                 {
                     // Filter: Ensure the interpretation matches the relation currently being scanned
-                    if (fs.predicate != *_relation_index) continue;
+                    if (fs.predicate != current_relation()) continue;
 
-                    for (auto& r : extract_bindings(fs.subject, fs.objects, *_relation_index, _log_depth))
+                    for (auto& r : extract_bindings(fs.subject, fs.objects, current_relation(), _log_depth))
                     {
                         if (!first)
                             first = std::move(r);
@@ -930,7 +965,7 @@ std::shared_ptr<Variables> Unification::Next()
             }
         }
 
-        if (++_relation_index == _relation_list.end()) return nullptr;
+        if (++_relation_pos == _relations.size()) return nullptr;
         _fact_index_initialized = false;
         return Next();
     }
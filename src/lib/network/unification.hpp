@@ -66,6 +66,8 @@ namespace zelph::network
 
     private:
         bool                                    increment_fact_index();
+        Node                                    current_relation() const { return _relations[_relation_pos]; }
+        Node                                    current_fact() const { return _ordered ? _ordered_facts[_ordered_pos] : *_fact_index; }
         std::vector<std::shared_ptr<Variables>> extract_bindings(const Node subject, const adjacency_set& objects, const Node relation, const int depth) const;
 
         Zelph* const               _n;
@@ -88,18 +90,23 @@ namespace zelph::network
         Node               _current_rel_ctx{};
 
         // Parallel mode
-        concurrency::ThreadPool*               _pool{nullptr};
-        bool                                   _use_parallel{false};
-        std::queue<std::shared_ptr<Variables>> _match_queue;
-        std::mutex                             _queue_mtx;
-        std::condition_variable                _queue_cv;
-        std::atomic<size_t>                    _active_tasks{0};
-        std::vector<Node>                      _snapshot_vec;
+        concurrency::ThreadPool*                             _pool{nullptr};
+        bool                                                 _use_parallel{false};
+        std::queue<std::shared_ptr<Variables>>               _match_queue;
+        std::mutex                                           _queue_mtx;
+        std::condition_variable                              _queue_cv;
+        std::atomic<size_t>                                  _active_tasks{0};
+        std::vector<Node>                                    _snapshot_vec;
+        std::vector<std::vector<std::shared_ptr<Variables>>> _chunk_matches; // per chunk, in snapshot order
 
         // Sequential fallback
-        adjacency_set::iterator _relation_index;
+        std::vector<Node>       _relations; // _relation_list, sorted in deterministic mode
+        size_t                  _relation_pos{0};
         adjacency_set::iterator _fact_index;
         adjacency_set           _facts_snapshot;
         bool                    _fact_index_initialized{false};
+        bool                    _ordered{false}; // Zelph::deterministic() at construction
+        std::vector<Node>       _ordered_facts;  // _facts_snapshot in ascending order (deterministic mode)
+        size_t                  _ordered_pos{0};
     };
 }
@@ -114,9 +114,10 @@ void Zelph::set_lang(const std::string& lang)
     }
 }
 
-Node Zelph::node(const std::string& name, std::string lang)
+Node Zelph::node(const std::string& raw_name, std::string lang)
 {
     if (lang.empty()) lang = _lang;
+    const std::string name = normalize_name(raw_name);
     if (name.empty())
     {
         throw std::invalid_argument("Zelph::node(): name cannot be empty");
@@ -172,6 +173,7 @@ Node Zelph::node(const std::string& name, std::string lang)
     Node new_node = _pImpl->create();
 
     std::unique_lock lock_name(_pImpl->_mtx_name_of_node);
+    ++_pImpl->_names_version;
 
     auto [reverse_outer_it, inserted_reverse_outer] = _pImpl->_node_of_name.try_emplace(lang);
     auto [forward_outer_it, inserted_forward_outer] = _pImpl->_name_of_node.try_emplace(lang);
@@ -186,6 +188,13 @@ Node Zelph::node(const std::string& name, std::string lang)
     return new_node;
 }
 
+// Creates a node without a name, e.g. for RDF blank nodes. It is
+// reachable only through the facts it takes part in.
+Node Zelph::create_node()
+{
+    return _pImpl->create();
+}
+
 bool Zelph::exists(uint64_t nd) const
 {
     return _pImpl->exists(nd);
@@ -482,6 +491,10 @@ Node Zelph::fact(const Node subject, const Node predicate, const adjacency_set&
         {
             throw std::runtime_error("fact(): this fact is known to be true");
         }
+        else if (answer.is_correct() && _on_fact_restated)
+        {
+            _on_fact_restated(answer.relation());
+        }
     }
     else
     {
@@ -536,6 +549,7 @@ Node Zelph::fact(const Node subject, const Node predicate, const adjacency_set&
         }
         _pImpl->connect(answer.relation(), predicate, probability);
 
+        if (predicate == core.IsA && probability >= 0.5L) extend_hierarchy(subject, objects);
         if (_on_fact_created) _on_fact_created(answer.relation(), predicate);
     }
 
@@ -636,6 +650,11 @@ void Zelph::set_fact_creation_observer(FactCreationObserver observer)
     _on_fact_created = std::move(observer);
 }
 
+void Zelph::set_fact_restatement_observer(FactRestatementObserver observer)
+{
+    _on_fact_restated = std::move(observer);
+}
+
 /**
  * Builds a Lisp-style singly linked list from a vector of Node elements using cons cells.
  *
@@ -28,10 +28,13 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "answer.hpp"
 #include "fact_structure_types.hpp"
 #include "io/output.hpp"
+#include "name_index.hpp"
 #include "network.hpp"
+#include "string/string_utils.hpp"
 
 #include <zelph_export.h>
 
+#include <atomic>
 #include <functional>
 #include <string>
 #include <unordered_map>
@@ -67,6 +70,18 @@ namespace zelph::network
             bool                  route_name_explicit   = false;
         };
 
+        // Orientations the matcher may use to find the candidate facts of
+        // a condition: from its subject (SPO), from its relation (POS) or
+        // from its object (OSP). All three read the adjacency the network
+        // keeps anyway. POS is always enabled, since it is the fallback
+        // for conditions without a usable subject or object.
+        enum TripleIndex : uint8_t
+        {
+            SPO = 1,
+            POS = 2,
+            OSP = 4
+        };
+
         explicit Zelph(const io::OutputHandler& output = io::default_output_handler);
         ~Zelph();
 
@@ -109,6 +124,7 @@ namespace zelph::network
         std::string          get_lang() const { return _lang; }
         std::string          lang() const { return _lang; }
         Node                 node(const std::string& name, std::string lang = "");
+        Node                 create_node();
         bool                 exists(uint64_t nd) const;
         adjacency_set        get_sources(Node relationType, Node target, bool exclude_vars = false) const;
         adjacency_set        get_fact_objects(Node subject, Node predicate) const;
@@ -157,6 +173,11 @@ namespace zelph::network
         void                 log(int depth, const std::string& category, const std::string& message) const;
         bool                 use_parallel() const { return _use_parallel; }
         void                 toggle_parallel() { _use_parallel = !_use_parallel; }
+        uint8_t              triple_indexes() const { return _triple_indexes; }
+        void                 set_triple_indexes(uint8_t indexes) { _triple_indexes = indexes | POS; }
+        // Deterministic mode (see Reasoning::set_deterministic): the matcher
+        // visits candidate facts in ascending order of their IDs.
+        bool                 deterministic() const { return _deterministic; }
         void                 set_synapse(const Node from, const Node to, const double weight) const;
         bool                 has_synapse(const Node from, const Node to) const;
         double               edge_weight(Node from, Node to, double fallback = 1.0) const;
@@ -182,6 +203,82 @@ namespace zelph::network
         void add_verbose_selffact_predicates(const std::vector<Node>& preds);
         bool selffact_sugar_suppressed(Node pred) const;
 
+        // --- Paths between nodes (implemented in zelph_paths.cpp) ---
+        // How two nodes are connected: simple paths over the statements of
+        // the network (rules, predicate declarations and facts with
+        // variables are skipped), shortest first. A step follows a
+        // statement from its subject to an object (forward) or, unless
+        // directed is set, from an object back to its subject. relations
+        // limits the predicates that may be followed (empty: all).
+        // max_depth bounds the number of steps (0: no bound). The search
+        // gives up after a fixed budget of partial paths, returning the
+        // paths found so far.
+        struct PathOptions
+        {
+            size_t        max_paths{1};
+            size_t        max_depth{6};
+            adjacency_set relations;
+            bool          directed{false};
+        };
+        struct PathStep
+        {
+            Node fact;
+            Node from;
+            Node predicate;
+            Node to;
+            bool forward;
+        };
+        using Path = std::vector<PathStep>;
+        std::vector<Path> find_paths(Node from, Node to, const PathOptions& options) const;
+
+        // --- Is-a hierarchy (implemented in zelph_hierarchy.cpp) ---
+        // Whether a node is a kind of a category, and all nodes that are,
+        // without applying rules: the transitive closure of the is-a
+        // statements (~) is materialized into an index of the ancestors and
+        // descendants of every node in the hierarchy, so that is_kind_of is
+        // a lookup and instances_of costs its result. A chain of one or more
+        // statements counts; statements with variables and those known to
+        // be wrong do not. The index is built by the first query and kept
+        // up to date by fact() as is-a statements are added; removals and
+        // loads make the next query rebuild it. kinds_of returns the
+        // categories of a node.
+        bool          is_kind_of(Node node, Node category) const;
+        adjacency_set kinds_of(Node node) const;
+        adjacency_set instances_of(Node category) const;
+
+        // --- Embeddings (implemented in zelph_embeddings.cpp) ---
+        // A vector of numbers per node, e.g. from a language model, so that
+        // symbolic reasoning can be combined with semantic similarity. All
+        // vectors have the dimension of the first one stored (0 again once
+        // none is left); set_embedding throws for a node that does not
+        // exist, an empty vector or one of another dimension.
+        // similar_nodes returns the k nodes whose vectors are closest to the
+        // given one (or to that of node, which is left out) by cosine
+        // similarity, best first, ties by node ID; it throws if node has no
+        // vector or the given one is zero or of another dimension. Vectors
+        // of zero length are never similar. Session state (cleared by load,
+        // not persisted).
+        void                                 set_embedding(Node node, std::vector<float> values) const;
+        bool                                 remove_embedding(Node node) const;
+        std::vector<float>                   embedding(Node node) const;
+        size_t                               embedding_count() const;
+        size_t                               embedding_dimension() const;
+        std::vector<std::pair<Node, double>> similar_nodes(Node node, size_t k) const;
+        std::vector<std::pair<Node, double>> similar_nodes(const std::vector<float>& values, size_t k) const;
+
+        // --- Name normalization ---
+        // Names are normalized whenever they are assigned or looked up
+        // (node, get_node, set_name, resolve_nodes_by_name): to Unicode NFC
+        // by default, so that an "é" typed precomposed or as "e" followed by
+        // a combining accent names the same node, and case-folded if
+        // enabled. Names stored before a change keep their form. Session
+        // state (cleared by .reset, not persisted).
+        void                           set_name_normalization(string::unicode::Normalization form) { _name_normalization = form; }
+        string::unicode::Normalization name_normalization() const { return _name_normalization; }
+        void                           set_case_folding(bool enabled) { _case_folding = enabled; }
+        bool                           case_folding() const { return _case_folding; }
+        std::string                    normalize_name(const std::string& name) const;
+
         // --- Fact-creation observer (semi-naive evaluation) ---
         // Invoked from fact() exactly when a NEW fact node is materialized
         // (never for pre-existing facts). Reasoning::run uses it to capture
@@ -193,6 +290,12 @@ namespace zelph::network
         using FactCreationObserver = std::function<void(Node relation, Node predicate)>;
         void set_fact_creation_observer(FactCreationObserver observer);
 
+        // Invoked from fact() when a fact that already exists is stated
+        // again as true. Reasoning uses it to turn a deduced fact into a
+        // stated one. Empty by default.
+        using FactRestatementObserver = std::function<void(Node relation)>;
+        void set_fact_restatement_observer(FactRestatementObserver observer);
+
         // --- Implemented in zelph_names.cpp (name management) ---
 
         void                     set_name(Node node, const std::string& name, std::string lang, bool merge_on_conflict);
@@ -216,14 +319,32 @@ namespace zelph::network
         size_t                   get_node_of_name_size(const std::string& lang) const;
         size_t                   language_count() const;
 
+        // Names of lang (the current language if empty) matching query, see
+        // NameIndex::search; the query is folded like the names. The index
+        // is built by the first search and rebuilt by the first one after
+        // a name changed, so that searches do not walk all names.
+        std::vector<NameIndex::Match> search_names(const std::string& query, size_t limit, bool fuzzy, std::string lang = "") const;
+
         // --- Implemented in zelph_maintenance.cpp (cleanup, rules, persistence) ---
 
         void          cleanup_isolated(size_t& removed_count) const;
         size_t        cleanup_names() const;
         void          remove_node(Node node) const;
         adjacency_set get_rules() const;
+        void          remove_rule(Node rule) const;
         void          remove_rules() const;
         size_t        rule_count() const;
+
+        // Merges the concept from into the concept into (owl:sameAs): every
+        // fact that mentions from, and in turn every fact that mentions such
+        // a fact (qualifiers, rules), is restated with into in its place and
+        // removed, keeping its probability. into keeps its names, those of
+        // from become aliases that resolve to into. Returns the removed facts,
+        // each mapped to the fact that replaces it. Throws std::runtime_error
+        // unless both are existing concepts (neither facts nor variables),
+        // for a core node as from, and if a fact of from contradicts one of
+        // into; the network is unchanged then.
+        std::unordered_map<Node, Node> merge_concepts(Node from, Node into);
         void          save_to_file(const std::string& filename) const;
         void          load_from_file(const std::string& filename) const;
         void          load_from_file(const std::string& filename, const BinChunkSelection& selection, bool skip_payload = false) const;
@@ -233,6 +354,13 @@ namespace zelph::network
                                          const std::string&       bin_path_override = "",
                                          bool                     skip_payload      = false) const;
 
+        // True once anything was loaded into the network from a .bin file or
+        // manifest. Such files do not record which facts were deduced. A load
+        // that fails before it changes the network, e.g. because the file
+        // cannot be opened, leaves it as it was; .new starts a network
+        // without it.
+        bool loaded_from_file() const { return _loaded_from_file.load(std::memory_order_relaxed); }
+
         void                                        set_active_cluster(const std::string& name) const;
         void                                        deactivate_cluster() const;
         std::string                                 active_cluster_name() const;
@@ -260,14 +388,29 @@ namespace zelph::network
         } core;
 
     protected:
+        // Is-a index (zelph_hierarchy.cpp): read_hierarchy (re)builds it as
+        // needed and returns it locked for reading; extend_hierarchy adds a
+        // new is-a statement to a built index.
+        struct Hierarchy;
+        std::shared_lock<std::shared_mutex> read_hierarchy() const;
+        void                                extend_hierarchy(Node subject, const adjacency_set& categories) const;
+
         std::string                                               _lang{"en"};
         std::unordered_map<network::Node, std::string>            _core_names_by_node;
         std::unordered_map<std::string, network::Node>            _core_names_by_name;
         bool                                                      _use_parallel{true};
+        uint8_t                                                   _triple_indexes{SPO | POS | OSP};
+        bool                                                      _deterministic{false};
         std::shared_ptr<const std::unordered_map<Node, uint32_t>> _number_digits;
         mutable std::shared_mutex                                 _smtx_number_digits;
         std::unordered_set<Node>                                  _verbose_selffact_preds;
         mutable std::shared_mutex                                 _smtx_verbose_selffact_preds;
         FactCreationObserver                                      _on_fact_created;
+        FactRestatementObserver                                   _on_fact_restated;
+        mutable std::atomic<bool>                                 _loaded_from_file{false};
+        string::unicode::Normalization                            _name_normalization{string::unicode::Normalization::NFC};
+        bool                                                      _case_folding{false};
+        mutable std::shared_ptr<Hierarchy>                        _hierarchy;
+        mutable std::shared_mutex                                 _smtx_hierarchy;
     };
 }
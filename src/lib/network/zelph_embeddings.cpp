@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "zelph.hpp"
+
+#include "zelph_impl.hpp"
+
+#include <algorithm>
+#include <cmath>
+#include <mutex>
+#include <shared_mutex>
+#include <stdexcept>
+
+using namespace zelph::network;
+
+namespace
+{
+    double norm(const std::vector<float>& values)
+    {
+        double sum = 0;
+        for (float v : values)
+            sum += static_cast<double>(v) * v;
+        return std::sqrt(sum);
+    }
+}
+
+void Zelph::set_embedding(const Node node, std::vector<float> values) const
+{
+    if (!_pImpl->exists(node))
+        throw std::runtime_error("Cannot set the embedding of non-existent node " + std::to_string(node));
+    if (values.empty())
+        throw std::runtime_error("An embedding needs at least one value");
+
+    std::unique_lock lock(_pImpl->_mtx_embeddings);
+    const bool replaces_only = _pImpl->_embeddings.size() == 1 && _pImpl->_embeddings.contains(node);
+    if (_pImpl->_embedding_dimension != 0 && !replaces_only && values.size() != _pImpl->_embedding_dimension)
+        throw std::runtime_error("Embedding of dimension " + std::to_string(values.size()) + ", the stored embeddings have dimension "
+                                 + std::to_string(_pImpl->_embedding_dimension));
+
+    _pImpl->_embedding_dimension = values.size();
+    _pImpl->_embeddings[node]    = std::move(values);
+}
+
+bool Zelph::remove_embedding(const Node node) const
+{
+    std::unique_lock lock(_pImpl->_mtx_embeddings);
+    const bool       removed = _pImpl->_embeddings.erase(node) == 1;
+    if (_pImpl->_embeddings.empty()) _pImpl->_embedding_dimension = 0;
+    return removed;
+}
+
+std::vector<float> Zelph::embedding(const Node node) const
+{
+    std::shared_lock lock(_pImpl->_mtx_embeddings);
+    auto             it = _pImpl->_embeddings.find(node);
+    return it == _pImpl->_embeddings.end() ? std::vector<float>{} : it->second;
+}
+
+size_t Zelph::embedding_count() const
+{
+    std::shared_lock lock(_pImpl->_mtx_embeddings);
+    return _pImpl->_embeddings.size();
+}
+
+size_t Zelph::embedding_dimension() const
+{
+    std::shared_lock lock(_pImpl->_mtx_embeddings);
+    return _pImpl->_embedding_dimension;
+}
+
+std::vector<std::pair<Node, double>> Zelph::similar_nodes(const Node node, const size_t k) const
+{
+    std::vector<float> values = embedding(node);
+    if (values.empty())
+        throw std::runtime_error("Node " + std::to_string(node) + " has no embedding");
+
+    // One more, in case node itself is among the best.
+    auto result = similar_nodes(values, k + 1);
+    result.erase(std::remove_if(result.begin(), result.end(), [node](const auto& entry)
+                                { return entry.first == node; }),
+                 result.end());
+    if (result.size() > k) result.resize(k);
+    return result;
+}
+
+std::vector<std::pair<Node, double>> Zelph::similar_nodes(const std::vector<float>& values, const size_t k) const
+{
+    const double query_norm = norm(values);
+    if (query_norm == 0)
+        throw std::runtime_error("Cannot compare with an embedding of zero length");
+
+    std::vector<std::pair<Node, double>> result;
+    {
+        std::shared_lock lock(_pImpl->_mtx_embeddings);
+        if (values.size() != _pImpl->_embedding_dimension)
+            throw std::runtime_error("Embedding of dimension " + std::to_string(values.size()) + ", the stored embeddings have dimension "
+                                     + std::to_string(_pImpl->_embedding_dimension));
+
+        result.reserve(_pImpl->_embeddings.size());
+        for (const auto& [candidate, vector] : _pImpl->_embeddings)
+        {
+            const double candidate_norm = norm(vector);
+            if (candidate_norm == 0 || !_pImpl->exists(candidate)) continue;
+
+            double dot = 0;
+            for (size_t i = 0; i < vector.size(); ++i)
+                dot += static_cast<double>(values[i]) * vector[i];
+            result.emplace_back(candidate, dot / (query_norm * candidate_norm));
+        }
+    }
+
+    auto better = [](const auto& a, const auto& b)
+    {
+        return a.second != b.second ? a.second > b.second : a.first < b.first;
+    };
+    if (result.size() > k)
+    {
+        std::partial_sort(result.begin(), result.begin() + static_cast<std::ptrdiff_t>(k), result.end(), better);
+        result.resize(k);
+    }
+    else
+    {
+        std::sort(result.begin(), result.end(), better);
+    }
+    return result;
+}
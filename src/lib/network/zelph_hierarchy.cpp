@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "zelph.hpp"
+
+#include "zelph_impl.hpp"
+
+using namespace zelph::network;
+
+// The transitive closure of the is-a statements in both directions. A node
+// is only present once it takes part in a statement.
+struct Zelph::Hierarchy
+{
+    std::unordered_map<Node, adjacency_set> ancestors;   // node -> categories it is a kind of
+    std::unordered_map<Node, adjacency_set> descendants; // category -> its instances
+    uint64_t                                changes{0};  // unobserved_changes() the index reflects
+
+    // Every node below or equal to subject becomes a kind of category and
+    // everything above it.
+    void add(const Node subject, const Node category)
+    {
+        if (ancestors[subject].contains(category)) return;
+
+        adjacency_set up{category};
+        for (const Node a : ancestors[category])
+            up.insert(a);
+        adjacency_set down{subject};
+        for (const Node d : descendants[subject])
+            down.insert(d);
+
+        for (const Node d : down)
+        {
+            adjacency_set& target = ancestors[d];
+            for (const Node a : up)
+                target.insert(a);
+        }
+        for (const Node a : up)
+        {
+            adjacency_set& target = descendants[a];
+            for (const Node d : down)
+                target.insert(d);
+        }
+    }
+};
+
+std::shared_lock<std::shared_mutex> Zelph::read_hierarchy() const
+{
+    for (;;)
+    {
+        {
+            std::shared_lock lock(_smtx_hierarchy);
+            if (_hierarchy && _hierarchy->changes == _pImpl->unobserved_changes()) return lock;
+        }
+
+        std::unique_lock lock(_smtx_hierarchy);
+        const uint64_t   changes = _pImpl->unobserved_changes();
+        if (_hierarchy && _hierarchy->changes == changes) continue;
+
+        auto          hierarchy = std::make_shared<Hierarchy>();
+        adjacency_set objects;
+        for (const Node f : _pImpl->get_left(core.IsA))
+        {
+            objects.clear();
+            const Node subject = parse_fact(f, objects);
+            if (!subject || Impl::is_var(subject) || parse_relation(f) != core.IsA) continue;
+            if (_pImpl->probability(f, core.IsA) < 0.5L) continue;
+            for (const Node o : objects)
+                if (!Impl::is_var(o)) hierarchy->add(subject, o);
+        }
+        hierarchy->changes = changes;
+        _hierarchy         = std::move(hierarchy);
+    }
+}
+
+void Zelph::extend_hierarchy(const Node subject, const adjacency_set& categories) const
+{
+    if (Impl::is_var(subject)) return;
+
+    std::unique_lock lock(_smtx_hierarchy);
+    if (!_hierarchy || _hierarchy->changes != _pImpl->unobserved_changes()) return; // rebuilt by the next query
+
+    for (const Node c : categories)
+        if (!Impl::is_var(c)) _hierarchy->add(subject, c);
+}
+
+bool Zelph::is_kind_of(const Node node, const Node category) const
+{
+    const auto lock = read_hierarchy();
+    const auto it   = _hierarchy->ancestors.find(node);
+    return it != _hierarchy->ancestors.end() && it->second.contains(category);
+}
+
+adjacency_set Zelph::kinds_of(const Node node) const
+{
+    const auto lock = read_hierarchy();
+    const auto it   = _hierarchy->ancestors.find(node);
+    return it == _hierarchy->ancestors.end() ? adjacency_set{} : it->second;
+}
+
+adjacency_set Zelph::instances_of(const Node category) const
+{
+    const auto lock = read_hierarchy();
+    const auto it   = _hierarchy->descendants.find(category);
+    return it == _hierarchy->descendants.end() ? adjacency_set{} : it->second;
+}
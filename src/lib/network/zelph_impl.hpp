@@ -34,6 +34,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
     #include <kj/io.h>
 #endif
 
+#include "name_index.hpp"
 #include "network.hpp"
 #include "zelph.hpp"
 
@@ -43,6 +44,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include <cstdint>
 #include <cstdlib>
 #include <cstring>
+#include <memory>
 #include <mutex>
 #include <string>
 #include <string_view>
@@ -229,7 +231,11 @@ namespace zelph::network
             std::unique_lock<std::shared_mutex> lock_weights(_mtx_weights);
             std::unique_lock<std::shared_mutex> lock_node_name(_mtx_node_of_name);
             std::unique_lock<std::shared_mutex> lock_name_node(_mtx_name_of_node);
+            std::unique_lock<std::shared_mutex> lock_embeddings(_mtx_embeddings);
+            ++_names_version;
 
+            _embeddings.clear();
+            _embedding_dimension = 0;
             _left.clear();
             _right.clear();
             _weights.clear();
@@ -238,8 +244,24 @@ namespace zelph::network
             _string_pool.clear();
         }
 
+        // Rejects files that are no zelph networks or were written by a newer
+        // format version; files from before versioning have neither field.
+        static void checkFormat(const ZelphImpl::Reader& impl)
+        {
+            if (impl.hasMagic() && impl.getMagic() != BIN_MAGIC.get())
+            {
+                throw std::runtime_error("Not a zelph network file");
+            }
+            if (impl.getFormatVersion() > BIN_FORMAT_VERSION)
+            {
+                throw std::runtime_error("The file has format version " + std::to_string(impl.getFormatVersion())
+                                         + ", this zelph reads up to version " + std::to_string(BIN_FORMAT_VERSION) + "; please upgrade zelph");
+            }
+        }
+
         void loadSmallData(const ZelphImpl::Reader& impl)
         {
+            checkFormat(impl);
     #ifdef CLEAR_ON_LOAD
             _weights.clear();
     #endif
@@ -332,6 +354,7 @@ namespace zelph::network
                                   uint32_t                        nameOfNodeChunkCount,
                                   const detail::chunk_selector*   selection = nullptr)
         {
+            ++_names_version;
     #ifdef CLEAR_ON_LOAD
             _name_of_node.clear();
             _string_pool.clear();
@@ -376,6 +399,7 @@ namespace zelph::network
                                   uint32_t                        nodeOfNameChunkCount,
                                   const detail::chunk_selector*   selection = nullptr)
         {
+            ++_names_version;
     #ifdef CLEAR_ON_LOAD
             _node_of_name.clear();
     #endif
@@ -485,6 +509,7 @@ namespace zelph::network
                                          uint64_t                      source_offset,
                                          const detail::chunk_selector* selection)
         {
+            ++_names_version;
             FILE* file = detail::open_file_or_throw(source_path);
             try
             {
@@ -539,6 +564,7 @@ namespace zelph::network
                                          uint64_t                      source_offset,
                                          const detail::chunk_selector* selection)
         {
+            ++_names_version;
             FILE* file = detail::open_file_or_throw(source_path);
             try
             {
@@ -1036,6 +1062,8 @@ namespace zelph::network
             }
             impl.setLast(_last);
             impl.setLastVar(_last_var);
+            impl.setMagic(BIN_MAGIC.get());
+            impl.setFormatVersion(BIN_FORMAT_VERSION);
 
             size_t nameOfNodeChunkTotal = 0;
             for (const auto& langMap : _name_of_node)
@@ -1340,6 +1368,7 @@ namespace zelph::network
             // PRECONDITION:
             // caller holds _mtx_node_of_name and _mtx_name_of_node exclusively,
             // always in this order: _mtx_node_of_name -> _mtx_name_of_node
+            ++_names_version;
 
             for (auto& [lang, forward] : _name_of_node)
             {
@@ -1437,6 +1466,7 @@ namespace zelph::network
             // PRECONDITION:
             // caller holds _mtx_node_of_name and _mtx_name_of_node exclusively
             // in this order: _mtx_node_of_name -> _mtx_name_of_node
+            ++_names_version;
 
             auto [rev_outer_it, rev_outer_inserted] = _node_of_name.try_emplace(lang);
             auto [fwd_outer_it, fwd_outer_inserted] = _name_of_node.try_emplace(lang);
@@ -1506,6 +1536,7 @@ namespace zelph::network
             // PRECONDITION:
             // caller holds _mtx_node_of_name and _mtx_name_of_node exclusively
             // in this order: _mtx_node_of_name -> _mtx_name_of_node
+            ++_names_version;
 
             auto fwd_outer_it = _name_of_node.find(lang);
             if (fwd_outer_it == _name_of_node.end())
@@ -1539,6 +1570,7 @@ namespace zelph::network
 
         size_t cleanup_dangling_names()
         {
+            ++_names_version;
             size_t removed_count = 0;
 
             ankerl::unordered_dense::set<Node> valid_nodes;
@@ -1600,6 +1632,7 @@ namespace zelph::network
         {
             std::unique_lock lock1(_mtx_node_of_name);
             std::unique_lock lock2(_mtx_name_of_node);
+            ++_names_version;
 
             // Remove forward mappings (node → name) in all languages
             for (auto& lang_map : _name_of_node)
@@ -1993,6 +2026,19 @@ namespace zelph::network
 
         mutable std::shared_mutex    _mtx_node_of_name;
         mutable std::shared_mutex    _mtx_name_of_node;
+
+        // Bumped by every change of the name maps; a NameIndex built for an
+        // older version is rebuilt by the next search (see Zelph::search_names).
+        using NameIndexSlot = std::pair<uint64_t, std::shared_ptr<const NameIndex>>; // (names version, index)
+        std::atomic<uint64_t>                                            _names_version{0};
+        mutable std::mutex                                               _mtx_name_indexes;
+        mutable ankerl::unordered_dense::map<std::string, NameIndexSlot> _name_indexes;
+
+        // Embedding vectors (see Zelph::set_embedding), all of
+        // _embedding_dimension values.
+        ankerl::unordered_dense::map<Node, std::vector<float>> _embeddings;
+        size_t                                                 _embedding_dimension{0};
+        mutable std::shared_mutex                              _mtx_embeddings;
         mutable std::recursive_mutex _mtx_print;
 
         mutable std::shared_mutex                                              _fs_cache_mtx;
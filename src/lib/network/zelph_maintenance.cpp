@@ -27,6 +27,8 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #include "zelph_impl.hpp"
 
+#include <deque>
+
 using namespace zelph::network;
 
 void Zelph::cleanup_isolated(size_t& removed_count) const
@@ -54,6 +56,7 @@ void Zelph::remove_node(Node node) const
 
     _pImpl->remove(node);            // Disconnects edges and removes from adjacency maps
     _pImpl->remove_node_names(node); // Separate method for name cleanup
+    remove_embedding(node);
 }
 
 // Returns all nodes that are subjects of a core.Causes relation
@@ -81,6 +84,17 @@ adjacency_set Zelph::get_rules() const
     return rules;
 }
 
+void Zelph::remove_rule(const Node rule) const
+{
+    if (get_rules().count(rule) == 0)
+        throw std::runtime_error("Node " + std::to_string(rule) + " is not a rule");
+
+    invalidate_fact_structures_cache();
+
+    _pImpl->remove(rule);
+    _pImpl->remove_node_names(rule);
+}
+
 void Zelph::remove_rules() const
 {
     adjacency_set rules = get_rules();
@@ -90,6 +104,7 @@ void Zelph::remove_rules() const
 
         _pImpl->remove(rule);
         // Clean up names
+        ++_pImpl->_names_version;
         for (auto& lang_map : _pImpl->_name_of_node)
         {
             lang_map.second.erase(rule);
@@ -116,7 +131,149 @@ size_t Zelph::rule_count() const
     return get_rules().size();
 }
 
+std::unordered_map<Node, Node> Zelph::merge_concepts(const Node from, const Node into)
+{
+    for (Node n : {from, into})
+        if (!_pImpl->exists(n) || Impl::is_hash(n) || Impl::is_var(n))
+            throw std::runtime_error("Node " + std::to_string(n) + " is not a concept");
+    if (from == into) return {};
+    if (!get_core_name(from).empty())
+        throw std::runtime_error("Core node " + get_core_name(from) + " cannot be merged into another node");
+
+    // The parts of a fact, or false if the node is not a fact.
+    auto parts = [this](Node f, Node& subject, Node& predicate, adjacency_set& objects)
+    {
+        objects.clear();
+        subject   = parse_fact(f, objects);
+        predicate = parse_relation(f);
+        return subject && predicate && !objects.empty();
+    };
+
+    auto mentions = [this](Node n)
+    {
+        adjacency_set result = _pImpl->get_right(n); // as subject or object
+        for (Node f : _pImpl->get_left(n))           // as predicate
+            result.insert(f);
+        return result;
+    };
+
+    std::unordered_map<Node, Node> replacement{{from, into}};
+    auto                           replaced = [&replacement](Node n)
+    {
+        const auto it = replacement.find(n);
+        return it == replacement.end() ? n : it->second;
+    };
+
+    // A fact of from that contradicts one of into would be stated as both
+    // true and false; nothing is changed in that case.
+    Node          subject, predicate;
+    adjacency_set objects;
+    for (Node f : mentions(from))
+    {
+        if (!Impl::is_hash(f) || !parts(f, subject, predicate, objects)) continue;
+        adjacency_set restated;
+        for (Node o : objects)
+            restated.insert(replaced(o));
+        const Answer answer = check_fact(replaced(subject), replaced(predicate), restated);
+        if (answer.is_known() && answer.is_wrong() != (_pImpl->probability(f, predicate) < 0.5L))
+            throw std::runtime_error("Cannot merge " + get_name(from, _lang, true) + " into " + get_name(into, _lang, true)
+                                     + ": a fact about one contradicts a fact about the other");
+    }
+
+    // Breadth-first over the mentions: a restated fact is replaced in turn
+    // in the facts that mention it. A replacement that still mentions an
+    // old node (a fact mentioning two of them) is itself restated later.
+    std::deque<Node>  queue{from};
+    std::vector<Node> removed;
+    while (!queue.empty())
+    {
+        const Node n = queue.front();
+        queue.pop_front();
+
+        for (Node f : mentions(n))
+        {
+            if (!Impl::is_hash(f) || replacement.count(f) == 1 || !parts(f, subject, predicate, objects)) continue;
+            if (subject != n && predicate != n && objects.count(n) == 0) continue;
+
+            // A fact that cannot be restated, e.g. (a p b, c) as (b p b, c),
+            // is dropped along with the facts that mention it.
+            adjacency_set restated;
+            for (Node o : objects)
+                restated.insert(replaced(o));
+            Node r = 0;
+            if (replaced(subject) && replaced(predicate) && restated.count(0) == 0)
+            {
+                try
+                {
+                    r = fact(replaced(subject), replaced(predicate), restated, _pImpl->probability(f, predicate));
+                }
+                catch (const std::runtime_error&)
+                {
+                }
+            }
+            replacement[f] = r;
+            removed.push_back(f);
+            queue.push_back(f);
+        }
+    }
+
+    for (Node f : removed)
+        if (_pImpl->exists(f)) remove_node(f);
+
+    // Names of from become aliases of into, unless into has no name in
+    // the language yet, where it takes the name over.
+    {
+        std::unique_lock lock_node(_pImpl->_mtx_node_of_name);
+        std::unique_lock lock_name(_pImpl->_mtx_name_of_node);
+        ++_pImpl->_names_version;
+        for (auto& [lang, forward] : _pImpl->_name_of_node)
+        {
+            const auto it = forward.find(from);
+            if (it == forward.end()) continue;
+            const std::string_view name = it->second;
+            forward.erase(it);
+            forward.try_emplace(into, name);
+            _pImpl->_node_of_name[lang].insert_or_assign(name, into);
+        }
+    }
+    remove_node(from);
+
+    // Chains of replacements resolve to the final fact.
+    std::unordered_map<Node, Node> result;
+    for (Node f : removed)
+    {
+        Node r = replacement[f];
+        while (r && replacement.count(r) == 1)
+            r = replacement[r];
+        result.emplace(f, r);
+    }
+    return result;
+}
+
 #ifndef __EMSCRIPTEN__
+namespace
+{
+    // Runs load and then marks the network as loaded from a file (see
+    // Zelph::loaded_from_file), also if the load fails after it has begun to
+    // replace the network, but not if it fails before, e.g. because the file
+    // cannot be opened.
+    template <typename Load>
+    void mark_loaded(const Zelph& zelph, std::atomic<bool>& loaded, Load load)
+    {
+        const Node nodes = zelph.count();
+        try
+        {
+            load();
+        }
+        catch (...)
+        {
+            if (zelph.count() != nodes) loaded = true;
+            throw;
+        }
+        loaded = true;
+    }
+}
+
 void Zelph::save_to_file(const std::string& filename) const
 {
     _pImpl->saveToFile(filename);
@@ -126,14 +283,18 @@ void Zelph::load_from_file(const std::string& filename) const
 {
     invalidate_fact_structures_cache();
 
-    _pImpl->loadFromFile(filename);
+    _pImpl->note_unobserved_change();
+    mark_loaded(*this, _loaded_from_file, [&]
+                { _pImpl->loadFromFile(filename); });
 }
 
 void Zelph::load_from_file(const std::string& filename, const BinChunkSelection& selection, const bool skip_payload) const
 {
     invalidate_fact_structures_cache();
 
-    _pImpl->loadFromFile(filename, selection, skip_payload);
+    _pImpl->note_unobserved_change();
+    mark_loaded(*this, _loaded_from_file, [&]
+                { _pImpl->loadFromFile(filename, selection, skip_payload); });
 }
 
 void Zelph::load_from_manifest(const std::string&       manifest_path,
@@ -144,7 +305,9 @@ void Zelph::load_from_manifest(const std::string&       manifest_path,
 {
     invalidate_fact_structures_cache();
 
-    _pImpl->loadFromManifest(manifest_path, selection, shard_root, bin_path_override, skip_payload);
+    _pImpl->note_unobserved_change();
+    mark_loaded(*this, _loaded_from_file, [&]
+                { _pImpl->loadFromManifest(manifest_path, selection, shard_root, bin_path_override, skip_payload); });
 }
 #endif
 
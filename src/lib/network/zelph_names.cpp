@@ -64,11 +64,14 @@ using namespace zelph::network;
 // the other node's connections are merged into this node, and the other node
 // is subsequently removed.
 void Zelph::set_name(const Node         node,
-                     const std::string& name,
+                     const std::string& raw_name,
                      std::string        lang,
                      const bool         merge_on_conflict)
 {
     if (lang.empty()) lang = _lang;
+    // Variable names are kept as written: case folding must not turn the
+    // variable A into a name clash with the node a.
+    const std::string name = is_var(node) ? raw_name : normalize_name(raw_name);
 
 #if _DEBUG
     diagnostic_stream() << "Node " << node << " has name '" << name
@@ -78,6 +81,7 @@ void Zelph::set_name(const Node         node,
 
     std::unique_lock lock_node(_pImpl->_mtx_node_of_name);
     std::unique_lock lock_name(_pImpl->_mtx_name_of_node);
+    ++_pImpl->_names_version;
 
     ExclusiveNameAccessScope scope_node(node_of_name_exclusive_depth);
     ExclusiveNameAccessScope scope_name(name_of_node_exclusive_depth);
@@ -193,10 +197,13 @@ void Zelph::set_name(const Node         node,
 // This overload is primarily used by the interactive `.name` command.
 // It either finds an existing node via the foreign-language name or creates a new one if none exists.
 // At the same time, it updates or corrects the name in the current default language.
-Node Zelph::set_name(const std::string& name_in_current_lang,
-                     const std::string& name_in_given_lang,
+Node Zelph::set_name(const std::string& raw_name_in_current_lang,
+                     const std::string& raw_name_in_given_lang,
                      std::string        lang)
 {
+    const std::string name_in_current_lang = normalize_name(raw_name_in_current_lang);
+    const std::string name_in_given_lang   = normalize_name(raw_name_in_given_lang);
+
     if (lang.empty() || lang == _lang)
     {
         throw std::runtime_error("Zelph::set_name: Source and target language must not be the same");
@@ -250,6 +257,7 @@ Node Zelph::set_name(const std::string& name_in_current_lang,
     // -------------------------------------------------------------------------
     std::unique_lock lock_node(_pImpl->_mtx_node_of_name);
     std::unique_lock lock_name(_pImpl->_mtx_name_of_node);
+    ++_pImpl->_names_version;
 
     ExclusiveNameAccessScope scope_node(node_of_name_exclusive_depth);
     ExclusiveNameAccessScope scope_name(name_of_node_exclusive_depth);
@@ -629,6 +637,7 @@ void Zelph::remove_name(Node node, std::string lang)
 
     std::unique_lock lock_node(_pImpl->_mtx_node_of_name);
     std::unique_lock lock_name(_pImpl->_mtx_name_of_node);
+    ++_pImpl->_names_version;
 
     _pImpl->remove_name_locked(node, lang);
 }
@@ -639,13 +648,20 @@ void Zelph::unset_name(Node node, std::string lang)
 
     std::unique_lock lock_node(_pImpl->_mtx_node_of_name);
     std::unique_lock lock_name(_pImpl->_mtx_name_of_node);
+    ++_pImpl->_names_version;
 
     _pImpl->remove_name_locked(node, lang);
 }
 
-Node Zelph::get_node(const std::string& name, std::string lang) const
+std::string Zelph::normalize_name(const std::string& name) const
+{
+    return string::unicode::normalize(name, _name_normalization, _case_folding);
+}
+
+Node Zelph::get_node(const std::string& raw_name, std::string lang) const
 {
     if (lang.empty()) lang = _lang;
+    const std::string name = normalize_name(raw_name);
 
     std::shared_lock lock(_pImpl->_mtx_node_of_name);
     auto             lang_it = _pImpl->_node_of_name.find(lang);
@@ -747,8 +763,9 @@ name_of_node_map Zelph::get_nodes_in_language(const std::string& lang) const
     return it->second;
 }
 
-std::vector<Node> Zelph::resolve_nodes_by_name(const std::string& name) const
+std::vector<Node> Zelph::resolve_nodes_by_name(const std::string& raw_name) const
 {
+    const std::string name = normalize_name(raw_name);
     std::vector<Node> results;
 
     std::shared_lock lock(_pImpl->_mtx_node_of_name);
@@ -787,3 +804,33 @@ size_t Zelph::language_count() const
     std::shared_lock lock(_pImpl->_mtx_node_of_name);
     return _pImpl->_node_of_name.size();
 }
+
+std::vector<NameIndex::Match> Zelph::search_names(const std::string& query, const size_t limit, const bool fuzzy, std::string lang) const
+{
+    if (lang.empty()) lang = _lang;
+
+    std::shared_ptr<const NameIndex> index;
+    {
+        std::lock_guard lock(_pImpl->_mtx_name_indexes);
+        const uint64_t  version = _pImpl->_names_version.load();
+        auto&           slot    = _pImpl->_name_indexes[lang];
+        if (!slot.second || slot.first != version)
+        {
+            std::vector<std::pair<std::string, Node>> names;
+            {
+                std::shared_lock lock_name(_pImpl->_mtx_name_of_node);
+                auto             it = _pImpl->_name_of_node.find(lang);
+                if (it != _pImpl->_name_of_node.end())
+                {
+                    names.reserve(it->second.size());
+                    for (const auto& [node, name] : it->second)
+                        if (!Impl::is_var(node)) names.emplace_back(std::string(name), node);
+                }
+            }
+            slot = {version, std::make_shared<const NameIndex>(std::move(names))};
+        }
+        index = slot.second;
+    }
+
+    return index->search(string::unicode::normalize(query, string::unicode::Normalization::NFC, true), limit, fuzzy);
+}
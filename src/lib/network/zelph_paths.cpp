@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "zelph.hpp"
+
+#include <deque>
+#include <unordered_map>
+#include <unordered_set>
+
+using namespace zelph::network;
+
+namespace
+{
+    // Partial paths a find_paths call may queue before it gives up.
+    constexpr size_t kPathSearchBudget = 1000000;
+}
+
+std::vector<Zelph::Path> Zelph::find_paths(const Node from, const Node to, const PathOptions& options) const
+{
+    std::vector<Path> result;
+    if (options.max_paths == 0 || !exists(from) || !exists(to)) return result;
+    if (from == to)
+    {
+        result.emplace_back();
+        return result;
+    }
+
+    // The statements a node takes part in as subject or object, in both
+    // directions; the searches pick the directions they may follow.
+    std::unordered_map<Node, std::vector<PathStep>> steps_cache;
+    auto steps = [&](const Node nd) -> const std::vector<PathStep>&
+    {
+        auto [it, inserted] = steps_cache.try_emplace(nd);
+        if (!inserted) return it->second;
+
+        for (const Node rel : get_right(nd))
+        {
+            const Node predicate = parse_relation(rel);
+            if (predicate == 0 || predicate == core.Causes) continue;
+            if (!options.relations.empty() && options.relations.count(predicate) == 0) continue;
+
+            adjacency_set objects;
+            const Node    subject = parse_fact(rel, objects);
+            if (subject == 0 || subject == predicate || is_var(subject)) continue;
+            if (predicate == core.IsA && objects.count(core.RelationTypeCategory) == 1) continue;
+
+            bool has_var = false;
+            for (const Node object : objects)
+                has_var = has_var || is_var(object);
+            if (has_var) continue;
+
+            if (subject == nd)
+            {
+                for (const Node object : objects)
+                    if (object != nd) it->second.push_back({rel, nd, predicate, object, true});
+            }
+            else if (objects.count(nd) == 1)
+            {
+                it->second.push_back({rel, nd, predicate, subject, false});
+            }
+        }
+        return it->second;
+    };
+
+    const size_t max_depth = options.max_depth == 0 ? static_cast<size_t>(-1) : options.max_depth;
+
+    // Distances to the target, walking statements backwards, so that the
+    // path search only extends paths that can still reach it in time.
+    std::unordered_map<Node, size_t> distance{{to, 0}};
+    std::deque<Node>                 frontier{to};
+    while (!frontier.empty())
+    {
+        const Node   nd   = frontier.front();
+        const size_t dist = distance[nd];
+        frontier.pop_front();
+        if (dist >= max_depth) continue;
+        for (const PathStep& step : steps(nd))
+        {
+            if (options.directed && step.forward) continue;
+            if (distance.try_emplace(step.to, dist + 1).second) frontier.push_back(step.to);
+        }
+    }
+    if (distance.count(from) == 0) return result;
+
+    // Breadth-first over simple paths, which yields them shortest first.
+    std::deque<Path> queue;
+    queue.emplace_back();
+    size_t queued = 1;
+    while (!queue.empty() && queued < kPathSearchBudget)
+    {
+        Path path = std::move(queue.front());
+        queue.pop_front();
+
+        const Node               at = path.empty() ? from : path.back().to;
+        std::unordered_set<Node> visited{from};
+        for (const PathStep& step : path)
+            visited.insert(step.to);
+
+        for (const PathStep& step : steps(at))
+        {
+            if (options.directed && !step.forward) continue;
+            auto dist = distance.find(step.to);
+            if (dist == distance.end() || path.size() + 1 + dist->second > max_depth) continue;
+            if (visited.count(step.to) == 1) continue;
+
+            Path next = path;
+            next.push_back(step);
+            if (step.to == to)
+            {
+                result.push_back(std::move(next));
+                if (result.size() == options.max_paths) return result;
+            }
+            else
+            {
+                queue.push_back(std::move(next));
+                ++queued;
+            }
+        }
+    }
+
+    return result;
+}
@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <stdexcept>
+#include <string>
+#include <utility>
+#include <vector>
+
+namespace zelph::console
+{
+    // Classifies the stage of Interactive::process in which an input line failed.
+    enum class ProcessErrorKind
+    {
+        Syntax,    // zelph statement could not be parsed
+        Command,   // a dot-command rejected its arguments or failed
+        Script,    // Janet code (inline, block or keyword handler) raised an error
+        Statement, // a parsed zelph statement failed while being asserted or queried
+        Reasoning,    // the auto-run inference pass after the line failed
+        Cancelled,    // reasoning was stopped via Interactive::cancel
+        ResourceLimit, // reasoning exceeded a limit set via .max-facts or .max-memory
+        Assertion      // an .assert or .assert-not expectation did not hold
+    };
+
+    inline const char* to_string(const ProcessErrorKind kind)
+    {
+        switch (kind)
+        {
+        case ProcessErrorKind::Syntax:
+            return "syntax";
+        case ProcessErrorKind::Command:
+            return "command";
+        case ProcessErrorKind::Script:
+            return "script";
+        case ProcessErrorKind::Statement:
+            return "statement";
+        case ProcessErrorKind::Reasoning:
+            return "reasoning";
+        case ProcessErrorKind::Cancelled:
+            return "cancelled";
+        case ProcessErrorKind::ResourceLimit:
+            return "resource-limit";
+        case ProcessErrorKind::Assertion:
+            return "assertion";
+        }
+        return "unknown";
+    }
+
+    // Thrown by Interactive::process. what() keeps the established
+    // "Error in line ..." text, so callers that only catch std::runtime_error
+    // are unaffected; embedders (e.g. the C interface) can inspect the
+    // offending line and the failing stage without parsing the message.
+    // For nested input (.import, zelph/import) the kind is the one of the
+    // innermost failing line, while line() is the outermost one.
+    class process_error final : public std::runtime_error
+    {
+    public:
+        process_error(const std::string& message, std::string line, const ProcessErrorKind kind, std::string reason, const size_t column = 0)
+            : std::runtime_error(message)
+            , _line(std::move(line))
+            , _kind(kind)
+            , _reason(std::move(reason))
+            , _column(column)
+        {
+        }
+
+        const std::string& line() const
+        {
+            return _line;
+        }
+
+        ProcessErrorKind kind() const
+        {
+            return _kind;
+        }
+
+        // The underlying error text, without the "Error in line" prefix.
+        const std::string& reason() const
+        {
+            return _reason;
+        }
+
+        // For a syntax error, the 1-based column in line() at which the
+        // statement stops parsing, counted in characters; 0 if unknown, e.g.
+        // for other kinds or an error in an earlier line of a statement
+        // spanning several lines.
+        size_t column() const
+        {
+            return _column;
+        }
+
+    private:
+        std::string      _line;
+        ProcessErrorKind _kind;
+        std::string      _reason;
+        size_t           _column;
+    };
+
+    // Thrown by the .assert and .assert-not commands when their expectation
+    // does not hold; Interactive::process reports it as a process_error of
+    // kind Assertion rather than Command.
+    class assertion_failed final : public std::runtime_error
+    {
+    public:
+        using std::runtime_error::runtime_error;
+    };
+
+    // One failed line of a script processed by Interactive::process_script.
+    struct ScriptLineError
+    {
+        size_t           number; // 1-based; 0 for the run after the last line
+        std::string      line;
+        ProcessErrorKind kind;
+        std::string      reason;
+        size_t           column{0}; // see process_error::column
+    };
+
+    // Thrown by Interactive::process_script once the whole script has been
+    // processed, with every line that failed. what() lists them, one per
+    // line of text, as "line <number>: <reason>".
+    class script_error final : public std::runtime_error
+    {
+    public:
+        explicit script_error(std::vector<ScriptLineError> errors)
+            : std::runtime_error(format(errors))
+            , _errors(std::move(errors))
+        {
+        }
+
+        const std::vector<ScriptLineError>& errors() const
+        {
+            return _errors;
+        }
+
+    private:
+        static std::string format(const std::vector<ScriptLineError>& errors)
+        {
+            std::string text = std::to_string(errors.size()) + " error(s) in script:";
+            for (const auto& e : errors)
+            {
+                text += "\n";
+                text += e.number == 0 ? std::string("after the last line") : "line " + std::to_string(e.number);
+                text += ": " + e.reason;
+            }
+            return text;
+        }
+
+        std::vector<ScriptLineError> _errors;
+    };
+}
@@ -0,0 +1,437 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "line_editor.hpp"
+
+#include <algorithm>
+#include <cstdlib>
+#include <fstream>
+#include <iostream>
+
+#if !defined(_WIN32) && !defined(__EMSCRIPTEN__)
+    #include <sys/ioctl.h>
+    #include <termios.h>
+    #include <unistd.h>
+
+    #include <cerrno>
+    #define ZELPH_LINE_EDITING
+#endif
+
+using namespace zelph::repl;
+
+namespace
+{
+    bool is_continuation(const char c) { return (static_cast<unsigned char>(c) & 0xC0) == 0x80; }
+
+#ifdef ZELPH_LINE_EDITING
+    // ZELPH_NO_LINE_EDITING, or ZELPH_NO_RLWRAP from the time the REPL was
+    // wrapped in rlwrap, which is still honoured but warned about once.
+    bool line_editing_disabled()
+    {
+        if (std::getenv("ZELPH_NO_LINE_EDITING") != nullptr) return true;
+        if (std::getenv("ZELPH_NO_RLWRAP") == nullptr) return false;
+
+        static bool warned = false;
+        if (!warned)
+        {
+            std::cerr << "Warning: ZELPH_NO_RLWRAP is deprecated, use ZELPH_NO_LINE_EDITING instead." << std::endl;
+            warned = true;
+        }
+        return true;
+    }
+#endif
+
+    // Byte offsets of the neighbouring UTF-8 code points.
+    size_t next_char(const std::string& text, size_t pos)
+    {
+        if (pos < text.size()) ++pos;
+        while (pos < text.size() && is_continuation(text[pos]))
+            ++pos;
+        return pos;
+    }
+
+    size_t previous_char(const std::string& text, size_t pos)
+    {
+        if (pos > 0) --pos;
+        while (pos > 0 && is_continuation(text[pos]))
+            --pos;
+        return pos;
+    }
+
+    // Terminal columns of text[from, to), one per code point.
+    size_t width(const std::string& text, const size_t from = 0, size_t to = std::string::npos)
+    {
+        to           = std::min(to, text.size());
+        size_t count = 0;
+        for (size_t i = from; i < to; ++i)
+            if (!is_continuation(text[i])) ++count;
+        return count;
+    }
+
+#ifdef ZELPH_LINE_EDITING
+    // The terminal in raw mode for as long as this exists.
+    class RawMode
+    {
+    public:
+        RawMode()
+        {
+            if (tcgetattr(STDIN_FILENO, &_saved) != 0) return;
+            termios raw = _saved;
+            raw.c_iflag &= ~(BRKINT | ICRNL | INPCK | ISTRIP | IXON);
+            raw.c_cflag |= CS8;
+            raw.c_lflag &= ~(ECHO | ICANON | IEXTEN | ISIG);
+            raw.c_cc[VMIN]  = 1;
+            raw.c_cc[VTIME] = 0;
+            _active         = tcsetattr(STDIN_FILENO, TCSAFLUSH, &raw) == 0;
+        }
+
+        ~RawMode()
+        {
+            if (_active) tcsetattr(STDIN_FILENO, TCSAFLUSH, &_saved);
+        }
+
+        bool active() const { return _active; }
+
+        RawMode(const RawMode&)            = delete;
+        RawMode& operator=(const RawMode&) = delete;
+
+    private:
+        termios _saved{};
+        bool    _active{false};
+    };
+
+    bool read_byte(char& c)
+    {
+        for (;;)
+        {
+            const ssize_t n = read(STDIN_FILENO, &c, 1);
+            if (n == 1) return true;
+            if (n < 0 && errno == EINTR) continue;
+            return false;
+        }
+    }
+
+    void write_out(const std::string& text)
+    {
+        size_t written = 0;
+        while (written < text.size())
+        {
+            const ssize_t n = write(STDOUT_FILENO, text.data() + written, text.size() - written);
+            if (n < 0 && errno == EINTR) continue;
+            if (n <= 0) return;
+            written += static_cast<size_t>(n);
+        }
+    }
+
+    size_t columns()
+    {
+        winsize size{};
+        if (ioctl(STDOUT_FILENO, TIOCGWINSZ, &size) == -1 || size.ws_col == 0) return 80;
+        return size.ws_col;
+    }
+
+    // Redraws the line on one row, scrolled horizontally so that the
+    // cursor stays visible.
+    void refresh(const std::string& prompt, const std::string& line, const size_t cursor)
+    {
+        const size_t cols         = columns();
+        const size_t prompt_width = width(prompt);
+
+        size_t start = 0;
+        while (start < cursor && prompt_width + width(line, start, cursor) >= cols)
+            start = next_char(line, start);
+        size_t end = start;
+        while (end < line.size() && prompt_width + width(line, start, next_char(line, end)) < cols)
+            end = next_char(line, end);
+
+        std::string sequence = "\r" + prompt + line.substr(start, end - start) + "\x1b[0K\r";
+        const size_t column  = prompt_width + width(line, start, cursor);
+        if (column > 0) sequence += "\x1b[" + std::to_string(column) + "C";
+        write_out(sequence);
+    }
+#endif
+}
+
+LineEditor::LineEditor(std::string history_file, const size_t max_history)
+    : LineEditor(std::cin, std::cout, std::move(history_file), max_history)
+{
+}
+
+LineEditor::LineEditor(std::istream& in, std::ostream& out, std::string history_file, const size_t max_history)
+    : _in(in)
+    , _out(out)
+    , _history_file(std::move(history_file))
+    , _max_history(max_history)
+{
+#ifdef ZELPH_LINE_EDITING
+    const char* term = std::getenv("TERM");
+    _editing         = &in == &std::cin && &out == &std::cout
+            && isatty(STDIN_FILENO) && isatty(STDOUT_FILENO)
+            && !(term && std::string(term) == "dumb")
+            && !line_editing_disabled();
+#endif
+    load_history();
+}
+
+std::optional<std::string> LineEditor::read_line(const std::string& prompt)
+{
+    if (_editing) return edit_line(prompt);
+
+    _out << prompt << std::flush;
+    std::string line;
+    if (!std::getline(_in, line)) return std::nullopt;
+    if (!line.empty() && line.back() == '\r') line.pop_back();
+    return line;
+}
+
+void LineEditor::add_history(const std::string& line)
+{
+    if (line.empty() || (!_history.empty() && _history.back() == line)) return;
+
+    _history.push_back(line);
+    while (_history.size() > _max_history)
+        _history.pop_front();
+
+    if (!_history_file.empty())
+    {
+        std::ofstream file(_history_file, std::ios::app);
+        file << line << '\n';
+    }
+}
+
+std::string LineEditor::common_prefix(const std::vector<std::string>& candidates)
+{
+    if (candidates.empty()) return "";
+
+    std::string prefix = candidates.front();
+    for (const std::string& candidate : candidates)
+    {
+        size_t length = 0;
+        while (length < prefix.size() && length < candidate.size() && prefix[length] == candidate[length])
+            ++length;
+        prefix.resize(length);
+    }
+    // Not in the middle of a character.
+    while (!prefix.empty() && prefix.size() < candidates.front().size() && is_continuation(candidates.front()[prefix.size()]))
+        prefix.pop_back();
+    return prefix;
+}
+
+// Keeps the last _max_history lines of the file, rewriting it if it has
+// grown beyond twice that.
+void LineEditor::load_history()
+{
+    if (_history_file.empty()) return;
+
+    std::ifstream file(_history_file);
+    size_t        lines = 0;
+    for (std::string line; std::getline(file, line); ++lines)
+    {
+        if (line.empty()) continue;
+        _history.push_back(line);
+        if (_history.size() > _max_history) _history.pop_front();
+    }
+    file.close();
+
+    if (lines > 2 * _max_history)
+    {
+        std::ofstream rewritten(_history_file, std::ios::trunc);
+        for (const std::string& line : _history)
+            rewritten << line << '\n';
+    }
+}
+
+std::optional<std::string> LineEditor::edit_line(const std::string& prompt)
+{
+#ifdef ZELPH_LINE_EDITING
+    _out.flush();
+    const RawMode raw;
+    if (!raw.active())
+    {
+        _editing = false;
+        return read_line(prompt);
+    }
+
+    std::string line;
+    size_t      cursor = 0;
+
+    // The history with the line being edited as its newest entry.
+    std::vector<std::string> entries(_history.begin(), _history.end());
+    entries.emplace_back();
+    size_t entry = entries.size() - 1;
+    auto   show  = [&](const size_t index)
+    {
+        entries[entry] = line;
+        entry          = index;
+        line           = entries[entry];
+        cursor         = line.size();
+    };
+
+    bool after_tab = false;
+    refresh(prompt, line, cursor);
+
+    for (char c; read_byte(c);)
+    {
+        const bool tab = c == '\t';
+        switch (c)
+        {
+        case '\r':
+        case '\n':
+            write_out("\n");
+            return line;
+        case 3: // Ctrl-C drops the line
+            write_out("^C\n");
+            line.clear();
+            cursor = 0;
+            break;
+        case 4: // Ctrl-D
+            if (line.empty())
+            {
+                write_out("\n");
+                return std::nullopt;
+            }
+            if (cursor < line.size()) line.erase(cursor, next_char(line, cursor) - cursor);
+            break;
+        case 127:
+        case 8: // Backspace
+            if (cursor > 0)
+            {
+                const size_t start = previous_char(line, cursor);
+                line.erase(start, cursor - start);
+                cursor = start;
+            }
+            break;
+        case '\t':
+            if (_completer)
+            {
+                size_t     word_start = cursor;
+                const auto candidates = _completer(line, cursor, word_start);
+                const auto word       = line.substr(word_start, cursor - word_start);
+                const auto completion = candidates.size() == 1 ? candidates.front() + " " : common_prefix(candidates);
+                if (completion.size() > word.size() && completion.compare(0, word.size(), word) == 0)
+                {
+                    line.replace(word_start, cursor - word_start, completion);
+                    cursor = word_start + completion.size();
+                }
+                else if (after_tab && candidates.size() > 1)
+                {
+                    std::string listing = "\n";
+                    for (size_t i = 0; i < candidates.size() && i < 100; ++i)
+                        listing += candidates[i] + "  ";
+                    if (candidates.size() > 100) listing += "…";
+                    write_out(listing + "\n");
+                }
+                else
+                {
+                    write_out("\x07");
+                }
+            }
+            break;
+        case 1: // Ctrl-A
+            cursor = 0;
+            break;
+        case 5: // Ctrl-E
+            cursor = line.size();
+            break;
+        case 2: // Ctrl-B
+            cursor = previous_char(line, cursor);
+            break;
+        case 6: // Ctrl-F
+            cursor = next_char(line, cursor);
+            break;
+        case 11: // Ctrl-K
+            line.erase(cursor);
+            break;
+        case 21: // Ctrl-U
+            line.erase(0, cursor);
+            cursor = 0;
+            break;
+        case 23: // Ctrl-W deletes the word before the cursor
+        {
+            size_t start = cursor;
+            while (start > 0 && line[start - 1] == ' ')
+                --start;
+            while (start > 0 && line[start - 1] != ' ')
+                --start;
+            line.erase(start, cursor - start);
+            cursor = start;
+            break;
+        }
+        case 12: // Ctrl-L
+            write_out("\x1b[H\x1b[2J");
+            break;
+        case 16: // Ctrl-P
+            if (entry > 0) show(entry - 1);
+            break;
+        case 14: // Ctrl-N
+            if (entry + 1 < entries.size()) show(entry + 1);
+            break;
+        case 27: // escape sequences of the arrow and editing keys
+        {
+            char first, second;
+            if (!read_byte(first) || !read_byte(second)) break;
+            if (first == '[' && second >= '0' && second <= '9')
+            {
+                char last;
+                if (!read_byte(last) || last != '~') break;
+                if (second == '3' && cursor < line.size()) line.erase(cursor, next_char(line, cursor) - cursor);
+                if (second == '1' || second == '7') cursor = 0;
+                if (second == '4' || second == '8') cursor = line.size();
+            }
+            else if (first == '[' || first == 'O')
+            {
+                if (second == 'A' && entry > 0) show(entry - 1);
+                if (second == 'B' && entry + 1 < entries.size()) show(entry + 1);
+                if (second == 'C') cursor = next_char(line, cursor);
+                if (second == 'D') cursor = previous_char(line, cursor);
+                if (second == 'H') cursor = 0;
+                if (second == 'F') cursor = line.size();
+            }
+            break;
+        }
+        default:
+            if (static_cast<unsigned char>(c) >= 32)
+            {
+                // A whole UTF-8 character, so that no partial one is drawn.
+                std::string character(1, c);
+                const auto  lead   = static_cast<unsigned char>(c);
+                const int   follow = lead >= 0xF0 ? 3 : lead >= 0xE0 ? 2 : lead >= 0xC0 ? 1 : 0;
+                for (int i = 0; i < follow && read_byte(c); ++i)
+                    character += c;
+                line.insert(cursor, character);
+                cursor += character.size();
+            }
+            break;
+        }
+        after_tab = tab;
+        refresh(prompt, line, cursor);
+    }
+    write_out("\n");
+    return line.empty() ? std::nullopt : std::optional<std::string>(line);
+#else
+    _editing = false;
+    return read_line(prompt);
+#endif
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <zelph_export.h>
+
+#include <cstddef>
+#include <deque>
+#include <functional>
+#include <iosfwd>
+#include <optional>
+#include <string>
+#include <vector>
+
+namespace zelph::repl
+{
+    // Reads input lines with readline-style editing when stdin is a
+    // terminal: cursor movement (arrows, Home/End, Ctrl-A/E), deleting
+    // (Backspace, Delete, Ctrl-K/U/W), history (Up/Down), Ctrl-L to clear
+    // the screen, Ctrl-C to drop the line, Ctrl-D on an empty line for the
+    // end of input and Tab for completion. A single completion is inserted;
+    // several are completed to their common prefix and listed on a second
+    // Tab. Elsewhere (pipes, Windows, ZELPH_NO_LINE_EDITING or the deprecated
+    // ZELPH_NO_RLWRAP set) lines are read as they are, so scripts and test
+    // drivers see no escape codes.
+    //
+    // The history keeps the last max_history lines, and with a file name is
+    // loaded from and appended to that file, one line per entry.
+    class ZELPH_EXPORT LineEditor
+    {
+    public:
+        // The candidates for the word that ends at cursor in line, and in
+        // word_start where that word begins.
+        using Completer = std::function<std::vector<std::string>(const std::string& line, size_t cursor, size_t& word_start)>;
+
+        explicit LineEditor(std::string history_file = "", size_t max_history = 1000);
+        LineEditor(std::istream& in, std::ostream& out, std::string history_file = "", size_t max_history = 1000);
+
+        void set_completer(Completer completer) { _completer = std::move(completer); }
+
+        // The next line without its line break, or nothing at the end of
+        // input. The prompt is printed first.
+        std::optional<std::string> read_line(const std::string& prompt);
+
+        // Adds a line to the history (and its file) unless it is empty or
+        // repeats the last one.
+        void                           add_history(const std::string& line);
+        const std::deque<std::string>& history() const { return _history; }
+
+        // True if lines are edited on the terminal.
+        bool editing() const { return _editing; }
+
+        // The longest prefix all candidates share.
+        static std::string common_prefix(const std::vector<std::string>& candidates);
+
+        LineEditor(const LineEditor&)            = delete;
+        LineEditor& operator=(const LineEditor&) = delete;
+
+    private:
+        std::optional<std::string> edit_line(const std::string& prompt);
+        void                       load_history();
+
+        std::istream&           _in;
+        std::ostream&           _out;
+        std::string             _history_file;
+        size_t                  _max_history;
+        std::deque<std::string> _history;
+        Completer               _completer;
+        bool                    _editing{false};
+    };
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "repl.hpp"
+
+#include "interactive.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <cstdio>
+#include <cstdlib>
+#include <set>
+#include <utility>
+
+using namespace zelph;
+using namespace zelph::repl;
+
+namespace
+{
+    std::string format_duration(const std::chrono::steady_clock::duration d)
+    {
+        using namespace std::chrono;
+        const long long ms = duration_cast<milliseconds>(d).count();
+        if (ms < 1000) return std::to_string(ms) + " ms";
+
+        const long long s = ms / 1000;
+        char            buf[64];
+        if (s < 60)
+            std::snprintf(buf, sizeof(buf), "%lld.%03lld s", s, ms % 1000);
+        else
+            std::snprintf(buf, sizeof(buf), "%lldm%lld.%03llds", s / 60, s % 60, ms % 1000);
+        return buf;
+    }
+}
+
+Repl::Repl(const console::Interactive& interactive, ReplOptions options, std::istream& in, std::ostream& out)
+    : _interactive(interactive)
+    , _options(std::move(options))
+    , _editor(in, out, _options.history_file)
+{
+    _editor.set_completer([this](const std::string& line, size_t cursor, size_t& word_start)
+                          { return complete(line, cursor, word_start); });
+}
+
+std::string Repl::default_history_file()
+{
+    if (const char* file = std::getenv("ZELPH_HISTORY")) return file;
+#ifdef _WIN32
+    const char* home = std::getenv("USERPROFILE");
+#else
+    const char* home = std::getenv("HOME");
+#endif
+    return home && *home ? std::string(home) + "/.zelph_history" : "";
+}
+
+std::string Repl::prompt() const
+{
+    // Without line editing the continuation prompt stays empty, as tools
+    // reading piped REPL output expect.
+    if (_interactive.is_accumulating()) return _editor.editing() ? "... " : "";
+    return _interactive.get_lang() + (_interactive.is_auto_run_active() ? "> " : "-> ");
+}
+
+void Repl::run()
+{
+    if (_options.banner)
+    {
+        _interactive.out("zelph " + console::Interactive::get_version());
+        _interactive.out("-- REPL mode - type .help for commands, " + _options.exit_command + " to exit --");
+        _interactive.out("");
+    }
+
+    while (const auto line = _editor.read_line(prompt()))
+    {
+        if (*line == _options.exit_command)
+            break;
+
+        if (line->empty() && !_interactive.is_accumulating())
+        {
+            _interactive.out("type .help for help --");
+            continue;
+        }
+
+        _editor.add_history(*line);
+
+        // A script import (.import file) arrives as a single line, so it
+        // yields exactly one timing - never one per script line.
+        const auto start_time = std::chrono::steady_clock::now();
+
+        try
+        {
+            _interactive.process(*line);
+        }
+        catch (const std::exception& e)
+        {
+            _interactive.err(e.what());
+        }
+
+        const auto elapsed = std::chrono::steady_clock::now() - start_time;
+        if (elapsed >= _options.timing_threshold)
+        {
+            _interactive.log("-- " + format_duration(elapsed) + " --");
+        }
+    }
+
+    _interactive.out("");
+}
+
+std::vector<std::string> Repl::complete(const std::string& line, const size_t cursor, size_t& word_start) const
+{
+    word_start = std::min(cursor, line.size());
+    while (word_start > 0)
+    {
+        const auto c = static_cast<unsigned char>(line[word_start - 1]);
+        if (std::isspace(c) || c == '(' || c == ')') break;
+        --word_start;
+    }
+    const std::string word = line.substr(word_start, cursor - word_start);
+
+    std::vector<std::string> candidates;
+    if (word.empty()) return candidates;
+
+    if (word[0] == '.' && word_start == line.find_first_not_of(" \t"))
+    {
+        for (const std::string& command : _interactive.commands())
+            if (command.compare(0, word.size(), word) == 0) candidates.push_back(command);
+        return candidates;
+    }
+
+    // Names with blanks would need quotes and are left out.
+    std::set<std::string> names;
+    try
+    {
+        for (const auto& match : _interactive.search_concepts(word, 100))
+            if ((match.kind == "exact" || match.kind == "prefix") && match.name.compare(0, word.size(), word) == 0
+                && match.name.find_first_of(" \t") == std::string::npos)
+                names.insert(match.name);
+    }
+    catch (const std::exception&)
+    {
+        // no completion rather than a broken line
+    }
+    candidates.assign(names.begin(), names.end());
+    return candidates;
+}
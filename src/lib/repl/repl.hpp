@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include "line_editor.hpp"
+
+#include <zelph_export.h>
+
+#include <chrono>
+#include <cstddef>
+#include <iostream>
+#include <string>
+#include <vector>
+
+namespace zelph::console
+{
+    class Interactive;
+}
+
+namespace zelph::repl
+{
+    struct ReplOptions
+    {
+        // See Repl::default_history_file; empty for no persistent history.
+        std::string               history_file;
+        std::string               exit_command{".quit"};
+        bool                      banner{true};
+        std::chrono::milliseconds timing_threshold{10}; // slower lines report their time
+    };
+
+    // The interactive mode of the zelph executable as a loop that embedders
+    // can run too: lines are read with a LineEditor, completion offers the
+    // REPL commands at the start of a line and the names of concepts and
+    // relations elsewhere, and while a statement or Janet block spans
+    // several lines the prompt shows "... " (nothing when lines are read
+    // without editing). Each line is processed by the Interactive; errors
+    // are printed and the REPL continues, and a line that takes longer than
+    // the timing threshold is followed by its time.
+    class ZELPH_EXPORT Repl
+    {
+    public:
+        explicit Repl(const console::Interactive& interactive, ReplOptions options = {}, std::istream& in = std::cin, std::ostream& out = std::cout);
+
+        // Until the exit command or the end of input.
+        void run();
+
+        // Candidates for the word before cursor, see LineEditor::Completer.
+        std::vector<std::string> complete(const std::string& line, size_t cursor, size_t& word_start) const;
+
+        // $ZELPH_HISTORY if set, else .zelph_history in the home directory,
+        // or empty if there is none.
+        static std::string default_history_file();
+
+        LineEditor& editor() { return _editor; }
+
+    private:
+        std::string prompt() const;
+
+        const console::Interactive& _interactive;
+        ReplOptions                 _options;
+        LineEditor                  _editor;
+    };
+}
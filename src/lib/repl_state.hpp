@@ -26,7 +26,14 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #pragma once
 
 #include <memory>
+#include <set>
 #include <string>
+#include <vector>
+
+namespace zelph::io
+{
+    class FactStore;
+}
 
 namespace zelph::console
 {
@@ -54,6 +61,24 @@ namespace zelph::console
         std::string keyword_buffer;
         bool        keyword_prev_blank = false;
 
+        // Set by .journal and handled by Interactive, like reset_requested:
+        // the file to replay and journal to, or empty to close the journal.
+        // journal_file is the journal currently open (empty if none).
+        bool        journal_requested{false};
+        std::string journal_request;
+        std::string journal_file;
+
+        // The scripts .import and .include are reading, outermost first,
+        // which finds include cycles and the directory of the including
+        // script; the modules .include has read (canonical paths, cleared
+        // by .reset); and the directories added by .include-path.
+        std::vector<std::string> script_stack;
+        std::set<std::string>    included;
+        std::vector<std::string> include_paths;
+
+        // The fact store opened by .store or Interactive::open_store, if any.
+        std::shared_ptr<io::FactStore> fact_store;
+
         // Absolute path of the most recently generated Mermaid HTML file
         // (graph of an output node). Consumed via Interactive::take_last_graph_html();
         // the wasm playground polls it after every command batch instead of
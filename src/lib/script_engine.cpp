@@ -37,6 +37,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include <map>
 #include <mutex>
 #include <random>
+#include <set>
 #include <thread>
 #include <unordered_set>
 #include <vector>
@@ -48,14 +49,47 @@ using namespace zelph;
 class ScriptEngine::Impl
 {
 public:
-    static Impl* s_instance; // Required for static Janet C-function callbacks
+    // The engine the static Janet C-function callbacks work on. Set by
+    // VmScope for the calling thread. Threads started by Janet itself
+    // (ev/spawn-thread) run their own VM outside any VmScope and start out
+    // with the process's only engine, if there is exactly one (see
+    // sole_engine); with several engines zelph/* is unavailable there.
+    static thread_local Impl* s_instance;
+
+    // Janet keeps its VM in thread-local state. Each engine owns a JanetVM
+    // that is loaded into the calling thread only for the duration of a
+    // VmScope and saved back afterwards, so an engine may be used from any
+    // thread. s_active names the engine whose state currently lives in the
+    // thread-local VM.
+    static thread_local Impl* s_active;
+
+    // All live engines, for sole_engine.
+    static std::mutex      s_engines_mutex;
+    static std::set<Impl*> s_engines;
+
+    static Impl* sole_engine()
+    {
+        std::lock_guard<std::mutex> lock(s_engines_mutex);
+        return s_engines.size() == 1 ? *s_engines.begin() : nullptr;
+    }
 
     network::Reasoning*          _n;
+    JanetVM*                     _vm        = nullptr;
+    std::recursive_mutex         _vm_mutex; // held by VmScope
     JanetTable*                  _janet_env = nullptr;
     Janet                        _zelph_peg{};
     bool                         _log_janet_functions = false;
     std::map<std::string, Janet> _keyword_handlers;
 
+    // Quote pairs accepted by the parser besides "..." (see set_quote_pairs).
+    std::vector<std::pair<std::string, std::string>> _quote_pairs;
+
+    // The dynamic bindings :out and :err of _janet_env, so that print and
+    // eprint in scripts write here instead of to stdout and stderr.
+    // flush_output passes their content on to the network's output handler.
+    JanetBuffer* _out_buffer = nullptr;
+    JanetBuffer* _err_buffer = nullptr;
+
     // Compiled neural networks (session-scoped caches, discarded on .reset).
     // Handles handed to Janet are indexes into this vector.
     std::vector<std::unique_ptr<network::NeuralNet>> _neural_nets;
@@ -74,6 +108,9 @@ public:
     // Track variables used in the current scope/statement
     std::map<std::string, network::Node> _scoped_variables;
 
+    // See ScriptEngine::set_module_namespace.
+    std::string _module_namespace;
+
     // Guards the script engine's own bookkeeping (_scoped_variables,
     // _neural_nets) against concurrent access from Janet threads
     // (ev/spawn-thread). Calls INTO the reasoning engine are synchronized
@@ -86,11 +123,6 @@ public:
     // Set by Interactive; backs zelph/save and zelph/load.
     CommandHandler _command_handler;
 
-    // The thread that owns _janet_env. zelph/import must run here: the REPL
-    // pipeline it delegates to executes Janet code in the main VM, which is
-    // not usable from other Janet threads (each has its own VM).
-    std::thread::id _main_thread_id;
-
     void clear_scoped_variables()
     {
         std::lock_guard<std::mutex> lock(_state_mutex);
@@ -106,34 +138,116 @@ public:
     explicit Impl(network::Reasoning* n)
         : _n(n)
     {
-        s_instance = this;
+        std::lock_guard<std::mutex> lock(s_engines_mutex);
+        s_engines.insert(this);
     }
 
     ~Impl()
     {
-        if (s_instance == this) s_instance = nullptr;
         if (_janet_env)
         {
+            VmScope scope(*this);
             for (auto& [kw, handler] : _keyword_handlers)
                 janet_gcunroot(handler);
             _keyword_handlers.clear();
             janet_gcunroot(_zelph_peg);
             janet_deinit();
         }
+        if (_vm) janet_vm_free(_vm);
+
+        std::lock_guard<std::mutex> lock(s_engines_mutex);
+        s_engines.erase(this);
     }
 
+    // Makes an engine's VM the current Janet VM of the calling thread while
+    // it lives, and saves the VM back on destruction, so that the next use
+    // may come from another thread. Holds the engine's _vm_mutex throughout:
+    // a JanetVM must not be loaded into two threads at once. Must precede
+    // every use of the Janet API from outside Janet itself. Nests: an inner
+    // scope of the same engine is a no-op, one of another engine on the
+    // same thread parks the outer VM and restores it afterwards.
+    class VmScope
+    {
+    public:
+        explicit VmScope(Impl& impl)
+            : _impl(impl)
+            , _lock(impl._vm_mutex)
+            , _previous(s_active)
+            , _previous_instance(s_instance)
+        {
+            if (_previous != &_impl)
+            {
+                if (_previous) janet_vm_save(_previous->_vm);
+                janet_vm_load(_impl._vm);
+                s_active = &_impl;
+            }
+            s_instance = &_impl;
+        }
+
+        ~VmScope()
+        {
+            if (_previous != &_impl)
+            {
+                janet_vm_save(_impl._vm);
+                if (_previous) janet_vm_load(_previous->_vm);
+                s_active = _previous;
+            }
+            s_instance = _previous_instance;
+        }
+
+        VmScope(const VmScope&)            = delete;
+        VmScope& operator=(const VmScope&) = delete;
+
+    private:
+        Impl&                                  _impl;
+        std::unique_lock<std::recursive_mutex> _lock;
+        Impl*                                  _previous;
+        Impl*                                  _previous_instance;
+    };
+
     void init()
     {
-        _main_thread_id = std::this_thread::get_id();
+        _vm = janet_vm_alloc();
+        VmScope scope(*this); // loads the blank VM; janet_init sets it up
         janet_init();
         _janet_env = janet_core_env(nullptr);
         register_zelph_functions();
+        setup_output();
         setup_module_paths();
         setup_script_runner();
         setup_peg();
         setup_numbers();
     }
 
+    void setup_output()
+    {
+        _out_buffer = janet_buffer(256);
+        _err_buffer = janet_buffer(256);
+        janet_gcroot(janet_wrap_buffer(_out_buffer));
+        janet_gcroot(janet_wrap_buffer(_err_buffer));
+        janet_table_put(_janet_env, janet_ckeywordv("out"), janet_wrap_buffer(_out_buffer));
+        janet_table_put(_janet_env, janet_ckeywordv("err"), janet_wrap_buffer(_err_buffer));
+    }
+
+    // Called after each evaluation, before zelph prints anything itself, so
+    // the output keeps its order.
+    void flush_output() const
+    {
+        auto flush = [this](JanetBuffer* buffer, const io::OutputChannel channel)
+        {
+            if (!buffer || buffer->count == 0) return;
+
+            std::string text(reinterpret_cast<const char*>(buffer->data), buffer->count);
+            buffer->count = 0;
+
+            const bool newline = text.back() == '\n';
+            if (newline) text.pop_back();
+            _n->emit(channel, text, newline);
+        };
+        flush(_out_buffer, io::OutputChannel::Out);
+        flush(_err_buffer, io::OutputChannel::Error);
+    }
+
     void register_zelph_functions() const
     {
 // Helper to handle platform-specific Janet definitions
@@ -238,6 +352,12 @@ public:
         janet_def(_janet_env, "zelph/approx", wrap((JanetCFunction)janet_cfun_zelph_approx), "(zelph/approx pattern net-name)\nTag a fact pattern as a neural rule condition: creates (pattern nn net). "
                                                                                              "Desugared form of ≈net(pattern). Returns the pattern node.");
 
+        janet_def(_janet_env, "zelph/in-context", wrap((JanetCFunction)janet_cfun_zelph_in_context), "(zelph/in-context fact name)\nAdd a fact to the named context. A fact without a context holds in every "
+                                                                                                     "context. Returns the fact.");
+
+        janet_def(_janet_env, "zelph/valid", wrap((JanetCFunction)janet_cfun_zelph_valid), "(zelph/valid fact from to)\nSet the validity interval of a fact: from and to are integer time points "
+                                                                                           "(inclusive) or nil for an open end. Desugared form of the statement suffix @[from..to]. Returns the fact.");
+
         janet_def(_janet_env, "zelph/set-number-digits", wrap((JanetCFunction)janet_cfun_zelph_set_number_digits), "(zelph/set-number-digits digits)\nRegister the digit alphabet of the loaded number representation, as an "
                                                                                                                    "array of digit nodes or names in ascending order of value (e.g. [\"0\" \"1\"] for binary). "
                                                                                                                    "node_to_string then displays every nil-terminated cons list consisting solely of these digit "
@@ -311,6 +431,7 @@ public:
         // 7. :focused -> *Element (Returns the element instead of the container)
         // 8. :unquote -> ,identifier (Reference to a Janet variable)
         // 9. :selffact -> :pred X (self-fact sugar: desugars to (X pred X))
+        // 10. :validity -> @[from..to] (validity suffix of top-level facts)
         // Returns tagged tuples like [:atom "val"], [:list-compact "val"] or [:nested sub-stmt...] for C++ processing
         std::string peg_setup = R"zph(
             (def zelph-grammar
@@ -323,16 +444,18 @@ public:
                 # To use them as atoms, we define specific rules below.
                 :reserved (set " \t\r\n\0\v<\"(){}*>,¬")
 
-                # Identifiers
-                :symchars (if-not :reserved 1)
+                # Identifiers. Opening quotes (see ScriptEngine::set_quote_pairs)
+                # end an atom like the reserved characters.
+                :quote-open @QUOTE_OPEN@
+                :symchars (if-not (choice :reserved :quote-open) 1)
                 :var-underscore (* "_" (any :symchars))
                 :var-uppercase  (* (range "AZ") (not :symchars))
 
                 # A variable must start with underscore or be a single uppercase letter
                 :var-token (choice :var-underscore :var-uppercase)
 
-                # Atoms
-                :quoted (capture (* "\"" (any (if-not "\"" 1)) "\""))
+                # Atoms. Text in other quote pairs is passed on as "...".
+                :quoted @QUOTED@
 
                 # Normal atoms (sequences of non-reserved chars)
                 :raw-atom (capture (some :symchars))
@@ -419,24 +542,59 @@ public:
 
                 # A statement is a sequence of values separated by whitespace
                 # Used inside ( ... ) and at top level for facts
-                :stmt-any (sequence :val-any (any (sequence :s+ :val-any)))
+                :stmt-any (sequence :val-any (any (sequence :s+ (not "@[") :val-any)))
+
+                # Validity suffix of a top-level fact: @[1848..], @[..1990],
+                # @[1848..1870]. Syntax only -- desugars to
+                # (zelph/valid fact from to) with nil for an open end.
+                :time-point (capture (any (set "-0123456789")))
+                :tag-validity (group (* (constant :validity) "@[" :s* :time-point :s* ".." :s* :time-point :s* "]"))
 
                 # Top Level Parsing
                 # Everything is captured into a :root group, or a :conjunction if comma separated.
                 # C++ logic decides if it's a single value or a fact (S P O) based on element count.
-                :main (sequence :s* (choice
-                                        (group (* (constant :conjunction) :conj-cond (some (* :comma-sep :conj-cond))))
-                                        (group (* (constant :root) :stmt-any))) :s* -1)})
+                :statement (choice
+                             (group (* (constant :conjunction) :conj-cond (some (* :comma-sep :conj-cond))))
+                             (group (* (constant :root) :stmt-any (opt (* :s+ :tag-validity)))))
+                :main (sequence :s* :statement :s* -1)
+
+                # Where a statement that does not parse goes wrong: the
+                # position up to which it does (see syntax_error_offset).
+                :prefix (sequence :s* (opt :statement) :s* (position))})
 
             (defn zelph-safe-parse [peg text]
                (peg/match peg text))
         )zph";
 
+        auto literal = [](const std::string& text)
+        {
+            return "\"" + string::replace_all_copy(string::replace_all_copy(text, "\\", "\\\\"), "\"", "\\\"") + "\"";
+        };
+
+        std::string quote_open = "(choice \"\\\"\"";
+        std::string quoted     = "(choice (capture (* \"\\\"\" (any (if-not \"\\\"\" 1)) \"\\\"\"))";
+        for (const auto& [open, close] : _quote_pairs)
+        {
+            quote_open += " " + literal(open);
+            quoted += " (replace (* " + literal(open) + " (capture (any (if-not " + literal(close) + " 1))) " + literal(close) + ") ,zelph-quote-wrap)";
+        }
+        quote_open += ")";
+        quoted += ")";
+
+        peg_setup = "(defn zelph-quote-wrap [s] (string \"\\\"\" s \"\\\"\"))\n"
+                  + string::replace_all_copy(string::replace_all_copy(peg_setup, "@QUOTE_OPEN@", quote_open), "@QUOTED@", quoted);
+
         Janet out;
         int   status = janet_dostring(_janet_env, peg_setup.c_str(), "setup", &out);
         if (status != JANET_SIGNAL_OK) janet_stacktrace(nullptr, out);
 
+        janet_dostring(_janet_env,
+                       "(def zelph-prefix-peg (peg/compile (table/to-struct (merge zelph-grammar {:main :prefix}))))\n"
+                       "(defn zelph-syntax-error-offset [text] (last (peg/match zelph-prefix-peg text)))",
+                       "init",
+                       &out);
         janet_dostring(_janet_env, "(def zelph-peg (peg/compile zelph-grammar))", "init", &out);
+        if (janet_checktype(_zelph_peg, JANET_ABSTRACT)) janet_gcunroot(_zelph_peg);
         _zelph_peg = out;
         janet_gcroot(_zelph_peg);
     }
@@ -493,7 +651,7 @@ public:
             // It's a standard named Node (Atom)
             const uint8_t* str  = janet_unwrap_string(arg);
             std::string    wstr = reinterpret_cast<const char*>(str);
-            return _n->node(wstr, _n->lang());
+            return module_node(wstr, true);
         }
         else if (janet_checktype(arg, JANET_SYMBOL))
         {
@@ -524,17 +682,32 @@ public:
         {
             const uint8_t* str  = janet_unwrap_string(arg);
             std::string    wstr = reinterpret_cast<const char*>(str);
-
-            // Check regular named nodes
-            network::Node n = _n->get_node(wstr, _n->lang());
-            if (n) return n;
-
-            // Check core nodes (e.g. "~", "=>", "in", "..")
-            return _n->get_core_node(wstr);
+            return module_node(wstr, false);
         }
         return 0;
     }
 
+    // The node a name in a statement stands for, in the namespace of the
+    // module being read if any (see ScriptEngine::set_module_namespace).
+    // Without create, 0 if there is none.
+    network::Node module_node(const std::string& name, const bool create) const
+    {
+        auto existing = [this](const std::string& n)
+        {
+            // Regular named nodes first, then core nodes (e.g. "~", "=>", "in", "..")
+            network::Node nd = _n->get_node(n, _n->lang());
+            return nd ? nd : _n->get_core_node(n);
+        };
+
+        if (_module_namespace.empty() || name.find(':') != std::string::npos)
+            return create ? _n->node(name, _n->lang()) : existing(name);
+
+        const std::string qualified = _module_namespace + ":" + name;
+        if (network::Node nd = _n->get_node(qualified, _n->lang())) return nd;
+        if (network::Node nd = existing(name)) return nd;
+        return create ? _n->node(qualified, _n->lang()) : 0;
+    }
+
     // Check whether a fact exists in the graph without creating it.
     // Returns true if the fact (subject predicate object...) is known.
     static Janet janet_cfun_zelph_exists(int32_t argc, Janet* argv)
@@ -1087,6 +1260,60 @@ public:
         return res;
     }
 
+    // Add a fact to a named context (see Reasoning::add_to_context).
+    // Returns the fact.
+    static Janet janet_cfun_zelph_in_context(int32_t argc, Janet* argv)
+    {
+        janet_fixarity(argc, 2);
+        if (!s_instance) return janet_wrap_nil();
+
+        network::Node fact = zelph_unwrap_node(argv[0]);
+        if (!fact) janet_panicf("zelph/in-context: first argument must be a fact node");
+        const std::string context = reinterpret_cast<const char*>(janet_getstring(argv, 1));
+
+        std::string err;
+        try
+        {
+            s_instance->_n->add_to_context(fact, context);
+            return argv[0];
+        }
+        catch (const std::exception& e)
+        {
+            err = e.what();
+        }
+        janet_panicf("zelph/in-context: %s", err.c_str());
+        return janet_wrap_nil(); // unreachable
+    }
+
+    // Set the validity interval of a fact. from and to are integer time
+    // points or nil for an open end. Desugared form of the statement
+    // suffix @[from..to]. Returns the fact.
+    static Janet janet_cfun_zelph_valid(int32_t argc, Janet* argv)
+    {
+        janet_fixarity(argc, 3);
+        if (!s_instance) return janet_wrap_nil();
+
+        network::Node fact = zelph_unwrap_node(argv[0]);
+        if (!fact) janet_panicf("zelph/valid: first argument must be a fact node");
+
+        network::Reasoning::Validity validity;
+        if (!janet_checktype(argv[1], JANET_NIL)) validity.from = janet_getinteger64(argv, 1);
+        if (!janet_checktype(argv[2], JANET_NIL)) validity.to = janet_getinteger64(argv, 2);
+
+        std::string err;
+        try
+        {
+            s_instance->_n->set_validity(fact, validity);
+            return argv[0];
+        }
+        catch (const std::exception& e)
+        {
+            err = e.what();
+        }
+        janet_panicf("zelph/valid: %s", err.c_str());
+        return janet_wrap_nil(); // unreachable
+    }
+
     // Register the digit alphabet for &-literal display (inverse of the
     // &-input syntax). Digits are given in ascending order of value; the
     // base is the array length. C++ makes no assumptions about the digit
@@ -1466,15 +1693,16 @@ public:
     // nested janet_loop (script importing a script) is not supported by
     // Janet - and Janet's own module system is the right tool for that job.
     //
-    // Main thread only: the import pipeline executes Janet code in the main
-    // VM (_janet_env), which must not be entered from other Janet threads.
+    // Main thread only, i.e. the thread holding the engine's VmScope: the
+    // import pipeline executes Janet code in the main VM (_janet_env), which
+    // must not be entered from other Janet threads.
     static Janet janet_cfun_zelph_import(int32_t argc, Janet* argv)
     {
         janet_arity(argc, 1, -1);
         if (!s_instance) return janet_wrap_nil();
         if (s_instance->_log_janet_functions) s_instance->log_janet_call("zelph/import", argc, argv, true);
 
-        if (std::this_thread::get_id() != s_instance->_main_thread_id)
+        if (s_active != s_instance)
             janet_panicf("zelph/import: must be called from the main thread, not from ev/spawn-thread (the import pipeline is bound to the main Janet VM)");
 
         if (!s_instance->_import_handler)
@@ -1511,16 +1739,16 @@ public:
     // auto-run handling, format detection (.bin vs. Wikidata JSON), and
     // timing diagnostics.
     //
-    // Main thread only: the commands manipulate REPL state (auto_run,
-    // partial_load_mode), which is owned by the main thread and not
-    // synchronized.
+    // Main thread only (see janet_cfun_zelph_import): the commands
+    // manipulate REPL state (auto_run, partial_load_mode), which is owned by
+    // the main thread and not synchronized.
     static Janet command_impl(int32_t argc, Janet* argv, const char* name, const char* command)
     {
         janet_fixarity(argc, 1);
         if (!s_instance) return janet_wrap_nil();
         if (s_instance->_log_janet_functions) s_instance->log_janet_call(name, argc, argv, true);
 
-        if (std::this_thread::get_id() != s_instance->_main_thread_id)
+        if (s_active != s_instance)
             janet_panicf("%s: must be called from the main thread, not from ev/spawn-thread", name);
 
         if (!s_instance->_command_handler)
@@ -1587,7 +1815,7 @@ public:
         if (!var_to_name.empty())
         {
             s_instance->_n->set_query_collector(&results);
-            s_instance->_n->apply_rule(0, n);
+            s_instance->_n->apply_query(n);
             s_instance->_n->set_query_collector(nullptr);
         }
 
@@ -1978,7 +2206,10 @@ network::Node ScriptEngine::zelph_unwrap_node(Janet val)
     return 0;
 }
 
-ScriptEngine::Impl* ScriptEngine::Impl::s_instance = nullptr;
+std::mutex                       ScriptEngine::Impl::s_engines_mutex;
+std::set<ScriptEngine::Impl*>    ScriptEngine::Impl::s_engines;
+thread_local ScriptEngine::Impl* ScriptEngine::Impl::s_instance = ScriptEngine::Impl::sole_engine();
+thread_local ScriptEngine::Impl* ScriptEngine::Impl::s_active   = nullptr;
 
 ScriptEngine::ScriptEngine(network::Reasoning* reasoning)
     : _pImpl(new Impl(reasoning))
@@ -2012,6 +2243,8 @@ std::string ScriptEngine::get_janet_logging_status() const
 
 std::string ScriptEngine::parse_zelph_to_janet(const std::string& input) const
 {
+    Impl::VmScope scope(*_pImpl);
+
     JanetSymbol      match_sym = janet_csymbol("zelph-safe-parse");
     Janet            match_fun_out;
     JanetBindingType bt = janet_resolve(_pImpl->_janet_env, match_sym, &match_fun_out);
@@ -2048,6 +2281,29 @@ std::string ScriptEngine::parse_zelph_to_janet(const std::string& input) const
 
         if (val_count == 0) return "";
 
+        // Validity suffix: wrap the fact in (zelph/valid fact from to).
+        const Janet* last_data;
+        int32_t      last_len;
+        if (janet_indexed_view(root_data[root_len - 1], &last_data, &last_len) && last_len == 3
+            && janet_checktype(last_data[0], JANET_KEYWORD)
+            && std::string(reinterpret_cast<const char*>(janet_unwrap_keyword(last_data[0]))) == "validity")
+        {
+            if (val_count < 3) return ""; // Syntax error: only facts have a validity
+
+            auto time_point = [](Janet capture) -> std::string
+            {
+                std::string text(reinterpret_cast<const char*>(janet_unwrap_string(capture)));
+                if (text.empty()) return "nil";
+                if (text.find_first_of("0123456789") == std::string::npos || text.find('-', 1) != std::string::npos)
+                    return "(error \"Zelph: invalid time point " + text + "\")";
+                return text;
+            };
+
+            std::vector<Janet> fact_args(root_data + 1, root_data + root_len - 1);
+            return "(zelph/valid " + _pImpl->build_smart_call("zelph/fact", fact_args)
+                 + " " + time_point(last_data[1]) + " " + time_point(last_data[2]) + ")";
+        }
+
         if (val_count == 1)
         {
             // A bare parenthesized fact like (A rel B) at the top level is a syntax error:
@@ -2085,10 +2341,12 @@ std::string ScriptEngine::parse_zelph_to_janet(const std::string& input) const
 
 void ScriptEngine::process_janet(const std::string& code, bool is_zelph_ast)
 {
+    Impl::VmScope scope(*_pImpl);
     _pImpl->_scoped_variables.clear();
 
     Janet out;
     int   status = janet_dostring(_pImpl->_janet_env, code.c_str(), "zelph-script", &out);
+    _pImpl->flush_output();
 
     if (status != JANET_SIGNAL_OK)
     {
@@ -2115,9 +2373,30 @@ void ScriptEngine::process_janet(const std::string& code, bool is_zelph_ast)
                 string::node_to_string(_pImpl->_n, output, _pImpl->_n->lang(), n, 3);
                 if (!output.empty() && output != "??") _pImpl->_n->out(string::unmark_identifiers(output), true);
 
+                if (_pImpl->_n->parse_trace())
+                    _pImpl->_n->diagnostic(_pImpl->_n->describe_statement(n, _pImpl->has_scoped_variables()), true);
+
+                if (_pImpl->_n->active_stratum() != 0 && _pImpl->_n->parse_relation(n) == _pImpl->_n->core.Causes)
+                    _pImpl->_n->set_rule_stratum(n, _pImpl->_n->active_stratum());
+
                 if (_pImpl->has_scoped_variables())
                 {
-                    _pImpl->_n->apply_rule(0, n);
+                    const uint64_t answered = _pImpl->_n->answers_reported();
+                    _pImpl->_n->apply_query(n);
+
+                    if (_pImpl->_n->report_no_matches() && _pImpl->_n->answers_reported() == answered
+                        && _pImpl->_n->parse_relation(n) != _pImpl->_n->core.Causes)
+                        _pImpl->_n->out(_pImpl->_n->no_match_notice(n), true);
+
+                    if (_pImpl->_n->suggest_concepts() && _pImpl->_n->answers_reported() == answered)
+                    {
+                        const std::string hint = _pImpl->_n->unknown_concept_hint(n);
+                        if (!hint.empty()) _pImpl->_n->diagnostic(hint);
+                    }
+                }
+                else if (!_pImpl->_n->active_context().empty() && network::Zelph::is_hash(n))
+                {
+                    _pImpl->_n->add_to_context(n, _pImpl->_n->active_context());
                 }
             }
         }
@@ -2133,6 +2412,7 @@ void ScriptEngine::process_janet(const std::string& code, bool is_zelph_ast)
 
 void ScriptEngine::run_janet_script(const std::string& path, const std::vector<std::string>& args)
 {
+    Impl::VmScope scope(*_pImpl);
     _pImpl->clear_scoped_variables();
 
     Janet runner;
@@ -2160,7 +2440,19 @@ void ScriptEngine::run_janet_script(const std::string& path, const std::vector<s
         janet_gcunlock(gc_handle);
         throw std::runtime_error("Internal error: could not create fiber for zelph/run-script");
     }
-    fiber->env = _pImpl->_janet_env;
+    // Like the janet CLI, a program writes to the process streams: its
+    // environment overrides the :out and :err buffers of the REPL
+    // environment (see Impl::setup_output), so long-running programs
+    // print as they go.
+    JanetTable* program_env = janet_table(2);
+    program_env->proto      = _pImpl->_janet_env;
+    for (const auto& [binding, file_name] : {std::pair{"out", "stdout"}, std::pair{"err", "stderr"}})
+    {
+        Janet file = janet_wrap_nil();
+        janet_resolve(_pImpl->_janet_env, janet_csymbol(file_name), &file);
+        janet_table_put(program_env, janet_ckeywordv(binding), file);
+    }
+    fiber->env = program_env;
     janet_gcroot(janet_wrap_fiber(fiber));
     janet_gcunlock(gc_handle);
 
@@ -2224,9 +2516,11 @@ void ScriptEngine::run_janet_script(const std::string& path, const std::vector<s
 // Helper function to evaluate a Janet expression and return a Node (used by prune commands)
 network::Node ScriptEngine::evaluate_expression(const std::string& janet_code)
 {
+    Impl::VmScope scope(*_pImpl);
     _pImpl->_scoped_variables.clear(); // Reset scopes for new evaluation context
     Janet out;
     int   status = janet_dostring(_pImpl->_janet_env, janet_code.c_str(), "eval_expr", &out);
+    _pImpl->flush_output();
     if (status != JANET_SIGNAL_OK)
     {
         std::string err = "Janet error";
@@ -2242,8 +2536,262 @@ network::Node ScriptEngine::evaluate_expression(const std::string& janet_code)
     return zelph_unwrap_node(out);
 }
 
+size_t ScriptEngine::syntax_error_offset(const std::string& input) const
+{
+    Impl::VmScope scope(*_pImpl);
+
+    Janet offset_fun;
+    if (janet_resolve(_pImpl->_janet_env, janet_csymbol("zelph-syntax-error-offset"), &offset_fun) != JANET_BINDING_DEF) return std::string::npos;
+
+    Janet args[1] = {janet_cstringv(input.c_str())};
+    Janet result;
+    if (janet_pcall(janet_unwrap_function(offset_fun), 1, args, &result, nullptr) != JANET_SIGNAL_OK
+        || !janet_checktype(result, JANET_NUMBER))
+    {
+        return std::string::npos;
+    }
+    return static_cast<size_t>(janet_unwrap_number(result));
+}
+
+bool ScriptEngine::has_variables(const std::string& code)
+{
+    // Variables are the only quoted symbols the parser emits ('X); a quote
+    // inside a string literal is part of a name.
+    bool in_string = false;
+    for (size_t i = 0; i < code.size(); ++i)
+    {
+        if (in_string)
+        {
+            if (code[i] == '\\')
+                ++i;
+            else if (code[i] == '"')
+                in_string = false;
+        }
+        else if (code[i] == '"')
+        {
+            in_string = true;
+        }
+        else if (code[i] == '\'')
+        {
+            return true;
+        }
+    }
+    return false;
+}
+
+namespace
+{
+    void require_variables(const std::string& code)
+    {
+        if (code.empty()) throw std::runtime_error("Syntax error: Could not parse statement.");
+        if (!ScriptEngine::has_variables(code))
+            throw std::runtime_error("A query needs a variable (e.g. X ~ human); without one the statement would be stated as a fact");
+    }
+}
+
+ScriptEngine::QueryBindings ScriptEngine::query(const std::string& statement, const std::string& code, std::vector<double>* probabilities, std::vector<std::vector<network::Node>>* premises, network::Node* pattern)
+{
+    // Probabilities and premises are not cached, only the bindings.
+    const bool cacheable = !probabilities && !premises && _pImpl->_n->query_cache();
+    if (cacheable)
+    {
+        network::Node cached_pattern = 0;
+        if (auto cached = _pImpl->_n->cached_query(statement, cached_pattern))
+        {
+            if (pattern) *pattern = cached_pattern;
+            return std::move(*cached);
+        }
+    }
+
+    require_variables(code);
+
+    // Held across evaluation and matching: _scoped_variables belongs to
+    // this statement until it is cleared.
+    Impl::VmScope scope(*_pImpl);
+
+    network::Node n = evaluate_expression(code);
+    if (pattern) *pattern = n;
+
+    std::map<network::Node, std::string> var_to_name;
+    {
+        std::lock_guard<std::mutex> lock(_pImpl->_state_mutex);
+        for (const auto& [name, node] : _pImpl->_scoped_variables)
+        {
+            var_to_name[node] = name;
+        }
+    }
+
+    std::vector<std::shared_ptr<network::Variables>> results;
+
+    if (n && !var_to_name.empty())
+    {
+        _pImpl->_n->set_query_collector(&results);
+        try
+        {
+            _pImpl->_n->apply_query(n);
+        }
+        catch (...)
+        {
+            _pImpl->_n->set_query_collector(nullptr);
+            _pImpl->clear_scoped_variables();
+            throw;
+        }
+        _pImpl->_n->set_query_collector(nullptr);
+    }
+
+    _pImpl->clear_scoped_variables();
+
+    if (n && results.empty() && _pImpl->_n->suggest_concepts())
+    {
+        const std::string hint = _pImpl->_n->unknown_concept_hint(n);
+        if (!hint.empty()) throw std::runtime_error(hint);
+    }
+
+    QueryBindings bindings;
+    bindings.reserve(results.size());
+    if (probabilities) probabilities->clear();
+    if (premises) premises->clear();
+
+    for (const auto& vars : results)
+    {
+        if (probabilities) probabilities->push_back(_pImpl->_n->answer_probability(n, *vars));
+        if (premises) premises->push_back(_pImpl->_n->answer_premises(n, *vars));
+
+        auto& entry = bindings.emplace_back();
+        for (const auto& [var_node, bound_node] : *vars)
+        {
+            auto it = var_to_name.find(var_node);
+            if (it != var_to_name.end())
+                entry[it->second] = bound_node;
+        }
+    }
+
+    if (cacheable && n && !var_to_name.empty()) _pImpl->_n->cache_query(statement, n, bindings);
+    return bindings;
+}
+
+size_t ScriptEngine::query_each(const std::string& code, const AnswerHandler& handler)
+{
+    require_variables(code);
+
+    Impl::VmScope scope(*_pImpl); // see query
+
+    network::Node n = evaluate_expression(code);
+
+    std::map<network::Node, std::string> var_to_name;
+    {
+        std::lock_guard<std::mutex> lock(_pImpl->_state_mutex);
+        for (const auto& [name, node] : _pImpl->_scoped_variables)
+        {
+            var_to_name[node] = name;
+        }
+    }
+
+    size_t count = 0;
+
+    if (n && !var_to_name.empty())
+    {
+        _pImpl->_n->set_answer_handler([&](const network::Variables& vars)
+                                       {
+            QueryBindings::value_type answer;
+            for (const auto& [var_node, bound_node] : vars)
+            {
+                auto it = var_to_name.find(var_node);
+                if (it != var_to_name.end())
+                    answer[it->second] = bound_node;
+            }
+            ++count;
+            return handler(answer); });
+        try
+        {
+            _pImpl->_n->apply_query(n);
+        }
+        catch (...)
+        {
+            _pImpl->_n->set_answer_handler(nullptr);
+            _pImpl->clear_scoped_variables();
+            throw;
+        }
+        _pImpl->_n->set_answer_handler(nullptr);
+    }
+
+    _pImpl->clear_scoped_variables();
+
+    if (n && count == 0 && _pImpl->_n->suggest_concepts())
+    {
+        const std::string hint = _pImpl->_n->unknown_concept_hint(n);
+        if (!hint.empty()) throw std::runtime_error(hint);
+    }
+
+    return count;
+}
+
+ScriptEngine::Rows ScriptEngine::call_rows(const std::string& function, const std::string& arg)
+{
+    Impl::VmScope scope(*_pImpl);
+    _pImpl->_scoped_variables.clear();
+
+    Janet fn;
+    if (janet_resolve(_pImpl->_janet_env, janet_csymbol(function.c_str()), &fn) == JANET_BINDING_NONE
+        || !janet_checktype(fn, JANET_FUNCTION))
+    {
+        throw std::runtime_error("Janet function '" + function + "' is not defined");
+    }
+
+    Janet       jarg = janet_cstringv(arg.c_str());
+    Janet       result;
+    JanetFiber* fiber = nullptr;
+
+    const JanetSignal signal = janet_pcall(janet_unwrap_function(fn), 1, &jarg, &result, &fiber);
+    _pImpl->flush_output();
+    if (signal != JANET_SIGNAL_OK)
+    {
+        std::string err = "Janet error in " + function;
+        if (janet_checktype(result, JANET_STRING))
+            err += ": " + std::string(reinterpret_cast<const char*>(janet_unwrap_string(result)));
+        else if (janet_checktype(result, JANET_BUFFER))
+        {
+            JanetBuffer* b = janet_unwrap_buffer(result);
+            err += ": " + std::string(reinterpret_cast<const char*>(b->data), b->count);
+        }
+        throw std::runtime_error(err);
+    }
+
+    const Janet* rows_data;
+    int32_t      rows_len;
+    if (!janet_indexed_view(result, &rows_data, &rows_len))
+        throw std::runtime_error(function + " did not return an array of rows");
+
+    Rows rows;
+    rows.reserve(rows_len);
+    for (int32_t i = 0; i < rows_len; ++i)
+    {
+        const Janet* data;
+        int32_t      len;
+        if (!janet_indexed_view(rows_data[i], &data, &len))
+            throw std::runtime_error(function + " returned a row that is not an array");
+
+        auto& row = rows.emplace_back();
+        row.reserve(len);
+        for (int32_t j = 0; j < len; ++j)
+        {
+            if (janet_checktype(data[j], JANET_NIL))
+                row.emplace_back(std::nullopt);
+            else if (janet_checktype(data[j], JANET_STRING))
+            {
+                const uint8_t* str = janet_unwrap_string(data[j]);
+                row.emplace_back(std::string(reinterpret_cast<const char*>(str), janet_string_length(str)));
+            }
+            else
+                row.emplace_back(Impl::format_janet(data[j]));
+        }
+    }
+    return rows;
+}
+
 void ScriptEngine::set_script_args(const std::vector<std::string>& args)
 {
+    Impl::VmScope scope(*_pImpl);
     JanetArray* jargs = janet_array(static_cast<int32_t>(args.size()));
     for (const auto& arg : args)
     {
@@ -2252,6 +2800,16 @@ void ScriptEngine::set_script_args(const std::vector<std::string>& args)
     janet_table_put(_pImpl->_janet_env, janet_ckeywordv("args"), janet_wrap_array(jargs));
 }
 
+void ScriptEngine::set_module_namespace(const std::string& name_space)
+{
+    _pImpl->_module_namespace = name_space;
+}
+
+const std::string& ScriptEngine::module_namespace() const
+{
+    return _pImpl->_module_namespace;
+}
+
 void ScriptEngine::set_import_handler(ImportHandler handler)
 {
     _pImpl->_import_handler = std::move(handler);
@@ -2262,6 +2820,36 @@ void ScriptEngine::set_command_handler(CommandHandler handler)
     _pImpl->_command_handler = std::move(handler);
 }
 
+void ScriptEngine::set_quote_pairs(const std::vector<std::pair<std::string, std::string>>& pairs)
+{
+    for (const auto& [open, close] : pairs)
+    {
+        for (const std::string& delimiter : {open, close})
+        {
+            if (delimiter.empty())
+            {
+                throw std::invalid_argument("Quote characters must not be empty");
+            }
+            if (delimiter.find_first_of(" \t\r\n\v<\"(){}*>,") != std::string::npos || delimiter.find("\u00ac") != std::string::npos)
+            {
+                throw std::invalid_argument("Quote characters must not contain whitespace or reserved characters: " + delimiter);
+            }
+        }
+    }
+
+    _pImpl->_quote_pairs = pairs;
+    if (_pImpl->_janet_env)
+    {
+        Impl::VmScope scope(*_pImpl);
+        _pImpl->setup_peg();
+    }
+}
+
+const std::vector<std::pair<std::string, std::string>>& ScriptEngine::quote_pairs() const
+{
+    return _pImpl->_quote_pairs;
+}
+
 bool ScriptEngine::has_keyword(const std::string& keyword) const
 {
     return _pImpl->_keyword_handlers.count(keyword) > 0;
@@ -2269,6 +2857,8 @@ bool ScriptEngine::has_keyword(const std::string& keyword) const
 
 bool ScriptEngine::invoke_keyword(const std::string& keyword, const std::string& text, const bool force)
 {
+    Impl::VmScope scope(*_pImpl);
+
     auto it = _pImpl->_keyword_handlers.find(keyword);
     if (it == _pImpl->_keyword_handlers.end())
         throw std::runtime_error("No handler registered for keyword '" + keyword + "'");
@@ -2280,7 +2870,9 @@ bool ScriptEngine::invoke_keyword(const std::string& keyword, const std::string&
     Janet          result;
     JanetFiber*    fiber = nullptr;
 
-    if (janet_pcall(f, 1, &arg, &result, &fiber) != JANET_SIGNAL_OK)
+    const JanetSignal signal = janet_pcall(f, 1, &arg, &result, &fiber);
+    _pImpl->flush_output();
+    if (signal != JANET_SIGNAL_OK)
     {
         std::string err = "Janet error in handler for keyword '" + keyword + "'";
         if (janet_checktype(result, JANET_STRING))
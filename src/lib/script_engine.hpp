@@ -28,7 +28,10 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "network/network_types.hpp" // For network::Node
 
 #include <functional>
+#include <map>
+#include <optional>
 #include <string>
+#include <utility>
 #include <vector>
 
 struct JanetAbstractType;
@@ -63,6 +66,15 @@ namespace zelph
         // Parse zelph syntax to Janet AST
         std::string parse_zelph_to_janet(const std::string& input) const;
 
+        // For input that parse_zelph_to_janet rejects: the byte offset up to
+        // which it parses as a statement, i.e. where the syntax error is, or
+        // std::string::npos if that cannot be told.
+        size_t syntax_error_offset(const std::string& input) const;
+
+        // Whether code, as returned by parse_zelph_to_janet, mentions a
+        // variable. A statement without one states a fact when evaluated.
+        static bool has_variables(const std::string& code);
+
         // Execute Janet code (either raw or transformed zelph AST)
         // is_zelph_ast determines how the output is handled/printed
         void process_janet(const std::string& code, bool is_zelph_ast);
@@ -79,9 +91,50 @@ namespace zelph
         // Evaluate an expression and return a single Node (used for patterns/pruning)
         network::Node evaluate_expression(const std::string& janet_code);
 
+        // Answer a zelph statement containing variables without printing
+        // anything. code is the statement as parse_zelph_to_janet returned
+        // it, so that callers checking the syntax first parse it only once;
+        // the statement itself is the key of the query cache. Each element
+        // is one answer, mapping the variable names used in the statement to
+        // their bound nodes. A statement without variables is rejected,
+        // since evaluating it would state the fact. Same collector path as
+        // zelph/query.
+        // If probabilities is given, it receives the probability of each
+        // answer (see network::Reasoning::answer_probability). With concept
+        // suggestions on, a statement without answers that mentions an
+        // unknown concept throws (see Reasoning::unknown_concept_hint). If
+        // premises is given, it receives the facts each answer matched (see
+        // network::Reasoning::answer_premises). If pattern is given, it
+        // receives the node the statement was parsed into.
+        using QueryBindings = std::vector<std::map<std::string, network::Node>>;
+        QueryBindings query(const std::string& statement, const std::string& code, std::vector<double>* probabilities = nullptr, std::vector<std::vector<network::Node>>* premises = nullptr, network::Node* pattern = nullptr);
+
+        // Like query, but hands each answer to the handler as soon as it is
+        // found instead of collecting them (see
+        // network::Reasoning::set_answer_handler); the query stops when the
+        // handler returns false. Returns the number of answers handed over.
+        using AnswerHandler = std::function<bool(const QueryBindings::value_type& answer)>;
+        size_t query_each(const std::string& code, const AnswerHandler& handler);
+
+        // Call the Janet function bound to `function` in the script
+        // environment with one string argument. It must return an array of
+        // arrays whose elements are strings or nil (std::nullopt). Used for
+        // result sets computed by scripts, e.g. sparql-rows in
+        // stdlib/sparql.zph.
+        using Rows = std::vector<std::vector<std::optional<std::string>>>;
+        Rows call_rows(const std::string& function, const std::string& arg);
+
         // Inject arguments into the script environment (for script files with args)
         void set_script_args(const std::vector<std::string>& args);
 
+        // Namespace of the module being read by .include, empty outside of
+        // modules. An unqualified name (one without ':') in a statement then
+        // stands for "<namespace>:name" if that exists, else for an existing
+        // name outside the module, so that modules share the vocabulary
+        // defined before them; other names are created in the namespace.
+        void               set_module_namespace(const std::string& name_space);
+        const std::string& module_namespace() const;
+
         // Handler backing the Janet function zelph/import. It delegates to
         // the REPL's .import implementation (path resolution including the
         // standard library, .zph line processing, argument passing). Set by
@@ -101,6 +154,15 @@ namespace zelph
 
         std::string get_janet_logging_status() const;
 
+        // Additional quote pairs accepted by the zelph parser, e.g.
+        // {"„", "“"} or {"«", "»"}. Text between them is read like "...";
+        // the standard double quote always remains available. Delimiters
+        // must not contain whitespace or reserved characters. Replaces the
+        // previous pairs and recompiles the grammar.
+        void set_quote_pairs(const std::vector<std::pair<std::string, std::string>>& pairs);
+
+        const std::vector<std::pair<std::string, std::string>>& quote_pairs() const;
+
         bool has_keyword(const std::string& keyword) const;
 
         bool invoke_keyword(const std::string& keyword, const std::string& text, const bool force);
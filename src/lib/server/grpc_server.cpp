@@ -0,0 +1,244 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "grpc_server.hpp"
+
+#include "interactive.hpp"
+#include "process_error.hpp"
+#include "zelph.grpc.pb.h"
+
+#include <grpcpp/grpcpp.h>
+
+#include <chrono>
+#include <condition_variable>
+#include <deque>
+#include <mutex>
+#include <set>
+#include <stdexcept>
+
+using namespace zelph;
+using namespace zelph::server;
+
+namespace
+{
+    grpc::Status status(const console::process_error& ex)
+    {
+        grpc::StatusCode code = grpc::StatusCode::INVALID_ARGUMENT;
+        switch (ex.kind())
+        {
+        case console::ProcessErrorKind::Cancelled:
+            code = grpc::StatusCode::CANCELLED;
+            break;
+        case console::ProcessErrorKind::ResourceLimit:
+            code = grpc::StatusCode::RESOURCE_EXHAUSTED;
+            break;
+        case console::ProcessErrorKind::Reasoning:
+            code = grpc::StatusCode::INTERNAL;
+            break;
+        case console::ProcessErrorKind::Assertion:
+            code = grpc::StatusCode::FAILED_PRECONDITION;
+            break;
+        default:
+            break;
+        }
+        return {code, std::string(console::to_string(ex.kind())) + ": " + ex.reason()};
+    }
+
+    // Runs the body of a call and turns what it throws into a status.
+    template <typename Call>
+    grpc::Status answer(const Call& call)
+    {
+        try
+        {
+            call();
+            return grpc::Status::OK;
+        }
+        catch (const console::process_error& ex)
+        {
+            return status(ex);
+        }
+        catch (const std::exception& ex)
+        {
+            return {grpc::StatusCode::INTERNAL, ex.what()};
+        }
+    }
+
+    // The deductions a StreamDeductions call has yet to send.
+    struct Subscriber
+    {
+        std::deque<v1::Deduction> pending;
+    };
+}
+
+class GrpcServer::Impl final : public v1::Zelph::Service
+{
+public:
+    explicit Impl(const console::Interactive& interactive)
+        : _interactive(interactive)
+    {
+    }
+
+    grpc::Status AssertFacts(grpc::ServerContext*, const v1::AssertFactsRequest* request, v1::AssertFactsResponse* response) override
+    {
+        return answer([&]
+                      {
+            std::vector<console::Interactive::FactIds> triples;
+            for (const v1::Fact& fact : request->facts())
+                triples.push_back({_interactive.intern(fact.subject()),
+                                   _interactive.intern(fact.predicate()),
+                                   _interactive.intern(fact.object())});
+            for (const uint64_t id : _interactive.add_facts(triples))
+                response->add_ids(id); });
+    }
+
+    grpc::Status Query(grpc::ServerContext*, const v1::QueryRequest* request, v1::QueryResponse* response) override
+    {
+        return answer([&]
+                      {
+            for (const auto& binding : _interactive.query(request->query()))
+            {
+                auto& values = *response->add_answers()->mutable_values();
+                for (const auto& [variable, value] : binding)
+                    values[variable] = value;
+            } });
+    }
+
+    grpc::Status Run(grpc::ServerContext*, const v1::RunRequest*, v1::RunResponse* response) override
+    {
+        return answer([&]
+                      {
+            _interactive.run(false, false, false);
+            response->set_fixpoint_reached(_interactive.fixpoint_reached()); });
+    }
+
+    grpc::Status StreamDeductions(grpc::ServerContext* context, const v1::StreamDeductionsRequest*, grpc::ServerWriter<v1::Deduction>* writer) override
+    {
+        Subscriber                   subscriber;
+        std::unique_lock<std::mutex> lock(_mtx_subscribers);
+        _subscribers.insert(&subscriber);
+
+        // Tells the client that it is subscribed.
+        lock.unlock();
+        bool open = writer->SendInitialMetadata();
+        lock.lock();
+        while (open && !_stopping && !context->IsCancelled())
+        {
+            // Cancellation is not signalled, so it is polled.
+            _deduced.wait_for(lock, std::chrono::milliseconds(200), [&]
+                              { return _stopping || !subscriber.pending.empty(); });
+
+            while (open && !subscriber.pending.empty())
+            {
+                const v1::Deduction deduction = std::move(subscriber.pending.front());
+                subscriber.pending.pop_front();
+                lock.unlock();
+                open = writer->Write(deduction);
+                lock.lock();
+            }
+        }
+
+        _subscribers.erase(&subscriber);
+        return grpc::Status::OK;
+    }
+
+    // Called by the watch on a reasoning thread.
+    void deliver(const console::Interactive::Deduction& deduction)
+    {
+        std::lock_guard<std::mutex> lock(_mtx_subscribers);
+        if (_subscribers.empty()) return;
+
+        v1::Deduction message;
+        message.set_fact(deduction.fact);
+        message.set_rule(deduction.rule);
+        message.set_fact_text(deduction.fact_text);
+        message.set_rule_text(deduction.rule_text);
+        for (Subscriber* subscriber : _subscribers)
+            subscriber->pending.push_back(message);
+        _deduced.notify_all();
+    }
+
+    void set_stopping(const bool stopping)
+    {
+        std::lock_guard<std::mutex> lock(_mtx_subscribers);
+        _stopping = stopping;
+        _deduced.notify_all();
+    }
+
+    const console::Interactive&   _interactive;
+    std::unique_ptr<grpc::Server> _server;
+    uint64_t                      _watch{0};
+
+private:
+    std::mutex              _mtx_subscribers;
+    std::condition_variable _deduced;
+    std::set<Subscriber*>   _subscribers;     // guarded by _mtx_subscribers
+    bool                    _stopping{false}; // guarded by _mtx_subscribers
+};
+
+GrpcServer::GrpcServer(const console::Interactive& interactive)
+    : _pImpl(std::make_unique<Impl>(interactive))
+{
+    _pImpl->_watch = interactive.watch([impl = _pImpl.get()](const console::Interactive::Change& change)
+                                       {
+        if (change.deduction) impl->deliver(*change.deduction); });
+}
+
+GrpcServer::~GrpcServer()
+{
+    stop();
+    _pImpl->_interactive.unwatch(_pImpl->_watch);
+}
+
+uint16_t GrpcServer::start(const std::string& address)
+{
+    if (_pImpl->_server) throw std::runtime_error("The server is already listening");
+
+    _pImpl->set_stopping(false);
+
+    int                 port = 0;
+    grpc::ServerBuilder builder;
+    builder.AddListeningPort(address, grpc::InsecureServerCredentials(), &port);
+    builder.RegisterService(_pImpl.get());
+    _pImpl->_server = builder.BuildAndStart();
+
+    if (!_pImpl->_server || port == 0)
+    {
+        _pImpl->_server.reset();
+        throw std::runtime_error("Cannot listen on " + address);
+    }
+    return static_cast<uint16_t>(port);
+}
+
+void GrpcServer::stop()
+{
+    if (!_pImpl->_server) return;
+
+    // Ends the StreamDeductions calls; the others get a second to finish
+    // before they are cancelled.
+    _pImpl->set_stopping(true);
+    _pImpl->_server->Shutdown(std::chrono::system_clock::now() + std::chrono::seconds(1));
+    _pImpl->_server->Wait();
+    _pImpl->_server.reset();
+}
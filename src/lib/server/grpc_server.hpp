@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <zelph_export.h>
+
+#include <cstdint>
+#include <memory>
+#include <string>
+
+namespace zelph::console
+{
+    class Interactive;
+}
+
+namespace zelph::server
+{
+    // Exposes an Interactive as the gRPC service zelph.v1.Zelph defined in
+    // zelph.proto: AssertFacts, Query, Run and StreamDeductions, which
+    // pushes each fact to its subscribers as soon as a rule deduces it. Errors
+    // in statements are answered with INVALID_ARGUMENT, cancellation with
+    // CANCELLED, resource limits with RESOURCE_EXHAUSTED and other reasoning
+    // errors with INTERNAL, each with the reason as message. Calls are
+    // served on gRPC's threads and run concurrently as far as the
+    // Interactive allows.
+    //
+    // The server follows the deductions through a watch (see
+    // Interactive::watch), so the instance's own callbacks stay as they are.
+    // The Interactive must outlive it. Only built with ZELPH_GRPC.
+    class ZELPH_EXPORT GrpcServer
+    {
+    public:
+        explicit GrpcServer(const console::Interactive& interactive);
+        ~GrpcServer();
+
+        // Listens on an address such as "0.0.0.0:50051" (port 0 picks a
+        // free one) without transport security and returns the port.
+        // stop() or the destructor ends the open calls and stops listening.
+        // Throws std::runtime_error if the address cannot be bound or the
+        // server is already listening.
+        uint16_t start(const std::string& address);
+        void     stop();
+
+        GrpcServer(const GrpcServer&)            = delete;
+        GrpcServer& operator=(const GrpcServer&) = delete;
+
+    private:
+        class Impl;
+        std::unique_ptr<Impl> _pImpl;
+    };
+}
@@ -0,0 +1,854 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "http_server.hpp"
+
+#include "interactive.hpp"
+#include "io/json.hpp"
+#include "process_error.hpp"
+
+#include <algorithm>
+#include <array>
+#include <cctype>
+#include <chrono>
+#include <condition_variable>
+#include <deque>
+#include <optional>
+#include <stdexcept>
+
+#ifdef _WIN32
+    #include <winsock2.h>
+    #include <ws2tcpip.h>
+#else
+    #include <arpa/inet.h>
+    #include <netinet/in.h>
+    #include <poll.h>
+    #include <sys/socket.h>
+    #include <sys/time.h>
+    #include <unistd.h>
+#endif
+
+using namespace zelph;
+using namespace zelph::server;
+
+namespace
+{
+#ifdef _WIN32
+    using socket_t                    = SOCKET;
+    constexpr socket_t invalid_socket = INVALID_SOCKET;
+
+    void close_socket(const socket_t s) { closesocket(s); }
+    void shutdown_socket(const socket_t s) { shutdown(s, SD_BOTH); }
+    int  poll_socket(pollfd* fds, const unsigned long n, const int timeout) { return WSAPoll(fds, n, timeout); }
+
+    // Winsock must be initialized once per process before the first socket.
+    void init_sockets()
+    {
+        static const bool initialized = []
+        {
+            WSADATA data;
+            return WSAStartup(MAKEWORD(2, 2), &data) == 0;
+        }();
+        if (!initialized) throw std::runtime_error("Could not initialize Winsock");
+    }
+#else
+    using socket_t                    = int;
+    constexpr socket_t invalid_socket = -1;
+
+    void close_socket(const socket_t s) { close(s); }
+    void shutdown_socket(const socket_t s) { shutdown(s, SHUT_RDWR); }
+    int  poll_socket(pollfd* fds, const nfds_t n, const int timeout) { return poll(fds, n, timeout); }
+    void init_sockets() {}
+#endif
+
+#ifdef MSG_NOSIGNAL
+    constexpr int send_flags = MSG_NOSIGNAL; // a closed peer must not raise SIGPIPE
+#else
+    constexpr int send_flags = 0;
+#endif
+
+    constexpr size_t max_header_size = 64 * 1024;
+    constexpr size_t max_body_size   = 16 * 1024 * 1024;
+
+    Response error(const int status, const std::string& kind, const std::string& reason, const size_t column = 0)
+    {
+        Response response;
+        response.status = status;
+        response.body   = "{\"error\": {\"kind\": " + io::json_quote(kind) + ", \"reason\": " + io::json_quote(reason);
+        if (column != 0) response.body += ", \"column\": " + std::to_string(column);
+        response.body += "}}";
+        return response;
+    }
+
+    Response error(const console::process_error& ex)
+    {
+        const bool reasoning = ex.kind() == console::ProcessErrorKind::Reasoning
+                            || ex.kind() == console::ProcessErrorKind::Cancelled
+                            || ex.kind() == console::ProcessErrorKind::ResourceLimit;
+        return error(reasoning ? 500 : 400, console::to_string(ex.kind()), ex.reason(), ex.column());
+    }
+
+    // The member of a JSON object body that must be a string.
+    std::string string_member(const io::JsonValue& object, const std::string& key)
+    {
+        const io::JsonValue* value = object.find(key);
+        if (!value || value->type != io::JsonValue::Type::String)
+            throw std::invalid_argument("expected a string \"" + key + "\"");
+        return value->text;
+    }
+
+    io::JsonValue parse_body(const Request& request)
+    {
+        io::JsonValue body;
+        try
+        {
+            body = io::JsonParser(request.body, "request").parse();
+        }
+        catch (const std::runtime_error& ex)
+        {
+            throw std::invalid_argument(ex.what());
+        }
+        if (body.type != io::JsonValue::Type::Object) throw std::invalid_argument("expected a JSON object");
+        return body;
+    }
+
+    std::string fact_json(const console::Interactive::Fact& fact)
+    {
+        std::string objects;
+        for (const auto& object : fact.objects)
+            objects += (objects.empty() ? "" : ", ") + io::json_quote(object);
+        return "{\"id\": " + std::to_string(fact.id) + ", \"subject\": " + io::json_quote(fact.subject)
+             + ", \"predicate\": " + io::json_quote(fact.predicate) + ", \"objects\": [" + objects
+             + "], \"deduced\": " + (fact.deduced ? "true" : "false") + "}";
+    }
+
+    // SHA-1 (RFC 3174) and base64, as much as the WebSocket handshake needs.
+    std::array<uint8_t, 20> sha1(const std::string& text)
+    {
+        uint32_t h[5] = {0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0};
+
+        std::string message = text + '\x80';
+        while (message.size() % 64 != 56)
+            message += '\0';
+        const uint64_t bits = static_cast<uint64_t>(text.size()) * 8;
+        for (int shift = 56; shift >= 0; shift -= 8)
+            message += static_cast<char>((bits >> shift) & 0xff);
+
+        auto rotate = [](const uint32_t value, const int n)
+        { return (value << n) | (value >> (32 - n)); };
+
+        for (size_t chunk = 0; chunk < message.size(); chunk += 64)
+        {
+            uint32_t w[80];
+            for (int i = 0; i < 16; ++i)
+                w[i] = static_cast<uint32_t>(static_cast<uint8_t>(message[chunk + 4 * i])) << 24
+                     | static_cast<uint32_t>(static_cast<uint8_t>(message[chunk + 4 * i + 1])) << 16
+                     | static_cast<uint32_t>(static_cast<uint8_t>(message[chunk + 4 * i + 2])) << 8
+                     | static_cast<uint32_t>(static_cast<uint8_t>(message[chunk + 4 * i + 3]));
+            for (int i = 16; i < 80; ++i)
+                w[i] = rotate(w[i - 3] ^ w[i - 8] ^ w[i - 14] ^ w[i - 16], 1);
+
+            uint32_t a = h[0], b = h[1], c = h[2], d = h[3], e = h[4];
+            for (int i = 0; i < 80; ++i)
+            {
+                uint32_t f, k;
+                if (i < 20)
+                    f = (b & c) | (~b & d), k = 0x5A827999;
+                else if (i < 40)
+                    f = b ^ c ^ d, k = 0x6ED9EBA1;
+                else if (i < 60)
+                    f = (b & c) | (b & d) | (c & d), k = 0x8F1BBCDC;
+                else
+                    f = b ^ c ^ d, k = 0xCA62C1D6;
+                const uint32_t next = rotate(a, 5) + f + e + k + w[i];
+                e                   = d;
+                d                   = c;
+                c                   = rotate(b, 30);
+                b                   = a;
+                a                   = next;
+            }
+            h[0] += a;
+            h[1] += b;
+            h[2] += c;
+            h[3] += d;
+            h[4] += e;
+        }
+
+        std::array<uint8_t, 20> digest{};
+        for (int i = 0; i < 20; ++i)
+            digest[i] = static_cast<uint8_t>(h[i / 4] >> (24 - 8 * (i % 4)));
+        return digest;
+    }
+
+    std::string base64(const uint8_t* data, const size_t size)
+    {
+        static const char* const digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+
+        std::string encoded;
+        for (size_t i = 0; i < size; i += 3)
+        {
+            const uint32_t group = static_cast<uint32_t>(data[i]) << 16
+                                 | (i + 1 < size ? static_cast<uint32_t>(data[i + 1]) << 8 : 0)
+                                 | (i + 2 < size ? static_cast<uint32_t>(data[i + 2]) : 0);
+            encoded += digits[(group >> 18) & 63];
+            encoded += digits[(group >> 12) & 63];
+            encoded += i + 1 < size ? digits[(group >> 6) & 63] : '=';
+            encoded += i + 2 < size ? digits[group & 63] : '=';
+        }
+        return encoded;
+    }
+
+    // The Sec-WebSocket-Accept answer to a Sec-WebSocket-Key (RFC 6455).
+    std::string websocket_accept(const std::string& key)
+    {
+        const auto digest = sha1(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11");
+        return base64(digest.data(), digest.size());
+    }
+
+    // An unmasked, unfragmented frame from the server: 0x1 text, 0x8
+    // close, 0x9 ping, 0xA pong.
+    std::string websocket_frame(const uint8_t opcode, const std::string& payload)
+    {
+        std::string frame(1, static_cast<char>(0x80 | opcode));
+        if (payload.size() < 126)
+        {
+            frame += static_cast<char>(payload.size());
+        }
+        else if (payload.size() < 65536)
+        {
+            frame += static_cast<char>(126);
+            frame += static_cast<char>(payload.size() >> 8);
+            frame += static_cast<char>(payload.size() & 0xff);
+        }
+        else
+        {
+            frame += static_cast<char>(127);
+            for (int shift = 56; shift >= 0; shift -= 8)
+                frame += static_cast<char>((static_cast<uint64_t>(payload.size()) >> shift) & 0xff);
+        }
+        return frame + payload;
+    }
+
+    // Takes the first complete frame off data, which holds what a client
+    // sent; false if it is not complete yet. Client frames are masked.
+    struct ClientFrame
+    {
+        uint8_t     opcode{0};
+        std::string payload;
+    };
+    bool take_websocket_frame(std::string& data, ClientFrame& frame)
+    {
+        if (data.size() < 2) return false;
+        const auto byte   = [&data](size_t i)
+        { return static_cast<uint8_t>(data[i]); };
+        size_t     header = 2;
+        uint64_t   length = byte(1) & 0x7f;
+        if (length == 126)
+        {
+            if (data.size() < 4) return false;
+            length = (uint64_t{byte(2)} << 8) | byte(3);
+            header = 4;
+        }
+        else if (length == 127)
+        {
+            if (data.size() < 10) return false;
+            length = 0;
+            for (size_t i = 2; i < 10; ++i)
+                length = (length << 8) | byte(i);
+            header = 10;
+        }
+        const bool masked = (byte(1) & 0x80) != 0;
+        if (masked) header += 4;
+        if (length > max_body_size) throw std::runtime_error("WebSocket frame too large");
+        if (data.size() < header + length) return false;
+
+        frame.opcode = byte(0) & 0x0f;
+        frame.payload.assign(data, header, static_cast<size_t>(length));
+        if (masked)
+            for (size_t i = 0; i < frame.payload.size(); ++i)
+                frame.payload[i] = static_cast<char>(frame.payload[i] ^ data[header - 4 + i % 4]);
+        data.erase(0, header + static_cast<size_t>(length));
+        return true;
+    }
+
+    // The value of a parameter in the query string of a request target,
+    // percent-decoded, "+" standing for a space.
+    std::optional<std::string> query_parameter(const std::string& target, const std::string& key)
+    {
+        const size_t question = target.find('?');
+        if (question == std::string::npos) return std::nullopt;
+
+        size_t start = question + 1;
+        while (start <= target.size())
+        {
+            size_t end = target.find('&', start);
+            if (end == std::string::npos) end = target.size();
+            const std::string parameter = target.substr(start, end - start);
+            const size_t      equals    = parameter.find('=');
+            if (parameter.substr(0, equals) == key)
+            {
+                std::string value;
+                const std::string encoded = equals == std::string::npos ? "" : parameter.substr(equals + 1);
+                for (size_t i = 0; i < encoded.size(); ++i)
+                {
+                    if (encoded[i] == '+')
+                        value += ' ';
+                    else if (encoded[i] == '%' && i + 2 < encoded.size() && std::isxdigit(static_cast<unsigned char>(encoded[i + 1]))
+                             && std::isxdigit(static_cast<unsigned char>(encoded[i + 2])))
+                    {
+                        value += static_cast<char>(std::stoi(encoded.substr(i + 1, 2), nullptr, 16));
+                        i += 2;
+                    }
+                    else
+                        value += encoded[i];
+                }
+                return value;
+            }
+            start = end + 1;
+        }
+        return std::nullopt;
+    }
+
+    // Changes a /changes client may fall behind by before it is dropped.
+    constexpr size_t max_pending_changes = 10000;
+
+    // The changes for one /changes connection, collected by a watch from
+    // when the upgrade is answered until the feed is dropped.
+    class Feed
+    {
+    public:
+        Feed(const console::Interactive& interactive, const std::optional<console::Interactive::Triple>& pattern)
+            : _interactive(interactive)
+        {
+            _watch = interactive.watch([this](const console::Interactive::Change& change)
+                                       {
+                std::lock_guard<std::mutex> lock(_mtx);
+                if (_overflowed) return;
+                if (_pending.size() >= max_pending_changes)
+                {
+                    _overflowed = true;
+                    _pending.clear();
+                }
+                else
+                {
+                    _pending.push_back("{\"change\": " + io::json_quote(change.kind) + ", \"fact\": " + fact_json(change.fact) + "}");
+                }
+                _changed.notify_one(); },
+                                       pattern);
+        }
+
+        ~Feed() { _interactive.unwatch(_watch); }
+
+        // Sends the changes as they come until closing is set, the client
+        // closes the connection or falls too far behind, or write throws.
+        // Pings of the client are answered; when idle, a ping now and then
+        // finds closed connections.
+        void send(const Response::Writer& write, const Response::Reader& read, const std::atomic<bool>& closing)
+        {
+            auto                         last_sent = std::chrono::steady_clock::now();
+            std::string                  input;
+            std::unique_lock<std::mutex> lock(_mtx);
+            while (!closing)
+            {
+                lock.unlock();
+                if (read)
+                {
+                    if (!read(input, 0)) return;
+                    ClientFrame frame;
+                    while (take_websocket_frame(input, frame))
+                    {
+                        if (frame.opcode == 0x8)
+                        {
+                            write(websocket_frame(0x8, frame.payload.substr(0, 2)));
+                            return;
+                        }
+                        if (frame.opcode == 0x9) write(websocket_frame(0xA, frame.payload));
+                    }
+                }
+                lock.lock();
+
+                _changed.wait_for(lock, std::chrono::milliseconds(200), [&]
+                                  { return _overflowed || !_pending.empty(); });
+                if (_overflowed)
+                {
+                    lock.unlock();
+                    write(websocket_frame(0x8, std::string("\x03\xf0", 2) + "falling behind"));
+                    return;
+                }
+
+                std::deque<std::string> messages;
+                messages.swap(_pending);
+                lock.unlock();
+
+                const auto now = std::chrono::steady_clock::now();
+                for (const std::string& message : messages)
+                    write(websocket_frame(0x1, message));
+                if (!messages.empty())
+                {
+                    last_sent = now;
+                }
+                else if (now - last_sent >= std::chrono::seconds(15))
+                {
+                    write(websocket_frame(0x9, ""));
+                    last_sent = now;
+                }
+
+                lock.lock();
+            }
+            lock.unlock();
+            write(websocket_frame(0x8, ""));
+        }
+
+        Feed(const Feed&)            = delete;
+        Feed& operator=(const Feed&) = delete;
+
+    private:
+        const console::Interactive& _interactive;
+        uint64_t                    _watch{0};
+        std::mutex                  _mtx;
+        std::condition_variable     _changed;
+        std::deque<std::string>     _pending;           // guarded by _mtx
+        bool                        _overflowed{false}; // guarded by _mtx
+    };
+
+    const char* reason_phrase(const int status)
+    {
+        switch (status)
+        {
+        case 101:
+            return "Switching Protocols";
+        case 200:
+            return "OK";
+        case 400:
+            return "Bad Request";
+        case 401:
+            return "Unauthorized";
+        case 404:
+            return "Not Found";
+        case 405:
+            return "Method Not Allowed";
+        case 413:
+            return "Payload Too Large";
+        case 503:
+            return "Service Unavailable";
+        default:
+            return "Internal Server Error";
+        }
+    }
+
+    bool send_all(const socket_t connection, const std::string& data)
+    {
+        size_t sent = 0;
+        while (sent < data.size())
+        {
+            const auto n = send(connection, data.data() + sent, static_cast<int>(data.size() - sent), send_flags);
+            if (n <= 0) return false;
+            sent += static_cast<size_t>(n);
+        }
+        return true;
+    }
+
+    // Writes the response; the caller closes the connection afterwards
+    // (Connection: close, or the end of a /changes feed), so a streamed body
+    // needs no length.
+    void send_response(const socket_t connection, const Response& response)
+    {
+        std::string head = "HTTP/1.1 " + std::to_string(response.status) + " " + reason_phrase(response.status) + "\r\n";
+        if (!response.content_type.empty()) head += "Content-Type: " + response.content_type + "\r\n";
+        if (!response.headers.contains("Connection")) head += "Connection: close\r\n";
+        for (const auto& [name, value] : response.headers)
+            head += name + ": " + value + "\r\n";
+        if (response.stream)
+        {
+            if (send_all(connection, head + "\r\n"))
+            {
+                try
+                {
+                    response.stream([connection](const std::string& piece)
+                                    {
+                        if (!send_all(connection, piece)) throw std::runtime_error("connection closed"); },
+                                    [connection](std::string& data, const int timeout_ms)
+                                    {
+                        pollfd descriptor{};
+                        descriptor.fd     = connection;
+                        descriptor.events = POLLIN;
+                        if (poll_socket(&descriptor, 1, timeout_ms) <= 0) return true;
+                        char       buffer[4096];
+                        const auto n = recv(connection, buffer, sizeof(buffer), 0);
+                        if (n <= 0) return false;
+                        data.append(buffer, static_cast<size_t>(n));
+                        return true; });
+                }
+                catch (const std::exception&)
+                {
+                    // the client went away or the network failed mid-stream
+                }
+            }
+        }
+        else
+        {
+            send_all(connection, head + "Content-Length: " + std::to_string(response.body.size()) + "\r\n\r\n" + response.body);
+        }
+    }
+
+    // Reads the request line, headers and body; false if the peer sent
+    // something else than an HTTP request or closed the connection early.
+    bool read_request(const socket_t connection, Request& request, int& status)
+    {
+        std::string data;
+        size_t      header_end = std::string::npos;
+        char        buffer[8192];
+        while (header_end == std::string::npos)
+        {
+            const auto n = recv(connection, buffer, sizeof(buffer), 0);
+            if (n <= 0) return false;
+            data.append(buffer, static_cast<size_t>(n));
+            header_end = data.find("\r\n\r\n");
+            if (header_end == std::string::npos && data.size() > max_header_size)
+            {
+                status = 413;
+                return true;
+            }
+        }
+
+        const size_t line_end = data.find("\r\n");
+        const size_t method   = data.find(' ');
+        const size_t path     = data.find(' ', method + 1);
+        if (method == std::string::npos || path == std::string::npos || path > line_end) return false;
+        request.method = data.substr(0, method);
+        request.path   = data.substr(method + 1, path - method - 1);
+
+        for (size_t pos = line_end + 2; pos < header_end;)
+        {
+            const size_t end   = data.find("\r\n", pos);
+            const size_t colon = data.find(':', pos);
+            if (colon != std::string::npos && colon < end)
+            {
+                std::string name = data.substr(pos, colon - pos);
+                std::transform(name.begin(), name.end(), name.begin(), [](unsigned char c)
+                               { return static_cast<char>(std::tolower(c)); });
+                std::string value = data.substr(colon + 1, end - colon - 1);
+                value.erase(0, value.find_first_not_of(" \t"));
+                value.erase(value.find_last_not_of(" \t") + 1);
+                request.headers[name] = value;
+            }
+            pos = end + 2;
+        }
+
+        size_t length = 0;
+        if (auto it = request.headers.find("content-length"); it != request.headers.end())
+        {
+            try
+            {
+                length = std::stoul(it->second);
+            }
+            catch (...)
+            {
+                status = 400;
+                return true;
+            }
+        }
+        if (length > max_body_size)
+        {
+            status = 413;
+            return true;
+        }
+
+        request.body = data.substr(header_end + 4);
+        while (request.body.size() < length)
+        {
+            const auto n = recv(connection, buffer, sizeof(buffer), 0);
+            if (n <= 0) return false;
+            request.body.append(buffer, static_cast<size_t>(n));
+        }
+        request.body.resize(length);
+        return true;
+    }
+}
+
+HttpServer::HttpServer(const console::Interactive& interactive)
+    : _interactive(interactive)
+{
+}
+
+HttpServer::~HttpServer()
+{
+    stop();
+}
+
+Response HttpServer::handle(const Request& request) const
+{
+    if (_authenticator && !_authenticator(request))
+        return error(401, "unauthorized", "the request was not authenticated");
+
+    const std::string path    = request.path.substr(0, request.path.find('?'));
+    const bool        facts   = path == "/facts";
+    const bool        query   = path == "/query";
+    const bool        run     = path == "/run";
+    const bool        changes = path == "/changes";
+    if (!facts && !query && !run && !changes)
+        return error(404, "not-found", "unknown path " + path);
+    if (changes ? request.method != "GET" : request.method != "POST" && !(facts && request.method == "GET"))
+        return error(405, "method-not-allowed", request.method + " is not supported for " + path);
+
+    try
+    {
+        Response response;
+        if (changes)
+        {
+            response = this->changes(request);
+        }
+        else if (facts && request.method == "GET")
+        {
+            response.content_type = "application/x-ndjson";
+            response.stream       = [this](const Response::Writer& write, const Response::Reader&)
+            {
+                _interactive.facts_each([&write](const std::vector<console::Interactive::Fact>& page)
+                                        {
+                    std::string lines;
+                    for (const auto& fact : page)
+                        lines += fact_json(fact) + "\n";
+                    write(lines); });
+            };
+        }
+        else if (facts)
+        {
+            const io::JsonValue  body  = parse_body(request);
+            const io::JsonValue* items = body.find("facts");
+            if (!items || items->type != io::JsonValue::Type::Array)
+                throw std::invalid_argument("expected an array \"facts\"");
+
+            std::vector<console::Interactive::FactIds> triples;
+            for (const io::JsonValue& item : items->items)
+            {
+                if (item.type != io::JsonValue::Type::Object) throw std::invalid_argument("expected a fact object");
+                triples.push_back({_interactive.intern(string_member(item, "subject")),
+                                   _interactive.intern(string_member(item, "predicate")),
+                                   _interactive.intern(string_member(item, "object"))});
+            }
+
+            std::string ids;
+            for (const uint64_t id : _interactive.add_facts(triples))
+                ids += (ids.empty() ? "" : ", ") + std::to_string(id);
+            response.body = "{\"ids\": [" + ids + "]}";
+        }
+        else if (query)
+        {
+            std::string answers;
+            for (const auto& answer : _interactive.query(string_member(parse_body(request), "query")))
+            {
+                std::string bindings;
+                for (const auto& [variable, value] : answer)
+                    bindings += (bindings.empty() ? "" : ", ") + io::json_quote(variable) + ": " + io::json_quote(value);
+                answers += (answers.empty() ? "{" : ", {") + bindings + "}";
+            }
+            response.body = "{\"answers\": [" + answers + "]}";
+        }
+        else
+        {
+            _interactive.run(false, false, false);
+            response.body = std::string("{\"fixpoint_reached\": ") + (_interactive.fixpoint_reached() ? "true" : "false") + "}";
+        }
+        return response;
+    }
+    catch (const console::process_error& ex)
+    {
+        return error(ex);
+    }
+    catch (const std::invalid_argument& ex)
+    {
+        return error(400, "request", ex.what());
+    }
+    catch (const std::exception& ex)
+    {
+        return error(500, "reasoning", ex.what());
+    }
+}
+
+Response HttpServer::changes(const Request& request) const
+{
+    auto header = [&](const std::string& name)
+    {
+        const auto it = request.headers.find(name);
+        return it == request.headers.end() ? std::string() : it->second;
+    };
+
+    std::string upgrade = header("upgrade");
+    std::transform(upgrade.begin(), upgrade.end(), upgrade.begin(), [](unsigned char c)
+                   { return static_cast<char>(std::tolower(c)); });
+    if (upgrade != "websocket" || header("sec-websocket-key").empty())
+        throw std::invalid_argument("expected a WebSocket upgrade");
+
+    auto term = [&](const std::string& key)
+    {
+        const auto name = query_parameter(request.path, key);
+        return name && !name->empty() ? console::Interactive::Term::constant(*name) : console::Interactive::Term::var();
+    };
+    std::optional<console::Interactive::Triple> pattern;
+    if (query_parameter(request.path, "subject") || query_parameter(request.path, "predicate") || query_parameter(request.path, "object"))
+        pattern = console::Interactive::Triple{term("subject"), term("predicate"), term("object")};
+
+    Response response;
+    response.status       = 101;
+    response.content_type = "";
+    response.headers      = {{"Upgrade", "websocket"},
+                             {"Connection", "Upgrade"},
+                             {"Sec-WebSocket-Accept", websocket_accept(header("sec-websocket-key"))}};
+
+    auto feed       = std::make_shared<Feed>(_interactive, pattern);
+    response.stream = [this, feed](const Response::Writer& write, const Response::Reader& read)
+    { feed->send(write, read, _closing); };
+    return response;
+}
+
+uint16_t HttpServer::start(const uint16_t port, const std::string& address_text)
+{
+    if (_running) throw std::runtime_error("The server is already listening");
+    init_sockets();
+    _closing = false;
+
+    sockaddr_in address{};
+    address.sin_family = AF_INET;
+    address.sin_port   = htons(port);
+    if (inet_pton(AF_INET, address_text.c_str(), &address.sin_addr) != 1)
+        throw std::runtime_error("Not an IPv4 address: " + address_text);
+
+    const socket_t listener = socket(AF_INET, SOCK_STREAM, 0);
+    if (listener == invalid_socket) throw std::runtime_error("Could not create a socket");
+
+    const int reuse = 1;
+    setsockopt(listener, SOL_SOCKET, SO_REUSEADDR, reinterpret_cast<const char*>(&reuse), sizeof(reuse));
+
+    if (bind(listener, reinterpret_cast<sockaddr*>(&address), sizeof(address)) != 0 || listen(listener, SOMAXCONN) != 0)
+    {
+        close_socket(listener);
+        throw std::runtime_error("Could not listen on " + address_text + ":" + std::to_string(port));
+    }
+
+    socklen_t length = sizeof(address);
+    getsockname(listener, reinterpret_cast<sockaddr*>(&address), &length);
+
+    _listener = static_cast<intptr_t>(listener);
+    _running  = true;
+    _acceptor = std::thread([this]
+                            { accept_loop(); });
+    return ntohs(address.sin_port);
+}
+
+void HttpServer::stop()
+{
+    if (!_running.exchange(false)) return;
+    _closing = true;
+
+    _acceptor.join();
+    close_socket(static_cast<socket_t>(_listener));
+    _listener = -1;
+
+    // Wakes the workers still waiting for a request or a slow client; one
+    // inside a call of the Interactive finishes it first.
+    std::vector<Worker> workers;
+    {
+        std::lock_guard<std::mutex> lock(_mtx_connections);
+        for (const intptr_t connection : _open_sockets)
+            shutdown_socket(static_cast<socket_t>(connection));
+        workers.swap(_workers);
+    }
+    for (Worker& worker : workers)
+        worker.thread.join();
+}
+
+// Polls so that stop() is noticed within a fraction of a second; each
+// connection is served on a worker thread of its own, up to the connection
+// limit, beyond which connections are answered with 503. Interactive
+// serialises access to the network, and its script engine loads its own
+// Janet VM on whichever thread calls it, so any thread can handle requests.
+void HttpServer::accept_loop()
+{
+    const auto listener = static_cast<socket_t>(_listener);
+    while (_running)
+    {
+        pollfd descriptor{};
+        descriptor.fd     = listener;
+        descriptor.events = POLLIN;
+        if (poll_socket(&descriptor, 1, 200) <= 0) continue;
+
+        const socket_t connection = accept(listener, nullptr, nullptr);
+        if (connection == invalid_socket) continue;
+
+        std::lock_guard<std::mutex> lock(_mtx_connections);
+        std::erase_if(_workers, [](Worker& worker)
+                      {
+            if (!*worker.done) return false;
+            worker.thread.join();
+            return true; });
+        if (_workers.size() >= _max_connections)
+        {
+            send_response(connection, error(503, "busy", "too many open connections"));
+            close_socket(connection);
+            continue;
+        }
+
+        // The socket leaves _open_sockets before it is closed, so that stop()
+        // never shuts down a descriptor that was reused meanwhile.
+        const auto done = std::make_shared<std::atomic<bool>>(false);
+        _open_sockets.insert(static_cast<intptr_t>(connection));
+        _workers.push_back({std::thread([this, connection, done]
+                                        {
+            serve_connection(static_cast<intptr_t>(connection));
+            {
+                std::lock_guard<std::mutex> lock(_mtx_connections);
+                _open_sockets.erase(static_cast<intptr_t>(connection));
+            }
+            close_socket(connection);
+            *done = true; }),
+                            done});
+    }
+}
+
+// One request per connection; the worker closes it after the response.
+void HttpServer::serve_connection(const intptr_t descriptor) const
+{
+    const auto connection = static_cast<socket_t>(descriptor);
+
+#ifdef _WIN32
+    const DWORD timeout = 30000;
+#else
+    const timeval timeout{30, 0};
+#endif
+    setsockopt(connection, SOL_SOCKET, SO_RCVTIMEO, reinterpret_cast<const char*>(&timeout), sizeof(timeout));
+
+    Request  request;
+    int      status = 200;
+    Response response;
+    if (!read_request(connection, request, status)) return;
+    if (status == 413)
+        response = error(413, "request", "the request is too large");
+    else if (status != 200)
+        response = error(status, "request", "malformed request");
+    else
+        response = handle(request);
+
+    send_response(connection, response);
+}
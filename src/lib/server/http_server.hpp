@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <zelph_export.h>
+
+#include <atomic>
+#include <cstdint>
+#include <functional>
+#include <map>
+#include <memory>
+#include <mutex>
+#include <set>
+#include <string>
+#include <thread>
+#include <vector>
+
+namespace zelph::console
+{
+    class Interactive;
+}
+
+namespace zelph::server
+{
+    // An HTTP request as the handler sees it. path is the request target
+    // with its query string, if any. Header names are lowercase.
+    struct Request
+    {
+        std::string                        method;
+        std::string                        path;
+        std::map<std::string, std::string> headers;
+        std::string                        body;
+    };
+
+    // A response with a JSON (or NDJSON) body. If stream is set, it is
+    // called with a function that writes one piece of the body at a time,
+    // and body is ignored; the stream ends when it returns or the function
+    // throws. The reader, which may be empty, appends what the client sent
+    // within timeout_ms to data and returns false once the client has gone.
+    // headers are sent in addition to Content-Type.
+    struct Response
+    {
+        using Writer = std::function<void(const std::string&)>;
+        using Reader = std::function<bool(std::string& data, int timeout_ms)>;
+
+        int                                                status{200};
+        std::string                                        content_type{"application/json"};
+        std::map<std::string, std::string>                 headers;
+        std::string                                        body;
+        std::function<void(const Writer&, const Reader&)> stream;
+    };
+
+    // Exposes an Interactive over HTTP:
+    //
+    //   POST /facts  {"facts": [{"subject": s, "predicate": p, "object": o}, ...]}
+    //                -> {"ids": [...]}, names created if new
+    //   POST /query  {"query": "X is_parent_of peter"}
+    //                -> {"answers": [{"X": "paul"}, ...]}
+    //   POST /run    -> {"fixpoint_reached": true}
+    //   GET  /facts  -> one JSON object per line (NDJSON), stated and deduced
+    //   GET  /changes?subject=s&predicate=p&object=o
+    //                -> a WebSocket sending a text message per change of the
+    //                   network (see Interactive::watch), e.g.
+    //                   {"change": "deduced", "fact": {...}}; parameters left
+    //                   out match any name. Pings are answered and a close
+    //                   frame ends the feed; a client that falls more than
+    //                   10,000 changes behind is disconnected (close code
+    //                   1008)
+    //
+    // Failures are answered with {"error": {"kind": ..., "reason": ...}},
+    // with a "column" for a syntax error whose column is known (see
+    // console::process_error::column): 400 for malformed requests and errors in statements, 401 if the
+    // authenticator rejects the request, 404 and 405 for unknown paths and
+    // methods, 500 for reasoning errors. The authenticator, if set, sees
+    // every request before it is handled, e.g. to check an Authorization
+    // header. Requests are handled concurrently as far as the Interactive
+    // allows (see there), each connection on a worker thread of its own, up
+    // to set_max_connections open connections (64 by default); more are
+    // answered with 503. The Interactive must outlive the server.
+    class ZELPH_EXPORT HttpServer
+    {
+    public:
+        using Authenticator = std::function<bool(const Request&)>;
+
+        explicit HttpServer(const console::Interactive& interactive);
+        ~HttpServer();
+
+        void set_authenticator(Authenticator authenticator) { _authenticator = std::move(authenticator); }
+        void set_max_connections(size_t count) { _max_connections = count; }
+
+        // Answers a single request; used by the listener and by tests.
+        Response handle(const Request& request) const;
+
+        // Listens on the port (0 picks a free one) of the IPv4 address, by
+        // default the loopback interface only, and returns the port; pass
+        // "0.0.0.0" for all interfaces, together with an authenticator.
+        // stop() or the destructor ends listening, closes the open
+        // connections and joins their workers. Throws std::runtime_error if the address is invalid,
+        // the port cannot be bound or the server is already listening.
+        uint16_t start(uint16_t port, const std::string& address = "127.0.0.1");
+        void     stop();
+
+        HttpServer(const HttpServer&)            = delete;
+        HttpServer& operator=(const HttpServer&) = delete;
+
+    private:
+        Response changes(const Request& request) const;
+        void     accept_loop();
+        void     serve_connection(intptr_t connection) const;
+
+        const console::Interactive& _interactive;
+        Authenticator               _authenticator;
+
+        struct Worker
+        {
+            std::thread                        thread;
+            std::shared_ptr<std::atomic<bool>> done;
+        };
+
+        intptr_t            _listener{-1};
+        std::atomic<bool>   _running{false};
+        std::atomic<bool>   _closing{false}; // ends the /changes feeds
+        std::thread         _acceptor;
+        size_t              _max_connections{64};
+        std::mutex          _mtx_connections;
+        std::vector<Worker> _workers;      // guarded by _mtx_connections
+        std::set<intptr_t>  _open_sockets; // guarded by _mtx_connections
+    };
+}
@@ -25,7 +25,11 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #include "string_utils.hpp"
 
+#include <utf8proc.h>
+
 #include <algorithm>
+#include <cctype>
+#include <cstdlib>
 #include <sstream>
 
 namespace zelph::string
@@ -62,6 +66,37 @@ namespace zelph::string
 
             return result;
         }
+
+        std::string normalize(const std::string& text, const Normalization form, const bool case_fold)
+        {
+            if (form == Normalization::None && !case_fold) return text;
+
+            if (std::all_of(text.begin(), text.end(), [](unsigned char c)
+                            { return c < 0x80; }))
+            {
+                if (!case_fold) return text; // ASCII is invariant under NFC and NFKC
+                std::string result(text);
+                std::transform(result.begin(), result.end(), result.begin(), [](unsigned char c)
+                               { return static_cast<char>(std::tolower(c)); });
+                return result;
+            }
+
+            int options = UTF8PROC_STABLE;
+            if (form != Normalization::None) options |= UTF8PROC_COMPOSE;
+            if (form == Normalization::NFKC) options |= UTF8PROC_COMPAT;
+            if (case_fold) options |= UTF8PROC_CASEFOLD;
+
+            utf8proc_uint8_t*      mapped = nullptr;
+            const utf8proc_ssize_t length = utf8proc_map(reinterpret_cast<const utf8proc_uint8_t*>(text.data()),
+                                                         static_cast<utf8proc_ssize_t>(text.size()),
+                                                         &mapped,
+                                                         static_cast<utf8proc_option_t>(options));
+            if (length < 0) return text;
+
+            std::string result(reinterpret_cast<const char*>(mapped), static_cast<size_t>(length));
+            std::free(mapped);
+            return result;
+        }
     }
 
     // Converts a uint64_t value to its hexadecimal string representation (without '0x' prefix).
@@ -27,6 +27,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #include <zelph_export.h>
 
+#include <algorithm>
 #include <cstdint>
 #include <stdexcept>
 #include <string>
@@ -37,6 +38,20 @@ namespace zelph::string
     namespace unicode
     {
         std::string ZELPH_EXPORT unescape(const std::string& input);
+
+        // The normalization forms applied to names (see
+        // network::Zelph::set_name_normalization).
+        enum class Normalization
+        {
+            None,
+            NFC,
+            NFKC
+        };
+
+        // text in the given normalization form, case-folded if requested.
+        // ASCII text needs no Unicode tables and is handled directly; text
+        // that is not valid UTF-8 is returned unchanged.
+        std::string ZELPH_EXPORT normalize(const std::string& text, Normalization form, bool case_fold);
     }
 
     namespace utf8
@@ -0,0 +1,282 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include "golden.hpp"
+
+#include "interactive.hpp"
+#include "io/output.hpp"
+#include "process_error.hpp"
+
+#include <algorithm>
+#include <filesystem>
+#include <fstream>
+#include <sstream>
+#include <stdexcept>
+
+using namespace zelph::testing;
+
+namespace
+{
+    std::vector<std::string> split_lines(const std::string& text)
+    {
+        std::vector<std::string> lines;
+        std::istringstream       in(text);
+        for (std::string line; std::getline(in, line);)
+            lines.push_back(line);
+        return lines;
+    }
+
+    std::string read_file(const std::filesystem::path& path)
+    {
+        std::ifstream in(path, std::ios::binary);
+        if (!in) throw std::runtime_error("Cannot open " + path.string());
+        std::ostringstream content;
+        content << in.rdbuf();
+        return content.str();
+    }
+
+    // One line of an edit script: ' ' kept, '-' only in the expected text,
+    // '+' only in the actual one, with the line's index in its text.
+    struct Edit
+    {
+        char   kind;
+        size_t expected;
+        size_t actual;
+    };
+
+    // The shortest edit script by the longest common subsequence. Beyond a
+    // few million line pairs, the texts are compared as one replaced block.
+    std::vector<Edit> edit_script(const std::vector<std::string>& a, const std::vector<std::string>& b)
+    {
+        std::vector<Edit> edits;
+        const size_t      n = a.size(), m = b.size();
+
+        if (n * m > 4'000'000)
+        {
+            for (size_t i = 0; i < n; ++i)
+                edits.push_back({'-', i, 0});
+            for (size_t j = 0; j < m; ++j)
+                edits.push_back({'+', n, j});
+            return edits;
+        }
+
+        // lcs[i][j]: length of the common subsequence of a[i..] and b[j..].
+        std::vector<std::vector<uint32_t>> lcs(n + 1, std::vector<uint32_t>(m + 1, 0));
+        for (size_t i = n; i-- > 0;)
+            for (size_t j = m; j-- > 0;)
+                lcs[i][j] = a[i] == b[j] ? lcs[i + 1][j + 1] + 1 : std::max(lcs[i + 1][j], lcs[i][j + 1]);
+
+        size_t i = 0, j = 0;
+        while (i < n || j < m)
+        {
+            if (i < n && j < m && a[i] == b[j])
+                edits.push_back({' ', i++, j++});
+            else if (i < n && (j == m || lcs[i + 1][j] >= lcs[i][j + 1]))
+                edits.push_back({'-', i++, j});
+            else
+                edits.push_back({'+', i, j++});
+        }
+        return edits;
+    }
+
+    std::string hunk_range(const size_t start, const size_t count)
+    {
+        // For an empty range, diff names the line before it.
+        return std::to_string(count == 0 ? start : start + 1) + "," + std::to_string(count);
+    }
+}
+
+std::string zelph::testing::capture_script(const std::string& script_file)
+{
+    std::ifstream in(script_file);
+    if (!in) throw std::runtime_error("Cannot open " + script_file);
+
+    std::string          output;
+    console::Interactive interactive([&output](const io::OutputEvent& event)
+                                     {
+        if (event.channel != io::OutputChannel::Out) return;
+        output += event.text;
+        if (event.newline) output += '\n'; });
+
+    try
+    {
+        interactive.process_script(in);
+    }
+    catch (const console::script_error& ex)
+    {
+        if (!output.empty() && output.back() != '\n') output += '\n';
+        for (const auto& error : ex.errors())
+            output += "error: " + (error.number == 0 ? std::string("after the last line") : "line " + std::to_string(error.number)) + ": " + error.reason + "\n";
+    }
+
+    if (!output.empty() && output.back() != '\n') output += '\n';
+    output += "--- facts ---\n";
+    for (const auto& fact : interactive.facts())
+    {
+        output += fact.subject + " " + fact.predicate;
+        for (const auto& object : fact.objects)
+            output += " " + object;
+        output += fact.deduced ? " (deduced)\n" : "\n";
+    }
+    return output;
+}
+
+std::string zelph::testing::expected_file(const std::string& script_file)
+{
+    return std::filesystem::path(script_file).replace_extension(".expected").string();
+}
+
+std::string zelph::testing::unified_diff(const std::string& expected,
+                                         const std::string& actual,
+                                         const std::string& expected_label,
+                                         const std::string& actual_label,
+                                         const size_t       context)
+{
+    const auto a     = split_lines(expected);
+    const auto b     = split_lines(actual);
+    const auto edits = edit_script(a, b);
+
+    std::string diff;
+    size_t      k = 0;
+    while (k < edits.size())
+    {
+        // The next change, and the end of the hunk: the last change that
+        // follows the previous one within twice the context.
+        while (k < edits.size() && edits[k].kind == ' ')
+            ++k;
+        if (k == edits.size()) break;
+
+        const size_t first = k >= context ? k - context : 0;
+        size_t       last  = k;
+        for (size_t e = k; e < edits.size(); ++e)
+        {
+            if (edits[e].kind == ' ') continue;
+            if (e > last + 2 * context) break;
+            last = e;
+        }
+        const size_t end = std::min(edits.size(), last + context + 1);
+
+        size_t expected_count = 0, actual_count = 0;
+        for (size_t e = first; e < end; ++e)
+        {
+            if (edits[e].kind != '+') ++expected_count;
+            if (edits[e].kind != '-') ++actual_count;
+        }
+
+        if (diff.empty()) diff = "--- " + expected_label + "\n+++ " + actual_label + "\n";
+        diff += "@@ -" + hunk_range(edits[first].expected, expected_count) + " +" + hunk_range(edits[first].actual, actual_count) + " @@\n";
+        for (size_t e = first; e < end; ++e)
+            diff += edits[e].kind + (edits[e].kind == '+' ? b[edits[e].actual] : a[edits[e].expected]) + "\n";
+
+        k = end;
+    }
+    return diff;
+}
+
+GoldenResult zelph::testing::check_script(const std::string& script_file, const bool update)
+{
+    GoldenResult result{script_file};
+
+    std::string actual;
+    try
+    {
+        actual = capture_script(script_file);
+    }
+    catch (const std::exception& ex)
+    {
+        result.message = ex.what();
+        return result;
+    }
+
+    const std::filesystem::path expected_path = expected_file(script_file);
+    std::string                 expected;
+    const bool                  exists = std::filesystem::exists(expected_path);
+    if (exists) expected = read_file(expected_path);
+
+    if (exists && expected == actual)
+    {
+        result.passed = true;
+        return result;
+    }
+
+    if (update)
+    {
+        std::ofstream out(expected_path, std::ios::binary);
+        out << actual;
+        if (!out) throw std::runtime_error("Cannot write " + expected_path.string());
+        result.passed  = true;
+        result.written = true;
+        return result;
+    }
+
+    result.message = exists ? unified_diff(expected, actual, expected_path.string(), "actual output of " + script_file)
+                            : "No expected output " + expected_path.string() + "; run zelph test --update to create it";
+    return result;
+}
+
+size_t zelph::testing::run_golden_tests(const std::vector<std::string>& paths, const bool update, std::ostream& out)
+{
+    std::vector<std::string> scripts;
+    for (const auto& path : paths)
+    {
+        if (!std::filesystem::is_directory(path))
+        {
+            scripts.push_back(path);
+            continue;
+        }
+
+        std::vector<std::string> found;
+        for (const auto& entry : std::filesystem::recursive_directory_iterator(path))
+            if (entry.is_regular_file() && entry.path().extension() == ".zph") found.push_back(entry.path().string());
+        std::sort(found.begin(), found.end());
+        scripts.insert(scripts.end(), found.begin(), found.end());
+    }
+
+    size_t failed = 0, written = 0;
+    for (const auto& script : scripts)
+    {
+        const GoldenResult result = check_script(script, update);
+        if (!result.passed)
+        {
+            ++failed;
+            out << "FAIL " << script << "\n"
+                << result.message << (result.message.empty() || result.message.back() == '\n' ? "" : "\n");
+        }
+        else if (result.written)
+        {
+            ++written;
+            out << "UPDATED " << script << "\n";
+        }
+        else
+        {
+            out << "PASS " << script << "\n";
+        }
+    }
+
+    out << scripts.size() << " test(s), " << failed << " failed";
+    if (written > 0) out << ", " << written << " expected file(s) written";
+    out << std::endl;
+    return failed;
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#pragma once
+
+#include <zelph_export.h>
+
+#include <cstddef>
+#include <ostream>
+#include <string>
+#include <vector>
+
+namespace zelph::testing
+{
+    // Golden-file tests of .zph scripts (see "zelph test"): a script runs
+    // in a fresh Interactive and what it produced is compared with an
+    // expected output committed next to it, so that changes to a rule set
+    // that alter its results show up as a diff.
+
+    // What a script produces: the lines it printed on the output channel
+    // (answers, deductions, command output; diagnostics and prompts are
+    // left out), then "error: line <n>: <reason>" for each line that
+    // failed, then a line "--- facts ---" and the final facts of the
+    // network as listed by Interactive::facts, without node IDs and with
+    // " (deduced)" for deductions. Imports are resolved against the working
+    // directory. Throws if the script cannot be read.
+    ZELPH_EXPORT std::string capture_script(const std::string& script_file);
+
+    // The expected output of a script: the script's path with the
+    // extension replaced by ".expected".
+    ZELPH_EXPORT std::string expected_file(const std::string& script_file);
+
+    // Compares expected and actual text line by line and returns the
+    // differences as a unified diff with the given number of context
+    // lines, or an empty string if the texts are equal.
+    ZELPH_EXPORT std::string unified_diff(const std::string& expected,
+                                          const std::string& actual,
+                                          const std::string& expected_label,
+                                          const std::string& actual_label,
+                                          size_t             context = 3);
+
+    // The outcome of one golden test. With update, a missing or different
+    // expected file is (re)written from the actual output and the test
+    // passes; without, a missing expected file fails the test.
+    struct GoldenResult
+    {
+        std::string script;
+        bool        passed{false};
+        bool        written{false}; // the expected file was (re)written
+        std::string message;        // why the test failed, e.g. the diff
+    };
+    ZELPH_EXPORT GoldenResult check_script(const std::string& script_file, bool update = false);
+
+    // Runs the golden tests of the given scripts and directories (all .zph
+    // files in them, recursively, in path order), prints "PASS", "FAIL"
+    // with the diff or "UPDATED" for each and a summary to out, and
+    // returns the number of failed tests.
+    ZELPH_EXPORT size_t run_golden_tests(const std::vector<std::string>& paths, bool update, std::ostream& out);
+}
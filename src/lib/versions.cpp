@@ -29,6 +29,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "script_engine.hpp"
 
 #include <ankerl/unordered_dense.h>
+#include <utf8proc.h>
 
 #ifndef __EMSCRIPTEN__
     #include <bzlib.h>
@@ -63,6 +64,9 @@ namespace zelph
             << ANKERL_UNORDERED_DENSE_VERSION_MINOR << "."
             << ANKERL_UNORDERED_DENSE_VERSION_PATCH << ") - MIT License\n";
 
+        // utf8proc
+        oss << "utf8proc (v" << utf8proc_version() << ") - MIT License\n";
+
 #ifndef __EMSCRIPTEN__
         // Cap'n Proto
         oss << "Cap'n Proto (v"
@@ -34,6 +34,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include <capnp/serialize-packed.h>
 #include <kj/io.h>
 
+#include <algorithm>
 #include <atomic>
 #include <cstdio>
 #include <fstream>
@@ -69,6 +70,9 @@ public:
 #endif
     bool                  _logging{true};
     std::filesystem::path _bin_path;
+    ImportFilter          _filter;
+    // {"language":"<lang>","value":" per label language, prepared once for process_import
+    std::vector<std::pair<std::string, std::string>> _label_tags{{"en", "{\"language\":\"en\",\"value\":\""}};
 };
 
 Wikidata::Wikidata(network::Zelph* n, const std::filesystem::path& input_path)
@@ -100,6 +104,42 @@ void Wikidata::load()
     import_all();
 }
 
+void Wikidata::set_import_filter(ImportFilter filter)
+{
+    _pImpl->_label_tags.clear();
+    for (const auto& lang : filter.label_languages)
+        _pImpl->_label_tags.emplace_back(lang, "{\"language\":\"" + lang + "\",\"value\":\"");
+
+    // Name the cache after the filter. The property list is represented by
+    // its size and a hash, as it may be long.
+    std::string tag;
+    if (filter.label_languages != std::vector<std::string>{"en"})
+    {
+        tag += ".lang";
+        for (const auto& lang : filter.label_languages)
+            tag += "-" + lang;
+        if (filter.label_languages.empty()) tag += "-none";
+    }
+    if (!filter.properties.empty())
+    {
+        std::vector<std::string> sorted(filter.properties.begin(), filter.properties.end());
+        std::sort(sorted.begin(), sorted.end());
+        std::string joined;
+        for (const auto& p : sorted)
+            joined += p + ",";
+
+        std::ostringstream hash;
+        hash << std::hex << std::setw(8) << std::setfill('0') << (std::hash<std::string>{}(joined) & 0xFFFFFFFFu);
+        tag += ".props-" + std::to_string(sorted.size()) + "-" + hash.str();
+    }
+
+    std::filesystem::path bin_path = _pImpl->_bin_path;
+    bin_path.replace_extension("");
+    bin_path += tag + ".bin";
+    _pImpl->_bin_path = bin_path;
+    _pImpl->_filter   = std::move(filter);
+}
+
 void Wikidata::import_all(const std::string& constraints_dir)
 {
     const bool export_constraints = !constraints_dir.empty();
@@ -141,8 +181,6 @@ void Wikidata::import_all(const std::string& constraints_dir)
             throw std::runtime_error("Operation requires original source file, but it could not be located based on the input path.");
         }
 
-        const std::string additional_language_to_import = "en";
-
         if (export_constraints)
         {
             _pImpl->_n->diagnostic("Exporting constraints from file " + _pImpl->_original_source_path.string(), true);
@@ -200,7 +238,7 @@ void Wikidata::import_all(const std::string& constraints_dir)
                            && !bytes_read.compare_exchange_weak(old, streampos, std::memory_order_relaxed))
                         ;
 
-                    process_entry(line, additional_language_to_import, log, constraints_dir, &local_diag);
+                    process_entry(line, log, constraints_dir, &local_diag);
                 }
 
 #if ZELPH_WIKIDATA_IMPORT_DIAGNOSTICS
@@ -658,7 +696,6 @@ void Wikidata::process_constraints(const std::string& line, std::string id_str,
 
 void Wikidata::process_import(const std::string& line,
                               const std::string& id_str,
-                              const std::string& additional_language_to_import,
                               const bool         log,
                               size_t             id1,
                               ImportThreadStats* diag)
@@ -666,29 +703,30 @@ void Wikidata::process_import(const std::string& line,
     thread_local std::unordered_map<std::string, network::Node> property_cache;
     thread_local std::unordered_set<network::Node>              typed_properties;
     network::Node                                               subject = 0;
-    std::string                                                 name_in_additional_language;
+    std::vector<std::pair<std::string, std::string>>            labels; // language, name
+    const size_t                                                id_end = id1;
 
-    if (!additional_language_to_import.empty())
+    for (const auto& [language, language_tag] : _pImpl->_label_tags)
     {
 #if ZELPH_WIKIDATA_IMPORT_DIAGNOSTICS
         const auto label_begin = SteadyClock::now();
 #endif
-        static const std::string language_tag("{\"language\":\"" + additional_language_to_import + "\",\"value\":\"");
-        const size_t             language0 = line.find(language_tag, id1 + 7);
+        const size_t language0 = line.find(language_tag, id_end + 7);
         if (language0 != std::string::npos)
         {
             if (language0 > line.find("\"labels\":{"))
             {
-                const size_t aliases = line.find("\"aliases\":{", id1 + 7);
+                const size_t aliases = line.find("\"aliases\":{", id_end + 7);
 
                 if (aliases == std::string::npos || language0 < aliases)
                 {
-                    const size_t descriptions = line.find("\"descriptions\":{", id1 + 7);
+                    const size_t descriptions = line.find("\"descriptions\":{", id_end + 7);
 
                     if (descriptions == std::string::npos || language0 < descriptions)
                     {
-                        id1                         = line.find('\"', language0 + language_tag.size() + 1);
-                        name_in_additional_language = line.substr(language0 + language_tag.size(), id1 - language0 - language_tag.size());
+                        const size_t name_end = line.find('\"', language0 + language_tag.size() + 1);
+                        labels.emplace_back(language, line.substr(language0 + language_tag.size(), name_end - language0 - language_tag.size()));
+                        id1 = std::max(id1, name_end);
                     }
                 }
             }
@@ -731,6 +769,12 @@ void Wikidata::process_import(const std::string& line,
         size_t next_property0 = line.find(property_tag, property0 + property_tag.size());
         size_t boundary       = (next_property0 != std::string::npos) ? next_property0 : line.size();
 
+        if (!_pImpl->_filter.properties.empty() && _pImpl->_filter.properties.count(property_str) == 0)
+        {
+            id1 = (next_property0 != std::string::npos) ? next_property0 - 1 : line.size() - 1;
+            continue;
+        }
+
         size_t search_pos = property0;
 
 #if ZELPH_WIKIDATA_IMPORT_DIAGNOSTICS
@@ -876,7 +920,7 @@ void Wikidata::process_import(const std::string& line,
 #endif
     }
 
-    if (!name_in_additional_language.empty())
+    for (const auto& [language, name] : labels)
     {
 #if ZELPH_WIKIDATA_IMPORT_DIAGNOSTICS
         const auto t = SteadyClock::now();
@@ -884,7 +928,7 @@ void Wikidata::process_import(const std::string& line,
 #ifdef SINGLE_THREADED_IMPORT
         assert(lock.owns_lock());
 #endif
-        _pImpl->_n->set_name(subject, name_in_additional_language, additional_language_to_import, false);
+        _pImpl->_n->set_name(subject, name, language, false);
 #if ZELPH_WIKIDATA_IMPORT_DIAGNOSTICS
         if (diag)
         {
@@ -896,7 +940,6 @@ void Wikidata::process_import(const std::string& line,
 }
 
 void Wikidata::process_entry(const std::string& line,
-                             const std::string& additional_language_to_import,
                              const bool         log,
                              const std::string& constraints_dir,
                              ImportThreadStats* diag)
@@ -929,7 +972,7 @@ void Wikidata::process_entry(const std::string& line,
         }
         else
         {
-            process_import(line, id_str, additional_language_to_import, log, id1, diag);
+            process_import(line, id_str, log, id1, diag);
         }
     }
 
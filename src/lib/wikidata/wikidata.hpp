@@ -29,7 +29,9 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 #include "network/zelph.hpp"
 
 #include <filesystem>
+#include <string>
 #include <unordered_set>
+#include <vector>
 
 namespace zelph::wikidata
 {
@@ -39,6 +41,14 @@ namespace zelph::wikidata
     class Wikidata : public io::DataManager
     {
     public:
+        // Restricts what import_all() takes from the dump. By default, English
+        // labels and all item-valued properties are imported.
+        struct ImportFilter
+        {
+            std::vector<std::string>        label_languages{"en"}; // names besides the "wikidata" IDs
+            std::unordered_set<std::string> properties;            // P-IDs of the claims to import; empty: all
+        };
+
         // input_path can be a raw source file (.json, .bz2) or a cache file (.bin)
         Wikidata(network::Zelph* n, const std::filesystem::path& input_path);
         ~Wikidata() override;
@@ -47,6 +57,11 @@ namespace zelph::wikidata
         void         import_all(const std::string& constraints_dir = "");
         void         set_logging(bool do_log) override;
         io::DataType get_type() const override { return io::DataType::Wikidata; }
+
+        // Must be called before load(). A filtered import is cached in a
+        // .bin file of its own (e.g. latest-all.lang-de-fr.props-2-1a2b3c4d.bin),
+        // so it never shadows the complete import or one filtered differently.
+        void set_import_filter(ImportFilter filter);
         /**
          * @brief Extracts the exact JSON lines for the given Wikidata IDs
          *        (Q…) from the dump and writes them as <id>.json
@@ -88,13 +103,11 @@ namespace zelph::wikidata
     private:
         void process_constraints(const std::string& line, std::string id_str, const std::string& dir);
         void process_entry(const std::string& line,
-                           const std::string& additional_language_to_import,
                            bool               log,
                            const std::string& constraints_dir,
                            ImportThreadStats* diag = nullptr);
         void process_import(const std::string& line,
                             const std::string& id_str,
-                            const std::string& additional_language_to_import,
                             bool               log,
                             size_t             id1,
                             ImportThreadStats* diag = nullptr);
@@ -0,0 +1,877 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include <doctest/doctest.h> // provides main()
+
+#include "process_error.hpp"
+#include "test_helpers.hpp"
+
+#include <algorithm>
+#include <atomic>
+#include <cstdint>
+#include <set>
+#include <thread>
+
+using namespace zelph::test;
+
+TEST_CASE("instances: concurrent instances keep separate networks and Janet state")
+{
+    zelph::io::OutputCollector  collector_a;
+    zelph::io::OutputCollector  collector_b;
+    zelph::console::Interactive a(collector_a.sink());
+    zelph::console::Interactive b(collector_b.sink());
+
+    // Each instance is fed and queried from its own thread while the other
+    // one runs, and neither of them from the thread that created it.
+    auto work = [](zelph::console::Interactive& z, const std::string& name, const std::string& other, bool& ok)
+    {
+        ok = true;
+        z.process("%(def tenant \"" + name + "\")");
+        for (int i = 0; i < 50; ++i)
+        {
+            z.process(name + std::to_string(i) + " knows " + name);
+            ok = ok && z.query("X knows " + name).size() == static_cast<size_t>(i + 1);
+            ok = ok && z.query("X knows " + other).empty();
+        }
+    };
+
+    bool        ok_a = false;
+    bool        ok_b = false;
+    std::thread thread_a([&]
+                         { work(a, "alice", "bob", ok_a); });
+    std::thread thread_b([&]
+                         { work(b, "bob", "alice", ok_b); });
+    thread_a.join();
+    thread_b.join();
+    CHECK(ok_a);
+    CHECK(ok_b);
+
+    // Janet state survives the threads and stays per instance.
+    collector_a.clear();
+    collector_b.clear();
+    a.process("%tenant");
+    std::thread([&]
+                { b.process("%tenant"); })
+        .join();
+    CHECK(any_output_contains(collector_a, "alice"));
+    CHECK_FALSE(any_output_contains(collector_a, "bob"));
+    CHECK(any_output_contains(collector_b, "bob"));
+}
+
+TEST_CASE("output: Janet's print and eprint go to the output handler")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.process("%(print \"hello from janet\")");
+        interactive.process("%(eprint \"warning from janet\")");
+
+        CHECK(any_output_contains(collector, "hello from janet"));
+        const auto& events = collector.events();
+        CHECK(std::any_of(events.begin(), events.end(), [](const zelph::io::OutputEvent& e)
+                          { return e.channel == zelph::io::OutputChannel::Error && e.text == "warning from janet" && e.newline; })); });
+}
+
+TEST_CASE("deduction callback: each new fact is reported once with its rule")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::vector<zelph::console::Interactive::Deduction> seen;
+        interactive.on_deduction([&](const zelph::console::Interactive::Deduction& d)
+                                 { seen.push_back(d); });
+
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+anna is_parent_of peter
+)");
+        interactive.run(false, false, false);
+
+        REQUIRE(seen.size() == 2);
+        CHECK(seen[0].rule == seen[1].rule);
+        CHECK(seen[0].rule_text.find("=>") != std::string::npos);
+        for (const auto& d : seen)
+        {
+            CHECK(d.fact_text.find("peter is_child_of") != std::string::npos);
+            CHECK(d.fact != 0);
+        }
+
+        interactive.run(false, false, false);
+        CHECK(seen.size() == 2); // nothing new
+
+        interactive.on_deduction(nullptr);
+        interactive.process("mary is_parent_of paul");
+        interactive.run(false, false, false);
+        CHECK(seen.size() == 2);
+        CHECK(interactive.query("paul is_child_of X").size() == 1); });
+}
+
+TEST_CASE("progress callback: a run ends with a finished report of its totals")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::vector<zelph::network::RunProgress> reports;
+        interactive.on_progress([&](const zelph::network::RunProgress& p)
+                                { reports.push_back(p); },
+                                0.001);
+
+        process_lines(interactive, R"(
+.auto-run
+(X before Y, Y before Z) => (X before Z)
+a before b
+b before c
+c before d
+)");
+        interactive.run(false, false, false);
+
+        REQUIRE_FALSE(reports.empty());
+        const auto& last = reports.back();
+        CHECK(last.finished);
+        CHECK(last.deductions == 3);
+        CHECK(last.iterations >= 1);
+        for (size_t i = 0; i + 1 < reports.size(); ++i)
+            CHECK_FALSE(reports[i].finished);
+
+        interactive.on_progress(nullptr);
+        reports.clear();
+        interactive.run(false, false, false);
+        CHECK(reports.empty()); });
+}
+
+TEST_CASE("event callback: stated facts, deductions, contradictions and errors are reported")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::vector<zelph::console::Interactive::Event> events;
+        interactive.on_event([&](const zelph::console::Interactive::Event& e)
+                             { events.push_back(e); });
+
+        auto of_type = [&](const std::string& type)
+        {
+            std::vector<zelph::console::Interactive::Event> result;
+            for (const auto& e : events)
+                if (e.type == type) result.push_back(e);
+            return result;
+        };
+        auto attribute = [](const zelph::console::Interactive::Event& e, const std::string& key)
+        {
+            for (const auto& [k, v] : e.attributes)
+                if (k == key) return v;
+            return std::string();
+        };
+
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+(A instanceof B, A subclassof B) => !
+paul is_parent_of peter
+gene instanceof geneclass
+gene subclassof geneclass
+)");
+
+        const auto added = of_type("fact_added");
+        REQUIRE(added.size() == 3);
+        CHECK(attribute(added[0], "fact_text").find("paul is_parent_of peter") != std::string::npos);
+        CHECK(std::stoull(attribute(added[0], "fact")) != 0);
+
+        const auto fired = of_type("rule_fired");
+        REQUIRE(fired.size() == 1);
+        CHECK(attribute(fired[0], "fact_text").find("peter is_child_of paul") != std::string::npos);
+        CHECK(attribute(fired[0], "rule_text").find("=>") != std::string::npos);
+
+        const auto contradictions = of_type("contradiction");
+        REQUIRE(contradictions.size() == 1);
+        CHECK(attribute(contradictions[0], "rule_text").find("instanceof") != std::string::npos);
+        CHECK(attribute(contradictions[0], "fact_text").find("gene") != std::string::npos);
+
+        CHECK_THROWS_AS(interactive.process("(a b c)"), zelph::console::process_error);
+        const auto errors = of_type("parse_error");
+        REQUIRE(errors.size() == 1);
+        CHECK(attribute(errors[0], "line") == "(a b c)");
+        CHECK(attribute(errors[0], "kind") == "syntax");
+        CHECK_FALSE(attribute(errors[0], "reason").empty());
+
+        interactive.on_event(nullptr);
+        events.clear();
+        interactive.process("anna is_parent_of peter");
+        CHECK(events.empty()); });
+}
+
+TEST_CASE("alias: names in other languages are set and resolved without script text")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process("dog ~ animal");
+
+        const auto dog = interactive.resolve_name("dog");
+        REQUIRE(dog.has_value());
+        CHECK(interactive.alias("dog", "de", "Hund") == *dog);
+        CHECK(interactive.resolve_name("Hund", "de") == dog);
+        CHECK_FALSE(interactive.resolve_name("Hund").has_value());
+
+        CHECK_FALSE(interactive.resolve_name("cat").has_value());
+        const uint64_t cat = interactive.alias("cat", "de", "Katze");
+        CHECK(interactive.resolve_name("cat") == cat);
+        CHECK(interactive.resolve_name("Katze", "de") == cat);
+
+        CHECK(interactive.resolve_name("~").has_value());
+        CHECK_THROWS_AS(interactive.alias("animal", "de", "Hund"), zelph::console::process_error);
+        CHECK(interactive.resolve_name("Hund", "de") == dog);
+
+        interactive.process(".lang de");
+        CHECK(interactive.query("Hund ~ X").size() == 1); });
+}
+
+TEST_CASE("intern: facts are stated by concept IDs")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process("(X likes Y) => (Y liked_by X)");
+
+        const uint64_t alice = interactive.intern("alice");
+        const uint64_t likes = interactive.intern("likes");
+        CHECK(interactive.intern("alice") == alice);
+        CHECK(interactive.resolve_name("alice") == alice);
+        CHECK(interactive.intern("~") == interactive.resolve_name("~"));
+
+        const uint64_t bob  = interactive.intern("bob");
+        const uint64_t fact = interactive.add_fact(alice, likes, bob);
+        CHECK(interactive.add_fact(alice, likes, bob) == fact);
+        CHECK(interactive.add_facts({{bob, likes, alice}, {alice, likes, interactive.intern("carol")}}).size() == 2);
+        CHECK(interactive.query("alice likes X").size() == 2);
+
+        CHECK_THROWS_AS(interactive.add_fact(alice, likes, 0), zelph::console::process_error);
+        CHECK_THROWS_AS(interactive.intern(""), zelph::console::process_error);
+
+        CHECK(interactive.query("alice liked_by X").empty());
+        interactive.run(false, false, false);
+        CHECK(interactive.query("alice liked_by X").size() == 1); });
+}
+
+TEST_CASE("names: NFC normalization, case folding and custom quotes")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process("caf\u00e9 ~ place");
+        const auto composed = interactive.resolve_name("caf\u00e9");
+        REQUIRE(composed.has_value());
+        CHECK(interactive.resolve_name("cafe\u0301") == composed);
+        CHECK(interactive.query("cafe\u0301 ~ X").size() == 1);
+
+        CHECK_FALSE(interactive.resolve_name("Place").has_value());
+        interactive.set_case_folding(true);
+        interactive.process("Berlin ~ City");
+        CHECK(interactive.resolve_name("berlin") == interactive.resolve_name("BERLIN"));
+        CHECK(interactive.query("BERLIN ~ X").size() == 1);
+
+        interactive.set_quote_pairs({{"\u201e", "\u201c"}});
+        interactive.process("\u201eNew York\u201c ~ city");
+        CHECK(interactive.resolve_name("new york").has_value());
+        CHECK(interactive.query("\"new york\" ~ X").size() == 1);
+
+        CHECK_THROWS_AS(interactive.set_quote_pairs({{"(", ")"}}), zelph::console::process_error); });
+}
+
+TEST_CASE("subgraph: a bounded, filtered slice becomes an independent network")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+paul is_parent_of peter
+peter is_parent_of mia
+mia is_parent_of tom
+peter lives_in berlin
+berlin is_capital_of germany
+)");
+        interactive.alias("peter", "de", "Peter");
+
+        auto ancestry = interactive.subgraph({"peter"}, 2, [](const std::string& relation)
+                                             { return relation == "is_parent_of"; });
+        REQUIRE(ancestry);
+        CHECK(ancestry->query("paul is_parent_of X").size() == 1);
+        CHECK(ancestry->query("mia is_parent_of X").size() == 1);
+        CHECK_FALSE(ancestry->resolve_name("berlin").has_value());
+        CHECK(ancestry->resolve_name("Peter", "de") == ancestry->resolve_name("peter"));
+
+        ancestry->process("tom is_parent_of lea");
+        CHECK(interactive.query("tom is_parent_of X").empty());
+
+        const auto shallow = interactive.subgraph({"peter"}, 1);
+        CHECK(shallow->query("peter lives_in X").size() == 1);
+        CHECK(shallow->query("mia is_parent_of X").empty());
+
+        CHECK_THROWS_AS(interactive.subgraph({"nobody"}, 1), zelph::console::process_error);
+        CHECK_THROWS_AS(interactive.copy_subgraph({"peter"}, 1, {}, interactive), zelph::console::process_error); });
+}
+
+TEST_CASE("diff: statements added and removed between two instances")
+{
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive yesterday(collector.sink());
+    zelph::console::Interactive today(collector.sink());
+    process_lines(yesterday, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+anna lives_in bern
+)");
+    process_lines(today, R"(
+(A is_parent_of B) => (B is_child_of A)
+anna lives_in berlin
+paul is_parent_of peter
+paul is_parent_of mia
+)");
+
+    const auto diff = zelph::console::Interactive::diff(yesterday, today);
+    std::set<std::string> added, removed;
+    for (const auto& fact : diff.added)
+        added.insert(fact.subject + " " + fact.predicate + " " + fact.objects.front());
+    for (const auto& fact : diff.removed)
+        removed.insert(fact.subject + " " + fact.predicate + " " + fact.objects.front());
+
+    CHECK((added == std::set<std::string>{"anna lives_in berlin", "paul is_parent_of mia", "mia is_child_of paul"}));
+    CHECK((removed == std::set<std::string>{"anna lives_in bern"}));
+
+    const auto same = zelph::console::Interactive::diff(today, today);
+    CHECK(same.added.empty());
+    CHECK(same.removed.empty());
+}
+
+TEST_CASE("merge: facts and rules are combined, contradictions handled by policy")
+{
+    using Policy = zelph::console::Interactive::MergePolicy;
+    zelph::io::OutputCollector collector;
+
+    const std::string left_script = R"(
+(R excludes S, A R B, A S B, R != S) => !
+parent_of excludes child_of
+paul parent_of peter
+)";
+    zelph::console::Interactive right(collector.sink());
+    process_lines(right, R"(
+(A parent_of B) => (B has_parent A)
+paul parent_of peter
+paul child_of peter
+anna parent_of tim
+)");
+
+    {
+        zelph::console::Interactive left(collector.sink());
+        process_lines(left, left_script);
+        const auto report = left.merge(right, Policy::KeepBoth);
+        CHECK(report.facts_added == 2);
+        CHECK(report.facts_duplicate == 1);
+        CHECK(report.rules_added == 1);
+        CHECK(report.rules_duplicate == 0);
+        CHECK(report.conflicts.size() == 1);
+        CHECK(left.query("tim has_parent X").size() == 1);
+        CHECK(left.conflicts().size() == 1);
+    }
+    {
+        zelph::console::Interactive left(collector.sink());
+        process_lines(left, left_script);
+        const auto report = left.merge(right, Policy::PreferLeft);
+        REQUIRE(report.rejected.size() == 1);
+        CHECK(report.rejected[0].predicate == "child_of");
+        CHECK(left.conflicts().empty());
+        CHECK(left.query("paul child_of X").empty());
+        CHECK(left.query("anna parent_of X").size() == 1);
+    }
+    {
+        zelph::console::Interactive left(collector.sink());
+        process_lines(left, left_script);
+        CHECK_THROWS_AS(left.merge(right, Policy::Fail), zelph::console::process_error);
+        CHECK(left.query("anna parent_of X").empty());
+        CHECK(left.rules().size() == 1);
+        CHECK(left.conflicts().empty());
+    }
+}
+
+TEST_CASE("speculate: what-if reasoning leaves the network unchanged")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        const size_t facts_before = interactive.facts().size();
+
+        size_t children = 0;
+        interactive.speculate([&](const zelph::console::Interactive& what_if)
+                              {
+            what_if.process("paul is_parent_of mia");
+            children = what_if.query("X is_child_of paul").size(); });
+        CHECK(children == 2);
+        CHECK(interactive.query("X is_child_of paul").size() == 1);
+        CHECK(interactive.facts().size() == facts_before);
+        CHECK_FALSE(interactive.in_transaction());
+
+        CHECK_THROWS_AS(interactive.speculate([](const zelph::console::Interactive& what_if)
+                                              {
+            what_if.process("anna is_parent_of tom");
+            throw std::runtime_error("abandoned"); }),
+                        std::runtime_error);
+        CHECK(interactive.query("anna is_parent_of X").empty());
+
+        interactive.begin();
+        CHECK_THROWS_AS(interactive.speculate([](const zelph::console::Interactive&) {}), zelph::console::process_error);
+        interactive.rollback(); });
+}
+
+TEST_CASE("metrics: gauges and counters in the Prometheus text format")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        const std::string before = interactive.metrics();
+        CHECK(before.find("# TYPE zelph_facts gauge") != std::string::npos);
+        CHECK(before.find("zelph_metrics_enabled 0") != std::string::npos);
+        CHECK(before.find("zelph_runs_total 0") != std::string::npos);
+
+        interactive.set_metrics_enabled(true);
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+anna is_parent_of peter
+)");
+        CHECK(interactive.query("peter is_child_of X").size() == 2);
+
+        const std::string text = interactive.metrics();
+        CHECK(text.find("zelph_facts 4") != std::string::npos);
+        CHECK(text.find("zelph_deduced_facts 2") != std::string::npos);
+        CHECK(text.find("zelph_rules 1") != std::string::npos);
+        CHECK(text.find("zelph_deductions_total 2") != std::string::npos);
+        CHECK(text.find("zelph_rule_firings_total{rule=\"") != std::string::npos);
+        CHECK(text.find("zelph_runs_total 0") == std::string::npos);
+        CHECK(text.find("zelph_run_duration_seconds_bucket{le=\"+Inf\"}") != std::string::npos);
+        CHECK(text.find("zelph_query_duration_seconds_count 0") == std::string::npos);
+
+        process_lines(interactive, R"(
+.metrics off
+.metrics
+)");
+        CHECK(any_output_contains(collector, "zelph_metrics_enabled 0"));
+        CHECK(any_output_contains(collector, "zelph_deductions_total 2")); });
+}
+
+TEST_CASE("conflicts: contradictions are listed with their rule and facts")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(R excludes S, A R B, A S B, R != S) => !
+parent_of excludes child_of
+anna parent_of tim
+paul parent_of peter
+paul child_of peter
+)");
+        interactive.run(false, false, false);
+
+        auto conflicts = interactive.conflicts();
+        REQUIRE(conflicts.size() == 1);
+        CHECK(conflicts[0].rule != 0);
+        CHECK(conflicts[0].facts.size() == 3);
+        CHECK(std::any_of(conflicts[0].fact_texts.begin(), conflicts[0].fact_texts.end(), [](const std::string& text)
+                          { return text.find("child_of") != std::string::npos && text.find("excludes") == std::string::npos; }));
+
+        uint64_t clash = 0;
+        for (const auto& f : interactive.facts())
+            if (f.predicate == "child_of") clash = f.id;
+        REQUIRE(clash != 0);
+        CHECK(std::find(conflicts[0].facts.begin(), conflicts[0].facts.end(), clash) != conflicts[0].facts.end());
+
+        interactive.retract(clash);
+        CHECK(interactive.conflicts().empty()); });
+}
+
+TEST_CASE("rules: embedders list, disable and remove rules")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+.auto-run
+paul is_parent_of peter
+)");
+
+        const uint64_t rule = interactive.add_rule("(A is_parent_of B)", "(B is_child_of A)");
+        auto           rules = interactive.rules();
+        REQUIRE(rules.size() == 1);
+        CHECK(rules[0].id == rule);
+        CHECK(rules[0].enabled);
+
+        interactive.set_rule_enabled(rule, false);
+        interactive.run(false, false, false);
+        CHECK(interactive.query("peter is_child_of X").empty());
+        CHECK_FALSE(interactive.rules()[0].enabled);
+
+        collector.clear();
+        interactive.process(".list-rules");
+        CHECK(any_output_contains(collector, "(disabled)"));
+
+        interactive.set_rule_enabled(rule, true);
+        interactive.run(false, false, false);
+        CHECK(interactive.query("peter is_child_of X").size() == 1);
+
+        interactive.remove_rule(rule);
+        CHECK(interactive.rules().empty());
+        CHECK_THROWS_AS(interactive.remove_rule(rule), zelph::console::process_error);
+        CHECK_THROWS_AS(interactive.set_rule_enabled(interactive.facts().at(0).id, false), zelph::console::process_error); });
+}
+
+TEST_CASE("cancel: a non-terminating run stops and keeps derived facts")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process("zero ~ nat");
+
+        // Cancel once the run has deduced something, so the run is known
+        // to be in progress.
+        std::atomic<bool> started{false};
+        interactive.on_deduction([&](const auto&)
+                                 { started = true; });
+
+        // Every firing nests the subject one level deeper: no fixpoint.
+        std::thread canceller([&]
+                              {
+            while (!started) std::this_thread::yield();
+            interactive.cancel(); });
+
+        std::string kind;
+        try
+        {
+            interactive.process("(X ~ nat) => ((X plus one) ~ nat)");
+        }
+        catch (const zelph::console::process_error& ex)
+        {
+            kind = zelph::console::to_string(ex.kind());
+        }
+        canceller.join();
+        interactive.on_deduction(nullptr);
+
+        CHECK(kind == "cancelled");
+        CHECK(interactive.query("(zero plus one) ~ X").size() == 1); });
+}
+
+TEST_CASE("cancel: a request while nothing runs stops neither queries nor the next run")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.set_query_cache(true);
+        interactive.process("(X is_parent_of Y) => (Y is_child_of X)");
+        interactive.process("paul is_parent_of peter");
+
+        interactive.cancel();
+        CHECK(interactive.query("peter is_child_of X").size() == 1);
+        CHECK(interactive.query("peter is_child_of X").size() == 1);
+
+        interactive.cancel();
+        interactive.process("anna is_parent_of tom");
+        interactive.run(false, false, false);
+        CHECK(interactive.query("tom is_child_of X").size() == 1);
+        CHECK(interactive.fixpoint_reached()); });
+}
+
+TEST_CASE("resource limits: a non-terminating run stops at .max-facts")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process(".max-facts 100");
+        interactive.process("zero ~ nat");
+
+        std::string kind;
+        try
+        {
+            interactive.process("(X ~ nat) => ((X plus one) ~ nat)");
+        }
+        catch (const zelph::console::process_error& ex)
+        {
+            kind = zelph::console::to_string(ex.kind());
+        }
+
+        CHECK(kind == "resource-limit");
+        CHECK(interactive.query("(zero plus one) ~ X").size() == 1);
+
+        interactive.process(".max-facts 0");
+        CHECK(any_output_contains(collector, "Runs may deduce any number of facts.")); });
+}
+
+TEST_CASE("threads: deductions are inserted in the same order for every thread count")
+{
+    auto deduce = [](size_t threads)
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive interactive(collector.sink());
+        interactive.set_thread_count(threads);
+        CHECK(interactive.thread_count() >= 1);
+
+        for (int i = 0; i < 200; ++i)
+            interactive.process("p" + std::to_string(i) + " parent c" + std::to_string(i));
+        interactive.process("(X parent Y) => (Y child X)");
+
+        std::vector<std::pair<uint64_t, std::string>> deduced;
+        for (const auto& fact : interactive.facts())
+            if (fact.deduced) deduced.emplace_back(fact.id, fact.subject);
+        return deduced;
+    };
+
+    const auto single = deduce(1);
+    CHECK(single.size() == 200);
+    CHECK(deduce(8) == single);
+}
+
+TEST_CASE("indexes: every lookup orientation finds the same matches")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        for (int i = 0; i < 300; ++i)
+            interactive.process("hub likes x" + std::to_string(i));
+        interactive.process("alice likes bob");
+        interactive.process("hub likes bob");
+        interactive.process("(X likes bob) => (X knows bob)");
+
+        // 1 = SPO, 2 = POS, 4 = OSP
+        for (uint8_t indexes : {2, 3, 6, 7})
+        {
+            interactive.set_triple_indexes(indexes);
+            CHECK(interactive.triple_indexes() == indexes);
+            CHECK(interactive.query("X likes bob").size() == 2);
+            CHECK(interactive.query("hub likes X").size() == 301);
+            CHECK(interactive.query("X knows bob").size() == 2);
+        }
+
+        interactive.set_triple_indexes(1);
+        CHECK(interactive.triple_indexes() == 3);
+
+        interactive.process(".indexes osp");
+        CHECK(any_output_contains(collector, "Fact lookup orientations: pos osp")); });
+}
+
+TEST_CASE("sessions: users keep their own settings and undo their own lines")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        zelph::console::Session     alice(interactive, "alice");
+        zelph::console::Session     bob(interactive, "bob");
+
+        alice.process(".context work");
+        alice.process("paul is_parent_of peter");
+        bob.process("anna is_parent_of peter");
+        bob.process("tom is_parent_of peter");
+
+        auto fact_id = [&](const std::string& subject)
+        {
+            for (const auto& fact : interactive.facts())
+                if (fact.subject == subject && fact.predicate == "is_parent_of") return fact.id;
+            return uint64_t{0};
+        };
+        const uint64_t paul = fact_id("paul");
+        const uint64_t anna = fact_id("anna");
+        REQUIRE(paul != 0);
+        REQUIRE(anna != 0);
+
+        CHECK(interactive.contexts(paul) == std::vector<std::string>{"work"});
+        CHECK(interactive.contexts(anna).empty());
+        CHECK(interactive.provenance(paul).source == "session:alice");
+        CHECK(interactive.provenance(paul).line == 2);
+        CHECK(interactive.provenance(anna).source == "session:bob");
+
+        CHECK(bob.undo_depth() == 2);
+        CHECK(bob.undo() == 1);
+        CHECK(fact_id("tom") == 0);
+        CHECK(bob.query("X is_parent_of peter").size() == 2);
+
+        bob.process(".context-scope work");
+        CHECK(bob.query("X is_parent_of peter").size() == 1);
+        CHECK(alice.query("X is_parent_of peter").size() == 2);
+        CHECK(interactive.query("X is_parent_of peter").size() == 2);
+
+        alice.process("(A is_parent_of B) => (B is_child_of A)");
+        interactive.run(false, false, false);
+        CHECK(interactive.query("peter is_child_of X").size() == 2);
+        CHECK(alice.undo() == 3);
+        CHECK(interactive.query("peter is_child_of X").empty());
+        CHECK(alice.undo_depth() == 1);
+
+        CHECK_THROWS_AS(zelph::console::Session(interactive, ""), zelph::console::process_error); });
+}
+
+TEST_CASE_FIXTURE(InteractiveFixture, "rule profile: firings, new facts and time per rule of the last run")
+{
+    process_lines(interactive, R"(
+.auto-run
+(A is_parent_of B) => (B is_child_of A)
+(A is_sibling_of B) => (B is_sibling_of A)
+paul is_parent_of peter
+paul is_parent_of anna
+)");
+    interactive.run(false, false, false);
+    CHECK(interactive.rule_profile().empty());
+    CHECK(interactive.rule_profile_report().find("No rule profile") != std::string::npos);
+
+    interactive.set_rule_profiling(true);
+    interactive.process("emma is_parent_of fred");
+    interactive.run(false, false, false);
+
+    const auto profile = interactive.rule_profile();
+    REQUIRE(profile.size() == 2);
+    const auto parent = std::find_if(profile.begin(), profile.end(), [](const auto& p)
+                                     { return p.text.find("is_parent_of") != std::string::npos; });
+    const auto sibling = std::find_if(profile.begin(), profile.end(), [](const auto& p)
+                                      { return p.text.find("is_sibling_of") != std::string::npos; });
+    REQUIRE(parent != profile.end());
+    REQUIRE(sibling != profile.end());
+    CHECK(parent->firings >= 1);
+    CHECK(parent->deductions == 1);
+    CHECK(parent->seconds >= 0);
+    CHECK(sibling->firings == 0);
+    CHECK(sibling->deductions == 0);
+
+    const std::string report = interactive.rule_profile_report();
+    CHECK(report.find("flat%") != std::string::npos);
+    CHECK(report.find("Never fired:") < report.size());
+    CHECK(report.find("is_sibling_of", report.find("Never fired:")) != std::string::npos);
+
+    collector.clear();
+    process_lines(interactive, R"(
+.rule-profile
+.rule-profile off
+)");
+    CHECK(any_output_starts_with(collector, "Rule profile of the last run: 2 rules"));
+    CHECK(any_output_starts_with(collector, "Rule profiling: off"));
+}
+
+TEST_CASE("watch: added, deduced and retracted facts are reported as they happen")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        using zelph::console::Interactive;
+
+        std::vector<std::string> all;
+        std::vector<std::string> children;
+        std::vector<std::string> rules;
+        const uint64_t           everything = interactive.watch([&](const Interactive::Change& change)
+                                                      { all.push_back(change.kind + " " + change.fact.subject + " " + change.fact.predicate); });
+        interactive.watch([&](const Interactive::Change& change)
+                          {
+                              children.push_back(change.kind + " " + change.fact.objects.at(0));
+                              CHECK(change.deduction.has_value() == (change.kind == "deduced"));
+                              if (change.deduction) rules.push_back(change.deduction->rule_text); },
+                          Interactive::Triple{Interactive::Term::var(), Interactive::Term::constant("is_child_of"), Interactive::Term::var()});
+
+        interactive.process("(A is_parent_of B) => (B is_child_of A)");
+        interactive.process("paul is_parent_of peter");
+        CHECK((all == std::vector<std::string>{"added paul is_parent_of", "deduced peter is_child_of"}));
+        CHECK(children == std::vector<std::string>{"deduced paul"});
+        REQUIRE(rules.size() == 1);
+        CHECK(rules[0].find("is_parent_of") != std::string::npos);
+
+        uint64_t fact = 0;
+        for (const auto& f : interactive.facts())
+            if (f.subject == "paul") fact = f.id;
+        REQUIRE(fact != 0);
+        CHECK(interactive.retract(fact) == 1);
+        CHECK(all.size() == 4);
+        CHECK(std::find(all.begin(), all.end(), "retracted paul is_parent_of") != all.end());
+        CHECK(std::find(all.begin(), all.end(), "retracted peter is_child_of") != all.end());
+        CHECK(children.back() == "retracted paul");
+
+        interactive.unwatch(everything);
+        interactive.process("anna is_parent_of tom");
+        CHECK(all.size() == 4);
+        CHECK(children.back() == "deduced anna"); });
+}
+
+TEST_CASE("concurrency: readers run alongside a writer and see whole statements")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process("(X parent Y) => (Y child X)");
+
+        std::atomic<bool> done{false};
+        std::atomic<bool> torn{false};
+        std::vector<std::thread> readers;
+        for (int i = 0; i < 4; ++i)
+            readers.emplace_back([&]
+                                 {
+                while (!done)
+                {
+                    // Auto-run deduces the child fact within the same
+                    // process call, so readers never see a parent alone.
+                    size_t parents = 0, children = 0;
+                    for (const auto& fact : interactive.facts())
+                    {
+                        if (fact.predicate == "parent") ++parents;
+                        if (fact.predicate == "child") ++children;
+                    }
+                    if (parents != children) torn = true;
+                } });
+
+        for (int i = 0; i < 50; ++i)
+            interactive.process("p" + std::to_string(i) + " parent c" + std::to_string(i));
+
+        done = true;
+        for (auto& reader : readers)
+            reader.join();
+
+        CHECK_FALSE(torn);
+        CHECK(interactive.query("X child Y").size() == 50); });
+}
+
+TEST_CASE("concurrency: a query runs alongside readers")
+{
+    run_both_modes([](auto&, auto& interactive)
+                   {
+        interactive.process("anna likes bob");
+
+        std::atomic<bool> inside{false};
+        std::atomic<bool> release{false};
+        std::thread       querying([&]
+                             { interactive.query_each("X likes bob", [&](const auto&)
+                                                      {
+                inside = true;
+                while (!release)
+                    std::this_thread::yield();
+                return false; }); });
+
+        while (!inside)
+            std::this_thread::yield();
+
+        // The query waits in its handler; a reader is not held up by it.
+        CHECK_FALSE(interactive.facts().empty());
+        release = true;
+        querying.join(); });
+}
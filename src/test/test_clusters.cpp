@@ -25,6 +25,7 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #include <doctest/doctest.h> // provides main()
 
+#include "process_error.hpp"
 #include "test_helpers.hpp"
 
 using namespace zelph::test;
@@ -84,3 +85,60 @@ a relP b
         interactive.process("X relP Y");
         CHECK(answers_contain(collector, "a relP b")); });
 }
+
+TEST_CASE("transactions: rollback discards a batch including its deductions")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+otto is_parent_of paul
+)");
+        interactive.begin();
+        CHECK(interactive.in_transaction());
+        CHECK_THROWS_AS(interactive.begin(), zelph::console::process_error);
+        process_lines(interactive, R"(
+paul is_parent_of peter
+)");
+        CHECK_THROWS_AS(interactive.process("paul is_parent_of (peter"), zelph::console::process_error);
+        CHECK(interactive.rollback() > 0);
+        CHECK_FALSE(interactive.in_transaction());
+
+        collector.clear();
+        interactive.process("X is_child_of Y");
+        CHECK(answers_contain(collector, "paul is_child_of otto"));
+        CHECK_FALSE(answers_contain(collector, "peter is_child_of paul"));
+
+        // The deduction is forgotten with its node: stated again, the fact
+        // counts as stated.
+        process_lines(interactive, R"(
+peter is_child_of paul
+)");
+        for (const auto& f : interactive.facts())
+            if (f.subject == "peter" && f.predicate == "is_child_of") CHECK_FALSE(f.deduced); });
+}
+
+TEST_CASE("transactions: commit keeps the batch in the cluster active before")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+.cluster exp
+)");
+        interactive.begin();
+        process_lines(interactive, R"(
+tmp1 relC tmp2
+)");
+        interactive.commit();
+        CHECK_THROWS_AS(interactive.commit(), zelph::console::process_error);
+
+        collector.clear();
+        interactive.process("X relC Y");
+        CHECK(answers_contain(collector, "tmp1 relC tmp2"));
+
+        // The batch now belongs to exp, so dropping exp removes it.
+        interactive.process(".cluster-drop exp");
+        collector.clear();
+        interactive.process("X relC Y");
+        CHECK_FALSE(answers_contain(collector, "tmp1 relC tmp2")); });
+}
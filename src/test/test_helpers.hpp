@@ -374,6 +374,15 @@ namespace zelph::test
         }
     }
 
+    // A single Interactive in its default mode, for TEST_CASE_FIXTURE.
+    // Only for tests bound to one instance, like servers and the REPL,
+    // or to counts of one run; everything else uses run_both_modes.
+    struct InteractiveFixture
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive interactive{collector.sink()};
+    };
+
     // Run a test against every arithmetic stdlib module, nested into
     // run_both_modes: 3 modules x 2 parallelism modes per leaf subcase,
     // each in `.semi-naive check` mode. All three modules expose the
@@ -0,0 +1,873 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include <doctest/doctest.h> // provides main()
+
+#include "process_error.hpp"
+#include "test_helpers.hpp"
+
+#include <algorithm>
+#include <atomic>
+#include <chrono>
+#include <cstdint>
+#include <filesystem>
+#include <fstream>
+#include <functional>
+#include <iterator>
+#include <sstream>
+#include <thread>
+
+using namespace zelph::test;
+
+TEST_CASE("include: modules are read once, in their own namespace, and cycles are rejected")
+{
+    namespace fs = std::filesystem;
+    const fs::path dir = fs::temp_directory_path() / "zelph-test-include";
+    fs::remove_all(dir);
+    fs::create_directories(dir / "kb");
+    auto write = [&](const fs::path& file, const std::string& text)
+    {
+        std::ofstream out(file);
+        out << text;
+    };
+    write(dir / "kb" / "family.zph", "paul \"is parent of\" peter\n");
+    write(dir / "kb" / "other.zph", "paul \"is parent of\" anna\n");
+    write(dir / "kb" / "a.zph", ".include b\n");
+    write(dir / "kb" / "b.zph", ".include a\n");
+
+    run_both_modes([&](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process("\"is parent of\" ~ relation");
+        interactive.process(".include-path " + (dir / "kb").string());
+        interactive.process(".include family");
+        interactive.process(".include other");
+        interactive.process(".include family");
+
+        auto children = interactive.query("family:paul \"is parent of\" X");
+        REQUIRE(children.size() == 1);
+        CHECK(children[0].at("X") == "family:peter");
+        children = interactive.query("other:paul \"is parent of\" X");
+        REQUIRE(children.size() == 1);
+        CHECK(children[0].at("X") == "other:anna");
+        CHECK(interactive.query("paul \"is parent of\" X").empty());
+
+        CHECK_THROWS_WITH(interactive.process(".include a"), doctest::Contains("includes itself"));
+
+        fs::remove_all(dir); });
+}
+
+#ifndef __EMSCRIPTEN__
+TEST_CASE("persistence: a saved network is restored by another instance")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-persistence.bin").string();
+
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive source(collector.sink());
+        process_lines(source, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        source.save(file);
+    }
+
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive target(collector.sink());
+    target.load(file);
+    std::filesystem::remove(file);
+
+    // The deduction is restored, no script has to be re-run.
+    auto answers = target.query("peter is_child_of X");
+    REQUIRE(answers.size() == 1);
+    CHECK(answers[0].at("X") == "paul");
+
+    CHECK_THROWS_AS(target.save("no-extension"), zelph::console::process_error);
+}
+
+namespace
+{
+    // Rewrites fields of the header of a saved .bin file, the first
+    // Cap'n Proto message in it, to forge files of other origins and
+    // versions. The message is unpacked from Cap'n Proto's packed encoding,
+    // edited and packed again; the chunks after it stay as they are. edit
+    // receives the unpacked message and the byte offsets of the root
+    // struct's data and pointer sections.
+    using HeaderEdit = std::function<void(std::vector<uint8_t>& message, size_t data, size_t pointers)>;
+
+    void rewrite_bin_header(const std::string& file, const HeaderEdit& edit)
+    {
+        std::vector<uint8_t> packed;
+        {
+            std::ifstream in(file, std::ios::binary);
+            packed.assign(std::istreambuf_iterator<char>(in), std::istreambuf_iterator<char>());
+        }
+
+        std::vector<uint8_t> message;
+        auto                 read32 = [&](size_t at)
+        {
+            return static_cast<uint32_t>(message[at]) | static_cast<uint32_t>(message[at + 1]) << 8
+                 | static_cast<uint32_t>(message[at + 2]) << 16 | static_cast<uint32_t>(message[at + 3]) << 24;
+        };
+        // The segment table tells the size of the message once it is read.
+        auto message_words = [&]() -> size_t
+        {
+            if (message.size() < 8) return SIZE_MAX;
+            const size_t segments    = read32(0) + 1;
+            const size_t table_words = (4 + 4 * segments + 7) / 8;
+            if (message.size() < table_words * 8) return SIZE_MAX;
+            size_t words = table_words;
+            for (size_t i = 0; i < segments; ++i)
+                words += read32(4 + 4 * i);
+            return words;
+        };
+
+        size_t pos = 0;
+        while (message.size() / 8 < message_words())
+        {
+            const uint8_t tag = packed.at(pos++);
+            for (int bit = 0; bit < 8; ++bit)
+                message.push_back((tag & (1 << bit)) ? packed.at(pos++) : 0);
+            if (tag == 0x00)
+            {
+                message.insert(message.end(), packed.at(pos++) * size_t{8}, 0);
+            }
+            else if (tag == 0xff)
+            {
+                const size_t raw = packed.at(pos++) * size_t{8};
+                message.insert(message.end(), packed.begin() + pos, packed.begin() + pos + raw);
+                pos += raw;
+            }
+        }
+
+        const size_t   segment = (4 + 4 * (read32(0) + 1) + 7) / 8 * 8;
+        const uint64_t root    = read32(segment) | static_cast<uint64_t>(read32(segment + 4)) << 32;
+        const size_t   data    = segment + 8 + static_cast<size_t>(static_cast<int32_t>(root & 0xFFFFFFFFu) >> 2) * 8;
+        edit(message, data, data + ((root >> 32) & 0xFFFFu) * 8);
+
+        std::vector<uint8_t> repacked;
+        for (size_t word = 0; word < message.size() / 8;)
+        {
+            const uint8_t* bytes = &message[word * 8];
+            uint8_t        tag   = 0;
+            for (int bit = 0; bit < 8; ++bit)
+                if (bytes[bit]) tag |= static_cast<uint8_t>(1 << bit);
+            repacked.push_back(tag);
+            for (int bit = 0; bit < 8; ++bit)
+                if (bytes[bit]) repacked.push_back(bytes[bit]);
+            ++word;
+
+            if (tag == 0x00)
+            {
+                uint8_t zeros = 0;
+                while (word < message.size() / 8 && zeros < 255
+                       && std::all_of(&message[word * 8], &message[word * 8] + 8, [](uint8_t b)
+                                      { return b == 0; }))
+                {
+                    ++zeros;
+                    ++word;
+                }
+                repacked.push_back(zeros);
+            }
+            else if (tag == 0xff)
+            {
+                repacked.push_back(0); // no uncompressed words follow
+            }
+        }
+        repacked.insert(repacked.end(), packed.begin() + pos, packed.end());
+
+        std::ofstream out(file, std::ios::binary | std::ios::trunc);
+        out.write(reinterpret_cast<const char*>(repacked.data()), static_cast<std::streamsize>(repacked.size()));
+    }
+
+    void save_sample_network(const std::string& file)
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive source(collector.sink());
+        process_lines(source, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        source.save(file);
+    }
+
+    // ZelphImpl's formatVersion, the fifth word of its data section.
+    void set_format_version(std::vector<uint8_t>& message, size_t data, uint32_t version)
+    {
+        for (size_t i = 0; i < 4; ++i)
+            message[data + 32 + i] = static_cast<uint8_t>(version >> (8 * i));
+    }
+}
+
+TEST_CASE("persistence: a file that is no zelph network is rejected")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-magic.bin").string();
+    save_sample_network(file);
+    rewrite_bin_header(file, [](std::vector<uint8_t>& message, size_t, size_t)
+                       {
+        const std::string magic = "zelph";
+        auto it = std::search(message.begin(), message.end(), magic.begin(), magic.end());
+        REQUIRE(it != message.end());
+        *it = 'Z'; });
+
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive target(collector.sink());
+    CHECK_THROWS_WITH_AS(target.load(file), doctest::Contains("Not a zelph network file"), zelph::console::process_error);
+    std::filesystem::remove(file);
+}
+
+TEST_CASE("persistence: a file of a newer format version is rejected")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-version.bin").string();
+    save_sample_network(file);
+    rewrite_bin_header(file, [](std::vector<uint8_t>& message, size_t data, size_t)
+                       { set_format_version(message, data, 1000); });
+
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive target(collector.sink());
+    CHECK_THROWS_WITH_AS(target.load(file), doctest::Contains("format version 1000"), zelph::console::process_error);
+    std::filesystem::remove(file);
+}
+
+TEST_CASE("persistence: a file from before format versioning still loads")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-unversioned.bin").string();
+    save_sample_network(file);
+
+    // No magic (its pointer, the second of the struct, is null) and version 0.
+    rewrite_bin_header(file, [](std::vector<uint8_t>& message, size_t data, size_t pointers)
+                       {
+        set_format_version(message, data, 0);
+        std::fill_n(message.begin() + static_cast<std::ptrdiff_t>(pointers + 8), 8, uint8_t{0}); });
+
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive target(collector.sink());
+    target.load(file);
+    std::filesystem::remove(file);
+
+    auto answers = target.query("peter is_child_of X");
+    REQUIRE(answers.size() == 1);
+    CHECK(answers[0].at("X") == "paul");
+}
+
+TEST_CASE("rdf: a Turtle file is imported as facts")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-import.ttl").string();
+    {
+        std::ofstream ttl(file);
+        ttl << R"(@prefix ex: <http://example.org/> .
+@prefix rdf: <http://www.w3.org/1999/02/22-rdf-syntax-ns#> .
+
+ex:alice a ex:Person ;
+    ex:knows ex:bob , ex:carol .
+ex:bob rdf:type ex:Person .
+)";
+    }
+
+    run_both_modes([&](auto& collector, auto& interactive)
+                   {
+        interactive.load(file);
+        std::filesystem::remove(file);
+
+        CHECK(any_output_contains(collector, "Imported 4 triples"));
+
+        // rdf:type and "a" both map onto ~.
+        auto persons = interactive.query("X ~ ex:Person");
+        CHECK(persons.size() == 2);
+
+        auto known = interactive.query("ex:alice ex:knows X");
+        REQUIRE(known.size() == 2);
+        CHECK((known[0].at("X") == "ex:bob" || known[1].at("X") == "ex:bob")); });
+}
+
+TEST_CASE("rdf: facts are exported as N-Quads with deduced facts in a named graph")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        interactive.run(false, false, false);
+
+        std::ostringstream quads;
+        interactive.export_rdf(quads, true);
+        const std::string nq = quads.str();
+
+        CHECK(nq.find("<urn:zelph:paul> <urn:zelph:is_parent_of> <urn:zelph:peter> .\n") != std::string::npos);
+        CHECK(nq.find("<urn:zelph:peter> <urn:zelph:is_child_of> <urn:zelph:paul> <urn:zelph:graph:deduced> .\n") != std::string::npos);
+
+        // The rule and its patterns are not statements.
+        CHECK(nq.find("<urn:zelph:A>") == std::string::npos);
+        CHECK(nq.find("<urn:zelph:B>") == std::string::npos);
+
+        std::ostringstream triples;
+        interactive.export_rdf(triples, false, "http://example.org/");
+        CHECK(triples.str().find("<http://example.org/peter> <http://example.org/is_child_of> <http://example.org/paul> .\n") != std::string::npos); });
+}
+
+TEST_CASE("jsonld: the context maps IRIs to names on import and back on export")
+{
+    const auto dir = std::filesystem::temp_directory_path();
+    const auto in  = (dir / "zelph-test-import.jsonld").string();
+    const auto out = (dir / "zelph-test-export.jsonld").string();
+    {
+        std::ofstream doc(in);
+        doc << R"({
+  "@context": {
+    "ex": "http://example.org/",
+    "knows": {"@id": "http://xmlns.com/foaf/0.1/knows", "@type": "@id"}
+  },
+  "@graph": [
+    {"@id": "ex:alice", "@type": "ex:Person", "knows": ["ex:bob", "ex:carol"]},
+    {"@id": "ex:bob", "@type": "ex:Person", "ex:age": 42}
+  ]
+})";
+    }
+
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive source(collector.sink());
+        source.load(in);
+        CHECK(any_output_contains(collector, "Imported 6 triples"));
+        CHECK(source.query("X ~ ex:Person").size() == 2);
+        CHECK(source.query("ex:alice knows X").size() == 2);
+        auto age = source.query("ex:bob ex:age X");
+        REQUIRE(age.size() == 1);
+        CHECK(age[0].at("X") == "42");
+
+        std::ofstream doc(out);
+        source.export_jsonld(doc, R"({"ex": "http://example.org/", "knows": "http://xmlns.com/foaf/0.1/knows"})");
+    }
+
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive target(collector.sink());
+    target.load(out);
+    std::filesystem::remove(in);
+    std::filesystem::remove(out);
+
+    auto known = target.query("ex:alice knows X");
+    REQUIRE(known.size() == 2);
+    CHECK((known[0].at("X") == "ex:bob" || known[1].at("X") == "ex:bob"));
+    CHECK(target.query("X ~ ex:Person").size() == 2);
+}
+
+TEST_CASE("neo4j: facts go out as Cypher and come back from an APOC export")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        interactive.run(false, false, false);
+
+        std::ostringstream cypher;
+        interactive.export_cypher(cypher);
+        const std::string c = cypher.str();
+        CHECK(c.find(" SET n.name = \"paul\";\n") != std::string::npos);
+        CHECK(c.find("MERGE (s)-[r:`is_parent_of`]->(o) SET r.deduced = false;\n") != std::string::npos);
+        CHECK(c.find("MERGE (s)-[r:`is_child_of`]->(o) SET r.deduced = true;\n") != std::string::npos);
+        CHECK(c.find("`is_child_of`") == c.rfind("`is_child_of`")); // not the rule
+
+        std::istringstream apoc(R"({"type":"node","id":"0","labels":["Person","Zelph"],"properties":{"name":"anna"}}
+{"type":"node","id":"1","labels":["Zelph"],"properties":{"name":"mary"}}
+{"type":"relationship","id":"0","label":"is_parent_of","properties":{"deduced":false},"start":{"id":"0"},"end":{"id":"1"}}
+)");
+        CHECK(interactive.import_neo4j(apoc) == 2);
+        CHECK(interactive.query("anna is_parent_of X").size() == 1);
+        CHECK(interactive.query("X ~ Person").size() == 1);
+        CHECK(interactive.query("X ~ Zelph").empty());
+
+        std::istringstream broken(R"({"type":"relationship","id":"0","label":"knows","start":{"id":"7"},"end":{"id":"1"}})");
+        CHECK_THROWS_WITH_AS(interactive.import_neo4j(broken), doctest::Contains("unknown node '7'"), zelph::console::process_error); });
+}
+
+TEST_CASE("datalog: facts and rules go out as Soufflé and Prolog clauses")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A "is part of" B, B "is part of" C) => (A "is part of" C)
+(A is yellow, ¬(A is green)) => (A "is not" green)
+(A instanceof B, A subclassof B) => !
+(X claims (Y is Z)) => (Y "is claimed" Z)
+wheel "is part of" car
+car "is part of" fleet
+plant is yellow
+)");
+        interactive.run(false, false, false);
+
+        std::ostringstream souffle;
+        const auto         counts = interactive.export_datalog(souffle);
+        const std::string  dl     = souffle.str();
+        CHECK(counts.facts == 3); // the deduced wheel "is part of" fleet is left out
+        CHECK(counts.rules == 3);
+        CHECK(counts.skipped == 1);
+        CHECK(dl.starts_with(".decl fact(s: symbol, p: symbol, o: symbol)\n.output fact\n.decl contradiction()\n"));
+        CHECK(dl.find("fact(\"wheel\", \"is part of\", \"car\").\n") != std::string::npos);
+        CHECK(dl.find("fact(\"wheel\", \"is part of\", \"fleet\")") == std::string::npos);
+        CHECK(dl.find("fact(A, \"is not\", \"green\") :- fact(A, \"is\", \"yellow\"), !fact(A, \"is\", \"green\").\n") != std::string::npos);
+
+        const size_t transitive = dl.find("fact(A, \"is part of\", C) :- ");
+        REQUIRE(transitive != std::string::npos);
+        const std::string clause = dl.substr(transitive, dl.find('\n', transitive) - transitive);
+        CHECK(clause.find("fact(A, \"is part of\", B)") != std::string::npos);
+        CHECK(clause.find("fact(B, \"is part of\", C)") != std::string::npos);
+        CHECK(dl.find("contradiction() :- ") != std::string::npos);
+        CHECK(dl.find("skipped: nested statement\n") != std::string::npos);
+
+        std::ostringstream prolog;
+        interactive.export_datalog(prolog, {zelph::io::DatalogDialect::Prolog, true});
+        const std::string pl = prolog.str();
+        CHECK(pl.starts_with(":- table fact/3.\n"));
+        CHECK(pl.find("fact('wheel', 'is part of', 'fleet').\n") != std::string::npos);
+        CHECK(pl.find("tnot(fact(A, 'is', 'green'))") != std::string::npos);
+        CHECK(pl.find("% rule ") != std::string::npos); });
+}
+
+TEST_CASE("datalog: Datalog clauses become facts, rules and queries")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        std::istringstream program(R"(% family
+parent(paul, peter).
+parent(peter, 'Mary Ann').
+human(paul).
+ancestor(X, Y) :- parent(X, Y).
+ancestor(X, Z) :- parent(X, Y),
+                  ancestor(Y, Z).
+root(X) :- human(X), \+ parent(_, X).
+p(a, b, c).
+)");
+
+        std::vector<zelph::console::ScriptLineError> errors;
+        try
+        {
+            interactive.process_datalog(program);
+        }
+        catch (const zelph::console::script_error& ex)
+        {
+            errors = ex.errors();
+        }
+        REQUIRE(errors.size() == 1);
+        CHECK(errors[0].number == 9);
+        CHECK(errors[0].kind == zelph::console::ProcessErrorKind::Syntax);
+        CHECK(errors[0].reason.find("p/3") != std::string::npos);
+
+        interactive.run(false, false, false);
+        CHECK(interactive.query("paul ancestor X").size() == 2);
+        auto roots = interactive.query("X ~ root");
+        REQUIRE(roots.size() == 1);
+        CHECK(roots[0].at("X") == "paul");
+
+        // What export_datalog writes reads back to the same deductions.
+        std::ostringstream exported;
+        interactive.export_datalog(exported);
+        zelph::console::Interactive copy(collector.sink());
+        std::istringstream          in(exported.str());
+        copy.process_datalog(in);
+        copy.run(false, false, false);
+        CHECK(copy.query("paul ancestor X").size() == 2);
+        CHECK(copy.query("X ~ root").size() == 1); });
+}
+
+TEST_CASE("rdf: a Turtle syntax error names the line")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-broken.ttl").string();
+    {
+        std::ofstream ttl(file);
+        ttl << "@prefix ex: <http://example.org/> .\n"
+               "ex:a ex:b ex:c .\n"
+               "ex:a foaf:knows ex:c .\n";
+    }
+
+    run_both_modes([&](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        try
+        {
+            interactive.load(file);
+            FAIL("expected a process_error");
+        }
+        catch (const zelph::console::process_error& e)
+        {
+            CHECK(std::string(e.what()).find(":3:") != std::string::npos);
+        }
+        std::filesystem::remove(file); });
+}
+#endif
+
+TEST_CASE("bulk: plain facts are streamed in, rules apply on the next run")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process("(A is_parent_of B) => (B is_child_of A)");
+
+        std::istringstream in("# family\n"
+                              "paul\tis_parent_of\tpeter\n"
+                              "\n"
+                              "anna is_parent_of \"mary ann\"\r\n"
+                              "anna is_parent_of peter");
+
+        std::vector<uint64_t> reported;
+        zelph::io::BulkOptions options;
+        options.progress_interval = 2;
+        options.progress          = [&](const zelph::io::BulkProgress& p)
+        { reported.push_back(p.lines); };
+
+        const auto result = interactive.bulk_load(in, options);
+        CHECK(result.facts == 3);
+        CHECK(result.lines == 5);
+        CHECK((reported == std::vector<uint64_t>{2, 4, 5}));
+
+        auto children = interactive.query("anna is_parent_of X");
+        CHECK(children.size() == 2);
+
+        interactive.run(false, false, false);
+        auto parents = interactive.query("peter is_child_of X");
+        CHECK(parents.size() == 2);
+
+        std::istringstream broken("a b c\na b\n");
+        CHECK_THROWS_WITH_AS(interactive.bulk_load(broken), doctest::Contains("line 2"), zelph::console::process_error);
+        CHECK(interactive.query("a b X").size() == 1); });
+}
+
+TEST_CASE("csv: rows become facts by a column mapping, bad rows are collected")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::istringstream people("name,city,age\r\n"
+                                  "paul,\"Bern, CH\",042\r\n"
+                                  "\"peter \"\"pete\"\"\",Zug,\"3.50\"\r\n"
+                                  "anna,,x\r\n"
+                                  "\r\n"
+                                  "mary\r\n");
+
+        const auto lives = interactive.import_csv(people, zelph::io::parse_csv_mapping({"name", "=lives in", "city"}));
+        CHECK(lives.rows == 4);
+        CHECK(lives.facts == 2);
+        CHECK(lives.skipped == 1);
+        REQUIRE(lives.errors.size() == 1);
+        CHECK(lives.errors[0].line == 6);
+
+        auto city = interactive.query("paul \"lives in\" X");
+        REQUIRE(city.size() == 1);
+        CHECK(city[0].at("X") == "Bern, CH");
+        auto zug = interactive.query("X \"lives in\" Zug");
+        REQUIRE(zug.size() == 1);
+        CHECK(zug[0].at("X") == "peter \"pete\"");
+
+        people.clear();
+        people.seekg(0);
+        const auto ages = interactive.import_csv(people, zelph::io::parse_csv_mapping({"name", "=age", "age:number"}));
+        CHECK(ages.facts == 2);
+        REQUIRE(ages.errors.size() == 2);
+        CHECK(ages.errors[0].line == 4);
+        CHECK(ages.errors[0].column == "age");
+        auto age = interactive.query("paul age X");
+        REQUIRE(age.size() == 1);
+        CHECK(age[0].at("X") == "42");
+        CHECK(interactive.query("X age 3.5").size() == 1);
+
+        std::istringstream no_header("a;b\n");
+        CHECK_THROWS_WITH_AS(interactive.import_csv(no_header, zelph::io::parse_csv_mapping({"name", "=x", "city"})),
+                             doctest::Contains("no column 'name'"),
+                             zelph::console::process_error);
+        CHECK_THROWS_AS(zelph::io::parse_csv_mapping({"1", "=x", "2", "separator=;"}), std::runtime_error); });
+}
+
+TEST_CASE("lexical: ConceptNet and WordNet importers filter by language and relation")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::istringstream conceptnet("/a/1\t/r/IsA\t/c/en/dog/n\t/c/en/domestic_animal\t{\"weight\": 2.0}\n"
+                                      "/a/2\t/r/IsA\t/c/de/hund\t/c/de/tier\t{\"weight\": 2.0}\n"
+                                      "/a/3\t/r/IsA\t/c/en/cat\t/c/en/pet\t{\"weight\": 0.5}\n"
+                                      "/a/4\t/r/PartOf\t/c/en/tail\t/c/en/dog\t{\"weight\": 1.0}\n"
+                                      "/a/5\t/r/ExternalURL\t/c/en/dog\thttp://dbpedia.org/resource/Dog\t{\"weight\": 1.0}\n");
+
+        const auto cn = interactive.import_conceptnet(conceptnet, zelph::io::parse_conceptnet_options({"languages=en", "relations=IsA,ExternalURL", "min-weight=1"}));
+        CHECK(cn.lines == 5);
+        CHECK(cn.facts == 1);
+        auto kinds = interactive.query("dog IsA X");
+        REQUIRE(kinds.size() == 1);
+        CHECK(kinds[0].at("X") == "domestic animal");
+        CHECK(interactive.query("hund IsA X").empty());
+        CHECK(interactive.query("cat IsA X").empty());
+        CHECK(interactive.query("tail PartOf X").empty());
+
+        std::istringstream wordnet("  1 This software and database is being provided\n"
+                                   "02084071 05 n 02 dog 0 domestic_dog 0 002 @ 02083346 n 0000 %p 02158846 n 0000 | a member of the genus Canis  \n"
+                                   "02083346 05 n 01 canine 0 001 ~ 02084071 n 0000 | any of various fissiped mammals  \n");
+
+        const auto wn = interactive.import_wordnet(wordnet, zelph::io::parse_wordnet_options({"glosses"}));
+        CHECK(wn.facts == 6);
+        auto dog = interactive.query("X lemma \"domestic dog\"");
+        REQUIRE(dog.size() == 1);
+        CHECK(dog[0].at("X") == "wn:02084071-n");
+        auto hypernyms = interactive.query("X hypernym Y");
+        REQUIRE(hypernyms.size() == 1);
+        CHECK(hypernyms[0].at("X") == "wn:02084071-n");
+        CHECK(hypernyms[0].at("Y") == "wn:02083346-n");
+        CHECK(interactive.query("X part_meronym Y").empty());
+        CHECK(interactive.query("X hyponym Y").empty());
+        CHECK(interactive.query("X gloss \"a member of the genus Canis\"").size() == 1);
+
+        std::istringstream broken("02084071 05 n 02 dog 0\n");
+        CHECK_THROWS_WITH_AS(interactive.import_wordnet(broken), doctest::Contains(":1: expected 2 words"), zelph::console::process_error);
+        CHECK_THROWS_AS(zelph::io::parse_wordnet_options({"relations=hypernyms"}), std::runtime_error); });
+}
+
+TEST_CASE("sync: facts follow the rows of a query result")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A "works at" B) => (B employs A)
+paul "works at" acme
+)");
+
+        const zelph::io::RowTemplate row_template("{name} \"works at\" {company}\n"
+                                                  "{name} ~ employee\n");
+        zelph::io::RowSet            rows{{"name", "company"}, {{"paul", "acme"}, {"anna", "initech"}, {"mary", std::nullopt}}};
+
+        const auto first = interactive.sync_rows("staff", row_template, rows);
+        CHECK(first.rows == 3);
+        CHECK(first.added == 4); // paul's job was stated before
+        CHECK(first.facts == 4);
+        interactive.run(false, false, false);
+        CHECK(interactive.query("initech employs X").size() == 1);
+
+        rows.rows = {{"paul", "acme"}, {"mary", "acme"}};
+        const auto second = interactive.sync_rows("staff", row_template, rows);
+        CHECK(second.added == 1);
+        CHECK(second.retracted == 2);
+        CHECK(second.facts == 3);
+        CHECK(interactive.query("anna \"works at\" X").empty());
+        CHECK(interactive.query("initech employs X").empty());
+
+        CHECK(interactive.drop_sync("staff") == 3);
+        CHECK(interactive.query("paul \"works at\" X").size() == 1);
+        CHECK(interactive.query("X ~ employee").empty());
+
+        CHECK_THROWS_AS(interactive.sync_rows("staff", zelph::io::RowTemplate("{id} is a"), rows), zelph::console::process_error);
+        CHECK_THROWS_AS(zelph::io::RowTemplate("{name} works"), std::runtime_error);
+
+        std::atomic<int> fetched{0};
+        interactive.schedule_sync("feed", row_template, [&]
+                                  { ++fetched; return rows; }, std::chrono::milliseconds(10));
+        for (int i = 0; i < 500 && fetched < 2; ++i)
+            std::this_thread::sleep_for(std::chrono::milliseconds(10));
+        interactive.unschedule_sync("feed");
+        CHECK(fetched >= 2);
+
+        const auto syncs = interactive.syncs();
+        REQUIRE(syncs.size() == 1);
+        CHECK(syncs[0].name == "feed");
+        CHECK(syncs[0].last.facts == 3);
+        CHECK(syncs[0].error.empty());
+        CHECK(syncs[0].interval.count() == 0);
+        CHECK(interactive.query("X ~ employee").size() == 2); });
+}
+
+TEST_CASE("store: facts are queried from disk and imported by pattern")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-store.zfs").string();
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive interactive(collector.sink());
+        interactive.process("paul is_parent_of peter");
+        interactive.process("anna is_parent_of peter");
+        interactive.process("anna is_parent_of mary");
+        interactive.process("peter ~ human");
+        CHECK(interactive.create_store(file) == 4);
+    }
+
+    run_both_modes([&](auto& collector, auto& interactive)
+                   {
+        interactive.process("(A is_parent_of B) => (B is_child_of A)");
+        CHECK_THROWS_AS(interactive.store_match("", "", ""), zelph::console::process_error);
+
+        interactive.open_store(file, 1);
+        CHECK(interactive.store_match("", "", "").size() == 4);
+        CHECK(interactive.store_match("anna", "", "").size() == 2);
+        CHECK(interactive.store_match("", "is_parent_of", "peter").size() == 2);
+        CHECK(interactive.store_match("", "", "peter", 1).size() == 1);
+        CHECK(interactive.store_match("nobody", "", "").empty());
+        CHECK(interactive.query("anna is_parent_of X").empty());
+
+        CHECK(interactive.store_import("anna", "is_parent_of", "") == 2);
+        CHECK(interactive.query("anna is_parent_of X").size() == 2);
+        interactive.run(false, false, false);
+        CHECK(interactive.query("mary is_child_of X").size() == 1);
+
+        interactive.process(".store");
+        CHECK(any_output_contains(collector, "4 facts, 7 names"));
+        interactive.process(".store off");
+        CHECK(any_output_contains(collector, "No fact store."));
+
+        std::istringstream facts("a b c\na b \"d e\"\na b c\n");
+        CHECK(zelph::io::write_fact_store(facts, file) == 2);
+        interactive.open_store(file);
+        CHECK(interactive.store_match("a", "b", "d e").size() == 1);
+        interactive.open_store("");
+        std::filesystem::remove(file); });
+}
+
+#ifndef __EMSCRIPTEN__
+TEST_CASE("journal: accepted input is appended and replayed by another instance")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-journal.zph").string();
+    std::filesystem::remove(file);
+
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive source(collector.sink());
+        source.set_journal(file);
+        CHECK(source.journal() == file);
+        process_lines(source, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        CHECK_THROWS_AS(source.process(".no-such-command"), zelph::console::process_error);
+        source.process(".journal off");
+        source.process("anna is_parent_of tom");
+        CHECK(source.journal().empty());
+    }
+
+    std::string text;
+    {
+        std::ifstream      in(file);
+        std::ostringstream content;
+        content << in.rdbuf();
+        text = content.str();
+    }
+    CHECK(text.find("paul is_parent_of peter\n") != std::string::npos);
+    CHECK(text.find("# deduced: ") != std::string::npos);
+    CHECK(text.find(".no-such-command") == std::string::npos);
+    CHECK(text.find(".journal") == std::string::npos);
+    CHECK(text.find("anna") == std::string::npos);
+
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive target(collector.sink());
+    target.process(".journal " + file);
+    target.process("mia is_parent_of paul");
+    target.set_journal("");
+
+    auto answers = target.query("peter is_child_of X");
+    REQUIRE(answers.size() == 1);
+    CHECK(answers[0].at("X") == "paul");
+
+    // The replayed lines are not written twice.
+    zelph::console::Interactive replay(collector.sink());
+    replay.set_journal(file);
+    replay.set_journal("");
+    std::filesystem::remove(file);
+    CHECK(replay.query("paul is_child_of X").size() == 1);
+    CHECK(replay.facts().size() == target.facts().size());
+}
+#endif
+
+TEST_CASE("owl: ontology axioms are translated into rules")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+dog rdfs:subClassOf animal
+teaches rdfs:domain teacher
+teaches rdfs:range student
+age rdfs:range xsd:integer
+parent_of owl:inverseOf child_of
+knows ~ owl:SymmetricProperty
+ancestor_of ~ owl:TransitiveProperty
+)");
+
+        const auto translation = interactive.owl_rules();
+        CHECK(translation.axioms == 7);
+        CHECK(translation.rules == 7);
+        CHECK(translation.skipped == 1);
+        CHECK(interactive.owl_rules().rules == 0);
+
+        process_lines(interactive, R"(
+rex ~ dog
+anna teaches tom
+paul parent_of peter
+peter knows mia
+a ancestor_of b
+b ancestor_of c
+)");
+        auto answers = interactive.query("X ~ animal");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "rex");
+        CHECK(interactive.query("anna ~ X").size() == 1);
+        CHECK(interactive.query("tom ~ X").size() == 1);
+        answers = interactive.query("peter child_of X");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "paul");
+        CHECK(interactive.query("mia knows X").size() == 1);
+        CHECK(interactive.query("a ancestor_of X").size() == 2); });
+}
+
+TEST_CASE("graph export: the neighborhood of a node as DOT and Mermaid")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+peter is_parent_of mary
+)");
+        interactive.run(false, false, false);
+
+        std::ostringstream dot;
+        interactive.export_dot(dot, "paul", 1);
+        const std::string d = dot.str();
+        CHECK(d.find("digraph \"paul\" {") == 0);
+        CHECK(d.find("[label=\"paul\", penwidth=2];") != std::string::npos);
+        CHECK(d.find("[label=\"is_parent_of\"];") != std::string::npos);
+        CHECK(d.find("[label=\"is_child_of\", style=dashed];") != std::string::npos);
+        CHECK(d.find("mary") == std::string::npos); // two steps away
+        CHECK(d.find("label=\"A\"") == std::string::npos);
+
+        std::ostringstream mermaid;
+        interactive.export_mermaid(mermaid, "paul", 2);
+        const std::string m = mermaid.str();
+        CHECK(m.find("flowchart LR\n") == 0);
+        CHECK(m.find("-->|\"is_parent_of\"|") != std::string::npos);
+        CHECK(m.find("-.->|\"is_child_of\"|") != std::string::npos);
+        CHECK(m.find("[\"mary\"]") != std::string::npos);
+
+        CHECK_THROWS_AS(interactive.export_dot(dot, "nobody"), zelph::console::process_error); });
+}
@@ -0,0 +1,742 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include <doctest/doctest.h> // provides main()
+
+#include "process_error.hpp"
+#include "test_helpers.hpp"
+
+#include <algorithm>
+#include <chrono>
+#include <cmath>
+#include <cstdint>
+#include <set>
+
+using namespace zelph::test;
+
+TEST_CASE("query: bindings are returned instead of printed")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+paul is_parent_of peter
+peter is_parent_of pius
+)");
+        collector.clear();
+        auto answers = interactive.query("X is_parent_of Y");
+        CHECK_FALSE(any_output_starts_with(collector, "Answer:"));
+        REQUIRE(answers.size() == 2);
+
+        bool found = false;
+        for (const auto& a : answers)
+            found = found || (a.at("X") == "paul" && a.at("Y") == "peter");
+        CHECK(found);
+
+        CHECK(interactive.query("nobody is_parent_of X").empty());
+        CHECK_THROWS_AS(interactive.query("(a b c)"), zelph::console::process_error); });
+}
+
+TEST_CASE("query: a statement without variables is rejected instead of stated")
+{
+    run_both_modes([](auto&, auto& interactive)
+                   {
+        interactive.process("paul is_parent_of peter");
+        const auto before = interactive.facts().size();
+
+        try
+        {
+            interactive.query("anna is_parent_of peter");
+            FAIL("a query without variables was answered");
+        }
+        catch (const zelph::console::process_error& e)
+        {
+            CHECK(e.kind() == zelph::console::ProcessErrorKind::Statement);
+            CHECK(e.reason().find("needs a variable") != std::string::npos);
+        }
+        CHECK_THROWS_AS(interactive.query_each("anna is_parent_of peter", [](const auto&)
+                                               { return true; }),
+                        zelph::console::process_error);
+        CHECK_THROWS_AS(interactive.explain_query("anna is_parent_of peter"), zelph::console::process_error);
+
+        CHECK(interactive.facts().size() == before);
+        CHECK(interactive.query("X is_parent_of peter").size() == 1); });
+}
+
+TEST_CASE("match: typed patterns need no quoting")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A "is parent of" B) => (A "is ancestor of" B)
+(A "is parent of" B, B "is ancestor of" C) => (A "is ancestor of" C)
+rupert "is parent of" paul
+paul "is parent of" pius
+)");
+
+        using Term = zelph::console::Interactive::Term;
+
+        auto ancestors = interactive.match({Term::var("A"), Term::constant("is ancestor of"), Term::constant("pius")});
+        REQUIRE(ancestors.size() == 2);
+        std::set<std::string> names;
+        for (const auto& answer : ancestors)
+            names.insert(answer.at("A"));
+        CHECK((names == std::set<std::string>{"paul", "rupert"}));
+
+        auto edges = interactive.match({Term::var(), Term::var("P"), Term::constant("pius")});
+        REQUIRE_FALSE(edges.empty());
+        for (const auto& answer : edges)
+        {
+            CHECK(answer.size() == 1);
+            CHECK(answer.count("P") == 1);
+        }
+
+        CHECK(interactive.match({Term::constant("rupert"), Term::constant("is ancestor of"), Term::constant("pius")}).size() == 1);
+        CHECK(interactive.match({Term::constant("pius"), Term::constant("is ancestor of"), Term::constant("rupert")}).empty());
+
+        const auto facts = interactive.facts().size();
+        CHECK(interactive.match({Term::var("A"), Term::constant("is ancestor of"), Term::constant("nobody")}).empty());
+        CHECK(interactive.facts().size() == facts); });
+}
+
+TEST_CASE("graph_stats: edges, relations, degrees and components")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+paul is_parent_of peter
+anna is_parent_of peter
+peter likes anna
+berlin is_capital_of germany
+)");
+
+        const auto stats = interactive.graph_stats();
+        CHECK(stats.statements == 4);
+        CHECK(stats.edges == 4);
+        CHECK(stats.components == 2);
+        REQUIRE(stats.relations.size() == 3);
+        CHECK(stats.relations[0].name == "is_parent_of");
+        CHECK(stats.relations[0].statements == 2);
+        CHECK(stats.out_degrees.at(0) == 1); // germany
+        CHECK(stats.out_degrees.at(1) == 4);
+        CHECK(stats.in_degrees.at(0) == 2); // paul, berlin
+        CHECK(stats.in_degrees.at(2) == 1); // peter
+
+        interactive.process(".graph-stats 1");
+        CHECK(any_output_contains(collector, "Connected components: 2"));
+        CHECK(any_output_contains(collector, "... 2 more")); });
+}
+
+TEST_CASE("find_paths: shortest connections, k paths, directions and relation filters")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+paul is_parent_of peter
+anna is_parent_of peter
+paul knows anna
+anna lives_in berlin
+)");
+
+        auto paths = interactive.find_paths("paul", "anna", {});
+        REQUIRE(paths.size() == 1);
+        REQUIRE(paths[0].size() == 1);
+        CHECK(paths[0][0].predicate_name == "knows");
+        CHECK(paths[0][0].forward);
+
+        paths = interactive.find_paths("paul", "anna", {3});
+        REQUIRE(paths.size() == 2);
+        REQUIRE(paths[1].size() == 2);
+        CHECK(paths[1][0].to_name == "peter");
+        CHECK_FALSE(paths[1][1].forward);
+
+        zelph::console::Interactive::PathOptions parents_only;
+        parents_only.relations = {"is_parent_of"};
+        paths = interactive.find_paths("paul", "anna", parents_only);
+        REQUIRE(paths.size() == 1);
+        CHECK(paths[0].size() == 2);
+
+        zelph::console::Interactive::PathOptions directed;
+        directed.directed = true;
+        CHECK(interactive.find_paths("paul", "berlin", directed).size() == 1);
+        CHECK(interactive.find_paths("berlin", "paul", directed).empty());
+
+        zelph::console::Interactive::PathOptions shallow;
+        shallow.max_depth = 1;
+        CHECK(interactive.find_paths("peter", "berlin", shallow).empty());
+
+        CHECK_THROWS_AS(interactive.find_paths("paul", "nobody", {}), zelph::console::process_error);
+
+        interactive.process(".paths paul anna 2");
+        CHECK(any_output_contains(collector, "paul --knows--> anna"));
+        CHECK(any_output_contains(collector, "paul --is_parent_of--> peter <--is_parent_of-- anna")); });
+}
+
+TEST_CASE("facts: all statements are enumerated, deduced ones marked")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+anna likes tea
+)");
+        interactive.run(false, false, false);
+
+        const auto facts = interactive.facts();
+        REQUIRE(facts.size() == 3);
+
+        auto find = [&](const std::string& predicate)
+        {
+            return std::find_if(facts.begin(), facts.end(), [&](const auto& f)
+                                { return f.predicate == predicate; });
+        };
+
+        auto parent = find("is_parent_of");
+        REQUIRE(parent != facts.end());
+        CHECK(parent->subject == "paul");
+        CHECK((parent->objects == std::vector<std::string>{"peter"}));
+        CHECK_FALSE(parent->deduced);
+
+        auto child = find("is_child_of");
+        REQUIRE(child != facts.end());
+        CHECK(child->subject == "peter");
+        CHECK(child->deduced);
+        CHECK(find("likes") != facts.end());
+        // Rules are not statements.
+        CHECK(find("=>") == facts.end()); });
+}
+
+TEST_CASE("explain: proof tree of a deduced fact down to stated premises")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+(*{ (A is_child_of B) (B is_child_of C) } ~ conjunction) => (A is_grandchild_of C)
+paul is_parent_of peter
+paul is_child_of otto
+)");
+        interactive.run(false, false, false);
+
+        uint64_t grandchild = 0;
+        uint64_t stated     = 0;
+        for (const auto& f : interactive.facts())
+        {
+            if (f.predicate == "is_grandchild_of") grandchild = f.id;
+            if (f.predicate == "is_parent_of") stated = f.id;
+        }
+        REQUIRE(grandchild != 0);
+        REQUIRE(stated != 0);
+
+        const auto proof = interactive.explain(grandchild);
+        CHECK(proof.fact == grandchild);
+        CHECK(proof.rule != 0);
+        REQUIRE(proof.premises.size() == 2);
+
+        auto child = std::find_if(proof.premises.begin(), proof.premises.end(), [](const auto& p)
+                                  { return p.rule != 0; });
+        REQUIRE(child != proof.premises.end());
+        CHECK(child->fact_text.find("peter") != std::string::npos);
+        REQUIRE(child->premises.size() == 1);
+        CHECK(child->premises[0].fact == stated);
+        CHECK(child->premises[0].rule == 0);
+
+        auto other = std::find_if(proof.premises.begin(), proof.premises.end(), [](const auto& p)
+                                  { return p.rule == 0; });
+        REQUIRE(other != proof.premises.end());
+        CHECK(other->fact_text.find("otto") != std::string::npos);
+        CHECK(other->premises.empty());
+
+        CHECK_THROWS_AS(interactive.explain(stated), zelph::console::process_error);
+
+        collector.clear();
+        interactive.process(".explain " + std::to_string(grandchild));
+        CHECK(any_output_contains(collector, "(stated)")); });
+}
+
+TEST_CASE("views: answers follow stated and deduced facts")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+paul parent peter
+peter parent pius
+)");
+        interactive.create_view("grandparents", "X parent Y, Y parent Z");
+        auto answers = interactive.view("grandparents");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "paul");
+        CHECK(answers[0].at("Z") == "pius");
+
+        process_lines(interactive, R"(
+pius parent anna
+(X mother Y) => (X parent Y)
+anna mother ben
+)");
+        interactive.run(false, false, false);
+        CHECK(interactive.view("grandparents").size() == 3);
+        CHECK(interactive.views() == std::vector<std::string>{"grandparents"});
+
+        CHECK(interactive.remove_view("grandparents"));
+        CHECK_THROWS_AS(interactive.view("grandparents"), zelph::console::process_error); });
+}
+
+TEST_CASE("query cache: repeated queries follow changes of their relations")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+paul parent peter
+peter parent pius
+)");
+        interactive.set_query_cache(true);
+        REQUIRE(interactive.query_cache());
+        CHECK(interactive.query("X parent Y").size() == 2);
+        CHECK(interactive.query("X parent Y").size() == 2);
+
+        process_lines(interactive, "pius likes anna");
+        CHECK(interactive.query("X parent Y").size() == 2);
+
+        process_lines(interactive, R"(
+(X mother Y) => (X parent Y)
+pius mother anna
+)");
+        interactive.run(false, false, false);
+        auto answers = interactive.query("X parent anna");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "pius");
+        CHECK(interactive.query("X parent Y").size() == 3);
+
+        // Answers cached in one mode are not handed out in the other.
+        collector.clear();
+        interactive.process(".query-cache");
+        CHECK_FALSE(any_output_contains(collector, " 0 entries"));
+        interactive.set_deterministic(true);
+        collector.clear();
+        interactive.process(".query-cache");
+        CHECK(any_output_contains(collector, " 0 entries"));
+
+        interactive.set_query_cache(false);
+        CHECK(interactive.query("X parent Y").size() == 3); });
+}
+
+TEST_CASE("query streaming: answers are handed over one by one and paginated")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+a likes b
+b likes c
+c likes d
+d likes e
+e likes f
+)");
+        std::vector<std::string> all;
+        CHECK(interactive.query_each("X likes Y", [&](const auto& answer)
+                                     {
+            all.push_back(answer.at("X"));
+            return true; })
+              == 5);
+        REQUIRE(all.size() == 5);
+
+        std::vector<std::string> page;
+        CHECK(interactive.query_each("X likes Y", [&](const auto& answer)
+                                     {
+            page.push_back(answer.at("X"));
+            return true; },
+                                     2,
+                                     2)
+              == 2);
+        CHECK(page == std::vector<std::string>{all[2], all[3]});
+
+        size_t seen = 0;
+        CHECK(interactive.query_each("X likes Y", [&](const auto&)
+                                     { return ++seen < 3; })
+              == 3);
+        CHECK(seen == 3);
+        CHECK(interactive.query("X likes Y").size() == 5); });
+}
+
+TEST_CASE("query limits: a bounded query returns partial answers marked truncated")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+a likes b
+b likes c
+c likes d
+d likes e
+e likes f
+)");
+        auto bounded = interactive.query_bounded("X likes Y", {std::chrono::milliseconds(0), 2});
+        CHECK(bounded.truncated);
+        CHECK(bounded.answers.size() == 2);
+        CHECK(bounded.reason.find("matches") != std::string::npos);
+
+        auto complete = interactive.query_bounded("X likes Y", {std::chrono::milliseconds(60000), 100});
+        CHECK_FALSE(complete.truncated);
+        CHECK(complete.answers.size() == 5);
+
+        CHECK(interactive.query("X likes Y").size() == 5);
+
+        process_lines(interactive, ".query-limits 0 3");
+        process_lines(interactive, "X likes Y");
+        const auto& events = collector.events();
+        CHECK(std::any_of(events.begin(), events.end(), [](const zelph::io::OutputEvent& e)
+                          { return e.text.find("Query stopped") != std::string::npos; })); });
+}
+
+TEST_CASE("deterministic mode: identical input gives the same answers in the same order")
+{
+    auto answers = [](const std::string& extra)
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive interactive(collector.sink());
+        interactive.set_deterministic(true);
+        REQUIRE(interactive.deterministic());
+        process_lines(interactive, extra + R"(
+(X parent Y, Y parent Z) => (X grandparent Z)
+a parent b
+b parent c
+c parent d
+d parent e
+)");
+        interactive.run(false, false, false);
+        return interactive.query("X grandparent Y");
+    };
+
+    const auto first = answers("");
+    CHECK(first.size() == 3);
+    CHECK(answers("") == first);
+}
+
+TEST_CASE("plan: join order, lookups and candidate counts of queries and rules")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        for (int i = 0; i < 300; ++i)
+            interactive.process("hub likes x" + std::to_string(i));
+        interactive.process("alice likes bob");
+
+        // The lookup starts from bob, not from the 301 facts of likes.
+        auto plan = interactive.explain_query("X likes bob");
+        REQUIRE(plan.size() == 1);
+        CHECK(plan[0].access == "osp");
+        CHECK(plan[0].candidates < 10);
+        CHECK_FALSE(plan[0].per_binding);
+        CHECK(plan[0].relation == "likes");
+        CHECK(plan[0].binds.size() == 1);
+
+        interactive.set_triple_indexes(3);
+        CHECK(interactive.explain_query("X likes bob")[0].access == "pos");
+        interactive.set_triple_indexes(7);
+
+        // The second condition is looked up per binding of the first.
+        const uint64_t rule = interactive.add_rule("(X likes Y, Y likes Z)", "(X likes2 Z)");
+        plan                = interactive.explain_rule(rule);
+        REQUIRE(plan.size() == 2);
+        CHECK(plan[0].access == "pos");
+        CHECK(plan[0].binds.size() == 2);
+        CHECK(plan[1].per_binding);
+        CHECK(plan[1].candidates >= 1);
+        CHECK(plan[1].binds.size() == 1);
+
+        CHECK_THROWS_AS(interactive.explain_rule(1), zelph::console::process_error);
+
+        interactive.process(".plan " + std::to_string(rule));
+        CHECK(any_output_contains(collector, "candidates per binding")); });
+}
+
+TEST_CASE("search: concept names by exact, prefix, substring and fuzzy match")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.process("Einstein ~ physicist");
+        interactive.process("\"Albert Einstein\" ~ physicist");
+        interactive.process("\"Einstein ring\" ~ phenomenon");
+        interactive.process("einsteinium ~ element");
+        interactive.process("Berlin ~ city");
+
+        auto matches = interactive.search_concepts("einstein");
+        REQUIRE(matches.size() == 4);
+        CHECK(matches[0].name == "Einstein");
+        CHECK(matches[0].kind == "exact");
+        CHECK(matches[1].name == "einsteinium");
+        CHECK(matches[1].kind == "prefix");
+        CHECK(matches[2].name == "Einstein ring");
+        CHECK(matches[3].name == "Albert Einstein");
+        CHECK(matches[3].kind == "substring");
+        CHECK(interactive.search_concepts("einstein", 2).size() == 2);
+
+        CHECK(interactive.search_concepts("einstien").empty());
+        matches = interactive.search_concepts("einstien", 10, true);
+        REQUIRE(matches.size() == 1);
+        CHECK(matches[0].name == "Einstein");
+        CHECK(matches[0].kind == "fuzzy");
+        CHECK(matches[0].distance == 2);
+
+        // Names added after the first search are found.
+        interactive.process("Einsteinhaus ~ building");
+        matches = interactive.search_concepts("einsteinh");
+        REQUIRE(matches.size() == 1);
+        CHECK(matches[0].id == interactive.resolve_name("Einsteinhaus"));
+
+        interactive.process(".search einstien 5 fuzzy");
+        CHECK(any_output_contains(collector, "Einstein [")); });
+}
+
+TEST_CASE("suggest: queries mentioning an unknown concept name near misses")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.process("pius ~ pope");
+
+        interactive.process("piuss ~ X");
+        CHECK_FALSE(any_event_contains(collector, "Unknown concept"));
+        CHECK(interactive.query("piuss ~ X").empty());
+
+        interactive.set_suggest_concepts(true);
+        interactive.process("piuss ~ X");
+        CHECK(any_event_contains(collector, "Unknown concept 'piuss' – did you mean pius?"));
+        CHECK_THROWS_WITH_AS(interactive.query("piuss ~ X"), doctest::Contains("did you mean pius?"), zelph::console::process_error);
+
+        // Known concepts without answers are no error.
+        CHECK(interactive.query("pope ~ X").empty());
+        CHECK(interactive.query("X ~ pius").empty()); });
+}
+
+TEST_CASE("embeddings: concepts ranked by cosine similarity")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.process("cat ~ animal");
+        interactive.process("dog ~ animal");
+        interactive.process("car ~ vehicle");
+        const uint64_t cat = *interactive.resolve_name("cat");
+        const uint64_t dog = *interactive.resolve_name("dog");
+        const uint64_t car = *interactive.resolve_name("car");
+
+        interactive.set_embedding(cat, {1.0f, 0.9f, 0.0f});
+        interactive.set_embedding(dog, {0.9f, 1.0f, 0.1f});
+        interactive.set_embedding(car, {0.0f, 0.1f, 1.0f});
+        CHECK(interactive.embedding(dog).size() == 3);
+        CHECK_THROWS_AS(interactive.set_embedding(car, {1.0f, 2.0f}), zelph::console::process_error);
+
+        auto similar = interactive.similar_concepts(cat, 5);
+        REQUIRE(similar.size() == 2);
+        CHECK(similar[0].id == dog);
+        CHECK(similar[0].name == "dog");
+        CHECK(similar[0].similarity > 0.9);
+        CHECK(similar[1].id == car);
+        CHECK(similar[1].similarity < 0.2);
+
+        similar = interactive.similar_concepts(std::vector<float>{0.0f, 0.0f, 2.0f}, 1);
+        REQUIRE(similar.size() == 1);
+        CHECK(similar[0].id == car);
+        CHECK(similar[0].similarity == doctest::Approx(1.0 / std::sqrt(1.01)));
+
+        CHECK(interactive.remove_embedding(car));
+        CHECK(interactive.similar_concepts(cat).size() == 1);
+        CHECK_THROWS_AS(interactive.similar_concepts(car), zelph::console::process_error);
+
+        interactive.process(".similar cat");
+        CHECK(any_output_contains(collector, "dog [")); });
+}
+
+TEST_CASE("ask: translated questions answered with proofs of the matched facts")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        CHECK_THROWS_AS(interactive.ask("Whose child is peter?"), zelph::console::process_error);
+
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        interactive.run(false, false, false);
+
+        std::string asked;
+        interactive.set_translator([&](const std::string& question)
+                                   {
+            asked = question;
+            return std::vector<std::string>{"peter is_child_of X", "(a b c)"}; });
+
+        const auto answer = interactive.ask("Whose child is peter?");
+        CHECK(asked == "Whose child is peter?");
+        REQUIRE(answer.queries.size() == 2);
+
+        const auto& query = answer.queries[0];
+        CHECK(query.statement == "peter is_child_of X");
+        CHECK(query.error.empty());
+        REQUIRE(query.answers.size() == 1);
+        CHECK(query.answers[0].at("X") == "paul");
+        REQUIRE(query.proofs.size() == 1);
+        REQUIRE(query.proofs[0].size() == 1);
+        CHECK(query.proofs[0][0].rule != 0);
+        REQUIRE(query.proofs[0][0].premises.size() == 1);
+        CHECK(query.proofs[0][0].premises[0].fact_text.find("is_parent_of") != std::string::npos);
+        CHECK(query.proofs[0][0].premises[0].rule == 0);
+
+        CHECK_FALSE(answer.queries[1].error.empty());
+        CHECK(answer.queries[1].answers.empty());
+
+        interactive.set_translator([](const std::string&) -> std::vector<std::string>
+                                   { throw std::runtime_error("model unavailable"); });
+        CHECK_THROWS_WITH_AS(interactive.ask("Whose child is peter?"), doctest::Contains("model unavailable"), zelph::console::process_error); });
+}
+
+TEST_CASE("builtins: conditions answered by embedder functions")
+{
+    using Solutions = std::vector<zelph::console::Interactive::BuiltinSolution>;
+
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.register_builtin("starts_with", [](const auto& args)
+                                     {
+            Solutions solutions;
+            if (args.size() == 2 && args[0].node && args[1].node && args[0].value.rfind(args[1].value, 0) == 0)
+                solutions.emplace_back();
+            return solutions; });
+        interactive.register_builtin("has_length", [](const auto& args)
+                                     {
+            Solutions solutions;
+            if (args.size() == 2 && args[0].node && !args[1].variable.empty())
+                solutions.push_back({{args[1].variable, std::to_string(args[0].value.size())}});
+            return solutions; });
+        interactive.register_builtin("explodes", [](const auto&) -> Solutions
+                                     { throw std::runtime_error("boom"); });
+
+        process_lines(interactive, R"(
+alice ~ person
+albert ~ person
+bob ~ person
+(*{ (X ~ person) (X starts_with al) } ~ conjunction) => (X ~ al_person)
+)");
+        interactive.run(false, false, false);
+
+        auto answers = interactive.query("X ~ al_person");
+        REQUIRE(answers.size() == 2);
+        const std::set<std::string> names{answers[0].at("X"), answers[1].at("X")};
+        const std::set<std::string> expected{"albert", "alice"};
+        CHECK(names == expected);
+
+        answers = interactive.query("alice has_length N");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("N") == "5");
+
+        CHECK_THROWS_WITH_AS(interactive.query("alice explodes X"), doctest::Contains("boom"), zelph::console::process_error);
+        CHECK(interactive.query("alice has_length N").size() == 1); });
+}
+
+TEST_CASE("aggregate: counts, extremes and sums of query answers")
+{
+    using Aggregate = zelph::console::Interactive::Aggregate;
+
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+paul parent_of anna
+paul parent_of ben
+anna parent_of carl
+(X parent_of Y) => (X ancestor_of Y)
+(*{ (X ancestor_of Y) (Y ancestor_of Z) } ~ conjunction) => (X ancestor_of Z)
+anna age 30
+ben age 25
+carl age 5
+)");
+        interactive.run(false, false, false);
+
+        auto rows = interactive.aggregate("paul ancestor_of X", {}, {Aggregate::count()});
+        REQUIRE(rows.size() == 1);
+        CHECK((rows[0].values == std::vector<std::string>{"3"}));
+
+        rows = interactive.aggregate("A ancestor_of X", {"A"}, {Aggregate::count(), Aggregate::count("X")});
+        REQUIRE(rows.size() == 2);
+        CHECK(rows[0].group.at("A") == "anna");
+        CHECK((rows[0].values == std::vector<std::string>{"1", "1"}));
+        CHECK(rows[1].group.at("A") == "paul");
+        CHECK((rows[1].values == std::vector<std::string>{"3", "3"}));
+
+        // Numbers compare numerically: 5 is the minimum, not 25.
+        rows = interactive.aggregate("X age N", {}, {Aggregate::min("N"), Aggregate::max("N"), Aggregate::sum("N")});
+        REQUIRE(rows.size() == 1);
+        CHECK((rows[0].values == std::vector<std::string>{"5", "30", "60"}));
+
+        rows = interactive.aggregate("X age nobody", {}, {Aggregate::count(), Aggregate::min("X"), Aggregate::sum("X")});
+        REQUIRE(rows.size() == 1);
+        CHECK((rows[0].values == std::vector<std::string>{"0", "", "0"}));
+
+        CHECK_THROWS_WITH_AS(interactive.aggregate("X age N", {}, {Aggregate::sum("X")}), doctest::Contains("not a number"), zelph::console::process_error);
+        CHECK_THROWS_WITH_AS(interactive.aggregate("X age N", {"Y"}, {Aggregate::count()}), doctest::Contains("do not bind Y"), zelph::console::process_error); });
+}
+
+TEST_CASE("dry run: the facts a run would deduce are returned and not kept")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        size_t told = 0;
+        interactive.on_deduction([&](const zelph::console::Interactive::Deduction&)
+                                 { ++told; });
+
+        process_lines(interactive, R"(
+.auto-run
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        const size_t statements = interactive.facts().size();
+
+        const auto preview = interactive.run_dry();
+        REQUIRE(preview.size() == 1);
+        CHECK(preview[0].subject == "peter");
+        CHECK(preview[0].predicate == "is_child_of");
+        CHECK(preview[0].objects == std::vector<std::string>{"paul"});
+        CHECK(preview[0].deduced);
+        CHECK(told == 0);
+        CHECK(interactive.facts().size() == statements);
+        CHECK(interactive.query("peter is_child_of X").empty());
+        CHECK_FALSE(interactive.in_transaction());
+
+        collector.clear();
+        interactive.process(".run-dry");
+        CHECK(any_output_contains(collector, "⇐"));
+        CHECK(any_output_starts_with(collector, "Dry run: would deduce 1 fact(s)"));
+        CHECK(interactive.query("peter is_child_of X").empty());
+
+        interactive.begin();
+        CHECK_THROWS_AS(interactive.run_dry(), zelph::console::process_error);
+        interactive.rollback();
+
+        interactive.run(false, false, false);
+        CHECK(told == 1);
+        CHECK(interactive.query("peter is_child_of X").size() == 1);
+        CHECK(interactive.run_dry().empty()); });
+}
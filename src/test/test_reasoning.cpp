@@ -25,8 +25,15 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #include <doctest/doctest.h> // provides main()
 
+#include "process_error.hpp"
 #include "test_helpers.hpp"
 
+#include <algorithm>
+#include <cstdint>
+#include <filesystem>
+#include <set>
+#include <sstream>
+
 using namespace zelph::test;
 
 TEST_CASE("import: missing scripts fail with a standard-library hint, wrong extensions are rejected")
@@ -162,6 +169,1081 @@ tim _predicate _object
         CHECK(answers_contain(collector, "tim ~ male")); });
 }
 
+TEST_CASE("schema: statements breaking a domain or range constraint are reported")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.constrain("is_capital_of", "city", "country");
+        const auto rule_count = interactive.rules().size();
+        CHECK(rule_count == 2);
+        interactive.constrain("is_capital_of", "city", "country");
+        CHECK(interactive.rules().size() == rule_count);
+
+        process_lines(interactive, R"(
+berlin ~ city
+germany ~ country
+berlin is_capital_of germany
+germany is_capital_of berlin
+)");
+        interactive.run(false, false, false);
+
+        auto violations = interactive.schema_violations();
+        REQUIRE(violations.size() == 2);
+        for (const auto& violation : violations)
+        {
+            CHECK(violation.relation == "is_capital_of");
+            CHECK(violation.fact_text.find("germany is_capital_of berlin") != std::string::npos);
+            CHECK(violation.expected == (violation.subject ? "city" : "country"));
+            CHECK(violation.node == (violation.subject ? "germany" : "berlin"));
+        }
+        CHECK(violations[0].subject != violations[1].subject);
+
+        interactive.retract(violations[0].fact);
+        CHECK(interactive.schema_violations().empty()); });
+}
+
+TEST_CASE("stdlib: the built-in base ontology deduces is-a, part-of, opposite and temporal facts")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.load_stdlib();
+        const auto rule_count = interactive.rules().size();
+        CHECK(rule_count > 0);
+        CHECK_FALSE(any_output_contains(collector, "rror"));
+        for (const auto& fact : interactive.facts())
+            CHECK(std::find(fact.objects.begin(), fact.objects.end(), "and") == fact.objects.end());
+        interactive.load_stdlib();
+        CHECK(interactive.rules().size() == rule_count);
+
+        // The base relations are declared, and so closed natively.
+        collector.clear();
+        interactive.process(".relation");
+        CHECK(any_output_contains(collector, "has part: transitive"));
+        CHECK(any_output_contains(collector, "is part of: inverse of has part"));
+        CHECK(any_output_contains(collector, "is opposite of: symmetric"));
+
+        process_lines(interactive, R"(
+paul ~ human
+human ~ mammal
+finger "is part of" hand
+hand "is part of" arm
+hot "is opposite of" cold
+breakfast "is before" lunch
+lunch "is before" dinner
+)");
+        interactive.run(false, false, false);
+
+        CHECK(interactive.query("paul ~ X").size() == 2);
+        CHECK(interactive.query("arm \"has part\" X").size() == 2);
+        auto opposite = interactive.query("cold \"is opposite of\" X");
+        REQUIRE(opposite.size() == 1);
+        CHECK(opposite[0].at("X") == "hot");
+        CHECK(interactive.query("dinner \"is after\" X").size() == 2);
+        CHECK(interactive.conflicts().empty());
+
+        process_lines(interactive, "dinner \"is before\" breakfast");
+        interactive.run(false, false, false);
+        CHECK_FALSE(interactive.conflicts().empty()); });
+}
+
+TEST_CASE("templates: a rule skeleton is instantiated for many relations")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        // Properties of relations are declared with .relation, not with
+        // predefined templates.
+        CHECK_THROWS_AS(interactive.apply_template("symmetric", {"is next to"}), zelph::console::process_error);
+
+        interactive.process(".template transitive R : (X R Y, Y R Z) => (X R Z)");
+        interactive.process(".apply transitive \"is ancestor of\" \"is part of\"");
+        CHECK(interactive.rules().size() == 2);
+        interactive.apply_template("transitive", {"is ancestor of"});
+        CHECK(interactive.rules().size() == 2);
+
+        interactive.process(".template exclusive R S : (A R B, A S B) => !");
+        interactive.define_template("opposite", {"R", "S"}, "(X R Y) => (Y S X)");
+        interactive.apply_template("opposite", {"is parent of", "is child of", "is child of", "is parent of"});
+        CHECK(interactive.rules().size() == 4);
+
+        process_lines(interactive, R"(
+peter "is ancestor of" paul
+paul "is ancestor of" pius
+finger "is part of" hand
+hand "is part of" arm
+anna "is parent of" tim
+)");
+        interactive.run(false, false, false);
+        CHECK(interactive.query("peter \"is ancestor of\" X").size() == 2);
+        CHECK(interactive.query("finger \"is part of\" X").size() == 2);
+        auto parent = interactive.query("tim \"is child of\" X");
+        REQUIRE(parent.size() == 1);
+        CHECK(parent[0].at("X") == "anna");
+
+        CHECK_THROWS_AS(interactive.apply_template("opposite", {"is parent of"}), zelph::console::process_error);
+        CHECK_THROWS_AS(interactive.apply_template("unknown", {"is parent of"}), zelph::console::process_error);
+        CHECK_THROWS_AS(interactive.define_template("broken", {"Q"}, "(X R Y) => (Y R X)"), zelph::console::process_error); });
+}
+
+TEST_CASE("relation properties: declared relations are closed natively and explained by their rules")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.process(".relation \"is part of\" transitive");
+        interactive.declare_relation("is sibling of", "symmetric");
+        interactive.declare_relation("equals", "transitive");
+        interactive.declare_relation("equals", "symmetric");
+        interactive.declare_relation("parent", "inverse", "child");
+        interactive.declare_relation("child", "inverse", "parent");
+        CHECK(interactive.rules().size() == 6);
+
+        process_lines(interactive, R"(
+finger "is part of" hand
+hand "is part of" arm
+arm "is part of" body
+anna "is sibling of" tim
+a equals b
+c equals b
+c equals d
+anna parent tim
+)");
+        interactive.run(false, false, false);
+        CHECK(interactive.query("finger \"is part of\" X").size() == 3);
+        CHECK(interactive.query("a equals X").size() == 4);
+        CHECK(interactive.query("X equals d").size() == 4);
+        auto sibling = interactive.query("tim \"is sibling of\" X");
+        REQUIRE(sibling.size() == 1);
+        CHECK(sibling[0].at("X") == "anna");
+        auto parent = interactive.query("tim child X");
+        REQUIRE(parent.size() == 1);
+        CHECK(parent[0].at("X") == "anna");
+
+        uint64_t finger_body = 0;
+        for (const auto& f : interactive.facts())
+            if (f.subject == "finger" && f.objects == std::vector<std::string>{"body"}) finger_body = f.id;
+        REQUIRE(finger_body != 0);
+        const auto proof = interactive.explain(finger_body);
+        CHECK(proof.rule != 0);
+        CHECK(proof.premises.size() == 2);
+
+        CHECK_THROWS_AS(interactive.declare_relation("parent", "inverse"), zelph::console::process_error);
+        CHECK_THROWS_AS(interactive.declare_relation("parent", "opposite"), zelph::console::process_error); });
+}
+
+TEST_CASE("relation properties: edges deduced by other rules extend the closure")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.declare_relation("is part of", "transitive");
+        interactive.declare_relation("is next to", "symmetric");
+        process_lines(interactive, R"(
+(X "is attached to" Y) => (X "is part of" Y)
+(X "is part of" Y) => (X "is next to" Y)
+finger "is part of" hand
+arm "is part of" body
+hand "is attached to" arm
+)");
+        interactive.run(false, false, false);
+
+        // hand "is part of" arm comes from a stage after the first closure, which
+        // then has to reach finger through it.
+        CHECK(interactive.query("finger \"is part of\" X").size() == 3);
+        CHECK(interactive.query("hand \"is part of\" X").size() == 2);
+        CHECK(interactive.query("body \"is next to\" X").size() == 3);
+        CHECK(interactive.conflicts().empty()); });
+}
+
+TEST_CASE("same-as: merged concepts take part in the same inferences")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(X "is located in" Y, Y "is located in" Z) => (X "is located in" Z)
+NYC "is located in" "New York State"
+"New York State" "is located in" USA
+brooklyn "is located in" "New York City"
+"New York City" "has mayor" adams
+)");
+        CHECK(interactive.merge_concepts("NYC", "New York City") >= 1);
+        interactive.run(false, false, false);
+
+        CHECK(interactive.query("brooklyn \"is located in\" X").size() == 3);
+        auto mayor = interactive.query("NYC \"has mayor\" X");
+        REQUIRE(mayor.size() == 1);
+        CHECK(mayor[0].at("X") == "adams");
+        auto city = interactive.query("X \"has mayor\" adams");
+        REQUIRE(city.size() == 1);
+        CHECK(city[0].at("X") == "New York City");
+
+        CHECK_THROWS_AS(interactive.merge_concepts("LA", "New York City"), zelph::console::process_error); });
+}
+
+TEST_CASE("is-a hierarchy: kinds and instances follow new statements without rules")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+bird ~ animal
+tweety ~ bird
+)");
+        CHECK(interactive.is_kind_of("tweety", "animal"));
+        CHECK_FALSE(interactive.is_kind_of("animal", "tweety"));
+
+        process_lines(interactive, R"(
+animal ~ organism
+penguin ~ bird
+pingu ~ penguin
+)");
+        CHECK(interactive.is_kind_of("pingu", "organism"));
+        CHECK(interactive.instances_of("animal") == std::vector<std::string>{"bird", "penguin", "pingu", "tweety"});
+
+        process_lines(interactive, ".remove penguin");
+        CHECK(interactive.instances_of("animal") == std::vector<std::string>{"bird", "tweety"});
+        CHECK_THROWS_AS(interactive.is_kind_of("tweety", "plant"), zelph::console::process_error); });
+}
+
+TEST_CASE("revise: only the deductions depending on a revised fact are withdrawn")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(X is_a planet) => (X clears its_orbit)
+(X is_a planet) => (X orbits sun)
+(X is_a dwarf_planet) => (X orbits sun)
+pluto is_a planet
+earth is_a planet
+)");
+        interactive.run(false, false, false);
+
+        auto id_of = [&](const std::string& subject, const std::string& predicate)
+        {
+            for (const auto& f : interactive.facts())
+                if (f.subject == subject && f.predicate == predicate) return f.id;
+            return uint64_t{0};
+        };
+
+        const uint64_t pluto = id_of("pluto", "is_a");
+        REQUIRE(pluto != 0);
+        CHECK_THROWS_AS(interactive.revise(id_of("pluto", "clears")), zelph::console::process_error);
+
+        const auto withdrawn = interactive.revise(pluto, "pluto is_a dwarf_planet");
+        REQUIRE(withdrawn.size() == 1);
+        CHECK(withdrawn[0].subject == "pluto");
+        CHECK(withdrawn[0].predicate == "clears");
+        CHECK(withdrawn[0].deduced);
+
+        CHECK(interactive.query("pluto clears X").empty());
+        CHECK(interactive.query("pluto orbits X").size() == 1);
+        CHECK(interactive.query("earth clears X").size() == 1);
+        const auto kinds = interactive.query("pluto is_a X");
+        REQUIRE(kinds.size() == 1);
+        CHECK(kinds[0].at("X") == "dwarf_planet"); });
+}
+
+TEST_CASE("default rules: a contradiction defeats the assumption instead of being reported")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        const uint64_t              flies = interactive.add_rule("(X is_a bird)", "(X can fly)");
+        interactive.set_rule_default(flies, true);
+        process_lines(interactive, R"(
+(X is_a penguin) => (X is_a bird)
+(X can fly) => (X reaches nests)
+(X is_a penguin, X can fly) => !
+tweety is_a bird
+pingu is_a penguin
+)");
+        interactive.run(false, false, false);
+
+        const auto flyers = interactive.query("X can fly");
+        REQUIRE(flyers.size() == 1);
+        CHECK(flyers[0].at("X") == "tweety");
+        CHECK(interactive.query("pingu reaches X").empty());
+        CHECK(interactive.conflicts().empty());
+
+        const auto defeats = interactive.defeats();
+        REQUIRE(defeats.size() == 1);
+        CHECK(defeats[0].assumption.subject == "pingu");
+        CHECK(defeats[0].rule == flies);
+
+        // As a strict rule, the same knowledge is contradictory.
+        interactive.set_rule_default(flies, false);
+        interactive.run(false, false, false);
+        CHECK(interactive.defeats().empty());
+        CHECK_FALSE(interactive.conflicts().empty()); });
+}
+
+TEST_CASE("default rules: rolling back the fact behind a defeat lifts it")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        const uint64_t              flies = interactive.add_rule("(X is_a bird)", "(X can fly)");
+        interactive.set_rule_default(flies, true);
+        process_lines(interactive, R"(
+(X is_a penguin, X can fly) => !
+pingu is_a bird
+)");
+        interactive.run(false, false, false);
+        CHECK(interactive.query("pingu can X").size() == 1);
+
+        interactive.begin();
+        interactive.process("pingu is_a penguin");
+        interactive.run(false, false, false);
+        CHECK(interactive.query("pingu can X").empty());
+        CHECK(interactive.defeats().size() == 1);
+
+        interactive.rollback();
+        CHECK(interactive.defeats().empty());
+        interactive.run(false, false, false);
+        CHECK(interactive.query("pingu can X").size() == 1); });
+}
+
+TEST_CASE("cardinality: subjects exceeding a relation's limit are reported")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+birth_mother_of_child ~ functional
+anna birth_mother_of_child tim
+paul birth_mother_of_child tim
+paul birth_mother_of_child mia
+anna parent_of tim
+anna parent_of mia
+anna parent_of lea
+)");
+        interactive.limit_cardinality("parent_of", 3);
+
+        auto violations = interactive.validate();
+        REQUIRE(violations.size() == 1);
+        CHECK(violations[0].relation == "birth_mother_of_child");
+        CHECK(violations[0].subject_text == "paul");
+        CHECK(violations[0].limit == 1);
+        CHECK(violations[0].count == 2);
+        CHECK(violations[0].facts.size() == 2);
+
+        interactive.limit_cardinality("parent_of", 2);
+        violations = interactive.validate();
+        REQUIRE(violations.size() == 2);
+        CHECK(violations[1].subject_text == "anna");
+        CHECK(violations[1].limit == 2);
+        CHECK(violations[1].count == 3);
+
+        interactive.retract(violations[0].facts[0]);
+        violations = interactive.validate();
+        REQUIRE(violations.size() == 1);
+        CHECK(violations[0].relation == "parent_of"); });
+}
+
+TEST_CASE("shacl: focus nodes are validated against declared shapes")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+capital_shape ~ sh:NodeShape
+capital_shape sh:targetSubjectsOf is_capital_of
+capital_shape sh:property capital_country
+capital_country sh:path is_capital_of
+capital_country sh:class country
+capital_country sh:maxCount 1
+germany ~ country
+europe ~ continent
+berlin is_capital_of germany
+berlin is_capital_of europe
+city rdfs:subClassOf place
+paul ~ person
+anna ~ person
+paul lives_in berlin
+berlin ~ city
+anna lives_in europe
+)");
+
+        auto report = interactive.validate_shapes();
+        CHECK(report.shapes == 1);
+        CHECK(report.focus_nodes == 1);
+        REQUIRE(report.results.size() == 2);
+        CHECK_FALSE(report.conforms);
+        for (const auto& result : report.results)
+        {
+            CHECK(result.focus_text == "berlin");
+            CHECK(result.path == "is_capital_of");
+        }
+        CHECK(std::any_of(report.results.begin(), report.results.end(), [](const auto& r)
+                          { return r.component == "sh:MaxCountConstraintComponent" && r.value == 0; }));
+        CHECK(std::any_of(report.results.begin(), report.results.end(), [](const auto& r)
+                          { return r.component == "sh:ClassConstraintComponent" && r.value_text == "europe"; }));
+
+        interactive.declare_shape("person_shape", "person", {{"lives_in", "place", 1, std::nullopt}, {"name", "", 1, 1}});
+        report = interactive.validate_shapes();
+        CHECK(report.shapes == 2);
+        CHECK(report.focus_nodes == 3);
+        auto of = [&](const std::string& focus, const std::string& component)
+        {
+            return std::count_if(report.results.begin(), report.results.end(), [&](const auto& r)
+                                 { return r.focus_text == focus && r.component == component; });
+        };
+        CHECK(of("paul", "sh:ClassConstraintComponent") == 0);
+        CHECK(of("anna", "sh:ClassConstraintComponent") == 1);
+        CHECK(of("paul", "sh:MinCountConstraintComponent") == 1);
+        CHECK(of("anna", "sh:MinCountConstraintComponent") == 1); });
+}
+
+TEST_CASE("strata: lower strata reach their fixpoint before higher ones fire")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::vector<std::string> seen;
+        interactive.on_deduction([&](const zelph::console::Interactive::Deduction& d)
+                                 { seen.push_back(d.fact_text); });
+
+        process_lines(interactive, R"(
+.auto-run
+.stratum 1
+(X ~ animal) => (X needs food)
+.stratum 0
+(X ~ dog) => (X ~ animal)
+(X ~ puppy) => (X ~ dog)
+rex ~ puppy
+bello ~ dog
+)");
+
+        const auto rules = interactive.rules();
+        REQUIRE(rules.size() == 3);
+        CHECK(rules[0].stratum == 1);
+        CHECK(rules[1].stratum == 0);
+        CHECK(rules[2].stratum == 0);
+
+        interactive.run(false, false, false);
+        REQUIRE(seen.size() == 5);
+        for (size_t i = 0; i < 3; ++i)
+            CHECK(seen[i].find("needs") == std::string::npos);
+        for (size_t i = 3; i < 5; ++i)
+            CHECK(seen[i].find("needs food") != std::string::npos);
+
+        interactive.set_rule_stratum(rules[0].id, 0);
+        CHECK(interactive.rules()[0].stratum == 0);
+        CHECK_THROWS_AS(interactive.set_rule_stratum(1, 2), zelph::console::process_error); });
+}
+
+TEST_CASE("retract: deductions without remaining support are withdrawn")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+anna is_parent_of peter
+)");
+        interactive.run(false, false, false);
+
+        auto id_of = [&](const std::string& subject, const std::string& predicate)
+        {
+            for (const auto& f : interactive.facts())
+                if (f.subject == subject && f.predicate == predicate) return f.id;
+            return uint64_t{0};
+        };
+
+        const uint64_t stated  = id_of("paul", "is_parent_of");
+        const uint64_t deduced = id_of("peter", "is_child_of");
+        REQUIRE(stated != 0);
+        REQUIRE(deduced != 0);
+
+        CHECK_THROWS_AS(interactive.retract(deduced), zelph::console::process_error);
+        CHECK(interactive.retract(stated) == 1);
+
+        const auto parents = interactive.query("peter is_child_of X");
+        REQUIRE(parents.size() == 1);
+        CHECK(parents[0].at("X") == "anna");
+        CHECK(id_of("paul", "is_parent_of") == 0);
+        CHECK_THROWS_AS(interactive.retract(stated), zelph::console::process_error); });
+}
+
+TEST_CASE("retract: only the deductions that depended on the fact are withdrawn and re-derived")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.set_metrics_enabled(true);
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+(A likes B) => (B is_liked_by A)
+paul is_parent_of peter
+anna is_parent_of peter
+tom likes jerry
+)");
+        interactive.run(false, false, false);
+        REQUIRE(interactive.metrics().find("zelph_deductions_total 3") != std::string::npos);
+
+        uint64_t stated = 0;
+        for (const auto& f : interactive.facts())
+            if (f.subject == "paul" && f.predicate == "is_parent_of") stated = f.id;
+        REQUIRE(stated != 0);
+        CHECK(interactive.retract(stated) == 1);
+
+        // Nothing else was withdrawn, so nothing had to be deduced again.
+        CHECK(interactive.metrics().find("zelph_deductions_total 3") != std::string::npos);
+        CHECK(interactive.query("peter is_child_of X").size() == 1);
+        CHECK(interactive.query("jerry is_liked_by X").size() == 1); });
+}
+
+TEST_CASE("retract: a deduced fact that is stated again outlives its premises")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        interactive.run(false, false, false);
+
+        auto find = [&](const std::string& subject, const std::string& predicate)
+        {
+            for (const auto& f : interactive.facts())
+                if (f.subject == subject && f.predicate == predicate) return f;
+            return zelph::console::Interactive::Fact{};
+        };
+
+        REQUIRE(find("peter", "is_child_of").deduced);
+        interactive.process("peter is_child_of paul");
+        CHECK_FALSE(find("peter", "is_child_of").deduced);
+
+        CHECK(interactive.retract(find("paul", "is_parent_of").id) == 0);
+        CHECK(interactive.query("peter is_child_of X").size() == 1); });
+}
+
+#ifndef __EMSCRIPTEN__
+TEST_CASE("retract: refused in a network loaded from a file")
+{
+    const auto file = (std::filesystem::temp_directory_path() / "zelph-test-retract.bin").string();
+    {
+        zelph::io::OutputCollector  collector;
+        zelph::console::Interactive source(collector.sink());
+        process_lines(source, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        source.save(file);
+    }
+
+    zelph::io::OutputCollector  collector;
+    zelph::console::Interactive target(collector.sink());
+    target.load(file);
+    std::filesystem::remove(file);
+
+    // The file does not say that "peter is_child_of paul" was deduced, so
+    // retracting its premise would leave it behind as if it were stated.
+    uint64_t premise = 0;
+    for (const auto& f : target.facts())
+        if (f.subject == "paul" && f.predicate == "is_parent_of") premise = f.id;
+    REQUIRE(premise != 0);
+    CHECK_THROWS_AS(target.retract(premise), zelph::console::process_error);
+    CHECK(target.query("peter is_child_of X").size() == 1);
+
+    // A new network records its deductions again.
+    target.process(".new");
+    target.process("(A is_parent_of B) => (B is_child_of A)");
+    target.process("paul is_parent_of peter");
+    premise = 0;
+    for (const auto& f : target.facts())
+        if (f.subject == "paul" && f.predicate == "is_parent_of") premise = f.id;
+    REQUIRE(premise != 0);
+    CHECK(target.retract(premise) == 1);
+}
+
+TEST_CASE("retract: a load that fails to open its file does not block it")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        CHECK_THROWS(interactive.load((std::filesystem::temp_directory_path() / "zelph-test-missing.bin").string()));
+
+        process_lines(interactive, R"(
+(A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        uint64_t premise = 0;
+        for (const auto& f : interactive.facts())
+            if (f.subject == "paul" && f.predicate == "is_parent_of") premise = f.id;
+        REQUIRE(premise != 0);
+        CHECK(interactive.retract(premise) == 1); });
+}
+#endif
+
+TEST_CASE("confidence: rules combine premise confidences and queries filter by them")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+otto is_parent_of paul
+paul is_parent_of peter
+paul is_parent_of maria
+)");
+
+        auto id_of = [&](const std::string& subject, const std::string& predicate, const std::string& object)
+        {
+            for (const auto& f : interactive.facts())
+                if (f.subject == subject && f.predicate == predicate && f.objects == std::vector<std::string>{object}) return f.id;
+            return uint64_t{0};
+        };
+
+        interactive.set_confidence(id_of("otto", "is_parent_of", "paul"), 0.9);
+        interactive.set_confidence(id_of("paul", "is_parent_of", "peter"), 0.8);
+        interactive.set_confidence(id_of("paul", "is_parent_of", "maria"), 0.5);
+        CHECK_THROWS_AS(interactive.set_confidence(id_of("paul", "is_parent_of", "peter"), 1.5), zelph::console::process_error);
+
+        const uint64_t grandparent = interactive.add_rule("(A is_parent_of B, B is_parent_of C)", "(A is_grandparent_of C)");
+        interactive.set_confidence_combination(grandparent, zelph::console::Interactive::Combination::Product);
+        const uint64_t child = interactive.add_rule("(A is_parent_of B)", "(B is_child_of A)");
+        interactive.set_confidence_combiner(child, [](const std::vector<double>& premises)
+                                            { return premises.at(0) - 0.1; });
+        interactive.run(false, false, false);
+
+        // 0.9 * 0.8 is drawn, 0.9 * 0.5 is not (a fact below 0.5 counts as false).
+        const auto grandchildren = interactive.query("otto is_grandparent_of X");
+        REQUIRE(grandchildren.size() == 1);
+        CHECK(grandchildren[0].at("X") == "peter");
+        CHECK(interactive.confidence(id_of("otto", "is_grandparent_of", "peter")) == doctest::Approx(0.72));
+        CHECK(interactive.confidence(id_of("peter", "is_child_of", "paul")) == doctest::Approx(0.7));
+        CHECK(id_of("maria", "is_child_of", "paul") == 0);
+
+        CHECK(interactive.query("paul is_parent_of X").size() == 2);
+        CHECK(interactive.query("paul is_parent_of X", 0.6).size() == 1);
+        CHECK(interactive.query("paul is_parent_of X").size() == 2);
+
+        collector.clear();
+        interactive.process(".confidence otto is_parent_of paul");
+        CHECK(any_output_contains(collector, "(confidence 0.9)")); });
+}
+
+TEST_CASE("probabilistic inference: derivations combine by noisy-OR")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+tim smokes yes
+tim has flu
+)");
+
+        auto id_of = [&](const std::string& subject, const std::string& predicate, const std::string& object)
+        {
+            for (const auto& f : interactive.facts())
+                if (f.subject == subject && f.predicate == predicate && f.objects == std::vector<std::string>{object}) return f.id;
+            return uint64_t{0};
+        };
+
+        interactive.set_confidence(id_of("tim", "smokes", "yes"), 0.8);
+        interactive.set_confidence(id_of("tim", "has", "flu"), 0.5);
+
+        const uint64_t smoking = interactive.add_rule("(A smokes yes)", "(A has cough)");
+        interactive.add_rule("(A has flu)", "(A has cough)");
+        interactive.set_rule_weight(smoking, 0.5);
+        CHECK_THROWS_AS(interactive.set_rule_weight(smoking, 2), zelph::console::process_error);
+
+        interactive.set_probabilistic(true);
+        interactive.run(false, false, false);
+
+        // 1 - (1 - 0.8 * 0.5) * (1 - 0.5): neither derivation alone exceeds 0.5.
+        const uint64_t cough = id_of("tim", "has", "cough");
+        REQUIRE(cough != 0);
+        CHECK(interactive.confidence(cough) == doctest::Approx(0.7));
+        CHECK(interactive.confidence(id_of("tim", "smokes", "yes")) == doctest::Approx(0.8));
+
+        // Derivations are recorded once; another run leaves the marginal as is.
+        interactive.run(false, false, false);
+        CHECK(interactive.confidence(cough) == doctest::Approx(0.7));
+
+        const auto answers = interactive.query_probabilities("tim has X");
+        REQUIRE(answers.size() == 2);
+        for (const auto& answer : answers)
+            CHECK(answer.probability == doctest::Approx(answer.binding.at("X") == "cough" ? 0.7 : 0.5));
+
+        collector.clear();
+        interactive.process("tim has X");
+        CHECK(any_output_contains(collector, "(probability 0.7)")); });
+}
+
+TEST_CASE("temporal validity: deductions hold where their premises overlap")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+bonn is_capital_of germany @[1949..1990]
+berlin is_capital_of germany @[1990..]
+vienna is_capital_of austria @[1900..1920]
+germany member_of eu @[1957..]
+austria member_of eu @[1995..]
+(A is_capital_of B, B member_of eu) => (A eu_capital eu)
+)");
+
+        auto id_of = [&](const std::string& subject, const std::string& predicate, const std::string& object)
+        {
+            for (const auto& f : interactive.facts())
+                if (f.subject == subject && f.predicate == predicate && f.objects == std::vector<std::string>{object}) return f.id;
+            return uint64_t{0};
+        };
+
+        const auto bonn = interactive.validity(id_of("bonn", "eu_capital", "eu"));
+        CHECK(bonn.from == 1957);
+        CHECK(bonn.to == 1990);
+        CHECK(interactive.validity(id_of("berlin", "eu_capital", "eu")).from == 1990);
+        CHECK_FALSE(interactive.validity(id_of("berlin", "eu_capital", "eu")).to.has_value());
+        CHECK(id_of("vienna", "eu_capital", "eu") == 0); // 1900..1920 and 1995.. do not overlap
+
+        const auto in_1980 = interactive.query_as_of("X is_capital_of germany", 1980);
+        REQUIRE(in_1980.size() == 1);
+        CHECK(in_1980[0].at("X") == "bonn");
+        CHECK(interactive.query_as_of("X eu_capital eu", 1990).size() == 2);
+        CHECK(interactive.query("X is_capital_of germany").size() == 2);
+
+        const uint64_t vienna = id_of("vienna", "is_capital_of", "austria");
+        CHECK_THROWS_AS(interactive.set_validity(vienna, {1920, 1900}), zelph::console::process_error);
+        interactive.set_validity(vienna, {});
+        CHECK_FALSE(interactive.validity(vienna).from.has_value());
+
+        collector.clear();
+        interactive.process(".validity bonn eu_capital eu");
+        CHECK(any_output_contains(collector, "(valid 1957..1990)")); });
+}
+
+TEST_CASE("contexts: facts hold in named contexts, queries and rules can be scoped")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+.context bureaucratic
+paris capital_of france
+italy member_of eu
+.context legal
+rome capital_of italy
+.context off
+berlin capital_of germany
+france member_of eu
+germany member_of eu
+(A capital_of B, B member_of eu) => (A eu_capital eu)
+)");
+
+        auto id_of = [&](const std::string& subject, const std::string& predicate, const std::string& object)
+        {
+            for (const auto& f : interactive.facts())
+                if (f.subject == subject && f.predicate == predicate && f.objects == std::vector<std::string>{object}) return f.id;
+            return uint64_t{0};
+        };
+
+        CHECK(interactive.contexts(id_of("paris", "capital_of", "france")) == std::vector<std::string>{"bureaucratic"});
+        CHECK(interactive.contexts(id_of("berlin", "capital_of", "germany")).empty());
+        CHECK(interactive.contexts(id_of("paris", "eu_capital", "eu")) == std::vector<std::string>{"bureaucratic"});
+        CHECK(interactive.contexts(id_of("berlin", "eu_capital", "eu")).empty());
+        CHECK(id_of("rome", "eu_capital", "eu") == 0); // legal and bureaucratic have nothing in common
+
+        CHECK(interactive.query("X capital_of Y").size() == 3);
+        CHECK(interactive.query_in("X capital_of Y", {"legal"}).size() == 2); // rome, berlin
+        CHECK(interactive.query_in("X eu_capital eu", {"bureaucratic"}).size() == 2);
+        CHECK(interactive.query_in("X eu_capital eu", {"legal"}).size() == 1);
+
+        const uint64_t has_capital = interactive.add_rule("(A capital_of B)", "(B has_capital A)");
+        interactive.set_rule_contexts(has_capital, {"bureaucratic", "legal"});
+        interactive.run(false, false, false);
+        CHECK(interactive.contexts(id_of("france", "has_capital", "paris")) == std::vector<std::string>{"bureaucratic"});
+        CHECK((interactive.contexts(id_of("germany", "has_capital", "berlin")) == std::vector<std::string>{"bureaucratic", "legal"}));
+
+        collector.clear();
+        interactive.process(".contexts");
+        CHECK(any_output_contains(collector, "legal (3 facts)")); });
+}
+
+TEST_CASE("term depth: rules that build terms without bound are reported and stopped")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.process("(X parent Y) => (Y child X)");
+        interactive.process(".unbounded-rules");
+        CHECK(any_output_contains(collector, "No rule builds new terms without bound."));
+
+        interactive.process(".max-term-depth 5");
+        interactive.process("zero ~ nat");
+
+        std::string kind, message;
+        try
+        {
+            interactive.process("(X ~ nat) => ((X plus one) ~ nat)");
+        }
+        catch (const zelph::console::process_error& ex)
+        {
+            kind    = zelph::console::to_string(ex.kind());
+            message = ex.what();
+        }
+
+        CHECK(kind == "resource-limit");
+        CHECK(message.find(".max-term-depth") != std::string::npos);
+        CHECK(interactive.query("(zero plus one) ~ X").size() == 1);
+
+        interactive.process(".unbounded-rules");
+        CHECK(any_output_contains(collector, "on each firing; cycle")); });
+}
+
+TEST_CASE("arithmetic: rules compare and compute numeric literals")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.set_native_arithmetic(true);
+        CHECK(interactive.native_arithmetic());
+
+        process_lines(interactive, R"(
+berlin population 3645000
+berlin area 900
+potsdam population 185000
+potsdam area 185
+(*{ (X population P) (P > 1000000) } ~ conjunction) => (X ~ large_city)
+(*{ (X population P) (P < 1000000) } ~ conjunction) => (X ~ small_city)
+(*{ (X population P) (X area A) ((P / A) = D) } ~ conjunction) => (X density D)
+)");
+        interactive.run(false, false, false);
+
+        auto answers = interactive.query("X ~ large_city");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "berlin");
+
+        answers = interactive.query("X ~ small_city");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "potsdam");
+
+        answers = interactive.query("berlin density D");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("D") == "4050");
+
+        answers = interactive.query("(2.5 * 4) = R");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("R") == "10");
+
+        // Not a number: the condition fails.
+        CHECK(interactive.query("(berlin + 1) = R").empty()); });
+}
+
+TEST_CASE("strings: rules test and compose names")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.set_string_builtins(true);
+        CHECK(interactive.string_builtins());
+
+        process_lines(interactive, R"(
+ada first_name Ada
+ada last_name Lovelace
+alan first_name Alan
+alan last_name Turing
+(*{ (X first_name F) (X last_name L) (((F ++ "-") ++ L) = N) } ~ conjunction) => (X label N)
+(*{ (X label N) (N starts_with Ada) } ~ conjunction) => (X ~ ada_person)
+(*{ (X label N) (N ends_with turing) } ~ conjunction) => (X ~ lower_turing)
+(*{ (X first_name F) (F equals_ignoring_case ALAN) } ~ conjunction) => (X ~ alan_person)
+)");
+        interactive.run(false, false, false);
+
+        auto answers = interactive.query("ada label N");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("N") == "Ada-Lovelace");
+
+        answers = interactive.query("X ~ ada_person");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "ada");
+
+        // starts_with and ends_with are case-sensitive.
+        CHECK(interactive.query("X ~ lower_turing").empty());
+
+        answers = interactive.query("X ~ alan_person");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "alan"); });
+}
+
+TEST_CASE("lists: membership, head, tail and length of list objects")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.set_list_builtins(true);
+        CHECK(interactive.list_builtins());
+
+        process_lines(interactive, R"(
+route1 has_waypoints <berlin potsdam dresden>
+route2 has_waypoints <leipzig halle>
+(*{ (R has_waypoints L) (W element_of L) } ~ conjunction) => (R passes W)
+(*{ (R has_waypoints L) (L head H) } ~ conjunction) => (R starts_at H)
+(*{ (R has_waypoints L) (L tail T) (T head H) } ~ conjunction) => (R continues_to H)
+(*{ (R has_waypoints L) (L length N) } ~ conjunction) => (R stops N)
+)");
+        interactive.run(false, false, false);
+
+        auto answers = interactive.query("route1 passes W");
+        REQUIRE(answers.size() == 3);
+        std::set<std::string> waypoints;
+        for (const auto& answer : answers)
+            waypoints.insert(answer.at("W"));
+        const std::set<std::string> expected{"berlin", "dresden", "potsdam"};
+        CHECK(waypoints == expected);
+
+        answers = interactive.query("R starts_at H");
+        REQUIRE(answers.size() == 2);
+
+        answers = interactive.query("route1 continues_to H");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("H") == "potsdam");
+
+        answers = interactive.query("route2 stops N");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("N") == "2");
+        answers = interactive.query("route1 stops N");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("N") == "3"); });
+}
+
+TEST_CASE("qualifiers: facts about facts are stated, listed and matched")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        process_lines(interactive, R"(
+berlin is_capital_of germany
+bonn is_capital_of germany
+(bonn is_capital_of germany) since 1949
+(bonn is_capital_of germany) until 1990
+((X is_capital_of Y) source wikidata) => (X ~ sourced_capital)
+)");
+
+        auto fact_id = [&](const std::string& subject)
+        {
+            for (const auto& fact : interactive.facts())
+                if (fact.subject == subject && fact.predicate == "is_capital_of") return fact.id;
+            return uint64_t{0};
+        };
+        const uint64_t berlin = fact_id("berlin");
+        const uint64_t bonn   = fact_id("bonn");
+        REQUIRE(berlin != 0);
+        REQUIRE(bonn != 0);
+
+        const auto bonn_qualifiers = interactive.qualifiers(bonn);
+        REQUIRE(bonn_qualifiers.size() == 2);
+        CHECK(bonn_qualifiers[0].predicate == "since");
+        CHECK(bonn_qualifiers[0].value == "1949");
+        CHECK(bonn_qualifiers[1].predicate == "until");
+        CHECK(bonn_qualifiers[1].value == "1990");
+
+        CHECK(interactive.qualify(berlin, "since", "1990") != 0);
+        interactive.qualify(berlin, "source", "wikidata");
+        CHECK(interactive.qualifiers(berlin).size() == 2);
+        interactive.run(false, false, false);
+
+        auto answers = interactive.query("(X is_capital_of germany) since 1990");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "berlin");
+
+        answers = interactive.query("X ~ sourced_capital");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "berlin");
+
+        CHECK_THROWS_WITH_AS(interactive.qualifiers(interactive.intern("berlin")), doctest::Contains("is not a fact"), zelph::console::process_error); });
+}
+
+TEST_CASE("provenance: facts record their origin, queries and rules can be limited to sources")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.set_source_scope({"script"});
+
+        std::istringstream script(R"((A is_parent_of B) => (B is_child_of A)
+paul is_parent_of peter
+)");
+        interactive.process_script(script);
+        interactive.process("anna is_parent_of peter");
+        interactive.add_fact(interactive.intern("tom"), interactive.intern("is_parent_of"), interactive.intern("peter"));
+
+        auto fact_id = [&](const std::string& subject, const std::string& predicate)
+        {
+            for (const auto& fact : interactive.facts())
+                if (fact.subject == subject && fact.predicate == predicate) return fact.id;
+            return uint64_t{0};
+        };
+        const uint64_t paul = fact_id("paul", "is_parent_of");
+        const uint64_t anna = fact_id("anna", "is_parent_of");
+        const uint64_t tom  = fact_id("tom", "is_parent_of");
+        REQUIRE(paul != 0);
+        REQUIRE(anna != 0);
+        REQUIRE(tom != 0);
+
+        const auto from_script = interactive.provenance(paul);
+        CHECK(from_script.source == "script");
+        CHECK(from_script.line == 2);
+        CHECK(interactive.provenance(anna).source == "input");
+        CHECK(interactive.provenance(tom).source == "api");
+
+        interactive.run(false, false, false);
+        auto answers = interactive.query("peter is_child_of X");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "paul");
+        CHECK(interactive.query("X is_parent_of peter").size() == 1);
+
+        const uint64_t deduced    = fact_id("peter", "is_child_of");
+        const auto     from_rule  = interactive.provenance(deduced);
+        CHECK(from_rule.source == "rule");
+        CHECK(from_rule.rule != 0);
+
+        interactive.set_source_scope({});
+        interactive.run(false, false, false);
+        CHECK(interactive.query("peter is_child_of X").size() == 3);
+
+        CHECK_THROWS_WITH_AS(interactive.provenance(interactive.intern("paul")), doctest::Contains("is not a fact"), zelph::console::process_error); });
+}
+
+TEST_CASE("trust: deductions carry the trust of their weakest source and can require a minimum")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        interactive.set_source_trust("api", 0.4);
+
+        std::istringstream script(R"(paul is_parent_of peter
+)");
+        interactive.process_script(script);
+        const uint64_t tom     = interactive.add_fact(interactive.intern("tom"), interactive.intern("is_parent_of"), interactive.intern("peter"));
+        const uint64_t careful = interactive.add_rule("A is_parent_of B", "B is_child_of A");
+        interactive.add_rule("A is_parent_of B", "A has_child B");
+        interactive.set_rule_min_trust(careful, 0.5);
+        interactive.run(false, false, false);
+
+        auto answers = interactive.query("peter is_child_of X");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "paul");
+        CHECK(interactive.query("X has_child peter").size() == 2);
+
+        CHECK(interactive.trust(tom) == doctest::Approx(0.4));
+        for (const auto& fact : interactive.facts())
+        {
+            if (fact.subject == "tom" && fact.predicate == "has_child") CHECK(interactive.trust(fact.id) == doctest::Approx(0.4));
+            if (fact.subject == "paul" && fact.predicate == "has_child") CHECK(interactive.trust(fact.id) == doctest::Approx(1));
+        }
+
+        interactive.set_min_trust(0.5);
+        answers = interactive.query("X has_child peter");
+        REQUIRE(answers.size() == 1);
+        CHECK(answers[0].at("X") == "paul");
+
+        CHECK_THROWS_AS(interactive.set_source_trust("api", 2), zelph::console::process_error);
+        CHECK_THROWS_WITH_AS(interactive.set_rule_min_trust(tom, 0.5), doctest::Contains("is not a rule"), zelph::console::process_error); });
+}
+
 // ---------------------------------------------------------------------------
 // Nested unification
 // ---------------------------------------------------------------------------
@@ -0,0 +1,454 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include <doctest/doctest.h> // provides main()
+
+#include "process_error.hpp"
+#include "repl/repl.hpp"
+#include "testing/golden.hpp"
+#include "test_helpers.hpp"
+
+#include <algorithm>
+#include <cstdint>
+#include <filesystem>
+#include <fstream>
+#include <sstream>
+
+using namespace zelph::test;
+
+TEST_CASE("errors: process reports the failing line and stage")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        auto kind_of = [&](const std::string& line)
+        {
+            try
+            {
+                interactive.process(line);
+            }
+            catch (const zelph::console::process_error& ex)
+            {
+                CHECK(ex.line() == line);
+                CHECK(std::string(ex.what()).starts_with("Error in line"));
+                return std::string(zelph::console::to_string(ex.kind()));
+            }
+            return std::string("none");
+        };
+
+        CHECK(kind_of(".semi-naive banana") == "command");
+        CHECK(kind_of("%(error \"boom\")") == "script");
+        CHECK(kind_of("(a b c)") == "syntax");
+        CHECK(kind_of("a b c") == "none"); });
+}
+
+TEST_CASE("errors: a syntax error reports its column")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        auto column_of = [&](const std::string& line) -> size_t
+        {
+            try
+            {
+                interactive.process(line);
+            }
+            catch (const zelph::console::process_error& ex)
+            {
+                CHECK(ex.kind() == zelph::console::ProcessErrorKind::Syntax);
+                return ex.column();
+            }
+            return 999;
+        };
+
+        CHECK(column_of("a b c )(") == 7);
+        CHECK(column_of("(a b c)") == 0); });
+}
+
+TEST_CASE("process_script: failing lines are collected with their numbers")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::istringstream script(R"(# a comment
+(A is_parent_of B) => (B is_child_of A)
+.semi-naive banana
+paul is_parent_of peter
+(a b c)
+anna is_parent_of peter
+)");
+
+        std::vector<zelph::console::ScriptLineError> errors;
+        try
+        {
+            interactive.process_script(script);
+        }
+        catch (const zelph::console::script_error& ex)
+        {
+            errors = ex.errors();
+            CHECK(std::string(ex.what()).find("line 3:") != std::string::npos);
+        }
+
+        REQUIRE(errors.size() == 2);
+        CHECK(errors[0].number == 3);
+        CHECK(errors[0].kind == zelph::console::ProcessErrorKind::Command);
+        CHECK(errors[0].line == ".semi-naive banana");
+        CHECK(errors[1].number == 5);
+        CHECK(errors[1].kind == zelph::console::ProcessErrorKind::Syntax);
+
+        // The lines after the errors were processed, and the run at the end
+        // deduced from them.
+        CHECK(interactive.query("peter is_child_of X").size() == 2);
+
+        std::istringstream clean("mary is_parent_of paul\n");
+        CHECK_NOTHROW(interactive.process_script(clean));
+        CHECK(interactive.query("paul is_child_of X").size() == 1); });
+}
+
+TEST_CASE("assertions: a script checks the knowledge it builds")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        (void)collector;
+        std::istringstream script("(A is_parent_of B) => (A \"is ancestor of\" B)\n"
+                                  "(A \"is ancestor of\" B, B \"is ancestor of\" C) => (A \"is ancestor of\" C)\n"
+                                  "paul is_parent_of peter\n"
+                                  "peter is_parent_of pius\n"
+                                  ".assert paul \"is ancestor of\" pius\n"
+                                  ".assert X \"is ancestor of\" pius\n"
+                                  ".assert-not pius \"is ancestor of\" paul\n"
+                                  ".assert-not nobody \"is ancestor of\" paul\n"
+                                  ".assert pius \"is ancestor of\" X\n"
+                                  ".assert-not paul is_parent_of peter\n");
+        try
+        {
+            interactive.process_script(script);
+            FAIL("expected a script_error");
+        }
+        catch (const zelph::console::script_error& ex)
+        {
+            REQUIRE(ex.errors().size() == 2);
+            CHECK(ex.errors()[0].number == 9);
+            CHECK(ex.errors()[0].kind == zelph::console::ProcessErrorKind::Assertion);
+            CHECK(ex.errors()[0].reason == "Assertion failed: pius \"is ancestor of\" X does not hold");
+            CHECK(ex.errors()[1].number == 10);
+            CHECK(ex.errors()[1].reason == "Assertion failed: paul is_parent_of peter holds");
+        }
+
+        // Checking a fact does not state it.
+        CHECK_THROWS_AS(interactive.process(".assert pius is_parent_of paul"), zelph::console::process_error);
+        CHECK(interactive.query("pius is_parent_of X").empty());
+        CHECK_THROWS_AS(interactive.process(".assert a b"), zelph::console::process_error); });
+}
+
+TEST_CASE("golden files: a script's output is compared with its expected file")
+{
+    namespace fs = std::filesystem;
+    const fs::path dir = fs::temp_directory_path() / "zelph-test-golden";
+    fs::remove_all(dir);
+    fs::create_directories(dir);
+    const std::string script = (dir / "family.zph").string();
+
+    {
+        std::ofstream out(script);
+        out << "(A is_parent_of B) => (B is_child_of A)\n"
+            << "paul is_parent_of peter\n"
+            << "X is_child_of paul\n";
+    }
+
+    const std::string output = zelph::testing::capture_script(script);
+    CHECK(output.find("--- facts ---\npaul is_parent_of peter\npeter is_child_of paul (deduced)\n") != std::string::npos);
+
+    CHECK_FALSE(zelph::testing::check_script(script).passed);
+    const auto created = zelph::testing::check_script(script, true);
+    CHECK(created.passed);
+    CHECK(created.written);
+    CHECK(fs::exists(zelph::testing::expected_file(script)));
+    CHECK(zelph::testing::check_script(script).passed);
+
+    {
+        std::ofstream out(script, std::ios::app);
+        out << "anna is_parent_of ben\n";
+    }
+    const auto changed = zelph::testing::check_script(script);
+    CHECK_FALSE(changed.passed);
+    CHECK(changed.message.find("@@ ") != std::string::npos);
+    CHECK(changed.message.find("+ben is_child_of anna (deduced)") != std::string::npos);
+
+    std::ostringstream report;
+    CHECK(zelph::testing::run_golden_tests({dir.string()}, false, report) == 1);
+    CHECK(report.str().find("FAIL " + script) != std::string::npos);
+    CHECK(report.str().find("1 test(s), 1 failed") != std::string::npos);
+
+    CHECK(zelph::testing::unified_diff("a\nb\nc\n", "a\nx\nc\n", "old", "new") == "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n");
+    CHECK(zelph::testing::unified_diff("same\n", "same\n", "old", "new").empty());
+
+    fs::remove_all(dir);
+}
+
+TEST_CASE("debugger: rules fire one step at a time and pause at breakpoints")
+{
+    using zelph::console::Interactive;
+    zelph::io::OutputCollector collector;
+    Interactive                interactive(collector.sink());
+
+    process_lines(interactive, R"(
+.auto-run
+(A is_parent_of B) => (B is_child_of A)
+(A is_child_of B) => (B has_child A)
+paul is_parent_of peter
+)");
+    uint64_t child_rule = 0;
+    for (const auto& rule : interactive.rules())
+        if (rule.text.find("has_child") != std::string::npos) child_rule = rule.id;
+    REQUIRE(child_rule != 0);
+
+    {
+        zelph::console::Debugger debugger(interactive);
+
+        const auto agenda = debugger.agenda();
+        REQUIRE(agenda.size() == 1);
+        CHECK(agenda[0].rule != child_rule);
+        CHECK(std::find(agenda[0].bindings.begin(), agenda[0].bindings.end(), std::make_pair(std::string("B"), std::string("peter")))
+              != agenda[0].bindings.end());
+
+        const auto first = debugger.step();
+        REQUIRE(first);
+        CHECK(first->fact_text.find("is_child_of") != std::string::npos);
+        CHECK_FALSE(interactive.fixpoint_reached());
+        CHECK(interactive.query("paul has_child X").empty());
+        REQUIRE(debugger.agenda().size() == 1);
+        CHECK(debugger.agenda()[0].rule == child_rule);
+
+        const auto second = debugger.step();
+        REQUIRE(second);
+        CHECK(second->rule == child_rule);
+        CHECK(debugger.agenda().empty());
+        CHECK_FALSE(debugger.step());
+        CHECK(interactive.fixpoint_reached());
+
+        const uint64_t on_rule = debugger.break_on_rule(child_rule);
+        interactive.process("carl is_parent_of dora");
+        const auto stopped = debugger.resume();
+        REQUIRE(stopped);
+        CHECK(stopped->rule == child_rule);
+        CHECK(stopped->fact_text.find("carl has_child dora") != std::string::npos);
+
+        debugger.remove_breakpoint(on_rule);
+        CHECK_THROWS_AS(debugger.remove_breakpoint(on_rule), zelph::console::process_error);
+        CHECK_THROWS_AS(debugger.break_on_rule(interactive.facts()[0].id), zelph::console::process_error);
+        debugger.break_on_fact(Interactive::Triple{Interactive::Term::var(), Interactive::Term::constant("is_child_of"), Interactive::Term::constant("emma")});
+        CHECK_FALSE(debugger.resume());
+
+        interactive.process("emma is_parent_of fred");
+        const auto matched = debugger.resume();
+        REQUIRE(matched);
+        CHECK(matched->fact_text.find("fred is_child_of emma") != std::string::npos);
+        CHECK(debugger.breakpoints().size() == 1);
+    }
+
+    // Outside the debugger, runs ignore its breakpoints.
+    interactive.run(false, false, false);
+    CHECK(interactive.fixpoint_reached());
+    CHECK(interactive.query("emma has_child X").size() == 1);
+
+    collector.clear();
+    process_lines(interactive, R"(
+.debug break * has_child *
+.debug
+anna is_parent_of ben
+.debug agenda
+.debug step
+)");
+    CHECK(any_output_starts_with(collector, "[1] at facts * has_child *"));
+    CHECK(any_output_contains(collector, "B = ben"));
+    CHECK(any_output_contains(collector, "ben is_child_of anna"));
+    CHECK(interactive.query("anna has_child X").empty());
+
+    collector.clear();
+    interactive.process(".debug continue");
+    CHECK(any_output_starts_with(collector, "Stopped at breakpoint [1]"));
+    CHECK(interactive.query("anna has_child X").size() == 1);
+
+    collector.clear();
+    process_lines(interactive, R"(
+.debug clear
+.debug continue
+)");
+    CHECK(any_output_starts_with(collector, "No rule fires anymore."));
+    CHECK_THROWS_AS(interactive.process(".debug delete 1"), zelph::console::process_error);
+}
+
+TEST_CASE("no matches: a query without answers can say how it was understood")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.process("paul is_parent_of peter");
+        interactive.process("X is_parent_of anna");
+        CHECK_FALSE(any_output_contains(collector, "No matches"));
+
+        interactive.process(".no-matches on");
+        CHECK(interactive.report_no_matches());
+        interactive.process("X is_parent_of anna");
+        CHECK(any_output_contains(collector, "No matches for pattern"));
+        CHECK(any_output_contains(collector, "(subject: variable X, relation: is_parent_of, object: anna)"));
+
+        collector.clear();
+        interactive.process("X is_parent_of peter");
+        interactive.process("(A is_parent_of B) => (B is_child_of A)");
+        CHECK_FALSE(any_output_contains(collector, "No matches"));
+
+        const auto report = interactive.query_report("X is_parent_of anna");
+        CHECK(report.answers.empty());
+        CHECK(report.pattern.find("is_parent_of") != std::string::npos);
+        CHECK(report.notice.find("relation: is_parent_of, object: anna") != std::string::npos);
+
+        const auto answered = interactive.query_report("X is_parent_of peter");
+        CHECK(answered.answers.size() == 1);
+        CHECK(answered.notice.empty());
+
+        interactive.set_report_no_matches(false);
+        CHECK_FALSE(interactive.report_no_matches()); });
+}
+
+TEST_CASE("commands: .facts, .rules, .stats and .trace and their API")
+{
+    using Term = zelph::console::Interactive::Term;
+
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        interactive.process("(A is_parent_of B) => (B is_child_of A)");
+        interactive.process("paul is_parent_of peter");
+        CHECK(any_output_contains(collector, "⇐"));
+
+        collector.clear();
+        interactive.process(".facts * is_child_of *");
+        CHECK(any_output_contains(collector, "(deduced)"));
+        CHECK_FALSE(any_output_contains(collector, "is_parent_of"));
+        interactive.process(".facts anna * *");
+        CHECK(any_output_starts_with(collector, "No facts match the pattern."));
+
+        const auto children = interactive.facts({Term::var(), Term::constant("is_child_of"), Term::var()});
+        REQUIRE(children.size() == 1);
+        CHECK(children[0].subject == "peter");
+        CHECK(children[0].deduced);
+        CHECK(interactive.facts({Term::var(), Term::var(), Term::var()}).size() == interactive.facts().size());
+
+        collector.clear();
+        interactive.process(".rules");
+        CHECK(any_output_contains(collector, "is_child_of"));
+        interactive.process(".stats");
+        CHECK(any_output_starts_with(collector, "Network Statistics:"));
+        const auto statistics = interactive.statistics();
+        CHECK(statistics.nodes > 0);
+        CHECK(statistics.rules == interactive.rules().size());
+
+        interactive.process(".trace off");
+        CHECK(interactive.trace() == zelph::console::Interactive::TraceLevel::Off);
+        collector.clear();
+        interactive.process("anna is_parent_of tom");
+        CHECK_FALSE(any_output_contains(collector, "⇐"));
+        CHECK(interactive.query("tom is_child_of X").size() == 1);
+        interactive.set_trace(zelph::console::Interactive::TraceLevel::Deductions);
+        CHECK(interactive.trace() == zelph::console::Interactive::TraceLevel::Deductions); });
+}
+
+TEST_CASE("trace: each statement is reported as the fact, rule or query it was parsed into")
+{
+    using TraceLevel = zelph::console::Interactive::TraceLevel;
+
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        CHECK(interactive.trace() == TraceLevel::Deductions);
+        interactive.process("paul is_parent_of peter");
+        CHECK_FALSE(any_event_contains(collector, "Parsed as"));
+
+        interactive.process(".trace on");
+        CHECK(interactive.trace() == TraceLevel::Parsing);
+        interactive.process("anna is_parent_of tom");
+        CHECK(any_event_contains(collector, "Parsed as fact"));
+        CHECK(any_event_contains(collector, "(subject: anna, relation: is_parent_of, object: tom); new concepts: anna, tom"));
+
+        interactive.process("(A is_parent_of B) => (B is_child_of A)");
+        CHECK(any_event_contains(collector, "Parsed as rule"));
+        CHECK(any_event_contains(collector, "condition subject: variable A, relation: is_parent_of, object: variable B; consequence subject: variable B, relation: is_child_of, object: variable A"));
+
+        interactive.process("X is_child_of paul");
+        CHECK(any_event_contains(collector, "Parsed as query"));
+        interactive.process("X is_parent_of bob");
+        CHECK(any_event_contains(collector, "(subject: variable X, relation: is_parent_of, object: bob); unknown concepts: bob"));
+
+        interactive.set_trace(TraceLevel::Off);
+        CHECK(interactive.trace() == TraceLevel::Off);
+        collector.clear();
+        interactive.process("bob is_parent_of eve");
+        CHECK_FALSE(any_event_contains(collector, "Parsed as"));
+        CHECK_FALSE(any_output_contains(collector, "⇐")); });
+}
+
+TEST_CASE_FIXTURE(InteractiveFixture, "repl: the loop keeps a history and completes commands and names")
+{
+    const auto history = std::filesystem::temp_directory_path() / "zelph_test_history";
+    std::filesystem::remove(history);
+
+    std::istringstream       in("paul is_parent_of peter\n"
+                                "\n"
+                                "(A is_parent_of B) => (B is_child_of\n"
+                                "A)\n"
+                                ".quit\n"
+                                "anna is_parent_of tom\n");
+    std::ostringstream       out;
+    zelph::repl::ReplOptions options;
+    options.history_file = history.string();
+    options.banner       = false;
+    zelph::repl::Repl repl(interactive, options, in, out);
+    CHECK_FALSE(repl.editor().editing());
+
+    repl.run();
+    CHECK(out.str().find("en> ") != std::string::npos);
+    CHECK(out.str().find("... ") == std::string::npos);
+    CHECK(any_output_starts_with(collector, "type .help for help"));
+    CHECK(interactive.query("peter is_child_of X").size() == 1);
+    CHECK(interactive.query("anna is_parent_of X").empty());
+    CHECK(repl.editor().history().size() == 3);
+
+    std::istringstream      no_input;
+    zelph::repl::LineEditor reloaded(no_input, out, history.string(), 2);
+    REQUIRE(reloaded.history().size() == 2);
+    CHECK(reloaded.history().back() == "A)");
+    CHECK_FALSE(reloaded.read_line("> "));
+    std::filesystem::remove(history);
+
+    size_t start = 99;
+    auto   commands = repl.complete(".hel", 4, start);
+    CHECK(start == 0);
+    CHECK(std::find(commands.begin(), commands.end(), ".help") != commands.end());
+    CHECK(repl.complete("paul is_pa", 10, start) == std::vector<std::string>{"is_parent_of"});
+    CHECK(start == 5);
+    CHECK(repl.complete("(X is_child_of pe", 17, start) == std::vector<std::string>{"peter"});
+    CHECK(repl.complete("paul ", 5, start).empty());
+
+    CHECK(zelph::repl::LineEditor::common_prefix({"is_parent_of", "is_part_of"}) == "is_par");
+}
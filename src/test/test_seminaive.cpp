@@ -143,3 +143,67 @@ TEST_CASE("semi-naive: facts materialized as instantiation side effects seed rul
         interactive.run(true, false, false);
         CHECK(any_output_starts_with(collector, "( p linked q )")); });
 }
+
+TEST_CASE("semi-naive: a run after new facts seeds only the facts added since the last run")
+{
+    // After a complete run, the next one skips the classic first pass and
+    // seeds the facts created in between. Check mode verifies with a
+    // classic pass that nothing is missed.
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+.auto-run
+(X parent Y, Y parent Z) => (X grandparent Z)
+a parent b
+)");
+        interactive.run(true, false, false);
+        CHECK_FALSE(any_event_contains(collector, "(incremental,"));
+
+        collector.clear();
+        interactive.process("b parent c");
+        interactive.run(true, false, false);
+        CHECK(any_event_contains(collector, "(incremental,"));
+        CHECK(any_output_starts_with(collector, "( a grandparent c )"));
+
+        // A new rule needs the classic pass again.
+        collector.clear();
+        interactive.process("(X grandparent Y) => (Y grandchild X)");
+        interactive.run(true, false, false);
+        CHECK_FALSE(any_event_contains(collector, "(incremental,"));
+        CHECK(any_output_starts_with(collector, "( c grandchild a )")); });
+}
+
+TEST_CASE("semi-naive: a run paused by a bound resumes where it stopped")
+{
+    // Each run pauses after one iteration or two deductions. Repeated runs
+    // reach the full closure; check mode verifies each completed run.
+    for (const char* bound : {".max-iterations 1", ".max-deductions 2"})
+    {
+        CAPTURE(bound);
+        run_both_modes([bound](auto& collector, auto& interactive)
+                       {
+            process_lines(interactive, R"(
+.auto-run
+(X before Y, Y before Z) => (X before Z)
+a before b
+b before c
+c before d
+d before e
+e before f
+)");
+            interactive.process(bound);
+            interactive.run(true, false, false);
+            CHECK(any_output_contains(collector, "Reasoning paused after"));
+            CHECK_FALSE(interactive.fixpoint_reached());
+
+            int runs = 1;
+            while (!interactive.fixpoint_reached() && runs < 50)
+            {
+                interactive.run(true, false, false);
+                ++runs;
+            }
+            CHECK(interactive.fixpoint_reached());
+            CHECK(runs > 1);
+            CHECK(interactive.query("a before X").size() == 5); });
+    }
+}
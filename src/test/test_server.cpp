@@ -0,0 +1,365 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include <doctest/doctest.h> // provides main()
+
+#include "server/http_server.hpp"
+#ifdef ZELPH_GRPC
+    #include "server/grpc_server.hpp"
+    #include "zelph.grpc.pb.h"
+
+    #include <grpcpp/grpcpp.h>
+#endif
+#include "test_helpers.hpp"
+
+#if !defined(_WIN32) && !defined(__EMSCRIPTEN__)
+    #include <arpa/inet.h>
+    #include <netinet/in.h>
+    #include <sys/socket.h>
+    #include <unistd.h>
+#endif
+
+#include <atomic>
+#include <chrono>
+#include <cstdint>
+#include <thread>
+
+using namespace zelph::test;
+
+TEST_CASE_FIXTURE(InteractiveFixture, "server: facts, queries and runs over HTTP")
+{
+    zelph::server::HttpServer server(interactive);
+
+    auto request = [&](const std::string& method, const std::string& path, const std::string& body = "")
+    {
+        return server.handle({method, path, {}, body});
+    };
+
+    auto added = request("POST", "/facts", R"({"facts": [{"subject": "paul", "predicate": "is_parent_of", "object": "peter"}]})");
+    CHECK(added.status == 200);
+    CHECK(added.body.find("\"ids\": [") != std::string::npos);
+
+    interactive.process("(A is_parent_of B) => (B is_child_of A)");
+    CHECK(request("POST", "/run").body == R"({"fixpoint_reached": true})");
+
+    auto answered = request("POST", "/query", R"({"query": "peter is_child_of X"})");
+    CHECK(answered.status == 200);
+    CHECK(answered.body == R"({"answers": [{"X": "paul"}]})");
+
+    auto listed = request("GET", "/facts");
+    REQUIRE(listed.stream);
+    CHECK(listed.content_type == "application/x-ndjson");
+    std::string lines;
+    listed.stream([&](const std::string& piece)
+                  { lines += piece; },
+                  {});
+    CHECK(lines.find(R"("subject": "paul", "predicate": "is_parent_of")") != std::string::npos);
+    CHECK(lines.find(R"("deduced": true)") != std::string::npos);
+
+    // The stream is read a page at a time.
+    std::vector<uint64_t> paged;
+    interactive.facts_each([&](const std::vector<zelph::console::Interactive::Fact>& page)
+                           {
+        CHECK(page.size() == 1);
+        paged.push_back(page.at(0).id); },
+                           1);
+    std::vector<uint64_t> listed_ids;
+    for (const auto& fact : interactive.facts())
+        listed_ids.push_back(fact.id);
+    CHECK(paged == listed_ids);
+
+    CHECK(request("POST", "/query", "{\"query\": \"(a b c)\"}").status == 400);
+    CHECK(request("POST", "/facts", "{").status == 400);
+    CHECK(request("POST", "/facts", R"({"facts": [{"subject": "paul"}]})").status == 400);
+    CHECK(request("GET", "/nowhere").status == 404);
+    CHECK(request("DELETE", "/facts").status == 405);
+
+    server.set_authenticator([](const zelph::server::Request& r)
+                             { return r.headers.contains("authorization") && r.headers.at("authorization") == "Bearer secret"; });
+    CHECK(request("POST", "/run").status == 401);
+    CHECK(server.handle({"POST", "/run", {{"authorization", "Bearer secret"}}, ""}).status == 200);
+}
+
+#if !defined(_WIN32) && !defined(__EMSCRIPTEN__)
+namespace
+{
+    // Sends one raw HTTP request to 127.0.0.1:port and returns everything
+    // the server writes before it closes the connection, or an empty string
+    // if the exchange fails (it runs on client threads, where REQUIRE can't).
+    std::string http_exchange(uint16_t port, const std::string& request)
+    {
+        const int socket_fd = ::socket(AF_INET, SOCK_STREAM, 0);
+        if (socket_fd < 0) return {};
+        sockaddr_in address{};
+        address.sin_family      = AF_INET;
+        address.sin_port        = htons(port);
+        address.sin_addr.s_addr = htonl(INADDR_LOOPBACK);
+        std::string response;
+        if (::connect(socket_fd, reinterpret_cast<sockaddr*>(&address), sizeof(address)) != 0
+            || ::send(socket_fd, request.data(), request.size(), 0) != static_cast<ssize_t>(request.size()))
+        {
+            ::close(socket_fd);
+            return response;
+        }
+        char        buffer[4096];
+        for (ssize_t received; (received = ::recv(socket_fd, buffer, sizeof(buffer), 0)) > 0;)
+            response.append(buffer, static_cast<size_t>(received));
+        ::close(socket_fd);
+        return response;
+    }
+
+    std::string http_post(uint16_t port, const std::string& path, const std::string& body)
+    {
+        return http_exchange(port,
+                             "POST " + path + " HTTP/1.1\r\nHost: 127.0.0.1\r\nContent-Type: application/json\r\n"
+                                 + "Content-Length: " + std::to_string(body.size()) + "\r\n\r\n" + body);
+    }
+}
+
+TEST_CASE_FIXTURE(InteractiveFixture, "server: requests over real connections are served on their own threads")
+{
+    zelph::server::HttpServer server(interactive);
+    const uint16_t            port = server.start(0);
+    REQUIRE(port != 0);
+
+    interactive.process("(A is_parent_of B) => (B is_child_of A)");
+    const std::string added = http_post(port, "/facts", R"({"facts": [{"subject": "paul", "predicate": "is_parent_of", "object": "peter"}]})");
+    CHECK(added.rfind("HTTP/1.1 200", 0) == 0);
+    CHECK(http_post(port, "/run", "").find(R"({"fixpoint_reached": true})") != std::string::npos);
+
+    // Several clients at once, each served on a connection thread that is
+    // neither the one that built the Interactive nor one another's.
+    std::vector<std::string> answers(4);
+    std::vector<std::thread> clients;
+    for (size_t i = 0; i < answers.size(); ++i)
+        clients.emplace_back([&, i]
+                             {
+            for (int round = 0; round < 10; ++round)
+                answers[i] = http_post(port, "/query", R"({"query": "peter is_child_of X"})"); });
+    for (auto& client : clients)
+        client.join();
+
+    for (const auto& answer : answers)
+    {
+        CHECK(answer.rfind("HTTP/1.1 200", 0) == 0);
+        CHECK(answer.find(R"({"answers": [{"X": "paul"}]})") != std::string::npos);
+    }
+
+    // The instance is still usable from the thread that created it.
+    interactive.process("anna is_parent_of peter");
+    CHECK(http_post(port, "/query", "{\"query\": \"(a b c)\"}").rfind("HTTP/1.1 400", 0) == 0);
+
+    server.stop();
+
+    zelph::server::HttpServer other(interactive);
+    CHECK_THROWS_AS(other.start(0, "localhost"), std::runtime_error);
+}
+
+TEST_CASE_FIXTURE(InteractiveFixture, "server: connections beyond the limit are refused and stop() ends idle ones")
+{
+    zelph::server::HttpServer server(interactive);
+    server.set_max_connections(1);
+    const uint16_t port = server.start(0);
+
+    // A client that connects and never sends its request.
+    const int idle = ::socket(AF_INET, SOCK_STREAM, 0);
+    REQUIRE(idle >= 0);
+    sockaddr_in address{};
+    address.sin_family      = AF_INET;
+    address.sin_port        = htons(port);
+    address.sin_addr.s_addr = htonl(INADDR_LOOPBACK);
+    REQUIRE(::connect(idle, reinterpret_cast<sockaddr*>(&address), sizeof(address)) == 0);
+
+    CHECK(http_post(port, "/run", "").rfind("HTTP/1.1 503", 0) == 0);
+
+    // Returns without waiting for the idle client's read timeout.
+    const auto before = std::chrono::steady_clock::now();
+    server.stop();
+    CHECK(std::chrono::steady_clock::now() - before < std::chrono::seconds(10));
+    ::close(idle);
+}
+#endif
+
+TEST_CASE_FIXTURE(InteractiveFixture, "server: /changes is a WebSocket feed of the network's changes")
+{
+    zelph::server::HttpServer server(interactive);
+
+    CHECK(server.handle({"GET", "/changes", {}, ""}).status == 400);
+    CHECK(server.handle({"POST", "/changes", {}, ""}).status == 405);
+
+    auto response = server.handle({"GET",
+                                   "/changes?predicate=is_parent_of&subject=paul",
+                                   {{"upgrade", "websocket"}, {"sec-websocket-key", "dGhlIHNhbXBsZSBub25jZQ=="}},
+                                   ""});
+    REQUIRE(response.status == 101);
+    CHECK(response.headers.at("Sec-WebSocket-Accept") == "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=");
+    REQUIRE(response.stream);
+
+    interactive.process("anna is_parent_of peter");
+    interactive.process("paul is_parent_of peter");
+
+    std::vector<std::string> frames;
+    CHECK_THROWS(response.stream([&](const std::string& frame)
+                                 {
+        frames.push_back(frame);
+        throw std::runtime_error("enough"); },
+                                 {}));
+    REQUIRE(frames.size() == 1);
+    CHECK(static_cast<unsigned char>(frames[0][0]) == 0x81);
+    CHECK(frames[0].find(R"({"change": "added", "fact": {)") != std::string::npos);
+    CHECK(frames[0].find(R"("subject": "paul")") != std::string::npos);
+}
+
+TEST_CASE_FIXTURE(InteractiveFixture, "server: /changes answers pings, ends on close and drops clients that fall behind")
+{
+    zelph::server::HttpServer server(interactive);
+
+    const zelph::server::Request upgrade{"GET",
+                                         "/changes",
+                                         {{"upgrade", "websocket"}, {"sec-websocket-key", "dGhlIHNhbXBsZSBub25jZQ=="}},
+                                         ""};
+
+    // A masked ping carrying "hi", then a masked close with code 1000.
+    const std::string client_frames{"\x89\x82\x01\x02\x03\x04"
+                                    "\x69\x6b"
+                                    "\x88\x82\x01\x02\x03\x04"
+                                    "\x02\xea",
+                                    14};
+    bool                     delivered = false;
+    std::vector<std::string> frames;
+    auto                     response = server.handle(upgrade);
+    REQUIRE(response.stream);
+    response.stream([&](const std::string& frame)
+                    { frames.push_back(frame); },
+                    [&](std::string& data, int)
+                    {
+                        if (!delivered) data += client_frames;
+                        delivered = true;
+                        return true;
+                    });
+    REQUIRE(frames.size() == 2);
+    CHECK(frames[0] == std::string("\x8a\x02hi", 4));
+    CHECK(frames[1] == std::string("\x88\x02\x03\xe8", 4));
+
+    // A client that never reads is closed with 1008 once too many changes wait.
+    frames.clear();
+    response = server.handle(upgrade);
+    REQUIRE(response.stream);
+    for (int i = 0; i <= 10000; ++i)
+        interactive.process("n" + std::to_string(i) + " follows n" + std::to_string(i + 1));
+    response.stream([&](const std::string& frame)
+                    { frames.push_back(frame); },
+                    {});
+    REQUIRE(frames.size() == 1);
+    CHECK(frames[0].substr(0, 4) == std::string("\x88\x10\x03\xf0", 4));
+    CHECK(frames[0].substr(4) == "falling behind");
+}
+
+#ifdef ZELPH_GRPC
+TEST_CASE_FIXTURE(InteractiveFixture, "grpc: facts, queries and runs, with deductions streamed as they are made")
+{
+    // The server subscribes next to the instance's own deduction callback.
+    std::atomic<int> own_deductions{0};
+    interactive.on_deduction([&](const zelph::console::Interactive::Deduction&)
+                             { ++own_deductions; });
+
+    zelph::server::GrpcServer server(interactive);
+    const uint16_t            port = server.start("127.0.0.1:0");
+
+    auto channel = grpc::CreateChannel("127.0.0.1:" + std::to_string(port), grpc::InsecureChannelCredentials());
+    auto stub    = zelph::v1::Zelph::NewStub(channel);
+
+    grpc::ClientContext stream_context;
+    auto                stream = stub->StreamDeductions(&stream_context, zelph::v1::StreamDeductionsRequest());
+    stream->WaitForInitialMetadata();
+
+    interactive.process("(A is_parent_of B) => (B is_child_of A)");
+
+    zelph::v1::AssertFactsRequest assert_request;
+    zelph::v1::Fact*              fact = assert_request.add_facts();
+    fact->set_subject("paul");
+    fact->set_predicate("is_parent_of");
+    fact->set_object("peter");
+    zelph::v1::AssertFactsResponse asserted;
+    {
+        grpc::ClientContext context;
+        REQUIRE(stub->AssertFacts(&context, assert_request, &asserted).ok());
+    }
+    CHECK(asserted.ids_size() == 1);
+
+    zelph::v1::RunResponse ran;
+    {
+        grpc::ClientContext context;
+        REQUIRE(stub->Run(&context, zelph::v1::RunRequest(), &ran).ok());
+    }
+    CHECK(ran.fixpoint_reached());
+
+    zelph::v1::Deduction deduction;
+    REQUIRE(stream->Read(&deduction));
+    CHECK(deduction.fact_text().find("is_child_of") != std::string::npos);
+    CHECK(deduction.rule() != 0);
+    CHECK(own_deductions == 1);
+
+    // Queries from several clients at once, each answered on a gRPC thread.
+    zelph::v1::QueryRequest query;
+    query.set_query("peter is_child_of X");
+    std::vector<zelph::v1::QueryResponse> answers(4);
+    std::vector<grpc::Status>             statuses(answers.size());
+    std::vector<std::thread>              clients;
+    for (size_t i = 0; i < answers.size(); ++i)
+        clients.emplace_back([&, i]
+                             {
+            for (int round = 0; round < 10 && statuses[i].ok(); ++round)
+            {
+                grpc::ClientContext context;
+                statuses[i] = stub->Query(&context, query, &answers[i]);
+            } });
+    for (auto& client : clients)
+        client.join();
+    for (size_t i = 0; i < answers.size(); ++i)
+    {
+        CHECK_MESSAGE(statuses[i].ok(), statuses[i].error_message());
+        REQUIRE(answers[i].answers_size() == 1);
+        CHECK(answers[i].answers(0).values().at("X") == "paul");
+    }
+
+    zelph::v1::QueryResponse answered;
+
+    query.set_query("(a b c)");
+    {
+        grpc::ClientContext context;
+        CHECK(stub->Query(&context, query, &answered).error_code() == grpc::StatusCode::INVALID_ARGUMENT);
+    }
+
+    stream_context.TryCancel();
+    while (stream->Read(&deduction))
+        ;
+    stream->Finish();
+
+    server.stop();
+    interactive.process("anna is_parent_of tom");
+    CHECK(own_deductions == 2);
+}
+#endif
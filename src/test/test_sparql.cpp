@@ -25,12 +25,14 @@ along with zelph. If not, see <https://www.gnu.org/licenses/>.
 
 #include <doctest/doctest.h>
 
+#include "process_error.hpp"
 #include "test_helpers.hpp"
 
 #include <filesystem>
 #include <fstream>
 #include <sstream>
 #include <string>
+#include <vector>
 
 using namespace zelph::test;
 
@@ -975,3 +977,28 @@ QD2 P279 QD1
         CHECK(any_output_contains(collector, "2"));
         CHECK(any_output_contains(collector, "-- 1 result(s) --")); });
 }
+
+TEST_CASE("sparql: embedders get a result set without importing the script")
+{
+    run_both_modes([](auto&, auto& interactive)
+                   {
+        setup_base_graph(interactive);
+
+        auto result = interactive.sparql(
+            "SELECT ?x ?g WHERE { ?x wdt:P31 wd:Q5 . OPTIONAL { ?x wdt:P21 ?g . } }");
+
+        CHECK((result.variables == std::vector<std::string>{"x", "g"}));
+        REQUIRE(result.rows.size() == 2);
+        for (const auto& row : result.rows)
+        {
+            if (row.at("x") == "Q1")
+                CHECK(row.at("g") == "Q6581097");
+            else
+            {
+                CHECK(row.at("x") == "Q2");
+                CHECK(row.count("g") == 0);
+            }
+        }
+
+        CHECK_THROWS_AS(interactive.sparql("SELECT ?x WHERE { ?x }"), zelph::console::process_error); });
+}
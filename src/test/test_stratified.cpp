@@ -334,3 +334,48 @@ TEST_CASE("stratified: deferred alternation with sugar-form negated conditions")
         CHECK(any_output_contains(collector, "w r w"));
         CHECK(any_output_contains(collector, "w s w")); });
 }
+
+TEST_CASE("stratified: root ancestors via negation-as-failure")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        process_lines(interactive, R"(
+(P parent C) => (P person P)
+(P parent C) => (C person C)
+(X person X, ¬(Y parent X)) => (X rootancestor X)
+adam parent cain
+cain parent enoch
+)");
+        CHECK(any_output_contains(collector, "adam rootancestor adam"));
+        CHECK_FALSE(any_output_contains(collector, "cain rootancestor cain"));
+        CHECK_FALSE(any_output_contains(collector, "enoch rootancestor enoch"));
+
+        collector.clear();
+        interactive.process(".stratification check");
+        CHECK(any_output_contains(collector, "The rule set is stratifiable.")); });
+}
+
+TEST_CASE("stratified: strict mode rejects non-stratifiable rule sets")
+{
+    run_both_modes([](auto& collector, auto& interactive)
+                   {
+        // Each rule's consequence grows the pattern the other one negates:
+        // which one fires for a depends on evaluation order.
+        process_lines(interactive, R"(
+(X node X, ¬(X blue X)) => (X red X)
+(X node X, ¬(X red X)) => (X blue X)
+a node a
+)");
+        collector.clear();
+        interactive.process(".stratification check");
+        CHECK(any_output_contains(collector, "may grow ¬"));
+
+        interactive.process(".stratification strict");
+        collector.clear();
+        CHECK_THROWS_WITH_AS(interactive.run(true, false, false), doctest::Contains("not stratifiable"), std::runtime_error);
+        CHECK_FALSE(any_output_contains(collector, "a red a"));
+        CHECK_FALSE(any_output_contains(collector, "a blue a"));
+
+        interactive.process(".stratification lenient");
+        CHECK_NOTHROW(interactive.run(false, false, false)); });
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2025, 2026 acrion innovations GmbH
+Authors: Stefan Zipproth, s.zipproth@acrion.ch
+
+This file is part of zelph, see https://github.com/acrion/zelph and https://zelph.org
+
+zelph is offered under a commercial and under the AGPL license.
+For commercial licensing, contact us at https://acrion.ch/sales. For AGPL licensing, see below.
+
+AGPL licensing:
+
+zelph is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+zelph is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with zelph. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+#include <doctest/doctest.h>
+
+#include "test_helpers.hpp"
+
+#include <filesystem>
+#include <fstream>
+#include <string>
+#include <vector>
+
+using namespace zelph::test;
+
+namespace
+{
+    // Q1 has labels in three languages and one claim each of P31 and P279.
+    const char* kDump = R"json([
+{"type":"item","id":"Q1","labels":{"en":{"language":"en","value":"housecat"},"de":{"language":"de","value":"Hauskatze"},"fr":{"language":"fr","value":"chat domestique"}},"claims":{"P31":[{"mainsnak":{"snaktype":"value","property":"P31","datavalue":{"value":{"entity-type":"item","numeric-id":2,"id":"Q2"},"type":"wikibase-entityid"},"datatype":"wikibase-item"},"type":"statement","id":"Q1$AAA1-1","rank":"normal"}],"P279":[{"mainsnak":{"snaktype":"value","property":"P279","datavalue":{"value":{"entity-type":"item","numeric-id":3,"id":"Q3"},"type":"wikibase-entityid"},"datatype":"wikibase-item"},"type":"statement","id":"Q1$BBB2-2","rank":"normal"}]},"sitelinks":{}}
+]
+)json";
+
+    const std::string kStem = "zelph_wikidata_filter_test";
+
+    std::filesystem::path write_dump()
+    {
+        const auto    path = std::filesystem::temp_directory_path() / (kStem + ".json");
+        std::ofstream out(path, std::ios::binary);
+        out << kDump;
+        return path;
+    }
+
+    // The .bin caches next to the dump, by file name.
+    std::vector<std::string> cache_files()
+    {
+        std::vector<std::string> names;
+        for (const auto& entry : std::filesystem::directory_iterator(std::filesystem::temp_directory_path()))
+        {
+            const std::string name = entry.path().filename().string();
+            if (name.rfind(kStem, 0) == 0 && entry.path().extension() == ".bin") names.push_back(name);
+        }
+        return names;
+    }
+
+    void remove_caches()
+    {
+        for (const auto& name : cache_files())
+            std::filesystem::remove(std::filesystem::temp_directory_path() / name);
+    }
+}
+
+TEST_CASE("wikidata filters: label languages and property whitelist, cached per filter")
+{
+    const auto dump = write_dump();
+    remove_caches();
+
+    // The first instance imports the dump, the second one reads the cache.
+    run_both_modes([&](auto& collector, auto& interactive)
+                   {
+        interactive.process(".load \"" + dump.string() + "\" lang=de properties=P31");
+        interactive.process(".lang wikidata");
+
+        collector.clear();
+        interactive.process("Q1 P31 _c");
+        CHECK(answers_contain(collector, "Q1 P31 Q2"));
+
+        // P279 is not in the whitelist.
+        collector.clear();
+        interactive.process("Q1 P279 _c");
+        CHECK(collect_answers(collector).empty());
+
+        // Only the German label is imported.
+        collector.clear();
+        interactive.process(R"(%(zelph/name (zelph/resolve "Q1" "wikidata") "de"))");
+        CHECK(any_output_contains(collector, "Hauskatze"));
+
+        collector.clear();
+        interactive.process(R"(%(zelph/name (zelph/resolve "Q1" "wikidata") "en"))");
+        interactive.process(R"(%(zelph/name (zelph/resolve "Q1" "wikidata") "fr"))");
+        CHECK_FALSE(any_output_contains(collector, "housecat"));
+        CHECK_FALSE(any_output_contains(collector, "chat domestique")); });
+
+    // The filtered import has a cache of its own, named after the filter,
+    // and leaves the name of the unfiltered cache free.
+    const auto caches = cache_files();
+    REQUIRE(caches.size() == 1);
+    CHECK(caches.front().rfind(kStem + ".lang-de.props-1-", 0) == 0);
+
+    remove_caches();
+    std::filesystem::remove(dump);
+}